@@ -1,86 +1,262 @@
 package main
 
 import (
-	"log"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
 	"os"
+	"os/signal"
 	"strings"
+	"sync"
+	"syscall"
+	"time"
 
+	"smart-learning-backend/pkg/authtoken"
+	"smart-learning-backend/pkg/blobstore"
+	"smart-learning-backend/pkg/config"
 	"smart-learning-backend/pkg/database"
 	"smart-learning-backend/pkg/handlers"
+	"smart-learning-backend/pkg/i18n"
+	"smart-learning-backend/pkg/interfaces"
+	"smart-learning-backend/pkg/logging"
+	"smart-learning-backend/pkg/mailer"
 	"smart-learning-backend/pkg/middleware"
+	"smart-learning-backend/pkg/models"
+	"smart-learning-backend/pkg/oidc"
+	"smart-learning-backend/pkg/passwordpolicy"
 	"smart-learning-backend/pkg/repositories"
 	"smart-learning-backend/pkg/services"
+	"smart-learning-backend/pkg/utils"
 
 	"github.com/gin-gonic/gin"
-	"github.com/joho/godotenv"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
 )
 
 func main() {
-	// 載入環境變數
-	if err := godotenv.Load(); err != nil {
-		log.Println("⚠️ 沒有找到 .env 檔案，使用系統環境變數")
+	configFile := flag.String("config", "", "YAML 設定檔路徑（預設為環境變數 CONFIG_FILE）")
+	printConfig := flag.Bool("print-config", false, "輸出遮蔽機密後的設定並結束")
+	flag.Parse()
+
+	// 載入設定：預設值、設定檔、.env 與環境變數
+	cfg, err := config.Load(*configFile)
+	if *printConfig {
+		os.Exit(runPrintConfig(cfg, err))
+	}
+	if err != nil {
+		fatal("設定無效", err)
+	}
+
+	// 依 LOG_LEVEL 與 LOG_FORMAT 輸出結構化日誌；標準 log 套件的輸出也經由此 logger
+	logger, err := logging.New(cfg.Log, os.Stdout)
+	if err != nil {
+		fatal("日誌設定無效", err)
+	}
+	slog.SetDefault(logger)
+	gin.DebugPrintRouteFunc = func(method, path, handler string, _ int) {
+		slog.Debug("註冊路由", "method", method, "path", path, "handler", handler)
 	}
 
 	// 設置 Gin 模式
-	ginMode := os.Getenv("GIN_MODE")
-	if ginMode != "" {
-		gin.SetMode(ginMode)
+	gin.SetMode(cfg.Server.GinMode)
+
+	// 載入 JWT 簽署金鑰（非 debug 模式下拒絕使用預設密鑰）
+	keyManager, err := authtoken.LoadKeyManager(cfg.JWT, cfg.Debug())
+	if err != nil {
+		fatal("JWT 金鑰載入失敗", err)
+	}
+	tokenIssuer := authtoken.NewIssuer(keyManager, cfg.JWT)
+
+	passwordHashing, err := utils.LoadPasswordHashing(cfg.PasswordHashing)
+	if err != nil {
+		fatal("密碼雜湊設定無效", err)
+	}
+	utils.SetPasswordHashing(passwordHashing)
+
+	passwordPolicy, err := passwordpolicy.Load(cfg.PasswordPolicy)
+	if err != nil {
+		fatal("密碼政策設定無效", err)
 	}
 
 	// 建立資料庫連接
-	db, err := database.NewPostgresConnection()
+	db, err := database.NewPostgresConnection(cfg.Database)
 	if err != nil {
-		log.Fatalf("❌ 資料庫連接失敗: %v", err)
+		fatal("資料庫連接失敗", err)
 	}
 	defer db.Close()
 
+	// 定期清除工作在資料庫關閉前停止並等待執行中的工作結束
+	jobs := newBackgroundJobs()
+	defer jobs.stop()
+
 	// 測試資料庫連接
 	if err := db.TestConnection(); err != nil {
-		log.Fatalf("❌ 資料庫測試失敗: %v", err)
+		fatal("資料庫測試失敗", err)
 	}
 
 	// 顯示連接池統計
 	stats := db.GetStats()
-	log.Printf("📊 連接池統計 - 最大開啟連接: %d, 開啟連接: %d, 使用中連接: %d, 閒置連接: %d",
-		stats.MaxOpenConnections, stats.OpenConnections, stats.InUse, stats.Idle)
+	slog.Info("連接池統計", "max_open_connections", stats.MaxOpenConnections, "open_connections", stats.OpenConnections,
+		"in_use", stats.InUse, "idle", stats.Idle)
 
 	// 初始化依賴注入
 	userRepo := repositories.NewUserRepository(db.DB)
-	authService := services.NewAuthService(userRepo)
-	authHandler := handlers.NewAuthHandler(authService)
+	refreshTokenRepo := repositories.NewRefreshTokenRepository(db.DB)
+	revokedTokenRepo := newRevokedTokenRepository(db, cfg.Store.Token, jobs)
+	defer closeStore(revokedTokenRepo)
+	sessionRepo := repositories.NewSessionRepository(db.DB)
+	passwordResetTokenRepo := repositories.NewPasswordResetTokenRepository(db.DB)
+	emailVerificationTokenRepo := repositories.NewEmailVerificationTokenRepository(db.DB)
+	mailSender := newMailer(cfg.Mail)
+	appBaseURL := cfg.Server.AppBaseURL
+	emailVerificationService := services.NewEmailVerificationService(userRepo, emailVerificationTokenRepo, mailSender, appBaseURL)
+	mfaRepo := repositories.NewMFARepository(db.DB)
+	mfaService := services.NewMFAService(mfaRepo, userRepo, cfg.MFA.Issuer)
+	loginAttemptRepo := newLoginAttemptRepository(db, cfg.Store.LoginAttempt, jobs)
+	defer closeStore(loginAttemptRepo)
+	rateLimitRepo := repositories.NewMemoryRateLimitRepository(10 * time.Minute)
+	defer rateLimitRepo.Close()
+	roleRepo := repositories.NewRoleRepository(db.DB)
+	auditLogService := services.NewAuditLogService(repositories.NewAuditLogRepository(db.DB))
+	authService := services.NewAuthService(
+		userRepo,
+		refreshTokenRepo,
+		revokedTokenRepo,
+		sessionRepo,
+		emailVerificationService,
+		mfaService,
+		loginAttemptRepo,
+		roleRepo,
+		passwordPolicy,
+		auditLogService,
+		tokenIssuer,
+	)
+	passwordResetService := services.NewPasswordResetService(
+		userRepo,
+		passwordResetTokenRepo,
+		refreshTokenRepo,
+		sessionRepo,
+		mailSender,
+		passwordPolicy,
+		auditLogService,
+		appBaseURL,
+	)
+	magicLinkService := services.NewMagicLinkService(
+		userRepo,
+		repositories.NewMagicLinkTokenRepository(db.DB),
+		rateLimitRepo,
+		mailSender,
+		authService,
+		appBaseURL,
+	)
+	apiKeyRepo := repositories.NewAPIKeyRepository(db.DB)
+	apiKeyService := services.NewAPIKeyService(apiKeyRepo, userRepo, roleRepo)
+	identityRepo := repositories.NewUserIdentityRepository(db.DB)
+	oidcService := services.NewOIDCService(
+		newOIDCProviders(cfg.OIDC),
+		newOIDCAuthStateRepository(db, jobs),
+		identityRepo,
+		userRepo,
+		authService,
+	)
+	profileService := services.NewProfileService(userRepo, sessionRepo, refreshTokenRepo, emailVerificationService, passwordPolicy)
+	apiBaseURL := cfg.Server.APIBaseURL
+	blobStore, blobHandler := newBlobStore(cfg.Blob, apiBaseURL)
+	avatarService := services.NewAvatarService(userRepo, blobStore, apiBaseURL)
+	accountService := services.NewAccountService(
+		userRepo,
+		sessionRepo,
+		refreshTokenRepo,
+		identityRepo,
+		apiKeyRepo,
+		mfaService,
+		loginAttemptRepo,
+		mailSender,
+		[]interfaces.UserDataPurgerInterface{avatarService},
+		// 單字表、學習紀錄與複習排程等功能模組在此註冊各自的匯出區段
+		[]interfaces.UserDataExporterInterface{},
+	)
+	startAccountPurge(accountService, jobs)
+	adminService := services.NewAdminService(userRepo, sessionRepo, refreshTokenRepo, roleRepo, passwordResetService, tokenIssuer)
+	authHandler := handlers.NewAuthHandler(authService, auditLogService)
+	passwordResetHandler := handlers.NewPasswordResetHandler(passwordResetService)
+	emailVerificationHandler := handlers.NewEmailVerificationHandler(emailVerificationService)
+	mfaHandler := handlers.NewMFAHandler(mfaService, auditLogService)
+	jwksHandler := handlers.NewJWKSHandler(keyManager)
+	adminHandler := handlers.NewAdminHandler(authService, adminService, auditLogService)
+	auditLogHandler := handlers.NewAuditLogHandler(auditLogService)
+	oidcHandler := handlers.NewOIDCHandler(oidcService)
+	magicLinkHandler := handlers.NewMagicLinkHandler(magicLinkService)
+	apiKeyHandler := handlers.NewAPIKeyHandler(apiKeyService, auditLogService)
+	profileHandler := handlers.NewProfileHandler(profileService, auditLogService)
+	avatarHandler := handlers.NewAvatarHandler(avatarService)
+	accountHandler := handlers.NewAccountHandler(accountService)
+
+	// 驗證錯誤訊息依請求語系翻譯
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		if err := i18n.RegisterValidator(v); err != nil {
+			fatal("註冊驗證訊息翻譯失敗", err)
+		}
+	}
 
 	// 初始化 Gin 路由器
-	r := gin.Default()
+	r := gin.New()
 
 	// 設置信任的代理伺服器
-	trustedProxies := os.Getenv("TRUSTED_PROXIES")
-	if trustedProxies == "" {
+	trustedProxies := cfg.Server.TrustedProxies
+	if len(trustedProxies) == 0 {
 		// 開發環境：不信任任何代理（最安全）
 		if err := r.SetTrustedProxies(nil); err != nil {
-			log.Fatalf("❌ 設置代理信任失敗: %v", err)
+			fatal("設置代理信任失敗", err)
 		}
-		log.Println("🔒 代理設置：不信任任何代理（開發模式）")
+		slog.Info("代理設置：不信任任何代理（開發模式）")
 	} else {
-		// 生產環境：根據環境變數設置信任的代理
+		// 生產環境：根據設定信任的代理，設為 none 時不信任任何代理
 		proxies := []string{}
-		if trustedProxies != "none" {
-			// 將逗號分隔的 IP 轉換為字串陣列
-			for _, proxy := range strings.Split(trustedProxies, ",") {
-				proxies = append(proxies, strings.TrimSpace(proxy))
+		for _, proxy := range trustedProxies {
+			if proxy != "none" {
+				proxies = append(proxies, proxy)
 			}
 		}
 		if err := r.SetTrustedProxies(proxies); err != nil {
-			log.Fatalf("❌ 設置代理信任失敗: %v", err)
+			fatal("設置代理信任失敗", err)
 		}
 		if len(proxies) == 0 {
-			log.Println("🔒 代理設置：不信任任何代理")
+			slog.Info("代理設置：不信任任何代理")
 		} else {
-			log.Printf("🔒 代理設置：信任的代理 %v", proxies)
+			slog.Info("代理設置：信任的代理", "proxies", proxies)
 		}
 	}
 
 	// 添加中介軟體
+	r.Use(middleware.RequestID(logger))
+	r.Use(middleware.RequestLogger())
+	r.Use(middleware.Locale())
+	r.Use(middleware.Timeout(newRequestTimeouts(cfg.Server)))
+	r.Use(middleware.ErrorHandler())
+	r.Use(middleware.Recovery())
 	r.Use(middleware.CORSMiddleware())
+	authMiddleware := middleware.AuthMiddleware(tokenIssuer, revokedTokenRepo, sessionRepo, userRepo, apiKeyService)
+
+	// 各路由群組的限流規則
+	apiRateLimit := middleware.RateLimitMiddleware(rateLimitRepo, middleware.RateLimitPolicy{
+		Name: "api", Limit: 300, Window: time.Minute, Key: middleware.KeyByIP,
+	})
+	credentialRateLimit := middleware.RateLimitMiddleware(rateLimitRepo, middleware.RateLimitPolicy{
+		Name: "auth", Limit: 10, Window: time.Minute, Key: middleware.KeyByIP,
+	})
+	userRateLimit := middleware.RateLimitMiddleware(rateLimitRepo, middleware.RateLimitPolicy{
+		Name: "user", Limit: 120, Window: time.Minute, Key: middleware.KeyByAPIKey,
+	})
+	adminRateLimit := middleware.RateLimitMiddleware(rateLimitRepo, middleware.RateLimitPolicy{
+		Name: "admin", Limit: 60, Window: time.Minute, Key: middleware.KeyByUser,
+	})
+	uploadRateLimit := middleware.RateLimitMiddleware(rateLimitRepo, middleware.RateLimitPolicy{
+		Name: "upload", Limit: 10, Window: time.Minute, Key: middleware.KeyByUser,
+	})
 
 	// 健康檢查端點
 	r.GET("/health", func(c *gin.Context) {
@@ -97,16 +273,95 @@ func main() {
 		})
 	})
 
-	// API 路由群組
-	api := r.Group("/api/v1")
+	// JWT 公鑰端點
+	r.GET("/.well-known/jwks.json", jwksHandler.GetJWKS)
+
+	// 本機儲存的上傳檔案（BLOB_DRIVER=local）
+	if blobHandler != nil {
+		files := gin.WrapH(http.StripPrefix("/files", blobHandler))
+		r.GET("/files/*key", files)
+		r.HEAD("/files/*key", files)
+	}
+
+	// API 路由群組（整體以來源 IP 限流）
+	api := r.Group("/api/v1", apiRateLimit)
 	{
-		// 認證路由
+		// 認證路由：登入、註冊等憑證端點以來源 IP 嚴格限流
 		auth := api.Group("/auth")
 		{
-			auth.POST("/register", authHandler.Register)
-			auth.POST("/login", authHandler.Login)
-			auth.POST("/logout", middleware.AuthMiddleware(), authHandler.Logout)
-			auth.GET("/me", middleware.AuthMiddleware(), authHandler.GetMe)
+			auth.POST("/register", credentialRateLimit, authHandler.Register)
+			auth.POST("/login", credentialRateLimit, authHandler.Login)
+			auth.POST("/mfa/verify", credentialRateLimit, authHandler.VerifyMFA)
+			auth.POST("/refresh", credentialRateLimit, authHandler.Refresh)
+			auth.POST("/password/forgot", credentialRateLimit, passwordResetHandler.ForgotPassword)
+			auth.POST("/password/reset", credentialRateLimit, passwordResetHandler.ResetPassword)
+			auth.POST("/magic-link", credentialRateLimit, magicLinkHandler.RequestMagicLink)
+			auth.POST("/magic-link/verify", credentialRateLimit, magicLinkHandler.LoginWithMagicLink)
+			auth.GET("/verify", credentialRateLimit, emailVerificationHandler.VerifyEmail)
+			auth.GET("/oidc/providers", oidcHandler.ListProviders)
+			auth.GET("/oidc/:provider/authorize", credentialRateLimit, oidcHandler.Authorize)
+			auth.POST("/oidc/:provider/callback", credentialRateLimit, oidcHandler.Callback)
+		}
+
+		// 需要登入的認證路由：依 API 金鑰或用戶 ID 限流
+		authed := api.Group("/auth", authMiddleware, userRateLimit)
+		{
+			authed.GET("/me", authHandler.GetMe)
+
+			// 帳號安全相關端點僅接受登入取得的 access token，不接受 API 金鑰
+			account := authed.Group("", middleware.DenyAPIKey())
+			{
+				account.POST("/logout", authHandler.Logout)
+
+				// 管理員代入用戶身分時不可變更帳號的安全設定
+				security := account.Group("", middleware.DenyImpersonation())
+				security.POST("/verify/resend", emailVerificationHandler.ResendVerification)
+				security.GET("/sessions", authHandler.ListSessions)
+				security.DELETE("/sessions/:id", authHandler.RevokeSession)
+				security.POST("/sessions/revoke-others", authHandler.RevokeOtherSessions)
+				security.POST("/mfa/setup", mfaHandler.Setup)
+				security.POST("/mfa/confirm", mfaHandler.Confirm)
+				security.POST("/mfa/disable", mfaHandler.Disable)
+				security.GET("/api-keys", apiKeyHandler.ListAPIKeys)
+				// 長期有效的 API 金鑰需先完成電子郵件驗證，避免以他人信箱註冊的帳號取得金鑰
+				security.POST("/api-keys", middleware.RequireVerifiedEmail(userRepo), apiKeyHandler.CreateAPIKey)
+				security.DELETE("/api-keys/:id", apiKeyHandler.RevokeAPIKey)
+			}
+		}
+
+		// 用戶自身資料：僅接受登入取得的 access token，代入用戶身分時不可修改；需要目前密碼的端點以來源 IP 嚴格限流
+		me := api.Group("/users/me", authMiddleware, userRateLimit, middleware.DenyAPIKey(), middleware.DenyImpersonation())
+		{
+			me.PATCH("", profileHandler.UpdateMe)
+			me.DELETE("", credentialRateLimit, accountHandler.DeleteMe)
+			me.GET("/export", accountHandler.ExportMe)
+			me.POST("/password", credentialRateLimit, profileHandler.ChangePassword)
+			me.POST("/email", credentialRateLimit, profileHandler.ChangeEmail)
+			me.POST("/avatar", uploadRateLimit, avatarHandler.UploadAvatar)
+			me.DELETE("/avatar", avatarHandler.DeleteAvatar)
+		}
+
+		// 頭像下載：轉址到儲存服務的網址，不需要登入
+		api.GET("/users/:id/avatar", avatarHandler.GetAvatar)
+
+		// 管理員路由：不接受代入用戶身分的 token
+		admin := api.Group("/admin", authMiddleware, adminRateLimit, middleware.DenyImpersonation())
+		{
+			usersRead := middleware.RequirePermission(models.PermissionUsersRead)
+			usersManage := middleware.RequirePermission(models.PermissionUsersManage)
+
+			admin.GET("/users", usersRead, adminHandler.ListUsers)
+			admin.GET("/users/:id", usersRead, adminHandler.GetUser)
+			admin.POST("/users/:id/unlock", usersManage, adminHandler.UnlockUser)
+			admin.POST("/users/:id/suspend", usersManage, adminHandler.SuspendUser)
+			admin.POST("/users/:id/unsuspend", usersManage, adminHandler.UnsuspendUser)
+			admin.POST("/users/:id/password-reset", usersManage, adminHandler.ForcePasswordReset)
+			admin.POST("/users/:id/impersonate", usersManage, adminHandler.Impersonate)
+			admin.PUT("/users/:id/role", middleware.RequirePermission(models.PermissionRolesManage), adminHandler.ChangeRole)
+
+			auditLogRead := middleware.RequirePermission(models.PermissionAuditLogRead)
+			admin.GET("/audit-logs", auditLogRead, auditLogHandler.ListAuditLogs)
+			admin.GET("/audit-logs/export", auditLogRead, auditLogHandler.ExportAuditLogs)
 		}
 
 		// 測試端點
@@ -118,22 +373,245 @@ func main() {
 	}
 
 	// 獲取端口
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
-	}
+	port := cfg.Server.Port
 
-	log.Printf("🚀 伺服器啟動在端口 %s", port)
-	log.Printf("🌐 健康檢查: http://localhost:%s/health", port)
-	log.Printf("📡 API 端點: http://localhost:%s/api/v1/ping", port)
-	log.Printf("🔐 認證端點:")
-	log.Printf("   註冊: POST http://localhost:%s/api/v1/auth/register", port)
-	log.Printf("   登入: POST http://localhost:%s/api/v1/auth/login", port)
-	log.Printf("   登出: POST http://localhost:%s/api/v1/auth/logout", port)
-	log.Printf("   用戶資料: GET http://localhost:%s/api/v1/auth/me", port)
+	slog.Info("伺服器啟動", "port", port, "gin_mode", gin.Mode(), "routes", len(r.Routes()))
 
 	// 啟動伺服器
-	if err := r.Run(":" + port); err != nil {
-		log.Fatalf("❌ 伺服器啟動失敗: %v", err)
+	srv := &http.Server{Addr: ":" + port, Handler: r}
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			fatal("伺服器啟動失敗", err)
+		}
+	}()
+
+	// 收到 SIGINT 或 SIGTERM 後停止接受新連線並等待處理中的請求完成；
+	// 返回後依序執行 defer，關閉記憶體儲存的清理 goroutine、停止定期清除工作並關閉資料庫連接
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	<-ctx.Done()
+
+	slog.Info("伺服器關閉中")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		slog.Error("伺服器未在時限內關閉", "error", err)
+	}
+}
+
+// shutdownTimeout 為關閉伺服器時等待處理中請求完成的時限
+const shutdownTimeout = 30 * time.Second
+
+// closeStore 停止記憶體儲存的背景清理 goroutine；資料庫儲存沒有 Close，不需處理
+func closeStore(store interface{}) {
+	if closer, ok := store.(interface{ Close() }); ok {
+		closer.Close()
+	}
+}
+
+// newRevokedTokenRepository 依 TOKEN_STORE 選擇撤銷清單的儲存方式（postgres 或 memory）
+func newRevokedTokenRepository(db *database.DB, store string, jobs *backgroundJobs) interfaces.RevokedTokenRepositoryInterface {
+	if store == "memory" {
+		slog.Info("Token 撤銷清單：使用記憶體儲存")
+		return repositories.NewMemoryRevokedTokenRepository(10 * time.Minute)
+	}
+
+	repo := repositories.NewRevokedTokenRepository(db.DB)
+
+	// 定期清除已過期的撤銷紀錄
+	jobs.every(time.Hour, func(ctx context.Context) {
+		deleted, err := repo.DeleteExpiredTokens(ctx)
+		if err != nil {
+			slog.Error("清除過期撤銷紀錄失敗", "error", err)
+		} else if deleted > 0 {
+			slog.Info("已清除過期撤銷紀錄", "deleted", deleted)
+		}
+	})
+
+	return repo
+}
+
+// newLoginAttemptRepository 依 LOGIN_ATTEMPT_STORE 選擇登入失敗紀錄的儲存方式（postgres 或 memory）
+func newLoginAttemptRepository(db *database.DB, store string, jobs *backgroundJobs) interfaces.LoginAttemptRepositoryInterface {
+	if store == "memory" {
+		slog.Info("登入失敗紀錄：使用記憶體儲存")
+		return repositories.NewMemoryLoginAttemptRepository(10*time.Minute, services.FailureResetWindow)
+	}
+
+	repo := repositories.NewLoginAttemptRepository(db.DB)
+
+	// 定期清除已失效的登入失敗紀錄
+	jobs.every(time.Hour, func(ctx context.Context) {
+		deleted, err := repo.DeleteStaleLoginAttempts(ctx, time.Now().Add(-services.FailureResetWindow))
+		if err != nil {
+			slog.Error("清除登入失敗紀錄失敗", "error", err)
+		} else if deleted > 0 {
+			slog.Info("已清除登入失敗紀錄", "deleted", deleted)
+		}
+	})
+
+	return repo
+}
+
+// newOIDCAuthStateRepository 建立 OIDC 授權狀態倉庫，並定期清除逾時未完成的登入流程
+func newOIDCAuthStateRepository(db *database.DB, jobs *backgroundJobs) interfaces.OIDCAuthStateRepositoryInterface {
+	repo := repositories.NewOIDCAuthStateRepository(db.DB)
+
+	jobs.every(time.Hour, func(ctx context.Context) {
+		deleted, err := repo.DeleteExpiredOIDCAuthStates(ctx, time.Now())
+		if err != nil {
+			slog.Error("清除過期外部登入狀態失敗", "error", err)
+		} else if deleted > 0 {
+			slog.Info("已清除過期外部登入狀態", "deleted", deleted)
+		}
+	})
+
+	return repo
+}
+
+// backgroundJobTimeout 為每次定期清除工作的時限，避免卡住的查詢長期佔用連接池
+const backgroundJobTimeout = 5 * time.Minute
+
+// backgroundJobs 管理定期清除工作的 goroutine；stop 會取消執行中的查詢並等待所有工作結束
+type backgroundJobs struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func newBackgroundJobs() *backgroundJobs {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &backgroundJobs{ctx: ctx, cancel: cancel}
+}
+
+// every 每隔 interval 執行一次 job，直到 stop 被呼叫
+func (j *backgroundJobs) every(interval time.Duration, job func(ctx context.Context)) {
+	j.wg.Add(1)
+	go func() {
+		defer j.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-j.ctx.Done():
+				return
+			case <-ticker.C:
+				ctx, cancel := context.WithTimeout(j.ctx, backgroundJobTimeout)
+				job(ctx)
+				cancel()
+			}
+		}
+	}()
+}
+
+// stop 停止所有定期清除工作，須在關閉資料庫連接前呼叫
+func (j *backgroundJobs) stop() {
+	j.cancel()
+	j.wg.Wait()
+}
+
+// newRequestTimeouts 依 REQUEST_TIMEOUT 與 ROUTE_TIMEOUTS 設定每個請求的處理時限
+func newRequestTimeouts(cfg config.ServerConfig) middleware.RequestTimeouts {
+	slog.Info("請求處理時限", "default", cfg.RequestTimeout, "routes", len(cfg.RouteTimeouts))
+	return middleware.RequestTimeouts{Default: cfg.RequestTimeout, Routes: cfg.RouteTimeouts}
+}
+
+// startAccountPurge 定期刪除寬限期已過的帳號
+func startAccountPurge(accountService *services.AccountService, jobs *backgroundJobs) {
+	jobs.every(time.Hour, func(ctx context.Context) {
+		purged, err := accountService.PurgeDueAccounts(ctx, time.Now())
+		if err != nil {
+			slog.Error("刪除到期帳號失敗", "error", err)
+		} else if purged > 0 {
+			slog.Info("已刪除到期帳號", "purged", purged)
+		}
+	})
+}
+
+// newOIDCProviders 依設定建立外部登入的身分提供者
+func newOIDCProviders(cfg config.OIDCConfig) []interfaces.OIDCProviderInterface {
+	providers := []interfaces.OIDCProviderInterface{}
+
+	for _, provider := range cfg.Providers {
+		slog.Info("外部登入", "provider", provider.Name, "issuer", provider.Issuer)
+		providers = append(providers, oidc.NewProvider(oidc.Config{
+			Name:         provider.Name,
+			Issuer:       provider.Issuer,
+			ClientID:     provider.ClientID,
+			ClientSecret: provider.ClientSecret,
+			RedirectURL:  provider.RedirectURL,
+			Scopes:       provider.Scopes,
+		}))
+	}
+
+	return providers
+}
+
+// newBlobStore 依 BLOB_DRIVER 選擇上傳檔案的儲存方式（local 或 s3）；
+// local 時另回傳提供檔案下載的 handler，由呼叫端掛載在 /files
+func newBlobStore(cfg config.BlobConfig, apiBaseURL string) (interfaces.BlobStoreInterface, http.Handler) {
+	switch cfg.Driver {
+	case "s3":
+		store, err := blobstore.NewS3Store(blobstore.S3Config{
+			Endpoint:        cfg.S3.Endpoint,
+			Region:          cfg.S3.Region,
+			Bucket:          cfg.S3.Bucket,
+			AccessKeyID:     cfg.S3.AccessKeyID,
+			SecretAccessKey: cfg.S3.SecretAccessKey,
+			PublicBaseURL:   cfg.S3.PublicBaseURL,
+			URLTTL:          cfg.URLTTL,
+		})
+		if err != nil {
+			fatal("S3 儲存設定無效", err)
+		}
+		slog.Info("上傳檔案：S3", "endpoint", cfg.S3.Endpoint, "bucket", cfg.S3.Bucket)
+		return store, nil
+	default:
+		signingKey := []byte(cfg.URLSigningKey)
+		if len(signingKey) == 0 {
+			slog.Info("上傳檔案：本機目錄（靜態網址）", "dir", cfg.LocalDir)
+		} else {
+			slog.Info("上傳檔案：本機目錄（簽章網址）", "dir", cfg.LocalDir, "url_ttl", cfg.URLTTL)
+		}
+		store := blobstore.NewLocalStore(cfg.LocalDir, strings.TrimRight(apiBaseURL, "/")+"/files", signingKey, cfg.URLTTL)
+		return store, store
+	}
+}
+
+// newMailer 依 MAIL_DRIVER 選擇郵件寄送方式（smtp、file 或 log）
+func newMailer(cfg config.MailConfig) interfaces.MailerInterface {
+	switch cfg.Driver {
+	case "smtp":
+		slog.Info("郵件寄送：SMTP", "host", cfg.SMTPHost)
+		return mailer.NewSMTPMailer(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.From)
+	case "file":
+		slog.Info("郵件寄送：寫入目錄", "dir", cfg.Dir)
+		return mailer.NewFileMailer(cfg.Dir, cfg.From)
+	default:
+		slog.Info("郵件寄送：僅輸出到日誌")
+		return mailer.NewLogMailer()
+	}
+}
+
+// fatal 記錄錯誤後結束程式
+func fatal(message string, err error) {
+	slog.Error(message, "error", err)
+	os.Exit(1)
+}
+
+// runPrintConfig 輸出遮蔽機密後的設定與驗證錯誤，回傳結束碼：設定無效時為 1
+func runPrintConfig(cfg *config.Config, loadErr error) int {
+	if cfg != nil {
+		out, err := cfg.Dump()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "輸出設定失敗: %v\n", err)
+			return 1
+		}
+		os.Stdout.Write(out)
+	}
+	if loadErr != nil {
+		fmt.Fprintf(os.Stderr, "設定無效:\n%v\n", loadErr)
+		return 1
 	}
-}
\ No newline at end of file
+	return 0
+}
@@ -0,0 +1,10 @@
+-- 建立 revoked_tokens 表（access token 撤銷清單）
+CREATE TABLE revoked_tokens (
+    jti VARCHAR(64) PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    revoked_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 建立索引
+CREATE INDEX idx_revoked_tokens_expires_at ON revoked_tokens(expires_at);
@@ -0,0 +1,11 @@
+-- 建立 login_attempts 表（登入失敗次數與暫時鎖定）
+-- key 格式為 account:<email> 或 ip:<address>
+CREATE TABLE login_attempts (
+    key VARCHAR(320) PRIMARY KEY,
+    failures INTEGER NOT NULL DEFAULT 0,
+    locked_until TIMESTAMP WITH TIME ZONE,
+    last_failed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 建立索引
+CREATE INDEX idx_login_attempts_last_failed_at ON login_attempts(last_failed_at);
@@ -0,0 +1,55 @@
+-- 建立角色與權限表
+CREATE TABLE roles (
+    name VARCHAR(20) PRIMARY KEY,
+    description VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE permissions (
+    name VARCHAR(50) PRIMARY KEY,
+    description VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE role_permissions (
+    role VARCHAR(20) NOT NULL REFERENCES roles(name) ON DELETE CASCADE,
+    permission VARCHAR(50) NOT NULL REFERENCES permissions(name) ON DELETE CASCADE,
+    PRIMARY KEY (role, permission)
+);
+
+-- 預設角色
+INSERT INTO roles (name, description) VALUES
+    ('student', '學生'),
+    ('teacher', '教師'),
+    ('admin', '管理員');
+
+-- 預設權限
+INSERT INTO permissions (name, description) VALUES
+    ('learning:read', '瀏覽學習內容'),
+    ('learning:write', '記錄自己的學習進度'),
+    ('content:manage', '建立與編輯學習內容'),
+    ('students:read', '查看學生的學習狀況'),
+    ('users:read', '查看用戶帳號'),
+    ('users:manage', '管理用戶帳號（停用、解除鎖定等）'),
+    ('roles:manage', '變更用戶角色');
+
+INSERT INTO role_permissions (role, permission) VALUES
+    ('student', 'learning:read'),
+    ('student', 'learning:write'),
+    ('teacher', 'learning:read'),
+    ('teacher', 'learning:write'),
+    ('teacher', 'content:manage'),
+    ('teacher', 'students:read'),
+    ('admin', 'learning:read'),
+    ('admin', 'learning:write'),
+    ('admin', 'content:manage'),
+    ('admin', 'students:read'),
+    ('admin', 'users:read'),
+    ('admin', 'users:manage'),
+    ('admin', 'roles:manage');
+
+-- 用戶角色，既有用戶預設為學生
+ALTER TABLE users ADD COLUMN role VARCHAR(20) NOT NULL DEFAULT 'student' REFERENCES roles(name);
+
+-- 建立索引
+CREATE INDEX idx_users_role ON users(role);
@@ -0,0 +1,2 @@
+-- 變更 email 時，新地址先保存在驗證 token 上，點擊驗證連結後才寫入 users
+ALTER TABLE email_verification_tokens ADD COLUMN new_email VARCHAR(255);
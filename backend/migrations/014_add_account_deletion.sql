@@ -0,0 +1,6 @@
+-- 刪除帳號的寬限期：deletion_scheduled_at 到期後由背景工作刪除帳號，
+-- 其餘資料表以外鍵 ON DELETE CASCADE 一併刪除；寬限期內重新登入即取消刪除
+ALTER TABLE users ADD COLUMN deletion_scheduled_at TIMESTAMP WITH TIME ZONE;
+
+-- 建立索引
+CREATE INDEX idx_users_deletion_scheduled_at ON users(deletion_scheduled_at) WHERE deletion_scheduled_at IS NOT NULL;
@@ -0,0 +1,7 @@
+-- 管理員停用帳號：停用期間無法登入，既有的會話、refresh token 與 API 金鑰一併失效
+ALTER TABLE users ADD COLUMN suspended_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE users ADD COLUMN suspension_reason VARCHAR(255);
+
+-- 建立索引：管理員用戶列表依建立時間與學習等級篩選、排序
+CREATE INDEX idx_users_created_at ON users(created_at);
+CREATE INDEX idx_users_learning_level ON users(learning_level);
@@ -0,0 +1,41 @@
+-- 建立 audit_log 表（安全稽核日誌，只允許新增）
+-- actor_id 為執行操作的用戶，target_user_id 為受影響的用戶；不設外鍵，帳號刪除後紀錄仍保留
+CREATE TABLE audit_log (
+    id BIGSERIAL PRIMARY KEY,
+    event_type VARCHAR(50) NOT NULL,
+    actor_id INTEGER,
+    target_user_id INTEGER,
+    ip_address VARCHAR(45) NOT NULL DEFAULT '',
+    user_agent VARCHAR(500) NOT NULL DEFAULT '',
+    request_id VARCHAR(64) NOT NULL DEFAULT '',
+    metadata JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 拒絕修改或刪除既有紀錄
+CREATE FUNCTION audit_log_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_log_no_update_delete
+    BEFORE UPDATE OR DELETE ON audit_log
+    FOR EACH ROW EXECUTE FUNCTION audit_log_append_only();
+
+CREATE TRIGGER audit_log_no_truncate
+    BEFORE TRUNCATE ON audit_log
+    FOR EACH STATEMENT EXECUTE FUNCTION audit_log_append_only();
+
+-- 查詢稽核日誌的權限
+INSERT INTO permissions (name, description) VALUES
+    ('audit_log:read', '查看與匯出稽核日誌');
+
+INSERT INTO role_permissions (role, permission) VALUES
+    ('admin', 'audit_log:read');
+
+-- 建立索引
+CREATE INDEX idx_audit_log_created_at ON audit_log(created_at);
+CREATE INDEX idx_audit_log_actor_id ON audit_log(actor_id, created_at);
+CREATE INDEX idx_audit_log_target_user_id ON audit_log(target_user_id, created_at);
+CREATE INDEX idx_audit_log_event_type ON audit_log(event_type, created_at);
@@ -0,0 +1,2 @@
+-- 用戶偏好的回應語系（zh-TW、en、ja）；NULL 代表依請求的 Accept-Language 決定
+ALTER TABLE users ADD COLUMN locale VARCHAR(10);
@@ -0,0 +1,124 @@
+package apperrors
+
+import "net/http"
+
+// 通用錯誤
+var (
+	ErrInternal          = New("INTERNAL_SERVER_ERROR", http.StatusInternalServerError, "internal server error")
+	ErrAccountLocked     = New("ACCOUNT_LOCKED", http.StatusTooManyRequests, "account locked")
+	ErrRateLimitExceeded = New("RATE_LIMIT_EXCEEDED", http.StatusTooManyRequests, "rate limit exceeded")
+	ErrRequestTimeout    = New("REQUEST_TIMEOUT", http.StatusServiceUnavailable, "request timeout")
+)
+
+// 用戶與帳號
+var (
+	ErrUserNotFound           = New("USER_NOT_FOUND", http.StatusNotFound, "user not found")
+	ErrUserAlreadyExists      = New("USER_ALREADY_EXISTS", http.StatusConflict, "user already exists")
+	ErrUsernameTaken          = New("USERNAME_TAKEN", http.StatusConflict, "username already taken")
+	ErrEmailAlreadyInUse      = New("EMAIL_ALREADY_IN_USE", http.StatusConflict, "email already in use")
+	ErrEmailAlreadyVerified   = New("EMAIL_ALREADY_VERIFIED", http.StatusConflict, "email already verified")
+	ErrUserUpdateConflict     = New("USER_UPDATE_CONFLICT", http.StatusConflict, "user update conflict")
+	ErrNoProfileChanges       = New("NO_PROFILE_CHANGES", http.StatusBadRequest, "no profile changes")
+	ErrAccountSuspended       = New("ACCOUNT_SUSPENDED", http.StatusForbidden, "account suspended")
+	ErrPasswordsDoNotMatch    = NewField("PASSWORDS_DO_NOT_MATCH", "confirm_password", "passwords do not match")
+	ErrInvalidUsername        = NewField("INVALID_USERNAME", "username", "username can only contain letters, numbers and underscores")
+	ErrInvalidCurrentPassword = NewField("INVALID_CURRENT_PASSWORD", "current_password", "invalid current password")
+	ErrEmailUnchanged         = NewField("EMAIL_UNCHANGED", "new_email", "email unchanged")
+	ErrInvalidAvatarURL       = NewField("INVALID_AVATAR_URL", "avatar_url", "invalid avatar url")
+	ErrUnsupportedLocale      = NewField("UNSUPPORTED_LOCALE", "locale", "unsupported locale")
+)
+
+// 登入、token 與會話
+var (
+	ErrInvalidCredentials       = New("INVALID_CREDENTIALS", http.StatusUnauthorized, "invalid credentials")
+	ErrInvalidRefreshToken      = New("INVALID_REFRESH_TOKEN", http.StatusUnauthorized, "invalid refresh token")
+	ErrRefreshTokenExpired      = New("INVALID_REFRESH_TOKEN", http.StatusUnauthorized, "refresh token expired")
+	ErrRefreshTokenReused       = New("REFRESH_TOKEN_REUSED", http.StatusUnauthorized, "refresh token reuse detected")
+	ErrSessionNotFound          = New("SESSION_NOT_FOUND", http.StatusNotFound, "session not found")
+	ErrInvalidMFAToken          = New("INVALID_MFA_TOKEN", http.StatusUnauthorized, "invalid mfa token")
+	ErrInvalidResetToken        = New("INVALID_RESET_TOKEN", http.StatusBadRequest, "invalid or expired reset token")
+	ErrInvalidVerificationToken = New("INVALID_VERIFICATION_TOKEN", http.StatusBadRequest, "invalid or expired verification token")
+	ErrInvalidMagicLink         = New("INVALID_MAGIC_LINK", http.StatusUnauthorized, "invalid or expired magic link")
+	ErrInvalidAPIKey            = New("INVALID_API_KEY", http.StatusUnauthorized, "invalid api key")
+	ErrReauthenticationRequired = New("REAUTHENTICATION_REQUIRED", http.StatusForbidden, "reauthentication required")
+	ErrMissingToken             = New("MISSING_TOKEN", http.StatusUnauthorized, "missing token")
+	ErrInvalidTokenFormat       = New("INVALID_TOKEN_FORMAT", http.StatusUnauthorized, "invalid token format")
+	ErrInvalidToken             = New("INVALID_TOKEN", http.StatusUnauthorized, "invalid token")
+	ErrTokenRevoked             = New("TOKEN_REVOKED", http.StatusUnauthorized, "token revoked")
+	ErrSessionRevoked           = New("SESSION_REVOKED", http.StatusUnauthorized, "session revoked")
+)
+
+// 授權；由 RequirePermission 等中介軟體在已通過認證後判斷
+var (
+	ErrUnauthorized              = New("UNAUTHORIZED", http.StatusUnauthorized, "unauthorized")
+	ErrAuthenticatedUserNotFound = New("USER_NOT_FOUND", http.StatusUnauthorized, "authenticated user not found")
+	ErrPermissionDenied          = New("PERMISSION_DENIED", http.StatusForbidden, "permission denied")
+	ErrEmailNotVerified          = New("EMAIL_NOT_VERIFIED", http.StatusForbidden, "email not verified")
+	ErrAPIKeyNotAllowed          = New("API_KEY_NOT_ALLOWED", http.StatusForbidden, "api key not allowed")
+	ErrImpersonationNotAllowed   = New("IMPERSONATION_NOT_ALLOWED", http.StatusForbidden, "impersonation not allowed")
+)
+
+// 兩步驟驗證；登入時驗證碼錯誤回應 401，已登入時設定或停用回應 400
+var (
+	ErrInvalidMFACode      = New("INVALID_MFA_CODE", http.StatusBadRequest, "invalid mfa code")
+	ErrInvalidMFALoginCode = New("INVALID_MFA_CODE", http.StatusUnauthorized, "invalid mfa code")
+	ErrMFAAlreadyEnabled   = New("MFA_ALREADY_ENABLED", http.StatusConflict, "mfa already enabled")
+	ErrMFANotEnabled       = New("MFA_NOT_ENABLED", http.StatusBadRequest, "mfa not enabled")
+	ErrMFASetupRequired    = New("MFA_SETUP_REQUIRED", http.StatusBadRequest, "mfa setup not started")
+)
+
+// 外部登入
+var (
+	ErrUnknownProvider         = New("UNKNOWN_OIDC_PROVIDER", http.StatusNotFound, "unknown provider")
+	ErrInvalidOIDCState        = New("INVALID_OIDC_STATE", http.StatusBadRequest, "invalid oidc state")
+	ErrOIDCExchangeFailed      = New("OIDC_EXCHANGE_FAILED", http.StatusUnauthorized, "oidc exchange failed")
+	ErrOIDCProviderUnavailable = New("OIDC_PROVIDER_ERROR", http.StatusBadGateway, "oidc provider unavailable")
+	ErrOIDCEmailNotVerified    = New("OIDC_EMAIL_NOT_VERIFIED", http.StatusForbidden, "email not verified by provider")
+	ErrEmailAlreadyRegistered  = New("EMAIL_ALREADY_REGISTERED", http.StatusConflict, "email already registered")
+)
+
+// 頭像與圖片
+var (
+	ErrAvatarNotFound       = New("AVATAR_NOT_FOUND", http.StatusNotFound, "avatar not found")
+	ErrAvatarTooLarge       = New("AVATAR_TOO_LARGE", http.StatusRequestEntityTooLarge, "avatar too large")
+	ErrUnsupportedImageType = New("UNSUPPORTED_IMAGE_TYPE", http.StatusUnsupportedMediaType, "unsupported image type")
+	ErrInvalidImage         = New("INVALID_IMAGE", http.StatusBadRequest, "invalid image")
+	ErrImageTooLarge        = New("INVALID_IMAGE", http.StatusBadRequest, "image dimensions too large")
+	ErrInvalidAvatarSize    = NewField("INVALID_AVATAR_SIZE", "size", "invalid avatar size")
+)
+
+// API 金鑰
+var (
+	ErrAPIKeyNotFound     = New("API_KEY_NOT_FOUND", http.StatusNotFound, "api key not found")
+	ErrAPIKeyLimitReached = New("API_KEY_LIMIT_REACHED", http.StatusConflict, "api key limit reached")
+	ErrInvalidScope       = NewField("INVALID_SCOPE", "scopes", "invalid scope")
+)
+
+// 用戶管理與稽核日誌
+var (
+	ErrCannotModifySelf       = New("SELF_ACTION_NOT_ALLOWED", http.StatusForbidden, "cannot modify own account")
+	ErrCannotImpersonateSelf  = New("SELF_ACTION_NOT_ALLOWED", http.StatusForbidden, "cannot impersonate self")
+	ErrCannotImpersonateAdmin = New("CANNOT_IMPERSONATE_ADMIN", http.StatusForbidden, "cannot impersonate admin")
+	ErrUserAlreadySuspended   = New("USER_ALREADY_SUSPENDED", http.StatusConflict, "user already suspended")
+	ErrUserNotSuspended       = New("USER_NOT_SUSPENDED", http.StatusConflict, "user not suspended")
+	ErrRoleNotFound           = NewField("ROLE_NOT_FOUND", "role", "role not found")
+	ErrInvalidDateRange       = NewField("INVALID_DATE_RANGE", "created_from", "invalid date range")
+	ErrInvalidTimeRange       = NewField("INVALID_TIME_RANGE", "from", "invalid time range")
+)
+
+// 只在服務內部判斷的錯誤，由服務轉換為上面對外的錯誤
+var (
+	ErrMFANotFound                       = newInternal("mfa not found")
+	ErrRefreshTokenNotFound              = newInternal("refresh token not found")
+	ErrRefreshTokenAlreadyUsed           = newInternal("refresh token already used")
+	ErrPasswordResetTokenNotFound        = newInternal("password reset token not found")
+	ErrPasswordResetTokenAlreadyUsed     = newInternal("password reset token already used")
+	ErrEmailVerificationTokenNotFound    = newInternal("email verification token not found")
+	ErrEmailVerificationTokenAlreadyUsed = newInternal("email verification token already used")
+	ErrMagicLinkTokenNotFound            = newInternal("magic link token not found")
+	ErrMagicLinkTokenAlreadyUsed         = newInternal("magic link token already used")
+	ErrOIDCAuthStateNotFound             = newInternal("oidc auth state not found")
+	ErrIdentityNotFound                  = newInternal("identity not found")
+	ErrIdentityAlreadyLinked             = newInternal("identity already linked")
+	ErrLoginAttemptNotFound              = newInternal("login attempt not found")
+)
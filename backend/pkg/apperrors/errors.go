@@ -0,0 +1,130 @@
+// Package apperrors 定義服務各層共用的領域錯誤。
+//
+// 每個錯誤帶有回應給用戶端的錯誤代碼、HTTP 狀態碼與訊息鍵；repositories 與 services 回傳或包裝
+// 這些錯誤，呼叫端以 errors.Is 判斷，最後由 middleware.ErrorHandler 統一轉換為 API 回應。
+// Error() 的內容只用於日誌，不會回應給用戶端。
+package apperrors
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"smart-learning-backend/pkg/i18n"
+	"strings"
+	"time"
+)
+
+// Error 為帶有錯誤代碼的領域錯誤。套件內宣告的變數為哨兵錯誤，WithArgs、Wrap 等方法回傳
+// 附加資訊的副本，副本仍以 errors.Is 對應到原本的哨兵錯誤
+type Error struct {
+	// Code 為回應中 error.code 的值，例如 USER_NOT_FOUND
+	Code string
+	// Status 為回應的 HTTP 狀態碼
+	Status int
+	// MessageKey 為顯示給用戶的訊息鍵
+	MessageKey string
+	// Field 不為空時表示欄位驗證錯誤，回應 400 並將訊息放在 errors 的此欄位下
+	Field string
+	// Args 為訊息中的參數，例如限制的大小
+	Args []interface{}
+	// RetryAfter 不為 0 時回應 Retry-After 標頭
+	RetryAfter time.Duration
+
+	text  string
+	cause error
+	base  *Error
+}
+
+// New 建立哨兵錯誤，訊息鍵由錯誤代碼推得，例如 USER_NOT_FOUND 為 errors.user_not_found
+func New(code string, status int, text string) *Error {
+	return &Error{
+		Code:       code,
+		Status:     status,
+		MessageKey: "errors." + strings.ToLower(code),
+		text:       text,
+	}
+}
+
+// NewField 建立對應到單一請求欄位的驗證錯誤，回應時只列出欄位訊息，不含錯誤代碼
+func NewField(code, field, text string) *Error {
+	err := New(code, http.StatusBadRequest, text)
+	err.Field = field
+	return err
+}
+
+// newInternal 建立只在服務內部判斷用的錯誤，例如資料列不存在；未被轉換就回應時視為伺服器錯誤
+func newInternal(text string) *Error {
+	return &Error{
+		Code:       "INTERNAL_SERVER_ERROR",
+		Status:     http.StatusInternalServerError,
+		MessageKey: "errors.internal_server_error",
+		text:       text,
+	}
+}
+
+func (e *Error) Error() string {
+	text := e.text
+	if len(e.Args) > 0 {
+		text += ": " + strings.TrimSuffix(fmt.Sprintln(e.Args...), "\n")
+	}
+	if e.cause != nil {
+		text += ": " + e.cause.Error()
+	}
+	return text
+}
+
+func (e *Error) Unwrap() error {
+	return e.cause
+}
+
+// Is 讓副本與其哨兵錯誤相符
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	if !ok {
+		return false
+	}
+	return e == t || e.base == t
+}
+
+// WithArgs 回傳帶有訊息參數的副本
+func (e *Error) WithArgs(args ...interface{}) *Error {
+	copied := e.derive()
+	copied.Args = args
+	return copied
+}
+
+// WithRetryAfter 回傳帶有重試等待時間的副本
+func (e *Error) WithRetryAfter(retryAfter time.Duration) *Error {
+	copied := e.derive()
+	copied.RetryAfter = retryAfter
+	return copied
+}
+
+// Wrap 回傳以 cause 為原因的副本，cause 只出現在日誌中
+func (e *Error) Wrap(cause error) *Error {
+	copied := e.derive()
+	copied.cause = cause
+	return copied
+}
+
+func (e *Error) derive() *Error {
+	copied := *e
+	if e.base == nil {
+		copied.base = e
+	}
+	return &copied
+}
+
+// Message 取得錯誤在指定語系顯示給用戶的訊息
+func (e *Error) Message(locale string) string {
+	return i18n.T(locale, e.MessageKey, e.Args...)
+}
+
+// As 取得錯誤鏈中的 *Error
+func As(err error) (*Error, bool) {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr, true
+	}
+	return nil, false
+}
@@ -0,0 +1,114 @@
+package apperrors
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"smart-learning-backend/pkg/i18n"
+	"testing"
+	"time"
+)
+
+func TestError_Is(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		target error
+		want   bool
+	}{
+		{name: "哨兵錯誤本身", err: ErrUserNotFound, target: ErrUserNotFound, want: true},
+		{name: "以 %w 包裝", err: fmt.Errorf("failed to get user: %w", ErrUserNotFound), target: ErrUserNotFound, want: true},
+		{name: "帶參數的副本", err: ErrInvalidScope.WithArgs("users:manage"), target: ErrInvalidScope, want: true},
+		{name: "附帶原因的副本", err: ErrInvalidImage.Wrap(errors.New("unexpected EOF")), target: ErrInvalidImage, want: true},
+		{name: "副本的副本", err: ErrAccountLocked.WithRetryAfter(time.Minute).WithArgs(60), target: ErrAccountLocked, want: true},
+		{name: "代碼相同的不同錯誤", err: ErrInvalidMFALoginCode, target: ErrInvalidMFACode, want: false},
+		{name: "不同錯誤", err: ErrUserNotFound, target: ErrSessionNotFound, want: false},
+		{name: "一般錯誤", err: errors.New("user not found"), target: ErrUserNotFound, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := errors.Is(tt.err, tt.target); got != tt.want {
+				t.Errorf("errors.Is(%v, %v) = %v, want %v", tt.err, tt.target, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestError_Error(t *testing.T) {
+	cause := errors.New("unexpected EOF")
+	wrapped := ErrInvalidImage.Wrap(cause)
+
+	if got := wrapped.Error(); got != "invalid image: unexpected EOF" {
+		t.Errorf("Error() = %q", got)
+	}
+	if !errors.Is(wrapped, cause) {
+		t.Error("errors.Is() should find the cause")
+	}
+	if got := ErrInvalidScope.WithArgs("users:manage").Error(); got != "invalid scope: users:manage" {
+		t.Errorf("Error() = %q", got)
+	}
+	// 產生副本不影響哨兵錯誤
+	if ErrInvalidImage.Error() != "invalid image" || len(ErrInvalidScope.Args) != 0 {
+		t.Errorf("sentinel modified: %q %v", ErrInvalidImage.Error(), ErrInvalidScope.Args)
+	}
+}
+
+func TestAs(t *testing.T) {
+	appErr, ok := As(fmt.Errorf("failed to revoke session: %w", ErrSessionNotFound))
+	if !ok || appErr.Code != "SESSION_NOT_FOUND" || appErr.Status != http.StatusNotFound {
+		t.Errorf("As() = %+v, %v", appErr, ok)
+	}
+
+	if _, ok := As(errors.New("database error")); ok {
+		t.Error("As() should not match plain errors")
+	}
+}
+
+func TestError_Message(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    *Error
+		locale string
+		want   string
+	}{
+		{name: "一般訊息", err: ErrUserNotFound, locale: i18n.LocaleZhTW, want: "用戶不存在"},
+		{name: "帶參數的訊息", err: ErrInvalidScope.WithArgs("users:manage"), locale: i18n.LocaleZhTW, want: "包含無效或未擁有的權限：users:manage"},
+		{name: "代碼相同的錯誤共用訊息", err: ErrRefreshTokenExpired, locale: i18n.LocaleZhTW, want: "Refresh token 無效或已過期"},
+		{name: "內部錯誤", err: ErrMFANotFound, locale: i18n.LocaleZhTW, want: "伺服器內部錯誤"},
+		{name: "英文訊息", err: ErrAccountLocked.WithArgs(60), locale: i18n.LocaleEn, want: "Too many failed login attempts; please try again in 60 seconds"},
+		{name: "不支援的語系使用預設語系", err: ErrUserNotFound, locale: "fr", want: "用戶不存在"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.err.Message(tt.locale); got != tt.want {
+				t.Errorf("Message() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// 每個對外的錯誤在每個語系都要有對應的訊息
+func TestMessages_Complete(t *testing.T) {
+	sentinels := []*Error{
+		ErrUserNotFound, ErrUserAlreadyExists, ErrUsernameTaken, ErrEmailAlreadyInUse, ErrEmailAlreadyVerified,
+		ErrUserUpdateConflict, ErrNoProfileChanges, ErrAccountSuspended, ErrPasswordsDoNotMatch, ErrInvalidUsername,
+		ErrInvalidCurrentPassword, ErrEmailUnchanged, ErrInvalidAvatarURL, ErrUnsupportedLocale, ErrInvalidCredentials, ErrInvalidRefreshToken,
+		ErrRefreshTokenReused, ErrSessionNotFound, ErrInvalidMFAToken, ErrInvalidResetToken, ErrInvalidVerificationToken,
+		ErrInvalidMagicLink, ErrInvalidAPIKey, ErrInvalidMFACode, ErrMFAAlreadyEnabled, ErrMFANotEnabled,
+		ErrMFASetupRequired, ErrUnknownProvider, ErrInvalidOIDCState, ErrOIDCExchangeFailed, ErrOIDCProviderUnavailable,
+		ErrOIDCEmailNotVerified, ErrEmailAlreadyRegistered, ErrAvatarNotFound, ErrAvatarTooLarge, ErrUnsupportedImageType,
+		ErrInvalidImage, ErrInvalidAvatarSize, ErrAPIKeyNotFound, ErrAPIKeyLimitReached, ErrInvalidScope,
+		ErrCannotModifySelf, ErrCannotImpersonateAdmin, ErrUserAlreadySuspended, ErrUserNotSuspended, ErrRoleNotFound,
+		ErrInvalidDateRange, ErrInvalidTimeRange, ErrAccountLocked, ErrRateLimitExceeded, ErrRequestTimeout,
+	}
+
+	for _, locale := range i18n.SupportedLocales {
+		for _, err := range sentinels {
+			if !i18n.Has(locale, err.MessageKey) {
+				t.Errorf("missing %s message for %s (%s)", locale, err.MessageKey, err.Error())
+			}
+		}
+	}
+}
@@ -0,0 +1,30 @@
+// Package authtokentest 提供在測試中使用的 token 簽發者，
+// 以固定的 HS256 密鑰與預設的 JWT 設定簽發及驗證 token。
+package authtokentest
+
+import (
+	"smart-learning-backend/pkg/authtoken"
+	"smart-learning-backend/pkg/config"
+	"smart-learning-backend/pkg/utils"
+)
+
+const testSecret = "test-secret-key-for-authtoken-tests"
+
+// NewIssuer 建立使用預設 iss、aud 與有效期限的簽發者
+func NewIssuer() *authtoken.Issuer {
+	return NewIssuerWithConfig(config.Default().JWT)
+}
+
+// NewIssuerWithConfig 以指定的 JWT 設定建立簽發者，簽署金鑰固定為測試用的 HS256 密鑰
+func NewIssuerWithConfig(cfg config.JWTConfig) *authtoken.Issuer {
+	return authtoken.NewIssuer(NewKeyManager(), cfg)
+}
+
+// NewKeyManager 建立只含測試用 HS256 密鑰的金鑰管理器
+func NewKeyManager() *utils.KeyManager {
+	m := utils.NewKeyManager()
+	if err := m.SetSigningKey(utils.NewHMACKey(utils.HMACKeyID, []byte(testSecret))); err != nil {
+		panic("authtokentest: " + err.Error())
+	}
+	return m
+}
@@ -0,0 +1,180 @@
+// Package authtoken 簽發與驗證本服務的 JWT access token
+package authtoken
+
+import (
+	"fmt"
+	"smart-learning-backend/pkg/config"
+	"smart-learning-backend/pkg/utils"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type Claims struct {
+	UserID    int    `json:"user_id"`
+	Email     string `json:"email"`
+	Username  string `json:"username"`
+	SessionID string `json:"sid,omitempty"`
+	// Role 與 Permissions 於簽發時由資料庫載入，角色變更在下一次換發 token 後生效
+	Role        string   `json:"role,omitempty"`
+	Permissions []string `json:"permissions,omitempty"`
+	// Purpose 為空代表一般 access token；其他用途（例如 mfa_pending）的 token 不可用於存取 API
+	Purpose string `json:"purpose,omitempty"`
+	// ImpersonatorID 不為 0 時，代表此 token 是該管理員代入用戶身分所簽發
+	ImpersonatorID int `json:"impersonator_id,omitempty"`
+	// Locale 為用戶的偏好語系，為空時依 Accept-Language 決定；與角色相同，變更後於下一次換發 token 生效
+	Locale string `json:"locale,omitempty"`
+	jwt.RegisteredClaims
+}
+
+const (
+	// MFAPendingTokenTTL 密碼驗證通過後，完成兩步驟驗證的期限
+	MFAPendingTokenTTL = 5 * time.Minute
+	// ImpersonationTokenTTL 管理員代入用戶身分的 token 有效期限，不可換發
+	ImpersonationTokenTTL = 15 * time.Minute
+
+	PurposeMFAPending = "mfa_pending"
+)
+
+// Issuer 以金鑰管理器與 JWT 設定簽發及驗證 token。
+// 驗證時只接受相同 iss 且 aud 包含設定之 audience 的 token，
+// 讓透過 JWKS 驗證的其他服務能區分本服務的 access token 與其他簽發者的 token
+type Issuer struct {
+	keys            *utils.KeyManager
+	issuer          string
+	audience        string
+	accessTokenTTL  time.Duration
+	refreshTokenTTL time.Duration
+}
+
+func NewIssuer(keys *utils.KeyManager, cfg config.JWTConfig) *Issuer {
+	return &Issuer{
+		keys:            keys,
+		issuer:          cfg.Issuer,
+		audience:        cfg.Audience,
+		accessTokenTTL:  cfg.AccessTokenTTL,
+		refreshTokenTTL: cfg.RefreshTokenTTL,
+	}
+}
+
+// AccessTokenTTL 回傳 access token 的有效期限，過期後需使用 refresh token 換發
+func (i *Issuer) AccessTokenTTL() time.Duration {
+	return i.accessTokenTTL
+}
+
+// RefreshTokenTTL 回傳 refresh token 的有效期限
+func (i *Issuer) RefreshTokenTTL() time.Duration {
+	return i.refreshTokenTTL
+}
+
+// newRegisteredClaims 建立帶有 jti、iss、aud 與有效期限的標準 claims
+func (i *Issuer) newRegisteredClaims(ttl time.Duration) (jwt.RegisteredClaims, error) {
+	// jti 用於登出時將單一 token 加入撤銷清單
+	jti, err := utils.GenerateRandomID()
+	if err != nil {
+		return jwt.RegisteredClaims{}, err
+	}
+
+	now := time.Now()
+	return jwt.RegisteredClaims{
+		ID:        jti,
+		Issuer:    i.issuer,
+		Audience:  jwt.ClaimStrings{i.audience},
+		ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+		IssuedAt:  jwt.NewNumericDate(now),
+	}, nil
+}
+
+// sign 補上標準 claims 後以目前的簽署金鑰簽發 token
+func (i *Issuer) sign(claims *Claims, ttl time.Duration) (string, error) {
+	registered, err := i.newRegisteredClaims(ttl)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	claims.RegisteredClaims = registered
+
+	tokenString, err := i.keys.Sign(claims)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	return tokenString, nil
+}
+
+func (i *Issuer) GenerateJWT(userID int, email, username, sessionID, role string, permissions []string, locale string) (string, error) {
+	return i.sign(&Claims{
+		UserID:      userID,
+		Email:       email,
+		Username:    username,
+		SessionID:   sessionID,
+		Role:        role,
+		Permissions: permissions,
+		Locale:      locale,
+	}, i.accessTokenTTL)
+}
+
+// GenerateImpersonationJWT 產生管理員代入用戶身分的 access token。
+// token 不屬於任何會話，也沒有對應的 refresh token，到期後需重新申請
+func (i *Issuer) GenerateImpersonationJWT(userID int, email, username, role string, permissions []string, impersonatorID int) (string, error) {
+	return i.sign(&Claims{
+		UserID:         userID,
+		Email:          email,
+		Username:       username,
+		Role:           role,
+		Permissions:    permissions,
+		ImpersonatorID: impersonatorID,
+	}, ImpersonationTokenTTL)
+}
+
+// GenerateMFAPendingToken 產生密碼驗證通過、尚待兩步驟驗證的短效 token
+func (i *Issuer) GenerateMFAPendingToken(userID int) (string, error) {
+	return i.sign(&Claims{
+		UserID:  userID,
+		Purpose: PurposeMFAPending,
+	}, MFAPendingTokenTTL)
+}
+
+// ValidateJWT 驗證 access token；其他用途的 token 一律拒絕
+func (i *Issuer) ValidateJWT(tokenString string) (*Claims, error) {
+	claims, err := i.parse(tokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.Purpose != "" {
+		return nil, fmt.Errorf("invalid token")
+	}
+
+	return claims, nil
+}
+
+// ValidateMFAPendingToken 驗證 GenerateMFAPendingToken 產生的 token
+func (i *Issuer) ValidateMFAPendingToken(tokenString string) (*Claims, error) {
+	claims, err := i.parse(tokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.Purpose != PurposeMFAPending {
+		return nil, fmt.Errorf("invalid token")
+	}
+
+	return claims, nil
+}
+
+func (i *Issuer) parse(tokenString string) (*Claims, error) {
+	claims := &Claims{}
+
+	token, err := jwt.ParseWithClaims(tokenString, claims, i.keys.Keyfunc,
+		jwt.WithValidMethods(i.keys.Algorithms()), jwt.WithIssuer(i.issuer), jwt.WithAudience(i.audience))
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse token: %w", err)
+	}
+
+	if !token.Valid {
+		return nil, fmt.Errorf("invalid token")
+	}
+
+	return claims, nil
+}
@@ -0,0 +1,245 @@
+package authtoken_test
+
+import (
+	"smart-learning-backend/pkg/authtoken"
+	"smart-learning-backend/pkg/authtoken/authtokentest"
+	"smart-learning-backend/pkg/config"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestIssuer_GenerateJWT(t *testing.T) {
+	issuer := authtokentest.NewIssuer()
+
+	userID := 1
+	email := "test@example.com"
+	username := "testuser"
+
+	token, err := issuer.GenerateJWT(userID, email, username, "session-1", "teacher", []string{"content:manage", "learning:read"}, "en")
+	if err != nil {
+		t.Fatalf("GenerateJWT() error = %v", err)
+	}
+	if token == "" {
+		t.Fatal("GenerateJWT() returned empty token")
+	}
+
+	// 驗證生成的 token
+	claims, err := issuer.ValidateJWT(token)
+	if err != nil {
+		t.Fatalf("ValidateJWT() error = %v", err)
+	}
+
+	if claims.UserID != userID || claims.Email != email || claims.Username != username {
+		t.Errorf("ValidateJWT() user = %d %s %s, want %d %s %s", claims.UserID, claims.Email, claims.Username, userID, email, username)
+	}
+	if claims.SessionID != "session-1" {
+		t.Errorf("SessionID = %v, want session-1", claims.SessionID)
+	}
+	if claims.Role != "teacher" {
+		t.Errorf("Role = %v, want teacher", claims.Role)
+	}
+	if len(claims.Permissions) != 2 || claims.Permissions[0] != "content:manage" {
+		t.Errorf("Permissions = %v, want [content:manage learning:read]", claims.Permissions)
+	}
+	if claims.Locale != "en" {
+		t.Errorf("Locale = %v, want en", claims.Locale)
+	}
+	if claims.ID == "" {
+		t.Error("Token is missing jti claim")
+	}
+
+	// 每個 token 應有唯一的 jti
+	other, _ := issuer.GenerateJWT(userID, email, username, "session-1", "student", nil, "")
+	otherClaims, err := issuer.ValidateJWT(other)
+	if err != nil {
+		t.Fatalf("ValidateJWT() error = %v", err)
+	}
+	if otherClaims.ID == claims.ID {
+		t.Error("Tokens share the same jti")
+	}
+
+	// 有效期限為設定的 access token 期限
+	if ttl := claims.ExpiresAt.Sub(claims.IssuedAt.Time); ttl != issuer.AccessTokenTTL() {
+		t.Errorf("token ttl = %v, want %v", ttl, issuer.AccessTokenTTL())
+	}
+}
+
+// 有效期限取自 JWT 設定，而非套件層級的預設值
+func TestIssuer_TTLFromConfig(t *testing.T) {
+	cfg := config.Default().JWT
+	cfg.AccessTokenTTL = 5 * time.Minute
+	cfg.RefreshTokenTTL = 7 * 24 * time.Hour
+	issuer := authtokentest.NewIssuerWithConfig(cfg)
+
+	if issuer.AccessTokenTTL() != 5*time.Minute || issuer.RefreshTokenTTL() != 7*24*time.Hour {
+		t.Errorf("AccessTokenTTL() = %v, RefreshTokenTTL() = %v", issuer.AccessTokenTTL(), issuer.RefreshTokenTTL())
+	}
+
+	token, _ := issuer.GenerateJWT(1, "test@example.com", "testuser", "session-1", "student", nil, "")
+	claims, err := issuer.ValidateJWT(token)
+	if err != nil {
+		t.Fatalf("ValidateJWT() error = %v", err)
+	}
+	if ttl := claims.ExpiresAt.Sub(claims.IssuedAt.Time); ttl != 5*time.Minute {
+		t.Errorf("token ttl = %v, want 5m", ttl)
+	}
+}
+
+func TestIssuer_ValidateJWT(t *testing.T) {
+	keys := authtokentest.NewKeyManager()
+	issuer := authtoken.NewIssuer(keys, config.Default().JWT)
+
+	tests := []struct {
+		name      string
+		token     string
+		wantError bool
+	}{
+		{
+			name: "有效的 token",
+			token: func() string {
+				token, _ := issuer.GenerateJWT(1, "test@example.com", "testuser", "session-1", "student", nil, "")
+				return token
+			}(),
+			wantError: false,
+		},
+		{
+			name:      "無效的 token",
+			token:     "invalid.token.here",
+			wantError: true,
+		},
+		{
+			name:      "空的 token",
+			token:     "",
+			wantError: true,
+		},
+		{
+			name: "過期的 token",
+			token: func() string {
+				claims := &authtoken.Claims{
+					UserID:   1,
+					Email:    "test@example.com",
+					Username: "testuser",
+					RegisteredClaims: jwt.RegisteredClaims{
+						Issuer:    config.Default().JWT.Issuer,
+						Audience:  jwt.ClaimStrings{config.Default().JWT.Audience},
+						ExpiresAt: jwt.NewNumericDate(time.Now().Add(-time.Hour)),
+						IssuedAt:  jwt.NewNumericDate(time.Now().Add(-25 * time.Hour)),
+					},
+				}
+				tokenString, _ := keys.Sign(claims)
+				return tokenString
+			}(),
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := issuer.ValidateJWT(tt.token)
+			if (err != nil) != tt.wantError {
+				t.Errorf("ValidateJWT() error = %v, wantError %v", err, tt.wantError)
+			}
+		})
+	}
+}
+
+func TestIssuer_MFAPendingToken(t *testing.T) {
+	issuer := authtokentest.NewIssuer()
+
+	pending, err := issuer.GenerateMFAPendingToken(1)
+	if err != nil {
+		t.Fatalf("GenerateMFAPendingToken() error = %v", err)
+	}
+
+	claims, err := issuer.ValidateMFAPendingToken(pending)
+	if err != nil {
+		t.Fatalf("ValidateMFAPendingToken() error = %v", err)
+	}
+	if claims.UserID != 1 || claims.Purpose != authtoken.PurposeMFAPending {
+		t.Errorf("ValidateMFAPendingToken() claims = %+v", claims)
+	}
+
+	// 待驗證 token 不可當作 access token 使用，反之亦然
+	if _, err := issuer.ValidateJWT(pending); err == nil {
+		t.Error("ValidateJWT() accepted an mfa_pending token")
+	}
+
+	access, _ := issuer.GenerateJWT(1, "test@example.com", "testuser", "session-1", "student", nil, "")
+	if _, err := issuer.ValidateMFAPendingToken(access); err == nil {
+		t.Error("ValidateMFAPendingToken() accepted an access token")
+	}
+}
+
+func TestIssuer_GenerateImpersonationJWT(t *testing.T) {
+	issuer := authtokentest.NewIssuer()
+
+	token, err := issuer.GenerateImpersonationJWT(2, "student@example.com", "student", "student", []string{"learning:read"}, 1)
+	if err != nil {
+		t.Fatalf("GenerateImpersonationJWT() error = %v", err)
+	}
+
+	claims, err := issuer.ValidateJWT(token)
+	if err != nil {
+		t.Fatalf("ValidateJWT() error = %v", err)
+	}
+	if claims.UserID != 2 || claims.ImpersonatorID != 1 || claims.SessionID != "" || claims.ID == "" {
+		t.Errorf("ValidateJWT() claims = %+v", claims)
+	}
+	if ttl := claims.ExpiresAt.Sub(claims.IssuedAt.Time); ttl != authtoken.ImpersonationTokenTTL {
+		t.Errorf("token ttl = %v, want %v", ttl, authtoken.ImpersonationTokenTTL)
+	}
+}
+
+func TestIssuer_IssuerAndAudience(t *testing.T) {
+	keys := authtokentest.NewKeyManager()
+	newIssuer := func(iss, aud string) *authtoken.Issuer {
+		cfg := config.Default().JWT
+		cfg.Issuer, cfg.Audience = iss, aud
+		return authtoken.NewIssuer(keys, cfg)
+	}
+
+	issuer := newIssuer("https://auth.example.com", "learning-api")
+	token, err := issuer.GenerateJWT(1, "test@example.com", "testuser", "session-1", "student", nil, "")
+	if err != nil {
+		t.Fatalf("GenerateJWT() error = %v", err)
+	}
+	claims, err := issuer.ValidateJWT(token)
+	if err != nil {
+		t.Fatalf("ValidateJWT() error = %v", err)
+	}
+	if claims.Issuer != "https://auth.example.com" || len(claims.Audience) != 1 || claims.Audience[0] != "learning-api" {
+		t.Errorf("iss = %q, aud = %v", claims.Issuer, claims.Audience)
+	}
+
+	// 同一把金鑰簽署、但 iss 或 aud 不同的 token 一律拒絕
+	tests := []struct {
+		name     string
+		issuer   string
+		audience string
+	}{
+		{name: "其他簽發者", issuer: "https://other.example.com", audience: "learning-api"},
+		{name: "其他對象", issuer: "https://auth.example.com", audience: "billing-api"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			other, err := newIssuer(tt.issuer, tt.audience).GenerateJWT(1, "test@example.com", "testuser", "session-1", "student", nil, "")
+			if err != nil {
+				t.Fatalf("GenerateJWT() error = %v", err)
+			}
+			if _, err := issuer.ValidateJWT(other); err == nil {
+				t.Error("ValidateJWT() accepted a token for another issuer or audience")
+			}
+		})
+	}
+
+	// 未帶 iss 與 aud 的 token 同樣拒絕
+	legacy, _ := keys.Sign(&authtoken.Claims{
+		UserID:           1,
+		RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute))},
+	})
+	if _, err := issuer.ValidateJWT(legacy); err == nil {
+		t.Error("ValidateJWT() accepted a token without iss and aud")
+	}
+}
@@ -0,0 +1,98 @@
+package authtoken
+
+import (
+	"fmt"
+	"smart-learning-backend/pkg/config"
+	"smart-learning-backend/pkg/utils"
+	"strings"
+)
+
+const (
+	// defaultJWTSecret 僅供本機開發使用，非 debug 模式下拒絕啟動
+	defaultJWTSecret = "your-super-secret-jwt-key-change-in-production"
+	// minJWTSecretLength 為非 debug 模式下 HS256 密鑰的最短長度（256 位元）
+	minJWTSecretLength = 32
+)
+
+// placeholderJWTSecrets 為預設值與範例設定檔中的密鑰，任何人都能以其偽造 token
+var placeholderJWTSecrets = map[string]bool{
+	defaultJWTSecret:       true,
+	"your_jwt_secret_here": true,
+}
+
+// LoadKeyManager 依 JWT 設定建立金鑰管理器：
+//   - SigningKeyFile：目前用於簽署的 RSA/Ed25519 私鑰 PEM 檔（SigningKeyID 可指定 kid）
+//   - VerificationKeyFiles：輪換期間仍接受的舊金鑰 PEM 檔，格式為 kid=path 或 path（kid 為 RFC 7638 thumbprint）；
+//     輪換曾以 SigningKeyID 指定 kid 的金鑰時，需沿用相同的 kid，已簽發的 token 才能通過驗證
+//   - Secret：未設定簽署金鑰時使用 HS256；設定了簽署金鑰時僅用於驗證舊 token
+//
+// 未指定 kid 的 token 以 HS256 密鑰驗證，因此非 debug 模式下只要會載入密鑰（不論用於簽署或驗證），
+// 密鑰為預設值、範例值或短於 minJWTSecretLength 時即回傳錯誤以拒絕啟動。
+func LoadKeyManager(cfg config.JWTConfig, debug bool) (*utils.KeyManager, error) {
+	m := utils.NewKeyManager()
+	secret := cfg.Secret
+
+	for _, entry := range cfg.VerificationKeyFiles {
+		kid, path := parseVerificationKeyEntry(entry)
+		key, err := utils.LoadSigningKeyFromPEM(path, kid)
+		if err != nil {
+			return nil, err
+		}
+		m.AddVerificationKey(key)
+	}
+
+	if cfg.SigningKeyFile != "" {
+		key, err := utils.LoadSigningKeyFromPEM(cfg.SigningKeyFile, cfg.SigningKeyID)
+		if err != nil {
+			return nil, err
+		}
+		if err := m.SetSigningKey(key); err != nil {
+			return nil, err
+		}
+		if secret != "" {
+			if err := checkJWTSecret(secret, debug); err != nil {
+				return nil, err
+			}
+			m.AddVerificationKey(utils.NewHMACKey(utils.HMACKeyID, []byte(secret)))
+		}
+		return m, nil
+	}
+
+	if secret == "" {
+		if !debug {
+			return nil, fmt.Errorf("JWT_SECRET is not set; refusing to start outside debug mode")
+		}
+		secret = defaultJWTSecret
+	}
+	if err := checkJWTSecret(secret, debug); err != nil {
+		return nil, err
+	}
+
+	if err := m.SetSigningKey(utils.NewHMACKey(utils.HMACKeyID, []byte(secret))); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// checkJWTSecret 在非 debug 模式下拒絕預設值、範例值與過短的 HS256 密鑰
+func checkJWTSecret(secret string, debug bool) error {
+	if debug {
+		return nil
+	}
+	if placeholderJWTSecrets[secret] {
+		return fmt.Errorf("JWT_SECRET uses a placeholder value; refusing to start outside debug mode")
+	}
+	if len(secret) < minJWTSecretLength {
+		return fmt.Errorf("JWT_SECRET must be at least %d bytes outside debug mode", minJWTSecretLength)
+	}
+	return nil
+}
+
+// parseVerificationKeyEntry 解析 kid=path 格式的驗證金鑰設定；未指定 kid 時回傳空字串
+func parseVerificationKeyEntry(entry string) (kid, path string) {
+	kid, path, ok := strings.Cut(entry, "=")
+	if !ok || kid == "" || strings.ContainsAny(kid, `/\`) {
+		return "", entry
+	}
+	return kid, path
+}
@@ -0,0 +1,207 @@
+package authtoken
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"smart-learning-backend/pkg/config"
+	"smart-learning-backend/pkg/utils"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// writePEM 將私鑰以 PKCS#8 PEM 格式寫入暫存檔
+func writePEM(t *testing.T, key interface{}) string {
+	t.Helper()
+
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("MarshalPKCS8PrivateKey() error = %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "key.pem")
+	data := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	return path
+}
+
+func testClaims() *Claims {
+	return &Claims{
+		UserID:   1,
+		Email:    "test@example.com",
+		Username: "testuser",
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+		},
+	}
+}
+
+// 以 SigningKeyID 指定 kid 的金鑰輪換為驗證金鑰時，以 kid=path 沿用原本的 kid
+func TestLoadKeyManager_RotateConfiguredKeyID(t *testing.T) {
+	oldRSA, _ := rsa.GenerateKey(rand.Reader, 2048)
+	_, newEd, _ := ed25519.GenerateKey(rand.Reader)
+	oldPath := writePEM(t, oldRSA)
+	newPath := writePEM(t, newEd)
+
+	before, err := LoadKeyManager(config.JWTConfig{SigningKeyFile: oldPath, SigningKeyID: "2024-01"}, false)
+	if err != nil {
+		t.Fatalf("LoadKeyManager() error = %v", err)
+	}
+	oldToken, err := before.Sign(testClaims())
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		entry     string
+		wantValid bool
+	}{
+		{name: "指定 kid", entry: "2024-01=" + oldPath, wantValid: true},
+		{name: "未指定 kid 時使用 thumbprint", entry: oldPath, wantValid: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			after, err := LoadKeyManager(config.JWTConfig{
+				SigningKeyFile:       newPath,
+				SigningKeyID:         "2024-07",
+				VerificationKeyFiles: []string{tt.entry},
+			}, false)
+			if err != nil {
+				t.Fatalf("LoadKeyManager() error = %v", err)
+			}
+
+			_, err = jwt.Parse(oldToken, after.Keyfunc, jwt.WithValidMethods(after.Algorithms()))
+			if (err == nil) != tt.wantValid {
+				t.Errorf("Parse() error = %v, wantValid %v", err, tt.wantValid)
+			}
+		})
+	}
+}
+
+func TestParseVerificationKeyEntry(t *testing.T) {
+	tests := []struct {
+		entry    string
+		wantKid  string
+		wantPath string
+	}{
+		{entry: "/etc/jwt/old.pem", wantKid: "", wantPath: "/etc/jwt/old.pem"},
+		{entry: "2024-01=/etc/jwt/old.pem", wantKid: "2024-01", wantPath: "/etc/jwt/old.pem"},
+		{entry: "/etc/jwt/key=1.pem", wantKid: "", wantPath: "/etc/jwt/key=1.pem"},
+		{entry: "=/etc/jwt/old.pem", wantKid: "", wantPath: "=/etc/jwt/old.pem"},
+	}
+
+	for _, tt := range tests {
+		kid, path := parseVerificationKeyEntry(tt.entry)
+		if kid != tt.wantKid || path != tt.wantPath {
+			t.Errorf("parseVerificationKeyEntry(%q) = %q, %q, want %q, %q", tt.entry, kid, path, tt.wantKid, tt.wantPath)
+		}
+	}
+}
+
+func TestLoadKeyManager(t *testing.T) {
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	keyPath := writePEM(t, edKey)
+
+	tests := []struct {
+		name      string
+		cfg       config.JWTConfig
+		debug     bool
+		wantError bool
+		wantAlg   string
+	}{
+		{
+			name:    "debug 模式可使用預設密鑰",
+			cfg:     config.JWTConfig{},
+			debug:   true,
+			wantAlg: utils.AlgorithmHS256,
+		},
+		{
+			name:      "非 debug 模式拒絕預設密鑰",
+			cfg:       config.JWTConfig{},
+			debug:     false,
+			wantError: true,
+		},
+		{
+			name:      "非 debug 模式拒絕明確設定的預設密鑰",
+			cfg:       config.JWTConfig{Secret: defaultJWTSecret},
+			debug:     false,
+			wantError: true,
+		},
+		{
+			name:      "非 debug 模式拒絕範例設定檔的密鑰",
+			cfg:       config.JWTConfig{Secret: "your_jwt_secret_here"},
+			debug:     false,
+			wantError: true,
+		},
+		{
+			name:      "非 debug 模式拒絕過短的密鑰",
+			cfg:       config.JWTConfig{Secret: "a-real-secret"},
+			debug:     false,
+			wantError: true,
+		},
+		{
+			name:    "非 debug 模式使用自訂密鑰",
+			cfg:     config.JWTConfig{Secret: "a-real-secret-that-is-long-enough-for-hs256"},
+			debug:   false,
+			wantAlg: utils.AlgorithmHS256,
+		},
+		{
+			name:    "使用 PEM 簽署金鑰",
+			cfg:     config.JWTConfig{SigningKeyFile: keyPath},
+			debug:   false,
+			wantAlg: utils.AlgorithmEdDSA,
+		},
+		{
+			name:      "PEM 簽署金鑰搭配預設密鑰",
+			cfg:       config.JWTConfig{SigningKeyFile: keyPath, Secret: defaultJWTSecret},
+			debug:     false,
+			wantError: true,
+		},
+		{
+			name:    "debug 模式 PEM 簽署金鑰搭配預設密鑰",
+			cfg:     config.JWTConfig{SigningKeyFile: keyPath, Secret: defaultJWTSecret},
+			debug:   true,
+			wantAlg: utils.AlgorithmEdDSA,
+		},
+		{
+			name:      "簽署金鑰檔案不存在",
+			cfg:       config.JWTConfig{SigningKeyFile: filepath.Join(t.TempDir(), "missing.pem")},
+			debug:     true,
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := LoadKeyManager(tt.cfg, tt.debug)
+			if (err != nil) != tt.wantError {
+				t.Fatalf("LoadKeyManager() error = %v, wantError %v", err, tt.wantError)
+			}
+			if tt.wantError {
+				return
+			}
+
+			tokenString, err := m.Sign(testClaims())
+			if err != nil {
+				t.Fatalf("Sign() error = %v", err)
+			}
+			token, err := jwt.Parse(tokenString, m.Keyfunc, jwt.WithValidMethods(m.Algorithms()))
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if token.Method.Alg() != tt.wantAlg {
+				t.Errorf("alg = %v, want %v", token.Method.Alg(), tt.wantAlg)
+			}
+		})
+	}
+}
@@ -0,0 +1,179 @@
+// Package blobstore 提供上傳檔案的儲存實作：本機目錄（LocalStore）與 S3 相容的物件儲存（S3Store）。
+package blobstore
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// validKey 檢查 key 為不含 "."、".." 或空白片段的相對路徑，避免寫出儲存目錄之外
+func validKey(key string) bool {
+	if key == "" || strings.ContainsAny(key, "\\\x00") {
+		return false
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return false
+		}
+	}
+	return true
+}
+
+// escapeKey 逐段編碼 key，保留分隔用的 "/"
+func escapeKey(key string) string {
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// LocalStore 將檔案存放在本機目錄，並以 ServeHTTP 提供下載。
+// 設定 signingKey 時，URL 產生附有到期時間與 HMAC 簽章的網址，下載時驗證簽章；否則為靜態網址。
+type LocalStore struct {
+	dir        string
+	baseURL    string
+	signingKey []byte
+	urlTTL     time.Duration
+	now        func() time.Time
+}
+
+// NewLocalStore 建立本機儲存；baseURL 為掛載 ServeHTTP 的外部網址，例如 http://localhost:8080/files
+func NewLocalStore(dir, baseURL string, signingKey []byte, urlTTL time.Duration) *LocalStore {
+	return &LocalStore{
+		dir:        dir,
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		signingKey: signingKey,
+		urlTTL:     urlTTL,
+		now:        time.Now,
+	}
+}
+
+func (s *LocalStore) path(key string) (string, error) {
+	if !validKey(key) {
+		return "", fmt.Errorf("invalid blob key %q", key)
+	}
+	return filepath.Join(s.dir, filepath.FromSlash(key)), nil
+}
+
+func (s *LocalStore) Put(key, contentType string, data []byte) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create blob directory: %w", err)
+	}
+
+	// 先寫入暫存檔再更名，下載端不會讀到寫到一半的檔案
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("failed to create blob: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write blob: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write blob: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return fmt.Errorf("failed to write blob: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write blob: %w", err)
+	}
+
+	return nil
+}
+
+func (s *LocalStore) Delete(key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete blob: %w", err)
+	}
+	return nil
+}
+
+func (s *LocalStore) URL(key string) (string, error) {
+	if !validKey(key) {
+		return "", fmt.Errorf("invalid blob key %q", key)
+	}
+
+	blobURL := s.baseURL + "/" + escapeKey(key)
+	if len(s.signingKey) == 0 {
+		return blobURL, nil
+	}
+
+	expires := strconv.FormatInt(s.now().Add(s.urlTTL).Unix(), 10)
+	query := url.Values{
+		"expires":   {expires},
+		"signature": {s.signature(key, expires)},
+	}
+	return blobURL + "?" + query.Encode(), nil
+}
+
+func (s *LocalStore) signature(key, expires string) string {
+	mac := hmac.New(sha256.New, s.signingKey)
+	mac.Write([]byte(key + "\n" + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// ServeHTTP 提供檔案下載，請求路徑（去除掛載前綴後）即為 key
+func (s *LocalStore) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	key := strings.TrimPrefix(r.URL.Path, "/")
+	path, err := s.path(key)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	if len(s.signingKey) > 0 {
+		expires := r.URL.Query().Get("expires")
+		expiresAt, err := strconv.ParseInt(expires, 10, 64)
+		signature := r.URL.Query().Get("signature")
+		if err != nil || s.now().Unix() > expiresAt || !hmac.Equal([]byte(signature), []byte(s.signature(key, expires))) {
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			return
+		}
+		w.Header().Set("Cache-Control", "private, max-age="+strconv.FormatInt(expiresAt-s.now().Unix(), 10))
+	} else {
+		w.Header().Set("Cache-Control", "public, max-age=86400")
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil || info.IsDir() {
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	http.ServeContent(w, r, info.Name(), info.ModTime(), file)
+}
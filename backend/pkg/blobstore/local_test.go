@@ -0,0 +1,126 @@
+package blobstore
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// serveLocal 以掛載在 /files 的 LocalStore 處理 rawURL 的 GET 請求
+func serveLocal(store *LocalStore, rawURL string) *httptest.ResponseRecorder {
+	u, _ := url.Parse(rawURL)
+	req := httptest.NewRequest(http.MethodGet, u.RequestURI(), nil)
+	w := httptest.NewRecorder()
+	http.StripPrefix("/files", store).ServeHTTP(w, req)
+	return w
+}
+
+func TestLocalStore_PutAndServe(t *testing.T) {
+	dir := t.TempDir()
+	store := NewLocalStore(dir, "http://localhost:8080/files/", nil, 0)
+
+	if err := store.Put("avatars/1/abc/256.jpg", "image/jpeg", []byte("jpeg-data")); err != nil {
+		t.Fatalf("Put() unexpected error = %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "avatars", "1", "abc", "256.jpg"))
+	if err != nil || string(data) != "jpeg-data" {
+		t.Fatalf("stored file = %q, %v", data, err)
+	}
+
+	blobURL, err := store.URL("avatars/1/abc/256.jpg")
+	if err != nil {
+		t.Fatalf("URL() unexpected error = %v", err)
+	}
+	if blobURL != "http://localhost:8080/files/avatars/1/abc/256.jpg" {
+		t.Errorf("URL() = %s, want static url", blobURL)
+	}
+
+	w := serveLocal(store, blobURL)
+	if w.Code != http.StatusOK || w.Body.String() != "jpeg-data" {
+		t.Errorf("GET %s = %d %q", blobURL, w.Code, w.Body.String())
+	}
+	if w.Header().Get("Content-Type") != "image/jpeg" {
+		t.Errorf("Content-Type = %s, want image/jpeg", w.Header().Get("Content-Type"))
+	}
+
+	if err := store.Delete("avatars/1/abc/256.jpg"); err != nil {
+		t.Fatalf("Delete() unexpected error = %v", err)
+	}
+	if err := store.Delete("avatars/1/abc/256.jpg"); err != nil {
+		t.Errorf("Delete() of missing blob error = %v, want nil", err)
+	}
+	if w := serveLocal(store, blobURL); w.Code != http.StatusNotFound {
+		t.Errorf("GET after delete = %d, want 404", w.Code)
+	}
+}
+
+func TestLocalStore_SignedURL(t *testing.T) {
+	store := NewLocalStore(t.TempDir(), "http://localhost:8080/files", []byte("url-signing-key"), 15*time.Minute)
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	store.now = func() time.Time { return now }
+
+	if err := store.Put("avatars/1/abc/64.jpg", "image/jpeg", []byte("thumb")); err != nil {
+		t.Fatal(err)
+	}
+	signedURL, err := store.URL("avatars/1/abc/64.jpg")
+	if err != nil {
+		t.Fatalf("URL() unexpected error = %v", err)
+	}
+	if !strings.Contains(signedURL, "expires=") || !strings.Contains(signedURL, "signature=") {
+		t.Fatalf("URL() = %s, want signed url", signedURL)
+	}
+
+	tests := []struct {
+		name       string
+		url        string
+		after      time.Duration
+		wantStatus int
+	}{
+		{name: "有效的簽章", url: signedURL, wantStatus: http.StatusOK},
+		{name: "缺少簽章", url: "http://localhost:8080/files/avatars/1/abc/64.jpg", wantStatus: http.StatusForbidden},
+		{name: "簽章用於其他檔案", url: strings.Replace(signedURL, "64.jpg", "256.jpg", 1), wantStatus: http.StatusForbidden},
+		{name: "竄改到期時間", url: strings.Replace(signedURL, "expires=1", "expires=2", 1), wantStatus: http.StatusForbidden},
+		{name: "已過期", url: signedURL, after: 16 * time.Minute, wantStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store.now = func() time.Time { return now.Add(tt.after) }
+			if w := serveLocal(store, tt.url); w.Code != tt.wantStatus {
+				t.Errorf("GET %s = %d, want %d", tt.url, w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestLocalStore_InvalidKeys(t *testing.T) {
+	dir := t.TempDir()
+	store := NewLocalStore(filepath.Join(dir, "blobs"), "http://localhost:8080/files", nil, 0)
+	if err := os.WriteFile(filepath.Join(dir, "secret.txt"), []byte("secret"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, key := range []string{"", "/abs.jpg", "../secret.txt", "avatars/../../secret.txt", "avatars//1.jpg", `avatars\1.jpg`} {
+		if err := store.Put(key, "image/jpeg", []byte("x")); err == nil {
+			t.Errorf("Put(%q) expected error", key)
+		}
+		if _, err := store.URL(key); err == nil {
+			t.Errorf("URL(%q) expected error", key)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/files/x", nil)
+	req.URL.Path = "/files/../secret.txt"
+	w := httptest.NewRecorder()
+	http.StripPrefix("/files", store).ServeHTTP(w, req)
+	body, _ := io.ReadAll(w.Body)
+	if w.Code != http.StatusNotFound || strings.Contains(string(body), "secret") {
+		t.Errorf("GET ../secret.txt = %d %q, want 404", w.Code, body)
+	}
+}
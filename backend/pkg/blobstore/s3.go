@@ -0,0 +1,136 @@
+package blobstore
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// maxPresignExpiry 為 S3 預先簽章網址允許的最長效期
+const maxPresignExpiry = 7 * 24 * time.Hour
+
+// S3Config 為 S3 相容物件儲存（AWS S3、MinIO、Cloudflare R2 等）的設定
+type S3Config struct {
+	// Endpoint 為服務網址，例如 https://s3.ap-northeast-1.amazonaws.com 或 http://localhost:9000；
+	// 以路徑形式（{Endpoint}/{Bucket}/{key}）存取 bucket
+	Endpoint        string
+	Region          string
+	Bucket          string
+	AccessKeyID     string
+	SecretAccessKey string
+	// PublicBaseURL 設定時 URL 回傳 {PublicBaseURL}/{key} 的靜態網址（例如公開 bucket 或 CDN），否則回傳預先簽章網址
+	PublicBaseURL string
+	URLTTL        time.Duration
+}
+
+// S3Store 以 S3 REST API 存放檔案，請求以 Signature Version 4 簽署
+type S3Store struct {
+	config   S3Config
+	endpoint *url.URL
+	signer   signer
+	client   *http.Client
+	now      func() time.Time
+}
+
+func NewS3Store(config S3Config) (*S3Store, error) {
+	endpoint, err := url.Parse(strings.TrimRight(config.Endpoint, "/"))
+	if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid S3 endpoint %q", config.Endpoint)
+	}
+	if config.Bucket == "" || config.AccessKeyID == "" || config.SecretAccessKey == "" {
+		return nil, fmt.Errorf("S3 bucket and credentials are required")
+	}
+	if config.Region == "" {
+		config.Region = "us-east-1"
+	}
+	if config.URLTTL <= 0 || config.URLTTL > maxPresignExpiry {
+		return nil, fmt.Errorf("S3 url ttl must be between 1s and %s", maxPresignExpiry)
+	}
+
+	return &S3Store{
+		config:   config,
+		endpoint: endpoint,
+		signer: signer{
+			accessKeyID:     config.AccessKeyID,
+			secretAccessKey: config.SecretAccessKey,
+			region:          config.Region,
+		},
+		client: &http.Client{Timeout: 30 * time.Second},
+		now:    time.Now,
+	}, nil
+}
+
+func (s *S3Store) objectURL(key string) *url.URL {
+	u := *s.endpoint
+	u.Path = s.endpoint.Path + "/" + s.config.Bucket + "/" + key
+	u.RawPath = s.endpoint.EscapedPath() + "/" + uriEncode(s.config.Bucket, true) + "/" + uriEncode(key, false)
+	return &u
+}
+
+func (s *S3Store) Put(key, contentType string, data []byte) error {
+	if !validKey(key) {
+		return fmt.Errorf("invalid blob key %q", key)
+	}
+
+	req, err := http.NewRequest(http.MethodPut, s.objectURL(key).String(), bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to create S3 request: %w", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	sum := sha256.Sum256(data)
+	return s.do(req, hex.EncodeToString(sum[:]), "put", key)
+}
+
+func (s *S3Store) Delete(key string) error {
+	if !validKey(key) {
+		return fmt.Errorf("invalid blob key %q", key)
+	}
+
+	req, err := http.NewRequest(http.MethodDelete, s.objectURL(key).String(), nil)
+	if err != nil {
+		return fmt.Errorf("failed to create S3 request: %w", err)
+	}
+
+	emptySum := sha256.Sum256(nil)
+	return s.do(req, hex.EncodeToString(emptySum[:]), "delete", key)
+}
+
+func (s *S3Store) do(req *http.Request, payloadHash, action, key string) error {
+	s.signer.signRequest(req, payloadHash, s.now())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("S3 %s %s failed: %w", action, key, err)
+	}
+	defer resp.Body.Close()
+
+	// DELETE 不存在的物件時部分實作回傳 404，與 S3 的 204 一樣視為成功
+	if resp.StatusCode/100 == 2 || (req.Method == http.MethodDelete && resp.StatusCode == http.StatusNotFound) {
+		io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	return fmt.Errorf("S3 %s %s failed: %s %s", action, key, resp.Status, strings.TrimSpace(string(body)))
+}
+
+func (s *S3Store) URL(key string) (string, error) {
+	if !validKey(key) {
+		return "", fmt.Errorf("invalid blob key %q", key)
+	}
+
+	if s.config.PublicBaseURL != "" {
+		return strings.TrimRight(s.config.PublicBaseURL, "/") + "/" + escapeKey(key), nil
+	}
+
+	// 簽章時間取整到分鐘，同一分鐘內產生的網址相同，瀏覽器快取才有作用
+	now := s.now().UTC().Truncate(time.Minute)
+	return s.signer.presign(http.MethodGet, s.objectURL(key), s.config.URLTTL, now), nil
+}
@@ -0,0 +1,144 @@
+package blobstore
+
+import (
+	"io"
+	"net/http"
+	"smart-learning-backend/pkg/blobstore/s3test"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestS3Store(t *testing.T, server *s3test.Server, publicBaseURL string) *S3Store {
+	t.Helper()
+
+	store, err := NewS3Store(S3Config{
+		Endpoint:        server.URL,
+		Region:          server.Region,
+		Bucket:          server.Bucket,
+		AccessKeyID:     server.AccessKeyID,
+		SecretAccessKey: server.SecretAccessKey,
+		PublicBaseURL:   publicBaseURL,
+		URLTTL:          15 * time.Minute,
+	})
+	if err != nil {
+		t.Fatalf("NewS3Store() unexpected error = %v", err)
+	}
+	return store
+}
+
+func TestS3Store_PutPresignedURLAndDelete(t *testing.T) {
+	server := s3test.NewServer("avatars")
+	defer server.Close()
+	store := newTestS3Store(t, server, "")
+
+	if err := store.Put("avatars/1/abc/256.jpg", "image/jpeg", []byte("jpeg-data")); err != nil {
+		t.Fatalf("Put() unexpected error = %v", err)
+	}
+	object, ok := server.Object("avatars/1/abc/256.jpg")
+	if !ok || string(object.Data) != "jpeg-data" || object.ContentType != "image/jpeg" {
+		t.Fatalf("stored object = %+v, %v", object, ok)
+	}
+
+	presigned, err := store.URL("avatars/1/abc/256.jpg")
+	if err != nil {
+		t.Fatalf("URL() unexpected error = %v", err)
+	}
+	if !strings.HasPrefix(presigned, server.URL+"/avatars/avatars/1/abc/256.jpg?") || !strings.Contains(presigned, "X-Amz-Signature=") {
+		t.Fatalf("URL() = %s, want presigned url", presigned)
+	}
+
+	resp, err := http.Get(presigned)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || string(body) != "jpeg-data" {
+		t.Errorf("GET presigned url = %d %q", resp.StatusCode, body)
+	}
+
+	// 網址過期後無法下載
+	server.Now = func() time.Time { return time.Now().Add(20 * time.Minute) }
+	resp, err = http.Get(presigned)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Errorf("GET expired presigned url = %d, want 403", resp.StatusCode)
+	}
+	server.Now = time.Now
+
+	if err := store.Delete("avatars/1/abc/256.jpg"); err != nil {
+		t.Fatalf("Delete() unexpected error = %v", err)
+	}
+	if _, ok := server.Object("avatars/1/abc/256.jpg"); ok {
+		t.Error("Delete() did not remove the object")
+	}
+}
+
+func TestS3Store_PublicBaseURL(t *testing.T) {
+	server := s3test.NewServer("avatars")
+	defer server.Close()
+	store := newTestS3Store(t, server, "https://cdn.example.com/")
+
+	got, err := store.URL("avatars/1/abc/64.jpg")
+	if err != nil {
+		t.Fatalf("URL() unexpected error = %v", err)
+	}
+	if got != "https://cdn.example.com/avatars/1/abc/64.jpg" {
+		t.Errorf("URL() = %s, want static url", got)
+	}
+}
+
+func TestS3Store_WrongCredentials(t *testing.T) {
+	server := s3test.NewServer("avatars")
+	defer server.Close()
+	server.SecretAccessKey = "rotated-secret"
+	store := newTestS3Store(t, server, "")
+	store.signer.secretAccessKey = "old-secret"
+
+	err := store.Put("avatars/1/abc/256.jpg", "image/jpeg", []byte("jpeg-data"))
+	if err == nil || !strings.Contains(err.Error(), "SignatureDoesNotMatch") {
+		t.Errorf("Put() error = %v, want SignatureDoesNotMatch", err)
+	}
+}
+
+func TestNewS3Store_InvalidConfig(t *testing.T) {
+	valid := S3Config{
+		Endpoint:        "http://localhost:9000",
+		Bucket:          "avatars",
+		AccessKeyID:     "minio",
+		SecretAccessKey: "minio-secret",
+		URLTTL:          time.Hour,
+	}
+
+	tests := []struct {
+		name   string
+		modify func(c *S3Config)
+	}{
+		{name: "endpoint 不是網址", modify: func(c *S3Config) { c.Endpoint = "localhost:9000" }},
+		{name: "缺少 bucket", modify: func(c *S3Config) { c.Bucket = "" }},
+		{name: "缺少憑證", modify: func(c *S3Config) { c.SecretAccessKey = "" }},
+		{name: "網址效期超過七天", modify: func(c *S3Config) { c.URLTTL = 8 * 24 * time.Hour }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := valid
+			tt.modify(&config)
+			if _, err := NewS3Store(config); err == nil {
+				t.Error("NewS3Store() expected error")
+			}
+		})
+	}
+
+	store, err := NewS3Store(valid)
+	if err != nil {
+		t.Fatalf("NewS3Store() unexpected error = %v", err)
+	}
+	if store.config.Region != "us-east-1" {
+		t.Errorf("default region = %s, want us-east-1", store.config.Region)
+	}
+}
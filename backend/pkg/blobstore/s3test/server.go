@@ -0,0 +1,220 @@
+// Package s3test 提供在測試中使用的 S3 相容物件儲存，
+// 以 httptest.Server 實作路徑形式的 PUT、GET 與 DELETE，並驗證 Signature Version 4 的
+// Authorization 標頭與預先簽章網址。
+package s3test
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Object 為已上傳的物件
+type Object struct {
+	ContentType string
+	Data        []byte
+}
+
+// Server 為測試用的 S3 相容物件儲存，只接受單一 bucket 與一組憑證
+type Server struct {
+	Server          *httptest.Server
+	URL             string
+	Region          string
+	Bucket          string
+	AccessKeyID     string
+	SecretAccessKey string
+
+	// Now 為驗證簽章時間與預先簽章網址效期時使用的時間
+	Now func() time.Time
+
+	mu      sync.Mutex
+	objects map[string]Object
+}
+
+// NewServer 啟動假物件儲存，測試結束時需呼叫 Close
+func NewServer(bucket string) *Server {
+	s := &Server{
+		Region:          "us-east-1",
+		Bucket:          bucket,
+		AccessKeyID:     "AKIDS3TEST",
+		SecretAccessKey: "s3test-secret-access-key",
+		Now:             time.Now,
+		objects:         make(map[string]Object),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	s.URL = s.Server.URL
+	return s
+}
+
+func (s *Server) Close() {
+	s.Server.Close()
+}
+
+// Object 回傳已上傳的物件
+func (s *Server) Object(key string) (Object, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	object, ok := s.objects[key]
+	return object, ok
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	prefix := "/" + s.Bucket + "/"
+	if !strings.HasPrefix(r.URL.Path, prefix) || len(r.URL.Path) == len(prefix) {
+		writeError(w, http.StatusNotFound, "NoSuchBucket")
+		return
+	}
+	key := strings.TrimPrefix(r.URL.Path, prefix)
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "IncompleteBody")
+		return
+	}
+	if code := s.authenticate(r, body); code != "" {
+		writeError(w, http.StatusForbidden, code)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch r.Method {
+	case http.MethodPut:
+		s.objects[key] = Object{ContentType: r.Header.Get("Content-Type"), Data: body}
+		w.WriteHeader(http.StatusOK)
+	case http.MethodGet:
+		object, ok := s.objects[key]
+		if !ok {
+			writeError(w, http.StatusNotFound, "NoSuchKey")
+			return
+		}
+		w.Header().Set("Content-Type", object.ContentType)
+		w.Write(object.Data)
+	case http.MethodDelete:
+		delete(s.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "MethodNotAllowed")
+	}
+}
+
+// authenticate 驗證請求的簽章，失敗時回傳 S3 的錯誤代碼
+func (s *Server) authenticate(r *http.Request, body []byte) string {
+	query := r.URL.Query()
+
+	var credential, signedHeaders, signature, amzDate, payloadHash string
+	if query.Get("X-Amz-Signature") != "" {
+		if query.Get("X-Amz-Algorithm") != "AWS4-HMAC-SHA256" {
+			return "AuthorizationQueryParametersError"
+		}
+		credential = query.Get("X-Amz-Credential")
+		signedHeaders = query.Get("X-Amz-SignedHeaders")
+		signature = query.Get("X-Amz-Signature")
+		amzDate = query.Get("X-Amz-Date")
+		payloadHash = "UNSIGNED-PAYLOAD"
+
+		signedAt, err := time.Parse("20060102T150405Z", amzDate)
+		expires, expiresErr := strconv.Atoi(query.Get("X-Amz-Expires"))
+		if err != nil || expiresErr != nil {
+			return "AuthorizationQueryParametersError"
+		}
+		if s.Now().After(signedAt.Add(time.Duration(expires) * time.Second)) {
+			return "AccessDenied"
+		}
+		query.Del("X-Amz-Signature")
+	} else {
+		fields := map[string]string{}
+		header := strings.TrimPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 ")
+		for _, part := range strings.Split(header, ",") {
+			name, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+			fields[name] = value
+		}
+		credential = fields["Credential"]
+		signedHeaders = fields["SignedHeaders"]
+		signature = fields["Signature"]
+		amzDate = r.Header.Get("X-Amz-Date")
+		payloadHash = r.Header.Get("X-Amz-Content-Sha256")
+
+		sum := sha256.Sum256(body)
+		if payloadHash != hex.EncodeToString(sum[:]) {
+			return "XAmzContentSHA256Mismatch"
+		}
+	}
+
+	parts := strings.Split(credential, "/")
+	if len(parts) != 5 || parts[0] != s.AccessKeyID || parts[2] != s.Region || parts[3] != "s3" || parts[4] != "aws4_request" {
+		return "InvalidAccessKeyId"
+	}
+	if len(amzDate) < 8 || parts[1] != amzDate[:8] {
+		return "SignatureDoesNotMatch"
+	}
+
+	var canonicalHeaders strings.Builder
+	for _, name := range strings.Split(signedHeaders, ";") {
+		value := r.Header.Get(name)
+		if name == "host" {
+			value = r.Host
+		}
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(value) + "\n")
+	}
+
+	canonicalRequest := strings.Join([]string{
+		r.Method,
+		r.URL.EscapedPath(),
+		encodeQuery(query),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	scope := strings.Join(parts[1:], "/")
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := []byte("AWS4" + s.SecretAccessKey)
+	for _, part := range parts[1:] {
+		key = sign(key, part)
+	}
+	if !hmac.Equal([]byte(signature), []byte(hex.EncodeToString(sign(key, stringToSign)))) {
+		return "SignatureDoesNotMatch"
+	}
+	return ""
+}
+
+func sign(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func encodeQuery(query url.Values) string {
+	pairs := []string{}
+	for name, values := range query {
+		for _, value := range values {
+			pairs = append(pairs, escape(name)+"="+escape(value))
+		}
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, "&")
+}
+
+// escape 以 url.QueryEscape 為基礎，空白改為 SigV4 要求的 %20
+func escape(value string) string {
+	return strings.ReplaceAll(url.QueryEscape(value), "+", "%20")
+}
+
+func writeError(w http.ResponseWriter, status int, code string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	fmt.Fprintf(w, "<Error><Code>%s</Code></Error>", code)
+}
@@ -0,0 +1,140 @@
+package blobstore
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	sigV4Algorithm  = "AWS4-HMAC-SHA256"
+	amzDateFormat   = "20060102T150405Z"
+	unsignedPayload = "UNSIGNED-PAYLOAD"
+)
+
+// signer 以 AWS Signature Version 4 簽署 S3 請求，支援 Authorization 標頭與預先簽章網址兩種方式
+type signer struct {
+	accessKeyID     string
+	secretAccessKey string
+	region          string
+}
+
+// signRequest 為請求加上 X-Amz-Date、X-Amz-Content-Sha256 與 Authorization 標頭
+func (s signer) signRequest(req *http.Request, payloadHash string, now time.Time) {
+	amzDate := now.UTC().Format(amzDateFormat)
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		name = strings.ToLower(name)
+		if name == "content-type" || strings.HasPrefix(name, "x-amz-") {
+			headers[name] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+	canonicalHeaders, signedHeaders := canonicalizeHeaders(headers)
+
+	signature := s.signature(req.Method, req.URL.EscapedPath(), canonicalQuery(req.URL.Query()), canonicalHeaders, signedHeaders, payloadHash, amzDate)
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		sigV4Algorithm, s.accessKeyID, s.scope(amzDate), signedHeaders, signature))
+}
+
+// presign 回傳在 expires 之後失效的預先簽章網址
+func (s signer) presign(method string, u *url.URL, expires time.Duration, now time.Time) string {
+	amzDate := now.UTC().Format(amzDateFormat)
+
+	query := u.Query()
+	query.Set("X-Amz-Algorithm", sigV4Algorithm)
+	query.Set("X-Amz-Credential", s.accessKeyID+"/"+s.scope(amzDate))
+	query.Set("X-Amz-Date", amzDate)
+	query.Set("X-Amz-Expires", strconv.Itoa(int(expires.Seconds())))
+	query.Set("X-Amz-SignedHeaders", "host")
+
+	canonicalHeaders, signedHeaders := canonicalizeHeaders(map[string]string{"host": u.Host})
+	signature := s.signature(method, u.EscapedPath(), canonicalQuery(query), canonicalHeaders, signedHeaders, unsignedPayload, amzDate)
+
+	return u.Scheme + "://" + u.Host + u.EscapedPath() + "?" + canonicalQuery(query) + "&X-Amz-Signature=" + signature
+}
+
+func (s signer) scope(amzDate string) string {
+	return amzDate[:8] + "/" + s.region + "/s3/aws4_request"
+}
+
+func (s signer) signature(method, canonicalURI, query, canonicalHeaders, signedHeaders, payloadHash, amzDate string) string {
+	canonicalRequest := strings.Join([]string{method, canonicalURI, query, canonicalHeaders, signedHeaders, payloadHash}, "\n")
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{sigV4Algorithm, amzDate, s.scope(amzDate), hex.EncodeToString(requestHash[:])}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.secretAccessKey), amzDate[:8])
+	key = hmacSHA256(key, s.region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// canonicalizeHeaders 依名稱排序並回傳 "name:value\n" 串接的標頭與以分號分隔的標頭名稱
+func canonicalizeHeaders(headers map[string]string) (string, string) {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonical strings.Builder
+	for _, name := range names {
+		canonical.WriteString(name + ":" + headers[name] + "\n")
+	}
+	return canonical.String(), strings.Join(names, ";")
+}
+
+// canonicalQuery 依名稱與值排序，並以 SigV4 的規則編碼查詢參數
+func canonicalQuery(query url.Values) string {
+	names := make([]string, 0, len(query))
+	for name := range query {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return uriEncode(names[i], true) < uriEncode(names[j], true) })
+
+	pairs := make([]string, 0, len(query))
+	for _, name := range names {
+		values := make([]string, 0, len(query[name]))
+		for _, value := range query[name] {
+			values = append(values, uriEncode(value, true))
+		}
+		sort.Strings(values)
+		for _, value := range values {
+			pairs = append(pairs, uriEncode(name, true)+"="+value)
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+// uriEncode 依 SigV4 規則編碼：僅保留 RFC 3986 的非保留字元，其餘以大寫 %XX 表示
+func uriEncode(value string, encodeSlash bool) string {
+	var encoded strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9', c == '-', c == '_', c == '.', c == '~':
+			encoded.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			encoded.WriteByte(c)
+		default:
+			fmt.Fprintf(&encoded, "%%%02X", c)
+		}
+	}
+	return encoded.String()
+}
@@ -0,0 +1,236 @@
+// Package config 集中載入伺服器設定：預設值、YAML 設定檔、.env 與環境變數，
+// 載入後驗證所有欄位，其他套件由呼叫端注入所需的設定區段，不直接讀取環境變數。
+package config
+
+import "time"
+
+// Config 為伺服器的完整設定。
+//
+// 每個欄位以 yaml 標籤對應設定檔的鍵、以 env 標籤對應環境變數；
+// 標記 secret 的欄位在 --print-config 輸出時遮蔽。
+type Config struct {
+	Server          ServerConfig          `yaml:"server"`
+	Database        DatabaseConfig        `yaml:"database"`
+	JWT             JWTConfig             `yaml:"jwt"`
+	PasswordHashing PasswordHashingConfig `yaml:"password_hashing"`
+	PasswordPolicy  PasswordPolicyConfig  `yaml:"password_policy"`
+	Store           StoreConfig           `yaml:"store"`
+	MFA             MFAConfig             `yaml:"mfa"`
+	OIDC            OIDCConfig            `yaml:"oidc"`
+	Mail            MailConfig            `yaml:"mail"`
+	Blob            BlobConfig            `yaml:"blob"`
+	Log             LogConfig             `yaml:"log"`
+}
+
+// ServerConfig 為 HTTP 伺服器的設定
+type ServerConfig struct {
+	Port    string `yaml:"port" env:"PORT"`
+	GinMode string `yaml:"gin_mode" env:"GIN_MODE"`
+	// TrustedProxies 為信任的代理伺服器；未設定或設為 none 時不信任任何代理
+	TrustedProxies []string `yaml:"trusted_proxies" env:"TRUSTED_PROXIES"`
+	// AppBaseURL 為前端網址，用於郵件中的連結與外部登入的預設回呼網址
+	AppBaseURL string `yaml:"app_base_url" env:"APP_BASE_URL"`
+	// APIBaseURL 為 API 對外的網址，用於本機儲存檔案的下載網址
+	APIBaseURL string `yaml:"api_base_url" env:"API_BASE_URL"`
+	// RequestTimeout 為請求的預設處理時限，0 表示不限制
+	RequestTimeout time.Duration `yaml:"request_timeout" env:"REQUEST_TIMEOUT"`
+	// RouteTimeouts 個別路由的處理時限，覆寫 RequestTimeout
+	RouteTimeouts RouteTimeouts `yaml:"route_timeouts" env:"ROUTE_TIMEOUTS"`
+}
+
+// DatabaseConfig 為 PostgreSQL 連接與連接池的設定；設定 URL 時忽略個別的連接欄位
+type DatabaseConfig struct {
+	URL      string `yaml:"url" env:"DATABASE_URL" secret:"url"`
+	Host     string `yaml:"host" env:"DB_HOST"`
+	Port     string `yaml:"port" env:"DB_PORT"`
+	User     string `yaml:"user" env:"DB_USER"`
+	Password string `yaml:"password" env:"DB_PASSWORD" secret:"true"`
+	Name     string `yaml:"name" env:"DB_NAME"`
+	SSLMode  string `yaml:"ssl_mode" env:"DB_SSL_MODE"`
+
+	MaxOpenConns    int           `yaml:"max_open_conns" env:"DB_MAX_OPEN_CONNS"`
+	MaxIdleConns    int           `yaml:"max_idle_conns" env:"DB_MAX_IDLE_CONNS"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime" env:"DB_CONN_MAX_LIFETIME"`
+	ConnMaxIdleTime time.Duration `yaml:"conn_max_idle_time" env:"DB_CONN_MAX_IDLE_TIME"`
+	// StatementTimeout 為單一 SQL 語句的執行時限，0 表示不限制
+	StatementTimeout time.Duration `yaml:"statement_timeout" env:"DB_STATEMENT_TIMEOUT"`
+}
+
+// JWTConfig 為 access token 的簽署金鑰與有效期限
+type JWTConfig struct {
+	// Secret 為 HS256 密鑰；設定了 SigningKeyFile 時僅用於驗證舊 token
+	Secret string `yaml:"secret" env:"JWT_SECRET" secret:"true"`
+	// SigningKeyFile 為目前用於簽署的 RSA/Ed25519 私鑰 PEM 檔，SigningKeyID 可指定 kid
+	SigningKeyFile string `yaml:"signing_key_file" env:"JWT_SIGNING_KEY_FILE"`
+	SigningKeyID   string `yaml:"signing_key_id" env:"JWT_SIGNING_KEY_ID"`
+	// VerificationKeyFiles 為金鑰輪換期間仍接受的舊金鑰 PEM 檔，可寫為 kid=path 指定 kid
+	VerificationKeyFiles []string `yaml:"verification_key_files" env:"JWT_VERIFICATION_KEY_FILES"`
+	// Issuer 與 Audience 為 token 的 iss 與 aud，驗證時拒絕其他簽發者或對象的 token
+	Issuer   string `yaml:"issuer" env:"JWT_ISSUER"`
+	Audience string `yaml:"audience" env:"JWT_AUDIENCE"`
+	// AccessTokenTTL 為 access token 的有效期限，過期後需使用 refresh token 換發
+	AccessTokenTTL time.Duration `yaml:"access_token_ttl" env:"JWT_EXPIRY"`
+	// RefreshTokenTTL 為 refresh token 的有效期限；會話的 refresh token 全數過期後即需重新登入
+	RefreshTokenTTL time.Duration `yaml:"refresh_token_ttl" env:"JWT_REFRESH_EXPIRY"`
+}
+
+// PasswordHashingConfig 為密碼雜湊的演算法與參數
+type PasswordHashingConfig struct {
+	// Algorithm 為新雜湊使用的演算法：argon2id 或 bcrypt；兩種格式的既有雜湊皆可驗證
+	Algorithm string `yaml:"algorithm" env:"PASSWORD_HASH_ALGORITHM"`
+	// Argon2Memory 單位為 KiB
+	Argon2Memory      int `yaml:"argon2_memory" env:"ARGON2_MEMORY"`
+	Argon2Iterations  int `yaml:"argon2_iterations" env:"ARGON2_ITERATIONS"`
+	Argon2Parallelism int `yaml:"argon2_parallelism" env:"ARGON2_PARALLELISM"`
+	BcryptCost        int `yaml:"bcrypt_cost" env:"BCRYPT_COST"`
+}
+
+// PasswordPolicyConfig 為密碼政策；MaxLength 為 0 表示不限制長度
+type PasswordPolicyConfig struct {
+	MinLength           int `yaml:"min_length" env:"PASSWORD_MIN_LENGTH"`
+	MaxLength           int `yaml:"max_length" env:"PASSWORD_MAX_LENGTH"`
+	MinCharacterClasses int `yaml:"min_character_classes" env:"PASSWORD_MIN_CHARACTER_CLASSES"`
+	MinStrength         int `yaml:"min_strength" env:"PASSWORD_MIN_STRENGTH"`
+	// BreachedList 為 Pwned Passwords 格式的外洩密碼清單（檔案或 range 目錄），未設定時不檢查
+	BreachedList string `yaml:"breached_list" env:"PASSWORD_BREACHED_LIST"`
+}
+
+// StoreConfig 選擇 token 撤銷清單與登入失敗紀錄的儲存方式：postgres 或 memory
+type StoreConfig struct {
+	Token        string `yaml:"token" env:"TOKEN_STORE"`
+	LoginAttempt string `yaml:"login_attempt" env:"LOGIN_ATTEMPT_STORE"`
+}
+
+// MFAConfig 為兩步驟驗證的設定
+type MFAConfig struct {
+	// Issuer 為驗證器 App 顯示的發行者名稱
+	Issuer string `yaml:"issuer" env:"MFA_ISSUER"`
+}
+
+// OIDCConfig 為外部登入的身分提供者。
+// 環境變數以 OIDC_PROVIDERS（以逗號分隔的名稱）列出提供者，
+// 每個提供者以 OIDC_<NAME>_ISSUER、_CLIENT_ID、_CLIENT_SECRET、_REDIRECT_URL、_SCOPES 設定。
+type OIDCConfig struct {
+	Providers []OIDCProviderConfig `yaml:"providers"`
+}
+
+// OIDCProviderConfig 為單一身分提供者的設定；RedirectURL 未設定時為 {AppBaseURL}/auth/oidc/{name}/callback
+type OIDCProviderConfig struct {
+	Name         string   `yaml:"name"`
+	Issuer       string   `yaml:"issuer"`
+	ClientID     string   `yaml:"client_id"`
+	ClientSecret string   `yaml:"client_secret" secret:"true"`
+	RedirectURL  string   `yaml:"redirect_url"`
+	Scopes       []string `yaml:"scopes"`
+}
+
+// MailConfig 為郵件寄送的設定；Driver 為 smtp、file（寫入 Dir）或 log（僅輸出到日誌）
+type MailConfig struct {
+	Driver       string `yaml:"driver" env:"MAIL_DRIVER"`
+	From         string `yaml:"from" env:"MAIL_FROM"`
+	Dir          string `yaml:"dir" env:"MAIL_DIR"`
+	SMTPHost     string `yaml:"smtp_host" env:"SMTP_HOST"`
+	SMTPPort     string `yaml:"smtp_port" env:"SMTP_PORT"`
+	SMTPUsername string `yaml:"smtp_username" env:"SMTP_USERNAME"`
+	SMTPPassword string `yaml:"smtp_password" env:"SMTP_PASSWORD" secret:"true"`
+}
+
+// BlobConfig 為上傳檔案的儲存設定；Driver 為 local（寫入 LocalDir，由 /files/ 提供下載）或 s3
+type BlobConfig struct {
+	Driver   string        `yaml:"driver" env:"BLOB_DRIVER"`
+	URLTTL   time.Duration `yaml:"url_ttl" env:"BLOB_URL_TTL"`
+	LocalDir string        `yaml:"local_dir" env:"BLOB_LOCAL_DIR"`
+	// URLSigningKey 設定後 /files/ 只接受簽章網址
+	URLSigningKey string   `yaml:"url_signing_key" env:"BLOB_URL_SIGNING_KEY" secret:"true"`
+	S3            S3Config `yaml:"s3"`
+}
+
+// S3Config 為 S3 相容儲存服務的設定
+type S3Config struct {
+	Endpoint        string `yaml:"endpoint" env:"S3_ENDPOINT"`
+	Region          string `yaml:"region" env:"S3_REGION"`
+	Bucket          string `yaml:"bucket" env:"S3_BUCKET"`
+	AccessKeyID     string `yaml:"access_key_id" env:"S3_ACCESS_KEY_ID"`
+	SecretAccessKey string `yaml:"secret_access_key" env:"S3_SECRET_ACCESS_KEY" secret:"true"`
+	// PublicBaseURL 於 bucket 公開讀取或經由 CDN 時設定，不再產生簽章網址
+	PublicBaseURL string `yaml:"public_base_url" env:"S3_PUBLIC_BASE_URL"`
+}
+
+// LogConfig 為日誌的設定；Level 為 debug、info、warn 或 error，Format 為 text 或 json
+type LogConfig struct {
+	Level  string `yaml:"level" env:"LOG_LEVEL"`
+	Format string `yaml:"format" env:"LOG_FORMAT"`
+}
+
+// Default 回傳所有欄位的預設值
+func Default() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Port:           "8080",
+			GinMode:        "debug",
+			AppBaseURL:     "http://localhost:5173",
+			APIBaseURL:     "http://localhost:8080",
+			RequestTimeout: 15 * time.Second,
+			RouteTimeouts: RouteTimeouts{
+				"GET /api/v1/users/me/export":         time.Minute,
+				"GET /api/v1/admin/audit-logs":        30 * time.Second,
+				"GET /api/v1/admin/audit-logs/export": 5 * time.Minute,
+			},
+		},
+		Database: DatabaseConfig{
+			Port:             "5432",
+			SSLMode:          "disable",
+			MaxOpenConns:     30,
+			MaxIdleConns:     5,
+			ConnMaxLifetime:  time.Hour,
+			ConnMaxIdleTime:  30 * time.Minute,
+			StatementTimeout: 30 * time.Second,
+		},
+		JWT: JWTConfig{
+			Issuer:          "smart-learning",
+			Audience:        "smart-learning-api",
+			AccessTokenTTL:  15 * time.Minute,
+			RefreshTokenTTL: 30 * 24 * time.Hour,
+		},
+		PasswordHashing: PasswordHashingConfig{
+			Algorithm:         "argon2id",
+			Argon2Memory:      64 * 1024,
+			Argon2Iterations:  3,
+			Argon2Parallelism: 2,
+			BcryptCost:        12,
+		},
+		PasswordPolicy: PasswordPolicyConfig{
+			MinLength:           8,
+			MaxLength:           128,
+			MinCharacterClasses: 2,
+			MinStrength:         2,
+		},
+		Store: StoreConfig{
+			Token:        "postgres",
+			LoginAttempt: "postgres",
+		},
+		MFA: MFAConfig{
+			Issuer: "Smart Learning",
+		},
+		Mail: MailConfig{
+			Driver:   "log",
+			From:     "Smart Learning <noreply@smart-learning.local>",
+			Dir:      "tmp/mail",
+			SMTPPort: "587",
+		},
+		Blob: BlobConfig{
+			Driver:   "local",
+			URLTTL:   15 * time.Minute,
+			LocalDir: "tmp/uploads",
+		},
+		Log: LogConfig{
+			Level:  "info",
+			Format: "text",
+		},
+	}
+}
+
+// Debug 回傳是否以 debug 模式執行
+func (c *Config) Debug() bool {
+	return c.Server.GinMode == "debug"
+}
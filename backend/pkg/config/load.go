@@ -0,0 +1,191 @@
+package config
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+// Load 依序套用預設值、YAML 設定檔與環境變數後驗證設定，後者覆寫前者。
+//
+// path 為 YAML 設定檔路徑，為空時使用環境變數 CONFIG_FILE，兩者皆未設定時不讀取設定檔。
+// 工作目錄中的 .env 會先載入為環境變數，但不覆寫已設定的環境變數。
+// 設定值格式錯誤時回傳 nil 與錯誤；驗證未通過時仍回傳設定（供 --print-config 檢視）與所有錯誤。
+func Load(path string) (*Config, error) {
+	if err := godotenv.Load(); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("讀取 .env 失敗: %w", err)
+	}
+	if path == "" {
+		path = os.Getenv("CONFIG_FILE")
+	}
+	return load(path, os.LookupEnv)
+}
+
+// load 以 lookup 讀取環境變數載入設定，供測試替換
+func load(path string, lookup func(string) (string, bool)) (*Config, error) {
+	cfg := Default()
+
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("讀取設定檔失敗: %w", err)
+		}
+		if err := yaml.Unmarshal(data, cfg); err != nil {
+			return nil, fmt.Errorf("設定檔 %s 格式錯誤: %w", path, err)
+		}
+	}
+
+	env := func(key string) string {
+		value, _ := lookup(key)
+		return strings.TrimSpace(value)
+	}
+	if err := applyEnv(reflect.ValueOf(cfg).Elem(), env); err != nil {
+		return nil, err
+	}
+	applyOIDCEnv(&cfg.OIDC, env)
+	cfg.OIDC.setDefaultRedirectURLs(cfg.Server.AppBaseURL)
+
+	return cfg, cfg.Validate()
+}
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// applyEnv 以有 env 標籤的環境變數覆寫設定；環境變數為空字串時視為未設定
+func applyEnv(v reflect.Value, env func(string) string) error {
+	var errs []error
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Field(i)
+		key := v.Type().Field(i).Tag.Get("env")
+		if key == "" {
+			if field.Kind() == reflect.Struct {
+				if err := applyEnv(field, env); err != nil {
+					errs = append(errs, err)
+				}
+			}
+			continue
+		}
+
+		value := env(key)
+		if value == "" {
+			continue
+		}
+		if err := setField(field, value); err != nil {
+			errs = append(errs, fmt.Errorf("%s 設定無效 %q: %w", key, value, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// setField 將環境變數的字串值轉換為欄位型別；清單以逗號分隔
+func setField(field reflect.Value, value string) error {
+	if field.Addr().Type().Implements(textUnmarshalerType) {
+		return field.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(value))
+	}
+
+	switch {
+	case field.Type() == durationType:
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return errors.New("應為時間長度，例如 30s、15m")
+		}
+		field.SetInt(int64(d))
+	case field.Kind() == reflect.String:
+		field.SetString(value)
+	case field.Kind() == reflect.Int:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return errors.New("應為整數")
+		}
+		field.SetInt(int64(n))
+	case field.Kind() == reflect.Slice && field.Type().Elem().Kind() == reflect.String:
+		field.Set(reflect.ValueOf(splitList(value)))
+	default:
+		return fmt.Errorf("不支援的欄位型別 %s", field.Type())
+	}
+	return nil
+}
+
+// applyOIDCEnv 設定 OIDC_PROVIDERS 時，以環境變數的提供者取代設定檔中的清單
+func applyOIDCEnv(cfg *OIDCConfig, env func(string) string) {
+	names := splitList(env("OIDC_PROVIDERS"))
+	if len(names) == 0 {
+		return
+	}
+
+	providers := make([]OIDCProviderConfig, 0, len(names))
+	for _, name := range names {
+		name = strings.ToLower(name)
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+		providers = append(providers, OIDCProviderConfig{
+			Name:         name,
+			Issuer:       env(prefix + "ISSUER"),
+			ClientID:     env(prefix + "CLIENT_ID"),
+			ClientSecret: env(prefix + "CLIENT_SECRET"),
+			RedirectURL:  env(prefix + "REDIRECT_URL"),
+			Scopes:       strings.Fields(env(prefix + "SCOPES")),
+		})
+	}
+	cfg.Providers = providers
+}
+
+// setDefaultRedirectURLs 為未設定回呼網址的提供者使用前端的預設回呼路徑
+func (c *OIDCConfig) setDefaultRedirectURLs(appBaseURL string) {
+	for i := range c.Providers {
+		if c.Providers[i].RedirectURL == "" {
+			c.Providers[i].RedirectURL = strings.TrimRight(appBaseURL, "/") + "/auth/oidc/" + c.Providers[i].Name + "/callback"
+		}
+	}
+}
+
+// splitList 分割以逗號分隔的清單，略過空白項目
+func splitList(value string) []string {
+	items := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// RouteTimeouts 以「方法 路徑」（例如 "GET /api/v1/admin/audit-logs/export"）為鍵的路由處理時限，
+// 路徑為路由定義的樣式
+type RouteTimeouts map[string]time.Duration
+
+// UnmarshalText 解析以逗號分隔的「方法 路徑=時限」，例如
+// "GET /api/v1/users/me/export=2m,GET /api/v1/admin/audit-logs/export=5m"；
+// 與既有的設定合併，相同路由以新值為準
+func (r *RouteTimeouts) UnmarshalText(text []byte) error {
+	routes := RouteTimeouts{}
+	for route, timeout := range *r {
+		routes[route] = timeout
+	}
+
+	for _, entry := range splitList(string(text)) {
+		route, rawTimeout, ok := strings.Cut(entry, "=")
+		fields := strings.Fields(route)
+		if !ok || len(fields) != 2 || !strings.HasPrefix(fields[1], "/") {
+			return fmt.Errorf("路由時限格式應為「方法 路徑=時限」: %q", entry)
+		}
+
+		timeout, err := time.ParseDuration(strings.TrimSpace(rawTimeout))
+		if err != nil {
+			return fmt.Errorf("路由 %s 的時限無效: %q", route, rawTimeout)
+		}
+		routes[strings.ToUpper(fields[0])+" "+fields[1]] = timeout
+	}
+
+	*r = routes
+	return nil
+}
@@ -0,0 +1,64 @@
+package config
+
+import (
+	"net/url"
+	"reflect"
+
+	"gopkg.in/yaml.v3"
+)
+
+// redactedValue 取代已設定的機密值
+const redactedValue = "******"
+
+// Redacted 回傳遮蔽機密欄位後的副本：標記 secret:"true" 的欄位整個遮蔽，
+// 標記 secret:"url" 的欄位只遮蔽網址中的密碼（顯示為 xxxxx）；未設定的機密欄位保持空白，方便確認是否已設定
+func (c *Config) Redacted() *Config {
+	redacted := *c
+	redact(reflect.ValueOf(&redacted).Elem())
+	return &redacted
+}
+
+// Dump 以 YAML 格式輸出遮蔽機密後的設定，格式與設定檔相同
+func (c *Config) Dump() ([]byte, error) {
+	return yaml.Marshal(c.Redacted())
+}
+
+// redact 遮蔽 v 中的機密欄位；切片會先複製，不影響原本的設定
+func redact(v reflect.Value) {
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Field(i)
+		switch field.Kind() {
+		case reflect.Struct:
+			redact(field)
+		case reflect.Slice:
+			if field.Type().Elem().Kind() != reflect.Struct {
+				continue
+			}
+			copied := reflect.MakeSlice(field.Type(), field.Len(), field.Len())
+			reflect.Copy(copied, field)
+			for j := 0; j < copied.Len(); j++ {
+				redact(copied.Index(j))
+			}
+			field.Set(copied)
+		case reflect.String:
+			if field.String() == "" {
+				continue
+			}
+			switch v.Type().Field(i).Tag.Get("secret") {
+			case "true":
+				field.SetString(redactedValue)
+			case "url":
+				field.SetString(redactURL(field.String()))
+			}
+		}
+	}
+}
+
+// redactURL 以 url.URL.Redacted 遮蔽網址中的密碼；無法解析時整個遮蔽
+func redactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme == "" {
+		return redactedValue
+	}
+	return u.Redacted()
+}
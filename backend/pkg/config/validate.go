@@ -0,0 +1,161 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Validate 檢查所有設定值，回傳所有未通過的項目；錯誤訊息以環境變數名稱標示欄位
+func (c *Config) Validate() error {
+	v := &validator{}
+
+	v.port("PORT", c.Server.Port)
+	v.oneOf("GIN_MODE", c.Server.GinMode, "debug", "release", "test")
+	v.url("APP_BASE_URL", c.Server.AppBaseURL)
+	v.url("API_BASE_URL", c.Server.APIBaseURL)
+	v.nonNegative("REQUEST_TIMEOUT", c.Server.RequestTimeout)
+	for route, timeout := range c.Server.RouteTimeouts {
+		v.nonNegative("ROUTE_TIMEOUTS "+route, timeout)
+	}
+
+	if c.Database.URL == "" {
+		v.required("DB_HOST", c.Database.Host)
+		v.required("DB_NAME", c.Database.Name)
+		v.port("DB_PORT", c.Database.Port)
+	}
+	v.intRange("DB_MAX_OPEN_CONNS", c.Database.MaxOpenConns, 1, 1000)
+	v.intRange("DB_MAX_IDLE_CONNS", c.Database.MaxIdleConns, 0, c.Database.MaxOpenConns)
+	v.nonNegative("DB_CONN_MAX_LIFETIME", c.Database.ConnMaxLifetime)
+	v.nonNegative("DB_CONN_MAX_IDLE_TIME", c.Database.ConnMaxIdleTime)
+	v.nonNegative("DB_STATEMENT_TIMEOUT", c.Database.StatementTimeout)
+
+	v.durationRange("JWT_EXPIRY", c.JWT.AccessTokenTTL, time.Minute, 24*time.Hour)
+	v.durationRange("JWT_REFRESH_EXPIRY", c.JWT.RefreshTokenTTL, time.Hour, 365*24*time.Hour)
+	if c.JWT.RefreshTokenTTL < c.JWT.AccessTokenTTL {
+		v.add("JWT_REFRESH_EXPIRY 不可小於 JWT_EXPIRY")
+	}
+	v.required("JWT_ISSUER", c.JWT.Issuer)
+	v.required("JWT_AUDIENCE", c.JWT.Audience)
+
+	v.oneOf("PASSWORD_HASH_ALGORITHM", c.PasswordHashing.Algorithm, "argon2id", "bcrypt")
+	v.intRange("ARGON2_MEMORY", c.PasswordHashing.Argon2Memory, 8*c.PasswordHashing.Argon2Parallelism, 4*1024*1024)
+	v.intRange("ARGON2_ITERATIONS", c.PasswordHashing.Argon2Iterations, 1, 32)
+	v.intRange("ARGON2_PARALLELISM", c.PasswordHashing.Argon2Parallelism, 1, 255)
+	v.intRange("BCRYPT_COST", c.PasswordHashing.BcryptCost, 4, 31)
+
+	v.intRange("PASSWORD_MIN_LENGTH", c.PasswordPolicy.MinLength, 1, 1024)
+	v.intRange("PASSWORD_MAX_LENGTH", c.PasswordPolicy.MaxLength, 0, 1024)
+	if c.PasswordPolicy.MaxLength > 0 && c.PasswordPolicy.MaxLength < c.PasswordPolicy.MinLength {
+		v.add("PASSWORD_MAX_LENGTH 不可小於 PASSWORD_MIN_LENGTH")
+	}
+	v.intRange("PASSWORD_MIN_CHARACTER_CLASSES", c.PasswordPolicy.MinCharacterClasses, 0, 4)
+	v.intRange("PASSWORD_MIN_STRENGTH", c.PasswordPolicy.MinStrength, 0, 4)
+
+	v.oneOf("TOKEN_STORE", c.Store.Token, "postgres", "memory")
+	v.oneOf("LOGIN_ATTEMPT_STORE", c.Store.LoginAttempt, "postgres", "memory")
+	v.required("MFA_ISSUER", c.MFA.Issuer)
+
+	names := map[string]bool{}
+	for _, provider := range c.OIDC.Providers {
+		prefix := "OIDC_" + strings.ToUpper(provider.Name) + "_"
+		switch {
+		case provider.Name == "":
+			v.add("OIDC 提供者缺少名稱")
+		case names[provider.Name]:
+			v.add(fmt.Sprintf("OIDC 提供者 %s 重複設定", provider.Name))
+		}
+		names[provider.Name] = true
+		v.url(prefix+"ISSUER", provider.Issuer)
+		v.required(prefix+"CLIENT_ID", provider.ClientID)
+		v.url(prefix+"REDIRECT_URL", provider.RedirectURL)
+	}
+
+	v.oneOf("MAIL_DRIVER", c.Mail.Driver, "smtp", "file", "log")
+	v.required("MAIL_FROM", c.Mail.From)
+	switch c.Mail.Driver {
+	case "smtp":
+		v.required("SMTP_HOST", c.Mail.SMTPHost)
+		v.port("SMTP_PORT", c.Mail.SMTPPort)
+	case "file":
+		v.required("MAIL_DIR", c.Mail.Dir)
+	}
+
+	v.oneOf("BLOB_DRIVER", c.Blob.Driver, "local", "s3")
+	v.durationRange("BLOB_URL_TTL", c.Blob.URLTTL, time.Second, 7*24*time.Hour)
+	switch c.Blob.Driver {
+	case "local":
+		v.required("BLOB_LOCAL_DIR", c.Blob.LocalDir)
+	case "s3":
+		v.url("S3_ENDPOINT", c.Blob.S3.Endpoint)
+		v.required("S3_BUCKET", c.Blob.S3.Bucket)
+		v.required("S3_ACCESS_KEY_ID", c.Blob.S3.AccessKeyID)
+		v.required("S3_SECRET_ACCESS_KEY", c.Blob.S3.SecretAccessKey)
+		if c.Blob.S3.PublicBaseURL != "" {
+			v.url("S3_PUBLIC_BASE_URL", c.Blob.S3.PublicBaseURL)
+		}
+	}
+
+	v.oneOf("LOG_LEVEL", c.Log.Level, "debug", "info", "warn", "error")
+	v.oneOf("LOG_FORMAT", c.Log.Format, "text", "json")
+
+	return errors.Join(v.errs...)
+}
+
+// validator 收集驗證錯誤，讓所有設定問題一次回報
+type validator struct {
+	errs []error
+}
+
+func (v *validator) add(message string) {
+	v.errs = append(v.errs, errors.New(message))
+}
+
+func (v *validator) required(key, value string) {
+	if value == "" {
+		v.add(key + " 未設定")
+	}
+}
+
+func (v *validator) oneOf(key, value string, allowed ...string) {
+	for _, candidate := range allowed {
+		if value == candidate {
+			return
+		}
+	}
+	v.add(fmt.Sprintf("%s 應為 %s 其中之一，目前為 %q", key, strings.Join(allowed, "、"), value))
+}
+
+func (v *validator) intRange(key string, value, min, max int) {
+	if value < min || value > max {
+		v.add(fmt.Sprintf("%s 應介於 %d 與 %d 之間，目前為 %d", key, min, max, value))
+	}
+}
+
+func (v *validator) nonNegative(key string, value time.Duration) {
+	if value < 0 {
+		v.add(fmt.Sprintf("%s 不可為負值，目前為 %s", key, value))
+	}
+}
+
+func (v *validator) durationRange(key string, value, min, max time.Duration) {
+	if value < min || value > max {
+		v.add(fmt.Sprintf("%s 應介於 %s 與 %s 之間，目前為 %s", key, min, max, value))
+	}
+}
+
+func (v *validator) port(key, value string) {
+	if port, err := strconv.Atoi(value); err != nil || port < 1 || port > 65535 {
+		v.add(fmt.Sprintf("%s 應為 1 到 65535 的連接埠，目前為 %q", key, value))
+	}
+}
+
+func (v *validator) url(key, value string) {
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		v.add(fmt.Sprintf("%s 應為 http 或 https 網址，目前為 %q", key, value))
+	}
+}
@@ -3,8 +3,10 @@ package database
 import (
 	"database/sql"
 	"fmt"
-	"log"
-	"os"
+	"log/slog"
+	"net/url"
+	"smart-learning-backend/pkg/config"
+	"strings"
 	"time"
 
 	_ "github.com/lib/pq"
@@ -14,25 +16,19 @@ type DB struct {
 	*sql.DB
 }
 
-// NewPostgresConnection 建立 PostgreSQL 連接
-func NewPostgresConnection() (*DB, error) {
-	// 從環境變數構建連接字串
-	databaseURL := os.Getenv("DATABASE_URL")
+// NewPostgresConnection 依設定建立 PostgreSQL 連接
+func NewPostgresConnection(cfg config.DatabaseConfig) (*DB, error) {
+	databaseURL := cfg.URL
 	if databaseURL == "" {
-		// 如果沒有 DATABASE_URL，則從個別環境變數構建
-		host := os.Getenv("DB_HOST")
-		port := os.Getenv("DB_PORT")
-		user := os.Getenv("DB_USER")
-		password := os.Getenv("DB_PASSWORD")
-		dbname := os.Getenv("DB_NAME")
-		sslmode := os.Getenv("DB_SSL_MODE")
-
-		if sslmode == "" {
-			sslmode = "disable"
-		}
-
+		// 如果沒有 DATABASE_URL，則從個別設定構建
 		databaseURL = fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=%s",
-			user, password, host, port, dbname, sslmode)
+			cfg.User, cfg.Password, cfg.Host, cfg.Port, cfg.Name, cfg.SSLMode)
+	}
+
+	// 為連接池的每個連接設定語句執行時限，避免慢查詢長期佔用連接
+	databaseURL, err := withStatementTimeout(databaseURL, cfg.StatementTimeout)
+	if err != nil {
+		return nil, err
 	}
 
 	// 開啟資料庫連接
@@ -42,10 +38,10 @@ func NewPostgresConnection() (*DB, error) {
 	}
 
 	// 設置連接池參數
-	db.SetMaxOpenConns(30)                 // 最大開啟連接數
-	db.SetMaxIdleConns(5)                  // 最大閒置連接數
-	db.SetConnMaxLifetime(time.Hour)       // 連接最大生命週期
-	db.SetConnMaxIdleTime(time.Minute * 30) // 連接最大閒置時間
+	db.SetMaxOpenConns(cfg.MaxOpenConns)       // 最大開啟連接數
+	db.SetMaxIdleConns(cfg.MaxIdleConns)       // 最大閒置連接數
+	db.SetConnMaxLifetime(cfg.ConnMaxLifetime) // 連接最大生命週期
+	db.SetConnMaxIdleTime(cfg.ConnMaxIdleTime) // 連接最大閒置時間
 
 	// 測試連接
 	if err := db.Ping(); err != nil {
@@ -53,11 +49,41 @@ func NewPostgresConnection() (*DB, error) {
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	log.Println("✅ 資料庫連接成功建立")
+	slog.Info("資料庫連接成功建立")
+	if cfg.StatementTimeout > 0 {
+		slog.Info("SQL 語句執行時限", "statement_timeout", cfg.StatementTimeout)
+	}
 
 	return &DB{DB: db}, nil
 }
 
+// withStatementTimeout 在連接字串加入 statement_timeout 參數，由 PostgreSQL 中止超過時限的語句；
+// 連接字串已指定 statement_timeout 時保留原設定。支援 URL 與 key=value 兩種格式。
+func withStatementTimeout(databaseURL string, timeout time.Duration) (string, error) {
+	if timeout <= 0 {
+		return databaseURL, nil
+	}
+	milliseconds := fmt.Sprintf("%d", timeout.Milliseconds())
+
+	if strings.HasPrefix(databaseURL, "postgres://") || strings.HasPrefix(databaseURL, "postgresql://") {
+		u, err := url.Parse(databaseURL)
+		if err != nil {
+			return "", fmt.Errorf("DATABASE_URL 格式無效: %w", err)
+		}
+		query := u.Query()
+		if query.Get("statement_timeout") == "" {
+			query.Set("statement_timeout", milliseconds)
+			u.RawQuery = query.Encode()
+		}
+		return u.String(), nil
+	}
+
+	if strings.Contains(databaseURL, "statement_timeout=") {
+		return databaseURL, nil
+	}
+	return strings.TrimSpace(databaseURL + " statement_timeout=" + milliseconds), nil
+}
+
 // TestConnection 測試資料庫連接並顯示版本資訊
 func (db *DB) TestConnection() error {
 	var version string
@@ -66,7 +92,7 @@ func (db *DB) TestConnection() error {
 		return fmt.Errorf("查詢失敗: %w", err)
 	}
 
-	log.Printf("🗄️ 資料庫版本: %s", version)
+	slog.Info("資料庫版本", "version", version)
 	return nil
 }
 
@@ -74,7 +100,7 @@ func (db *DB) TestConnection() error {
 func (db *DB) Close() {
 	if db.DB != nil {
 		db.DB.Close()
-		log.Println("🔚 資料庫連接已關閉")
+		slog.Info("資料庫連接已關閉")
 	}
 }
 
@@ -84,10 +110,9 @@ func (db *DB) GetStats() sql.DBStats {
 }
 
 // SimpleConnection 簡單連接範例 (用於測試)
-func SimpleConnection() error {
-	databaseURL := os.Getenv("DATABASE_URL")
+func SimpleConnection(databaseURL string) error {
 	if databaseURL == "" {
-		return fmt.Errorf("DATABASE_URL 環境變數未設置")
+		return fmt.Errorf("DATABASE_URL 未設置")
 	}
 
 	db, err := sql.Open("postgres", databaseURL)
@@ -102,6 +127,6 @@ func SimpleConnection() error {
 		return fmt.Errorf("查詢失敗: %w", err)
 	}
 
-	log.Println("🔗 簡單連接成功:", version)
+	slog.Info("簡單連接成功", "version", version)
 	return nil
 }
\ No newline at end of file
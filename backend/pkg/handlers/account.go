@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"smart-learning-backend/pkg/interfaces"
+	"smart-learning-backend/pkg/logging"
+	"smart-learning-backend/pkg/models"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AccountHandler struct {
+	accountService interfaces.AccountServiceInterface
+}
+
+func NewAccountHandler(accountService interfaces.AccountServiceInterface) *AccountHandler {
+	return &AccountHandler{
+		accountService: accountService,
+	}
+}
+
+// DeleteMe 驗證目前密碼，或確認目前會話為剛重新登入建立後排定刪除帳號；寬限期內重新登入即取消
+func (h *AccountHandler) DeleteMe(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	// 未設定密碼的帳號（OIDC、登入連結）可不帶請求內容
+	var req models.DeleteAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		validationFailed(c, bindingErrors(c, err))
+		return
+	}
+
+	result, err := h.accountService.RequestDeletion(c.Request.Context(), userID, c.GetString("session_id"), &req)
+	if err != nil {
+		abortWithError(c, err, tr(c, "account.delete_failed"))
+		return
+	}
+
+	c.JSON(http.StatusAccepted, models.APIResponse{
+		Success: true,
+		Message: tr(c, "account.deletion_scheduled"),
+		Data:    result,
+	})
+}
+
+// ExportMe 以附件下載目前登入用戶的個人資料，format 為 zip（預設，每個區段一個 JSON 檔）或 json
+func (h *AccountHandler) ExportMe(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	format := c.DefaultQuery("format", "zip")
+	if format != "zip" && format != "json" {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: tr(c, "errors.validation_failed"),
+			Errors: map[string][]string{
+				"format": {tr(c, "account.invalid_export_format")},
+			},
+		})
+		return
+	}
+
+	export, err := h.accountService.ExportUserData(c.Request.Context(), userID)
+	if err != nil {
+		abortWithError(c, err, tr(c, "account.export_failed"))
+		return
+	}
+
+	filename := fmt.Sprintf("smart-learning-export-%d-%s.%s", export.Profile.ID, export.ExportedAt.Format("20060102"), format)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Header("Cache-Control", "no-store")
+
+	// 標頭送出後無法再回應錯誤，寫入失敗（通常是用戶端中斷連線）只記錄日誌
+	if format == "json" {
+		c.Header("Content-Type", "application/json; charset=utf-8")
+		c.Status(http.StatusOK)
+		err = writeExportJSON(c.Writer, export)
+	} else {
+		c.Header("Content-Type", "application/zip")
+		c.Status(http.StatusOK)
+		err = writeExportZIP(c.Writer, export)
+	}
+	if err != nil {
+		logging.FromContext(c.Request.Context()).Warn("個人資料匯出寫入失敗", "error", err)
+	}
+}
+
+func writeExportJSON(w io.Writer, data interface{}) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(data)
+}
+
+// exportFile 為 ZIP 匯出中的一個檔案
+type exportFile struct {
+	name string
+	data interface{}
+}
+
+// writeExportZIP 將匯出內容逐一寫入 ZIP 串流，每個區段一個 JSON 檔；功能模組的區段依名稱排序在後
+func writeExportZIP(w io.Writer, export *models.UserDataExport) error {
+	sections := make([]string, 0, len(export.Sections))
+	for name := range export.Sections {
+		sections = append(sections, name)
+	}
+	sort.Strings(sections)
+
+	files := []exportFile{
+		{name: "profile.json", data: export.Profile},
+		{name: "security.json", data: export.Security},
+	}
+	for _, name := range sections {
+		files = append(files, exportFile{name: name + ".json", data: export.Sections[name]})
+	}
+
+	archive := zip.NewWriter(w)
+	for _, file := range files {
+		entry, err := archive.CreateHeader(&zip.FileHeader{
+			Name:     file.name,
+			Method:   zip.Deflate,
+			Modified: export.ExportedAt,
+		})
+		if err != nil {
+			return err
+		}
+		if err := writeExportJSON(entry, file.data); err != nil {
+			return err
+		}
+	}
+	return archive.Close()
+}
@@ -0,0 +1,211 @@
+package handlers
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"smart-learning-backend/pkg/apperrors"
+	"smart-learning-backend/pkg/models"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MockAccountService 實現了 AccountServiceInterface 介面用於測試
+type MockAccountService struct {
+	shouldFailNext string
+}
+
+func NewMockAccountService() *MockAccountService {
+	return &MockAccountService{}
+}
+
+func (m *MockAccountService) RequestDeletion(ctx context.Context, userID int, sessionID string, req *models.DeleteAccountRequest) (*models.AccountDeletionResponse, error) {
+	if req.CurrentPassword == "" && sessionID != "fresh-session" {
+		return nil, apperrors.ErrReauthenticationRequired.WithArgs(5)
+	}
+	if req.CurrentPassword != "" && req.CurrentPassword != "password123" {
+		return nil, apperrors.ErrInvalidCurrentPassword
+	}
+	return &models.AccountDeletionResponse{DeletionScheduledAt: time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)}, nil
+}
+
+func (m *MockAccountService) ExportUserData(ctx context.Context, userID int) (*models.UserDataExport, error) {
+	if m.shouldFailNext == "ExportUserData" {
+		m.shouldFailNext = ""
+		return nil, errors.New("failed to list sessions: database error")
+	}
+	return &models.UserDataExport{
+		ExportedAt: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+		Profile:    &models.User{ID: userID, Email: "test@example.com", Username: "testuser"},
+		Security:   models.AccountSecurityExport{MFAEnabled: true},
+		Sections: map[string]interface{}{
+			"word_lists": map[string]interface{}{"lists": []string{"TOEIC 核心單字"}},
+		},
+	}, nil
+}
+
+func (m *MockAccountService) SetShouldFailNext(method string) {
+	m.shouldFailNext = method
+}
+
+func TestAccountHandler_DeleteMe(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		sessionID      string
+		expectedStatus int
+		expectedField  string
+		expectedCode   string
+	}{
+		{name: "申請成功", body: `{"current_password":"password123"}`, expectedStatus: http.StatusAccepted},
+		{name: "目前密碼錯誤", body: `{"current_password":"wrong"}`, expectedStatus: http.StatusBadRequest, expectedField: "current_password"},
+		{name: "剛重新登入不需密碼", sessionID: "fresh-session", expectedStatus: http.StatusAccepted},
+		{name: "未提供密碼且未重新登入", body: `{}`, sessionID: "old-session", expectedStatus: http.StatusForbidden, expectedCode: "REAUTHENTICATION_REQUIRED"},
+		{name: "請求格式錯誤", body: `{"current_password":`, expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := setupGin()
+			handler := NewAccountHandler(NewMockAccountService())
+			r.DELETE("/users/me", func(c *gin.Context) {
+				c.Set("user_id", 1)
+				c.Set("session_id", tt.sessionID)
+				handler.DeleteMe(c)
+			})
+
+			req, _ := http.NewRequest("DELETE", "/users/me", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+
+			var response models.APIResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatalf("Failed to unmarshal response: %v", err)
+			}
+			if tt.expectedField != "" {
+				errs, _ := response.Errors.(map[string]interface{})
+				if _, ok := errs[tt.expectedField]; !ok {
+					t.Errorf("Expected validation error for %s, got %v", tt.expectedField, response.Errors)
+				}
+			}
+			if tt.expectedCode != "" && (response.Error == nil || response.Error.Code != tt.expectedCode) {
+				t.Errorf("Expected error code %s, got %+v", tt.expectedCode, response.Error)
+			}
+			if tt.expectedStatus == http.StatusAccepted {
+				data, _ := response.Data.(map[string]interface{})
+				if data["deletion_scheduled_at"] != "2025-02-01T00:00:00Z" {
+					t.Errorf("deletion_scheduled_at = %v", data["deletion_scheduled_at"])
+				}
+			}
+		})
+	}
+}
+
+func serveExportRequest(t *testing.T, mockService *MockAccountService, query string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	r := setupGin()
+	handler := NewAccountHandler(mockService)
+	r.GET("/users/me/export", func(c *gin.Context) {
+		c.Set("user_id", 1)
+		handler.ExportMe(c)
+	})
+
+	req, _ := http.NewRequest("GET", "/users/me/export"+query, nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestAccountHandler_ExportMe_ZIP(t *testing.T) {
+	w := serveExportRequest(t, NewMockAccountService(), "")
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	if got := w.Header().Get("Content-Type"); got != "application/zip" {
+		t.Errorf("Content-Type = %s, want application/zip", got)
+	}
+	if got := w.Header().Get("Content-Disposition"); got != `attachment; filename="smart-learning-export-1-20250102.zip"` {
+		t.Errorf("Content-Disposition = %s", got)
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(w.Body.Bytes()), int64(w.Body.Len()))
+	if err != nil {
+		t.Fatalf("response is not a zip archive: %v", err)
+	}
+	files := map[string]map[string]interface{}{}
+	for _, file := range archive.File {
+		rc, err := file.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var content map[string]interface{}
+		if err := json.NewDecoder(rc).Decode(&content); err != nil {
+			t.Errorf("%s is not valid JSON: %v", file.Name, err)
+		}
+		rc.Close()
+		files[file.Name] = content
+	}
+
+	if files["profile.json"]["email"] != "test@example.com" {
+		t.Errorf("profile.json = %v", files["profile.json"])
+	}
+	if files["security.json"]["mfa_enabled"] != true {
+		t.Errorf("security.json = %v", files["security.json"])
+	}
+	if lists, _ := files["word_lists.json"]["lists"].([]interface{}); len(lists) != 1 {
+		t.Errorf("word_lists.json = %v", files["word_lists.json"])
+	}
+}
+
+func TestAccountHandler_ExportMe(t *testing.T) {
+	t.Run("JSON 格式", func(t *testing.T) {
+		w := serveExportRequest(t, NewMockAccountService(), "?format=json")
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", w.Code)
+		}
+
+		var export models.UserDataExport
+		if err := json.Unmarshal(w.Body.Bytes(), &export); err != nil {
+			t.Fatalf("Failed to unmarshal export: %v", err)
+		}
+		if export.Profile == nil || export.Profile.Username != "testuser" || !export.Security.MFAEnabled {
+			t.Errorf("export = %+v", export)
+		}
+		if !strings.HasSuffix(w.Header().Get("Content-Disposition"), `.json"`) {
+			t.Errorf("Content-Disposition = %s", w.Header().Get("Content-Disposition"))
+		}
+	})
+
+	t.Run("不支援的格式", func(t *testing.T) {
+		w := serveExportRequest(t, NewMockAccountService(), "?format=xml")
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", w.Code)
+		}
+	})
+
+	t.Run("讀取資料失敗", func(t *testing.T) {
+		mockService := NewMockAccountService()
+		mockService.SetShouldFailNext("ExportUserData")
+		w := serveExportRequest(t, mockService, "")
+		if w.Code != http.StatusInternalServerError {
+			t.Errorf("Expected status 500, got %d", w.Code)
+		}
+		if w.Header().Get("Content-Disposition") != "" {
+			t.Error("error response should not be sent as an attachment")
+		}
+	})
+}
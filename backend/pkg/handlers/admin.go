@@ -0,0 +1,237 @@
+package handlers
+
+import (
+	"net/http"
+	"smart-learning-backend/pkg/interfaces"
+	"smart-learning-backend/pkg/models"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AdminHandler struct {
+	authService  interfaces.AuthServiceInterface
+	adminService interfaces.AdminServiceInterface
+	auditLog     interfaces.AuditRecorderInterface
+}
+
+func NewAdminHandler(
+	authService interfaces.AuthServiceInterface,
+	adminService interfaces.AdminServiceInterface,
+	auditLog interfaces.AuditRecorderInterface,
+) *AdminHandler {
+	return &AdminHandler{
+		authService:  authService,
+		adminService: adminService,
+		auditLog:     auditLog,
+	}
+}
+
+// ListUsers 搜尋並分頁列出用戶
+func (h *AdminHandler) ListUsers(c *gin.Context) {
+	var req models.ListUsersRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		validationErrors := bindingErrors(c, err)
+		if len(validationErrors) == 0 {
+			// 日期或數字無法解析時不會產生欄位錯誤
+			validationErrors = map[string][]string{
+				"query": {tr(c, "admin.invalid_user_query")},
+			}
+		}
+		validationFailed(c, validationErrors)
+		return
+	}
+
+	result, err := h.adminService.ListUsers(c.Request.Context(), &req)
+	if err != nil {
+		abortWithError(c, err, tr(c, "admin.list_users_failed"))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    result,
+	})
+}
+
+// GetUser 取得單一用戶的資料
+func (h *AdminHandler) GetUser(c *gin.Context) {
+	userID, ok := userIDParam(c)
+	if !ok {
+		return
+	}
+
+	user, err := h.adminService.GetUser(c.Request.Context(), userID)
+	if err != nil {
+		abortWithError(c, err, tr(c, "admin.list_users_failed"))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    user,
+	})
+}
+
+// SuspendUser 停用帳號並登出該用戶所有裝置
+func (h *AdminHandler) SuspendUser(c *gin.Context) {
+	adminID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	userID, ok := userIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req models.SuspendUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationFailed(c, bindingErrors(c, err))
+		return
+	}
+
+	user, err := h.adminService.SuspendUser(c.Request.Context(), adminID, userID, &req)
+	if err != nil {
+		abortWithError(c, err, tr(c, "admin.suspend_failed"))
+		return
+	}
+	recordAudit(c, h.auditLog, models.AuditEventAccountSuspended, userID, map[string]string{"reason": req.Reason})
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: tr(c, "admin.suspended"),
+		Data:    user,
+	})
+}
+
+// UnsuspendUser 解除帳號停用
+func (h *AdminHandler) UnsuspendUser(c *gin.Context) {
+	userID, ok := userIDParam(c)
+	if !ok {
+		return
+	}
+
+	user, err := h.adminService.UnsuspendUser(c.Request.Context(), userID)
+	if err != nil {
+		abortWithError(c, err, tr(c, "admin.unsuspend_failed"))
+		return
+	}
+	recordAudit(c, h.auditLog, models.AuditEventAccountUnsuspended, userID, nil)
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: tr(c, "admin.unsuspended"),
+		Data:    user,
+	})
+}
+
+// ForcePasswordReset 讓用戶原密碼失效、登出所有裝置，並寄送密碼重設連結
+func (h *AdminHandler) ForcePasswordReset(c *gin.Context) {
+	userID, ok := userIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.adminService.ForcePasswordReset(c.Request.Context(), userID); err != nil {
+		abortWithError(c, err, tr(c, "admin.reset_password_failed"))
+		return
+	}
+	recordAudit(c, h.auditLog, models.AuditEventPasswordChange, userID, map[string]string{"method": "admin_reset"})
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: tr(c, "admin.password_reset"),
+	})
+}
+
+// ChangeRole 變更用戶角色，用戶需重新登入
+func (h *AdminHandler) ChangeRole(c *gin.Context) {
+	adminID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	userID, ok := userIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req models.ChangeRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationFailed(c, bindingErrors(c, err))
+		return
+	}
+
+	user, err := h.adminService.ChangeRole(c.Request.Context(), adminID, userID, &req)
+	if err != nil {
+		abortWithError(c, err, tr(c, "admin.change_role_failed"))
+		return
+	}
+	recordAudit(c, h.auditLog, models.AuditEventRoleChange, userID, map[string]string{"role": req.Role})
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: tr(c, "admin.role_changed"),
+		Data:    user,
+	})
+}
+
+// Impersonate 簽發以用戶身分存取 API 的短效 token，供客服排查問題
+func (h *AdminHandler) Impersonate(c *gin.Context) {
+	adminID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	userID, ok := userIDParam(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.adminService.Impersonate(c.Request.Context(), adminID, userID)
+	if err != nil {
+		abortWithError(c, err, tr(c, "admin.impersonate_failed"))
+		return
+	}
+	recordAudit(c, h.auditLog, models.AuditEventImpersonation, userID, nil)
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: tr(c, "admin.impersonation_started"),
+		Data:    result,
+	})
+}
+
+// UnlockUser 解除用戶因多次登入失敗造成的鎖定
+func (h *AdminHandler) UnlockUser(c *gin.Context) {
+	userID, ok := userIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.authService.UnlockAccount(c.Request.Context(), userID); err != nil {
+		abortWithError(c, err, tr(c, "admin.unlock_failed"))
+		return
+	}
+	recordAudit(c, h.auditLog, models.AuditEventAccountUnlocked, userID, nil)
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: tr(c, "admin.unlocked"),
+	})
+}
+
+// userIDParam 解析路徑中的用戶 ID；格式不正確時回應 400 並回傳 false
+func userIDParam(c *gin.Context) (int, bool) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || userID <= 0 {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: tr(c, "errors.validation_failed"),
+			Errors: map[string][]string{
+				"id": {tr(c, "admin.invalid_user_id")},
+			},
+		})
+		return 0, false
+	}
+	return userID, true
+}
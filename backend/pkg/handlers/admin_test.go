@@ -0,0 +1,440 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"smart-learning-backend/pkg/apperrors"
+	"smart-learning-backend/pkg/interfaces"
+	"smart-learning-backend/pkg/middleware"
+	"smart-learning-backend/pkg/models"
+	"smart-learning-backend/pkg/services"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MockAdminService 實現了 AdminServiceInterface 介面用於測試；用戶 1 為學生、用戶 2 為管理員
+type MockAdminService struct {
+	lastList       *models.ListUsersRequest
+	shouldFailNext string
+}
+
+var _ interfaces.AdminServiceInterface = (*MockAdminService)(nil)
+
+func NewMockAdminService() *MockAdminService {
+	return &MockAdminService{}
+}
+
+func (m *MockAdminService) ListUsers(ctx context.Context, req *models.ListUsersRequest) (*models.UserListResponse, error) {
+	if m.shouldFailNext == "ListUsers" {
+		m.shouldFailNext = ""
+		return nil, errors.New("database error")
+	}
+	if req.CreatedFrom != nil && req.CreatedTo != nil && req.CreatedFrom.After(*req.CreatedTo) {
+		return nil, apperrors.ErrInvalidDateRange
+	}
+
+	m.lastList = req
+	return &models.UserListResponse{Users: []models.User{createTestUser()}, Page: 1, PageSize: 20, Total: 1}, nil
+}
+
+func (m *MockAdminService) GetUser(ctx context.Context, userID int) (*models.User, error) {
+	if userID != 1 {
+		return nil, apperrors.ErrUserNotFound
+	}
+	user := createTestUser()
+	return &user, nil
+}
+
+func (m *MockAdminService) SuspendUser(ctx context.Context, adminID, userID int, req *models.SuspendUserRequest) (*models.User, error) {
+	if adminID == userID {
+		return nil, apperrors.ErrCannotModifySelf
+	}
+	user, err := m.GetUser(context.Background(), userID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	user.SuspendedAt = &now
+	user.SuspensionReason = &req.Reason
+	return user, nil
+}
+
+func (m *MockAdminService) UnsuspendUser(ctx context.Context, userID int) (*models.User, error) {
+	if _, err := m.GetUser(context.Background(), userID); err != nil {
+		return nil, err
+	}
+	return nil, apperrors.ErrUserNotSuspended
+}
+
+func (m *MockAdminService) ForcePasswordReset(ctx context.Context, userID int) error {
+	_, err := m.GetUser(context.Background(), userID)
+	return err
+}
+
+func (m *MockAdminService) ChangeRole(ctx context.Context, adminID, userID int, req *models.ChangeRoleRequest) (*models.User, error) {
+	if req.Role == "owner" {
+		return nil, apperrors.ErrRoleNotFound
+	}
+	user, err := m.GetUser(context.Background(), userID)
+	if err != nil {
+		return nil, err
+	}
+	user.Role = req.Role
+	return user, nil
+}
+
+func (m *MockAdminService) Impersonate(ctx context.Context, adminID, userID int) (*models.ImpersonationResponse, error) {
+	if userID == 2 {
+		return nil, apperrors.ErrCannotImpersonateAdmin
+	}
+	user, err := m.GetUser(context.Background(), userID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.ImpersonationResponse{User: user, Token: "impersonation-token", ExpiresIn: 900}, nil
+}
+
+func (m *MockAdminService) SetShouldFailNext(method string) {
+	m.shouldFailNext = method
+}
+
+func TestAdminHandler_UnlockUser(t *testing.T) {
+	tests := []struct {
+		name           string
+		userID         string
+		setupService   func(*MockAuthService)
+		expectedStatus int
+		expectedCode   string
+	}{
+		{
+			name:   "成功解除鎖定",
+			userID: "1",
+			setupService: func(m *MockAuthService) {
+				m.AddUser(createTestUser())
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "無效的用戶 ID",
+			userID:         "abc",
+			setupService:   func(m *MockAuthService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "用戶不存在",
+			userID:         "99",
+			setupService:   func(m *MockAuthService) {},
+			expectedStatus: http.StatusNotFound,
+			expectedCode:   "USER_NOT_FOUND",
+		},
+		{
+			name:   "服務錯誤",
+			userID: "1",
+			setupService: func(m *MockAuthService) {
+				m.AddUser(createTestUser())
+				m.SetShouldFailNext("UnlockAccount")
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedCode:   "INTERNAL_SERVER_ERROR",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := setupGin()
+			mockService := NewMockAuthService()
+			tt.setupService(mockService)
+			handler := NewAdminHandler(mockService, NewMockAdminService(), NewMockAuditLogService())
+
+			r.POST("/admin/users/:id/unlock", handler.UnlockUser)
+
+			req, _ := http.NewRequest("POST", "/admin/users/"+tt.userID+"/unlock", nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+
+			var response models.APIResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatalf("Failed to unmarshal response: %v", err)
+			}
+			if tt.expectedCode != "" && (response.Error == nil || response.Error.Code != tt.expectedCode) {
+				t.Errorf("Expected error code %s, got %+v", tt.expectedCode, response.Error)
+			}
+		})
+	}
+}
+
+func TestAdminHandler_ListUsers(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		setupService   func(*MockAdminService)
+		expectedStatus int
+		expectedCode   string
+		expectedField  string
+	}{
+		{name: "成功查詢", query: "?q=test&role=student&learning_level=3&created_from=2025-01-01&created_to=2025-01-31&sort=-username&page=2&page_size=50", expectedStatus: http.StatusOK},
+		{name: "日期格式錯誤", query: "?created_from=2025/01/01", expectedStatus: http.StatusBadRequest, expectedField: "query"},
+		{name: "每頁筆數超過上限", query: "?page_size=500", expectedStatus: http.StatusBadRequest, expectedField: "pagesize"},
+		{name: "不支援的排序欄位", query: "?sort=password_hash", expectedStatus: http.StatusBadRequest, expectedField: "sort"},
+		{name: "不支援的狀態", query: "?status=deleted", expectedStatus: http.StatusBadRequest, expectedField: "status"},
+		{name: "起始日期晚於結束日期", query: "?created_from=2025-02-01&created_to=2025-01-01", expectedStatus: http.StatusBadRequest, expectedField: "created_from"},
+		{
+			name:           "服務錯誤",
+			setupService:   func(m *MockAdminService) { m.SetShouldFailNext("ListUsers") },
+			expectedStatus: http.StatusInternalServerError,
+			expectedCode:   "INTERNAL_SERVER_ERROR",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := setupGin()
+			mockService := NewMockAdminService()
+			if tt.setupService != nil {
+				tt.setupService(mockService)
+			}
+			r.GET("/admin/users", NewAdminHandler(NewMockAuthService(), mockService, NewMockAuditLogService()).ListUsers)
+
+			req, _ := http.NewRequest("GET", "/admin/users"+tt.query, nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+
+			var response models.APIResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatalf("Failed to unmarshal response: %v", err)
+			}
+			if tt.expectedCode != "" && (response.Error == nil || response.Error.Code != tt.expectedCode) {
+				t.Errorf("Expected error code %s, got %+v", tt.expectedCode, response.Error)
+			}
+			if tt.expectedField != "" {
+				errs, _ := response.Errors.(map[string]interface{})
+				if _, ok := errs[tt.expectedField]; !ok {
+					t.Errorf("Expected validation error for %s, got %v", tt.expectedField, response.Errors)
+				}
+			}
+		})
+	}
+
+	t.Run("查詢參數完整傳入服務", func(t *testing.T) {
+		r := setupGin()
+		mockService := NewMockAdminService()
+		r.GET("/admin/users", NewAdminHandler(NewMockAuthService(), mockService, NewMockAuditLogService()).ListUsers)
+
+		req, _ := http.NewRequest("GET", "/admin/users?q=test&learning_level=3&created_to=2025-01-31&status=suspended&page=2", nil)
+		r.ServeHTTP(httptest.NewRecorder(), req)
+
+		got := mockService.lastList
+		if got == nil || got.Query != "test" || got.LearningLevel != 3 || got.Status != models.UserStatusSuspended || got.Page != 2 {
+			t.Fatalf("ListUsers() received %+v", got)
+		}
+		if got.CreatedTo == nil || !got.CreatedTo.Equal(time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC)) {
+			t.Errorf("created_to = %v, want 2025-01-31 UTC", got.CreatedTo)
+		}
+	})
+}
+
+func TestAdminHandler_UserActions(t *testing.T) {
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		body           string
+		expectedStatus int
+		expectedCode   string
+		expectedField  string
+		expectedEvent  string
+	}{
+		{name: "查詢用戶", method: "GET", path: "/admin/users/1", expectedStatus: http.StatusOK},
+		{name: "查詢不存在的用戶", method: "GET", path: "/admin/users/99", expectedStatus: http.StatusNotFound, expectedCode: "USER_NOT_FOUND"},
+		{name: "無效的用戶 ID", method: "GET", path: "/admin/users/abc", expectedStatus: http.StatusBadRequest, expectedField: "id"},
+		{name: "停用帳號", method: "POST", path: "/admin/users/1/suspend", body: `{"reason":"spam"}`, expectedStatus: http.StatusOK, expectedEvent: models.AuditEventAccountSuspended},
+		{name: "停用帳號缺少原因", method: "POST", path: "/admin/users/1/suspend", body: `{}`, expectedStatus: http.StatusBadRequest, expectedField: "reason"},
+		{name: "不可停用自己", method: "POST", path: "/admin/users/2/suspend", body: `{"reason":"test"}`, expectedStatus: http.StatusForbidden, expectedCode: "SELF_ACTION_NOT_ALLOWED"},
+		{name: "解除未停用的帳號", method: "POST", path: "/admin/users/1/unsuspend", expectedStatus: http.StatusConflict, expectedCode: "USER_NOT_SUSPENDED"},
+		{name: "強制重設密碼", method: "POST", path: "/admin/users/1/password-reset", expectedStatus: http.StatusOK, expectedEvent: models.AuditEventPasswordChange},
+		{name: "強制重設不存在的用戶", method: "POST", path: "/admin/users/99/password-reset", expectedStatus: http.StatusNotFound, expectedCode: "USER_NOT_FOUND"},
+		{name: "變更角色", method: "PUT", path: "/admin/users/1/role", body: `{"role":"teacher"}`, expectedStatus: http.StatusOK, expectedEvent: models.AuditEventRoleChange},
+		{name: "角色不存在", method: "PUT", path: "/admin/users/1/role", body: `{"role":"owner"}`, expectedStatus: http.StatusBadRequest, expectedField: "role"},
+		{name: "代入用戶身分", method: "POST", path: "/admin/users/1/impersonate", expectedStatus: http.StatusOK, expectedEvent: models.AuditEventImpersonation},
+		{name: "不可代入管理員", method: "POST", path: "/admin/users/2/impersonate", expectedStatus: http.StatusForbidden, expectedCode: "CANNOT_IMPERSONATE_ADMIN"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := setupGin()
+			auditLog := NewMockAuditLogService()
+			handler := NewAdminHandler(NewMockAuthService(), NewMockAdminService(), auditLog)
+			admin := r.Group("/admin/users", func(c *gin.Context) {
+				c.Set("user_id", 2)
+			})
+			admin.GET("/:id", handler.GetUser)
+			admin.POST("/:id/suspend", handler.SuspendUser)
+			admin.POST("/:id/unsuspend", handler.UnsuspendUser)
+			admin.POST("/:id/password-reset", handler.ForcePasswordReset)
+			admin.PUT("/:id/role", handler.ChangeRole)
+			admin.POST("/:id/impersonate", handler.Impersonate)
+
+			req, _ := http.NewRequest(tt.method, tt.path, bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+
+			var response models.APIResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatalf("Failed to unmarshal response: %v", err)
+			}
+			if tt.expectedCode != "" && (response.Error == nil || response.Error.Code != tt.expectedCode) {
+				t.Errorf("Expected error code %s, got %+v", tt.expectedCode, response.Error)
+			}
+			if tt.expectedField != "" {
+				errs, _ := response.Errors.(map[string]interface{})
+				if _, ok := errs[tt.expectedField]; !ok {
+					t.Errorf("Expected validation error for %s, got %v", tt.expectedField, response.Errors)
+				}
+			}
+
+			// 只有成功的變更操作寫入稽核紀錄，執行者為管理員
+			entry := auditLog.lastEntry()
+			if tt.expectedEvent == "" {
+				if entry != nil {
+					t.Errorf("Unexpected audit entry %+v", entry)
+				}
+			} else if entry == nil || entry.EventType != tt.expectedEvent || entry.ActorID == nil || *entry.ActorID != 2 ||
+				entry.TargetUserID == nil || *entry.TargetUserID != 1 {
+				t.Errorf("Expected %s audit entry by admin 2 for user 1, got %+v", tt.expectedEvent, entry)
+			}
+		})
+	}
+}
+
+func TestAdminHandler_Localization(t *testing.T) {
+	tests := []struct {
+		name            string
+		acceptLanguage  string
+		method          string
+		path            string
+		body            string
+		expectedMessage string
+		expectedErrors  map[string]string
+		expectedError   string
+	}{
+		{
+			name:            "英文欄位錯誤",
+			acceptLanguage:  "en",
+			method:          "GET",
+			path:            "/admin/users/abc",
+			expectedMessage: "Validation failed",
+			expectedErrors:  map[string]string{"id": "Invalid user ID"},
+		},
+		{
+			name:            "英文操作失敗",
+			acceptLanguage:  "en",
+			method:          "POST",
+			path:            "/admin/users/99/suspend",
+			body:            `{"reason":"spam"}`,
+			expectedMessage: "Failed to suspend account",
+			expectedError:   "User not found",
+		},
+		{
+			name:            "日文成功訊息",
+			acceptLanguage:  "ja",
+			method:          "PUT",
+			path:            "/admin/users/1/role",
+			body:            `{"role":"teacher"}`,
+			expectedMessage: "ロールを変更しました",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := setupGin()
+			handler := NewAdminHandler(NewMockAuthService(), NewMockAdminService(), NewMockAuditLogService())
+			admin := r.Group("/admin/users", func(c *gin.Context) {
+				c.Set("user_id", 2)
+			})
+			admin.GET("/:id", handler.GetUser)
+			admin.POST("/:id/suspend", handler.SuspendUser)
+			admin.PUT("/:id/role", handler.ChangeRole)
+
+			req, _ := http.NewRequest(tt.method, tt.path, bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Accept-Language", tt.acceptLanguage)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			var response struct {
+				Message string              `json:"message"`
+				Error   *models.APIError    `json:"error"`
+				Errors  map[string][]string `json:"errors"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatalf("Failed to unmarshal response: %v", err)
+			}
+			if response.Message != tt.expectedMessage {
+				t.Errorf("Expected message %q, got %q", tt.expectedMessage, response.Message)
+			}
+			for field, want := range tt.expectedErrors {
+				if got := response.Errors[field]; len(got) != 1 || got[0] != want {
+					t.Errorf("Expected %s errors [%s], got %v", field, want, got)
+				}
+			}
+			if tt.expectedError != "" && (response.Error == nil || response.Error.Message != tt.expectedError) {
+				t.Errorf("Expected error message %q, got %+v", tt.expectedError, response.Error)
+			}
+		})
+	}
+}
+
+// slowUserRepository 的 GetUserByID 直到 context 結束才回傳，錯誤與 database/sql 相同帶有 context 錯誤
+type slowUserRepository struct {
+	interfaces.UserRepositoryInterface
+}
+
+func (r *slowUserRepository) GetUserByID(ctx context.Context, id int) (*models.User, error) {
+	<-ctx.Done()
+	return nil, fmt.Errorf("failed to get user: %w", ctx.Err())
+}
+
+// 以實際的 AdminService 確認查詢用戶逾時回應 503，而不是用戶不存在
+func TestAdminHandler_GetUser_RequestDeadline(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(middleware.Locale())
+	r.Use(middleware.Timeout(middleware.RequestTimeouts{Default: 20 * time.Millisecond}))
+	r.Use(middleware.ErrorHandler())
+	adminService := services.NewAdminService(&slowUserRepository{}, nil, nil, nil, nil, nil)
+	r.GET("/admin/users/:id", NewAdminHandler(NewMockAuthService(), adminService, NewMockAuditLogService()).GetUser)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/admin/users/1", nil))
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusServiceUnavailable, w.Code, w.Body.String())
+	}
+	var response models.APIResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if response.Error == nil || response.Error.Code != "REQUEST_TIMEOUT" {
+		t.Errorf("Expected error code REQUEST_TIMEOUT, got %+v", response.Error)
+	}
+}
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"net/http"
+	"smart-learning-backend/pkg/interfaces"
+	"smart-learning-backend/pkg/models"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+type APIKeyHandler struct {
+	apiKeyService interfaces.APIKeyServiceInterface
+	auditLog      interfaces.AuditRecorderInterface
+}
+
+func NewAPIKeyHandler(apiKeyService interfaces.APIKeyServiceInterface, auditLog interfaces.AuditRecorderInterface) *APIKeyHandler {
+	return &APIKeyHandler{
+		apiKeyService: apiKeyService,
+		auditLog:      auditLog,
+	}
+}
+
+// CreateAPIKey 建立個人 API 金鑰，完整金鑰只會在此回應中出現一次
+func (h *APIKeyHandler) CreateAPIKey(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationFailed(c, bindingErrors(c, err))
+		return
+	}
+
+	result, err := h.apiKeyService.CreateAPIKey(c.Request.Context(), userID, &req)
+	if err != nil {
+		abortWithError(c, err, tr(c, "api_key.create_failed"))
+		return
+	}
+	recordAudit(c, h.auditLog, models.AuditEventAPIKeyCreated, userID, map[string]string{
+		"key_id": strconv.Itoa(result.ID),
+		"scopes": strings.Join(result.Scopes, ","),
+	})
+
+	c.JSON(http.StatusCreated, models.APIResponse{
+		Success: true,
+		Message: tr(c, "api_key.created"),
+		Data:    result,
+	})
+}
+
+func (h *APIKeyHandler) ListAPIKeys(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	keys, err := h.apiKeyService.ListAPIKeys(c.Request.Context(), userID)
+	if err != nil {
+		abortWithError(c, err, tr(c, "api_key.list_failed"))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data: map[string]interface{}{
+			"api_keys": keys,
+		},
+	})
+}
+
+func (h *APIKeyHandler) RevokeAPIKey(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	keyID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || keyID <= 0 {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: tr(c, "errors.validation_failed"),
+			Errors: map[string][]string{
+				"id": {tr(c, "api_key.invalid_id")},
+			},
+		})
+		return
+	}
+
+	if err := h.apiKeyService.RevokeAPIKey(c.Request.Context(), userID, keyID); err != nil {
+		abortWithError(c, err, tr(c, "api_key.revoke_failed"))
+		return
+	}
+	recordAudit(c, h.auditLog, models.AuditEventTokenRevoked, userID, map[string]string{
+		"reason": "api_key_revoked",
+		"key_id": strconv.Itoa(keyID),
+	})
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: tr(c, "api_key.revoked"),
+	})
+}
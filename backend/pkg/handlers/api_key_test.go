@@ -0,0 +1,247 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"smart-learning-backend/pkg/apperrors"
+	"smart-learning-backend/pkg/models"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MockAPIKeyService 實現了 APIKeyServiceInterface 介面用於測試
+type MockAPIKeyService struct {
+	shouldFailNext string
+}
+
+func (m *MockAPIKeyService) CreateAPIKey(ctx context.Context, userID int, req *models.CreateAPIKeyRequest) (*models.CreateAPIKeyResponse, error) {
+	switch m.shouldFailNext {
+	case "CreateAPIKey":
+		m.shouldFailNext = ""
+		return nil, errors.New("database error")
+	case "LimitReached":
+		m.shouldFailNext = ""
+		return nil, apperrors.ErrAPIKeyLimitReached
+	}
+	for _, scope := range req.Scopes {
+		if scope != models.PermissionLearningRead {
+			return nil, apperrors.ErrInvalidScope.WithArgs(scope)
+		}
+	}
+	return &models.CreateAPIKeyResponse{
+		APIKey: &models.APIKey{ID: 1, Name: req.Name, Prefix: "slk_abcdefgh", Scopes: req.Scopes},
+		Key:    "slk_abcdefgh-rest-of-key",
+	}, nil
+}
+
+func (m *MockAPIKeyService) ListAPIKeys(ctx context.Context, userID int) ([]models.APIKey, error) {
+	if m.shouldFailNext == "ListAPIKeys" {
+		m.shouldFailNext = ""
+		return nil, errors.New("database error")
+	}
+	return []models.APIKey{{ID: 1, Name: "匯入腳本", Prefix: "slk_abcdefgh"}}, nil
+}
+
+func (m *MockAPIKeyService) RevokeAPIKey(ctx context.Context, userID, id int) error {
+	if m.shouldFailNext == "RevokeAPIKey" {
+		m.shouldFailNext = ""
+		return errors.New("database error")
+	}
+	if id != 1 {
+		return apperrors.ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+func (m *MockAPIKeyService) AuthenticateAPIKey(ctx context.Context, key string) (*models.APIKeyPrincipal, error) {
+	return nil, apperrors.ErrInvalidAPIKey
+}
+
+func (m *MockAPIKeyService) SetShouldFailNext(method string) {
+	m.shouldFailNext = method
+}
+
+func TestAPIKeyHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		requestBody    interface{}
+		authenticated  bool
+		setupService   func(*MockAPIKeyService)
+		expectedStatus int
+		expectedCode   string
+		expectedField  string
+		expectedEvent  string
+	}{
+		{
+			name:           "成功建立金鑰",
+			method:         "POST",
+			path:           "/api-keys",
+			requestBody:    models.CreateAPIKeyRequest{Name: "匯入腳本", Scopes: []string{models.PermissionLearningRead}},
+			authenticated:  true,
+			expectedStatus: http.StatusCreated,
+			expectedEvent:  models.AuditEventAPIKeyCreated,
+		},
+		{
+			name:           "未登入",
+			method:         "POST",
+			path:           "/api-keys",
+			requestBody:    models.CreateAPIKeyRequest{Name: "匯入腳本", Scopes: []string{models.PermissionLearningRead}},
+			expectedStatus: http.StatusUnauthorized,
+			expectedCode:   "UNAUTHORIZED",
+		},
+		{
+			name:           "缺少 scope",
+			method:         "POST",
+			path:           "/api-keys",
+			requestBody:    map[string]interface{}{"name": "匯入腳本"},
+			authenticated:  true,
+			expectedStatus: http.StatusBadRequest,
+			expectedField:  "scopes",
+		},
+		{
+			name:           "scope 超出權限",
+			method:         "POST",
+			path:           "/api-keys",
+			requestBody:    models.CreateAPIKeyRequest{Name: "匯入腳本", Scopes: []string{models.PermissionUsersManage}},
+			authenticated:  true,
+			expectedStatus: http.StatusBadRequest,
+			expectedField:  "scopes",
+		},
+		{
+			name:          "金鑰數量已達上限",
+			method:        "POST",
+			path:          "/api-keys",
+			requestBody:   models.CreateAPIKeyRequest{Name: "匯入腳本", Scopes: []string{models.PermissionLearningRead}},
+			authenticated: true,
+			setupService: func(m *MockAPIKeyService) {
+				m.SetShouldFailNext("LimitReached")
+			},
+			expectedStatus: http.StatusConflict,
+			expectedCode:   "API_KEY_LIMIT_REACHED",
+		},
+		{
+			name:           "列出金鑰",
+			method:         "GET",
+			path:           "/api-keys",
+			authenticated:  true,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:          "列出金鑰服務錯誤",
+			method:        "GET",
+			path:          "/api-keys",
+			authenticated: true,
+			setupService: func(m *MockAPIKeyService) {
+				m.SetShouldFailNext("ListAPIKeys")
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedCode:   "INTERNAL_SERVER_ERROR",
+		},
+		{
+			name:           "撤銷金鑰",
+			method:         "DELETE",
+			path:           "/api-keys/1",
+			authenticated:  true,
+			expectedStatus: http.StatusOK,
+			expectedEvent:  models.AuditEventTokenRevoked,
+		},
+		{
+			name:           "撤銷不存在的金鑰",
+			method:         "DELETE",
+			path:           "/api-keys/99",
+			authenticated:  true,
+			expectedStatus: http.StatusNotFound,
+			expectedCode:   "API_KEY_NOT_FOUND",
+		},
+		{
+			name:           "無效的金鑰 ID",
+			method:         "DELETE",
+			path:           "/api-keys/abc",
+			authenticated:  true,
+			expectedStatus: http.StatusBadRequest,
+			expectedField:  "id",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := setupGin()
+			mockService := &MockAPIKeyService{}
+			if tt.setupService != nil {
+				tt.setupService(mockService)
+			}
+			auditLog := NewMockAuditLogService()
+			handler := NewAPIKeyHandler(mockService, auditLog)
+
+			if tt.authenticated {
+				r.Use(func(c *gin.Context) {
+					c.Set("user_id", 1)
+					c.Next()
+				})
+			}
+			r.POST("/api-keys", handler.CreateAPIKey)
+			r.GET("/api-keys", handler.ListAPIKeys)
+			r.DELETE("/api-keys/:id", handler.RevokeAPIKey)
+
+			var body *bytes.Buffer
+			if tt.requestBody != nil {
+				reqBody, err := json.Marshal(tt.requestBody)
+				if err != nil {
+					t.Fatalf("Failed to marshal request body: %v", err)
+				}
+				body = bytes.NewBuffer(reqBody)
+			} else {
+				body = bytes.NewBuffer(nil)
+			}
+
+			req, err := http.NewRequest(tt.method, tt.path, body)
+			if err != nil {
+				t.Fatalf("Failed to create request: %v", err)
+			}
+			req.Header.Set("Content-Type", "application/json")
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+
+			var response models.APIResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatalf("Failed to unmarshal response: %v", err)
+			}
+
+			if tt.expectedCode != "" {
+				if response.Error == nil || response.Error.Code != tt.expectedCode {
+					t.Errorf("Expected error code %s, got %+v", tt.expectedCode, response.Error)
+				}
+			}
+
+			if tt.expectedField != "" {
+				errs, _ := response.Errors.(map[string]interface{})
+				if _, ok := errs[tt.expectedField]; !ok {
+					t.Errorf("Expected validation error for %s, got %v", tt.expectedField, response.Errors)
+				}
+			}
+
+			// 只有成功建立或撤銷金鑰時寫入稽核紀錄
+			entry := auditLog.lastEntry()
+			if tt.expectedEvent == "" {
+				if entry != nil {
+					t.Errorf("Unexpected audit entry %+v", entry)
+				}
+			} else if entry == nil || entry.EventType != tt.expectedEvent || entry.Metadata["key_id"] != "1" ||
+				entry.TargetUserID == nil || *entry.TargetUserID != 1 {
+				t.Errorf("Expected %s audit entry for key 1, got %+v", tt.expectedEvent, entry)
+			}
+		})
+	}
+}
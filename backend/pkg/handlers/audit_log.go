@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"smart-learning-backend/pkg/interfaces"
+	"smart-learning-backend/pkg/logging"
+	"smart-learning-backend/pkg/models"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AuditLogHandler struct {
+	auditLogService interfaces.AuditLogServiceInterface
+}
+
+func NewAuditLogHandler(auditLogService interfaces.AuditLogServiceInterface) *AuditLogHandler {
+	return &AuditLogHandler{auditLogService: auditLogService}
+}
+
+// auditLogCSVHeader 為匯出 CSV 的欄位，順序與 auditLogCSVRecord 一致
+var auditLogCSVHeader = []string{"id", "created_at", "event_type", "actor_id", "target_user_id", "ip_address", "user_agent", "request_id", "metadata"}
+
+// ListAuditLogs 依條件查詢稽核日誌並分頁，新的排在前面
+func (h *AuditLogHandler) ListAuditLogs(c *gin.Context) {
+	query, ok := bindAuditLogQuery(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.auditLogService.ListEntries(c.Request.Context(), query)
+	if err != nil {
+		abortWithError(c, err, tr(c, "audit_log.list_failed"))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    result,
+	})
+}
+
+// ExportAuditLogs 以 CSV 匯出符合條件的所有稽核紀錄，依時間先後排列
+func (h *AuditLogHandler) ExportAuditLogs(c *gin.Context) {
+	query, ok := bindAuditLogQuery(c)
+	if !ok {
+		return
+	}
+
+	// 第一筆紀錄寫出前都還能改回應 JSON 錯誤；之後的錯誤只能記錄並中斷輸出
+	writer := csv.NewWriter(c.Writer)
+	started := false
+	start := func() error {
+		if started {
+			return nil
+		}
+		started = true
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+		c.Header("Content-Disposition", `attachment; filename="audit-log-`+time.Now().UTC().Format("20060102T150405Z")+`.csv"`)
+		c.Header("Cache-Control", "no-store")
+		c.Status(http.StatusOK)
+		return writer.Write(auditLogCSVHeader)
+	}
+
+	err := h.auditLogService.ExportEntries(c.Request.Context(), query, func(entry *models.AuditLogEntry) error {
+		if err := start(); err != nil {
+			return err
+		}
+		return writer.Write(auditLogCSVRecord(entry))
+	})
+	if err == nil {
+		err = start()
+	}
+	if err != nil && !started {
+		abortWithError(c, err, tr(c, "audit_log.export_failed"))
+		return
+	}
+
+	writer.Flush()
+	if err == nil {
+		err = writer.Error()
+	}
+	if err != nil {
+		logging.FromContext(c.Request.Context()).Warn("稽核日誌匯出中斷", "error", err)
+	}
+}
+
+// bindAuditLogQuery 解析查詢條件；格式不正確時回應 400 並回傳 false
+func bindAuditLogQuery(c *gin.Context) (*models.AuditLogQuery, bool) {
+	var query models.AuditLogQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		validationErrors := bindingErrors(c, err)
+		if len(validationErrors) == 0 {
+			// 時間或數字無法解析時不會產生欄位錯誤
+			validationErrors = map[string][]string{
+				"query": {tr(c, "audit_log.invalid_query")},
+			}
+		}
+		validationFailed(c, validationErrors)
+		return nil, false
+	}
+	return &query, true
+}
+
+// auditLogCSVRecord 將稽核紀錄轉換為 CSV 的一列
+func auditLogCSVRecord(entry *models.AuditLogEntry) []string {
+	optionalID := func(id *int) string {
+		if id == nil {
+			return ""
+		}
+		return strconv.Itoa(*id)
+	}
+
+	metadata := ""
+	if len(entry.Metadata) > 0 {
+		encoded, _ := json.Marshal(entry.Metadata)
+		metadata = string(encoded)
+	}
+
+	return []string{
+		strconv.FormatInt(entry.ID, 10),
+		entry.CreatedAt.UTC().Format(time.RFC3339),
+		entry.EventType,
+		optionalID(entry.ActorID),
+		optionalID(entry.TargetUserID),
+		csvSafe(entry.IPAddress),
+		csvSafe(entry.UserAgent),
+		csvSafe(entry.RequestID),
+		csvSafe(metadata),
+	}
+}
+
+// csvSafe 在可能被試算表當作公式的值前加上單引號，User-Agent 等欄位由用戶端控制
+func csvSafe(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// recordAudit 以目前請求的用戶端資訊寫入稽核紀錄，執行者為已登入的用戶；
+// 以 API 金鑰或代入用戶身分的 token 操作時，另記錄 api_key_id 或 impersonator_id
+func recordAudit(c *gin.Context, auditLog interfaces.AuditRecorderInterface, eventType string, targetUserID int, metadata map[string]string) {
+	for _, key := range []string{"api_key_id", "impersonator_id"} {
+		if id := c.GetInt(key); id != 0 {
+			if metadata == nil {
+				metadata = map[string]string{}
+			}
+			metadata[key] = strconv.Itoa(id)
+		}
+	}
+
+	auditLog.Record(c.Request.Context(), models.NewAuditLogEntry(eventType, clientInfo(c), c.GetInt("user_id"), targetUserID, metadata))
+}
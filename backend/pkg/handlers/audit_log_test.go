@@ -0,0 +1,251 @@
+package handlers
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"smart-learning-backend/pkg/apperrors"
+	"smart-learning-backend/pkg/interfaces"
+	"smart-learning-backend/pkg/models"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MockAuditLogService 實現了 AuditLogServiceInterface 介面用於測試，Record 寫入的紀錄可供查詢與匯出
+type MockAuditLogService struct {
+	entries        []models.AuditLogEntry
+	lastQuery      *models.AuditLogQuery
+	shouldFailNext string
+}
+
+var _ interfaces.AuditLogServiceInterface = (*MockAuditLogService)(nil)
+
+func NewMockAuditLogService() *MockAuditLogService {
+	return &MockAuditLogService{
+		entries: make([]models.AuditLogEntry, 0),
+	}
+}
+
+func (m *MockAuditLogService) Record(ctx context.Context, entry *models.AuditLogEntry) {
+	entry.ID = int64(len(m.entries) + 1)
+	entry.CreatedAt = time.Date(2025, 1, 1, 0, 0, len(m.entries), 0, time.UTC)
+	m.entries = append(m.entries, *entry)
+}
+
+func (m *MockAuditLogService) ListEntries(ctx context.Context, query *models.AuditLogQuery) (*models.AuditLogListResponse, error) {
+	m.lastQuery = query
+	if m.shouldFailNext == "ListEntries" {
+		m.shouldFailNext = ""
+		return nil, errors.New("database error")
+	}
+	if query.From != nil && query.To != nil && !query.From.Before(*query.To) {
+		return nil, apperrors.ErrInvalidTimeRange
+	}
+	return &models.AuditLogListResponse{Entries: m.entries, Page: 1, PageSize: 50, Total: len(m.entries)}, nil
+}
+
+func (m *MockAuditLogService) ExportEntries(ctx context.Context, query *models.AuditLogQuery, fn func(entry *models.AuditLogEntry) error) error {
+	m.lastQuery = query
+	if m.shouldFailNext == "ExportEntries" {
+		m.shouldFailNext = ""
+		return errors.New("database error")
+	}
+	if query.From != nil && query.To != nil && !query.From.Before(*query.To) {
+		return apperrors.ErrInvalidTimeRange
+	}
+	for i := range m.entries {
+		if err := fn(&m.entries[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lastEntry 回傳最後寫入的紀錄，沒有紀錄時回傳 nil
+func (m *MockAuditLogService) lastEntry() *models.AuditLogEntry {
+	if len(m.entries) == 0 {
+		return nil
+	}
+	return &m.entries[len(m.entries)-1]
+}
+
+func TestAuditLogHandler_ListAuditLogs(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		setupService   func(*MockAuditLogService)
+		expectedStatus int
+		expectedCode   string
+		expectedField  string
+	}{
+		{name: "成功查詢", query: "?event_type=login_failure&user_id=1&from=2025-01-01T00:00:00Z&to=2025-02-01T00:00:00%2B08:00", expectedStatus: http.StatusOK},
+		{name: "時間格式錯誤", query: "?from=2025-01-01", expectedStatus: http.StatusBadRequest, expectedField: "query"},
+		{name: "不支援的事件類型", query: "?event_type=unknown", expectedStatus: http.StatusBadRequest, expectedField: "eventtype"},
+		{name: "每頁筆數超過上限", query: "?page_size=500", expectedStatus: http.StatusBadRequest, expectedField: "pagesize"},
+		{name: "起始時間晚於結束時間", query: "?from=2025-02-01T00:00:00Z&to=2025-01-01T00:00:00Z", expectedStatus: http.StatusBadRequest, expectedField: "from"},
+		{
+			name:           "服務錯誤",
+			setupService:   func(m *MockAuditLogService) { m.shouldFailNext = "ListEntries" },
+			expectedStatus: http.StatusInternalServerError,
+			expectedCode:   "INTERNAL_SERVER_ERROR",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := setupGin()
+			mockService := NewMockAuditLogService()
+			if tt.setupService != nil {
+				tt.setupService(mockService)
+			}
+			r.GET("/admin/audit-logs", NewAuditLogHandler(mockService).ListAuditLogs)
+
+			req, _ := http.NewRequest("GET", "/admin/audit-logs"+tt.query, nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+
+			var response models.APIResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatalf("Failed to unmarshal response: %v", err)
+			}
+			if tt.expectedCode != "" && (response.Error == nil || response.Error.Code != tt.expectedCode) {
+				t.Errorf("Expected error code %s, got %+v", tt.expectedCode, response.Error)
+			}
+			if tt.expectedField != "" {
+				errs, _ := response.Errors.(map[string]interface{})
+				if _, ok := errs[tt.expectedField]; !ok {
+					t.Errorf("Expected validation error for %s, got %v", tt.expectedField, response.Errors)
+				}
+			}
+		})
+	}
+
+	t.Run("查詢參數完整傳入服務", func(t *testing.T) {
+		r := setupGin()
+		mockService := NewMockAuditLogService()
+		r.GET("/admin/audit-logs", NewAuditLogHandler(mockService).ListAuditLogs)
+
+		req, _ := http.NewRequest("GET", "/admin/audit-logs?event_type=role_change&actor_id=2&to=2025-02-01T08:00:00%2B08:00", nil)
+		r.ServeHTTP(httptest.NewRecorder(), req)
+
+		got := mockService.lastQuery
+		if got == nil || got.EventType != models.AuditEventRoleChange || got.ActorID != 2 || got.From != nil {
+			t.Fatalf("ListEntries() received %+v", got)
+		}
+		if got.To == nil || !got.To.Equal(time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)) {
+			t.Errorf("to = %v, want 2025-02-01T00:00:00Z", got.To)
+		}
+	})
+}
+
+func TestAuditLogHandler_ExportAuditLogs(t *testing.T) {
+	export := func(mockService *MockAuditLogService, query string) *httptest.ResponseRecorder {
+		r := setupGin()
+		r.GET("/admin/audit-logs/export", NewAuditLogHandler(mockService).ExportAuditLogs)
+		req, _ := http.NewRequest("GET", "/admin/audit-logs/export"+query, nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("匯出 CSV", func(t *testing.T) {
+		mockService := NewMockAuditLogService()
+		mockService.Record(context.Background(), models.NewAuditLogEntry(models.AuditEventLoginFailure, models.ClientInfo{
+			UserAgent: "=HYPERLINK(\"http://evil.example\")",
+			IPAddress: "203.0.113.1",
+			RequestID: "req-1",
+		}, 0, 1, map[string]string{"reason": "invalid_password"}))
+		mockService.Record(context.Background(), models.NewAuditLogEntry(models.AuditEventRoleChange, models.ClientInfo{}, 2, 1, nil))
+
+		w := export(mockService, "?user_id=1")
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+		if contentType := w.Header().Get("Content-Type"); !strings.HasPrefix(contentType, "text/csv") {
+			t.Errorf("Content-Type = %q, want text/csv", contentType)
+		}
+		if disposition := w.Header().Get("Content-Disposition"); !strings.Contains(disposition, "attachment") {
+			t.Errorf("Content-Disposition = %q, want attachment", disposition)
+		}
+		if mockService.lastQuery == nil || mockService.lastQuery.UserID != 1 {
+			t.Errorf("ExportEntries() received %+v", mockService.lastQuery)
+		}
+
+		records, err := csv.NewReader(w.Body).ReadAll()
+		if err != nil {
+			t.Fatalf("Failed to parse CSV: %v", err)
+		}
+		if len(records) != 3 || strings.Join(records[0], ",") != strings.Join(auditLogCSVHeader, ",") {
+			t.Fatalf("CSV records = %v", records)
+		}
+		first := records[1]
+		if first[2] != models.AuditEventLoginFailure || first[3] != "" || first[4] != "1" || first[5] != "203.0.113.1" {
+			t.Errorf("first record = %v", first)
+		}
+		if !strings.HasPrefix(first[6], "'=") {
+			t.Errorf("user agent = %q, want formula escaped", first[6])
+		}
+		if first[8] != `{"reason":"invalid_password"}` {
+			t.Errorf("metadata = %q", first[8])
+		}
+		if records[2][3] != "2" || records[2][8] != "" {
+			t.Errorf("second record = %v", records[2])
+		}
+	})
+
+	t.Run("沒有紀錄時只有標題列", func(t *testing.T) {
+		w := export(NewMockAuditLogService(), "")
+		records, err := csv.NewReader(w.Body).ReadAll()
+		if w.Code != http.StatusOK || err != nil || len(records) != 1 {
+			t.Errorf("export = %d %v %v", w.Code, records, err)
+		}
+	})
+
+	t.Run("時間範圍錯誤時回應 JSON", func(t *testing.T) {
+		w := export(NewMockAuditLogService(), "?from=2025-02-01T00:00:00Z&to=2025-01-01T00:00:00Z")
+		if w.Code != http.StatusBadRequest || !strings.HasPrefix(w.Header().Get("Content-Type"), "application/json") {
+			t.Errorf("export = %d %q", w.Code, w.Header().Get("Content-Type"))
+		}
+	})
+
+	t.Run("服務錯誤", func(t *testing.T) {
+		mockService := NewMockAuditLogService()
+		mockService.shouldFailNext = "ExportEntries"
+		if w := export(mockService, ""); w.Code != http.StatusInternalServerError {
+			t.Errorf("Expected status 500, got %d", w.Code)
+		}
+	})
+}
+
+func TestRecordAudit(t *testing.T) {
+	r := setupGin()
+	mockService := NewMockAuditLogService()
+	r.POST("/action", func(c *gin.Context) {
+		c.Set("user_id", 1)
+		c.Set("impersonator_id", 2)
+		c.Set("request_id", "req-9")
+		recordAudit(c, mockService, models.AuditEventLogout, 1, nil)
+	})
+
+	req, _ := http.NewRequest("POST", "/action", nil)
+	req.Header.Set("User-Agent", "Mozilla/5.0")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	entry := mockService.lastEntry()
+	if entry == nil || entry.ActorID == nil || *entry.ActorID != 1 || entry.TargetUserID == nil || *entry.TargetUserID != 1 {
+		t.Fatalf("recordAudit() entry = %+v", entry)
+	}
+	if entry.RequestID != "req-9" || entry.UserAgent != "Mozilla/5.0" || entry.Metadata["impersonator_id"] != "2" {
+		t.Errorf("recordAudit() entry = %+v", entry)
+	}
+}
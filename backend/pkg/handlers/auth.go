@@ -1,124 +1,53 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
+	"smart-learning-backend/pkg/apperrors"
 	"smart-learning-backend/pkg/interfaces"
 	"smart-learning-backend/pkg/models"
-	"strings"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
-	"github.com/go-playground/validator/v10"
 )
 
 type AuthHandler struct {
 	authService interfaces.AuthServiceInterface
-	validator   *validator.Validate
+	auditLog    interfaces.AuditRecorderInterface
 }
 
-func NewAuthHandler(authService interfaces.AuthServiceInterface) *AuthHandler {
+func NewAuthHandler(authService interfaces.AuthServiceInterface, auditLog interfaces.AuditRecorderInterface) *AuthHandler {
 	return &AuthHandler{
 		authService: authService,
-		validator:   validator.New(),
+		auditLog:    auditLog,
 	}
 }
 
 func (h *AuthHandler) Register(c *gin.Context) {
 	var req models.RegisterRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		validationErrors := make(map[string][]string)
-		
-		if validatorErrors, ok := err.(validator.ValidationErrors); ok {
-			for _, fieldError := range validatorErrors {
-				field := strings.ToLower(fieldError.Field())
-				var message string
-				
-				switch fieldError.Tag() {
-				case "required":
-					message = "此欄位為必填"
-				case "email":
-					message = "電子郵件格式不正確"
-				case "min":
-					if field == "password" {
-						message = "密碼至少需要 8 個字符"
-					} else if field == "username" {
-						message = "用戶名至少需要 2 個字符"
-					} else {
-						message = "長度不足"
-					}
-				case "max":
-					if field == "username" {
-						message = "用戶名不能超過 20 個字符"
-					} else {
-						message = "長度過長"
-					}
-				case "alphanum":
-					message = "只能包含字母和數字"
-				default:
-					message = "格式不正確"
-				}
-				
-				validationErrors[field] = append(validationErrors[field], message)
-			}
-		}
-		
-		c.JSON(http.StatusBadRequest, models.APIResponse{
-			Success: false,
-			Message: "驗證失敗",
-			Errors:  validationErrors,
-		})
+		validationFailed(c, bindingErrors(c, err))
 		return
 	}
 	
-	authResponse, err := h.authService.Register(&req)
+	authResponse, err := h.authService.Register(c.Request.Context(), &req, clientInfo(c))
 	if err != nil {
-		if strings.Contains(err.Error(), "user already exists") {
-			c.JSON(http.StatusConflict, models.APIResponse{
-				Success: false,
-				Message: "用戶已存在",
-				Error: &models.APIError{
-					Code:    "USER_ALREADY_EXISTS",
-					Message: "電子郵件或用戶名已被使用",
-				},
-			})
-			return
-		}
-		
-		if strings.Contains(err.Error(), "passwords do not match") {
-			c.JSON(http.StatusBadRequest, models.APIResponse{
-				Success: false,
-				Message: "驗證失敗",
-				Errors: map[string][]string{
-					"confirm_password": {"密碼確認不一致"},
-				},
-			})
+		if errs, ok := passwordPolicyErrors(c, err, "password"); ok {
+			validationFailed(c, errs)
 			return
 		}
-		
-		if strings.Contains(err.Error(), "username can only contain") {
-			c.JSON(http.StatusBadRequest, models.APIResponse{
-				Success: false,
-				Message: "驗證失敗",
-				Errors: map[string][]string{
-					"username": {"用戶名只能包含字母、數字和底線"},
-				},
-			})
-			return
+
+		message := tr(c, "auth.register_failed")
+		if errors.Is(err, apperrors.ErrUserAlreadyExists) {
+			message = tr(c, "auth.user_already_exists")
 		}
-		
-		c.JSON(http.StatusInternalServerError, models.APIResponse{
-			Success: false,
-			Message: "註冊失敗",
-			Error: &models.APIError{
-				Code:    "INTERNAL_SERVER_ERROR",
-				Message: "伺服器內部錯誤",
-			},
-		})
+		abortWithError(c, err, message)
 		return
 	}
 	
 	c.JSON(http.StatusCreated, models.APIResponse{
 		Success: true,
-		Message: "註冊成功",
+		Message: tr(c, "auth.register_success"),
 		Data:    authResponse,
 	})
 }
@@ -126,118 +55,117 @@ func (h *AuthHandler) Register(c *gin.Context) {
 func (h *AuthHandler) Login(c *gin.Context) {
 	var req models.LoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		validationErrors := make(map[string][]string)
-		
-		if validatorErrors, ok := err.(validator.ValidationErrors); ok {
-			for _, fieldError := range validatorErrors {
-				field := strings.ToLower(fieldError.Field())
-				var message string
-				
-				switch fieldError.Tag() {
-				case "required":
-					message = "此欄位為必填"
-				case "email":
-					message = "請輸入有效的電子郵件"
-				case "min":
-					if field == "password" {
-						message = "密碼至少需要 8 個字符"
-					} else {
-						message = "長度不足"
-					}
-				default:
-					message = "格式不正確"
-				}
-				
-				validationErrors[field] = append(validationErrors[field], message)
-			}
-		}
-		
-		c.JSON(http.StatusBadRequest, models.APIResponse{
-			Success: false,
-			Message: "驗證失敗",
-			Errors:  validationErrors,
-		})
+		validationFailed(c, bindingErrors(c, err))
 		return
 	}
 	
-	authResponse, err := h.authService.Login(&req)
+	authResponse, err := h.authService.Login(c.Request.Context(), &req, clientInfo(c))
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, models.APIResponse{
-			Success: false,
-			Message: "登入失敗",
-			Error: &models.APIError{
-				Code:    "INVALID_CREDENTIALS",
-				Message: "電子郵件或密碼錯誤",
-			},
+		abortWithError(c, err, tr(c, "auth.login_failed"))
+		return
+	}
+
+	if authResponse.MFARequired {
+		c.JSON(http.StatusOK, models.APIResponse{
+			Success: true,
+			Message: tr(c, "auth.mfa_required"),
+			Data:    authResponse,
 		})
 		return
 	}
 	
 	c.JSON(http.StatusOK, models.APIResponse{
 		Success: true,
-		Message: "登入成功",
+		Message: tr(c, "auth.login_success"),
+		Data:    authResponse,
+	})
+}
+
+// VerifyMFA 以登入時取得的 mfa_token 與驗證碼完成兩步驟驗證
+func (h *AuthHandler) VerifyMFA(c *gin.Context) {
+	var req models.MFAVerifyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationFailed(c, bindingErrors(c, err))
+		return
+	}
+
+	authResponse, err := h.authService.VerifyMFA(c.Request.Context(), &req, clientInfo(c))
+	if err != nil {
+		abortWithError(c, err, tr(c, "auth.mfa_verification_failed"))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: tr(c, "auth.login_success"),
+		Data:    authResponse,
+	})
+}
+
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var req models.RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationFailed(c, map[string][]string{
+			"refresh_token": {tr(c, "validation.required")},
+		})
+		return
+	}
+
+	authResponse, err := h.authService.Refresh(c.Request.Context(), &req, clientInfo(c))
+	if err != nil {
+		abortWithError(c, err, tr(c, "auth.refresh_failed"))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: tr(c, "auth.refresh_success"),
 		Data:    authResponse,
 	})
 }
 
 func (h *AuthHandler) Logout(c *gin.Context) {
 	// 從中介軟體中取得用戶資訊（已經通過認證）
-	userID, exists := c.Get("user_id")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, models.APIResponse{
-			Success: false,
-			Message: "未授權",
-			Error: &models.APIError{
-				Code:    "UNAUTHORIZED",
-				Message: "無法獲取用戶資訊",
-			},
-		})
+	userID, ok := currentUserID(c)
+	if !ok {
 		return
 	}
 
 	// 記錄登出資訊（用於日誌記錄）
 	username, _ := c.Get("username")
 	email, _ := c.Get("email")
-	
-	// 在實際應用中，這裡可以將 token 加入黑名單
-	// 目前使用 stateless JWT，客戶端丟棄 token 即完成登出
-	
+
+	// 將目前的 access token 加入撤銷清單，直到其原本的過期時間，並結束目前的裝置會話
+	sessionID := c.GetString("session_id")
+	jti := c.GetString("jti")
+	expiresAt := c.GetTime("token_expires_at")
+	if err := h.authService.Logout(c.Request.Context(), userID, sessionID, jti, expiresAt); err != nil {
+		abortWithError(c, err, tr(c, "auth.logout_failed"))
+		return
+	}
+	recordAudit(c, h.auditLog, models.AuditEventLogout, userID, map[string]string{"session_id": sessionID})
+
 	c.JSON(http.StatusOK, models.APIResponse{
 		Success: true,
-		Message: "登出成功",
+		Message: tr(c, "auth.logout_success"),
 		Data: map[string]interface{}{
 			"user_id":  userID,
 			"username": username,
 			"email":    email,
-			"message":  "已成功登出系統",
+			"message":  tr(c, "auth.logged_out"),
 		},
 	})
 }
 
 func (h *AuthHandler) GetMe(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, models.APIResponse{
-			Success: false,
-			Message: "未授權",
-			Error: &models.APIError{
-				Code:    "UNAUTHORIZED",
-				Message: "無法獲取用戶資訊",
-			},
-		})
+	userID, ok := currentUserID(c)
+	if !ok {
 		return
 	}
 	
-	user, err := h.authService.GetUserByID(userID.(int))
+	user, err := h.authService.GetUserByID(c.Request.Context(), userID)
 	if err != nil {
-		c.JSON(http.StatusNotFound, models.APIResponse{
-			Success: false,
-			Message: "用戶不存在",
-			Error: &models.APIError{
-				Code:    "USER_NOT_FOUND",
-				Message: "用戶不存在",
-			},
-		})
+		abortWithError(c, err, tr(c, "errors.user_not_found"))
 		return
 	}
 	
@@ -247,4 +175,84 @@ func (h *AuthHandler) GetMe(c *gin.Context) {
 			"user": user,
 		},
 	})
-}
\ No newline at end of file
+}
+
+func (h *AuthHandler) ListSessions(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	sessions, err := h.authService.ListSessions(c.Request.Context(), userID, c.GetString("session_id"))
+	if err != nil {
+		abortWithError(c, err, tr(c, "auth.list_sessions_failed"))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data: map[string]interface{}{
+			"sessions": sessions,
+		},
+	})
+}
+
+func (h *AuthHandler) RevokeSession(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.authService.RevokeSession(c.Request.Context(), userID, c.Param("id")); err != nil {
+		abortWithError(c, err, tr(c, "auth.revoke_session_failed"))
+		return
+	}
+	recordAudit(c, h.auditLog, models.AuditEventTokenRevoked, userID, map[string]string{
+		"reason":     "session_revoked",
+		"session_id": c.Param("id"),
+	})
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: tr(c, "auth.session_revoked"),
+	})
+}
+
+func (h *AuthHandler) RevokeOtherSessions(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	revoked, err := h.authService.RevokeOtherSessions(c.Request.Context(), userID, c.GetString("session_id"))
+	if err != nil {
+		abortWithError(c, err, tr(c, "auth.revoke_other_sessions_failed"))
+		return
+	}
+	recordAudit(c, h.auditLog, models.AuditEventTokenRevoked, userID, map[string]string{
+		"reason":           "other_sessions_revoked",
+		"revoked_sessions": strconv.FormatInt(revoked, 10),
+	})
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: tr(c, "auth.other_sessions_revoked"),
+		Data: map[string]interface{}{
+			"revoked_sessions": revoked,
+		},
+	})
+}
+
+// clientInfo 取得請求的用戶端資訊；ClientIP 會依 TRUSTED_PROXIES 設定決定是否採用代理標頭
+func clientInfo(c *gin.Context) models.ClientInfo {
+	userAgent := c.Request.UserAgent()
+	if len(userAgent) > 500 {
+		userAgent = userAgent[:500]
+	}
+
+	return models.ClientInfo{
+		UserAgent: userAgent,
+		IPAddress: c.ClientIP(),
+		RequestID: c.GetString("request_id"),
+	}
+}
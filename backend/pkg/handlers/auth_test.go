@@ -2,11 +2,19 @@ package handlers
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"smart-learning-backend/pkg/apperrors"
+	"smart-learning-backend/pkg/authtoken/authtokentest"
+	"smart-learning-backend/pkg/config"
+	"smart-learning-backend/pkg/i18n"
 	"smart-learning-backend/pkg/interfaces"
+	"smart-learning-backend/pkg/logging"
+	"smart-learning-backend/pkg/middleware"
 	"smart-learning-backend/pkg/models"
 	"smart-learning-backend/pkg/utils"
 	"strings"
@@ -14,24 +22,31 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
 )
 
 // MockAuthService 實現了認證服務的 mock 版本用於測試
 type MockAuthService struct {
 	users          []models.User
+	sessions       []models.Session
+	revokedJTIs    []string
 	shouldFailNext string // 指定下一個應該失敗的方法
 }
 
 // 確保 MockAuthService 實現 AuthServiceInterface 介面
 var _ interfaces.AuthServiceInterface = (*MockAuthService)(nil)
 
+// testTokenIssuer 簽發 mock 回應中的 access token
+var testTokenIssuer = authtokentest.NewIssuer()
+
 func NewMockAuthService() *MockAuthService {
 	return &MockAuthService{
 		users: make([]models.User, 0),
 	}
 }
 
-func (m *MockAuthService) Register(req *models.RegisterRequest) (*models.AuthResponse, error) {
+func (m *MockAuthService) Register(ctx context.Context, req *models.RegisterRequest, client models.ClientInfo) (*models.AuthResponse, error) {
 	if m.shouldFailNext == "Register" {
 		m.shouldFailNext = ""
 		return nil, errors.New("database error")
@@ -40,18 +55,26 @@ func (m *MockAuthService) Register(req *models.RegisterRequest) (*models.AuthRes
 	// 模擬用戶已存在錯誤
 	for _, user := range m.users {
 		if user.Email == req.Email || user.Username == req.Username {
-			return nil, errors.New("user already exists")
+			return nil, apperrors.ErrUserAlreadyExists
 		}
 	}
 
 	// 模擬密碼不匹配錯誤
 	if req.Password != req.ConfirmPassword {
-		return nil, errors.New("passwords do not match")
+		return nil, apperrors.ErrPasswordsDoNotMatch
 	}
 
 	// 模擬用戶名格式錯誤
 	if strings.Contains(req.Username, "@") {
-		return nil, errors.New("username can only contain letters, numbers and underscores")
+		return nil, apperrors.ErrInvalidUsername
+	}
+
+	// 模擬密碼政策錯誤
+	if len(req.Password) < 8 {
+		return nil, &models.PasswordPolicyError{Violations: []models.PasswordPolicyViolation{
+			{Rule: models.PasswordRuleMinLength, Limit: 8},
+			{Rule: models.PasswordRuleStrength, Limit: 2},
+		}}
 	}
 
 	// 創建新用戶
@@ -69,18 +92,26 @@ func (m *MockAuthService) Register(req *models.RegisterRequest) (*models.AuthRes
 	m.users = append(m.users, user)
 
 	// 生成 JWT
-	token, _ := utils.GenerateJWT(user.ID, user.Email, user.Username)
+	token, _ := testTokenIssuer.GenerateJWT(user.ID, user.Email, user.Username, "session-1", "student", nil, "")
 
 	return &models.AuthResponse{
-		User:  user,
+		User:  &user,
 		Token: token,
 	}, nil
 }
 
-func (m *MockAuthService) Login(req *models.LoginRequest) (*models.AuthResponse, error) {
+func (m *MockAuthService) Login(ctx context.Context, req *models.LoginRequest, client models.ClientInfo) (*models.AuthResponse, error) {
 	if m.shouldFailNext == "Login" {
 		m.shouldFailNext = ""
-		return nil, errors.New("invalid credentials")
+		return nil, apperrors.ErrInvalidCredentials
+	}
+	if m.shouldFailNext == "LoginLocked" {
+		m.shouldFailNext = ""
+		return nil, &models.AccountLockedError{RetryAfter: 90*time.Second + 500*time.Millisecond}
+	}
+	if m.shouldFailNext == "LoginSuspended" {
+		m.shouldFailNext = ""
+		return nil, apperrors.ErrAccountSuspended
 	}
 
 	// 查找用戶
@@ -93,27 +124,91 @@ func (m *MockAuthService) Login(req *models.LoginRequest) (*models.AuthResponse,
 	}
 
 	if foundUser == nil {
-		return nil, errors.New("invalid credentials")
+		return nil, apperrors.ErrInvalidCredentials
 	}
 
 	// 驗證密碼
 	if err := utils.VerifyPassword(foundUser.PasswordHash, req.Password); err != nil {
-		return nil, errors.New("invalid credentials")
+		return nil, apperrors.ErrInvalidCredentials
 	}
 
 	// 生成 JWT
-	token, _ := utils.GenerateJWT(foundUser.ID, foundUser.Email, foundUser.Username)
+	token, _ := testTokenIssuer.GenerateJWT(foundUser.ID, foundUser.Email, foundUser.Username, "session-1", "student", nil, "")
 
 	return &models.AuthResponse{
-		User:  *foundUser,
+		User:  foundUser,
 		Token: token,
 	}, nil
 }
 
-func (m *MockAuthService) GetUserByID(id int) (*models.User, error) {
+func (m *MockAuthService) VerifyMFA(ctx context.Context, req *models.MFAVerifyRequest, client models.ClientInfo) (*models.AuthResponse, error) {
+	if m.shouldFailNext == "VerifyMFA" {
+		m.shouldFailNext = ""
+		return nil, errors.New("database error")
+	}
+
+	if req.MFAToken != "valid-mfa-token" || len(m.users) == 0 {
+		return nil, apperrors.ErrInvalidMFAToken
+	}
+	if req.Code != "123456" {
+		return nil, apperrors.ErrInvalidMFALoginCode
+	}
+
+	user := m.users[0]
+	token, _ := testTokenIssuer.GenerateJWT(user.ID, user.Email, user.Username, "session-1", "student", nil, "")
+	return &models.AuthResponse{
+		User:  &user,
+		Token: token,
+	}, nil
+}
+
+func (m *MockAuthService) Refresh(ctx context.Context, req *models.RefreshRequest, client models.ClientInfo) (*models.AuthResponse, error) {
+	if m.shouldFailNext == "Refresh" {
+		m.shouldFailNext = ""
+		return nil, errors.New("failed to rotate refresh token: database error")
+	}
+
+	switch req.RefreshToken {
+	case "reused-refresh-token":
+		return nil, apperrors.ErrRefreshTokenReused
+	case "valid-refresh-token":
+		if len(m.users) == 0 {
+			return nil, apperrors.ErrInvalidRefreshToken
+		}
+		user := m.users[0]
+		token, _ := testTokenIssuer.GenerateJWT(user.ID, user.Email, user.Username, "session-1", "student", nil, "")
+		return &models.AuthResponse{
+			User:         &user,
+			Token:        token,
+			RefreshToken: "rotated-refresh-token",
+		}, nil
+	}
+
+	return nil, apperrors.ErrInvalidRefreshToken
+}
+
+func (m *MockAuthService) Logout(ctx context.Context, userID int, sessionID, jti string, expiresAt time.Time) error {
+	if m.shouldFailNext == "Logout" {
+		m.shouldFailNext = ""
+		return errors.New("failed to revoke access token: database error")
+	}
+
+	if jti != "" {
+		m.revokedJTIs = append(m.revokedJTIs, jti)
+	}
+	return nil
+}
+
+func (m *MockAuthService) GetUserByID(ctx context.Context, id int) (*models.User, error) {
 	if m.shouldFailNext == "GetUserByID" {
 		m.shouldFailNext = ""
-		return nil, errors.New("user not found")
+		return nil, apperrors.ErrUserNotFound
+	}
+	if m.shouldFailNext == "GetUserByIDSlow" {
+		// 模擬慢查詢：直到請求逾時或用戶端中斷才回傳
+		m.shouldFailNext = ""
+		<-ctx.Done()
+		return nil, fmt.Errorf("failed to get user by id: %w", ctx.Err())
 	}
 
 	for _, user := range m.users {
@@ -121,7 +216,61 @@ func (m *MockAuthService) GetUserByID(id int) (*models.User, error) {
 			return &user, nil
 		}
 	}
-	return nil, errors.New("user not found")
+	return nil, apperrors.ErrUserNotFound
+}
+
+func (m *MockAuthService) ListSessions(ctx context.Context, userID int, currentSessionID string) ([]models.Session, error) {
+	if m.shouldFailNext == "ListSessions" {
+		m.shouldFailNext = ""
+		return nil, errors.New("failed to list sessions: database error")
+	}
+
+	sessions := make([]models.Session, 0)
+	for _, session := range m.sessions {
+		if session.UserID == userID {
+			session.Current = session.ID == currentSessionID
+			sessions = append(sessions, session)
+		}
+	}
+	return sessions, nil
+}
+
+func (m *MockAuthService) RevokeSession(ctx context.Context, userID int, sessionID string) error {
+	for i, session := range m.sessions {
+		if session.ID == sessionID && session.UserID == userID {
+			m.sessions = append(m.sessions[:i], m.sessions[i+1:]...)
+			return nil
+		}
+	}
+	return apperrors.ErrSessionNotFound
+}
+
+func (m *MockAuthService) RevokeOtherSessions(ctx context.Context, userID int, currentSessionID string) (int64, error) {
+	var revoked int64
+	remaining := make([]models.Session, 0)
+	for _, session := range m.sessions {
+		if session.UserID == userID && session.ID != currentSessionID {
+			revoked++
+			continue
+		}
+		remaining = append(remaining, session)
+	}
+	m.sessions = remaining
+	return revoked, nil
+}
+
+func (m *MockAuthService) UnlockAccount(ctx context.Context, userID int) error {
+	if m.shouldFailNext == "UnlockAccount" {
+		m.shouldFailNext = ""
+		return errors.New("failed to unlock account: database error")
+	}
+
+	for _, user := range m.users {
+		if user.ID == userID {
+			return nil
+		}
+	}
+	return apperrors.ErrUserNotFound
 }
 
 func (m *MockAuthService) SetShouldFailNext(method string) {
@@ -135,16 +284,22 @@ func (m *MockAuthService) AddUser(user models.User) {
 // 測試幫助函數
 func setupGin() *gin.Engine {
 	gin.SetMode(gin.TestMode)
-	return gin.New()
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		_ = i18n.RegisterValidator(v)
+	}
+	r := gin.New()
+	r.Use(middleware.Locale())
+	r.Use(middleware.ErrorHandler())
+	return r
 }
 
 func createAuthHandler() *AuthHandler {
 	mockService := NewMockAuthService()
-	return NewAuthHandler(mockService)
+	return NewAuthHandler(mockService, NewMockAuditLogService())
 }
 
 func createAuthHandlerWithService(service *MockAuthService) *AuthHandler {
-	return NewAuthHandler(service)
+	return NewAuthHandler(service, NewMockAuditLogService())
 }
 
 func createTestUser() models.User {
@@ -162,7 +317,7 @@ func createTestUser() models.User {
 
 func TestNewAuthHandler(t *testing.T) {
 	mockService := NewMockAuthService()
-	handler := NewAuthHandler(mockService)
+	handler := NewAuthHandler(mockService, NewMockAuditLogService())
 
 	if handler == nil {
 		t.Fatal("NewAuthHandler() returned nil")
@@ -171,10 +326,6 @@ func TestNewAuthHandler(t *testing.T) {
 	if handler.authService == nil {
 		t.Fatal("NewAuthHandler() authService is nil")
 	}
-
-	if handler.validator == nil {
-		t.Fatal("NewAuthHandler() validator is nil")
-	}
 }
 
 func TestAuthHandler_Register(t *testing.T) {
@@ -309,6 +460,43 @@ func TestAuthHandler_Register(t *testing.T) {
 	}
 }
 
+func TestAuthHandler_Register_PasswordPolicy(t *testing.T) {
+	r := setupGin()
+	handler := createAuthHandlerWithService(NewMockAuthService())
+	r.POST("/register", handler.Register)
+
+	body, _ := json.Marshal(models.RegisterRequest{
+		Email:           "new@example.com",
+		Username:        "newuser",
+		Password:        "abc123",
+		ConfirmPassword: "abc123",
+	})
+	req, _ := http.NewRequest("POST", "/register", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+
+	var response struct {
+		Message string              `json:"message"`
+		Errors  map[string][]string `json:"errors"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if response.Message != "驗證失敗" {
+		t.Errorf("Expected message '驗證失敗', got '%s'", response.Message)
+	}
+
+	want := []string{"密碼至少需要 8 個字符", "密碼強度不足，請避免常見單字、連續或重複的字元"}
+	if got := response.Errors["password"]; strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("Expected password errors %v, got %v", want, got)
+	}
+}
+
 func TestAuthHandler_Login(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -430,10 +618,176 @@ func TestAuthHandler_Login(t *testing.T) {
 	}
 }
 
+func TestAuthHandler_Login_AccountLocked(t *testing.T) {
+	r := setupGin()
+	mockService := NewMockAuthService()
+	mockService.SetShouldFailNext("LoginLocked")
+	handler := createAuthHandlerWithService(mockService)
+	r.POST("/login", handler.Login)
+
+	reqBody, _ := json.Marshal(models.LoginRequest{Email: "test@example.com", Password: "password123"})
+	req, _ := http.NewRequest("POST", "/login", bytes.NewBuffer(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusTooManyRequests {
+		t.Errorf("Expected status %d, got %d", http.StatusTooManyRequests, w.Code)
+	}
+	if got := w.Header().Get("Retry-After"); got != "91" {
+		t.Errorf("Expected Retry-After 91, got %q", got)
+	}
+
+	var response models.APIResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if response.Error == nil || response.Error.Code != "ACCOUNT_LOCKED" {
+		t.Errorf("Expected error code ACCOUNT_LOCKED, got %+v", response.Error)
+	}
+}
+
+func TestAuthHandler_Login_RequestLog(t *testing.T) {
+	tests := []struct {
+		name          string
+		requestID     string
+		wantRequestID func(string) bool
+	}{
+		{
+			name:          "沿用上游的請求 ID",
+			requestID:     "req-123",
+			wantRequestID: func(id string) bool { return id == "req-123" },
+		},
+		{
+			name:          "格式不正確時產生新的請求 ID",
+			requestID:     "bad id\nwith newline",
+			wantRequestID: func(id string) bool { return id != "" && !strings.Contains(id, " ") },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger, err := logging.New(config.LogConfig{Level: "info", Format: "json"}, &buf)
+			if err != nil {
+				t.Fatalf("logging.New() error = %v", err)
+			}
+
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.Use(middleware.RequestID(logger))
+			r.Use(middleware.RequestLogger())
+			r.Use(middleware.Locale())
+			r.Use(middleware.ErrorHandler())
+			mockService := NewMockAuthService()
+			mockService.SetShouldFailNext("Login")
+			r.POST("/api/v1/auth/login", createAuthHandlerWithService(mockService).Login)
+
+			reqBody, _ := json.Marshal(models.LoginRequest{Email: "test@example.com", Password: "password123"})
+			req, _ := http.NewRequest("POST", "/api/v1/auth/login?next=/home", bytes.NewBuffer(reqBody))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set(middleware.RequestIDHeader, tt.requestID)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != http.StatusUnauthorized {
+				t.Fatalf("Expected status %d, got %d", http.StatusUnauthorized, w.Code)
+			}
+			requestID := w.Header().Get(middleware.RequestIDHeader)
+			if !tt.wantRequestID(requestID) {
+				t.Errorf("Unexpected %s header %q", middleware.RequestIDHeader, requestID)
+			}
+
+			var entry map[string]interface{}
+			if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+				t.Fatalf("Expected a single JSON log entry: %v\n%s", err, buf.String())
+			}
+			want := map[string]interface{}{
+				"level":      "WARN",
+				"request_id": requestID,
+				"method":     "POST",
+				"route":      "/api/v1/auth/login",
+				"path":       "/api/v1/auth/login",
+				"status":     float64(http.StatusUnauthorized),
+				"error_code": "INVALID_CREDENTIALS",
+			}
+			for key, value := range want {
+				if entry[key] != value {
+					t.Errorf("Log entry %s = %v, want %v", key, entry[key], value)
+				}
+			}
+			if strings.Contains(buf.String(), "next=") {
+				t.Errorf("Log entry contains the query string: %s", buf.String())
+			}
+		})
+	}
+}
+
+func TestRecovery(t *testing.T) {
+	var buf bytes.Buffer
+	logger, _ := logging.New(config.LogConfig{Level: "info", Format: "json"}, &buf)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(middleware.RequestID(logger))
+	r.Use(middleware.Locale())
+	r.Use(middleware.ErrorHandler())
+	r.Use(middleware.Recovery())
+	r.GET("/panic", func(c *gin.Context) { panic("boom") })
+
+	req, _ := http.NewRequest("GET", "/panic", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("Expected status %d, got %d", http.StatusInternalServerError, w.Code)
+	}
+	var response models.APIResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if response.Error == nil || response.Error.Code != "INTERNAL_SERVER_ERROR" {
+		t.Errorf("Expected error code INTERNAL_SERVER_ERROR, got %+v", response.Error)
+	}
+	if !strings.Contains(buf.String(), `"panic":"boom"`) || !strings.Contains(buf.String(), `"route":"/panic"`) {
+		t.Errorf("Expected the panic to be logged with the request logger, got %s", buf.String())
+	}
+}
+
+func TestAuthHandler_Login_AccountSuspended(t *testing.T) {
+	r := setupGin()
+	mockService := NewMockAuthService()
+	mockService.SetShouldFailNext("LoginSuspended")
+	handler := createAuthHandlerWithService(mockService)
+	r.POST("/login", handler.Login)
+
+	reqBody, _ := json.Marshal(models.LoginRequest{Email: "test@example.com", Password: "password123"})
+	req, _ := http.NewRequest("POST", "/login", bytes.NewBuffer(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Expected status %d, got %d", http.StatusForbidden, w.Code)
+	}
+
+	var response models.APIResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if response.Error == nil || response.Error.Code != "ACCOUNT_SUSPENDED" {
+		t.Errorf("Expected error code ACCOUNT_SUSPENDED, got %+v", response.Error)
+	}
+}
+
 func TestAuthHandler_Logout(t *testing.T) {
 	tests := []struct {
 		name           string
 		setupContext   func(*gin.Context)
+		setupService   func(*MockAuthService)
 		expectedStatus int
 		checkResponse  func(t *testing.T, response models.APIResponse)
 	}{
@@ -443,7 +797,9 @@ func TestAuthHandler_Logout(t *testing.T) {
 				c.Set("user_id", 1)
 				c.Set("username", "testuser")
 				c.Set("email", "test@example.com")
+				c.Set("jti", "test-jti")
 			},
+			setupService:   func(m *MockAuthService) {},
 			expectedStatus: http.StatusOK,
 			checkResponse: func(t *testing.T, response models.APIResponse) {
 				if !response.Success {
@@ -466,11 +822,31 @@ func TestAuthHandler_Logout(t *testing.T) {
 				}
 			},
 		},
+		{
+			name: "撤銷服務錯誤",
+			setupContext: func(c *gin.Context) {
+				c.Set("user_id", 1)
+				c.Set("jti", "fail-jti")
+			},
+			setupService: func(m *MockAuthService) {
+				m.SetShouldFailNext("Logout")
+			},
+			expectedStatus: http.StatusInternalServerError,
+			checkResponse: func(t *testing.T, response models.APIResponse) {
+				if response.Success {
+					t.Error("Expected success to be false")
+				}
+				if response.Message != "登出失敗" {
+					t.Errorf("Expected message '登出失敗', got '%s'", response.Message)
+				}
+			},
+		},
 		{
 			name: "缺少用戶資訊",
 			setupContext: func(c *gin.Context) {
 				// 不設置任何用戶資訊
 			},
+			setupService:   func(m *MockAuthService) {},
 			expectedStatus: http.StatusUnauthorized,
 			checkResponse: func(t *testing.T, response models.APIResponse) {
 				if response.Success {
@@ -487,7 +863,9 @@ func TestAuthHandler_Logout(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			// 設置
 			r := setupGin()
-			handler := createAuthHandler()
+			mockService := NewMockAuthService()
+			tt.setupService(mockService)
+			handler := createAuthHandlerWithService(mockService)
 
 			r.POST("/logout", func(c *gin.Context) {
 				tt.setupContext(c)
@@ -617,4 +995,465 @@ func TestAuthHandler_GetMe(t *testing.T) {
 			tt.checkResponse(t, response)
 		})
 	}
-}
\ No newline at end of file
+}
+
+func TestAuthHandler_GetMe_RequestDeadline(t *testing.T) {
+	newRouter := func(handler *AuthHandler, timeouts middleware.RequestTimeouts) *gin.Engine {
+		// Timeout 須在 ErrorHandler 之前，ErrorHandler 才能在 context 取消前判斷逾時
+		gin.SetMode(gin.TestMode)
+		r := gin.New()
+		r.Use(middleware.Locale())
+		r.Use(middleware.Timeout(timeouts))
+		r.Use(middleware.ErrorHandler())
+		r.GET("/me", func(c *gin.Context) {
+			c.Set("user_id", 1)
+			handler.GetMe(c)
+		})
+		return r
+	}
+
+	t.Run("超過處理時限時取消查詢並回應逾時", func(t *testing.T) {
+		mockService := NewMockAuthService()
+		mockService.AddUser(createTestUser())
+		mockService.shouldFailNext = "GetUserByIDSlow"
+		r := newRouter(createAuthHandlerWithService(mockService), middleware.RequestTimeouts{Default: 20 * time.Millisecond})
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", "/me", nil))
+
+		if w.Code != http.StatusServiceUnavailable {
+			t.Fatalf("Expected status %d, got %d", http.StatusServiceUnavailable, w.Code)
+		}
+		var response models.APIResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+			t.Fatalf("Failed to unmarshal response: %v", err)
+		}
+		if response.Error == nil || response.Error.Code != "REQUEST_TIMEOUT" {
+			t.Errorf("Expected error code REQUEST_TIMEOUT, got %+v", response.Error)
+		}
+	})
+
+	t.Run("個別路由的時限覆寫預設值", func(t *testing.T) {
+		mockService := NewMockAuthService()
+		mockService.AddUser(createTestUser())
+		r := newRouter(createAuthHandlerWithService(mockService), middleware.RequestTimeouts{
+			Default: time.Nanosecond,
+			Routes:  map[string]time.Duration{"GET /me": time.Minute},
+		})
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", "/me", nil))
+
+		if w.Code != http.StatusOK {
+			t.Errorf("Expected status %d, got %d", http.StatusOK, w.Code)
+		}
+	})
+
+	t.Run("用戶端中斷連線時不回應錯誤內容", func(t *testing.T) {
+		mockService := NewMockAuthService()
+		mockService.AddUser(createTestUser())
+		mockService.shouldFailNext = "GetUserByIDSlow"
+		r := newRouter(createAuthHandlerWithService(mockService), middleware.RequestTimeouts{Default: time.Minute})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", "/me", nil).WithContext(ctx))
+
+		if w.Code != middleware.StatusClientClosedRequest {
+			t.Errorf("Expected status %d, got %d", middleware.StatusClientClosedRequest, w.Code)
+		}
+		if w.Body.Len() != 0 {
+			t.Errorf("Expected empty body, got %s", w.Body.String())
+		}
+	})
+}
+
+func TestAuthHandler_Refresh(t *testing.T) {
+	tests := []struct {
+		name           string
+		requestBody    interface{}
+		setupService   func(*MockAuthService)
+		expectedStatus int
+		expectedCode   string
+	}{
+		{
+			name:        "成功換發",
+			requestBody: models.RefreshRequest{RefreshToken: "valid-refresh-token"},
+			setupService: func(m *MockAuthService) {
+				m.AddUser(createTestUser())
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "缺少 refresh token",
+			requestBody:    map[string]string{},
+			setupService:   func(m *MockAuthService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "無效的 refresh token",
+			requestBody:    models.RefreshRequest{RefreshToken: "unknown"},
+			setupService:   func(m *MockAuthService) {},
+			expectedStatus: http.StatusUnauthorized,
+			expectedCode:   "INVALID_REFRESH_TOKEN",
+		},
+		{
+			name:           "重複使用的 refresh token",
+			requestBody:    models.RefreshRequest{RefreshToken: "reused-refresh-token"},
+			setupService:   func(m *MockAuthService) {},
+			expectedStatus: http.StatusUnauthorized,
+			expectedCode:   "REFRESH_TOKEN_REUSED",
+		},
+		{
+			name:        "服務錯誤",
+			requestBody: models.RefreshRequest{RefreshToken: "valid-refresh-token"},
+			setupService: func(m *MockAuthService) {
+				m.SetShouldFailNext("Refresh")
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedCode:   "INTERNAL_SERVER_ERROR",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 設置
+			r := setupGin()
+			mockService := NewMockAuthService()
+			tt.setupService(mockService)
+			handler := createAuthHandlerWithService(mockService)
+
+			r.POST("/refresh", handler.Refresh)
+
+			// 準備請求
+			reqBody, err := json.Marshal(tt.requestBody)
+			if err != nil {
+				t.Fatalf("Failed to marshal request body: %v", err)
+			}
+
+			req, err := http.NewRequest("POST", "/refresh", bytes.NewBuffer(reqBody))
+			if err != nil {
+				t.Fatalf("Failed to create request: %v", err)
+			}
+			req.Header.Set("Content-Type", "application/json")
+
+			// 執行
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			// 驗證
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+
+			var response models.APIResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatalf("Failed to unmarshal response: %v", err)
+			}
+
+			if tt.expectedCode != "" {
+				if response.Error == nil || response.Error.Code != tt.expectedCode {
+					t.Errorf("Expected error code %s, got %+v", tt.expectedCode, response.Error)
+				}
+			}
+		})
+	}
+}
+
+func TestAuthHandler_VerifyMFA(t *testing.T) {
+	tests := []struct {
+		name           string
+		requestBody    interface{}
+		expectedStatus int
+		expectedCode   string
+	}{
+		{
+			name:           "驗證成功",
+			requestBody:    models.MFAVerifyRequest{MFAToken: "valid-mfa-token", Code: "123456"},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "缺少驗證碼",
+			requestBody:    map[string]string{"mfa_token": "valid-mfa-token"},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "待驗證 token 無效",
+			requestBody:    models.MFAVerifyRequest{MFAToken: "expired", Code: "123456"},
+			expectedStatus: http.StatusUnauthorized,
+			expectedCode:   "INVALID_MFA_TOKEN",
+		},
+		{
+			name:           "驗證碼錯誤",
+			requestBody:    models.MFAVerifyRequest{MFAToken: "valid-mfa-token", Code: "000000"},
+			expectedStatus: http.StatusUnauthorized,
+			expectedCode:   "INVALID_MFA_CODE",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 設置
+			r := setupGin()
+			mockService := NewMockAuthService()
+			mockService.AddUser(createTestUser())
+			handler := createAuthHandlerWithService(mockService)
+
+			r.POST("/mfa/verify", handler.VerifyMFA)
+
+			// 準備請求
+			reqBody, err := json.Marshal(tt.requestBody)
+			if err != nil {
+				t.Fatalf("Failed to marshal request body: %v", err)
+			}
+
+			req, err := http.NewRequest("POST", "/mfa/verify", bytes.NewBuffer(reqBody))
+			if err != nil {
+				t.Fatalf("Failed to create request: %v", err)
+			}
+			req.Header.Set("Content-Type", "application/json")
+
+			// 執行
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			// 驗證
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+
+			var response models.APIResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatalf("Failed to unmarshal response: %v", err)
+			}
+
+			if tt.expectedCode != "" {
+				if response.Error == nil || response.Error.Code != tt.expectedCode {
+					t.Errorf("Expected error code %s, got %+v", tt.expectedCode, response.Error)
+				}
+			}
+		})
+	}
+}
+
+func TestAuthHandler_Sessions(t *testing.T) {
+	setup := func() (*gin.Engine, *MockAuthService) {
+		r := setupGin()
+		mockService := NewMockAuthService()
+		mockService.sessions = []models.Session{
+			{ID: "current", UserID: 1, UserAgent: "Laptop", IPAddress: "203.0.113.1"},
+			{ID: "lab-pc", UserID: 1, UserAgent: "Lab PC", IPAddress: "203.0.113.2"},
+			{ID: "other-user", UserID: 2, UserAgent: "Phone", IPAddress: "203.0.113.3"},
+		}
+		handler := createAuthHandlerWithService(mockService)
+
+		withUser := func(c *gin.Context) {
+			c.Set("user_id", 1)
+			c.Set("session_id", "current")
+			c.Next()
+		}
+		r.GET("/sessions", withUser, handler.ListSessions)
+		r.DELETE("/sessions/:id", withUser, handler.RevokeSession)
+		r.POST("/sessions/revoke-others", withUser, handler.RevokeOtherSessions)
+		return r, mockService
+	}
+
+	doRequest := func(r *gin.Engine, method, path string) (*httptest.ResponseRecorder, models.APIResponse) {
+		req, err := http.NewRequest(method, path, nil)
+		if err != nil {
+			t.Fatalf("Failed to create request: %v", err)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		var response models.APIResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+			t.Fatalf("Failed to unmarshal response: %v", err)
+		}
+		return w, response
+	}
+
+	t.Run("列出會話", func(t *testing.T) {
+		r, _ := setup()
+		w, response := doRequest(r, "GET", "/sessions")
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+		}
+
+		data, ok := response.Data.(map[string]interface{})
+		if !ok {
+			t.Fatal("Expected data to be a map")
+		}
+		sessions, ok := data["sessions"].([]interface{})
+		if !ok || len(sessions) != 2 {
+			t.Fatalf("Expected 2 sessions, got %v", data["sessions"])
+		}
+		first := sessions[0].(map[string]interface{})
+		if first["current"] != true {
+			t.Error("Expected current session to be flagged")
+		}
+	})
+
+	t.Run("列出會話失敗", func(t *testing.T) {
+		r, mockService := setup()
+		mockService.SetShouldFailNext("ListSessions")
+		w, _ := doRequest(r, "GET", "/sessions")
+		if w.Code != http.StatusInternalServerError {
+			t.Errorf("Expected status %d, got %d", http.StatusInternalServerError, w.Code)
+		}
+	})
+
+	t.Run("結束指定會話", func(t *testing.T) {
+		r, mockService := setup()
+		w, response := doRequest(r, "DELETE", "/sessions/lab-pc")
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+		}
+		if response.Message != "會話已結束" {
+			t.Errorf("Expected message '會話已結束', got '%s'", response.Message)
+		}
+		if len(mockService.sessions) != 2 {
+			t.Errorf("Expected 2 remaining sessions, got %d", len(mockService.sessions))
+		}
+	})
+
+	t.Run("不可結束其他用戶的會話", func(t *testing.T) {
+		r, _ := setup()
+		w, response := doRequest(r, "DELETE", "/sessions/other-user")
+		if w.Code != http.StatusNotFound {
+			t.Fatalf("Expected status %d, got %d", http.StatusNotFound, w.Code)
+		}
+		if response.Error == nil || response.Error.Code != "SESSION_NOT_FOUND" {
+			t.Errorf("Expected SESSION_NOT_FOUND, got %+v", response.Error)
+		}
+	})
+
+	t.Run("登出其他裝置", func(t *testing.T) {
+		r, _ := setup()
+		w, response := doRequest(r, "POST", "/sessions/revoke-others")
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+		}
+		data, ok := response.Data.(map[string]interface{})
+		if !ok || data["revoked_sessions"] != float64(1) {
+			t.Errorf("Expected 1 revoked session, got %v", response.Data)
+		}
+	})
+}
+
+func TestAuthHandler_Logout_AuditLog(t *testing.T) {
+	r := setupGin()
+	auditLog := NewMockAuditLogService()
+	handler := NewAuthHandler(NewMockAuthService(), auditLog)
+	r.POST("/logout", func(c *gin.Context) {
+		c.Set("user_id", 1)
+		c.Set("session_id", "session-1")
+		c.Set("request_id", "req-1")
+		handler.Logout(c)
+	})
+
+	req, _ := http.NewRequest("POST", "/logout", nil)
+	req.RemoteAddr = "203.0.113.1:1234"
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	entry := auditLog.lastEntry()
+	if entry == nil || entry.EventType != models.AuditEventLogout || entry.ActorID == nil || *entry.ActorID != 1 {
+		t.Fatalf("Expected logout audit entry, got %+v", entry)
+	}
+	if entry.IPAddress != "203.0.113.1" || entry.RequestID != "req-1" || entry.Metadata["session_id"] != "session-1" {
+		t.Errorf("Logout audit entry = %+v", entry)
+	}
+}
+
+func TestAuthHandler_Localization(t *testing.T) {
+	tests := []struct {
+		name            string
+		acceptLanguage  string
+		requestBody     interface{}
+		expectedStatus  int
+		expectedLocale  string
+		expectedMessage string
+		expectedErrors  map[string][]string
+		expectedError   string
+	}{
+		{
+			name:            "英文驗證訊息",
+			acceptLanguage:  "en-US,en;q=0.9",
+			requestBody:     map[string]interface{}{"email": "not-an-email"},
+			expectedStatus:  http.StatusBadRequest,
+			expectedLocale:  "en",
+			expectedMessage: "Validation failed",
+			expectedErrors: map[string][]string{
+				"email":    {"email must be a valid email address"},
+				"password": {"password is a required field"},
+			},
+		},
+		{
+			name:            "日文錯誤訊息",
+			acceptLanguage:  "ja",
+			requestBody:     models.LoginRequest{Email: "nobody@example.com", Password: "password123"},
+			expectedStatus:  http.StatusUnauthorized,
+			expectedLocale:  "ja",
+			expectedMessage: "ログインに失敗しました",
+			expectedError:   "メールアドレスまたはパスワードが正しくありません",
+		},
+		{
+			name:            "不支援的語系使用正體中文",
+			acceptLanguage:  "fr-FR",
+			requestBody:     map[string]interface{}{"email": "test@example.com"},
+			expectedStatus:  http.StatusBadRequest,
+			expectedLocale:  "zh-TW",
+			expectedMessage: "驗證失敗",
+			expectedErrors: map[string][]string{
+				"password": {"password為必填欄位"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := setupGin()
+			handler := createAuthHandler()
+			r.POST("/login", handler.Login)
+
+			body, _ := json.Marshal(tt.requestBody)
+			req, _ := http.NewRequest("POST", "/login", bytes.NewBuffer(body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Accept-Language", tt.acceptLanguage)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+			if got := w.Header().Get("Content-Language"); got != tt.expectedLocale {
+				t.Errorf("Expected Content-Language %q, got %q", tt.expectedLocale, got)
+			}
+
+			var response struct {
+				Message string              `json:"message"`
+				Error   *models.APIError    `json:"error"`
+				Errors  map[string][]string `json:"errors"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatalf("Failed to unmarshal response: %v", err)
+			}
+			if response.Message != tt.expectedMessage {
+				t.Errorf("Expected message %q, got %q", tt.expectedMessage, response.Message)
+			}
+			for field, want := range tt.expectedErrors {
+				if got := response.Errors[field]; strings.Join(got, "|") != strings.Join(want, "|") {
+					t.Errorf("Expected %s errors %v, got %v", field, want, got)
+				}
+			}
+			if tt.expectedError != "" && (response.Error == nil || response.Error.Message != tt.expectedError) {
+				t.Errorf("Expected error message %q, got %+v", tt.expectedError, response.Error)
+			}
+		})
+	}
+}
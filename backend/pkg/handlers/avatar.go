@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"smart-learning-backend/pkg/apperrors"
+	"smart-learning-backend/pkg/interfaces"
+	"smart-learning-backend/pkg/models"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// multipartOverheadBytes 為 multipart 邊界與標頭預留的空間，請求本體上限為 AvatarMaxBytes 加上此值
+const multipartOverheadBytes = 1 << 20
+
+type AvatarHandler struct {
+	avatarService interfaces.AvatarServiceInterface
+}
+
+func NewAvatarHandler(avatarService interfaces.AvatarServiceInterface) *AvatarHandler {
+	return &AvatarHandler{
+		avatarService: avatarService,
+	}
+}
+
+// UploadAvatar 以 multipart/form-data 的 avatar 欄位上傳頭像
+func (h *AvatarHandler) UploadAvatar(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, models.AvatarMaxBytes+multipartOverheadBytes)
+	fileHeader, err := c.FormFile("avatar")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			abortWithError(c, apperrors.ErrAvatarTooLarge.WithArgs(models.AvatarMaxBytes>>20), tr(c, "avatar.upload_failed"))
+			return
+		}
+
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: tr(c, "errors.validation_failed"),
+			Errors: map[string][]string{
+				"avatar": {tr(c, "avatar.file_required")},
+			},
+		})
+		return
+	}
+	if fileHeader.Size > models.AvatarMaxBytes {
+		abortWithError(c, apperrors.ErrAvatarTooLarge.WithArgs(models.AvatarMaxBytes>>20), tr(c, "avatar.upload_failed"))
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		abortWithError(c, err, tr(c, "avatar.upload_failed"))
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		abortWithError(c, err, tr(c, "avatar.upload_failed"))
+		return
+	}
+
+	user, err := h.avatarService.UploadAvatar(c.Request.Context(), userID, data)
+	if err != nil {
+		abortWithError(c, err, tr(c, "avatar.upload_failed"))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: tr(c, "avatar.updated"),
+		Data:    user,
+	})
+}
+
+// DeleteAvatar 移除目前登入用戶的頭像
+func (h *AvatarHandler) DeleteAvatar(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	user, err := h.avatarService.DeleteAvatar(c.Request.Context(), userID)
+	if err != nil {
+		abortWithError(c, err, tr(c, "avatar.delete_failed"))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: tr(c, "avatar.deleted"),
+		Data:    user,
+	})
+}
+
+// GetAvatar 轉址到頭像檔案的下載網址；不需要登入，可直接作為 <img> 的來源
+func (h *AvatarHandler) GetAvatar(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || userID <= 0 {
+		abortWithError(c, apperrors.ErrAvatarNotFound, tr(c, "avatar.get_failed"))
+		return
+	}
+
+	size := 0
+	if value := c.Query("size"); value != "" {
+		if size, err = strconv.Atoi(value); err != nil {
+			// 不是數字時交由服務視為不支援的尺寸
+			size = -1
+		}
+	}
+
+	avatarURL, err := h.avatarService.AvatarURL(userID, c.Query("v"), size)
+	if err != nil {
+		abortWithError(c, err, tr(c, "avatar.get_failed"))
+		return
+	}
+
+	c.Redirect(http.StatusFound, avatarURL)
+}
@@ -0,0 +1,262 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"smart-learning-backend/pkg/apperrors"
+	"smart-learning-backend/pkg/models"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MockAvatarService 實現了 AvatarServiceInterface 介面用於測試
+type MockAvatarService struct {
+	uploaded       []byte
+	hasAvatar      bool
+	shouldFailNext string
+}
+
+func NewMockAvatarService() *MockAvatarService {
+	return &MockAvatarService{}
+}
+
+func (m *MockAvatarService) UploadAvatar(ctx context.Context, userID int, data []byte) (*models.User, error) {
+	if m.shouldFailNext == "UploadAvatar" {
+		m.shouldFailNext = ""
+		return nil, errors.New("failed to store avatar: storage unavailable")
+	}
+	switch string(data) {
+	case "<svg/>":
+		return nil, apperrors.ErrUnsupportedImageType
+	case "broken":
+		return nil, apperrors.ErrInvalidImage.Wrap(errors.New("unexpected EOF"))
+	}
+
+	m.uploaded = data
+	avatarURL := "http://localhost:8080/api/v1/users/1/avatar?v=abc"
+	return &models.User{ID: userID, AvatarURL: &avatarURL}, nil
+}
+
+func (m *MockAvatarService) DeleteAvatar(ctx context.Context, userID int) (*models.User, error) {
+	if !m.hasAvatar {
+		return nil, apperrors.ErrAvatarNotFound
+	}
+	return &models.User{ID: userID}, nil
+}
+
+func (m *MockAvatarService) AvatarURL(userID int, version string, size int) (string, error) {
+	if version != "abc" {
+		return "", apperrors.ErrAvatarNotFound
+	}
+	if size != 0 && size != 64 {
+		return "", apperrors.ErrInvalidAvatarSize
+	}
+	return "https://blobs.example.com/avatars/1/abc/64.jpg?signature=test", nil
+}
+
+func (m *MockAvatarService) SetShouldFailNext(method string) {
+	m.shouldFailNext = method
+}
+
+// multipartBody 建立含單一檔案欄位的 multipart 請求本體
+func multipartBody(field string, data []byte) (*bytes.Buffer, string) {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	if field != "" {
+		part, _ := writer.CreateFormFile(field, "avatar.png")
+		part.Write(data)
+	}
+	writer.Close()
+	return body, writer.FormDataContentType()
+}
+
+func TestAvatarHandler_UploadAvatar(t *testing.T) {
+	tests := []struct {
+		name           string
+		field          string
+		data           []byte
+		setupService   func(*MockAvatarService)
+		expectedStatus int
+		expectedCode   string
+		expectedField  string
+	}{
+		{
+			name:           "上傳成功",
+			field:          "avatar",
+			data:           []byte("png-data"),
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "缺少檔案",
+			field:          "other",
+			data:           []byte("png-data"),
+			expectedStatus: http.StatusBadRequest,
+			expectedField:  "avatar",
+		},
+		{
+			name:           "檔案超過上限",
+			field:          "avatar",
+			data:           make([]byte, models.AvatarMaxBytes+1),
+			expectedStatus: http.StatusRequestEntityTooLarge,
+			expectedCode:   "AVATAR_TOO_LARGE",
+		},
+		{
+			name:           "請求本體超過上限",
+			field:          "avatar",
+			data:           make([]byte, models.AvatarMaxBytes+multipartOverheadBytes+1),
+			expectedStatus: http.StatusRequestEntityTooLarge,
+			expectedCode:   "AVATAR_TOO_LARGE",
+		},
+		{
+			name:           "不支援的格式",
+			field:          "avatar",
+			data:           []byte("<svg/>"),
+			expectedStatus: http.StatusUnsupportedMediaType,
+			expectedCode:   "UNSUPPORTED_IMAGE_TYPE",
+		},
+		{
+			name:           "損壞的圖片",
+			field:          "avatar",
+			data:           []byte("broken"),
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   "INVALID_IMAGE",
+		},
+		{
+			name:  "儲存失敗",
+			field: "avatar",
+			data:  []byte("png-data"),
+			setupService: func(m *MockAvatarService) {
+				m.SetShouldFailNext("UploadAvatar")
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedCode:   "INTERNAL_SERVER_ERROR",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 設置
+			r := setupGin()
+			mockService := NewMockAvatarService()
+			if tt.setupService != nil {
+				tt.setupService(mockService)
+			}
+			handler := NewAvatarHandler(mockService)
+			r.POST("/users/me/avatar", func(c *gin.Context) {
+				c.Set("user_id", 1)
+				handler.UploadAvatar(c)
+			})
+
+			// 執行
+			body, contentType := multipartBody(tt.field, tt.data)
+			req, _ := http.NewRequest("POST", "/users/me/avatar", body)
+			req.Header.Set("Content-Type", contentType)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			// 驗證
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+
+			var response models.APIResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatalf("Failed to unmarshal response: %v", err)
+			}
+			if tt.expectedCode != "" && (response.Error == nil || response.Error.Code != tt.expectedCode) {
+				t.Errorf("Expected error code %s, got %+v", tt.expectedCode, response.Error)
+			}
+			if tt.expectedField != "" {
+				errs, _ := response.Errors.(map[string]interface{})
+				if _, ok := errs[tt.expectedField]; !ok {
+					t.Errorf("Expected validation error for %s, got %v", tt.expectedField, response.Errors)
+				}
+			}
+			if tt.expectedStatus == http.StatusOK && string(mockService.uploaded) != string(tt.data) {
+				t.Errorf("service received %q, want %q", mockService.uploaded, tt.data)
+			}
+		})
+	}
+}
+
+func TestAvatarHandler_DeleteAvatar(t *testing.T) {
+	tests := []struct {
+		name           string
+		hasAvatar      bool
+		expectedStatus int
+		expectedCode   string
+	}{
+		{name: "移除成功", hasAvatar: true, expectedStatus: http.StatusOK},
+		{name: "尚未設定頭像", hasAvatar: false, expectedStatus: http.StatusNotFound, expectedCode: "AVATAR_NOT_FOUND"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := setupGin()
+			mockService := NewMockAvatarService()
+			mockService.hasAvatar = tt.hasAvatar
+			handler := NewAvatarHandler(mockService)
+			r.DELETE("/users/me/avatar", func(c *gin.Context) {
+				c.Set("user_id", 1)
+				handler.DeleteAvatar(c)
+			})
+
+			req, _ := http.NewRequest("DELETE", "/users/me/avatar", nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+			var response models.APIResponse
+			json.Unmarshal(w.Body.Bytes(), &response)
+			if tt.expectedCode != "" && (response.Error == nil || response.Error.Code != tt.expectedCode) {
+				t.Errorf("Expected error code %s, got %+v", tt.expectedCode, response.Error)
+			}
+		})
+	}
+}
+
+func TestAvatarHandler_GetAvatar(t *testing.T) {
+	tests := []struct {
+		name             string
+		path             string
+		expectedStatus   int
+		expectedLocation string
+	}{
+		{
+			name:             "轉址到下載網址",
+			path:             "/users/1/avatar?v=abc&size=64",
+			expectedStatus:   http.StatusFound,
+			expectedLocation: "https://blobs.example.com/avatars/1/abc/64.jpg?signature=test",
+		},
+		{name: "不支援的尺寸", path: "/users/1/avatar?v=abc&size=100", expectedStatus: http.StatusBadRequest},
+		{name: "尺寸不是數字", path: "/users/1/avatar?v=abc&size=large", expectedStatus: http.StatusBadRequest},
+		{name: "版本不存在", path: "/users/1/avatar?v=missing", expectedStatus: http.StatusNotFound},
+		{name: "用戶 ID 格式錯誤", path: "/users/me/avatar?v=abc", expectedStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := setupGin()
+			r.GET("/users/:id/avatar", NewAvatarHandler(NewMockAvatarService()).GetAvatar)
+
+			req, _ := http.NewRequest("GET", tt.path, nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+			if tt.expectedLocation != "" && w.Header().Get("Location") != tt.expectedLocation {
+				t.Errorf("Location = %s, want %s", w.Header().Get("Location"), tt.expectedLocation)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"net/http"
+	"smart-learning-backend/pkg/interfaces"
+	"smart-learning-backend/pkg/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+type EmailVerificationHandler struct {
+	emailVerificationService interfaces.EmailVerificationServiceInterface
+}
+
+func NewEmailVerificationHandler(emailVerificationService interfaces.EmailVerificationServiceInterface) *EmailVerificationHandler {
+	return &EmailVerificationHandler{
+		emailVerificationService: emailVerificationService,
+	}
+}
+
+// VerifyEmail 以查詢參數中的 token 完成 email 驗證
+func (h *EmailVerificationHandler) VerifyEmail(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: tr(c, "errors.validation_failed"),
+			Errors: map[string][]string{
+				"token": {tr(c, "validation.required")},
+			},
+		})
+		return
+	}
+
+	if err := h.emailVerificationService.VerifyEmail(c.Request.Context(), token); err != nil {
+		abortWithError(c, err, tr(c, "errors.validation_failed"))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: tr(c, "email_verification.verified"),
+	})
+}
+
+// ResendVerification 重新寄送驗證郵件給目前登入的用戶
+func (h *EmailVerificationHandler) ResendVerification(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.emailVerificationService.ResendVerification(c.Request.Context(), userID); err != nil {
+		abortWithError(c, err, tr(c, "email_verification.send_failed"))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: tr(c, "email_verification.sent"),
+	})
+}
@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"smart-learning-backend/pkg/apperrors"
+	"smart-learning-backend/pkg/models"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MockEmailVerificationService 實現了 EmailVerificationServiceInterface 介面用於測試
+type MockEmailVerificationService struct {
+	verifiedUsers  map[int]bool
+	shouldFailNext string
+}
+
+func NewMockEmailVerificationService() *MockEmailVerificationService {
+	return &MockEmailVerificationService{
+		verifiedUsers: make(map[int]bool),
+	}
+}
+
+func (m *MockEmailVerificationService) SendVerification(ctx context.Context, user *models.User) error {
+	return nil
+}
+
+func (m *MockEmailVerificationService) SendEmailChangeVerification(ctx context.Context, user *models.User, newEmail string) error {
+	return nil
+}
+
+func (m *MockEmailVerificationService) VerifyEmail(ctx context.Context, token string) error {
+	if m.shouldFailNext == "VerifyEmail" {
+		m.shouldFailNext = ""
+		return errors.New("database error")
+	}
+	if token == "taken-email-change-token" {
+		return apperrors.ErrEmailAlreadyInUse
+	}
+	if token != "valid-verification-token" {
+		return apperrors.ErrInvalidVerificationToken
+	}
+	return nil
+}
+
+func (m *MockEmailVerificationService) ResendVerification(ctx context.Context, userID int) error {
+	if m.verifiedUsers[userID] {
+		return apperrors.ErrEmailAlreadyVerified
+	}
+	return nil
+}
+
+func (m *MockEmailVerificationService) SetShouldFailNext(method string) {
+	m.shouldFailNext = method
+}
+
+func TestEmailVerificationHandler_VerifyEmail(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		setupService   func(*MockEmailVerificationService)
+		expectedStatus int
+		expectedCode   string
+	}{
+		{
+			name:           "驗證成功",
+			query:          "?token=valid-verification-token",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "缺少 token",
+			query:          "",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "無效的 token",
+			query:          "?token=unknown",
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   "INVALID_VERIFICATION_TOKEN",
+		},
+		{
+			name:           "變更的 email 已被使用",
+			query:          "?token=taken-email-change-token",
+			expectedStatus: http.StatusConflict,
+			expectedCode:   "EMAIL_ALREADY_IN_USE",
+		},
+		{
+			name:  "服務錯誤",
+			query: "?token=valid-verification-token",
+			setupService: func(m *MockEmailVerificationService) {
+				m.SetShouldFailNext("VerifyEmail")
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedCode:   "INTERNAL_SERVER_ERROR",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 設置
+			r := setupGin()
+			mockService := NewMockEmailVerificationService()
+			if tt.setupService != nil {
+				tt.setupService(mockService)
+			}
+			r.GET("/verify", NewEmailVerificationHandler(mockService).VerifyEmail)
+
+			// 執行
+			req, _ := http.NewRequest("GET", "/verify"+tt.query, nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			// 驗證
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+
+			var response models.APIResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatalf("Failed to unmarshal response: %v", err)
+			}
+
+			if tt.expectedCode != "" {
+				if response.Error == nil || response.Error.Code != tt.expectedCode {
+					t.Errorf("Expected error code %s, got %+v", tt.expectedCode, response.Error)
+				}
+			}
+		})
+	}
+}
+
+func TestEmailVerificationHandler_ResendVerification(t *testing.T) {
+	tests := []struct {
+		name           string
+		setupContext   func(*gin.Context)
+		expectedStatus int
+		expectedCode   string
+	}{
+		{
+			name: "寄送成功",
+			setupContext: func(c *gin.Context) {
+				c.Set("user_id", 1)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "已完成驗證",
+			setupContext: func(c *gin.Context) {
+				c.Set("user_id", 2)
+			},
+			expectedStatus: http.StatusConflict,
+			expectedCode:   "EMAIL_ALREADY_VERIFIED",
+		},
+		{
+			name:           "未登入",
+			setupContext:   func(c *gin.Context) {},
+			expectedStatus: http.StatusUnauthorized,
+			expectedCode:   "UNAUTHORIZED",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 設置
+			r := setupGin()
+			mockService := NewMockEmailVerificationService()
+			mockService.verifiedUsers[2] = true
+			handler := NewEmailVerificationHandler(mockService)
+
+			r.POST("/verify/resend", func(c *gin.Context) {
+				tt.setupContext(c)
+				handler.ResendVerification(c)
+			})
+
+			// 執行
+			req, _ := http.NewRequest("POST", "/verify/resend", nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			// 驗證
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+
+			var response models.APIResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatalf("Failed to unmarshal response: %v", err)
+			}
+
+			if tt.expectedCode != "" {
+				if response.Error == nil || response.Error.Code != tt.expectedCode {
+					t.Errorf("Expected error code %s, got %+v", tt.expectedCode, response.Error)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,12 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// abortWithError 將服務錯誤交由 middleware.ErrorHandler 依錯誤代碼回應，message 為回應的 message；
+// 欄位驗證錯誤的 message 一律為「驗證失敗」
+func abortWithError(c *gin.Context, err error, message string) {
+	_ = c.Error(err).SetMeta(message)
+	c.Abort()
+}
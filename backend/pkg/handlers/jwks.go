@@ -0,0 +1,25 @@
+package handlers
+
+import (
+	"net/http"
+	"smart-learning-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type JWKSHandler struct {
+	keyManager *utils.KeyManager
+}
+
+func NewJWKSHandler(keyManager *utils.KeyManager) *JWKSHandler {
+	return &JWKSHandler{
+		keyManager: keyManager,
+	}
+}
+
+// GetJWKS 公開目前接受的 JWT 驗證公鑰，供其他服務驗證 Smart Learning 簽發的 token。
+// 回應遵循 RFC 7517 格式，不使用 APIResponse 包裝。
+func (h *JWKSHandler) GetJWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, h.keyManager.JWKS())
+}
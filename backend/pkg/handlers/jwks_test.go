@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"smart-learning-backend/pkg/utils"
+	"testing"
+)
+
+func TestJWKSHandler_GetJWKS(t *testing.T) {
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("ed25519.GenerateKey() error = %v", err)
+	}
+	key, err := utils.NewAsymmetricKey("ed-1", edKey)
+	if err != nil {
+		t.Fatalf("NewAsymmetricKey() error = %v", err)
+	}
+
+	keyManager := utils.NewKeyManager()
+	keyManager.SetSigningKey(key)
+	keyManager.AddVerificationKey(utils.NewHMACKey("hs256", []byte("secret")))
+
+	r := setupGin()
+	r.GET("/.well-known/jwks.json", NewJWKSHandler(keyManager).GetJWKS)
+
+	req, err := http.NewRequest("GET", "/.well-known/jwks.json", nil)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	if w.Header().Get("Cache-Control") == "" {
+		t.Error("Expected Cache-Control header")
+	}
+
+	var jwks utils.JWKSet
+	if err := json.Unmarshal(w.Body.Bytes(), &jwks); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if len(jwks.Keys) != 1 {
+		t.Fatalf("Expected 1 public key, got %d", len(jwks.Keys))
+	}
+	if jwks.Keys[0].KeyID != "ed-1" || jwks.Keys[0].KeyType != "OKP" || jwks.Keys[0].X == "" {
+		t.Errorf("Unexpected JWK %+v", jwks.Keys[0])
+	}
+}
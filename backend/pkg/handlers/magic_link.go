@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"net/http"
+	"smart-learning-backend/pkg/interfaces"
+	"smart-learning-backend/pkg/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+type MagicLinkHandler struct {
+	magicLinkService interfaces.MagicLinkServiceInterface
+}
+
+func NewMagicLinkHandler(magicLinkService interfaces.MagicLinkServiceInterface) *MagicLinkHandler {
+	return &MagicLinkHandler{
+		magicLinkService: magicLinkService,
+	}
+}
+
+// RequestMagicLink 寄送免密碼登入連結；不論 email 是否已註冊皆回傳相同訊息
+func (h *MagicLinkHandler) RequestMagicLink(c *gin.Context) {
+	var req models.MagicLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationFailed(c, bindingErrors(c, err))
+		return
+	}
+
+	if err := h.magicLinkService.RequestMagicLink(c.Request.Context(), &req); err != nil {
+		abortWithError(c, err, tr(c, "magic_link.send_failed"))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: tr(c, "magic_link.sent"),
+	})
+}
+
+// LoginWithMagicLink 以登入連結中的 token 完成登入
+func (h *MagicLinkHandler) LoginWithMagicLink(c *gin.Context) {
+	var req models.MagicLinkLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationFailed(c, bindingErrors(c, err))
+		return
+	}
+
+	authResponse, err := h.magicLinkService.LoginWithMagicLink(c.Request.Context(), &req, clientInfo(c))
+	if err != nil {
+		abortWithError(c, err, tr(c, "auth.login_failed"))
+		return
+	}
+
+	message := tr(c, "auth.login_success")
+	if authResponse.MFARequired {
+		message = tr(c, "auth.mfa_required")
+	}
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: message,
+		Data:    authResponse,
+	})
+}
@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"smart-learning-backend/pkg/apperrors"
+	"smart-learning-backend/pkg/models"
+	"testing"
+	"time"
+)
+
+// MockMagicLinkService 實現了 MagicLinkServiceInterface 介面用於測試
+type MockMagicLinkService struct {
+	shouldFailNext string
+}
+
+func (m *MockMagicLinkService) RequestMagicLink(ctx context.Context, req *models.MagicLinkRequest) error {
+	switch m.shouldFailNext {
+	case "RequestMagicLink":
+		m.shouldFailNext = ""
+		return errors.New("database error")
+	case "RateLimited":
+		m.shouldFailNext = ""
+		return &models.RateLimitedError{RetryAfter: 90 * time.Second}
+	}
+	return nil
+}
+
+func (m *MockMagicLinkService) LoginWithMagicLink(ctx context.Context, req *models.MagicLinkLoginRequest, client models.ClientInfo) (*models.AuthResponse, error) {
+	if m.shouldFailNext == "LoginWithMagicLink" {
+		m.shouldFailNext = ""
+		return nil, errors.New("database error")
+	}
+	if req.Token != "valid-magic-token" {
+		return nil, apperrors.ErrInvalidMagicLink
+	}
+	return &models.AuthResponse{Token: "access", RefreshToken: "refresh"}, nil
+}
+
+func (m *MockMagicLinkService) SetShouldFailNext(method string) {
+	m.shouldFailNext = method
+}
+
+func TestMagicLinkHandler(t *testing.T) {
+	tests := []struct {
+		name             string
+		path             string
+		requestBody      interface{}
+		setupService     func(*MockMagicLinkService)
+		expectedStatus   int
+		expectedCode     string
+		expectedField    string
+		expectRetryAfter string
+	}{
+		{
+			name:           "申請登入連結成功",
+			path:           "/magic-link",
+			requestBody:    models.MagicLinkRequest{Email: "test@example.com"},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "email 格式錯誤",
+			path:           "/magic-link",
+			requestBody:    models.MagicLinkRequest{Email: "invalid-email"},
+			expectedStatus: http.StatusBadRequest,
+			expectedField:  "email",
+		},
+		{
+			name:        "同一 email 申請過於頻繁",
+			path:        "/magic-link",
+			requestBody: models.MagicLinkRequest{Email: "test@example.com"},
+			setupService: func(m *MockMagicLinkService) {
+				m.SetShouldFailNext("RateLimited")
+			},
+			expectedStatus:   http.StatusTooManyRequests,
+			expectedCode:     "RATE_LIMIT_EXCEEDED",
+			expectRetryAfter: "90",
+		},
+		{
+			name:        "申請登入連結服務錯誤",
+			path:        "/magic-link",
+			requestBody: models.MagicLinkRequest{Email: "test@example.com"},
+			setupService: func(m *MockMagicLinkService) {
+				m.SetShouldFailNext("RequestMagicLink")
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedCode:   "INTERNAL_SERVER_ERROR",
+		},
+		{
+			name:           "以登入連結登入成功",
+			path:           "/magic-link/verify",
+			requestBody:    models.MagicLinkLoginRequest{Token: "valid-magic-token"},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "缺少 token",
+			path:           "/magic-link/verify",
+			requestBody:    map[string]string{},
+			expectedStatus: http.StatusBadRequest,
+			expectedField:  "token",
+		},
+		{
+			name:           "登入連結無效",
+			path:           "/magic-link/verify",
+			requestBody:    models.MagicLinkLoginRequest{Token: "expired-magic-token"},
+			expectedStatus: http.StatusUnauthorized,
+			expectedCode:   "INVALID_MAGIC_LINK",
+		},
+		{
+			name:        "登入服務錯誤",
+			path:        "/magic-link/verify",
+			requestBody: models.MagicLinkLoginRequest{Token: "valid-magic-token"},
+			setupService: func(m *MockMagicLinkService) {
+				m.SetShouldFailNext("LoginWithMagicLink")
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedCode:   "INTERNAL_SERVER_ERROR",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := setupGin()
+			mockService := &MockMagicLinkService{}
+			if tt.setupService != nil {
+				tt.setupService(mockService)
+			}
+			handler := NewMagicLinkHandler(mockService)
+
+			r.POST("/magic-link", handler.RequestMagicLink)
+			r.POST("/magic-link/verify", handler.LoginWithMagicLink)
+
+			reqBody, err := json.Marshal(tt.requestBody)
+			if err != nil {
+				t.Fatalf("Failed to marshal request body: %v", err)
+			}
+
+			req, err := http.NewRequest("POST", tt.path, bytes.NewBuffer(reqBody))
+			if err != nil {
+				t.Fatalf("Failed to create request: %v", err)
+			}
+			req.Header.Set("Content-Type", "application/json")
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+			if tt.expectRetryAfter != "" && w.Header().Get("Retry-After") != tt.expectRetryAfter {
+				t.Errorf("Expected Retry-After %s, got %q", tt.expectRetryAfter, w.Header().Get("Retry-After"))
+			}
+
+			var response models.APIResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatalf("Failed to unmarshal response: %v", err)
+			}
+
+			if tt.expectedCode != "" {
+				if response.Error == nil || response.Error.Code != tt.expectedCode {
+					t.Errorf("Expected error code %s, got %+v", tt.expectedCode, response.Error)
+				}
+			}
+
+			if tt.expectedField != "" {
+				errs, _ := response.Errors.(map[string]interface{})
+				if _, ok := errs[tt.expectedField]; !ok {
+					t.Errorf("Expected validation error for %s, got %v", tt.expectedField, response.Errors)
+				}
+			}
+		})
+	}
+}
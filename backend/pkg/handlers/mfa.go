@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"net/http"
+	"smart-learning-backend/pkg/apperrors"
+	"smart-learning-backend/pkg/interfaces"
+	"smart-learning-backend/pkg/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+type MFAHandler struct {
+	mfaService interfaces.MFAServiceInterface
+	auditLog   interfaces.AuditRecorderInterface
+}
+
+func NewMFAHandler(mfaService interfaces.MFAServiceInterface, auditLog interfaces.AuditRecorderInterface) *MFAHandler {
+	return &MFAHandler{
+		mfaService: mfaService,
+		auditLog:   auditLog,
+	}
+}
+
+// Setup 產生 TOTP 密鑰與 otpauth:// URI，供驗證器 App 掃描
+func (h *MFAHandler) Setup(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	setup, err := h.mfaService.Setup(c.Request.Context(), userID)
+	if err != nil {
+		abortWithError(c, err, tr(c, "mfa.setup_failed"))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: tr(c, "mfa.setup_started"),
+		Data:    setup,
+	})
+}
+
+// Confirm 以第一組驗證碼啟用兩步驟驗證，回傳僅顯示一次的復原碼
+func (h *MFAHandler) Confirm(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationFailed(c, bindingErrors(c, err))
+		return
+	}
+
+	recoveryCodes, err := h.mfaService.Confirm(c.Request.Context(), userID, req.Code)
+	if err != nil {
+		abortWithError(c, err, tr(c, "mfa.setup_failed"))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: tr(c, "mfa.enabled"),
+		Data: map[string]interface{}{
+			"recovery_codes": recoveryCodes,
+		},
+	})
+}
+
+// Disable 驗證目前的驗證碼或復原碼後停用兩步驟驗證
+func (h *MFAHandler) Disable(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationFailed(c, bindingErrors(c, err))
+		return
+	}
+
+	if err := h.mfaService.Disable(c.Request.Context(), userID, req.Code); err != nil {
+		abortWithError(c, err, tr(c, "mfa.disable_failed"))
+		return
+	}
+	recordAudit(c, h.auditLog, models.AuditEventMFADisabled, userID, nil)
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: tr(c, "mfa.disabled"),
+	})
+}
+
+// currentUserID 取得 AuthMiddleware 設定的用戶 ID，不存在時回應 401
+func currentUserID(c *gin.Context) (int, bool) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		abortWithError(c, apperrors.ErrUnauthorized, tr(c, "common.unauthorized"))
+		return 0, false
+	}
+	return userID.(int), true
+}
@@ -0,0 +1,200 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"smart-learning-backend/pkg/apperrors"
+	"smart-learning-backend/pkg/models"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MockMFAService 實現了 MFAServiceInterface 介面用於測試
+type MockMFAService struct {
+	pending map[int]bool
+	enabled map[int]bool
+}
+
+func NewMockMFAService() *MockMFAService {
+	return &MockMFAService{
+		pending: make(map[int]bool),
+		enabled: make(map[int]bool),
+	}
+}
+
+func (m *MockMFAService) Setup(ctx context.Context, userID int) (*models.MFASetupResponse, error) {
+	if m.enabled[userID] {
+		return nil, apperrors.ErrMFAAlreadyEnabled
+	}
+	m.pending[userID] = true
+	return &models.MFASetupResponse{
+		Secret:          "JBSWY3DPEHPK3PXP",
+		ProvisioningURI: "otpauth://totp/Smart%20Learning:test@example.com?secret=JBSWY3DPEHPK3PXP",
+	}, nil
+}
+
+func (m *MockMFAService) Confirm(ctx context.Context, userID int, code string) ([]string, error) {
+	if !m.pending[userID] {
+		return nil, apperrors.ErrMFASetupRequired
+	}
+	if code != "123456" {
+		return nil, apperrors.ErrInvalidMFACode
+	}
+	m.enabled[userID] = true
+	return []string{"abcde-fghij"}, nil
+}
+
+func (m *MockMFAService) Disable(ctx context.Context, userID int, code string) error {
+	if err := m.VerifyCode(context.Background(), userID, code); err != nil {
+		return err
+	}
+	m.enabled[userID] = false
+	return nil
+}
+
+func (m *MockMFAService) VerifyCode(ctx context.Context, userID int, code string) error {
+	if !m.enabled[userID] {
+		return apperrors.ErrMFANotEnabled
+	}
+	if code != "123456" {
+		return apperrors.ErrInvalidMFACode
+	}
+	return nil
+}
+
+func (m *MockMFAService) IsEnabled(ctx context.Context, userID int) (bool, error) {
+	return m.enabled[userID], nil
+}
+
+func TestMFAHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		path           string
+		requestBody    interface{}
+		setupService   func(*MockMFAService)
+		expectedStatus int
+		expectedCode   string
+		expectedEvent  string
+	}{
+		{
+			name:           "開始設定",
+			path:           "/mfa/setup",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "已啟用時重新設定",
+			path: "/mfa/setup",
+			setupService: func(m *MockMFAService) {
+				m.enabled[1] = true
+			},
+			expectedStatus: http.StatusConflict,
+			expectedCode:   "MFA_ALREADY_ENABLED",
+		},
+		{
+			name:        "確認設定成功",
+			path:        "/mfa/confirm",
+			requestBody: models.MFACodeRequest{Code: "123456"},
+			setupService: func(m *MockMFAService) {
+				m.pending[1] = true
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:        "確認時驗證碼錯誤",
+			path:        "/mfa/confirm",
+			requestBody: models.MFACodeRequest{Code: "000000"},
+			setupService: func(m *MockMFAService) {
+				m.pending[1] = true
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   "INVALID_MFA_CODE",
+		},
+		{
+			name:           "尚未開始設定",
+			path:           "/mfa/confirm",
+			requestBody:    models.MFACodeRequest{Code: "123456"},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   "MFA_SETUP_REQUIRED",
+		},
+		{
+			name:        "停用成功",
+			path:        "/mfa/disable",
+			requestBody: models.MFACodeRequest{Code: "123456"},
+			setupService: func(m *MockMFAService) {
+				m.enabled[1] = true
+			},
+			expectedStatus: http.StatusOK,
+			expectedEvent:  models.AuditEventMFADisabled,
+		},
+		{
+			name:           "未啟用時停用",
+			path:           "/mfa/disable",
+			requestBody:    models.MFACodeRequest{Code: "123456"},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   "MFA_NOT_ENABLED",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 設置
+			r := setupGin()
+			mockService := NewMockMFAService()
+			if tt.setupService != nil {
+				tt.setupService(mockService)
+			}
+			auditLog := NewMockAuditLogService()
+			handler := NewMFAHandler(mockService, auditLog)
+
+			withUser := func(c *gin.Context) { c.Set("user_id", 1) }
+			r.POST("/mfa/setup", withUser, handler.Setup)
+			r.POST("/mfa/confirm", withUser, handler.Confirm)
+			r.POST("/mfa/disable", withUser, handler.Disable)
+
+			// 準備請求
+			reqBody, err := json.Marshal(tt.requestBody)
+			if err != nil {
+				t.Fatalf("Failed to marshal request body: %v", err)
+			}
+
+			req, err := http.NewRequest("POST", tt.path, bytes.NewBuffer(reqBody))
+			if err != nil {
+				t.Fatalf("Failed to create request: %v", err)
+			}
+			req.Header.Set("Content-Type", "application/json")
+
+			// 執行
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			// 驗證
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+
+			var response models.APIResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatalf("Failed to unmarshal response: %v", err)
+			}
+
+			if tt.expectedCode != "" {
+				if response.Error == nil || response.Error.Code != tt.expectedCode {
+					t.Errorf("Expected error code %s, got %+v", tt.expectedCode, response.Error)
+				}
+			}
+
+			entry := auditLog.lastEntry()
+			if tt.expectedEvent == "" {
+				if entry != nil {
+					t.Errorf("Unexpected audit entry %+v", entry)
+				}
+			} else if entry == nil || entry.EventType != tt.expectedEvent || entry.TargetUserID == nil || *entry.TargetUserID != 1 {
+				t.Errorf("Expected %s audit entry for user 1, got %+v", tt.expectedEvent, entry)
+			}
+		})
+	}
+}
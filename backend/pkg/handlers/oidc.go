@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"net/http"
+	"smart-learning-backend/pkg/interfaces"
+	"smart-learning-backend/pkg/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+type OIDCHandler struct {
+	oidcService interfaces.OIDCServiceInterface
+}
+
+func NewOIDCHandler(oidcService interfaces.OIDCServiceInterface) *OIDCHandler {
+	return &OIDCHandler{
+		oidcService: oidcService,
+	}
+}
+
+// ListProviders 列出可用的外部登入身分提供者
+func (h *OIDCHandler) ListProviders(c *gin.Context) {
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: tr(c, "oidc.list_providers_success"),
+		Data: gin.H{
+			"providers": h.oidcService.Providers(),
+		},
+	})
+}
+
+// Authorize 回傳身分提供者的授權網址，前端需將瀏覽器導向此網址
+func (h *OIDCHandler) Authorize(c *gin.Context) {
+	authURL, err := h.oidcService.AuthorizationURL(c.Request.Context(), c.Param("provider"))
+	if err != nil {
+		abortWithError(c, err, tr(c, "oidc.login_failed"))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: tr(c, "oidc.redirect_to_provider"),
+		Data:    models.OIDCAuthorizeResponse{AuthorizationURL: authURL},
+	})
+}
+
+// Callback 以身分提供者導回的 code 與 state 完成登入
+func (h *OIDCHandler) Callback(c *gin.Context) {
+	var req models.OIDCCallbackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationFailed(c, bindingErrors(c, err))
+		return
+	}
+
+	authResponse, err := h.oidcService.Callback(c.Request.Context(), c.Param("provider"), &req, clientInfo(c))
+	if err != nil {
+		abortWithError(c, err, tr(c, "oidc.login_failed"))
+		return
+	}
+
+	message := tr(c, "auth.login_success")
+	if authResponse.MFARequired {
+		message = tr(c, "auth.mfa_required")
+	}
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: message,
+		Data:    authResponse,
+	})
+}
@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"smart-learning-backend/pkg/apperrors"
+	"smart-learning-backend/pkg/models"
+	"testing"
+)
+
+// MockOIDCService 實現了 OIDCServiceInterface 介面用於測試
+type MockOIDCService struct {
+	err          error
+	authResponse *models.AuthResponse
+}
+
+func (m *MockOIDCService) Providers() []string {
+	return []string{"google", "school"}
+}
+
+func (m *MockOIDCService) AuthorizationURL(ctx context.Context, provider string) (string, error) {
+	if m.err != nil {
+		return "", m.err
+	}
+	return "https://idp.example/authorize?state=abc", nil
+}
+
+func (m *MockOIDCService) Callback(ctx context.Context, provider string, req *models.OIDCCallbackRequest, client models.ClientInfo) (*models.AuthResponse, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return m.authResponse, nil
+}
+
+func TestOIDCHandler_Authorize(t *testing.T) {
+	tests := []struct {
+		name           string
+		err            error
+		expectedStatus int
+		expectedCode   string
+	}{
+		{name: "成功取得授權網址", expectedStatus: http.StatusOK},
+		{name: "不支援的身分提供者", err: apperrors.ErrUnknownProvider, expectedStatus: http.StatusNotFound, expectedCode: "UNKNOWN_OIDC_PROVIDER"},
+		{name: "身分提供者無法連線", err: apperrors.ErrOIDCProviderUnavailable.Wrap(errors.New("timeout")), expectedStatus: http.StatusBadGateway, expectedCode: "OIDC_PROVIDER_ERROR"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := setupGin()
+			handler := NewOIDCHandler(&MockOIDCService{err: tt.err})
+			r.GET("/auth/oidc/:provider/authorize", handler.Authorize)
+
+			req, _ := http.NewRequest("GET", "/auth/oidc/school/authorize", nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+
+			var response models.APIResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatalf("Failed to unmarshal response: %v", err)
+			}
+			if tt.expectedCode != "" && (response.Error == nil || response.Error.Code != tt.expectedCode) {
+				t.Errorf("Expected error code %s, got %+v", tt.expectedCode, response.Error)
+			}
+		})
+	}
+}
+
+func TestOIDCHandler_Callback(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           interface{}
+		err            error
+		authResponse   *models.AuthResponse
+		expectedStatus int
+		expectedCode   string
+	}{
+		{
+			name:           "成功登入",
+			body:           models.OIDCCallbackRequest{Code: "code", State: "state"},
+			authResponse:   &models.AuthResponse{Token: "token"},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "需要兩步驟驗證",
+			body:           models.OIDCCallbackRequest{Code: "code", State: "state"},
+			authResponse:   &models.AuthResponse{MFARequired: true, MFAToken: "mfa"},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "缺少 code",
+			body:           map[string]string{"state": "state"},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "無效的 state",
+			body:           models.OIDCCallbackRequest{Code: "code", State: "state"},
+			err:            apperrors.ErrInvalidOIDCState,
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   "INVALID_OIDC_STATE",
+		},
+		{
+			name:           "授權碼交換失敗",
+			body:           models.OIDCCallbackRequest{Code: "code", State: "state"},
+			err:            apperrors.ErrOIDCExchangeFailed.Wrap(errors.New("invalid id token")),
+			expectedStatus: http.StatusUnauthorized,
+			expectedCode:   "OIDC_EXCHANGE_FAILED",
+		},
+		{
+			name:           "身分提供者未驗證信箱",
+			body:           models.OIDCCallbackRequest{Code: "code", State: "state"},
+			err:            apperrors.ErrOIDCEmailNotVerified,
+			expectedStatus: http.StatusForbidden,
+			expectedCode:   "OIDC_EMAIL_NOT_VERIFIED",
+		},
+		{
+			name:           "信箱已被未驗證帳號註冊",
+			body:           models.OIDCCallbackRequest{Code: "code", State: "state"},
+			err:            apperrors.ErrEmailAlreadyRegistered,
+			expectedStatus: http.StatusConflict,
+			expectedCode:   "EMAIL_ALREADY_REGISTERED",
+		},
+		{
+			name:           "服務錯誤",
+			body:           models.OIDCCallbackRequest{Code: "code", State: "state"},
+			err:            errors.New("failed to create user"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedCode:   "INTERNAL_SERVER_ERROR",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := setupGin()
+			handler := NewOIDCHandler(&MockOIDCService{err: tt.err, authResponse: tt.authResponse})
+			r.POST("/auth/oidc/:provider/callback", handler.Callback)
+
+			body, _ := json.Marshal(tt.body)
+			req, _ := http.NewRequest("POST", "/auth/oidc/school/callback", bytes.NewBuffer(body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+
+			var response models.APIResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatalf("Failed to unmarshal response: %v", err)
+			}
+			if tt.expectedCode != "" && (response.Error == nil || response.Error.Code != tt.expectedCode) {
+				t.Errorf("Expected error code %s, got %+v", tt.expectedCode, response.Error)
+			}
+		})
+	}
+}
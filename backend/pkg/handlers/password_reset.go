@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"net/http"
+	"smart-learning-backend/pkg/interfaces"
+	"smart-learning-backend/pkg/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PasswordResetHandler struct {
+	passwordResetService interfaces.PasswordResetServiceInterface
+}
+
+func NewPasswordResetHandler(passwordResetService interfaces.PasswordResetServiceInterface) *PasswordResetHandler {
+	return &PasswordResetHandler{
+		passwordResetService: passwordResetService,
+	}
+}
+
+// ForgotPassword 寄送密碼重設連結；不論 email 是否已註冊皆回傳相同訊息
+func (h *PasswordResetHandler) ForgotPassword(c *gin.Context) {
+	var req models.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationFailed(c, bindingErrors(c, err))
+		return
+	}
+
+	if err := h.passwordResetService.ForgotPassword(c.Request.Context(), &req); err != nil {
+		abortWithError(c, err, tr(c, "password_reset.send_failed"))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: tr(c, "password_reset.sent"),
+	})
+}
+
+// ResetPassword 以重設 token 設定新密碼，成功後所有裝置需重新登入
+func (h *PasswordResetHandler) ResetPassword(c *gin.Context) {
+	var req models.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationFailed(c, bindingErrors(c, err))
+		return
+	}
+
+	if err := h.passwordResetService.ResetPassword(c.Request.Context(), &req, clientInfo(c)); err != nil {
+		if errs, ok := passwordPolicyErrors(c, err, "password"); ok {
+			validationFailed(c, errs)
+			return
+		}
+
+		abortWithError(c, err, tr(c, "password_reset.reset_failed"))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: tr(c, "password_reset.completed"),
+	})
+}
@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"smart-learning-backend/pkg/apperrors"
+	"smart-learning-backend/pkg/models"
+	"testing"
+)
+
+// MockPasswordResetService 實現了 PasswordResetServiceInterface 介面用於測試
+type MockPasswordResetService struct {
+	shouldFailNext string
+}
+
+func (m *MockPasswordResetService) ForgotPassword(ctx context.Context, req *models.ForgotPasswordRequest) error {
+	if m.shouldFailNext == "ForgotPassword" {
+		m.shouldFailNext = ""
+		return errors.New("database error")
+	}
+	return nil
+}
+
+func (m *MockPasswordResetService) ResetPassword(ctx context.Context, req *models.ResetPasswordRequest, client models.ClientInfo) error {
+	if m.shouldFailNext == "ResetPassword" {
+		m.shouldFailNext = ""
+		return errors.New("database error")
+	}
+	if req.Password != req.ConfirmPassword {
+		return apperrors.ErrPasswordsDoNotMatch
+	}
+	if req.Token != "valid-reset-token" {
+		return apperrors.ErrInvalidResetToken
+	}
+	if len(req.Password) < 8 {
+		return &models.PasswordPolicyError{Violations: []models.PasswordPolicyViolation{
+			{Rule: models.PasswordRuleMinLength, Limit: 8},
+		}}
+	}
+	return nil
+}
+
+func (m *MockPasswordResetService) SetShouldFailNext(method string) {
+	m.shouldFailNext = method
+}
+
+func TestPasswordResetHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		path           string
+		requestBody    interface{}
+		setupService   func(*MockPasswordResetService)
+		expectedStatus int
+		expectedCode   string
+		expectedField  string
+	}{
+		{
+			name:           "忘記密碼成功",
+			path:           "/password/forgot",
+			requestBody:    models.ForgotPasswordRequest{Email: "test@example.com"},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "忘記密碼 email 格式錯誤",
+			path:           "/password/forgot",
+			requestBody:    models.ForgotPasswordRequest{Email: "invalid-email"},
+			expectedStatus: http.StatusBadRequest,
+			expectedField:  "email",
+		},
+		{
+			name:        "忘記密碼服務錯誤",
+			path:        "/password/forgot",
+			requestBody: models.ForgotPasswordRequest{Email: "test@example.com"},
+			setupService: func(m *MockPasswordResetService) {
+				m.SetShouldFailNext("ForgotPassword")
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedCode:   "INTERNAL_SERVER_ERROR",
+		},
+		{
+			name: "重設密碼成功",
+			path: "/password/reset",
+			requestBody: models.ResetPasswordRequest{
+				Token: "valid-reset-token", Password: "newpassword123", ConfirmPassword: "newpassword123",
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "重設密碼太短",
+			path: "/password/reset",
+			requestBody: models.ResetPasswordRequest{
+				Token: "valid-reset-token", Password: "short", ConfirmPassword: "short",
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedField:  "password",
+		},
+		{
+			name: "重設密碼確認不一致",
+			path: "/password/reset",
+			requestBody: models.ResetPasswordRequest{
+				Token: "valid-reset-token", Password: "newpassword123", ConfirmPassword: "different123",
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedField:  "confirm_password",
+		},
+		{
+			name: "重設 token 無效",
+			path: "/password/reset",
+			requestBody: models.ResetPasswordRequest{
+				Token: "expired-reset-token", Password: "newpassword123", ConfirmPassword: "newpassword123",
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   "INVALID_RESET_TOKEN",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 設置
+			r := setupGin()
+			mockService := &MockPasswordResetService{}
+			if tt.setupService != nil {
+				tt.setupService(mockService)
+			}
+			handler := NewPasswordResetHandler(mockService)
+
+			r.POST("/password/forgot", handler.ForgotPassword)
+			r.POST("/password/reset", handler.ResetPassword)
+
+			// 準備請求
+			reqBody, err := json.Marshal(tt.requestBody)
+			if err != nil {
+				t.Fatalf("Failed to marshal request body: %v", err)
+			}
+
+			req, err := http.NewRequest("POST", tt.path, bytes.NewBuffer(reqBody))
+			if err != nil {
+				t.Fatalf("Failed to create request: %v", err)
+			}
+			req.Header.Set("Content-Type", "application/json")
+
+			// 執行
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			// 驗證
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+
+			var response models.APIResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatalf("Failed to unmarshal response: %v", err)
+			}
+
+			if tt.expectedCode != "" {
+				if response.Error == nil || response.Error.Code != tt.expectedCode {
+					t.Errorf("Expected error code %s, got %+v", tt.expectedCode, response.Error)
+				}
+			}
+
+			if tt.expectedField != "" {
+				errs, _ := response.Errors.(map[string]interface{})
+				if _, ok := errs[tt.expectedField]; !ok {
+					t.Errorf("Expected validation error for %s, got %v", tt.expectedField, response.Errors)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"net/http"
+	"smart-learning-backend/pkg/interfaces"
+	"smart-learning-backend/pkg/models"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ProfileHandler struct {
+	profileService interfaces.ProfileServiceInterface
+	auditLog       interfaces.AuditRecorderInterface
+}
+
+func NewProfileHandler(profileService interfaces.ProfileServiceInterface, auditLog interfaces.AuditRecorderInterface) *ProfileHandler {
+	return &ProfileHandler{
+		profileService: profileService,
+		auditLog:       auditLog,
+	}
+}
+
+// UpdateMe 部分更新目前登入用戶的個人資料；updated_at 與目前資料不一致時回傳 409
+func (h *ProfileHandler) UpdateMe(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationFailed(c, bindingErrors(c, err))
+		return
+	}
+
+	user, err := h.profileService.UpdateProfile(c.Request.Context(), userID, &req)
+	if err != nil {
+		abortWithError(c, err, tr(c, "profile.update_failed"))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: tr(c, "profile.updated"),
+		Data:    user,
+	})
+}
+
+// ChangePassword 驗證目前密碼後變更密碼，目前裝置以外的會話都會被登出
+func (h *ProfileHandler) ChangePassword(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationFailed(c, bindingErrors(c, err))
+		return
+	}
+
+	if err := h.profileService.ChangePassword(c.Request.Context(), userID, c.GetString("session_id"), &req); err != nil {
+		if errs, ok := passwordPolicyErrors(c, err, "new_password"); ok {
+			validationFailed(c, errs)
+			return
+		}
+
+		abortWithError(c, err, tr(c, "profile.change_password_failed"))
+		return
+	}
+	recordAudit(c, h.auditLog, models.AuditEventPasswordChange, userID, map[string]string{"method": "current_password"})
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: tr(c, "profile.password_changed"),
+	})
+}
+
+// ChangeEmail 申請變更 email：寄送驗證連結到新地址，完成驗證後才會生效
+func (h *ProfileHandler) ChangeEmail(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.ChangeEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		validationFailed(c, bindingErrors(c, err))
+		return
+	}
+
+	if err := h.profileService.RequestEmailChange(c.Request.Context(), userID, &req); err != nil {
+		abortWithError(c, err, tr(c, "profile.change_email_failed"))
+		return
+	}
+	recordAudit(c, h.auditLog, models.AuditEventEmailChangeRequest, userID, map[string]string{"new_email": strings.TrimSpace(req.NewEmail)})
+
+	c.JSON(http.StatusAccepted, models.APIResponse{
+		Success: true,
+		Message: tr(c, "profile.email_change_requested"),
+	})
+}
@@ -0,0 +1,351 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"smart-learning-backend/pkg/apperrors"
+	"smart-learning-backend/pkg/i18n"
+	"smart-learning-backend/pkg/models"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MockProfileService 實現了 ProfileServiceInterface 介面用於測試
+type MockProfileService struct {
+	updatedAt      time.Time
+	shouldFailNext string
+}
+
+func NewMockProfileService() *MockProfileService {
+	return &MockProfileService{
+		updatedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+func (m *MockProfileService) UpdateProfile(ctx context.Context, userID int, req *models.UpdateProfileRequest) (*models.User, error) {
+	if m.shouldFailNext == "UpdateProfile" {
+		m.shouldFailNext = ""
+		return nil, errors.New("database error")
+	}
+	if req.Username == nil && req.LearningLevel == nil && req.AvatarURL == nil && req.Locale == nil {
+		return nil, apperrors.ErrNoProfileChanges
+	}
+	if !req.UpdatedAt.Equal(m.updatedAt) {
+		return nil, apperrors.ErrUserUpdateConflict
+	}
+	if req.Locale != nil && *req.Locale != "" && !i18n.IsSupported(*req.Locale) {
+		return nil, apperrors.ErrUnsupportedLocale.WithArgs("zh-TW/en/ja")
+	}
+	if req.Username != nil && *req.Username == "taken" {
+		return nil, apperrors.ErrUsernameTaken
+	}
+
+	user := &models.User{ID: userID, Username: "testuser", UpdatedAt: m.updatedAt.Add(time.Second)}
+	if req.Username != nil {
+		user.Username = *req.Username
+	}
+	return user, nil
+}
+
+func (m *MockProfileService) ChangePassword(ctx context.Context, userID int, currentSessionID string, req *models.ChangePasswordRequest) error {
+	if req.NewPassword != req.ConfirmPassword {
+		return apperrors.ErrPasswordsDoNotMatch
+	}
+	if req.CurrentPassword != "password123" {
+		return apperrors.ErrInvalidCurrentPassword
+	}
+	if len(req.NewPassword) < 8 {
+		return &models.PasswordPolicyError{Violations: []models.PasswordPolicyViolation{{Rule: models.PasswordRuleMinLength, Limit: 8}}}
+	}
+	return nil
+}
+
+func (m *MockProfileService) RequestEmailChange(ctx context.Context, userID int, req *models.ChangeEmailRequest) error {
+	if req.CurrentPassword != "password123" {
+		return apperrors.ErrInvalidCurrentPassword
+	}
+	switch req.NewEmail {
+	case "test@example.com":
+		return apperrors.ErrEmailUnchanged
+	case "taken@example.com":
+		return apperrors.ErrEmailAlreadyInUse
+	}
+	return nil
+}
+
+func (m *MockProfileService) SetShouldFailNext(method string) {
+	m.shouldFailNext = method
+}
+
+// serveProfileRequest 以已登入的用戶 1 呼叫 handler 並解析回應
+func serveProfileRequest(t *testing.T, mockService *MockProfileService, handle func(*ProfileHandler, *gin.Context), body interface{}) (*httptest.ResponseRecorder, models.APIResponse) {
+	t.Helper()
+
+	r := setupGin()
+	handler := NewProfileHandler(mockService, NewMockAuditLogService())
+	r.POST("/users/me", func(c *gin.Context) {
+		c.Set("user_id", 1)
+		c.Set("session_id", "current-session")
+		handle(handler, c)
+	})
+
+	jsonBody, _ := json.Marshal(body)
+	req, _ := http.NewRequest("POST", "/users/me", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	var response models.APIResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	return w, response
+}
+
+func TestProfileHandler_UpdateMe(t *testing.T) {
+	updatedAt := NewMockProfileService().updatedAt
+
+	tests := []struct {
+		name           string
+		requestBody    interface{}
+		setupService   func(*MockProfileService)
+		expectedStatus int
+		expectedCode   string
+		expectedField  string
+	}{
+		{
+			name:           "更新成功",
+			requestBody:    map[string]interface{}{"username": "newname", "updated_at": updatedAt},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "缺少 updated_at",
+			requestBody:    map[string]interface{}{"username": "newname"},
+			expectedStatus: http.StatusBadRequest,
+			expectedField:  "updatedat",
+		},
+		{
+			name:           "學習等級超出範圍",
+			requestBody:    map[string]interface{}{"learning_level": 11, "updated_at": updatedAt},
+			expectedStatus: http.StatusBadRequest,
+			expectedField:  "learninglevel",
+		},
+		{
+			name:           "頭像網址格式錯誤",
+			requestBody:    map[string]interface{}{"avatar_url": "not a url", "updated_at": updatedAt},
+			expectedStatus: http.StatusBadRequest,
+			expectedField:  "avatarurl",
+		},
+		{
+			name:           "設定偏好語系",
+			requestBody:    map[string]interface{}{"locale": "en", "updated_at": updatedAt},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "空字串清除偏好語系",
+			requestBody:    map[string]interface{}{"locale": "", "updated_at": updatedAt},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "不支援的語系",
+			requestBody:    map[string]interface{}{"locale": "fr", "updated_at": updatedAt},
+			expectedStatus: http.StatusBadRequest,
+			expectedField:  "locale",
+		},
+		{
+			name:           "沒有要更新的欄位",
+			requestBody:    map[string]interface{}{"updated_at": updatedAt},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   "NO_PROFILE_CHANGES",
+		},
+		{
+			name:           "資料已被修改",
+			requestBody:    map[string]interface{}{"username": "newname", "updated_at": updatedAt.Add(-time.Minute)},
+			expectedStatus: http.StatusConflict,
+			expectedCode:   "USER_UPDATE_CONFLICT",
+		},
+		{
+			name:           "用戶名已被使用",
+			requestBody:    map[string]interface{}{"username": "taken", "updated_at": updatedAt},
+			expectedStatus: http.StatusConflict,
+			expectedCode:   "USERNAME_TAKEN",
+		},
+		{
+			name:        "服務錯誤",
+			requestBody: map[string]interface{}{"username": "newname", "updated_at": updatedAt},
+			setupService: func(m *MockProfileService) {
+				m.SetShouldFailNext("UpdateProfile")
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedCode:   "INTERNAL_SERVER_ERROR",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := NewMockProfileService()
+			if tt.setupService != nil {
+				tt.setupService(mockService)
+			}
+
+			w, response := serveProfileRequest(t, mockService, (*ProfileHandler).UpdateMe, tt.requestBody)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+			if tt.expectedCode != "" && (response.Error == nil || response.Error.Code != tt.expectedCode) {
+				t.Errorf("Expected error code %s, got %+v", tt.expectedCode, response.Error)
+			}
+			if tt.expectedField != "" {
+				errs, _ := response.Errors.(map[string]interface{})
+				if _, ok := errs[tt.expectedField]; !ok {
+					t.Errorf("Expected validation error for %s, got %v", tt.expectedField, response.Errors)
+				}
+			}
+		})
+	}
+}
+
+func TestProfileHandler_ChangePassword(t *testing.T) {
+	tests := []struct {
+		name           string
+		requestBody    models.ChangePasswordRequest
+		expectedStatus int
+		expectedField  string
+	}{
+		{
+			name:           "變更成功",
+			requestBody:    models.ChangePasswordRequest{CurrentPassword: "password123", NewPassword: "newpassword456", ConfirmPassword: "newpassword456"},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "目前密碼錯誤",
+			requestBody:    models.ChangePasswordRequest{CurrentPassword: "wrong", NewPassword: "newpassword456", ConfirmPassword: "newpassword456"},
+			expectedStatus: http.StatusBadRequest,
+			expectedField:  "current_password",
+		},
+		{
+			name:           "密碼確認不一致",
+			requestBody:    models.ChangePasswordRequest{CurrentPassword: "password123", NewPassword: "newpassword456", ConfirmPassword: "other"},
+			expectedStatus: http.StatusBadRequest,
+			expectedField:  "confirm_password",
+		},
+		{
+			name:           "新密碼不符合政策",
+			requestBody:    models.ChangePasswordRequest{CurrentPassword: "password123", NewPassword: "short", ConfirmPassword: "short"},
+			expectedStatus: http.StatusBadRequest,
+			expectedField:  "new_password",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, response := serveProfileRequest(t, NewMockProfileService(), (*ProfileHandler).ChangePassword, tt.requestBody)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+			if tt.expectedField != "" {
+				errs, _ := response.Errors.(map[string]interface{})
+				if _, ok := errs[tt.expectedField]; !ok {
+					t.Errorf("Expected validation error for %s, got %v", tt.expectedField, response.Errors)
+				}
+			}
+		})
+	}
+}
+
+func TestProfileHandler_ChangeEmail(t *testing.T) {
+	tests := []struct {
+		name           string
+		requestBody    models.ChangeEmailRequest
+		expectedStatus int
+		expectedCode   string
+		expectedField  string
+	}{
+		{
+			name:           "已寄出驗證信",
+			requestBody:    models.ChangeEmailRequest{NewEmail: "new@example.com", CurrentPassword: "password123"},
+			expectedStatus: http.StatusAccepted,
+		},
+		{
+			name:           "email 格式錯誤",
+			requestBody:    models.ChangeEmailRequest{NewEmail: "invalid-email", CurrentPassword: "password123"},
+			expectedStatus: http.StatusBadRequest,
+			expectedField:  "newemail",
+		},
+		{
+			name:           "與目前 email 相同",
+			requestBody:    models.ChangeEmailRequest{NewEmail: "test@example.com", CurrentPassword: "password123"},
+			expectedStatus: http.StatusBadRequest,
+			expectedField:  "new_email",
+		},
+		{
+			name:           "email 已被使用",
+			requestBody:    models.ChangeEmailRequest{NewEmail: "taken@example.com", CurrentPassword: "password123"},
+			expectedStatus: http.StatusConflict,
+			expectedCode:   "EMAIL_ALREADY_IN_USE",
+		},
+		{
+			name:           "目前密碼錯誤",
+			requestBody:    models.ChangeEmailRequest{NewEmail: "new@example.com", CurrentPassword: "wrong"},
+			expectedStatus: http.StatusBadRequest,
+			expectedField:  "current_password",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, response := serveProfileRequest(t, NewMockProfileService(), (*ProfileHandler).ChangeEmail, tt.requestBody)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+			if tt.expectedCode != "" && (response.Error == nil || response.Error.Code != tt.expectedCode) {
+				t.Errorf("Expected error code %s, got %+v", tt.expectedCode, response.Error)
+			}
+			if tt.expectedField != "" {
+				errs, _ := response.Errors.(map[string]interface{})
+				if _, ok := errs[tt.expectedField]; !ok {
+					t.Errorf("Expected validation error for %s, got %v", tt.expectedField, response.Errors)
+				}
+			}
+		})
+	}
+}
+
+func TestProfileHandler_ChangeEmail_AuditLog(t *testing.T) {
+	r := setupGin()
+	auditLog := NewMockAuditLogService()
+	handler := NewProfileHandler(NewMockProfileService(), auditLog)
+	r.POST("/users/me/email", func(c *gin.Context) {
+		c.Set("user_id", 1)
+		handler.ChangeEmail(c)
+	})
+
+	for _, body := range []models.ChangeEmailRequest{
+		{NewEmail: "new@example.com", CurrentPassword: "wrong"},
+		{NewEmail: "new@example.com", CurrentPassword: "password123"},
+	} {
+		jsonBody, _ := json.Marshal(body)
+		req, _ := http.NewRequest("POST", "/users/me/email", bytes.NewBuffer(jsonBody))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	// 密碼錯誤的申請不寫入稽核紀錄
+	if len(auditLog.entries) != 1 {
+		t.Fatalf("Expected 1 audit entry, got %+v", auditLog.entries)
+	}
+	entry := auditLog.lastEntry()
+	if entry.EventType != models.AuditEventEmailChangeRequest || entry.ActorID == nil || *entry.ActorID != 1 ||
+		entry.Metadata["new_email"] != "new@example.com" {
+		t.Errorf("Email change audit entry = %+v", entry)
+	}
+}
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"smart-learning-backend/pkg/i18n"
+	"smart-learning-backend/pkg/models"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// tr 取得請求語系（見 middleware.Locale）的訊息
+func tr(c *gin.Context, key string, args ...interface{}) string {
+	return i18n.T(c.GetString("locale"), key, args...)
+}
+
+// validationFailed 回應 400 與各欄位的驗證錯誤訊息
+func validationFailed(c *gin.Context, errs map[string][]string) {
+	c.JSON(http.StatusBadRequest, models.APIResponse{
+		Success: false,
+		Message: tr(c, "errors.validation_failed"),
+		Errors:  errs,
+	})
+}
+
+// bindingErrors 將請求綁定的驗證錯誤轉換為以欄位為鍵的錯誤訊息，訊息依請求語系由驗證器的翻譯產生
+func bindingErrors(c *gin.Context, err error) map[string][]string {
+	validationErrors := make(map[string][]string)
+
+	if validatorErrors, ok := err.(validator.ValidationErrors); ok {
+		locale := c.GetString("locale")
+		for _, fieldError := range validatorErrors {
+			field := strings.ToLower(fieldError.StructField())
+			validationErrors[field] = append(validationErrors[field], i18n.ValidationMessage(locale, fieldError))
+		}
+	}
+
+	return validationErrors
+}
+
+// passwordPolicyErrors 將未通過的密碼政策規則轉換為指定欄位的錯誤訊息，每條規則一則
+func passwordPolicyErrors(c *gin.Context, err error, field string) (map[string][]string, bool) {
+	var policyErr *models.PasswordPolicyError
+	if !errors.As(err, &policyErr) {
+		return nil, false
+	}
+
+	messages := make([]string, 0, len(policyErr.Violations))
+	for _, violation := range policyErr.Violations {
+		var message string
+
+		switch violation.Rule {
+		case models.PasswordRuleMinLength:
+			message = tr(c, "validation.password_min_length", violation.Limit)
+		case models.PasswordRuleMaxLength:
+			message = tr(c, "validation.password_max_length", violation.Limit)
+		case models.PasswordRuleCharacterClasses:
+			message = tr(c, "validation.password_character_classes", violation.Limit)
+		case models.PasswordRuleStrength:
+			message = tr(c, "validation.password_strength")
+		case models.PasswordRuleUserInfo:
+			message = tr(c, "validation.password_user_info")
+		case models.PasswordRuleBreached:
+			message = tr(c, "validation.password_breached")
+		default:
+			message = tr(c, "validation.password_policy")
+		}
+
+		messages = append(messages, message)
+	}
+
+	return map[string][]string{field: messages}, true
+}
@@ -0,0 +1,82 @@
+// Package i18n 提供 API 回應訊息的多語系目錄與 Accept-Language 協商。
+//
+// 訊息以訊息鍵查詢，帶參數的訊息以 fmt 格式化；請求語系缺少的訊息改用預設語系（正體中文）。
+package i18n
+
+import (
+	"fmt"
+
+	"golang.org/x/text/language"
+)
+
+// 支援的語系
+const (
+	LocaleZhTW = "zh-TW"
+	LocaleEn   = "en"
+	LocaleJa   = "ja"
+
+	// DefaultLocale 為未指定語系或無法協商時使用的語系
+	DefaultLocale = LocaleZhTW
+)
+
+// SupportedLocales 依協商優先順序排列，第一個為預設語系
+var SupportedLocales = []string{LocaleZhTW, LocaleEn, LocaleJa}
+
+// catalogs 為各語系的訊息目錄
+var catalogs = map[string]map[string]string{
+	LocaleZhTW: zhTWMessages,
+	LocaleEn:   enMessages,
+	LocaleJa:   jaMessages,
+}
+
+var matcher = language.NewMatcher([]language.Tag{
+	language.MustParse(LocaleZhTW),
+	language.English,
+	language.Japanese,
+})
+
+// IsSupported 檢查語系是否在支援清單中
+func IsSupported(locale string) bool {
+	_, ok := catalogs[locale]
+	return ok
+}
+
+// Negotiate 依 Accept-Language 標頭選出最適合的支援語系，例如 zh-Hant、zh-HK 對應 zh-TW，en-US 對應 en；
+// 標頭為空、格式錯誤或沒有相近的語系時回傳預設語系
+func Negotiate(acceptLanguage string) string {
+	if acceptLanguage == "" {
+		return DefaultLocale
+	}
+
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(tags) == 0 {
+		return DefaultLocale
+	}
+
+	_, index, confidence := matcher.Match(tags...)
+	if confidence == language.No {
+		return DefaultLocale
+	}
+	return SupportedLocales[index]
+}
+
+// T 取得訊息鍵在指定語系的訊息；語系不支援或缺少該訊息時改用預設語系，未定義的鍵視為伺服器錯誤
+func T(locale, key string, args ...interface{}) string {
+	message, ok := catalogs[locale][key]
+	if !ok {
+		message, ok = catalogs[DefaultLocale][key]
+	}
+	if !ok {
+		return catalogs[DefaultLocale]["errors.internal_server_error"]
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(message, args...)
+	}
+	return message
+}
+
+// Has 檢查語系的目錄是否定義了訊息鍵
+func Has(locale, key string) bool {
+	_, ok := catalogs[locale][key]
+	return ok
+}
@@ -0,0 +1,145 @@
+package i18n
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+)
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		name           string
+		acceptLanguage string
+		want           string
+	}{
+		{name: "未提供標頭", acceptLanguage: "", want: LocaleZhTW},
+		{name: "完全相符", acceptLanguage: "ja", want: LocaleJa},
+		{name: "地區變體", acceptLanguage: "en-US,en;q=0.9", want: LocaleEn},
+		{name: "正體中文的其他寫法", acceptLanguage: "zh-Hant", want: LocaleZhTW},
+		{name: "依權重排序", acceptLanguage: "fr;q=0.9,ja;q=0.5,en;q=0.8", want: LocaleEn},
+		{name: "不支援的語系", acceptLanguage: "fr-FR,de", want: LocaleZhTW},
+		{name: "格式錯誤", acceptLanguage: "@@@", want: LocaleZhTW},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Negotiate(tt.acceptLanguage); got != tt.want {
+				t.Errorf("Negotiate(%q) = %q, want %q", tt.acceptLanguage, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestT(t *testing.T) {
+	tests := []struct {
+		name   string
+		locale string
+		key    string
+		args   []interface{}
+		want   string
+	}{
+		{name: "正體中文", locale: LocaleZhTW, key: "common.unauthorized", want: "未授權"},
+		{name: "英文", locale: LocaleEn, key: "common.unauthorized", want: "Unauthorized"},
+		{name: "帶參數", locale: LocaleJa, key: "errors.avatar_too_large", args: []interface{}{5}, want: "画像は 5 MB 以下にしてください"},
+		{name: "不支援的語系", locale: "fr", key: "common.unauthorized", want: "未授權"},
+		{name: "未定義的鍵", locale: LocaleEn, key: "errors.no_such_key", want: "伺服器內部錯誤"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := T(tt.locale, tt.key, tt.args...); got != tt.want {
+				t.Errorf("T(%q, %q) = %q, want %q", tt.locale, tt.key, got, tt.want)
+			}
+		})
+	}
+}
+
+// 各語系的目錄需與預設語系有相同的訊息鍵與格式化參數
+func TestCatalogs_Complete(t *testing.T) {
+	for _, locale := range SupportedLocales {
+		catalog := catalogs[locale]
+		for key, message := range catalogs[DefaultLocale] {
+			translated, ok := catalog[key]
+			if !ok {
+				t.Errorf("%s: missing message for %s", locale, key)
+				continue
+			}
+			if strings.Count(translated, "%") != strings.Count(message, "%") {
+				t.Errorf("%s: %s has different format arguments: %q", locale, key, translated)
+			}
+		}
+		for key := range catalog {
+			if _, ok := catalogs[DefaultLocale][key]; !ok {
+				t.Errorf("%s: %s is not defined in %s", locale, key, DefaultLocale)
+			}
+		}
+	}
+}
+
+// 處理器與中介軟體以 tr(c, key) 取用的訊息鍵都必須存在於各語系的目錄
+func TestCatalogs_CoverSourceKeys(t *testing.T) {
+	trCall := regexp.MustCompile(`\btr\(c, "([^"]+)"`)
+
+	for _, dir := range []string{"../handlers", "../middleware"} {
+		files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+		if err != nil {
+			t.Fatalf("Glob(%s) error = %v", dir, err)
+		}
+		for _, file := range files {
+			if strings.HasSuffix(file, "_test.go") {
+				continue
+			}
+			source, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatalf("ReadFile(%s) error = %v", file, err)
+			}
+			for _, match := range trCall.FindAllStringSubmatch(string(source), -1) {
+				for _, locale := range SupportedLocales {
+					if !Has(locale, match[1]) {
+						t.Errorf("%s: %s uses %s, which is not defined", locale, file, match[1])
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestValidationMessage(t *testing.T) {
+	v := validator.New()
+	if err := RegisterValidator(v); err != nil {
+		t.Fatalf("RegisterValidator() error = %v", err)
+	}
+
+	type request struct {
+		Email    string `json:"email" validate:"required,email"`
+		PageSize int    `form:"page_size" validate:"max=100"`
+	}
+	err := v.Struct(request{Email: "not-an-email", PageSize: 200})
+	fieldErrors, ok := err.(validator.ValidationErrors)
+	if !ok || len(fieldErrors) != 2 {
+		t.Fatalf("Struct() error = %v", err)
+	}
+
+	tests := []struct {
+		locale string
+		want   []string
+	}{
+		{locale: LocaleZhTW, want: []string{"email必須是一個有效的信箱", "page_size必須小於或等於100"}},
+		{locale: LocaleEn, want: []string{"email must be a valid email address", "page_size must be 100 or less"}},
+		{locale: LocaleJa, want: []string{"emailは正しいメールアドレスでなければなりません", "page_sizeは100以下でなければなりません"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.locale, func(t *testing.T) {
+			for i, fieldError := range fieldErrors {
+				if got := ValidationMessage(tt.locale, fieldError); got != tt.want[i] {
+					t.Errorf("ValidationMessage(%q) = %q, want %q", tt.locale, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
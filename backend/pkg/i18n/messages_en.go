@@ -0,0 +1,178 @@
+package i18n
+
+// enMessages 為英文訊息
+var enMessages = map[string]string{
+	"common.unauthorized": "Unauthorized",
+	"common.forbidden":    "Forbidden",
+	"common.server_error": "Server error",
+
+	"validation.required": "This field is required",
+	"validation.invalid":  "Invalid format",
+
+	"validation.password_min_length":        "Password must be at least %d characters",
+	"validation.password_max_length":        "Password must not exceed %d characters",
+	"validation.password_character_classes": "Password must contain at least %d of: uppercase letters, lowercase letters, digits, symbols",
+	"validation.password_strength":          "Password is too weak; avoid common words and sequential or repeated characters",
+	"validation.password_user_info":         "Password must not contain or resemble your username or email",
+	"validation.password_breached":          "This password has appeared in a data breach; please choose another one",
+	"validation.password_policy":            "Password does not meet the security requirements",
+
+	"auth.register_success":             "Registration successful",
+	"auth.register_failed":              "Registration failed",
+	"auth.user_already_exists":          "User already exists",
+	"auth.login_success":                "Login successful",
+	"auth.login_failed":                 "Login failed",
+	"auth.mfa_required":                 "Please enter your two-step verification code",
+	"auth.mfa_verification_failed":      "Verification failed",
+	"auth.refresh_success":              "Token refreshed",
+	"auth.refresh_failed":               "Token refresh failed",
+	"auth.logout_success":               "Logout successful",
+	"auth.logout_failed":                "Logout failed",
+	"auth.logged_out":                   "You have been logged out",
+	"auth.list_sessions_failed":         "Failed to load sessions",
+	"auth.session_revoked":              "Session ended",
+	"auth.revoke_session_failed":        "Failed to end session",
+	"auth.other_sessions_revoked":       "Logged out of other devices",
+	"auth.revoke_other_sessions_failed": "Failed to log out of other devices",
+
+	"account.delete_failed":         "Failed to delete account",
+	"account.deletion_scheduled":    "Account deletion scheduled and all devices logged out; log in again before the deletion date to cancel",
+	"account.export_failed":         "Export failed",
+	"account.invalid_export_format": "Format must be zip or json",
+
+	"admin.list_users_failed":     "Failed to load users",
+	"admin.invalid_user_query":    "Invalid query parameters; dates must use YYYY-MM-DD",
+	"admin.invalid_user_id":       "Invalid user ID",
+	"admin.suspend_failed":        "Failed to suspend account",
+	"admin.suspended":             "Account suspended",
+	"admin.unsuspend_failed":      "Failed to unsuspend account",
+	"admin.unsuspended":           "Account unsuspended",
+	"admin.reset_password_failed": "Failed to reset password",
+	"admin.password_reset":        "Password reset and reset link sent",
+	"admin.change_role_failed":    "Failed to change role",
+	"admin.role_changed":          "Role changed",
+	"admin.impersonate_failed":    "Failed to impersonate user",
+	"admin.impersonation_started": "Impersonation token issued",
+	"admin.unlock_failed":         "Failed to unlock account",
+	"admin.unlocked":              "Account unlocked",
+
+	"audit_log.list_failed":   "Failed to load audit log",
+	"audit_log.export_failed": "Failed to export audit log",
+	"audit_log.invalid_query": "Invalid query parameters; times must use RFC 3339, for example 2025-01-01T00:00:00Z",
+
+	"api_key.create_failed": "Failed to create API key",
+	"api_key.created":       "API key created; save it now, it will not be shown again",
+	"api_key.list_failed":   "Failed to load API keys",
+	"api_key.invalid_id":    "Invalid API key ID",
+	"api_key.revoke_failed": "Failed to revoke API key",
+	"api_key.revoked":       "API key revoked",
+
+	"avatar.upload_failed": "Upload failed",
+	"avatar.file_required": "Please choose an image to upload",
+	"avatar.updated":       "Avatar updated",
+	"avatar.delete_failed": "Failed to remove avatar",
+	"avatar.deleted":       "Avatar removed",
+	"avatar.get_failed":    "Failed to load avatar",
+
+	"email_verification.verified":    "Email verified",
+	"email_verification.send_failed": "Failed to send email",
+	"email_verification.sent":        "Verification email sent",
+
+	"magic_link.send_failed": "Failed to send email",
+	"magic_link.sent":        "If this email is registered, you will receive a login link",
+
+	"mfa.setup_failed":   "Setup failed",
+	"mfa.setup_started":  "Scan the code with your authenticator app and enter the code to finish setup",
+	"mfa.enabled":        "Two-step verification enabled; keep your recovery codes in a safe place",
+	"mfa.disable_failed": "Failed to disable two-step verification",
+	"mfa.disabled":       "Two-step verification disabled",
+
+	"oidc.list_providers_success": "Identity providers loaded",
+	"oidc.login_failed":           "External login failed",
+	"oidc.redirect_to_provider":   "Continue to the identity provider to log in",
+
+	"password_reset.send_failed":  "Failed to send email",
+	"password_reset.sent":         "If this email is registered, you will receive a password reset link",
+	"password_reset.reset_failed": "Password reset failed",
+	"password_reset.completed":    "Password reset; please log in again",
+
+	"profile.update_failed":          "Update failed",
+	"profile.updated":                "Profile updated",
+	"profile.change_password_failed": "Failed to change password",
+	"profile.password_changed":       "Password changed; other devices have been logged out",
+	"profile.change_email_failed":    "Failed to change email",
+	"profile.email_change_requested": "A verification email has been sent to the new address; the change takes effect once verified",
+
+	"errors.validation_failed":     "Validation failed",
+	"errors.internal_server_error": "Internal server error",
+	"errors.account_locked":        "Too many failed login attempts; please try again in %d seconds",
+	"errors.rate_limit_exceeded":   "Too many requests; please try again in %d seconds",
+	"errors.request_timeout":       "The request timed out; please try again later",
+
+	"errors.user_not_found":           "User not found",
+	"errors.user_already_exists":      "Email or username is already in use",
+	"errors.username_taken":           "Username is already in use",
+	"errors.email_already_in_use":     "This email is already in use",
+	"errors.email_already_verified":   "Email has already been verified",
+	"errors.user_update_conflict":     "Your data was updated elsewhere; please reload and try again",
+	"errors.no_profile_changes":       "No fields to update",
+	"errors.account_suspended":        "Your account has been suspended; please contact support",
+	"errors.passwords_do_not_match":   "Passwords do not match",
+	"errors.invalid_username":         "Username may only contain letters, digits and underscores",
+	"errors.invalid_current_password": "Current password is incorrect",
+	"errors.email_unchanged":          "New email is the same as the current one",
+	"errors.invalid_avatar_url":       "Avatar URL must be an http or https URL",
+	"errors.unsupported_locale":       "Locale must be one of %s",
+
+	"errors.invalid_credentials":        "Incorrect email or password",
+	"errors.invalid_refresh_token":      "Refresh token is invalid or expired",
+	"errors.refresh_token_reused":       "Refresh token has already been used; please log in again",
+	"errors.session_not_found":          "Session not found or already ended",
+	"errors.invalid_mfa_token":          "Verification timed out; please log in again",
+	"errors.invalid_reset_token":        "Reset link is invalid or expired",
+	"errors.invalid_verification_token": "Verification link is invalid or expired",
+	"errors.invalid_magic_link":         "Login link is invalid or expired",
+	"errors.invalid_api_key":            "API key is invalid, revoked or expired",
+	"errors.reauthentication_required":  "Provide your current password, or sign in again and retry within %d minutes",
+	"errors.missing_token":              "Authorization header is required",
+	"errors.invalid_token_format":       "Invalid authorization header format",
+	"errors.invalid_token":              "Token is invalid or expired",
+	"errors.token_revoked":              "Token has been revoked",
+	"errors.session_revoked":            "Your session has ended; please log in again",
+
+	"errors.unauthorized":              "Unable to read user information",
+	"errors.permission_denied":         "Missing permissions: %s",
+	"errors.email_not_verified":        "Please verify your email first",
+	"errors.api_key_not_allowed":       "This action requires signing in with your account; API keys are not accepted",
+	"errors.impersonation_not_allowed": "This action is not allowed while impersonating a user",
+
+	"errors.invalid_mfa_code":    "Incorrect verification code",
+	"errors.mfa_already_enabled": "Two-step verification is already enabled",
+	"errors.mfa_not_enabled":     "Two-step verification is not enabled",
+	"errors.mfa_setup_required":  "Please start two-step verification setup first",
+
+	"errors.unknown_oidc_provider":    "Unsupported identity provider",
+	"errors.invalid_oidc_state":       "Login flow expired or is invalid; please log in again",
+	"errors.oidc_exchange_failed":     "Could not verify the login with the identity provider",
+	"errors.oidc_provider_error":      "Could not reach the identity provider; please try again later",
+	"errors.oidc_email_not_verified":  "The identity provider did not supply a verified email",
+	"errors.email_already_registered": "This email is registered but not verified; please log in with your password and verify it first",
+
+	"errors.avatar_not_found":       "Avatar not found",
+	"errors.avatar_too_large":       "Image must not exceed %d MB",
+	"errors.unsupported_image_type": "Only JPEG, PNG and GIF images are supported",
+	"errors.invalid_image":          "Image cannot be read or is too large",
+	"errors.invalid_avatar_size":    "Size must be one of %s",
+
+	"errors.api_key_not_found":     "API key not found or already revoked",
+	"errors.api_key_limit_reached": "API key limit reached; please revoke keys you no longer use",
+	"errors.invalid_scope":         "Contains invalid or unavailable permissions: %s",
+
+	"errors.self_action_not_allowed":  "This action cannot be performed on your own account",
+	"errors.cannot_impersonate_admin": "Administrators cannot be impersonated",
+	"errors.user_already_suspended":   "Account is already suspended",
+	"errors.user_not_suspended":       "Account is not suspended",
+	"errors.role_not_found":           "Role not found",
+	"errors.invalid_date_range":       "Start date must not be after end date",
+	"errors.invalid_time_range":       "Start time must be before end time",
+}
@@ -0,0 +1,178 @@
+package i18n
+
+// jaMessages 為日文訊息
+var jaMessages = map[string]string{
+	"common.unauthorized": "認証されていません",
+	"common.forbidden":    "権限がありません",
+	"common.server_error": "サーバーエラー",
+
+	"validation.required": "この項目は必須です",
+	"validation.invalid":  "形式が正しくありません",
+
+	"validation.password_min_length":        "パスワードは %d 文字以上で入力してください",
+	"validation.password_max_length":        "パスワードは %d 文字以内で入力してください",
+	"validation.password_character_classes": "パスワードには大文字・小文字・数字・記号のうち %d 種類以上を含めてください",
+	"validation.password_strength":          "パスワードが弱すぎます。一般的な単語や連続・繰り返しの文字は避けてください",
+	"validation.password_user_info":         "パスワードにユーザー名やメールアドレスを含めたり、似せたりしないでください",
+	"validation.password_breached":          "このパスワードは漏えいしたデータに含まれています。別のパスワードを使用してください",
+	"validation.password_policy":            "パスワードがセキュリティ要件を満たしていません",
+
+	"auth.register_success":             "登録が完了しました",
+	"auth.register_failed":              "登録に失敗しました",
+	"auth.user_already_exists":          "ユーザーは既に存在します",
+	"auth.login_success":                "ログインしました",
+	"auth.login_failed":                 "ログインに失敗しました",
+	"auth.mfa_required":                 "2段階認証コードを入力してください",
+	"auth.mfa_verification_failed":      "認証に失敗しました",
+	"auth.refresh_success":              "トークンを更新しました",
+	"auth.refresh_failed":               "トークンの更新に失敗しました",
+	"auth.logout_success":               "ログアウトしました",
+	"auth.logout_failed":                "ログアウトに失敗しました",
+	"auth.logged_out":                   "システムからログアウトしました",
+	"auth.list_sessions_failed":         "セッションの取得に失敗しました",
+	"auth.session_revoked":              "セッションを終了しました",
+	"auth.revoke_session_failed":        "セッションの終了に失敗しました",
+	"auth.other_sessions_revoked":       "他のデバイスからログアウトしました",
+	"auth.revoke_other_sessions_failed": "他のデバイスからのログアウトに失敗しました",
+
+	"account.delete_failed":         "アカウントの削除に失敗しました",
+	"account.deletion_scheduled":    "アカウントの削除を予約し、すべての端末からログアウトしました。削除日時までに再度ログインすると取り消せます",
+	"account.export_failed":         "エクスポートに失敗しました",
+	"account.invalid_export_format": "形式は zip または json を指定してください",
+
+	"admin.list_users_failed":     "ユーザーの取得に失敗しました",
+	"admin.invalid_user_query":    "クエリパラメーターの形式が正しくありません。日付は YYYY-MM-DD 形式で指定してください",
+	"admin.invalid_user_id":       "ユーザー ID の形式が正しくありません",
+	"admin.suspend_failed":        "アカウントの停止に失敗しました",
+	"admin.suspended":             "アカウントを停止しました",
+	"admin.unsuspend_failed":      "アカウントの停止解除に失敗しました",
+	"admin.unsuspended":           "アカウントの停止を解除しました",
+	"admin.reset_password_failed": "パスワードの再設定に失敗しました",
+	"admin.password_reset":        "パスワードを再設定し、再設定リンクを送信しました",
+	"admin.change_role_failed":    "ロールの変更に失敗しました",
+	"admin.role_changed":          "ロールを変更しました",
+	"admin.impersonate_failed":    "代理ログインに失敗しました",
+	"admin.impersonation_started": "代理ログイン用のトークンを発行しました",
+	"admin.unlock_failed":         "アカウントのロック解除に失敗しました",
+	"admin.unlocked":              "アカウントのロックを解除しました",
+
+	"audit_log.list_failed":   "監査ログの取得に失敗しました",
+	"audit_log.export_failed": "監査ログのエクスポートに失敗しました",
+	"audit_log.invalid_query": "クエリパラメーターの形式が正しくありません。時刻は RFC 3339 形式（例：2025-01-01T00:00:00Z）で指定してください",
+
+	"api_key.create_failed": "API キーの作成に失敗しました",
+	"api_key.created":       "API キーを作成しました。キーは再表示されないため、今すぐ保存してください",
+	"api_key.list_failed":   "API キーの取得に失敗しました",
+	"api_key.invalid_id":    "API キー ID の形式が正しくありません",
+	"api_key.revoke_failed": "API キーの取り消しに失敗しました",
+	"api_key.revoked":       "API キーを取り消しました",
+
+	"avatar.upload_failed": "アップロードに失敗しました",
+	"avatar.file_required": "アップロードする画像を選択してください",
+	"avatar.updated":       "アバターを更新しました",
+	"avatar.delete_failed": "アバターの削除に失敗しました",
+	"avatar.deleted":       "アバターを削除しました",
+	"avatar.get_failed":    "アバターの取得に失敗しました",
+
+	"email_verification.verified":    "メールアドレスを確認しました",
+	"email_verification.send_failed": "メールの送信に失敗しました",
+	"email_verification.sent":        "確認メールを送信しました",
+
+	"magic_link.send_failed": "メールの送信に失敗しました",
+	"magic_link.sent":        "このメールアドレスが登録されている場合、ログインリンクが届きます",
+
+	"mfa.setup_failed":   "設定に失敗しました",
+	"mfa.setup_started":  "認証アプリでスキャンし、認証コードを入力して設定を完了してください",
+	"mfa.enabled":        "2段階認証を有効にしました。リカバリーコードを安全に保管してください",
+	"mfa.disable_failed": "2段階認証の無効化に失敗しました",
+	"mfa.disabled":       "2段階認証を無効にしました",
+
+	"oidc.list_providers_success": "ID プロバイダーを取得しました",
+	"oidc.login_failed":           "外部ログインに失敗しました",
+	"oidc.redirect_to_provider":   "ID プロバイダーでログインしてください",
+
+	"password_reset.send_failed":  "メールの送信に失敗しました",
+	"password_reset.sent":         "このメールアドレスが登録されている場合、パスワード再設定リンクが届きます",
+	"password_reset.reset_failed": "パスワードの再設定に失敗しました",
+	"password_reset.completed":    "パスワードを再設定しました。再度ログインしてください",
+
+	"profile.update_failed":          "更新に失敗しました",
+	"profile.updated":                "プロフィールを更新しました",
+	"profile.change_password_failed": "パスワードの変更に失敗しました",
+	"profile.password_changed":       "パスワードを変更しました。他の端末からはログアウトされました",
+	"profile.change_email_failed":    "メールアドレスの変更に失敗しました",
+	"profile.email_change_requested": "新しいメールアドレスに確認メールを送信しました。確認が完了すると変更されます",
+
+	"errors.validation_failed":     "入力内容に誤りがあります",
+	"errors.internal_server_error": "サーバー内部エラー",
+	"errors.account_locked":        "ログインの失敗回数が多すぎます。%d 秒後に再度お試しください",
+	"errors.rate_limit_exceeded":   "リクエストが多すぎます。%d 秒後に再度お試しください",
+	"errors.request_timeout":       "リクエストがタイムアウトしました。しばらくしてから再度お試しください",
+
+	"errors.user_not_found":           "ユーザーが見つかりません",
+	"errors.user_already_exists":      "メールアドレスまたはユーザー名は既に使用されています",
+	"errors.username_taken":           "ユーザー名は既に使用されています",
+	"errors.email_already_in_use":     "このメールアドレスは既に使用されています",
+	"errors.email_already_verified":   "メールアドレスは確認済みです",
+	"errors.user_update_conflict":     "データが別の操作で更新されました。再読み込みしてからお試しください",
+	"errors.no_profile_changes":       "更新する項目がありません",
+	"errors.account_suspended":        "アカウントは停止されています。サポートにお問い合わせください",
+	"errors.passwords_do_not_match":   "確認用パスワードが一致しません",
+	"errors.invalid_username":         "ユーザー名には英字・数字・アンダースコアのみ使用できます",
+	"errors.invalid_current_password": "現在のパスワードが正しくありません",
+	"errors.email_unchanged":          "新しいメールアドレスが現在のものと同じです",
+	"errors.invalid_avatar_url":       "アバターの URL は http または https である必要があります",
+	"errors.unsupported_locale":       "言語は %s のいずれかを指定してください",
+
+	"errors.invalid_credentials":        "メールアドレスまたはパスワードが正しくありません",
+	"errors.invalid_refresh_token":      "リフレッシュトークンが無効か、有効期限が切れています",
+	"errors.refresh_token_reused":       "リフレッシュトークンは既に使用されています。再度ログインしてください",
+	"errors.session_not_found":          "セッションが見つからないか、既に終了しています",
+	"errors.invalid_mfa_token":          "認証の有効期限が切れました。再度ログインしてください",
+	"errors.invalid_reset_token":        "再設定リンクが無効か、有効期限が切れています",
+	"errors.invalid_verification_token": "確認リンクが無効か、有効期限が切れています",
+	"errors.invalid_magic_link":         "ログインリンクが無効か、有効期限が切れています",
+	"errors.invalid_api_key":            "API キーが無効、取り消し済み、または有効期限切れです",
+	"errors.reauthentication_required":  "現在のパスワードを入力するか、再ログイン後 %d 分以内にもう一度お試しください",
+	"errors.missing_token":              "Authorization ヘッダーが必要です",
+	"errors.invalid_token_format":       "Authorization ヘッダーの形式が正しくありません",
+	"errors.invalid_token":              "トークンが無効か、有効期限が切れています",
+	"errors.token_revoked":              "トークンは取り消されています",
+	"errors.session_revoked":            "セッションが終了しました。再度ログインしてください",
+
+	"errors.unauthorized":              "ユーザー情報を取得できません",
+	"errors.permission_denied":         "権限がありません：%s",
+	"errors.email_not_verified":        "先にメールアドレスの確認を完了してください",
+	"errors.api_key_not_allowed":       "この操作にはアカウントでのログインが必要です。API キーは使用できません",
+	"errors.impersonation_not_allowed": "ユーザーの代理ログイン中はこの操作を実行できません",
+
+	"errors.invalid_mfa_code":    "認証コードが正しくありません",
+	"errors.mfa_already_enabled": "2段階認証は既に有効です",
+	"errors.mfa_not_enabled":     "2段階認証が有効になっていません",
+	"errors.mfa_setup_required":  "先に2段階認証の設定を開始してください",
+
+	"errors.unknown_oidc_provider":    "対応していない ID プロバイダーです",
+	"errors.invalid_oidc_state":       "ログイン手続きの有効期限が切れたか無効です。再度ログインしてください",
+	"errors.oidc_exchange_failed":     "ID プロバイダーでログインを確認できませんでした",
+	"errors.oidc_provider_error":      "ID プロバイダーに接続できません。しばらくしてから再度お試しください",
+	"errors.oidc_email_not_verified":  "ID プロバイダーから確認済みのメールアドレスが提供されませんでした",
+	"errors.email_already_registered": "このメールアドレスは登録済みですが未確認です。パスワードでログインして確認を完了してください",
+
+	"errors.avatar_not_found":       "アバターが見つかりません",
+	"errors.avatar_too_large":       "画像は %d MB 以下にしてください",
+	"errors.unsupported_image_type": "JPEG、PNG、GIF 画像のみ対応しています",
+	"errors.invalid_image":          "画像を読み込めないか、サイズが大きすぎます",
+	"errors.invalid_avatar_size":    "サイズは %s のいずれかを指定してください",
+
+	"errors.api_key_not_found":     "API キーが見つからないか、既に取り消されています",
+	"errors.api_key_limit_reached": "API キーの数が上限に達しました。使用していないキーを取り消してください",
+	"errors.invalid_scope":         "無効または付与されていない権限が含まれています：%s",
+
+	"errors.self_action_not_allowed":  "自分のアカウントに対してこの操作は実行できません",
+	"errors.cannot_impersonate_admin": "管理者として代理ログインすることはできません",
+	"errors.user_already_suspended":   "アカウントは既に停止されています",
+	"errors.user_not_suspended":       "アカウントは停止されていません",
+	"errors.role_not_found":           "ロールが見つかりません",
+	"errors.invalid_date_range":       "開始日は終了日より後にできません",
+	"errors.invalid_time_range":       "開始時刻は終了時刻より前にしてください",
+}
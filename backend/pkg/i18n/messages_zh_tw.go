@@ -0,0 +1,178 @@
+package i18n
+
+// zhTWMessages 為正體中文訊息，也是其他語系缺少訊息時的備援
+var zhTWMessages = map[string]string{
+	"common.unauthorized": "未授權",
+	"common.forbidden":    "權限不足",
+	"common.server_error": "伺服器錯誤",
+
+	"validation.required": "此欄位為必填",
+	"validation.invalid":  "格式不正確",
+
+	"validation.password_min_length":        "密碼至少需要 %d 個字符",
+	"validation.password_max_length":        "密碼不能超過 %d 個字符",
+	"validation.password_character_classes": "密碼需包含大寫字母、小寫字母、數字、符號其中至少 %d 種",
+	"validation.password_strength":          "密碼強度不足，請避免常見單字、連續或重複的字元",
+	"validation.password_user_info":         "密碼不能包含或近似用戶名、電子郵件",
+	"validation.password_breached":          "此密碼曾出現在外洩資料中，請改用其他密碼",
+	"validation.password_policy":            "密碼不符合安全要求",
+
+	"auth.register_success":             "註冊成功",
+	"auth.register_failed":              "註冊失敗",
+	"auth.user_already_exists":          "用戶已存在",
+	"auth.login_success":                "登入成功",
+	"auth.login_failed":                 "登入失敗",
+	"auth.mfa_required":                 "請輸入兩步驟驗證碼",
+	"auth.mfa_verification_failed":      "驗證失敗",
+	"auth.refresh_success":              "換發成功",
+	"auth.refresh_failed":               "換發失敗",
+	"auth.logout_success":               "登出成功",
+	"auth.logout_failed":                "登出失敗",
+	"auth.logged_out":                   "已成功登出系統",
+	"auth.list_sessions_failed":         "獲取會話失敗",
+	"auth.session_revoked":              "會話已結束",
+	"auth.revoke_session_failed":        "結束會話失敗",
+	"auth.other_sessions_revoked":       "已登出其他裝置",
+	"auth.revoke_other_sessions_failed": "登出其他裝置失敗",
+
+	"account.delete_failed":         "刪除帳號失敗",
+	"account.deletion_scheduled":    "帳號已排定刪除，所有裝置已登出；在刪除時間前重新登入即可取消",
+	"account.export_failed":         "匯出失敗",
+	"account.invalid_export_format": "格式必須為 zip 或 json",
+
+	"admin.list_users_failed":     "查詢用戶失敗",
+	"admin.invalid_user_query":    "查詢參數格式不正確，日期請使用 YYYY-MM-DD",
+	"admin.invalid_user_id":       "用戶 ID 格式不正確",
+	"admin.suspend_failed":        "停用帳號失敗",
+	"admin.suspended":             "帳號已停用",
+	"admin.unsuspend_failed":      "解除停用失敗",
+	"admin.unsuspended":           "已解除帳號停用",
+	"admin.reset_password_failed": "重設密碼失敗",
+	"admin.password_reset":        "已重設密碼並寄出重設連結",
+	"admin.change_role_failed":    "變更角色失敗",
+	"admin.role_changed":          "角色已變更",
+	"admin.impersonate_failed":    "代入用戶身分失敗",
+	"admin.impersonation_started": "已取得代入用戶身分的 token",
+	"admin.unlock_failed":         "解除鎖定失敗",
+	"admin.unlocked":              "已解除帳號鎖定",
+
+	"audit_log.list_failed":   "查詢稽核日誌失敗",
+	"audit_log.export_failed": "匯出稽核日誌失敗",
+	"audit_log.invalid_query": "查詢參數格式不正確，時間請使用 RFC 3339 格式，例如 2025-01-01T00:00:00Z",
+
+	"api_key.create_failed": "建立失敗",
+	"api_key.created":       "API 金鑰已建立，請立即保存，金鑰不會再次顯示",
+	"api_key.list_failed":   "獲取 API 金鑰失敗",
+	"api_key.invalid_id":    "API 金鑰 ID 格式不正確",
+	"api_key.revoke_failed": "撤銷失敗",
+	"api_key.revoked":       "API 金鑰已撤銷",
+
+	"avatar.upload_failed": "上傳失敗",
+	"avatar.file_required": "請選擇要上傳的圖片",
+	"avatar.updated":       "頭像已更新",
+	"avatar.delete_failed": "移除失敗",
+	"avatar.deleted":       "頭像已移除",
+	"avatar.get_failed":    "取得頭像失敗",
+
+	"email_verification.verified":    "電子郵件驗證成功",
+	"email_verification.send_failed": "寄送失敗",
+	"email_verification.sent":        "驗證郵件已寄出",
+
+	"magic_link.send_failed": "寄送失敗",
+	"magic_link.sent":        "若此電子郵件已註冊，您將收到登入連結",
+
+	"mfa.setup_failed":   "設定失敗",
+	"mfa.setup_started":  "請以驗證器 App 掃描後輸入驗證碼完成設定",
+	"mfa.enabled":        "兩步驟驗證已啟用，請妥善保存復原碼",
+	"mfa.disable_failed": "停用失敗",
+	"mfa.disabled":       "兩步驟驗證已停用",
+
+	"oidc.list_providers_success": "獲取身分提供者成功",
+	"oidc.login_failed":           "外部登入失敗",
+	"oidc.redirect_to_provider":   "請前往身分提供者登入",
+
+	"password_reset.send_failed":  "寄送失敗",
+	"password_reset.sent":         "若此電子郵件已註冊，您將收到密碼重設連結",
+	"password_reset.reset_failed": "重設失敗",
+	"password_reset.completed":    "密碼已重設，請重新登入",
+
+	"profile.update_failed":          "更新失敗",
+	"profile.updated":                "個人資料已更新",
+	"profile.change_password_failed": "變更密碼失敗",
+	"profile.password_changed":       "密碼已變更，其他裝置已登出",
+	"profile.change_email_failed":    "變更失敗",
+	"profile.email_change_requested": "驗證郵件已寄送至新的電子郵件，完成驗證後才會變更",
+
+	"errors.validation_failed":     "驗證失敗",
+	"errors.internal_server_error": "伺服器內部錯誤",
+	"errors.account_locked":        "登入失敗次數過多，請於 %d 秒後再試",
+	"errors.rate_limit_exceeded":   "申請次數過多，請於 %d 秒後再試",
+	"errors.request_timeout":       "請求處理逾時，請稍後再試",
+
+	"errors.user_not_found":           "用戶不存在",
+	"errors.user_already_exists":      "電子郵件或用戶名已被使用",
+	"errors.username_taken":           "用戶名已被使用",
+	"errors.email_already_in_use":     "此電子郵件已被使用",
+	"errors.email_already_verified":   "電子郵件已完成驗證",
+	"errors.user_update_conflict":     "資料已被其他操作更新，請重新載入後再試",
+	"errors.no_profile_changes":       "沒有要更新的欄位",
+	"errors.account_suspended":        "帳號已被停用，請聯絡客服",
+	"errors.passwords_do_not_match":   "密碼確認不一致",
+	"errors.invalid_username":         "用戶名只能包含字母、數字和底線",
+	"errors.invalid_current_password": "目前密碼不正確",
+	"errors.email_unchanged":          "新電子郵件與目前相同",
+	"errors.invalid_avatar_url":       "頭像網址必須為 http 或 https 網址",
+	"errors.unsupported_locale":       "語系必須為 %s 之一",
+
+	"errors.invalid_credentials":        "電子郵件或密碼錯誤",
+	"errors.invalid_refresh_token":      "Refresh token 無效或已過期",
+	"errors.refresh_token_reused":       "Refresh token 已被使用，請重新登入",
+	"errors.session_not_found":          "會話不存在或已結束",
+	"errors.invalid_mfa_token":          "驗證已逾時，請重新登入",
+	"errors.invalid_reset_token":        "重設連結無效或已過期",
+	"errors.invalid_verification_token": "驗證連結無效或已過期",
+	"errors.invalid_magic_link":         "登入連結無效或已過期",
+	"errors.invalid_api_key":            "API 金鑰無效、已撤銷或已過期",
+	"errors.reauthentication_required":  "請提供目前密碼，或在重新登入後 %d 分鐘內再試一次",
+	"errors.missing_token":              "缺少 Authorization 標頭",
+	"errors.invalid_token_format":       "Authorization 標頭格式不正確",
+	"errors.invalid_token":              "Token 無效或已過期",
+	"errors.token_revoked":              "Token 已被撤銷",
+	"errors.session_revoked":            "會話已結束，請重新登入",
+
+	"errors.unauthorized":              "無法獲取用戶資訊",
+	"errors.permission_denied":         "缺少權限：%s",
+	"errors.email_not_verified":        "請先完成電子郵件驗證",
+	"errors.api_key_not_allowed":       "此操作需要以帳號登入，無法使用 API 金鑰",
+	"errors.impersonation_not_allowed": "代入用戶身分時無法執行此操作",
+
+	"errors.invalid_mfa_code":    "驗證碼錯誤",
+	"errors.mfa_already_enabled": "已啟用兩步驟驗證",
+	"errors.mfa_not_enabled":     "尚未啟用兩步驟驗證",
+	"errors.mfa_setup_required":  "請先開始兩步驟驗證設定",
+
+	"errors.unknown_oidc_provider":    "不支援的身分提供者",
+	"errors.invalid_oidc_state":       "登入流程已逾時或無效，請重新登入",
+	"errors.oidc_exchange_failed":     "無法向身分提供者驗證登入",
+	"errors.oidc_provider_error":      "無法連線到身分提供者，請稍後再試",
+	"errors.oidc_email_not_verified":  "身分提供者未提供已驗證的電子郵件",
+	"errors.email_already_registered": "此電子郵件已註冊但尚未驗證，請先以密碼登入並完成驗證",
+
+	"errors.avatar_not_found":       "頭像不存在",
+	"errors.avatar_too_large":       "圖片不可超過 %d MB",
+	"errors.unsupported_image_type": "僅支援 JPEG、PNG 與 GIF 圖片",
+	"errors.invalid_image":          "圖片無法讀取或尺寸過大",
+	"errors.invalid_avatar_size":    "尺寸必須為 %s 之一",
+
+	"errors.api_key_not_found":     "API 金鑰不存在或已撤銷",
+	"errors.api_key_limit_reached": "API 金鑰數量已達上限，請先撤銷不再使用的金鑰",
+	"errors.invalid_scope":         "包含無效或未擁有的權限：%s",
+
+	"errors.self_action_not_allowed":  "無法對自己的帳號執行此操作",
+	"errors.cannot_impersonate_admin": "無法代入管理員的身分",
+	"errors.user_already_suspended":   "帳號已是停用狀態",
+	"errors.user_not_suspended":       "帳號未被停用",
+	"errors.role_not_found":           "角色不存在",
+	"errors.invalid_date_range":       "起始日期不可晚於結束日期",
+	"errors.invalid_time_range":       "起始時間須早於結束時間",
+}
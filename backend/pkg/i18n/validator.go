@@ -0,0 +1,93 @@
+package i18n
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/go-playground/locales"
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/ja"
+	"github.com/go-playground/locales/zh_Hant_TW"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	entranslations "github.com/go-playground/validator/v10/translations/en"
+	jatranslations "github.com/go-playground/validator/v10/translations/ja"
+	zhtwtranslations "github.com/go-playground/validator/v10/translations/zh_tw"
+)
+
+var (
+	registerOnce sync.Once
+	registerErr  error
+	// translators 為各語系的驗證訊息翻譯器，由 RegisterValidator 建立
+	translators map[string]ut.Translator
+)
+
+// RegisterValidator 在驗證器註冊各語系的驗證訊息，訊息中的欄位名稱改用 JSON（或查詢參數）名稱。
+// gin 的 binding 驗證器為全域共用，只會註冊一次，之後的呼叫回傳第一次註冊的結果。
+func RegisterValidator(v *validator.Validate) error {
+	registerOnce.Do(func() {
+		registerErr = registerValidator(v)
+	})
+	return registerErr
+}
+
+func registerValidator(v *validator.Validate) error {
+	registrations := []struct {
+		locale     string
+		translator locales.Translator
+		register   func(*validator.Validate, ut.Translator) error
+	}{
+		{LocaleZhTW, zh_Hant_TW.New(), zhtwtranslations.RegisterDefaultTranslations},
+		{LocaleEn, en.New(), entranslations.RegisterDefaultTranslations},
+		{LocaleJa, ja.New(), jatranslations.RegisterDefaultTranslations},
+	}
+
+	supported := make([]locales.Translator, 0, len(registrations))
+	for _, r := range registrations {
+		supported = append(supported, r.translator)
+	}
+	uni := ut.New(registrations[0].translator, supported...)
+
+	registered := make(map[string]ut.Translator, len(registrations))
+	for _, r := range registrations {
+		trans, _ := uni.GetTranslator(r.translator.Locale())
+		if err := r.register(v, trans); err != nil {
+			return err
+		}
+		registered[r.locale] = trans
+	}
+
+	v.RegisterTagNameFunc(fieldName)
+	translators = registered
+	return nil
+}
+
+// fieldName 取得欄位在請求中的名稱：JSON 欄位優先，其次為查詢參數名稱
+func fieldName(field reflect.StructField) string {
+	for _, tag := range []string{"json", "form"} {
+		name := strings.SplitN(field.Tag.Get(tag), ",", 2)[0]
+		if name != "" && name != "-" {
+			return name
+		}
+	}
+	return ""
+}
+
+// ValidationMessage 取得欄位驗證錯誤在指定語系的訊息；驗證規則沒有對應的翻譯時回傳「格式不正確」
+func ValidationMessage(locale string, fieldError validator.FieldError) string {
+	trans, ok := translators[locale]
+	if !ok {
+		trans, ok = translators[DefaultLocale]
+	}
+	if !ok {
+		return T(locale, "validation.invalid")
+	}
+
+	// 沒有翻譯時 Translate 回傳驗證器原始的英文錯誤
+	message := fieldError.Translate(trans)
+	if message == fieldError.Error() {
+		return T(locale, "validation.invalid")
+	}
+	return message
+}
@@ -0,0 +1,111 @@
+// Package imaging 處理用戶上傳的圖片：驗證格式與尺寸、依 EXIF 方向轉正、裁切縮圖並重新編碼。
+// 重新編碼的輸出只包含像素資料，原始檔中的 EXIF（含 GPS 位置）、ICC 與文字區塊都不會保留。
+package imaging
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	"net/http"
+	"smart-learning-backend/pkg/apperrors"
+
+	// 註冊 GIF 與 PNG 解碼器
+	_ "image/gif"
+	_ "image/png"
+)
+
+const (
+	// MaxPixels 為解碼前允許的最大像素數，避免小檔案宣告巨大尺寸耗盡記憶體
+	MaxPixels = 40_000_000
+	// jpegQuality 為輸出 JPEG 的品質
+	jpegQuality = 85
+)
+
+// supportedTypes 為以檔案內容判斷（不採信副檔名與 Content-Type 標頭）後接受的格式
+var supportedTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+}
+
+// Decode 驗證並解碼 JPEG、PNG 或 GIF（取第一格），依 EXIF 方向轉正，
+// 透明區域以白色填滿後回傳不含任何中繼資料的 RGBA 影像
+func Decode(data []byte) (*image.RGBA, error) {
+	if !supportedTypes[http.DetectContentType(data)] {
+		return nil, apperrors.ErrUnsupportedImageType
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, apperrors.ErrInvalidImage.Wrap(err)
+	}
+	if config.Width <= 0 || config.Height <= 0 || config.Width*config.Height > MaxPixels {
+		return nil, apperrors.ErrImageTooLarge
+	}
+
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, apperrors.ErrInvalidImage.Wrap(err)
+	}
+
+	rgba := flatten(img)
+	if format == "jpeg" {
+		rgba = orient(rgba, jpegOrientation(data))
+	}
+	return rgba, nil
+}
+
+// flatten 將影像轉為原點在 (0,0) 的 RGBA，透明區域以白色填滿
+func flatten(img image.Image) *image.RGBA {
+	bounds := img.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(dst, dst.Bounds(), image.White, image.Point{}, draw.Src)
+	draw.Draw(dst, dst.Bounds(), img, bounds.Min, draw.Over)
+	return dst
+}
+
+// Thumbnail 將影像置中裁切為正方形並以區域平均縮小到 size×size；原圖較小時不放大
+func Thumbnail(src *image.RGBA, size int) *image.RGBA {
+	bounds := src.Bounds()
+	side := min(bounds.Dx(), bounds.Dy())
+	x0 := bounds.Min.X + (bounds.Dx()-side)/2
+	y0 := bounds.Min.Y + (bounds.Dy()-side)/2
+	size = min(size, side)
+
+	dst := image.NewRGBA(image.Rect(0, 0, size, size))
+	for dy := 0; dy < size; dy++ {
+		sy0, sy1 := y0+dy*side/size, y0+(dy+1)*side/size
+		for dx := 0; dx < size; dx++ {
+			sx0, sx1 := x0+dx*side/size, x0+(dx+1)*side/size
+
+			var sum [4]int
+			for sy := sy0; sy < sy1; sy++ {
+				offset := src.PixOffset(sx0, sy)
+				for sx := sx0; sx < sx1; sx++ {
+					for c := 0; c < 4; c++ {
+						sum[c] += int(src.Pix[offset+c])
+					}
+					offset += 4
+				}
+			}
+
+			count := (sy1 - sy0) * (sx1 - sx0)
+			offset := dst.PixOffset(dx, dy)
+			for c := 0; c < 4; c++ {
+				dst.Pix[offset+c] = uint8((sum[c] + count/2) / count)
+			}
+		}
+	}
+	return dst
+}
+
+// EncodeJPEG 將影像編碼為 JPEG
+func EncodeJPEG(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality}); err != nil {
+		return nil, fmt.Errorf("failed to encode image: %w", err)
+	}
+	return buf.Bytes(), nil
+}
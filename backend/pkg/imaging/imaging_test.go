@@ -0,0 +1,189 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+var (
+	red  = color.RGBA{R: 255, A: 255}
+	blue = color.RGBA{B: 255, A: 255}
+)
+
+// halfImage 建立左半紅色、右半藍色的影像
+func halfImage(w, h int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			if x < w/2 {
+				img.Set(x, y, red)
+			} else {
+				img.Set(x, y, blue)
+			}
+		}
+	}
+	return img
+}
+
+// jpegWithExif 編碼 JPEG 並在 SOI 之後插入含方向標記與 GPS 假資料的 APP1 區段
+func jpegWithExif(t *testing.T, img image.Image, orientation uint16) []byte {
+	t.Helper()
+
+	var encoded bytes.Buffer
+	if err := jpeg.Encode(&encoded, img, &jpeg.Options{Quality: 95}); err != nil {
+		t.Fatal(err)
+	}
+
+	tiff := []byte("MM\x00\x2A\x00\x00\x00\x08")
+	tiff = binary.BigEndian.AppendUint16(tiff, 1)
+	tiff = binary.BigEndian.AppendUint16(tiff, exifOrientationTag)
+	tiff = binary.BigEndian.AppendUint16(tiff, 3)
+	tiff = binary.BigEndian.AppendUint32(tiff, 1)
+	tiff = binary.BigEndian.AppendUint16(tiff, orientation)
+	tiff = append(tiff, 0, 0, 0, 0, 0, 0)
+	tiff = append(tiff, []byte("GPSLatitude=25.0330N")...)
+
+	segment := append([]byte("Exif\x00\x00"), tiff...)
+	app1 := []byte{0xFF, 0xE1}
+	app1 = binary.BigEndian.AppendUint16(app1, uint16(len(segment)+2))
+	app1 = append(app1, segment...)
+
+	data := encoded.Bytes()
+	return append(append(append([]byte{}, data[:2]...), app1...), data[2:]...)
+}
+
+// isRed 判斷 JPEG 壓縮後的像素是否以紅色為主
+func isRed(c color.Color) bool {
+	r, _, b, _ := c.RGBA()
+	return r > 0xC000 && b < 0x4000
+}
+
+func TestJPEGOrientation(t *testing.T) {
+	for orientation := uint16(1); orientation <= 8; orientation++ {
+		if got := jpegOrientation(jpegWithExif(t, halfImage(16, 8), orientation)); got != int(orientation) {
+			t.Errorf("jpegOrientation() = %d, want %d", got, orientation)
+		}
+	}
+
+	var plain bytes.Buffer
+	jpeg.Encode(&plain, halfImage(16, 8), nil)
+	if got := jpegOrientation(plain.Bytes()); got != 1 {
+		t.Errorf("jpegOrientation() without exif = %d, want 1", got)
+	}
+	if got := jpegOrientation([]byte{0xFF, 0xD8, 0xFF, 0xE1, 0xFF, 0xFF}); got != 1 {
+		t.Errorf("jpegOrientation() with truncated segment = %d, want 1", got)
+	}
+}
+
+func TestDecode_AppliesOrientationAndStripsMetadata(t *testing.T) {
+	tests := []struct {
+		orientation uint16
+		width       int
+		height      int
+		// redAt 為轉正後仍為紅色（原圖左半）的像素
+		redAt image.Point
+	}{
+		{orientation: 1, width: 16, height: 8, redAt: image.Pt(2, 4)},
+		{orientation: 3, width: 16, height: 8, redAt: image.Pt(13, 4)},
+		{orientation: 6, width: 8, height: 16, redAt: image.Pt(4, 2)},
+		{orientation: 8, width: 8, height: 16, redAt: image.Pt(4, 13)},
+	}
+
+	for _, tt := range tests {
+		img, err := Decode(jpegWithExif(t, halfImage(16, 8), tt.orientation))
+		if err != nil {
+			t.Fatalf("Decode() orientation %d unexpected error = %v", tt.orientation, err)
+		}
+
+		if img.Bounds().Dx() != tt.width || img.Bounds().Dy() != tt.height {
+			t.Errorf("orientation %d: size = %v, want %dx%d", tt.orientation, img.Bounds().Size(), tt.width, tt.height)
+		}
+		if !isRed(img.At(tt.redAt.X, tt.redAt.Y)) {
+			t.Errorf("orientation %d: pixel %v = %v, want red", tt.orientation, tt.redAt, img.At(tt.redAt.X, tt.redAt.Y))
+		}
+
+		encoded, err := EncodeJPEG(img)
+		if err != nil {
+			t.Fatalf("EncodeJPEG() unexpected error = %v", err)
+		}
+		if bytes.Contains(encoded, []byte("Exif")) || bytes.Contains(encoded, []byte("GPSLatitude")) {
+			t.Errorf("orientation %d: re-encoded image still contains EXIF data", tt.orientation)
+		}
+	}
+}
+
+func TestDecode_FlattensTransparency(t *testing.T) {
+	var buf bytes.Buffer
+	png.Encode(&buf, image.NewNRGBA(image.Rect(0, 0, 4, 4)))
+
+	img, err := Decode(buf.Bytes())
+	if err != nil {
+		t.Fatalf("Decode() unexpected error = %v", err)
+	}
+	if got := img.RGBAAt(1, 1); got != (color.RGBA{R: 255, G: 255, B: 255, A: 255}) {
+		t.Errorf("transparent pixel = %v, want white", got)
+	}
+}
+
+func TestDecode_Errors(t *testing.T) {
+	// GIF 標頭宣告 65535×65535，不含任何影像資料
+	hugeGIF := []byte("GIF89a\xFF\xFF\xFF\xFF\x00\x00\x00")
+
+	tests := []struct {
+		name          string
+		data          []byte
+		errorContains string
+	}{
+		{name: "非圖片", data: []byte("<svg xmlns='http://www.w3.org/2000/svg'></svg>"), errorContains: "unsupported image type"},
+		{name: "BMP", data: append([]byte("BM"), make([]byte, 64)...), errorContains: "unsupported image type"},
+		{name: "損壞的 PNG", data: append([]byte("\x89PNG\r\n\x1a\n"), make([]byte, 32)...), errorContains: "invalid image"},
+		{name: "尺寸過大", data: hugeGIF, errorContains: "image dimensions too large"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Decode(tt.data)
+			if err == nil || !strings.Contains(err.Error(), tt.errorContains) {
+				t.Errorf("Decode() error = %v, want containing %q", err, tt.errorContains)
+			}
+		})
+	}
+}
+
+func TestThumbnail(t *testing.T) {
+	// 300×200：左側 50px 為紅色，其餘為藍色，置中裁切後應只剩藍色
+	src := image.NewRGBA(image.Rect(0, 0, 300, 200))
+	for y := 0; y < 200; y++ {
+		for x := 0; x < 300; x++ {
+			if x < 50 {
+				src.Set(x, y, red)
+			} else {
+				src.Set(x, y, blue)
+			}
+		}
+	}
+
+	tests := []struct {
+		size int
+		want int
+	}{
+		{size: 64, want: 64},
+		{size: 256, want: 200},
+	}
+
+	for _, tt := range tests {
+		thumb := Thumbnail(src, tt.size)
+		if thumb.Bounds().Dx() != tt.want || thumb.Bounds().Dy() != tt.want {
+			t.Errorf("Thumbnail(%d) size = %v, want %dx%d", tt.size, thumb.Bounds().Size(), tt.want, tt.want)
+		}
+		if got := thumb.RGBAAt(0, 0); got != blue {
+			t.Errorf("Thumbnail(%d) corner = %v, want blue (red edge cropped)", tt.size, got)
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package interfaces
+
+import (
+	"context"
+	"smart-learning-backend/pkg/models"
+)
+
+// UserDataPurgerInterface 清除用戶存放在資料庫以外的資料（例如上傳的檔案），
+// 資料庫內的資料由外鍵 ON DELETE CASCADE 隨用戶一併刪除
+type UserDataPurgerInterface interface {
+	PurgeUserData(user *models.User) error
+}
+
+// UserDataExporterInterface 提供個人資料匯出的一個區段（例如單字表、學習紀錄、複習排程）；
+// 功能模組實作此介面並在建立 AccountService 時註冊，區段名稱不可重複，也不可為 profile 或 security
+type UserDataExporterInterface interface {
+	ExportSection() string
+	ExportUserData(ctx context.Context, user *models.User) (interface{}, error)
+}
+
+// AccountServiceInterface 定義帳號刪除與個人資料匯出的服務介面
+type AccountServiceInterface interface {
+	RequestDeletion(ctx context.Context, userID int, sessionID string, req *models.DeleteAccountRequest) (*models.AccountDeletionResponse, error)
+	ExportUserData(ctx context.Context, userID int) (*models.UserDataExport, error)
+}
@@ -0,0 +1,22 @@
+package interfaces
+
+import (
+	"context"
+	"smart-learning-backend/pkg/models"
+)
+
+// PasswordResetForcerInterface 讓原密碼失效、登出所有裝置並寄送重設連結，供管理員強制重設密碼
+type PasswordResetForcerInterface interface {
+	ForceReset(ctx context.Context, userID int) error
+}
+
+// AdminServiceInterface 定義管理員用戶管理的服務介面；adminID 為執行操作的管理員
+type AdminServiceInterface interface {
+	ListUsers(ctx context.Context, req *models.ListUsersRequest) (*models.UserListResponse, error)
+	GetUser(ctx context.Context, userID int) (*models.User, error)
+	SuspendUser(ctx context.Context, adminID, userID int, req *models.SuspendUserRequest) (*models.User, error)
+	UnsuspendUser(ctx context.Context, userID int) (*models.User, error)
+	ForcePasswordReset(ctx context.Context, userID int) error
+	ChangeRole(ctx context.Context, adminID, userID int, req *models.ChangeRoleRequest) (*models.User, error)
+	Impersonate(ctx context.Context, adminID, userID int) (*models.ImpersonationResponse, error)
+}
@@ -0,0 +1,28 @@
+package interfaces
+
+import (
+	"context"
+	"smart-learning-backend/pkg/models"
+)
+
+// APIKeyRepositoryInterface 定義 API 金鑰倉庫的介面
+type APIKeyRepositoryInterface interface {
+	CreateAPIKey(ctx context.Context, key *models.APIKey) error
+	ListAPIKeys(ctx context.Context, userID int) ([]models.APIKey, error)
+	GetAPIKeyByHash(ctx context.Context, keyHash string) (*models.APIKey, error)
+	RevokeAPIKey(ctx context.Context, userID, id int) error
+	TouchAPIKey(ctx context.Context, id int) error
+}
+
+// APIKeyAuthenticatorInterface 定義以 API 金鑰驗證請求的介面，供 AuthMiddleware 使用
+type APIKeyAuthenticatorInterface interface {
+	AuthenticateAPIKey(ctx context.Context, key string) (*models.APIKeyPrincipal, error)
+}
+
+// APIKeyServiceInterface 定義 API 金鑰服務的介面
+type APIKeyServiceInterface interface {
+	APIKeyAuthenticatorInterface
+	CreateAPIKey(ctx context.Context, userID int, req *models.CreateAPIKeyRequest) (*models.CreateAPIKeyResponse, error)
+	ListAPIKeys(ctx context.Context, userID int) ([]models.APIKey, error)
+	RevokeAPIKey(ctx context.Context, userID, id int) error
+}
@@ -0,0 +1,27 @@
+package interfaces
+
+import (
+	"context"
+	"smart-learning-backend/pkg/models"
+)
+
+// AuditLogRepositoryInterface 定義稽核日誌倉庫的介面；紀錄只能新增，不能修改或刪除
+type AuditLogRepositoryInterface interface {
+	CreateAuditLogEntry(ctx context.Context, entry *models.AuditLogEntry) error
+	// ListAuditLogEntries 回傳該頁的紀錄與符合條件的總數；Page 與 PageSize 須由呼叫端先設定
+	ListAuditLogEntries(ctx context.Context, query *models.AuditLogQuery) ([]models.AuditLogEntry, int, error)
+	// ExportAuditLogEntries 依時間先後逐筆讀出符合條件的紀錄，不分頁
+	ExportAuditLogEntries(ctx context.Context, query *models.AuditLogQuery, fn func(entry *models.AuditLogEntry) error) error
+}
+
+// AuditRecorderInterface 寫入稽核紀錄；寫入失敗只記錄在日誌，不影響原本的操作
+type AuditRecorderInterface interface {
+	Record(ctx context.Context, entry *models.AuditLogEntry)
+}
+
+// AuditLogServiceInterface 定義稽核日誌的服務介面，供管理員查詢與匯出
+type AuditLogServiceInterface interface {
+	AuditRecorderInterface
+	ListEntries(ctx context.Context, query *models.AuditLogQuery) (*models.AuditLogListResponse, error)
+	ExportEntries(ctx context.Context, query *models.AuditLogQuery, fn func(entry *models.AuditLogEntry) error) error
+}
@@ -0,0 +1,14 @@
+package interfaces
+
+import (
+	"context"
+	"smart-learning-backend/pkg/models"
+)
+
+// AvatarServiceInterface 定義頭像上傳與下載網址的服務介面
+type AvatarServiceInterface interface {
+	UploadAvatar(ctx context.Context, userID int, data []byte) (*models.User, error)
+	DeleteAvatar(ctx context.Context, userID int) (*models.User, error)
+	// AvatarURL 回傳指定版本與尺寸的頭像下載網址（簽章或靜態網址）
+	AvatarURL(userID int, version string, size int) (string, error)
+}
@@ -0,0 +1,11 @@
+package interfaces
+
+// BlobStoreInterface 定義上傳檔案的儲存介面，可替換為本機目錄或 S3 相容的物件儲存。
+// key 為以 "/" 分隔的相對路徑，例如 "avatars/1/<version>/256.jpg"。
+type BlobStoreInterface interface {
+	Put(key, contentType string, data []byte) error
+	// Delete 刪除檔案，檔案不存在時不視為錯誤
+	Delete(key string) error
+	// URL 回傳檔案的下載網址：設定簽章時為會過期的簽章網址，否則為靜態網址
+	URL(key string) (string, error)
+}
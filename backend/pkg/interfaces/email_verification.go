@@ -0,0 +1,22 @@
+package interfaces
+
+import (
+	"context"
+	"smart-learning-backend/pkg/models"
+)
+
+// EmailVerificationTokenRepositoryInterface 定義 email 驗證 token 倉庫的介面
+type EmailVerificationTokenRepositoryInterface interface {
+	CreateEmailVerificationToken(ctx context.Context, token *models.EmailVerificationToken) error
+	GetEmailVerificationTokenByHash(ctx context.Context, tokenHash string) (*models.EmailVerificationToken, error)
+	MarkEmailVerificationTokenUsed(ctx context.Context, id int) error
+	InvalidateUserEmailVerificationTokens(ctx context.Context, userID int, emailChange bool) error
+}
+
+// EmailVerificationServiceInterface 定義 email 驗證服務的介面
+type EmailVerificationServiceInterface interface {
+	SendVerification(ctx context.Context, user *models.User) error
+	SendEmailChangeVerification(ctx context.Context, user *models.User, newEmail string) error
+	VerifyEmail(ctx context.Context, token string) error
+	ResendVerification(ctx context.Context, userID int) error
+}
@@ -0,0 +1,17 @@
+package interfaces
+
+import (
+	"context"
+	"smart-learning-backend/pkg/models"
+	"time"
+)
+
+// LoginAttemptRepositoryInterface 定義登入失敗紀錄倉庫的介面，可替換為 Postgres 或記憶體實作
+type LoginAttemptRepositoryInterface interface {
+	GetLoginAttempt(ctx context.Context, key string) (*models.LoginAttempt, error)
+	// RecordFailedLogin 累加失敗次數；上次失敗早於 resetBefore 時重新計算
+	RecordFailedLogin(ctx context.Context, key string, resetBefore time.Time) (*models.LoginAttempt, error)
+	LockLogin(ctx context.Context, key string, until time.Time) error
+	ClearLoginAttempts(ctx context.Context, key string) error
+	DeleteStaleLoginAttempts(ctx context.Context, before time.Time) (int64, error)
+}
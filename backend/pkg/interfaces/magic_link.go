@@ -0,0 +1,20 @@
+package interfaces
+
+import (
+	"context"
+	"smart-learning-backend/pkg/models"
+)
+
+// MagicLinkTokenRepositoryInterface 定義免密碼登入 token 倉庫的介面
+type MagicLinkTokenRepositoryInterface interface {
+	CreateMagicLinkToken(ctx context.Context, token *models.MagicLinkToken) error
+	GetMagicLinkTokenByHash(ctx context.Context, tokenHash string) (*models.MagicLinkToken, error)
+	MarkMagicLinkTokenUsed(ctx context.Context, id int) error
+	InvalidateUserMagicLinkTokens(ctx context.Context, userID int) error
+}
+
+// MagicLinkServiceInterface 定義免密碼登入服務的介面
+type MagicLinkServiceInterface interface {
+	RequestMagicLink(ctx context.Context, req *models.MagicLinkRequest) error
+	LoginWithMagicLink(ctx context.Context, req *models.MagicLinkLoginRequest, client models.ClientInfo) (*models.AuthResponse, error)
+}
@@ -0,0 +1,8 @@
+package interfaces
+
+import "smart-learning-backend/pkg/models"
+
+// MailerInterface 定義寄送郵件的介面，可替換為 SMTP 或本機檔案/日誌實作
+type MailerInterface interface {
+	Send(message *models.EmailMessage) error
+}
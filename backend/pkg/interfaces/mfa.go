@@ -0,0 +1,25 @@
+package interfaces
+
+import (
+	"context"
+	"smart-learning-backend/pkg/models"
+)
+
+// MFARepositoryInterface 定義兩步驟驗證倉庫的介面
+type MFARepositoryInterface interface {
+	GetUserMFA(ctx context.Context, userID int) (*models.UserMFA, error)
+	SaveMFASecret(ctx context.Context, userID int, secret string) error
+	EnableMFA(ctx context.Context, userID int, step int64, recoveryCodeHashes []string) error
+	ConsumeTOTPStep(ctx context.Context, userID int, step int64) (bool, error)
+	ConsumeRecoveryCode(ctx context.Context, userID int, codeHash string) (bool, error)
+	DeleteUserMFA(ctx context.Context, userID int) error
+}
+
+// MFAServiceInterface 定義兩步驟驗證服務的介面
+type MFAServiceInterface interface {
+	Setup(ctx context.Context, userID int) (*models.MFASetupResponse, error)
+	Confirm(ctx context.Context, userID int, code string) ([]string, error)
+	Disable(ctx context.Context, userID int, code string) error
+	VerifyCode(ctx context.Context, userID int, code string) error
+	IsEnabled(ctx context.Context, userID int) (bool, error)
+}
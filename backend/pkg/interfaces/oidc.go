@@ -0,0 +1,38 @@
+package interfaces
+
+import (
+	"context"
+	"smart-learning-backend/pkg/models"
+	"time"
+)
+
+// UserIdentityRepositoryInterface 定義外部身分連結倉庫的介面
+type UserIdentityRepositoryInterface interface {
+	CreateUserIdentity(ctx context.Context, identity *models.UserIdentity) error
+	GetUserIdentity(ctx context.Context, provider, subject string) (*models.UserIdentity, error)
+	TouchUserIdentity(ctx context.Context, id int) error
+	ListUserIdentities(ctx context.Context, userID int) ([]models.UserIdentity, error)
+}
+
+// OIDCAuthStateRepositoryInterface 定義 OIDC 授權狀態倉庫的介面
+type OIDCAuthStateRepositoryInterface interface {
+	CreateOIDCAuthState(ctx context.Context, state *models.OIDCAuthState) error
+	// ConsumeOIDCAuthState 取出並刪除未過期的狀態，確保只能使用一次
+	ConsumeOIDCAuthState(ctx context.Context, stateHash string) (*models.OIDCAuthState, error)
+	DeleteExpiredOIDCAuthStates(ctx context.Context, before time.Time) (int64, error)
+}
+
+// OIDCProviderInterface 定義外部 OIDC 身分提供者的介面
+type OIDCProviderInterface interface {
+	Name() string
+	AuthCodeURL(ctx context.Context, state, nonce, codeChallenge string) (string, error)
+	// Exchange 以授權碼與 PKCE code_verifier 換取並驗證 ID token
+	Exchange(ctx context.Context, code, codeVerifier, nonce string) (*models.ExternalIdentity, error)
+}
+
+// OIDCServiceInterface 定義 OIDC 登入服務的介面
+type OIDCServiceInterface interface {
+	Providers() []string
+	AuthorizationURL(ctx context.Context, provider string) (string, error)
+	Callback(ctx context.Context, provider string, req *models.OIDCCallbackRequest, client models.ClientInfo) (*models.AuthResponse, error)
+}
@@ -0,0 +1,8 @@
+package interfaces
+
+// PasswordPolicyInterface 定義密碼政策的介面。
+// userInputs 為用戶的個人資料（用戶名、email 等），用於拒絕包含或近似個人資料的密碼；
+// 未通過時回傳 *models.PasswordPolicyError。
+type PasswordPolicyInterface interface {
+	Validate(password string, userInputs ...string) error
+}
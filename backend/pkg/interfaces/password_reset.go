@@ -0,0 +1,20 @@
+package interfaces
+
+import (
+	"context"
+	"smart-learning-backend/pkg/models"
+)
+
+// PasswordResetTokenRepositoryInterface 定義密碼重設 token 倉庫的介面
+type PasswordResetTokenRepositoryInterface interface {
+	CreatePasswordResetToken(ctx context.Context, token *models.PasswordResetToken) error
+	GetPasswordResetTokenByHash(ctx context.Context, tokenHash string) (*models.PasswordResetToken, error)
+	MarkPasswordResetTokenUsed(ctx context.Context, id int) error
+	InvalidateUserPasswordResetTokens(ctx context.Context, userID int) error
+}
+
+// PasswordResetServiceInterface 定義密碼重設服務的介面
+type PasswordResetServiceInterface interface {
+	ForgotPassword(ctx context.Context, req *models.ForgotPasswordRequest) error
+	ResetPassword(ctx context.Context, req *models.ResetPasswordRequest, client models.ClientInfo) error
+}
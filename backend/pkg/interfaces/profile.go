@@ -0,0 +1,13 @@
+package interfaces
+
+import (
+	"context"
+	"smart-learning-backend/pkg/models"
+)
+
+// ProfileServiceInterface 定義用戶管理自身資料的服務介面
+type ProfileServiceInterface interface {
+	UpdateProfile(ctx context.Context, userID int, req *models.UpdateProfileRequest) (*models.User, error)
+	ChangePassword(ctx context.Context, userID int, currentSessionID string, req *models.ChangePasswordRequest) error
+	RequestEmailChange(ctx context.Context, userID int, req *models.ChangeEmailRequest) error
+}
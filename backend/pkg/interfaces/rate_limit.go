@@ -0,0 +1,13 @@
+package interfaces
+
+import (
+	"context"
+	"smart-learning-backend/pkg/models"
+	"time"
+)
+
+// RateLimitRepositoryInterface 定義限流計數儲存的介面，預設為記憶體實作，可替換為共用儲存
+type RateLimitRepositoryInterface interface {
+	// Allow 嘗試為 key 消耗一次額度；limit 為每個 window 內允許的請求數
+	Allow(ctx context.Context, key string, limit int, window time.Duration) (*models.RateLimitResult, error)
+}
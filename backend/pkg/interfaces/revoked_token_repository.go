@@ -0,0 +1,14 @@
+package interfaces
+
+import (
+	"context"
+	"time"
+)
+
+// RevokedTokenRepositoryInterface 定義已撤銷 access token（依 jti）的儲存介面。
+// 紀錄只需保留到 token 原本的過期時間，之後即可清除。
+type RevokedTokenRepositoryInterface interface {
+	RevokeToken(ctx context.Context, jti string, userID int, expiresAt time.Time) error
+	IsTokenRevoked(ctx context.Context, jti string) (bool, error)
+	DeleteExpiredTokens(ctx context.Context) (int64, error)
+}
@@ -0,0 +1,8 @@
+package interfaces
+
+import "context"
+
+// RoleRepositoryInterface 定義角色權限倉庫的介面
+type RoleRepositoryInterface interface {
+	GetRolePermissions(ctx context.Context, role string) ([]string, error)
+}
@@ -0,0 +1,16 @@
+package interfaces
+
+import (
+	"context"
+	"smart-learning-backend/pkg/models"
+)
+
+// SessionRepositoryInterface 定義裝置會話倉庫的介面
+type SessionRepositoryInterface interface {
+	CreateSession(ctx context.Context, session *models.Session) error
+	ListActiveSessions(ctx context.Context, userID int) ([]models.Session, error)
+	GetSession(ctx context.Context, userID int, id string) (*models.Session, error)
+	TouchSession(ctx context.Context, id string) (bool, error)
+	RevokeSession(ctx context.Context, userID int, id string) error
+	RevokeOtherSessions(ctx context.Context, userID int, keepID string) (int64, error)
+}
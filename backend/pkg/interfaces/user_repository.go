@@ -1,18 +1,51 @@
 package interfaces
 
-import "smart-learning-backend/pkg/models"
+import (
+	"context"
+	"smart-learning-backend/pkg/models"
+	"time"
+)
 
 // UserRepositoryInterface 定義用戶倉庫的介面
 type UserRepositoryInterface interface {
-	CreateUser(user *models.User) error
-	GetUserByEmail(email string) (*models.User, error)
-	GetUserByID(id int) (*models.User, error)
-	CheckUserExists(email, username string) (bool, error)
+	CreateUser(ctx context.Context, user *models.User) error
+	GetUserByEmail(ctx context.Context, email string) (*models.User, error)
+	GetUserByID(ctx context.Context, id int) (*models.User, error)
+	CheckUserExists(ctx context.Context, email, username string) (bool, error)
+	UpdatePasswordHash(ctx context.Context, id int, passwordHash string) error
+	MarkEmailVerified(ctx context.Context, id int) error
+	// 以 updated_at 做樂觀鎖的更新；資料已被修改時回傳 apperrors.ErrUserUpdateConflict
+	UpdateProfile(ctx context.Context, user *models.User, expectedUpdatedAt time.Time) error
+	UpdatePassword(ctx context.Context, id int, passwordHash string, expectedUpdatedAt time.Time) error
+	UpdateEmail(ctx context.Context, id int, email string, expectedUpdatedAt time.Time) error
+	// 帳號刪除的寬限期：到期後 DeleteScheduledUser 才會刪除，期間可取消
+	ScheduleDeletion(ctx context.Context, id int, at time.Time) error
+	CancelDeletion(ctx context.Context, id int) error
+	ListUsersDueForDeletion(ctx context.Context, before time.Time, limit int) ([]models.User, error)
+	DeleteScheduledUser(ctx context.Context, id int, before time.Time) error
+	// 管理員用戶管理
+	ListUsers(ctx context.Context, filter *models.ListUsersRequest) ([]models.User, int, error)
+	SuspendUser(ctx context.Context, id int, reason string) error
+	UnsuspendUser(ctx context.Context, id int) error
+	UpdateRole(ctx context.Context, id int, role string) error
+}
+
+// LoginCompleterInterface 在身分驗證通過後完成登入：建立會話並簽發 token，
+// 啟用兩步驟驗證的用戶則改回傳 mfa_token
+type LoginCompleterInterface interface {
+	CompleteLogin(ctx context.Context, user *models.User, client models.ClientInfo) (*models.AuthResponse, error)
 }
 
 // AuthServiceInterface 定義認證服務的介面
 type AuthServiceInterface interface {
-	Register(req *models.RegisterRequest) (*models.AuthResponse, error)
-	Login(req *models.LoginRequest) (*models.AuthResponse, error)
-	GetUserByID(id int) (*models.User, error)
+	Register(ctx context.Context, req *models.RegisterRequest, client models.ClientInfo) (*models.AuthResponse, error)
+	Login(ctx context.Context, req *models.LoginRequest, client models.ClientInfo) (*models.AuthResponse, error)
+	VerifyMFA(ctx context.Context, req *models.MFAVerifyRequest, client models.ClientInfo) (*models.AuthResponse, error)
+	Refresh(ctx context.Context, req *models.RefreshRequest, client models.ClientInfo) (*models.AuthResponse, error)
+	Logout(ctx context.Context, userID int, sessionID, jti string, expiresAt time.Time) error
+	GetUserByID(ctx context.Context, id int) (*models.User, error)
+	ListSessions(ctx context.Context, userID int, currentSessionID string) ([]models.Session, error)
+	RevokeSession(ctx context.Context, userID int, sessionID string) error
+	RevokeOtherSessions(ctx context.Context, userID int, currentSessionID string) (int64, error)
+	UnlockAccount(ctx context.Context, userID int) error
 }
\ No newline at end of file
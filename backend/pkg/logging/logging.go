@@ -0,0 +1,45 @@
+// Package logging 以 log/slog 輸出結構化日誌：依設定選擇 JSON 或文字格式與最低層級，
+// 輸出前遮蔽機密與 email，並透過 context 傳遞帶有請求 ID、路由與用戶 ID 的請求 logger。
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+
+	"smart-learning-backend/pkg/config"
+)
+
+// New 依 LOG_LEVEL（debug、info、warn、error）與 LOG_FORMAT（text、json）建立寫入 w 的 logger
+func New(cfg config.LogConfig, w io.Writer) (*slog.Logger, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(cfg.Level)); err != nil {
+		return nil, fmt.Errorf("invalid LOG_LEVEL: %s", cfg.Level)
+	}
+
+	options := &slog.HandlerOptions{Level: level, ReplaceAttr: redactAttr}
+	switch cfg.Format {
+	case "json":
+		return slog.New(slog.NewJSONHandler(w, options)), nil
+	case "text":
+		return slog.New(slog.NewTextHandler(w, options)), nil
+	default:
+		return nil, fmt.Errorf("unsupported LOG_FORMAT: %s", cfg.Format)
+	}
+}
+
+type contextKey struct{}
+
+// WithLogger 回傳帶有 logger 的 context，供服務與倉庫以 FromContext 取用
+func WithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, logger)
+}
+
+// FromContext 回傳 context 中的請求 logger；不在請求中（例如背景工作）時回傳 slog.Default()
+func FromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(contextKey{}).(*slog.Logger); ok {
+		return logger
+	}
+	return slog.Default()
+}
@@ -0,0 +1,160 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"smart-learning-backend/pkg/config"
+)
+
+// newJSONLogger 建立寫入 buf 的 JSON logger
+func newJSONLogger(t *testing.T, level string, buf *bytes.Buffer) *slog.Logger {
+	t.Helper()
+	logger, err := New(config.LogConfig{Level: level, Format: "json"}, buf)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return logger
+}
+
+// decodeEntries 解析每行一筆的 JSON 日誌
+func decodeEntries(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+	entries := []map[string]interface{}{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var entry map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("invalid JSON log line %q: %v", line, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name      string
+		cfg       config.LogConfig
+		wantError bool
+		wantText  string
+	}{
+		{name: "JSON 格式", cfg: config.LogConfig{Level: "info", Format: "json"}, wantText: `"msg":"hello"`},
+		{name: "文字格式", cfg: config.LogConfig{Level: "info", Format: "text"}, wantText: "msg=hello"},
+		{name: "層級不分大小寫", cfg: config.LogConfig{Level: "WARN", Format: "text"}},
+		{name: "無效的層級", cfg: config.LogConfig{Level: "verbose", Format: "json"}, wantError: true},
+		{name: "無效的格式", cfg: config.LogConfig{Level: "info", Format: "xml"}, wantError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger, err := New(tt.cfg, &buf)
+			if (err != nil) != tt.wantError {
+				t.Fatalf("New() error = %v, wantError %v", err, tt.wantError)
+			}
+			if tt.wantError {
+				return
+			}
+
+			logger.Warn("hello")
+			if !strings.Contains(buf.String(), tt.wantText) {
+				t.Errorf("output = %q, want containing %q", buf.String(), tt.wantText)
+			}
+		})
+	}
+}
+
+func TestNew_Level(t *testing.T) {
+	var buf bytes.Buffer
+	logger := newJSONLogger(t, "warn", &buf)
+
+	logger.Debug("debug")
+	logger.Info("info")
+	logger.Warn("warn")
+	logger.Error("error")
+
+	entries := decodeEntries(t, &buf)
+	if len(entries) != 2 || entries[0]["msg"] != "warn" || entries[1]["msg"] != "error" {
+		t.Errorf("entries = %v, want only warn and error", entries)
+	}
+}
+
+func TestNew_RedactsSecretsAndEmails(t *testing.T) {
+	var buf bytes.Buffer
+	logger := newJSONLogger(t, "info", &buf)
+
+	logger.With("refresh_token", "rt-value").Info("登入 alice@example.com 失敗",
+		"password", "hunter2",
+		"Authorization", "Bearer abc",
+		"client_secret", "cs-value",
+		"email", "bob@example.org",
+		"error", errors.New("user carol@example.net not found"),
+		slog.Group("request", slog.String("api_key", "slk_value"), slog.Int("user_id", 7)),
+		"error_code", "INVALID_CREDENTIALS",
+	)
+
+	output := buf.String()
+	for _, leaked := range []string{"rt-value", "hunter2", "Bearer abc", "cs-value", "slk_value", "alice@", "bob@", "carol@"} {
+		if strings.Contains(output, leaked) {
+			t.Errorf("output leaked %q: %s", leaked, output)
+		}
+	}
+
+	entry := decodeEntries(t, &buf)[0]
+	want := map[string]interface{}{
+		"msg":           "登入 a***@example.com 失敗",
+		"password":      redactedValue,
+		"refresh_token": redactedValue,
+		"email":         "b***@example.org",
+		"error":         "user c***@example.net not found",
+		"error_code":    "INVALID_CREDENTIALS",
+	}
+	for key, value := range want {
+		if entry[key] != value {
+			t.Errorf("%s = %v, want %v", key, entry[key], value)
+		}
+	}
+	if group, _ := entry["request"].(map[string]interface{}); group["api_key"] != redactedValue || group["user_id"] != float64(7) {
+		t.Errorf("request group = %v, want redacted api_key and user_id kept", entry["request"])
+	}
+}
+
+func TestRedactEmail(t *testing.T) {
+	tests := []struct {
+		email string
+		want  string
+	}{
+		{"alice@example.com", "a***@example.com"},
+		{"陳小明@example.com", "陳***@example.com"},
+		{"@example.com", redactedValue},
+		{"not-an-email", redactedValue},
+	}
+
+	for _, tt := range tests {
+		if got := RedactEmail(tt.email); got != tt.want {
+			t.Errorf("RedactEmail(%q) = %q, want %q", tt.email, got, tt.want)
+		}
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	if FromContext(context.Background()) != slog.Default() {
+		t.Error("FromContext() without a logger should return slog.Default()")
+	}
+
+	var buf bytes.Buffer
+	logger := newJSONLogger(t, "info", &buf).With("request_id", "req-1")
+	FromContext(WithLogger(context.Background(), logger)).Info("hello")
+
+	if entry := decodeEntries(t, &buf)[0]; entry["request_id"] != "req-1" {
+		t.Errorf("entry = %v, want request_id from context logger", entry)
+	}
+}
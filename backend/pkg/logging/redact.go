@@ -0,0 +1,64 @@
+package logging
+
+import (
+	"log/slog"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// redactedValue 取代機密欄位的值
+const redactedValue = "[REDACTED]"
+
+// sensitiveKeys 為欄位名稱包含即視為機密的字串（不分大小寫），例如 password、refresh_token、client_secret
+var sensitiveKeys = []string{"password", "secret", "token", "authorization", "cookie", "api_key", "signing_key", "recovery_code"}
+
+// emailPattern 比對訊息與欄位值中的 email
+var emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+
+// redactAttr 為 slog.HandlerOptions.ReplaceAttr：遮蔽機密欄位的值，並遮蔽所有字串與錯誤訊息中的 email。
+// 經由標準 log 套件輸出的訊息同樣會經過此函式。
+func redactAttr(_ []string, a slog.Attr) slog.Attr {
+	if isSensitiveKey(a.Key) {
+		return slog.String(a.Key, redactedValue)
+	}
+
+	switch a.Value.Kind() {
+	case slog.KindString:
+		return slog.String(a.Key, RedactEmails(a.Value.String()))
+	case slog.KindAny:
+		if err, ok := a.Value.Any().(error); ok {
+			return slog.String(a.Key, RedactEmails(err.Error()))
+		}
+	}
+	return a
+}
+
+func isSensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, sensitive := range sensitiveKeys {
+		if strings.Contains(key, sensitive) {
+			return true
+		}
+	}
+	return false
+}
+
+// RedactEmails 將文字中的 email 遮蔽為 RedactEmail 的格式
+func RedactEmails(text string) string {
+	if !strings.Contains(text, "@") {
+		return text
+	}
+	return emailPattern.ReplaceAllStringFunc(text, RedactEmail)
+}
+
+// RedactEmail 保留 email 帳號的第一個字元與網域，例如 alice@example.com 遮蔽為 a***@example.com，
+// 足以比對同一網域的問題而不暴露完整地址
+func RedactEmail(email string) string {
+	local, domain, ok := strings.Cut(email, "@")
+	if !ok || local == "" {
+		return redactedValue
+	}
+	first, _ := utf8.DecodeRuneInString(local)
+	return string(first) + "***@" + domain
+}
@@ -0,0 +1,55 @@
+package mailer
+
+import (
+	"os"
+	"path/filepath"
+	"smart-learning-backend/pkg/models"
+	"strings"
+	"testing"
+)
+
+func testMessage() *models.EmailMessage {
+	return &models.EmailMessage{
+		To:      "test@example.com",
+		Subject: "重設密碼",
+		Body:    "請點擊連結重設密碼",
+	}
+}
+
+func TestBuildMessage(t *testing.T) {
+	data := string(buildMessage("noreply@example.com", testMessage()))
+
+	for _, want := range []string{
+		"From: noreply@example.com\r\n",
+		"To: test@example.com\r\n",
+		"Subject: =?UTF-8?b?",
+		"Content-Type: text/plain; charset=UTF-8\r\n",
+		"\r\n\r\n請點擊連結重設密碼",
+	} {
+		if !strings.Contains(data, want) {
+			t.Errorf("buildMessage() missing %q in:\n%s", want, data)
+		}
+	}
+}
+
+func TestFileMailer_Send(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "mail")
+	m := NewFileMailer(dir, "noreply@example.com")
+
+	if err := m.Send(testMessage()); err != nil {
+		t.Fatalf("Send() unexpected error = %v", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir() error = %v", err)
+	}
+	if len(entries) != 1 || filepath.Ext(entries[0].Name()) != ".eml" {
+		t.Fatalf("Send() wrote %v, want one .eml file", entries)
+	}
+
+	data, _ := os.ReadFile(filepath.Join(dir, entries[0].Name()))
+	if !strings.Contains(string(data), "請點擊連結重設密碼") {
+		t.Errorf("written email missing body: %s", data)
+	}
+}
@@ -1,62 +1,195 @@
 package middleware
 
 import (
-	"net/http"
-	"smart-learning-backend/pkg/models"
+	"errors"
+	"smart-learning-backend/pkg/apperrors"
+	"smart-learning-backend/pkg/authtoken"
+	"smart-learning-backend/pkg/i18n"
+	"smart-learning-backend/pkg/interfaces"
 	"smart-learning-backend/pkg/utils"
 
 	"github.com/gin-gonic/gin"
 )
 
-func AuthMiddleware() gin.HandlerFunc {
+// AuthMiddleware 驗證 JWT access token 或個人 API 金鑰（X-API-Key 標頭或 Bearer slk_...），
+// 兩者皆在上下文中設定相同的用戶資訊，驗證失敗時交由 ErrorHandler 回應；以 API 金鑰驗證時另外設定 api_key_id，
+// 管理員代入用戶身分的 token 另外設定 impersonator_id。用戶設定了偏好語系時，回應改用該語系。
+// 代入用戶身分的 token 不屬於任何會話，每次請求另外確認用戶未被停用，停用後立即失效。
+func AuthMiddleware(
+	tokens *authtoken.Issuer,
+	revokedTokenRepo interfaces.RevokedTokenRepositoryInterface,
+	sessionRepo interfaces.SessionRepositoryInterface,
+	userRepo interfaces.UserRepositoryInterface,
+	apiKeyAuth interfaces.APIKeyAuthenticatorInterface,
+) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		if apiKey := apiKeyFromRequest(c); apiKey != "" {
+			authenticateAPIKey(c, apiKeyAuth, apiKey)
+			return
+		}
+
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, models.APIResponse{
-				Success: false,
-				Message: "未授權",
-				Error: &models.APIError{
-					Code:    "MISSING_TOKEN",
-					Message: "Authorization header is required",
-				},
-			})
+			_ = c.Error(apperrors.ErrMissingToken).SetMeta(tr(c, "common.unauthorized"))
 			c.Abort()
 			return
 		}
 
 		tokenString, err := utils.ExtractTokenFromHeader(authHeader)
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, models.APIResponse{
-				Success: false,
-				Message: "未授權",
-				Error: &models.APIError{
-					Code:    "INVALID_TOKEN_FORMAT",
-					Message: "Invalid authorization header format",
-				},
-			})
+			_ = c.Error(apperrors.ErrInvalidTokenFormat).SetMeta(tr(c, "common.unauthorized"))
 			c.Abort()
 			return
 		}
 
-		claims, err := utils.ValidateJWT(tokenString)
+		claims, err := tokens.ValidateJWT(tokenString)
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, models.APIResponse{
-				Success: false,
-				Message: "未授權",
-				Error: &models.APIError{
-					Code:    "INVALID_TOKEN",
-					Message: "Token 無效或已過期",
-				},
-			})
+			_ = c.Error(apperrors.ErrInvalidToken).SetMeta(tr(c, "common.unauthorized"))
 			c.Abort()
 			return
 		}
 
+		// 檢查 token 是否已於登出時撤銷
+		if claims.ID != "" {
+			revoked, err := revokedTokenRepo.IsTokenRevoked(c.Request.Context(), claims.ID)
+			if err != nil {
+				_ = c.Error(err).SetMeta(tr(c, "common.server_error"))
+				c.Abort()
+				return
+			}
+			if revoked {
+				_ = c.Error(apperrors.ErrTokenRevoked).SetMeta(tr(c, "common.unauthorized"))
+				c.Abort()
+				return
+			}
+		}
+
+		// 檢查裝置會話是否仍有效，並更新最後活動時間
+		if claims.SessionID != "" {
+			active, err := sessionRepo.TouchSession(c.Request.Context(), claims.SessionID)
+			if err != nil {
+				_ = c.Error(err).SetMeta(tr(c, "common.server_error"))
+				c.Abort()
+				return
+			}
+			if !active {
+				_ = c.Error(apperrors.ErrSessionRevoked).SetMeta(tr(c, "common.unauthorized"))
+				c.Abort()
+				return
+			}
+		}
+
+		if claims.ImpersonatorID != 0 && !impersonatedUserActive(c, userRepo, claims.UserID) {
+			return
+		}
+
 		// 將用戶資訊存儲在上下文中
 		c.Set("user_id", claims.UserID)
 		c.Set("email", claims.Email)
 		c.Set("username", claims.Username)
+		c.Set("jti", claims.ID)
+		c.Set("session_id", claims.SessionID)
+		c.Set("role", claims.Role)
+		c.Set("permissions", claims.Permissions)
+		if claims.ExpiresAt != nil {
+			c.Set("token_expires_at", claims.ExpiresAt.Time)
+		}
+		if claims.ImpersonatorID != 0 {
+			c.Set("impersonator_id", claims.ImpersonatorID)
+		}
+		if i18n.IsSupported(claims.Locale) {
+			setLocale(c, claims.Locale)
+		}
+		if claims.ImpersonatorID != 0 {
+			withLoggerAttrs(c, "user_id", claims.UserID, "impersonator_id", claims.ImpersonatorID)
+		} else {
+			withLoggerAttrs(c, "user_id", claims.UserID)
+		}
+
+		c.Next()
+	}
+}
+
+// impersonatedUserActive 確認代入身分的用戶仍存在且未被停用；否則交由 ErrorHandler 回應並回傳 false
+func impersonatedUserActive(c *gin.Context, userRepo interfaces.UserRepositoryInterface, userID int) bool {
+	user, err := userRepo.GetUserByID(c.Request.Context(), userID)
+	switch {
+	case errors.Is(err, apperrors.ErrUserNotFound):
+		_ = c.Error(apperrors.ErrAuthenticatedUserNotFound.Wrap(err)).SetMeta(tr(c, "common.unauthorized"))
+	case err != nil:
+		_ = c.Error(err).SetMeta(tr(c, "common.server_error"))
+	case user.SuspendedAt != nil:
+		_ = c.Error(apperrors.ErrAccountSuspended).SetMeta(tr(c, "common.forbidden"))
+	default:
+		return true
+	}
+	c.Abort()
+	return false
+}
+
+// authenticateAPIKey 以 API 金鑰驗證請求，權限為金鑰 scope 與用戶角色權限的交集
+func authenticateAPIKey(c *gin.Context, apiKeyAuth interfaces.APIKeyAuthenticatorInterface, apiKey string) {
+	principal, err := apiKeyAuth.AuthenticateAPIKey(c.Request.Context(), apiKey)
+	if err != nil {
+		// 由 ErrorHandler 依錯誤代碼回應
+		message := tr(c, "common.server_error")
+		if errors.Is(err, apperrors.ErrInvalidAPIKey) {
+			message = tr(c, "common.unauthorized")
+		}
+		_ = c.Error(err).SetMeta(message)
+		c.Abort()
+		return
+	}
+
+	c.Set("user_id", principal.User.ID)
+	c.Set("email", principal.User.Email)
+	c.Set("username", principal.User.Username)
+	c.Set("role", principal.User.Role)
+	c.Set("permissions", principal.Permissions)
+	c.Set("api_key_id", principal.KeyID)
+	if principal.User.Locale != nil && i18n.IsSupported(*principal.User.Locale) {
+		setLocale(c, *principal.User.Locale)
+	}
+	withLoggerAttrs(c, "user_id", principal.User.ID, "api_key_id", principal.KeyID)
+
+	c.Next()
+}
+
+// apiKeyFromRequest 取出請求中的 API 金鑰：優先使用 X-API-Key 標頭，其次為 Bearer slk_... 格式的 Authorization 標頭
+func apiKeyFromRequest(c *gin.Context) string {
+	if apiKey := c.GetHeader("X-API-Key"); apiKey != "" {
+		return apiKey
+	}
+	if token, err := utils.ExtractTokenFromHeader(c.GetHeader("Authorization")); err == nil && utils.IsAPIKey(token) {
+		return token
+	}
+	return ""
+}
+
+// DenyAPIKey 拒絕以 API 金鑰驗證的請求，用於帳號安全相關的端點（管理金鑰、會話與兩步驟驗證），
+// 需接在 AuthMiddleware 之後使用。
+func DenyAPIKey() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, viaAPIKey := c.Get("api_key_id"); viaAPIKey {
+			_ = c.Error(apperrors.ErrAPIKeyNotAllowed).SetMeta(tr(c, "common.forbidden"))
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// DenyImpersonation 拒絕管理員代入用戶身分的請求，用於變更帳號資料與安全設定的端點，
+// 需接在 AuthMiddleware 之後使用。
+func DenyImpersonation() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, impersonated := c.Get("impersonator_id"); impersonated {
+			_ = c.Error(apperrors.ErrImpersonationNotAllowed).SetMeta(tr(c, "common.forbidden"))
+			c.Abort()
+			return
+		}
 
 		c.Next()
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,211 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"smart-learning-backend/pkg/apperrors"
+	"smart-learning-backend/pkg/authtoken/authtokentest"
+	"smart-learning-backend/pkg/interfaces"
+	"smart-learning-backend/pkg/models"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// fakeRevokedTokenRepository 預設視所有 token 為未撤銷
+type fakeRevokedTokenRepository struct {
+	interfaces.RevokedTokenRepositoryInterface
+	revoked bool
+	err     error
+}
+
+func (r *fakeRevokedTokenRepository) IsTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	return r.revoked, r.err
+}
+
+// fakeSessionRepository 只實作 TouchSession
+type fakeSessionRepository struct {
+	interfaces.SessionRepositoryInterface
+	active bool
+	err    error
+}
+
+func (r *fakeSessionRepository) TouchSession(ctx context.Context, id string) (bool, error) {
+	return r.active, r.err
+}
+
+// 驗證失敗皆由 ErrorHandler 以統一格式回應
+func TestAuthMiddleware_Errors(t *testing.T) {
+	tokens := authtokentest.NewIssuer()
+	token, err := tokens.GenerateJWT(7, "student@example.com", "student", "session-1", "student", []string{"learning:read"}, "")
+	if err != nil {
+		t.Fatalf("GenerateJWT() error = %v", err)
+	}
+
+	tests := []struct {
+		name           string
+		header         string
+		revoked        *fakeRevokedTokenRepository
+		sessions       *fakeSessionRepository
+		expectedStatus int
+		expectedCode   string
+	}{
+		{name: "缺少 Authorization", expectedStatus: http.StatusUnauthorized, expectedCode: "MISSING_TOKEN"},
+		{name: "格式不正確", header: "Token " + token, expectedStatus: http.StatusUnauthorized, expectedCode: "INVALID_TOKEN_FORMAT"},
+		{name: "token 無效", header: "Bearer invalid.token.value", expectedStatus: http.StatusUnauthorized, expectedCode: "INVALID_TOKEN"},
+		{
+			name:           "token 已撤銷",
+			header:         "Bearer " + token,
+			revoked:        &fakeRevokedTokenRepository{revoked: true},
+			expectedStatus: http.StatusUnauthorized,
+			expectedCode:   "TOKEN_REVOKED",
+		},
+		{
+			name:           "撤銷清單讀取失敗",
+			header:         "Bearer " + token,
+			revoked:        &fakeRevokedTokenRepository{err: errors.New("connection refused")},
+			expectedStatus: http.StatusInternalServerError,
+			expectedCode:   "INTERNAL_SERVER_ERROR",
+		},
+		{
+			name:           "會話已結束",
+			header:         "Bearer " + token,
+			sessions:       &fakeSessionRepository{},
+			expectedStatus: http.StatusUnauthorized,
+			expectedCode:   "SESSION_REVOKED",
+		},
+		{
+			name:           "會話讀取失敗",
+			header:         "Bearer " + token,
+			sessions:       &fakeSessionRepository{err: errors.New("connection refused")},
+			expectedStatus: http.StatusInternalServerError,
+			expectedCode:   "INTERNAL_SERVER_ERROR",
+		},
+		{name: "驗證通過", header: "Bearer " + token, expectedStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.revoked == nil {
+				tt.revoked = &fakeRevokedTokenRepository{}
+			}
+			if tt.sessions == nil {
+				tt.sessions = &fakeSessionRepository{active: true}
+			}
+			r := setupRouter(AuthMiddleware(tokens, tt.revoked, tt.sessions, nil, nil))
+
+			req, _ := http.NewRequest(http.MethodGet, "/test", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if tt.expectedCode != "" {
+				assertErrorCode(t, w, tt.expectedCode, "")
+			}
+		})
+	}
+}
+
+func TestDenyAPIKeyAndImpersonation(t *testing.T) {
+	tests := []struct {
+		name           string
+		key            string
+		deny           gin.HandlerFunc
+		expectedStatus int
+		expectedCode   string
+	}{
+		{name: "API 金鑰", key: "api_key_id", deny: DenyAPIKey(), expectedStatus: http.StatusForbidden, expectedCode: "API_KEY_NOT_ALLOWED"},
+		{name: "代入用戶身分", key: "impersonator_id", deny: DenyImpersonation(), expectedStatus: http.StatusForbidden, expectedCode: "IMPERSONATION_NOT_ALLOWED"},
+		{name: "以帳號登入", deny: DenyAPIKey(), expectedStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setKey := func(c *gin.Context) {
+				if tt.key != "" {
+					c.Set(tt.key, 1)
+				}
+			}
+			r := setupRouter(setKey, tt.deny)
+
+			req, _ := http.NewRequest(http.MethodGet, "/test", nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if tt.expectedCode != "" {
+				assertErrorCode(t, w, tt.expectedCode, "")
+			}
+		})
+	}
+}
+
+// 代入用戶身分的 token 沒有會話，用戶被停用後需立即失效
+func TestAuthMiddleware_Impersonation(t *testing.T) {
+	suspendedAt := time.Now()
+
+	tests := []struct {
+		name           string
+		repo           *fakeUserRepository
+		expectedStatus int
+		expectedCode   string
+	}{
+		{
+			name:           "用戶未停用",
+			repo:           &fakeUserRepository{user: &models.User{ID: 7}},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "用戶已停用",
+			repo:           &fakeUserRepository{user: &models.User{ID: 7, SuspendedAt: &suspendedAt}},
+			expectedStatus: http.StatusForbidden,
+			expectedCode:   "ACCOUNT_SUSPENDED",
+		},
+		{
+			name:           "用戶已刪除",
+			repo:           &fakeUserRepository{err: apperrors.ErrUserNotFound},
+			expectedStatus: http.StatusUnauthorized,
+			expectedCode:   "USER_NOT_FOUND",
+		},
+		{
+			name:           "資料庫錯誤",
+			repo:           &fakeUserRepository{err: errors.New("connection refused")},
+			expectedStatus: http.StatusInternalServerError,
+			expectedCode:   "INTERNAL_SERVER_ERROR",
+		},
+	}
+
+	tokens := authtokentest.NewIssuer()
+	token, err := tokens.GenerateImpersonationJWT(7, "student@example.com", "student", "student", []string{"learning:read"}, 1)
+	if err != nil {
+		t.Fatalf("GenerateImpersonationJWT() error = %v", err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := setupRouter(AuthMiddleware(tokens, &fakeRevokedTokenRepository{}, nil, tt.repo, nil))
+
+			req, _ := http.NewRequest(http.MethodGet, "/test", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if tt.expectedCode != "" {
+				assertErrorCode(t, w, tt.expectedCode, "")
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"smart-learning-backend/pkg/apperrors"
+	"smart-learning-backend/pkg/i18n"
+	"smart-learning-backend/pkg/logging"
+	"smart-learning-backend/pkg/models"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// StatusClientClosedRequest 為用戶端在回應前中斷連線時記錄的狀態碼（沿用 nginx 的 499）
+const StatusClientClosedRequest = 499
+
+// ErrorHandler 將處理器以 c.Error 記錄的錯誤轉換為 API 回應，處理器已寫出回應時不再處理。
+//
+// 錯誤的 Meta 為字串時作為回應的 message；欄位驗證錯誤的 message 一律為「驗證失敗」。
+// 錯誤訊息依請求的語系（見 Locale）回應。
+// 不是 apperrors 的錯誤視為伺服器錯誤，原始錯誤只記錄在日誌中；錯誤代碼設定為 error_code 供 RequestLogger 記錄；
+// 請求已超過處理時限（見 Timeout）時回應逾時，用戶端已中斷連線時不再回應。
+func ErrorHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+
+		locale := c.GetString("locale")
+		last := c.Errors.Last()
+		appErr, ok := toAppError(last.Err)
+		// 由處理時限或用戶端中斷造成的錯誤，即使已包成 apperrors（例如外部服務錯誤）也依 context 回應
+		ctxErr := c.Request.Context().Err()
+		if !ok || (ctxErr != nil && errors.Is(last.Err, ctxErr)) {
+			logger := logging.FromContext(c.Request.Context())
+			switch {
+			case errors.Is(ctxErr, context.DeadlineExceeded):
+				logger.Warn("請求處理逾時", "error", last.Err)
+				appErr = apperrors.ErrRequestTimeout
+			case errors.Is(ctxErr, context.Canceled):
+				logger.Info("用戶端已中斷連線", "error", last.Err)
+				c.Status(StatusClientClosedRequest)
+				return
+			default:
+				logger.Error("處理請求時發生錯誤", "error", last.Err)
+				appErr = apperrors.ErrInternal
+			}
+		}
+		c.Set("error_code", appErr.Code)
+
+		if appErr.RetryAfter > 0 {
+			retryAfter := int(math.Ceil(appErr.RetryAfter.Seconds()))
+			c.Header("Retry-After", strconv.Itoa(retryAfter))
+			appErr = appErr.WithArgs(retryAfter)
+		}
+
+		if appErr.Field != "" {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Message: i18n.T(locale, "errors.validation_failed"),
+				Errors: map[string][]string{
+					appErr.Field: {appErr.Message(locale)},
+				},
+			})
+			return
+		}
+
+		message, _ := last.Meta.(string)
+		if message == "" {
+			message = appErr.Message(locale)
+		}
+		c.JSON(appErr.Status, models.APIResponse{
+			Success: false,
+			Message: message,
+			Error: &models.APIError{
+				Code:    appErr.Code,
+				Message: appErr.Message(locale),
+			},
+		})
+	}
+}
+
+// toAppError 取得錯誤鏈中的 *apperrors.Error；登入鎖定與限流錯誤帶有等待時間，轉換為對應的錯誤
+func toAppError(err error) (*apperrors.Error, bool) {
+	var lockedErr *models.AccountLockedError
+	if errors.As(err, &lockedErr) {
+		return apperrors.ErrAccountLocked.WithRetryAfter(lockedErr.RetryAfter), true
+	}
+
+	var limitedErr *models.RateLimitedError
+	if errors.As(err, &limitedErr) {
+		return apperrors.ErrRateLimitExceeded.WithRetryAfter(limitedErr.RetryAfter), true
+	}
+
+	return apperrors.As(err)
+}
@@ -0,0 +1,100 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"smart-learning-backend/pkg/apperrors"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 處理時限已過或用戶端中斷時，由 context 造成的錯誤不以原本的錯誤代碼回應
+func TestErrorHandler_ContextErrors(t *testing.T) {
+	tests := []struct {
+		name           string
+		cancel         bool
+		err            func(ctxErr error) error
+		expectedStatus int
+		expectedCode   string
+	}{
+		{
+			name:           "逾時的資料庫查詢",
+			err:            func(ctxErr error) error { return fmt.Errorf("failed to get user: %w", ctxErr) },
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedCode:   "REQUEST_TIMEOUT",
+		},
+		{
+			name:           "包成 apperrors 的逾時",
+			err:            func(ctxErr error) error { return apperrors.ErrOIDCProviderUnavailable.Wrap(ctxErr) },
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedCode:   "REQUEST_TIMEOUT",
+		},
+		{
+			name:           "與逾時無關的錯誤",
+			err:            func(ctxErr error) error { return apperrors.ErrUserNotFound },
+			expectedStatus: http.StatusNotFound,
+			expectedCode:   "USER_NOT_FOUND",
+		},
+		{
+			name:           "用戶端中斷連線",
+			cancel:         true,
+			err:            func(ctxErr error) error { return apperrors.ErrOIDCProviderUnavailable.Wrap(ctxErr) },
+			expectedStatus: StatusClientClosedRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fail := func(c *gin.Context) {
+				ctx, cancel := context.WithTimeout(c.Request.Context(), time.Nanosecond)
+				if tt.cancel {
+					ctx, cancel = context.WithCancel(c.Request.Context())
+					cancel()
+				}
+				defer cancel()
+				<-ctx.Done()
+
+				c.Request = c.Request.WithContext(ctx)
+				_ = c.Error(tt.err(ctx.Err()))
+				c.Abort()
+			}
+			r := setupRouter(fail)
+
+			req, _ := http.NewRequest(http.MethodGet, "/test", nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if tt.expectedCode != "" {
+				assertErrorCode(t, w, tt.expectedCode, "")
+			}
+			if tt.cancel && w.Body.Len() != 0 {
+				t.Errorf("Expected empty body, got %s", w.Body.String())
+			}
+		})
+	}
+}
+
+// 錯誤鏈中沒有 context 錯誤時維持原本的處理
+func TestErrorHandler_InternalError(t *testing.T) {
+	r := setupRouter(func(c *gin.Context) {
+		_ = c.Error(errors.New("connection refused"))
+		c.Abort()
+	})
+
+	req, _ := http.NewRequest(http.MethodGet, "/test", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("Expected status 500, got %d", w.Code)
+	}
+	assertErrorCode(t, w, "INTERNAL_SERVER_ERROR", "")
+}
@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"smart-learning-backend/pkg/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Locale 依 Accept-Language 標頭決定回應語系，存放在上下文的 locale 中並回應 Content-Language 標頭。
+// 已登入且設定偏好語系的用戶由 AuthMiddleware 改用其偏好語系。
+func Locale() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Vary", "Accept-Language")
+		setLocale(c, i18n.Negotiate(c.GetHeader("Accept-Language")))
+
+		c.Next()
+	}
+}
+
+// setLocale 設定請求的回應語系
+func setLocale(c *gin.Context, locale string) {
+	c.Set("locale", locale)
+	c.Header("Content-Language", locale)
+}
+
+// tr 取得請求語系的訊息；未經過 Locale 的請求使用預設語系
+func tr(c *gin.Context, key string, args ...interface{}) string {
+	return i18n.T(c.GetString("locale"), key, args...)
+}
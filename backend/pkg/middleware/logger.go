@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"smart-learning-backend/pkg/logging"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestLogger 取代 gin 預設的存取日誌，請求結束時以請求 logger（見 RequestID）記錄狀態碼與耗時；
+// 4xx 記錄為 warn、5xx 為 error，錯誤回應另外記錄 error_code（見 ErrorHandler）。
+// 不記錄查詢字串，避免驗證信箱等連結中的 token 寫入日誌。
+func RequestLogger() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		status := c.Writer.Status()
+		level := slog.LevelInfo
+		switch {
+		case status >= 500:
+			level = slog.LevelError
+		case status >= 400:
+			level = slog.LevelWarn
+		}
+
+		attrs := []slog.Attr{
+			slog.Int("status", status),
+			slog.String("path", c.Request.URL.Path),
+			slog.Duration("latency", time.Since(start)),
+			slog.String("client_ip", c.ClientIP()),
+			slog.Int("bytes", c.Writer.Size()),
+		}
+		if code := c.GetString("error_code"); code != "" {
+			attrs = append(attrs, slog.String("error_code", code))
+		}
+		logging.FromContext(c.Request.Context()).LogAttrs(c.Request.Context(), level, "請求完成", attrs...)
+	}
+}
+
+// Recovery 取代 gin 預設的 panic 復原：以請求 logger 記錄 panic 與堆疊，並交由 ErrorHandler 回應伺服器錯誤。
+// 以 r.Use 安裝在 ErrorHandler 之後，ErrorHandler 才能在 panic 復原後處理回應。
+func Recovery() gin.HandlerFunc {
+	return gin.CustomRecoveryWithWriter(nil, func(c *gin.Context, recovered any) {
+		logging.FromContext(c.Request.Context()).Error("處理請求時發生 panic", "panic", fmt.Sprint(recovered), "stack", string(debug.Stack()))
+		_ = c.Error(fmt.Errorf("panic: %v", recovered))
+		c.Abort()
+	})
+}
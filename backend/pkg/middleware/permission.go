@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"smart-learning-backend/pkg/apperrors"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequirePermission 要求目前用戶擁有所有指定的權限，需接在 AuthMiddleware 之後使用。
+// 權限取自 access token 的 claims，不另外查詢資料庫；錯誤交由 ErrorHandler 回應。
+func RequirePermission(permissions ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, exists := c.Get("user_id"); !exists {
+			_ = c.Error(apperrors.ErrUnauthorized).SetMeta(tr(c, "common.unauthorized"))
+			c.Abort()
+			return
+		}
+
+		granted := make(map[string]bool)
+		if values, ok := c.Get("permissions"); ok {
+			if list, ok := values.([]string); ok {
+				for _, permission := range list {
+					granted[permission] = true
+				}
+			}
+		}
+
+		missing := make([]string, 0)
+		for _, permission := range permissions {
+			if !granted[permission] {
+				missing = append(missing, permission)
+			}
+		}
+
+		if len(missing) > 0 {
+			_ = c.Error(apperrors.ErrPermissionDenied.WithArgs(strings.Join(missing, ", "))).SetMeta(tr(c, "common.forbidden"))
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,150 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"smart-learning-backend/pkg/apperrors"
+	"smart-learning-backend/pkg/models"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// fakeAPIKeyAuthenticator 接受 validKey，並以 permissions 作為金鑰的有效權限
+type fakeAPIKeyAuthenticator struct {
+	validKey    string
+	permissions []string
+}
+
+func (a *fakeAPIKeyAuthenticator) AuthenticateAPIKey(ctx context.Context, key string) (*models.APIKeyPrincipal, error) {
+	if key != a.validKey {
+		return nil, apperrors.ErrInvalidAPIKey
+	}
+	return &models.APIKeyPrincipal{
+		KeyID:       3,
+		User:        &models.User{ID: 7, Email: "student@example.com", Username: "student", Role: "student"},
+		Permissions: a.permissions,
+	}, nil
+}
+
+// assertErrorCode 確認回應為 ErrorHandler 產生的錯誤格式
+func assertErrorCode(t *testing.T, w *httptest.ResponseRecorder, expectedCode, expectedMessage string) {
+	t.Helper()
+
+	var response models.APIResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if response.Success || response.Error == nil || response.Error.Code != expectedCode {
+		t.Fatalf("Expected error code %s, got %+v", expectedCode, response)
+	}
+	if expectedMessage != "" && response.Error.Message != expectedMessage {
+		t.Errorf("Expected error message %q, got %q", expectedMessage, response.Error.Message)
+	}
+}
+
+func TestRequirePermission(t *testing.T) {
+	tests := []struct {
+		name            string
+		userID          interface{}
+		permissions     interface{}
+		expectedStatus  int
+		expectedCode    string
+		expectedMessage string
+	}{
+		{
+			name:           "缺少用戶資訊",
+			expectedStatus: http.StatusUnauthorized,
+			expectedCode:   "UNAUTHORIZED",
+		},
+		{
+			name:            "token 沒有權限",
+			userID:          7,
+			expectedStatus:  http.StatusForbidden,
+			expectedCode:    "PERMISSION_DENIED",
+			expectedMessage: "缺少權限：users:manage, audit:read",
+		},
+		{
+			name:            "缺少部分權限",
+			userID:          7,
+			permissions:     []string{"users:manage", "learning:read"},
+			expectedStatus:  http.StatusForbidden,
+			expectedCode:    "PERMISSION_DENIED",
+			expectedMessage: "缺少權限：audit:read",
+		},
+		{
+			name:           "權限格式不正確",
+			userID:         7,
+			permissions:    "users:manage,audit:read",
+			expectedStatus: http.StatusForbidden,
+			expectedCode:   "PERMISSION_DENIED",
+		},
+		{
+			name:           "擁有所有權限",
+			userID:         7,
+			permissions:    []string{"audit:read", "users:manage"},
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setClaims := func(c *gin.Context) {
+				if tt.userID != nil {
+					c.Set("user_id", tt.userID)
+				}
+				if tt.permissions != nil {
+					c.Set("permissions", tt.permissions)
+				}
+			}
+			r := setupRouter(setClaims, RequirePermission("users:manage", "audit:read"))
+
+			req, _ := http.NewRequest(http.MethodGet, "/test", nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if tt.expectedCode != "" {
+				assertErrorCode(t, w, tt.expectedCode, tt.expectedMessage)
+			}
+		})
+	}
+}
+
+// 以 API 金鑰驗證時，權限為 AuthMiddleware 設定的金鑰有效權限
+func TestRequirePermission_APIKey(t *testing.T) {
+	const apiKey = "slk_testkey"
+
+	tests := []struct {
+		name           string
+		permissions    []string
+		expectedStatus int
+		expectedCode   string
+	}{
+		{name: "金鑰擁有權限", permissions: []string{"learning:read", "users:manage"}, expectedStatus: http.StatusOK},
+		{name: "金鑰缺少權限", permissions: []string{"learning:read"}, expectedStatus: http.StatusForbidden, expectedCode: "PERMISSION_DENIED"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auth := &fakeAPIKeyAuthenticator{validKey: apiKey, permissions: tt.permissions}
+			r := setupRouter(AuthMiddleware(nil, nil, nil, nil, auth), RequirePermission("users:manage"))
+
+			req, _ := http.NewRequest(http.MethodGet, "/test", nil)
+			req.Header.Set("X-API-Key", apiKey)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if tt.expectedCode != "" {
+				assertErrorCode(t, w, tt.expectedCode, "")
+			}
+		})
+	}
+}
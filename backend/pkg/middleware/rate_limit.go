@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"fmt"
+	"math"
+	"smart-learning-backend/pkg/apperrors"
+	"smart-learning-backend/pkg/interfaces"
+	"smart-learning-backend/pkg/logging"
+	"smart-learning-backend/pkg/utils"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimitKeyFunc 由請求取得限流的識別鍵
+type RateLimitKeyFunc func(c *gin.Context) string
+
+// RateLimitPolicy 定義一組路由的限流規則：每個 Window 內同一個 Key 最多 Limit 次請求
+type RateLimitPolicy struct {
+	Name   string
+	Limit  int
+	Window time.Duration
+	Key    RateLimitKeyFunc
+}
+
+// KeyByIP 以用戶端 IP 作為限流鍵（依 TRUSTED_PROXIES 設定解析）
+func KeyByIP(c *gin.Context) string {
+	return "ip:" + c.ClientIP()
+}
+
+// KeyByUser 以 AuthMiddleware 設定的用戶 ID 作為限流鍵，未登入時退回使用 IP
+func KeyByUser(c *gin.Context) string {
+	if userID, exists := c.Get("user_id"); exists {
+		return fmt.Sprintf("user:%v", userID)
+	}
+	return KeyByIP(c)
+}
+
+// KeyByAPIKey 以 API key 的雜湊作為限流鍵，未提供 API key 時退回使用用戶 ID 或 IP
+func KeyByAPIKey(c *gin.Context) string {
+	if apiKey := apiKeyFromRequest(c); apiKey != "" {
+		return "apikey:" + utils.HashToken(apiKey)
+	}
+	return KeyByUser(c)
+}
+
+// RateLimitMiddleware 依 policy 限制請求頻率，並回傳 X-RateLimit-* 標頭。
+// 超過限制時交由 ErrorHandler 回應 429 與 Retry-After；儲存發生錯誤時放行請求，避免限流故障影響服務。
+func RateLimitMiddleware(store interfaces.RateLimitRepositoryInterface, policy RateLimitPolicy) gin.HandlerFunc {
+	keyFunc := policy.Key
+	if keyFunc == nil {
+		keyFunc = KeyByIP
+	}
+
+	return func(c *gin.Context) {
+		result, err := store.Allow(c.Request.Context(), policy.Name+":"+keyFunc(c), policy.Limit, policy.Window)
+		if err != nil {
+			logging.FromContext(c.Request.Context()).Error("限流檢查失敗", "policy", policy.Name, "error", err)
+			c.Next()
+			return
+		}
+
+		c.Header("X-RateLimit-Limit", strconv.Itoa(result.Limit))
+		c.Header("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.Header("X-RateLimit-Reset", strconv.Itoa(ceilSeconds(result.ResetAfter)))
+
+		if !result.Allowed {
+			_ = c.Error(apperrors.ErrRateLimitExceeded.WithRetryAfter(result.RetryAfter))
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"log/slog"
+	"regexp"
+	"smart-learning-backend/pkg/logging"
+	"smart-learning-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestIDHeader 為傳遞請求 ID 的標頭
+const RequestIDHeader = "X-Request-ID"
+
+// requestIDPattern 限制沿用的請求 ID 格式，避免任意內容寫入日誌與稽核紀錄
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// RequestID 為每個請求設定 request_id：沿用上游代理傳入的 X-Request-ID，
+// 沒有或格式不正確時產生新的 ID，並在回應標頭中回傳。
+// 請求的 context 另外帶有以 logger 建立、附加 request_id、method 與 route 的請求 logger
+// （見 logging.FromContext），AuthMiddleware 驗證通過後再附加 user_id。
+func RequestID(logger *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if !requestIDPattern.MatchString(requestID) {
+			// 無法產生時留空，不影響請求本身
+			requestID, _ = utils.GenerateRandomID()
+		}
+
+		c.Set("request_id", requestID)
+		c.Header(RequestIDHeader, requestID)
+
+		requestLogger := logger.With("request_id", requestID, "method", c.Request.Method, "route", c.FullPath())
+		c.Request = c.Request.WithContext(logging.WithLogger(c.Request.Context(), requestLogger))
+		c.Next()
+	}
+}
+
+// withLoggerAttrs 為請求 logger 附加欄位，之後的處理器與服務記錄的日誌皆帶有這些欄位
+func withLoggerAttrs(c *gin.Context, args ...any) {
+	ctx := c.Request.Context()
+	c.Request = c.Request.WithContext(logging.WithLogger(ctx, logging.FromContext(ctx).With(args...)))
+}
@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"context"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestTimeouts 設定請求的處理時限。Routes 以「方法 路徑」（例如 "GET /api/v1/admin/audit-logs/export"）
+// 指定個別路由的時限，路徑為路由定義的樣式；其餘路由使用 Default。時限為 0 表示不限制。
+type RequestTimeouts struct {
+	Default time.Duration
+	Routes  map[string]time.Duration
+}
+
+// For 回傳路由的處理時限
+func (t RequestTimeouts) For(method, path string) time.Duration {
+	if timeout, ok := t.Routes[method+" "+path]; ok {
+		return timeout
+	}
+	return t.Default
+}
+
+// Timeout 依路由為請求的 context 設定處理時限。處理器將 c.Request.Context() 傳給服務與倉庫，
+// 時限到期或用戶端中斷連線時，進行中的資料庫查詢隨之取消；逾時的回應由 ErrorHandler 處理。
+// 以 r.Use 安裝在 ErrorHandler 之前：中介軟體執行時路由已比對完成，
+// 且 ErrorHandler 須在時限的 context 取消前判斷請求是否逾時。
+func Timeout(timeouts RequestTimeouts) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		timeout := timeouts.For(c.Request.Method, c.FullPath())
+		if timeout <= 0 {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"errors"
+	"smart-learning-backend/pkg/apperrors"
+	"smart-learning-backend/pkg/interfaces"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireVerifiedEmail 要求目前用戶已完成 email 驗證，需接在 AuthMiddleware 之後使用。
+// 每次請求皆查詢資料庫，驗證完成後不需重新登入即可生效；錯誤交由 ErrorHandler 回應。
+func RequireVerifiedEmail(userRepo interfaces.UserRepositoryInterface) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := c.Get("user_id")
+		if !exists {
+			_ = c.Error(apperrors.ErrUnauthorized).SetMeta(tr(c, "common.unauthorized"))
+			c.Abort()
+			return
+		}
+
+		user, err := userRepo.GetUserByID(c.Request.Context(), userID.(int))
+		if err != nil {
+			// 只有用戶已不存在時視為認證失敗，資料庫錯誤或逾時由 ErrorHandler 回應伺服器錯誤
+			if errors.Is(err, apperrors.ErrUserNotFound) {
+				_ = c.Error(apperrors.ErrAuthenticatedUserNotFound.Wrap(err)).SetMeta(tr(c, "common.unauthorized"))
+			} else {
+				_ = c.Error(err).SetMeta(tr(c, "common.server_error"))
+			}
+			c.Abort()
+			return
+		}
+
+		if user.EmailVerifiedAt == nil {
+			_ = c.Error(apperrors.ErrEmailNotVerified).SetMeta(tr(c, "common.forbidden"))
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,98 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"smart-learning-backend/pkg/apperrors"
+	"smart-learning-backend/pkg/interfaces"
+	"smart-learning-backend/pkg/models"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// fakeUserRepository 只實作 GetUserByID，其他方法未被 RequireVerifiedEmail 使用
+type fakeUserRepository struct {
+	interfaces.UserRepositoryInterface
+	user *models.User
+	err  error
+}
+
+func (r *fakeUserRepository) GetUserByID(ctx context.Context, id int) (*models.User, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	return r.user, nil
+}
+
+func TestRequireVerifiedEmail(t *testing.T) {
+	verifiedAt := time.Now()
+
+	tests := []struct {
+		name           string
+		userID         interface{}
+		repo           *fakeUserRepository
+		expectedStatus int
+		expectedCode   string
+	}{
+		{
+			name:           "缺少用戶資訊",
+			repo:           &fakeUserRepository{},
+			expectedStatus: http.StatusUnauthorized,
+			expectedCode:   "UNAUTHORIZED",
+		},
+		{
+			name:           "用戶不存在",
+			userID:         7,
+			repo:           &fakeUserRepository{err: fmt.Errorf("failed to get user: %w", apperrors.ErrUserNotFound)},
+			expectedStatus: http.StatusUnauthorized,
+			expectedCode:   "USER_NOT_FOUND",
+		},
+		{
+			name:           "資料庫錯誤",
+			userID:         7,
+			repo:           &fakeUserRepository{err: errors.New("connection refused")},
+			expectedStatus: http.StatusInternalServerError,
+			expectedCode:   "INTERNAL_SERVER_ERROR",
+		},
+		{
+			name:           "尚未驗證",
+			userID:         7,
+			repo:           &fakeUserRepository{user: &models.User{ID: 7}},
+			expectedStatus: http.StatusForbidden,
+			expectedCode:   "EMAIL_NOT_VERIFIED",
+		},
+		{
+			name:           "已完成驗證",
+			userID:         7,
+			repo:           &fakeUserRepository{user: &models.User{ID: 7, EmailVerifiedAt: &verifiedAt}},
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setUser := func(c *gin.Context) {
+				if tt.userID != nil {
+					c.Set("user_id", tt.userID)
+				}
+			}
+			r := setupRouter(setUser, RequireVerifiedEmail(tt.repo))
+
+			req, _ := http.NewRequest(http.MethodGet, "/test", nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if tt.expectedCode != "" {
+				assertErrorCode(t, w, tt.expectedCode, "")
+			}
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+)
+
+// DeleteAccountRequest 申請刪除帳號；未提供目前密碼時，需在重新登入後的短時間內申請
+type DeleteAccountRequest struct {
+	CurrentPassword string `json:"current_password"`
+}
+
+// AccountDeletionResponse 帳號預定刪除的時間；在此之前重新登入即取消刪除
+type AccountDeletionResponse struct {
+	DeletionScheduledAt time.Time `json:"deletion_scheduled_at"`
+}
+
+// UserDataExport 為用戶個人資料的匯出內容（GET /users/me/export）
+type UserDataExport struct {
+	ExportedAt time.Time             `json:"exported_at"`
+	Profile    *User                 `json:"profile"`
+	Security   AccountSecurityExport `json:"security"`
+	// Sections 為各功能模組以 UserDataExporterInterface 提供的區段，以區段名稱為鍵
+	Sections map[string]interface{} `json:"sections,omitempty"`
+}
+
+// AccountSecurityExport 帳號安全相關的資料；不含密碼、token 與金鑰本身
+type AccountSecurityExport struct {
+	MFAEnabled     bool           `json:"mfa_enabled"`
+	Sessions       []Session      `json:"sessions"`
+	LinkedAccounts []UserIdentity `json:"linked_accounts"`
+	APIKeys        []APIKey       `json:"api_keys"`
+}
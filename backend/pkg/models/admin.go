@@ -0,0 +1,57 @@
+package models
+
+import (
+	"time"
+)
+
+// 管理員用戶列表的帳號狀態篩選
+const (
+	UserStatusActive          = "active"
+	UserStatusSuspended       = "suspended"
+	UserStatusPendingDeletion = "pending_deletion"
+)
+
+// 管理員用戶列表的分頁設定
+const (
+	DefaultUserListPageSize = 20
+	MaxUserListPageSize     = 100
+)
+
+// ListUsersRequest 為管理員用戶列表的查詢條件（GET /admin/users）。
+// created_from 與 created_to 為 YYYY-MM-DD（UTC），兩端皆包含；sort 前加 - 表示遞減
+type ListUsersRequest struct {
+	Query         string     `form:"q" binding:"omitempty,max=100"`
+	Role          string     `form:"role" binding:"omitempty,max=20"`
+	LearningLevel int        `form:"learning_level" binding:"omitempty,min=1,max=10"`
+	Status        string     `form:"status" binding:"omitempty,oneof=active suspended pending_deletion"`
+	CreatedFrom   *time.Time `form:"created_from" time_format:"2006-01-02" time_utc:"1"`
+	CreatedTo     *time.Time `form:"created_to" time_format:"2006-01-02" time_utc:"1"`
+	Sort          string     `form:"sort" binding:"omitempty,oneof=created_at -created_at username -username email -email learning_level -learning_level"`
+	Page          int        `form:"page" binding:"omitempty,min=1"`
+	PageSize      int        `form:"page_size" binding:"omitempty,min=1,max=100"`
+}
+
+// UserListResponse 為分頁後的用戶列表
+type UserListResponse struct {
+	Users    []User `json:"users"`
+	Page     int    `json:"page"`
+	PageSize int    `json:"page_size"`
+	Total    int    `json:"total"`
+}
+
+// SuspendUserRequest 停用帳號，reason 會顯示於管理介面
+type SuspendUserRequest struct {
+	Reason string `json:"reason" binding:"required,max=255"`
+}
+
+// ChangeRoleRequest 變更用戶角色
+type ChangeRoleRequest struct {
+	Role string `json:"role" binding:"required,max=20"`
+}
+
+// ImpersonationResponse 為代入用戶身分的 access token；不附 refresh token，到期後需重新申請
+type ImpersonationResponse struct {
+	User      *User  `json:"user"`
+	Token     string `json:"token"`
+	ExpiresIn int64  `json:"expires_in"`
+}
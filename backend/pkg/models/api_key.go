@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+)
+
+// APIKey 代表用戶的個人 API 金鑰。金鑰本身只在建立時回傳一次，資料庫僅保存雜湊值與用於辨識的前綴
+type APIKey struct {
+	ID         int        `json:"id" db:"id"`
+	UserID     int        `json:"-" db:"user_id"`
+	Name       string     `json:"name" db:"name"`
+	Prefix     string     `json:"prefix" db:"prefix"`
+	KeyHash    string     `json:"-" db:"key_hash"`
+	Scopes     []string   `json:"scopes" db:"scopes"`
+	LastUsedAt *time.Time `json:"last_used_at" db:"last_used_at"`
+	ExpiresAt  *time.Time `json:"expires_at" db:"expires_at"`
+	RevokedAt  *time.Time `json:"-" db:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+}
+
+type CreateAPIKeyRequest struct {
+	Name          string   `json:"name" binding:"required,max=100"`
+	Scopes        []string `json:"scopes" binding:"required,min=1"`
+	ExpiresInDays int      `json:"expires_in_days" binding:"omitempty,min=1,max=365"`
+}
+
+// CreateAPIKeyResponse 建立 API 金鑰的回應，Key 為完整金鑰且不會再次顯示
+type CreateAPIKeyResponse struct {
+	*APIKey
+	Key string `json:"key"`
+}
+
+// APIKeyPrincipal 以 API 金鑰驗證成功後的身分，Permissions 為金鑰 scope 與用戶目前角色權限的交集
+type APIKeyPrincipal struct {
+	KeyID       int
+	User        *User
+	Permissions []string
+}
@@ -0,0 +1,81 @@
+package models
+
+import (
+	"time"
+)
+
+// 稽核日誌的事件類型
+const (
+	AuditEventRegister           = "register"
+	AuditEventLoginSuccess       = "login_success"
+	AuditEventLoginFailure       = "login_failure"
+	AuditEventLogout             = "logout"
+	AuditEventPasswordChange     = "password_change"
+	AuditEventRoleChange         = "role_change"
+	AuditEventTokenRevoked       = "token_revoked"
+	AuditEventAccountSuspended   = "account_suspended"
+	AuditEventAccountUnsuspended = "account_unsuspended"
+	AuditEventAccountUnlocked    = "account_unlocked"
+	AuditEventImpersonation      = "impersonation"
+	AuditEventAPIKeyCreated      = "api_key_created"
+	AuditEventMFADisabled        = "mfa_disabled"
+	AuditEventEmailChangeRequest = "email_change_requested"
+)
+
+// 稽核日誌查詢的分頁設定
+const (
+	DefaultAuditLogPageSize = 50
+	MaxAuditLogPageSize     = 200
+)
+
+// AuditLogEntry 為一筆安全稽核紀錄，寫入後不可修改。
+// ActorID 為執行操作的用戶，TargetUserID 為受影響的用戶；未登入或帳號不存在時為 nil
+type AuditLogEntry struct {
+	ID           int64             `json:"id" db:"id"`
+	EventType    string            `json:"event_type" db:"event_type"`
+	ActorID      *int              `json:"actor_id" db:"actor_id"`
+	TargetUserID *int              `json:"target_user_id" db:"target_user_id"`
+	IPAddress    string            `json:"ip_address" db:"ip_address"`
+	UserAgent    string            `json:"user_agent" db:"user_agent"`
+	RequestID    string            `json:"request_id" db:"request_id"`
+	Metadata     map[string]string `json:"metadata,omitempty" db:"metadata"`
+	CreatedAt    time.Time         `json:"created_at" db:"created_at"`
+}
+
+// NewAuditLogEntry 以請求的用戶端資訊建立稽核紀錄；actorID 或 targetUserID 為 0 表示無
+func NewAuditLogEntry(eventType string, client ClientInfo, actorID, targetUserID int, metadata map[string]string) *AuditLogEntry {
+	entry := &AuditLogEntry{
+		EventType: eventType,
+		IPAddress: client.IPAddress,
+		UserAgent: client.UserAgent,
+		RequestID: client.RequestID,
+		Metadata:  metadata,
+	}
+	if actorID != 0 {
+		entry.ActorID = &actorID
+	}
+	if targetUserID != 0 {
+		entry.TargetUserID = &targetUserID
+	}
+	return entry
+}
+
+// AuditLogQuery 為稽核日誌的查詢條件（GET /admin/audit-logs）。
+// from 與 to 為 RFC 3339 時間，from 包含、to 不包含；user_id 比對執行者或受影響的用戶
+type AuditLogQuery struct {
+	EventType string     `form:"event_type" binding:"omitempty,oneof=register login_success login_failure logout password_change role_change token_revoked account_suspended account_unsuspended account_unlocked impersonation api_key_created mfa_disabled email_change_requested"`
+	ActorID   int        `form:"actor_id" binding:"omitempty,min=1"`
+	UserID    int        `form:"user_id" binding:"omitempty,min=1"`
+	From      *time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To        *time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Page      int        `form:"page" binding:"omitempty,min=1"`
+	PageSize  int        `form:"page_size" binding:"omitempty,min=1,max=200"`
+}
+
+// AuditLogListResponse 為分頁後的稽核紀錄，新的排在前面
+type AuditLogListResponse struct {
+	Entries  []AuditLogEntry `json:"entries"`
+	Page     int             `json:"page"`
+	PageSize int             `json:"page_size"`
+	Total    int             `json:"total"`
+}
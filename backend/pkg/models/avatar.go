@@ -0,0 +1,7 @@
+package models
+
+// AvatarMaxBytes 為頭像上傳檔案的大小上限
+const AvatarMaxBytes = 5 << 20
+
+// AvatarSizes 為上傳頭像時產生的正方形縮圖邊長（像素），第一個為未指定尺寸時回傳的預設尺寸
+var AvatarSizes = []int{512, 256, 64}
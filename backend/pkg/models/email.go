@@ -0,0 +1,8 @@
+package models
+
+// EmailMessage 代表一封待寄送的純文字郵件
+type EmailMessage struct {
+	To      string
+	Subject string
+	Body    string
+}
@@ -0,0 +1,17 @@
+package models
+
+import (
+	"time"
+)
+
+// EmailVerificationToken 代表單次使用、具有效期限的 email 驗證 token（僅保存雜湊值）。
+// NewEmail 不為 nil 時為變更 email 的驗證，驗證成功後才將用戶的 email 改為新地址。
+type EmailVerificationToken struct {
+	ID        int        `json:"id" db:"id"`
+	UserID    int        `json:"user_id" db:"user_id"`
+	TokenHash string     `json:"-" db:"token_hash"`
+	NewEmail  *string    `json:"new_email,omitempty" db:"new_email"`
+	ExpiresAt time.Time  `json:"expires_at" db:"expires_at"`
+	UsedAt    *time.Time `json:"used_at" db:"used_at"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+}
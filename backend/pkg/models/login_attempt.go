@@ -0,0 +1,23 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// LoginAttempt 記錄某個帳號或 IP 的連續登入失敗次數與鎖定期限
+type LoginAttempt struct {
+	Key          string     `json:"key" db:"key"`
+	Failures     int        `json:"failures" db:"failures"`
+	LockedUntil  *time.Time `json:"locked_until" db:"locked_until"`
+	LastFailedAt time.Time  `json:"last_failed_at" db:"last_failed_at"`
+}
+
+// AccountLockedError 表示帳號或來源 IP 因多次登入失敗而暫時鎖定
+type AccountLockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *AccountLockedError) Error() string {
+	return fmt.Sprintf("account locked, retry after %s", e.RetryAfter.Round(time.Second))
+}
@@ -0,0 +1,35 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// 密碼政策規則名稱
+const (
+	PasswordRuleMinLength        = "min_length"
+	PasswordRuleMaxLength        = "max_length"
+	PasswordRuleCharacterClasses = "character_classes"
+	PasswordRuleStrength         = "strength"
+	PasswordRuleUserInfo         = "user_info"
+	PasswordRuleBreached         = "breached"
+)
+
+// PasswordPolicyViolation 為單一未通過的密碼規則；Limit 為該規則的門檻（例如最短長度），無門檻時為 0
+type PasswordPolicyViolation struct {
+	Rule  string `json:"rule"`
+	Limit int    `json:"limit,omitempty"`
+}
+
+// PasswordPolicyError 表示密碼未通過密碼政策，Violations 依檢查順序列出所有未通過的規則
+type PasswordPolicyError struct {
+	Violations []PasswordPolicyViolation
+}
+
+func (e *PasswordPolicyError) Error() string {
+	rules := make([]string, 0, len(e.Violations))
+	for _, violation := range e.Violations {
+		rules = append(rules, violation.Rule)
+	}
+	return fmt.Sprintf("password does not meet policy: %s", strings.Join(rules, ", "))
+}
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+)
+
+// UpdateProfileRequest 為部分更新，未提供的欄位維持原值；avatar_url 傳空字串表示移除頭像，
+// locale 傳空字串表示清除偏好語系、改依 Accept-Language 決定。
+// UpdatedAt 須為用戶資料目前的 updated_at，資料已被其他請求修改時更新會被拒絕。
+type UpdateProfileRequest struct {
+	Username      *string   `json:"username" binding:"omitempty,min=2,max=20,alphanum"`
+	LearningLevel *int      `json:"learning_level" binding:"omitempty,min=1,max=10"`
+	AvatarURL     *string   `json:"avatar_url" binding:"omitempty,max=500,url"`
+	Locale        *string   `json:"locale" binding:"omitempty,max=10"`
+	UpdatedAt     time.Time `json:"updated_at" binding:"required"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required"`
+	ConfirmPassword string `json:"confirm_password" binding:"required"`
+}
+
+// ChangeEmailRequest 申請變更 email；新地址完成驗證後才會生效
+type ChangeEmailRequest struct {
+	NewEmail        string `json:"new_email" binding:"required,email"`
+	CurrentPassword string `json:"current_password" binding:"required"`
+}
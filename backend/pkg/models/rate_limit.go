@@ -0,0 +1,24 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// RateLimitResult 單次限流判斷的結果，用於組成 X-RateLimit-* 標頭
+type RateLimitResult struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	ResetAfter time.Duration // 額度完全恢復所需時間
+	RetryAfter time.Duration // 被拒絕時，下一次請求可通過前需等待的時間
+}
+
+// RateLimitedError 表示服務層的限流（例如同一 email 短時間內重複申請登入連結）
+type RateLimitedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitedError) Error() string {
+	return fmt.Sprintf("rate limit exceeded, retry after %s", e.RetryAfter.Round(time.Second))
+}
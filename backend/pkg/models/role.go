@@ -0,0 +1,20 @@
+package models
+
+// 用戶角色
+const (
+	RoleStudent = "student"
+	RoleTeacher = "teacher"
+	RoleAdmin   = "admin"
+)
+
+// 權限名稱，角色與權限的對應存放於 role_permissions 表
+const (
+	PermissionLearningRead  = "learning:read"
+	PermissionLearningWrite = "learning:write"
+	PermissionContentManage = "content:manage"
+	PermissionStudentsRead  = "students:read"
+	PermissionUsersRead     = "users:read"
+	PermissionUsersManage   = "users:manage"
+	PermissionRolesManage   = "roles:manage"
+	PermissionAuditLogRead  = "audit_log:read"
+)
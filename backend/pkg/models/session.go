@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+)
+
+// Session 代表一次登入所建立的裝置會話，ID 同時作為 refresh token 家族 ID
+type Session struct {
+	ID         string     `json:"id" db:"id"`
+	UserID     int        `json:"-" db:"user_id"`
+	UserAgent  string     `json:"user_agent" db:"user_agent"`
+	IPAddress  string     `json:"ip_address" db:"ip_address"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+	LastSeenAt time.Time  `json:"last_seen_at" db:"last_seen_at"`
+	RevokedAt  *time.Time `json:"-" db:"revoked_at"`
+	Current    bool       `json:"current" db:"-"`
+}
+
+// ClientInfo 登入請求的用戶端資訊，由 handler 從 HTTP 請求中取得
+type ClientInfo struct {
+	UserAgent string
+	IPAddress string
+	RequestID string
+}
@@ -5,26 +5,32 @@ import (
 )
 
 type User struct {
-	ID            int       `json:"id" db:"id"`
-	Email         string    `json:"email" db:"email"`
-	Username      string    `json:"username" db:"username"`
-	PasswordHash  string    `json:"-" db:"password_hash"`
-	LearningLevel int       `json:"learning_level" db:"learning_level"`
-	AvatarURL     *string   `json:"avatar_url" db:"avatar_url"`
-	CreatedAt     time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt     time.Time `json:"updated_at" db:"updated_at"`
+	ID                  int        `json:"id" db:"id"`
+	Email               string     `json:"email" db:"email"`
+	Username            string     `json:"username" db:"username"`
+	PasswordHash        string     `json:"-" db:"password_hash"`
+	LearningLevel       int        `json:"learning_level" db:"learning_level"`
+	AvatarURL           *string    `json:"avatar_url" db:"avatar_url"`
+	EmailVerifiedAt     *time.Time `json:"email_verified_at" db:"email_verified_at"`
+	Role                string     `json:"role" db:"role"`
+	DeletionScheduledAt *time.Time `json:"deletion_scheduled_at,omitempty" db:"deletion_scheduled_at"`
+	SuspendedAt         *time.Time `json:"suspended_at,omitempty" db:"suspended_at"`
+	SuspensionReason    *string    `json:"suspension_reason,omitempty" db:"suspension_reason"`
+	Locale              *string    `json:"locale" db:"locale"`
+	CreatedAt           time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at" db:"updated_at"`
 }
 
 type RegisterRequest struct {
 	Email           string `json:"email" binding:"required,email"`
 	Username        string `json:"username" binding:"required,min=2,max=20,alphanum"`
-	Password        string `json:"password" binding:"required,min=8"`
+	Password        string `json:"password" binding:"required"`
 	ConfirmPassword string `json:"confirm_password" binding:"required"`
 }
 
 type LoginRequest struct {
 	Email    string `json:"email" binding:"required,email"`
-	Password string `json:"password" binding:"required,min=8"`
+	Password string `json:"password" binding:"required"`
 }
 
 type APIResponse struct {
@@ -40,7 +46,13 @@ type APIError struct {
 	Message string `json:"message"`
 }
 
+// AuthResponse 為登入結果；啟用兩步驟驗證的用戶僅回傳 MFARequired 與 MFAToken，
+// 需再以 POST /auth/mfa/verify 換取正式的 token
 type AuthResponse struct {
-	User  User   `json:"user"`
-	Token string `json:"token"`
+	User         *User  `json:"user,omitempty"`
+	Token        string `json:"token,omitempty"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	ExpiresIn    int64  `json:"expires_in,omitempty"`
+	MFARequired  bool   `json:"mfa_required,omitempty"`
+	MFAToken     string `json:"mfa_token,omitempty"`
 }
\ No newline at end of file
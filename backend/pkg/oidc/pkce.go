@@ -0,0 +1,18 @@
+package oidc
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"smart-learning-backend/pkg/utils"
+)
+
+// GenerateCodeVerifier 產生 PKCE code_verifier（43 個字元的 URL 安全字串）
+func GenerateCodeVerifier() (string, error) {
+	return utils.GenerateOpaqueToken()
+}
+
+// CodeChallengeS256 依 RFC 7636 計算 code_challenge
+func CodeChallengeS256(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,191 @@
+package oidc_test
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"smart-learning-backend/pkg/oidc"
+	"smart-learning-backend/pkg/oidc/oidctest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const redirectURL = "http://localhost:5173/auth/oidc/school/callback"
+
+// authorize 走完授權流程並回傳授權碼
+func authorize(t *testing.T, fake *oidctest.Provider, provider *oidc.Provider, verifier, nonce string) string {
+	t.Helper()
+
+	authURL, err := provider.AuthCodeURL(context.Background(), "state-1", nonce, oidc.CodeChallengeS256(verifier))
+	if err != nil {
+		t.Fatalf("AuthCodeURL() error = %v", err)
+	}
+
+	code, state, err := fake.Authorize(authURL)
+	if err != nil {
+		t.Fatalf("Authorize() error = %v", err)
+	}
+	if state != "state-1" {
+		t.Fatalf("state = %q, want state-1", state)
+	}
+	return code
+}
+
+func TestProvider_AuthCodeURL(t *testing.T) {
+	fake := oidctest.NewProvider("client-1", "secret-1")
+	defer fake.Close()
+
+	provider := oidc.NewProvider(fake.Config("school", redirectURL))
+	authURL, err := provider.AuthCodeURL(context.Background(), "state-1", "nonce-1", "challenge-1")
+	if err != nil {
+		t.Fatalf("AuthCodeURL() error = %v", err)
+	}
+
+	parsed, _ := url.Parse(authURL)
+	q := parsed.Query()
+	want := map[string]string{
+		"response_type":         "code",
+		"client_id":             "client-1",
+		"redirect_uri":          redirectURL,
+		"scope":                 "openid email profile",
+		"state":                 "state-1",
+		"nonce":                 "nonce-1",
+		"code_challenge":        "challenge-1",
+		"code_challenge_method": "S256",
+	}
+	for key, value := range want {
+		if q.Get(key) != value {
+			t.Errorf("%s = %q, want %q", key, q.Get(key), value)
+		}
+	}
+	if !strings.HasPrefix(authURL, fake.Issuer+"/authorize?") {
+		t.Errorf("AuthCodeURL() = %s, want authorization endpoint", authURL)
+	}
+}
+
+func TestProvider_Exchange(t *testing.T) {
+	tests := []struct {
+		name          string
+		claims        func(jwt.MapClaims)
+		verifier      string
+		nonce         string
+		wantError     bool
+		errorContains string
+	}{
+		{
+			name:     "成功換取並驗證 ID token",
+			verifier: "correct-verifier-correct-verifier-correct-verifier",
+			nonce:    "nonce-1",
+		},
+		{
+			name:          "code_verifier 錯誤",
+			verifier:      "wrong-verifier-wrong-verifier-wrong-verifier-wrong",
+			nonce:         "nonce-1",
+			wantError:     true,
+			errorContains: "invalid_grant",
+		},
+		{
+			name:          "nonce 不符",
+			verifier:      "correct-verifier-correct-verifier-correct-verifier",
+			nonce:         "other-nonce",
+			wantError:     true,
+			errorContains: "nonce mismatch",
+		},
+		{
+			name:          "audience 不符",
+			claims:        func(c jwt.MapClaims) { c["aud"] = "other-client" },
+			verifier:      "correct-verifier-correct-verifier-correct-verifier",
+			nonce:         "nonce-1",
+			wantError:     true,
+			errorContains: "invalid id token",
+		},
+		{
+			name:          "issuer 不符",
+			claims:        func(c jwt.MapClaims) { c["iss"] = "https://evil.example" },
+			verifier:      "correct-verifier-correct-verifier-correct-verifier",
+			nonce:         "nonce-1",
+			wantError:     true,
+			errorContains: "invalid id token",
+		},
+		{
+			name:          "ID token 已過期",
+			claims:        func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Hour).Unix() },
+			verifier:      "correct-verifier-correct-verifier-correct-verifier",
+			nonce:         "nonce-1",
+			wantError:     true,
+			errorContains: "invalid id token",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := oidctest.NewProvider("client-1", "secret-1")
+			defer fake.Close()
+			fake.IDTokenClaims = tt.claims
+
+			provider := oidc.NewProvider(fake.Config("school", redirectURL))
+			code := authorize(t, fake, provider, "correct-verifier-correct-verifier-correct-verifier", "nonce-1")
+
+			identity, err := provider.Exchange(context.Background(), code, tt.verifier, tt.nonce)
+
+			if tt.wantError {
+				if err == nil {
+					t.Fatal("Exchange() expected error but got nil")
+				}
+				if !strings.Contains(err.Error(), tt.errorContains) {
+					t.Errorf("Exchange() error = %v, expected to contain %v", err, tt.errorContains)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Exchange() unexpected error = %v", err)
+			}
+			if identity.Provider != "school" || identity.Subject != fake.User.Subject {
+				t.Errorf("Exchange() identity = %+v", identity)
+			}
+			if identity.Email != fake.User.Email || !identity.EmailVerified {
+				t.Errorf("Exchange() email = %s verified = %v", identity.Email, identity.EmailVerified)
+			}
+
+			// 授權碼只能使用一次
+			if _, err := provider.Exchange(context.Background(), code, tt.verifier, tt.nonce); err == nil {
+				t.Error("Exchange() accepted a reused authorization code")
+			}
+		})
+	}
+}
+
+func TestProvider_DiscoveryFailure(t *testing.T) {
+	fake := oidctest.NewProvider("client-1", "secret-1")
+	config := fake.Config("school", redirectURL)
+	fake.Close()
+
+	if _, err := oidc.NewProvider(config).AuthCodeURL(context.Background(), "s", "n", "c"); err == nil || !strings.Contains(err.Error(), "oidc discovery failed") {
+		t.Errorf("AuthCodeURL() error = %v, want discovery failure", err)
+	}
+}
+
+// 請求已取消時不再向身分提供者換取 token
+func TestProvider_ExchangeCanceled(t *testing.T) {
+	fake := oidctest.NewProvider("client-1", "secret-1")
+	defer fake.Close()
+
+	provider := oidc.NewProvider(fake.Config("school", redirectURL))
+	verifier := "correct-verifier-correct-verifier-correct-verifier"
+	code := authorize(t, fake, provider, verifier, "nonce-1")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := provider.Exchange(ctx, code, verifier, "nonce-1"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Exchange() error = %v, want context.Canceled", err)
+	}
+
+	// 授權碼未被使用，之後仍可換取
+	if _, err := provider.Exchange(context.Background(), code, verifier, "nonce-1"); err != nil {
+		t.Errorf("Exchange() unexpected error = %v", err)
+	}
+}
@@ -0,0 +1,128 @@
+package passwordpolicy
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	sha1HexLength     = 40
+	rangePrefixLength = 5
+)
+
+// BreachedList 為離線的外洩密碼清單，格式與 Pwned Passwords 的 k-anonymity range API 相同：
+// 以密碼 SHA-1 雜湊（大寫十六進位）的前 5 碼分組，查詢時只比對同一前綴下的後 35 碼，清單中不含任何明文密碼。
+//
+// 路徑可以是目錄或單一檔案：
+//   - 目錄：每個前綴一個 <PREFIX>.txt，每行為 "SUFFIX:COUNT"，查詢時才讀取對應的檔案，適合完整的 Pwned Passwords 資料
+//   - 檔案：每行為完整雜湊 "HASH:COUNT"（COUNT 可省略），啟動時依前綴載入記憶體，適合較小的清單
+//
+// COUNT 為 0 的行為 range 回應的填充資料，不視為外洩。
+type BreachedList struct {
+	dir    string
+	ranges map[string]map[string]struct{}
+}
+
+// LoadBreachedList 載入外洩密碼清單
+func LoadBreachedList(path string) (*BreachedList, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open breached password list: %w", err)
+	}
+	if info.IsDir() {
+		return &BreachedList{dir: path}, nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open breached password list: %w", err)
+	}
+	defer file.Close()
+
+	list := &BreachedList{ranges: make(map[string]map[string]struct{})}
+	err = scanHashLines(file, sha1HexLength, func(hash string) {
+		prefix, suffix := hash[:rangePrefixLength], hash[rangePrefixLength:]
+		if list.ranges[prefix] == nil {
+			list.ranges[prefix] = make(map[string]struct{})
+		}
+		list.ranges[prefix][suffix] = struct{}{}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid breached password list %s: %w", path, err)
+	}
+
+	return list, nil
+}
+
+// Contains 判斷密碼是否出現在外洩清單中
+func (b *BreachedList) Contains(password string) (bool, error) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := hash[:rangePrefixLength], hash[rangePrefixLength:]
+
+	if b.dir == "" {
+		_, ok := b.ranges[prefix][suffix]
+		return ok, nil
+	}
+
+	file, err := os.Open(filepath.Join(b.dir, prefix+".txt"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to open breached password range %s: %w", prefix, err)
+	}
+	defer file.Close()
+
+	found := false
+	err = scanHashLines(file, sha1HexLength-rangePrefixLength, func(candidate string) {
+		if candidate == suffix {
+			found = true
+		}
+	})
+	if err != nil {
+		return false, fmt.Errorf("invalid breached password range %s: %w", prefix, err)
+	}
+
+	return found, nil
+}
+
+// scanHashLines 逐行解析 "HASH:COUNT"，略過空行與 COUNT 為 0 的填充資料
+func scanHashLines(r io.Reader, hashLength int, fn func(hash string)) error {
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		hash, count, hasCount := strings.Cut(text, ":")
+		hash = strings.ToUpper(hash)
+		if len(hash) != hashLength || strings.Trim(hash, "0123456789ABCDEF") != "" {
+			return fmt.Errorf("line %d: invalid hash", line)
+		}
+
+		if hasCount {
+			n, err := strconv.Atoi(count)
+			if err != nil {
+				return fmt.Errorf("line %d: invalid count", line)
+			}
+			if n == 0 {
+				continue
+			}
+		}
+
+		fn(hash)
+	}
+
+	return scanner.Err()
+}
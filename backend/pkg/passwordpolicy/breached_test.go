@@ -0,0 +1,100 @@
+package passwordpolicy
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func sha1Hex(password string) string {
+	sum := sha1.Sum([]byte(password))
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
+
+func TestBreachedList_File(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "breached.txt")
+	content := strings.Join([]string{
+		sha1Hex("hunter2") + ":17",
+		strings.ToLower(sha1Hex("letmein!")),
+		sha1Hex("padding-only") + ":0",
+		"",
+	}, "\n")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	list, err := LoadBreachedList(path)
+	if err != nil {
+		t.Fatalf("LoadBreachedList() unexpected error = %v", err)
+	}
+
+	tests := []struct {
+		password string
+		want     bool
+	}{
+		{"hunter2", true},
+		{"letmein!", true},
+		{"padding-only", false},
+		{"correct horse battery staple", false},
+	}
+	for _, tt := range tests {
+		got, err := list.Contains(tt.password)
+		if err != nil {
+			t.Fatalf("Contains(%q) unexpected error = %v", tt.password, err)
+		}
+		if got != tt.want {
+			t.Errorf("Contains(%q) = %v, want %v", tt.password, got, tt.want)
+		}
+	}
+}
+
+func TestBreachedList_RangeDirectory(t *testing.T) {
+	dir := t.TempDir()
+	hash := sha1Hex("hunter2")
+	content := strings.Repeat("0", 34) + "A:0\r\n" + hash[5:] + ":17\r\n"
+	if err := os.WriteFile(filepath.Join(dir, hash[:5]+".txt"), []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	list, err := LoadBreachedList(dir)
+	if err != nil {
+		t.Fatalf("LoadBreachedList() unexpected error = %v", err)
+	}
+
+	if got, err := list.Contains("hunter2"); err != nil || !got {
+		t.Errorf("Contains(hunter2) = %v, %v, want true", got, err)
+	}
+	// 沒有對應的 range 檔視為未外洩
+	if got, err := list.Contains("correct horse battery staple"); err != nil || got {
+		t.Errorf("Contains() = %v, %v, want false", got, err)
+	}
+}
+
+func TestLoadBreachedList_Errors(t *testing.T) {
+	dir := t.TempDir()
+	invalid := filepath.Join(dir, "invalid.txt")
+	if err := os.WriteFile(invalid, []byte("password123\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name          string
+		path          string
+		errorContains string
+	}{
+		{name: "檔案不存在", path: filepath.Join(dir, "missing.txt"), errorContains: "failed to open breached password list"},
+		{name: "含明文密碼", path: invalid, errorContains: "line 1: invalid hash"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadBreachedList(tt.path)
+			if err == nil || !strings.Contains(err.Error(), tt.errorContains) {
+				t.Errorf("LoadBreachedList() error = %v, want containing %q", err, tt.errorContains)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package passwordpolicy
+
+import "strings"
+
+// commonWords 為常見密碼與常用單字，依常見程度排序；排名越前面，猜中所需次數越少
+var commonWords = strings.Fields(`
+123456 password 12345678 qwerty 123456789 12345 1234 111111 1234567 dragon
+123123 baseball abc123 football monkey letmein 696969 shadow master 666666
+qwertyuiop 123321 mustang 1234567890 michael 654321 superman 1qaz2wsx 7777777 121212
+000000 qazwsx 123qwe killer trustno1 jordan jennifer zxcvbnm asdfgh hunter
+buster soccer harley batman andrew tigger sunshine iloveyou 2000 charlie
+robert thomas hockey ranger daniel starwars 112233 george computer michelle
+jessica pepper 1111 zxcvbn 555555 11111111 131313 freedom 777777 pass
+maggie 159753 aaaaaa ginger princess joshua cheese amanda summer love
+ashley nicole chelsea biteme matthew access yankees 987654321 dallas austin
+thunder taylor matrix admin welcome login passw0rd hello secret password1
+qwerty123 123456a a123456 abcd1234 aa123456 qwe123 abcdef changeme default guest
+root user test demo woaini 520520 5201314 iloveu letmein1 football1
+monkey1 dragon1 baseball1 superman1 sunshine1 princess1 welcome1 admin123 root123 test123
+student teacher school learning smart english study homework class classroom
+china taiwan taipei japan tokyo apple orange banana flower family
+friend happy lucky money angel baby honey forever heart secret1
+spring winter autumn purple yellow silver golden diamond killer1 hello123
+`)
+
+// englishWords 為常被用來組成密碼的英文單字，排名於 commonWords 之後
+var englishWords = strings.Fields(`
+the and you that was for are with his they one have this from word
+what all were when your can said there use each which she how their
+time will way about many then them write would like these long make
+thing see him two has look more day could come did number sound
+most people over know water than call first who may down side been
+now find any new work part take get place made live where after back
+little only round man year came show every good give our under name
+very through just form great think say help low line differ turn cause
+much mean before move right boy old too same tell does set three want
+air well also play small end put home read hand port large spell add
+even land here must big high such follow act why ask men change went
+light kind off need house picture try again animal point mother world near
+build self earth father head stand own page should country found answer
+grow learn plant cover food sun four between state keep eye never last
+city tree cross farm hard start might story saw far sea draw left late
+run while press close night real life few north open seem together next
+white children begin got walk example ease paper group always music those
+both mark often letter until mile river car feet care second book carry
+took science eat room began idea fish mountain stop once base hear horse
+cut sure watch color face wood main enough plain girl usual young ready
+above ever red list though feel talk bird soon body dog song door
+product black short class wind question happen complete ship area half rock
+order fire south problem piece told knew pass since top whole king space
+heard best hour better true during hundred five remember step early hold west
+ground interest reach fast verb sing listen six table travel less morning ten
+simple several vowel toward war lay against pattern slow center person money
+serve appear road map rain rule govern pull cold notice voice unit power
+town fine certain fly fall lead cry dark machine note wait plan figure
+star box noun field rest correct able pound done beauty drive stood contain
+front teach week final gave green quick develop ocean warm free minute strong
+special mind behind clear tail produce fact street inch multiply nothing course stay
+wheel full force blue object decide surface deep moon island foot system busy
+test record boat common gold possible plane dry wonder laugh thousand ago ran
+check game shape equate miss brought heat snow tire bring yes distant fill
+east paint language among
+`)
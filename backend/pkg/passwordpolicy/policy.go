@@ -0,0 +1,180 @@
+package passwordpolicy
+
+import (
+	"log/slog"
+	"smart-learning-backend/pkg/config"
+	"smart-learning-backend/pkg/models"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// minUserTokenLength 為比對個人資料時的最短單字長度，避免 "com"、"abc" 之類的短字誤判
+const minUserTokenLength = 4
+
+// Config 為密碼政策設定
+type Config struct {
+	MinLength int
+	// MaxLength 為 0 時不限制長度
+	MaxLength int
+	// MinCharacterClasses 為大寫字母、小寫字母、數字、符號中至少需包含的種類數
+	MinCharacterClasses int
+	// MinStrength 為 EstimateStrength 的最低分數（0–4），0 表示不檢查
+	MinStrength int
+	// Breached 為外洩密碼清單，nil 時不檢查
+	Breached *BreachedList
+}
+
+// DefaultConfig 為預設的密碼政策，與 config.Default 的設定相同
+var DefaultConfig = Config{
+	MinLength:           8,
+	MaxLength:           128,
+	MinCharacterClasses: 2,
+	MinStrength:         2,
+}
+
+// Policy 依設定檢查密碼，並一次回報所有未通過的規則
+type Policy struct {
+	config Config
+}
+
+func New(config Config) *Policy {
+	return &Policy{config: config}
+}
+
+// Validate 檢查密碼是否符合政策；userInputs 為用戶名、email 等個人資料。
+// 未通過時回傳 *models.PasswordPolicyError。外洩清單查詢失敗時僅記錄，不阻擋設定密碼。
+func (p *Policy) Validate(password string, userInputs ...string) error {
+	var violations []models.PasswordPolicyViolation
+	violate := func(rule string, limit int) {
+		violations = append(violations, models.PasswordPolicyViolation{Rule: rule, Limit: limit})
+	}
+
+	length := utf8.RuneCountInString(password)
+	if length < p.config.MinLength {
+		violate(models.PasswordRuleMinLength, p.config.MinLength)
+	}
+	if p.config.MaxLength > 0 && length > p.config.MaxLength {
+		violate(models.PasswordRuleMaxLength, p.config.MaxLength)
+	}
+
+	if characterClasses(password) < p.config.MinCharacterClasses {
+		violate(models.PasswordRuleCharacterClasses, p.config.MinCharacterClasses)
+	}
+
+	if p.config.MinStrength > 0 && EstimateStrength(password, userInputs...).Score < p.config.MinStrength {
+		violate(models.PasswordRuleStrength, p.config.MinStrength)
+	}
+
+	if similarToUserInputs(password, userInputs) {
+		violate(models.PasswordRuleUserInfo, 0)
+	}
+
+	if p.config.Breached != nil {
+		breached, err := p.config.Breached.Contains(password)
+		if err != nil {
+			slog.Warn("外洩密碼清單查詢失敗", "error", err)
+		} else if breached {
+			violate(models.PasswordRuleBreached, 0)
+		}
+	}
+
+	if len(violations) > 0 {
+		return &models.PasswordPolicyError{Violations: violations}
+	}
+	return nil
+}
+
+// characterClasses 計算密碼包含大寫字母、小寫字母、數字、符號中的幾種
+func characterClasses(password string) int {
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			symbol = true
+		}
+	}
+
+	classes := 0
+	for _, present := range []bool{upper, lower, digit, symbol} {
+		if present {
+			classes++
+		}
+	}
+	return classes
+}
+
+// similarToUserInputs 判斷密碼（含 l33t 替換或反轉）是否包含個人資料，或與個人資料僅有少數字元不同
+func similarToUserInputs(password string, userInputs []string) bool {
+	lower := strings.ToLower(password)
+	candidates := []string{lower, unleet(lower), reverse(lower)}
+
+	for _, token := range userTokens(userInputs) {
+		if utf8.RuneCountInString(token) < minUserTokenLength {
+			continue
+		}
+
+		for _, candidate := range candidates {
+			if strings.Contains(candidate, token) {
+				return true
+			}
+		}
+
+		maxDistance := utf8.RuneCountInString(token) / 4
+		if levenshtein(unleet(lower), token) <= maxDistance {
+			return true
+		}
+	}
+
+	return false
+}
+
+func levenshtein(a, b string) int {
+	source, target := []rune(a), []rune(b)
+	previous := make([]int, len(target)+1)
+	current := make([]int, len(target)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(source); i++ {
+		current[0] = i
+		for j := 1; j <= len(target); j++ {
+			cost := 1
+			if source[i-1] == target[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+
+	return previous[len(target)]
+}
+
+// Load 依設定建立密碼政策；BreachedList 為外洩密碼清單的檔案或目錄，未設定時不檢查。
+// 數值範圍由 config.Config.Validate 檢查。
+func Load(settings config.PasswordPolicyConfig) (*Policy, error) {
+	policyConfig := Config{
+		MinLength:           settings.MinLength,
+		MaxLength:           settings.MaxLength,
+		MinCharacterClasses: settings.MinCharacterClasses,
+		MinStrength:         settings.MinStrength,
+	}
+
+	if settings.BreachedList != "" {
+		breached, err := LoadBreachedList(settings.BreachedList)
+		if err != nil {
+			return nil, err
+		}
+		policyConfig.Breached = breached
+	}
+
+	return New(policyConfig), nil
+}
@@ -0,0 +1,128 @@
+package passwordpolicy
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"smart-learning-backend/pkg/config"
+	"smart-learning-backend/pkg/models"
+	"strings"
+	"testing"
+)
+
+func violatedRules(err error) []string {
+	var policyErr *models.PasswordPolicyError
+	if !errors.As(err, &policyErr) {
+		return nil
+	}
+
+	rules := make([]string, 0, len(policyErr.Violations))
+	for _, violation := range policyErr.Violations {
+		rules = append(rules, violation.Rule)
+	}
+	return rules
+}
+
+func TestPolicy_Validate(t *testing.T) {
+	breachedPath := filepath.Join(t.TempDir(), "breached.txt")
+	if err := os.WriteFile(breachedPath, []byte(sha1Hex("Tr0ub4dor&3")+":3\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	breached, err := LoadBreachedList(breachedPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config := DefaultConfig
+	config.Breached = breached
+	policy := New(config)
+	userInputs := []string{"meiling", "mei.chen@example.com"}
+
+	tests := []struct {
+		name     string
+		password string
+		want     []string
+	}{
+		{name: "符合政策", password: "gH7#kLq9!vZ"},
+		{name: "過短", password: "gH7#kLq", want: []string{models.PasswordRuleMinLength}},
+		{name: "過長", password: "gH7#kLq9!vZ" + strings.Repeat("x", 128), want: []string{models.PasswordRuleMaxLength}},
+		{name: "字元種類不足", password: "xkqvbnmwhpz", want: []string{models.PasswordRuleCharacterClasses}},
+		{name: "強度不足", password: "password123", want: []string{models.PasswordRuleStrength}},
+		{name: "包含用戶名", password: "Meiling#8842x", want: []string{models.PasswordRuleUserInfo}},
+		{name: "包含 email 帳號的 l33t 替換", password: "Fr0g-m3i.ch3n", want: []string{models.PasswordRuleUserInfo}},
+		{name: "與用戶名近似", password: "meilinq", want: []string{models.PasswordRuleMinLength, models.PasswordRuleCharacterClasses, models.PasswordRuleStrength, models.PasswordRuleUserInfo}},
+		{name: "出現在外洩清單", password: "Tr0ub4dor&3", want: []string{models.PasswordRuleBreached}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := policy.Validate(tt.password, userInputs...)
+			if len(tt.want) == 0 {
+				if err != nil {
+					t.Errorf("Validate() unexpected error = %v", err)
+				}
+				return
+			}
+
+			if got := violatedRules(err); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Validate() violations = %v, want %v (err = %v)", got, tt.want, err)
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name          string
+		modify        func(*config.PasswordPolicyConfig)
+		errorContains string
+		password      string
+		wantRules     []string
+	}{
+		{
+			name:     "預設值",
+			modify:   func(*config.PasswordPolicyConfig) {},
+			password: "short1",
+			wantRules: []string{
+				models.PasswordRuleMinLength,
+				models.PasswordRuleStrength,
+			},
+		},
+		{
+			name: "自訂最短長度並關閉強度檢查",
+			modify: func(c *config.PasswordPolicyConfig) {
+				c.MinLength, c.MinStrength, c.MinCharacterClasses = 12, 0, 0
+			},
+			password:  "abcdefghij",
+			wantRules: []string{models.PasswordRuleMinLength},
+		},
+		{
+			name:          "外洩清單不存在",
+			modify:        func(c *config.PasswordPolicyConfig) { c.BreachedList = filepath.Join(t.TempDir(), "missing.txt") },
+			errorContains: "failed to open breached password list",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.Default().PasswordPolicy
+			tt.modify(&cfg)
+
+			policy, err := Load(cfg)
+			if tt.errorContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errorContains) {
+					t.Errorf("Load() error = %v, want containing %q", err, tt.errorContains)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Load() unexpected error = %v", err)
+			}
+
+			if got := violatedRules(policy.Validate(tt.password)); !reflect.DeepEqual(got, tt.wantRules) {
+				t.Errorf("Validate(%q) violations = %v, want %v", tt.password, got, tt.wantRules)
+			}
+		})
+	}
+}
@@ -0,0 +1,363 @@
+package passwordpolicy
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// 強度估算參考 zxcvbn：找出密碼中的常見單字、連續字元、鍵盤排列、重複與年份，
+// 以動態規劃求出猜中整個密碼所需的最少次數，未被任何模式涵蓋的字元以暴力破解計算。
+const (
+	// maxEstimateLength 限制估算的輸入長度，超過的部分一律以暴力破解計算
+	maxEstimateLength = 100
+	maxWordLength     = 32
+
+	bruteforceCardinality        = 10
+	minSubmatchGuessesSingleChar = 10
+	minSubmatchGuessesMultiChar  = 50
+	minYearSpace                 = 20
+)
+
+// Strength 為密碼強度估算結果
+type Strength struct {
+	// GuessesLog10 為估計猜中密碼所需次數的 log10
+	GuessesLog10 float64
+	// Score 與 zxcvbn 相同：0 極弱、1 很弱、2 普通、3 強、4 很強
+	Score int
+}
+
+type match struct {
+	start   int
+	guesses float64
+}
+
+var rankedDictionary = buildDictionary(commonWords, englishWords)
+
+func buildDictionary(wordLists ...[]string) map[string]int {
+	dictionary := make(map[string]int)
+	for _, words := range wordLists {
+		for _, word := range words {
+			if _, ok := dictionary[word]; !ok {
+				dictionary[word] = len(dictionary) + 1
+			}
+		}
+	}
+	return dictionary
+}
+
+var l33tTable = map[rune]rune{
+	'4': 'a', '@': 'a', '8': 'b', '(': 'c', '3': 'e', '6': 'g', '1': 'i', '!': 'i',
+	'|': 'i', '0': 'o', '$': 's', '5': 's', '7': 't', '+': 't', '2': 'z',
+}
+
+var keyboardRows = []string{
+	"`1234567890-=",
+	"qwertyuiop[]\\",
+	"asdfghjkl;'",
+	"zxcvbnm,./",
+}
+
+// EstimateStrength 估算密碼強度；userInputs（用戶名、email 等）視為攻擊者優先嘗試的單字
+func EstimateStrength(password string, userInputs ...string) Strength {
+	runes := []rune(password)
+	extra := 0
+	if len(runes) > maxEstimateLength {
+		extra = len(runes) - maxEstimateLength
+		runes = runes[:maxEstimateLength]
+	}
+
+	guesses := minimumGuessesLog10(runes, buildDictionary(userTokens(userInputs))) +
+		float64(extra)*math.Log10(bruteforceCardinality)
+
+	return Strength{GuessesLog10: guesses, Score: scoreFor(guesses)}
+}
+
+func scoreFor(guessesLog10 float64) int {
+	switch {
+	case guessesLog10 < 3:
+		return 0
+	case guessesLog10 < 6:
+		return 1
+	case guessesLog10 < 8:
+		return 2
+	case guessesLog10 < 10:
+		return 3
+	default:
+		return 4
+	}
+}
+
+// minimumGuessesLog10 以動態規劃組合各模式，best[k] 為猜中前 k 個字元所需的最少次數（log10）
+func minimumGuessesLog10(runes []rune, userDictionary map[string]int) float64 {
+	matchesByEnd := findMatches(runes, userDictionary)
+
+	best := make([]float64, len(runes)+1)
+	for k := 1; k <= len(runes); k++ {
+		best[k] = best[k-1] + math.Log10(bruteforceCardinality)
+		for _, m := range matchesByEnd[k] {
+			if guesses := best[m.start] + math.Log10(m.guesses); guesses < best[k] {
+				best[k] = guesses
+			}
+		}
+	}
+
+	return best[len(runes)]
+}
+
+// findMatches 回傳以結束位置為索引的所有模式
+func findMatches(runes []rune, userDictionary map[string]int) [][]match {
+	matchesByEnd := make([][]match, len(runes)+1)
+	add := func(start, end int, guesses float64) {
+		minimum := float64(minSubmatchGuessesMultiChar)
+		if end-start == 1 {
+			minimum = minSubmatchGuessesSingleChar
+		}
+		matchesByEnd[end] = append(matchesByEnd[end], match{start: start, guesses: math.Max(guesses, minimum)})
+	}
+
+	lower := make([]rune, len(runes))
+	for i, r := range runes {
+		lower[i] = unicode.ToLower(r)
+	}
+
+	dictionaryMatches(runes, lower, userDictionary, add)
+	sequenceMatches(lower, add)
+	keyboardMatches(lower, add)
+	repeatMatches(runes, lower, add)
+	yearMatches(lower, add)
+
+	return matchesByEnd
+}
+
+func dictionaryMatches(runes, lower []rune, userDictionary map[string]int, add func(start, end int, guesses float64)) {
+	lookup := func(word string) (int, bool) {
+		if rank, ok := userDictionary[word]; ok {
+			return rank, true
+		}
+		rank, ok := rankedDictionary[word]
+		return rank, ok
+	}
+
+	for i := range lower {
+		for j := i + 1; j <= len(lower) && j-i <= maxWordLength; j++ {
+			word := string(lower[i:j])
+			best := math.Inf(1)
+
+			if rank, ok := lookup(word); ok {
+				best = float64(rank)
+			}
+			if unleeted := unleet(word); unleeted != word {
+				if rank, ok := lookup(unleeted); ok {
+					best = math.Min(best, float64(rank)*2)
+				}
+			}
+			if reversed := reverse(word); j-i >= 3 && reversed != word {
+				if rank, ok := lookup(reversed); ok {
+					best = math.Min(best, float64(rank)*2)
+				}
+			}
+
+			if !math.IsInf(best, 1) {
+				add(i, j, best*uppercaseVariations(runes[i:j]))
+			}
+		}
+	}
+}
+
+// sequenceMatches 找出 abc、4321 等連續字元
+func sequenceMatches(lower []rune, add func(start, end int, guesses float64)) {
+	for i := 0; i+2 < len(lower); {
+		delta := lower[i+1] - lower[i]
+		if delta != 1 && delta != -1 {
+			i++
+			continue
+		}
+
+		j := i + 2
+		for j < len(lower) && lower[j]-lower[j-1] == delta {
+			j++
+		}
+
+		if j-i >= 3 {
+			base := 26.0
+			switch first := lower[i]; {
+			case strings.ContainsRune("az019", first):
+				base = 4
+			case unicode.IsDigit(first):
+				base = 10
+			}
+			if delta < 0 {
+				base *= 2
+			}
+			add(i, j, base*float64(j-i))
+		}
+		i = j - 1
+	}
+}
+
+// keyboardMatches 找出 qwerty、asdf 等同一列相鄰按鍵
+func keyboardMatches(lower []rune, add func(start, end int, guesses float64)) {
+	for i := 0; i+3 < len(lower); {
+		direction := keyboardStep(lower[i], lower[i+1])
+		if direction == 0 {
+			i++
+			continue
+		}
+
+		j := i + 2
+		for j < len(lower) && keyboardStep(lower[j-1], lower[j]) == direction {
+			j++
+		}
+
+		if j-i >= 4 {
+			keys := 0
+			for _, row := range keyboardRows {
+				keys += len(row)
+			}
+			add(i, j, float64(keys*2*(j-i-1)))
+		}
+		i = j - 1
+	}
+}
+
+// keyboardStep 回傳兩個按鍵在同一列上的方向（1 向右、-1 向左），不相鄰時回傳 0
+func keyboardStep(from, to rune) int {
+	for _, row := range keyboardRows {
+		fromIndex := strings.IndexRune(row, from)
+		toIndex := strings.IndexRune(row, to)
+		if fromIndex < 0 || toIndex < 0 {
+			continue
+		}
+		if toIndex-fromIndex == 1 || toIndex-fromIndex == -1 {
+			return toIndex - fromIndex
+		}
+	}
+	return 0
+}
+
+// repeatMatches 找出 aaa、abcabc 等重複片段，猜測次數為片段本身的強度乘以重複次數
+func repeatMatches(runes, lower []rune, add func(start, end int, guesses float64)) {
+	for i := range lower {
+		for size := 1; i+size*2 <= len(lower); size++ {
+			block := string(lower[i : i+size])
+			// 只處理最短的重複單位，例如 aaaa 以 a 計算而非 aa
+			if !isPrimitive(block) {
+				continue
+			}
+
+			count := 1
+			for i+(count+1)*size <= len(lower) && string(lower[i+count*size:i+(count+1)*size]) == block {
+				count++
+			}
+			if count < 2 || (size == 1 && count < 3) {
+				continue
+			}
+
+			blockGuesses := math.Pow(10, minimumGuessesLog10(runes[i:i+size], nil))
+			add(i, i+count*size, blockGuesses*float64(count))
+		}
+	}
+}
+
+func isPrimitive(block string) bool {
+	doubled := block + block
+	return !strings.Contains(doubled[1:len(doubled)-1], block)
+}
+
+// yearMatches 找出 1900–2099 的年份，越接近今年越容易被猜中
+func yearMatches(lower []rune, add func(start, end int, guesses float64)) {
+	currentYear := time.Now().Year()
+	for i := 0; i+4 <= len(lower); i++ {
+		year, err := strconv.Atoi(string(lower[i : i+4]))
+		if err != nil || year < 1900 || year > 2099 {
+			continue
+		}
+
+		space := currentYear - year
+		if space < 0 {
+			space = -space
+		}
+		if space < minYearSpace {
+			space = minYearSpace
+		}
+		add(i, i+4, float64(space))
+	}
+}
+
+// uppercaseVariations 估算大小寫變化使猜測次數增加的倍數
+func uppercaseVariations(word []rune) float64 {
+	upper, lower := 0, 0
+	for _, r := range word {
+		switch {
+		case unicode.IsUpper(r):
+			upper++
+		case unicode.IsLower(r):
+			lower++
+		}
+	}
+
+	if upper == 0 {
+		return 1
+	}
+	// 全大寫、僅首字或尾字大寫是最常見的變化
+	if lower == 0 || (upper == 1 && (unicode.IsUpper(word[0]) || unicode.IsUpper(word[len(word)-1]))) {
+		return 2
+	}
+
+	variations := 0.0
+	for k := 1; k <= upper && k <= lower; k++ {
+		variations += binomial(upper+lower, k)
+	}
+	return variations
+}
+
+func binomial(n, k int) float64 {
+	result := 1.0
+	for i := 1; i <= k; i++ {
+		result = result * float64(n-k+i) / float64(i)
+	}
+	return result
+}
+
+func unleet(word string) string {
+	return strings.Map(func(r rune) rune {
+		if replacement, ok := l33tTable[r]; ok {
+			return replacement
+		}
+		return r
+	}, word)
+}
+
+func reverse(word string) string {
+	runes := []rune(word)
+	for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+		runes[i], runes[j] = runes[j], runes[i]
+	}
+	return string(runes)
+}
+
+// userTokens 將用戶名、email 等個人資料拆成小寫單字；email 僅取 @ 之前的部分
+func userTokens(userInputs []string) []string {
+	var tokens []string
+	for _, input := range userInputs {
+		input = strings.ToLower(strings.TrimSpace(input))
+		if at := strings.LastIndex(input, "@"); at >= 0 {
+			input = input[:at]
+		}
+		if input == "" {
+			continue
+		}
+
+		tokens = append(tokens, input)
+		parts := strings.FieldsFunc(input, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+		if len(parts) > 1 {
+			tokens = append(tokens, parts...)
+		}
+	}
+	return tokens
+}
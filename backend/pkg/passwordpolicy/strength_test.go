@@ -0,0 +1,56 @@
+package passwordpolicy
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEstimateStrength(t *testing.T) {
+	tests := []struct {
+		name       string
+		password   string
+		userInputs []string
+		maxScore   int
+		minScore   int
+	}{
+		{name: "常見密碼", password: "password", maxScore: 0},
+		{name: "常見密碼加連續數字", password: "password123", maxScore: 1},
+		{name: "l33t 替換", password: "P@ssw0rd!", maxScore: 0},
+		{name: "鍵盤排列", password: "qwertyuiop", maxScore: 0},
+		{name: "重複字元", password: "aaaaaaaaaa", maxScore: 0},
+		{name: "重複片段", password: "abcabcabc", maxScore: 0},
+		{name: "季節加年份", password: "Summer2024!", maxScore: 1},
+		{name: "用戶名加年份", password: "testuser2024", userInputs: []string{"testuser"}, maxScore: 1},
+		{name: "隨機字元", password: "gH7#kLq9!vZ", minScore: 4, maxScore: 4},
+		{name: "多個不相關單字", password: "correct horse battery staple", minScore: 4, maxScore: 4},
+		{name: "空密碼", password: "", maxScore: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			strength := EstimateStrength(tt.password, tt.userInputs...)
+			if strength.Score < tt.minScore || strength.Score > tt.maxScore {
+				t.Errorf("EstimateStrength(%q) score = %d (log10 guesses %.2f), want %d–%d",
+					tt.password, strength.Score, strength.GuessesLog10, tt.minScore, tt.maxScore)
+			}
+		})
+	}
+}
+
+func TestEstimateStrength_UserInputsLowerScore(t *testing.T) {
+	password := "mei-ling.chen7"
+
+	without := EstimateStrength(password)
+	with := EstimateStrength(password, "meiling", "mei-ling.chen@example.com")
+
+	if with.GuessesLog10 >= without.GuessesLog10 {
+		t.Errorf("EstimateStrength() with user inputs = %.2f, want less than %.2f", with.GuessesLog10, without.GuessesLog10)
+	}
+}
+
+func TestEstimateStrength_LongPassword(t *testing.T) {
+	strength := EstimateStrength(strings.Repeat("ab", 5000))
+	if strength.Score != 4 {
+		t.Errorf("EstimateStrength() score = %d, want 4 for characters beyond the estimate limit", strength.Score)
+	}
+}
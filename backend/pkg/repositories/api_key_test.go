@@ -0,0 +1,230 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"smart-learning-backend/pkg/models"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+var apiKeyColumns = []string{"id", "user_id", "name", "prefix", "key_hash", "scopes",
+	"last_used_at", "expires_at", "revoked_at", "created_at"}
+
+func TestAPIKeyRepository_CreateAPIKey(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewAPIKeyRepository(db)
+
+	tests := []struct {
+		name      string
+		mockSetup func()
+		wantError bool
+		errorMsg  string
+	}{
+		{
+			name: "成功建立 API 金鑰",
+			mockSetup: func() {
+				rows := sqlmock.NewRows([]string{"id", "created_at"}).AddRow(1, time.Now())
+				mock.ExpectQuery(`INSERT INTO api_keys`).
+					WithArgs(1, "匯入腳本", "slk_abcdefgh", "hash", sqlmock.AnyArg(), nil).
+					WillReturnRows(rows)
+			},
+			wantError: false,
+		},
+		{
+			name: "數據庫錯誤",
+			mockSetup: func() {
+				mock.ExpectQuery(`INSERT INTO api_keys`).
+					WithArgs(1, "匯入腳本", "slk_abcdefgh", "hash", sqlmock.AnyArg(), nil).
+					WillReturnError(errors.New("database connection failed"))
+			},
+			wantError: true,
+			errorMsg:  "failed to create api key",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			key := &models.APIKey{
+				UserID:  1,
+				Name:    "匯入腳本",
+				Prefix:  "slk_abcdefgh",
+				KeyHash: "hash",
+				Scopes:  []string{models.PermissionLearningRead},
+			}
+			err := repo.CreateAPIKey(context.Background(), key)
+
+			if tt.wantError {
+				if err == nil {
+					t.Error("CreateAPIKey() expected error but got nil")
+					return
+				}
+				if tt.errorMsg != "" && !contains(err.Error(), tt.errorMsg) {
+					t.Errorf("CreateAPIKey() error = %v, expected to contain %v", err.Error(), tt.errorMsg)
+				}
+			} else {
+				if err != nil {
+					t.Errorf("CreateAPIKey() unexpected error = %v", err)
+					return
+				}
+				if key.ID == 0 {
+					t.Error("CreateAPIKey() did not set ID")
+				}
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("Unfulfilled expectations: %v", err)
+			}
+		})
+	}
+}
+
+func TestAPIKeyRepository_GetAPIKeyByHash(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewAPIKeyRepository(db)
+
+	tests := []struct {
+		name      string
+		mockSetup func()
+		wantError bool
+		errorMsg  string
+	}{
+		{
+			name: "成功獲取 API 金鑰",
+			mockSetup: func() {
+				rows := sqlmock.NewRows(apiKeyColumns).
+					AddRow(1, 1, "匯入腳本", "slk_abcdefgh", "hash", "{learning:read,learning:write}", nil, nil, nil, time.Now())
+				mock.ExpectQuery(`SELECT (.+) FROM api_keys WHERE key_hash`).
+					WithArgs("hash").
+					WillReturnRows(rows)
+			},
+			wantError: false,
+		},
+		{
+			name: "API 金鑰不存在",
+			mockSetup: func() {
+				mock.ExpectQuery(`SELECT (.+) FROM api_keys WHERE key_hash`).
+					WithArgs("hash").
+					WillReturnError(sql.ErrNoRows)
+			},
+			wantError: true,
+			errorMsg:  "api key not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			key, err := repo.GetAPIKeyByHash(context.Background(), "hash")
+
+			if tt.wantError {
+				if err == nil {
+					t.Error("GetAPIKeyByHash() expected error but got nil")
+					return
+				}
+				if tt.errorMsg != "" && !contains(err.Error(), tt.errorMsg) {
+					t.Errorf("GetAPIKeyByHash() error = %v, expected to contain %v", err.Error(), tt.errorMsg)
+				}
+			} else {
+				if err != nil {
+					t.Errorf("GetAPIKeyByHash() unexpected error = %v", err)
+					return
+				}
+				if len(key.Scopes) != 2 || key.Scopes[1] != models.PermissionLearningWrite {
+					t.Errorf("GetAPIKeyByHash() scopes = %v", key.Scopes)
+				}
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("Unfulfilled expectations: %v", err)
+			}
+		})
+	}
+}
+
+func TestAPIKeyRepository_ListAPIKeys(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewAPIKeyRepository(db)
+
+	rows := sqlmock.NewRows(apiKeyColumns).
+		AddRow(2, 1, "新金鑰", "slk_22222222", "hash2", "{learning:read}", time.Now(), nil, nil, time.Now()).
+		AddRow(1, 1, "舊金鑰", "slk_11111111", "hash1", "{}", nil, time.Now().Add(time.Hour), nil, time.Now())
+	mock.ExpectQuery(`SELECT (.+) FROM api_keys WHERE user_id`).
+		WithArgs(1).
+		WillReturnRows(rows)
+
+	keys, err := repo.ListAPIKeys(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("ListAPIKeys() unexpected error = %v", err)
+	}
+	if len(keys) != 2 || keys[0].Name != "新金鑰" || keys[1].ExpiresAt == nil {
+		t.Errorf("ListAPIKeys() = %+v", keys)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestAPIKeyRepository_RevokeAPIKey(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewAPIKeyRepository(db)
+
+	tests := []struct {
+		name      string
+		affected  int64
+		wantError bool
+		errorMsg  string
+	}{
+		{name: "成功撤銷", affected: 1, wantError: false},
+		{name: "金鑰不存在或不屬於該用戶", affected: 0, wantError: true, errorMsg: "api key not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock.ExpectExec(`UPDATE api_keys`).
+				WithArgs(3, 1).
+				WillReturnResult(sqlmock.NewResult(0, tt.affected))
+
+			err := repo.RevokeAPIKey(context.Background(), 1, 3)
+
+			if tt.wantError {
+				if err == nil || !contains(err.Error(), tt.errorMsg) {
+					t.Errorf("RevokeAPIKey() error = %v, expected to contain %v", err, tt.errorMsg)
+				}
+			} else if err != nil {
+				t.Errorf("RevokeAPIKey() unexpected error = %v", err)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("Unfulfilled expectations: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,180 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"smart-learning-backend/pkg/models"
+	"strconv"
+	"strings"
+)
+
+// auditLogColumns 為查詢稽核紀錄時選取的欄位，順序與 scanAuditLogEntry 一致
+const auditLogColumns = `id, event_type, actor_id, target_user_id, ip_address, user_agent, request_id, metadata, created_at`
+
+type AuditLogRepository struct {
+	db *sql.DB
+}
+
+func NewAuditLogRepository(db *sql.DB) *AuditLogRepository {
+	return &AuditLogRepository{db: db}
+}
+
+func (r *AuditLogRepository) CreateAuditLogEntry(ctx context.Context, entry *models.AuditLogEntry) error {
+	metadata := entry.Metadata
+	if metadata == nil {
+		metadata = map[string]string{}
+	}
+	metadataJSON, err := json.Marshal(metadata)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit log metadata: %w", err)
+	}
+
+	query := `
+		INSERT INTO audit_log (event_type, actor_id, target_user_id, ip_address, user_agent, request_id, metadata)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at
+	`
+
+	err = r.db.QueryRowContext(ctx,
+		query,
+		entry.EventType,
+		entry.ActorID,
+		entry.TargetUserID,
+		entry.IPAddress,
+		entry.UserAgent,
+		entry.RequestID,
+		metadataJSON,
+	).Scan(&entry.ID, &entry.CreatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create audit log entry: %w", err)
+	}
+
+	return nil
+}
+
+// ListAuditLogEntries 依條件查詢稽核紀錄並分頁，新的排在前面
+func (r *AuditLogRepository) ListAuditLogEntries(ctx context.Context, query *models.AuditLogQuery) ([]models.AuditLogEntry, int, error) {
+	where, args := auditLogConditions(query)
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM audit_log`+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count audit log entries: %w", err)
+	}
+
+	sqlQuery := `SELECT ` + auditLogColumns + ` FROM audit_log` + where +
+		` ORDER BY created_at DESC, id DESC` +
+		` LIMIT $` + strconv.Itoa(len(args)+1) + ` OFFSET $` + strconv.Itoa(len(args)+2)
+	args = append(args, query.PageSize, (query.Page-1)*query.PageSize)
+
+	entries := []models.AuditLogEntry{}
+	err := r.eachAuditLogEntry(ctx, sqlQuery, args, func(entry *models.AuditLogEntry) error {
+		entries = append(entries, *entry)
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return entries, total, nil
+}
+
+// ExportAuditLogEntries 依時間先後逐筆讀出符合條件的紀錄；fn 回傳錯誤時停止並回傳該錯誤
+func (r *AuditLogRepository) ExportAuditLogEntries(ctx context.Context, query *models.AuditLogQuery, fn func(entry *models.AuditLogEntry) error) error {
+	where, args := auditLogConditions(query)
+	sqlQuery := `SELECT ` + auditLogColumns + ` FROM audit_log` + where + ` ORDER BY created_at, id`
+
+	return r.eachAuditLogEntry(ctx, sqlQuery, args, fn)
+}
+
+func (r *AuditLogRepository) eachAuditLogEntry(ctx context.Context, query string, args []interface{}, fn func(entry *models.AuditLogEntry) error) error {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to list audit log entries: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var entry models.AuditLogEntry
+		if err := scanAuditLogEntry(rows, &entry); err != nil {
+			return fmt.Errorf("failed to scan audit log entry: %w", err)
+		}
+		if err := fn(&entry); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to list audit log entries: %w", err)
+	}
+
+	return nil
+}
+
+// auditLogConditions 將查詢條件轉換為 WHERE 子句與對應的參數
+func auditLogConditions(query *models.AuditLogQuery) (string, []interface{}) {
+	conditions := []string{}
+	args := []interface{}{}
+	addCondition := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, strings.ReplaceAll(condition, "?", "$"+strconv.Itoa(len(args))))
+	}
+
+	if query.EventType != "" {
+		addCondition("event_type = ?", query.EventType)
+	}
+	if query.ActorID != 0 {
+		addCondition("actor_id = ?", query.ActorID)
+	}
+	if query.UserID != 0 {
+		addCondition("(actor_id = ? OR target_user_id = ?)", query.UserID)
+	}
+	if query.From != nil {
+		addCondition("created_at >= ?", *query.From)
+	}
+	if query.To != nil {
+		addCondition("created_at < ?", *query.To)
+	}
+
+	if len(conditions) == 0 {
+		return "", args
+	}
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
+
+func scanAuditLogEntry(row rowScanner, entry *models.AuditLogEntry) error {
+	var actorID, targetUserID sql.NullInt64
+	var metadata []byte
+
+	err := row.Scan(
+		&entry.ID,
+		&entry.EventType,
+		&actorID,
+		&targetUserID,
+		&entry.IPAddress,
+		&entry.UserAgent,
+		&entry.RequestID,
+		&metadata,
+		&entry.CreatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	if actorID.Valid {
+		id := int(actorID.Int64)
+		entry.ActorID = &id
+	}
+	if targetUserID.Valid {
+		id := int(targetUserID.Int64)
+		entry.TargetUserID = &id
+	}
+	if len(metadata) > 0 {
+		if err := json.Unmarshal(metadata, &entry.Metadata); err != nil {
+			return fmt.Errorf("failed to decode metadata: %w", err)
+		}
+	}
+
+	return nil
+}
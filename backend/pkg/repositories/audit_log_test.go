@@ -0,0 +1,174 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"smart-learning-backend/pkg/models"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+var auditLogTestColumns = []string{"id", "event_type", "actor_id", "target_user_id", "ip_address", "user_agent", "request_id", "metadata", "created_at"}
+
+func TestAuditLogRepository_CreateAuditLogEntry(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewAuditLogRepository(db)
+	now := time.Now()
+
+	t.Run("寫入紀錄", func(t *testing.T) {
+		entry := models.NewAuditLogEntry(models.AuditEventRoleChange, models.ClientInfo{
+			UserAgent: "Mozilla/5.0",
+			IPAddress: "203.0.113.1",
+			RequestID: "req-1",
+		}, 2, 1, map[string]string{"role": "teacher"})
+
+		mock.ExpectQuery(`INSERT INTO audit_log`).
+			WithArgs(models.AuditEventRoleChange, 2, 1, "203.0.113.1", "Mozilla/5.0", "req-1", []byte(`{"role":"teacher"}`)).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(7, now))
+
+		if err := repo.CreateAuditLogEntry(context.Background(), entry); err != nil {
+			t.Fatalf("CreateAuditLogEntry() unexpected error = %v", err)
+		}
+		if entry.ID != 7 || !entry.CreatedAt.Equal(now) {
+			t.Errorf("CreateAuditLogEntry() entry = %+v", entry)
+		}
+	})
+
+	t.Run("未登入時不記錄執行者", func(t *testing.T) {
+		entry := models.NewAuditLogEntry(models.AuditEventLoginFailure, models.ClientInfo{}, 0, 0, nil)
+
+		mock.ExpectQuery(`INSERT INTO audit_log`).
+			WithArgs(models.AuditEventLoginFailure, nil, nil, "", "", "", []byte(`{}`)).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(8, now))
+
+		if err := repo.CreateAuditLogEntry(context.Background(), entry); err != nil {
+			t.Fatalf("CreateAuditLogEntry() unexpected error = %v", err)
+		}
+	})
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestAuditLogRepository_ListAuditLogEntries(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewAuditLogRepository(db)
+	now := time.Date(2025, 2, 2, 0, 0, 0, 0, time.UTC)
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("組合篩選條件", func(t *testing.T) {
+		query := &models.AuditLogQuery{
+			EventType: models.AuditEventLoginFailure,
+			UserID:    1,
+			From:      &from,
+			To:        &to,
+			Page:      2,
+			PageSize:  10,
+		}
+		where := `WHERE event_type = \$1 AND \(actor_id = \$2 OR target_user_id = \$2\) AND created_at >= \$3 AND created_at < \$4`
+
+		mock.ExpectQuery(`SELECT COUNT\(\*\) FROM audit_log `+where).
+			WithArgs(models.AuditEventLoginFailure, 1, from, to).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(11))
+		mock.ExpectQuery(`SELECT (.+) FROM audit_log `+where+` ORDER BY created_at DESC, id DESC LIMIT \$5 OFFSET \$6`).
+			WithArgs(models.AuditEventLoginFailure, 1, from, to, 10, 10).
+			WillReturnRows(sqlmock.NewRows(auditLogTestColumns).
+				AddRow(3, models.AuditEventLoginFailure, nil, 1, "203.0.113.1", "curl", "req-3", []byte(`{"reason":"invalid_credentials"}`), now))
+
+		entries, total, err := repo.ListAuditLogEntries(context.Background(), query)
+		if err != nil {
+			t.Fatalf("ListAuditLogEntries() unexpected error = %v", err)
+		}
+		if total != 11 || len(entries) != 1 {
+			t.Fatalf("ListAuditLogEntries() = %+v, total %d", entries, total)
+		}
+		entry := entries[0]
+		if entry.ActorID != nil || entry.TargetUserID == nil || *entry.TargetUserID != 1 || entry.Metadata["reason"] != "invalid_credentials" {
+			t.Errorf("ListAuditLogEntries() entry = %+v", entry)
+		}
+	})
+
+	t.Run("未指定條件", func(t *testing.T) {
+		mock.ExpectQuery(`SELECT COUNT\(\*\) FROM audit_log$`).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+		mock.ExpectQuery(`SELECT (.+) FROM audit_log ORDER BY created_at DESC, id DESC LIMIT \$1 OFFSET \$2`).
+			WithArgs(50, 0).
+			WillReturnRows(sqlmock.NewRows(auditLogTestColumns))
+
+		entries, total, err := repo.ListAuditLogEntries(context.Background(), &models.AuditLogQuery{Page: 1, PageSize: 50})
+		if err != nil || total != 0 || entries == nil || len(entries) != 0 {
+			t.Errorf("ListAuditLogEntries() = %v, %d, %v", entries, total, err)
+		}
+	})
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestAuditLogRepository_ExportAuditLogEntries(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewAuditLogRepository(db)
+	now := time.Now()
+	rows := func() *sqlmock.Rows {
+		return sqlmock.NewRows(auditLogTestColumns).
+			AddRow(1, models.AuditEventRegister, 1, 1, "", "", "", []byte(`{}`), now).
+			AddRow(2, models.AuditEventLogout, 1, 1, "", "", "", []byte(`{}`), now)
+	}
+
+	t.Run("依時間先後逐筆讀出", func(t *testing.T) {
+		mock.ExpectQuery(`SELECT (.+) FROM audit_log WHERE actor_id = \$1 ORDER BY created_at, id$`).
+			WithArgs(1).
+			WillReturnRows(rows())
+
+		ids := []int64{}
+		err := repo.ExportAuditLogEntries(context.Background(), &models.AuditLogQuery{ActorID: 1}, func(entry *models.AuditLogEntry) error {
+			ids = append(ids, entry.ID)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("ExportAuditLogEntries() unexpected error = %v", err)
+		}
+		if len(ids) != 2 || ids[0] != 1 || ids[1] != 2 {
+			t.Errorf("ExportAuditLogEntries() ids = %v", ids)
+		}
+	})
+
+	t.Run("回呼失敗時停止", func(t *testing.T) {
+		mock.ExpectQuery(`SELECT (.+) FROM audit_log ORDER BY created_at, id$`).
+			WillReturnRows(rows())
+
+		writeErr := errors.New("client disconnected")
+		calls := 0
+		err := repo.ExportAuditLogEntries(context.Background(), &models.AuditLogQuery{}, func(entry *models.AuditLogEntry) error {
+			calls++
+			return writeErr
+		})
+		if !errors.Is(err, writeErr) || calls != 1 {
+			t.Errorf("ExportAuditLogEntries() error = %v after %d calls", err, calls)
+		}
+	})
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
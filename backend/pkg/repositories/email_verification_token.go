@@ -0,0 +1,102 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"smart-learning-backend/pkg/apperrors"
+	"smart-learning-backend/pkg/models"
+)
+
+type EmailVerificationTokenRepository struct {
+	db *sql.DB
+}
+
+func NewEmailVerificationTokenRepository(db *sql.DB) *EmailVerificationTokenRepository {
+	return &EmailVerificationTokenRepository{db: db}
+}
+
+func (r *EmailVerificationTokenRepository) CreateEmailVerificationToken(ctx context.Context, token *models.EmailVerificationToken) error {
+	query := `
+		INSERT INTO email_verification_tokens (user_id, token_hash, new_email, expires_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query, token.UserID, token.TokenHash, token.NewEmail, token.ExpiresAt).
+		Scan(&token.ID, &token.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create email verification token: %w", err)
+	}
+
+	return nil
+}
+
+func (r *EmailVerificationTokenRepository) GetEmailVerificationTokenByHash(ctx context.Context, tokenHash string) (*models.EmailVerificationToken, error) {
+	token := &models.EmailVerificationToken{}
+	query := `
+		SELECT id, user_id, token_hash, new_email, expires_at, used_at, created_at
+		FROM email_verification_tokens
+		WHERE token_hash = $1
+	`
+
+	err := r.db.QueryRowContext(ctx, query, tokenHash).Scan(
+		&token.ID,
+		&token.UserID,
+		&token.TokenHash,
+		&token.NewEmail,
+		&token.ExpiresAt,
+		&token.UsedAt,
+		&token.CreatedAt,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, apperrors.ErrEmailVerificationTokenNotFound
+		}
+		return nil, fmt.Errorf("failed to get email verification token: %w", err)
+	}
+
+	return token, nil
+}
+
+// MarkEmailVerificationTokenUsed 將 token 標記為已使用。
+// 若 token 已被使用（例如併發請求），回傳 apperrors.ErrEmailVerificationTokenAlreadyUsed。
+func (r *EmailVerificationTokenRepository) MarkEmailVerificationTokenUsed(ctx context.Context, id int) error {
+	query := `
+		UPDATE email_verification_tokens
+		SET used_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND used_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to mark email verification token used: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to mark email verification token used: %w", err)
+	}
+	if affected == 0 {
+		return apperrors.ErrEmailVerificationTokenAlreadyUsed
+	}
+
+	return nil
+}
+
+// InvalidateUserEmailVerificationTokens 使用戶尚未使用的同用途驗證 token 失效；
+// emailChange 為 true 時只處理變更 email 的 token（new_email 不為空），否則只處理註冊驗證的 token
+func (r *EmailVerificationTokenRepository) InvalidateUserEmailVerificationTokens(ctx context.Context, userID int, emailChange bool) error {
+	query := `
+		UPDATE email_verification_tokens
+		SET used_at = CURRENT_TIMESTAMP
+		WHERE user_id = $1 AND used_at IS NULL AND (new_email IS NOT NULL) = $2
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, userID, emailChange); err != nil {
+		return fmt.Errorf("failed to invalidate email verification tokens: %w", err)
+	}
+
+	return nil
+}
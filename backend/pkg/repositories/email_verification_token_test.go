@@ -0,0 +1,175 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"smart-learning-backend/pkg/models"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestEmailVerificationTokenRepository_CreateEmailVerificationToken(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewEmailVerificationTokenRepository(db)
+	expiresAt := time.Now().Add(time.Hour)
+
+	mock.ExpectQuery(`INSERT INTO email_verification_tokens`).
+		WithArgs(1, "hash", nil, expiresAt).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(1, time.Now()))
+
+	token := &models.EmailVerificationToken{UserID: 1, TokenHash: "hash", ExpiresAt: expiresAt}
+	if err := repo.CreateEmailVerificationToken(context.Background(), token); err != nil {
+		t.Fatalf("CreateEmailVerificationToken() unexpected error = %v", err)
+	}
+	if token.ID != 1 {
+		t.Errorf("CreateEmailVerificationToken() ID = %v, want 1", token.ID)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestEmailVerificationTokenRepository_GetEmailVerificationTokenByHash(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewEmailVerificationTokenRepository(db)
+
+	tests := []struct {
+		name      string
+		mockSetup func()
+		wantError bool
+		errorMsg  string
+	}{
+		{
+			name: "成功獲取驗證 token",
+			mockSetup: func() {
+				rows := sqlmock.NewRows([]string{"id", "user_id", "token_hash", "new_email", "expires_at", "used_at", "created_at"}).
+					AddRow(1, 1, "hash", nil, time.Now().Add(time.Hour), nil, time.Now())
+				mock.ExpectQuery(`SELECT (.+) FROM email_verification_tokens WHERE token_hash`).
+					WithArgs("hash").
+					WillReturnRows(rows)
+			},
+			wantError: false,
+		},
+		{
+			name: "驗證 token 不存在",
+			mockSetup: func() {
+				mock.ExpectQuery(`SELECT (.+) FROM email_verification_tokens WHERE token_hash`).
+					WithArgs("hash").
+					WillReturnError(sql.ErrNoRows)
+			},
+			wantError: true,
+			errorMsg:  "email verification token not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			token, err := repo.GetEmailVerificationTokenByHash(context.Background(), "hash")
+
+			if tt.wantError {
+				if err == nil {
+					t.Error("GetEmailVerificationTokenByHash() expected error but got nil")
+					return
+				}
+				if tt.errorMsg != "" && !contains(err.Error(), tt.errorMsg) {
+					t.Errorf("GetEmailVerificationTokenByHash() error = %v, expected to contain %v", err.Error(), tt.errorMsg)
+				}
+			} else {
+				if err != nil {
+					t.Errorf("GetEmailVerificationTokenByHash() unexpected error = %v", err)
+					return
+				}
+				if token.UserID != 1 {
+					t.Errorf("GetEmailVerificationTokenByHash() user_id = %v, want 1", token.UserID)
+				}
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("Unfulfilled expectations: %v", err)
+			}
+		})
+	}
+}
+
+func TestEmailVerificationTokenRepository_MarkEmailVerificationTokenUsed(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewEmailVerificationTokenRepository(db)
+
+	tests := []struct {
+		name      string
+		affected  int64
+		wantError bool
+		errorMsg  string
+	}{
+		{name: "成功標記為已使用", affected: 1, wantError: false},
+		{name: "token 已被使用", affected: 0, wantError: true, errorMsg: "email verification token already used"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock.ExpectExec(`UPDATE email_verification_tokens SET used_at`).
+				WithArgs(1).
+				WillReturnResult(sqlmock.NewResult(0, tt.affected))
+
+			err := repo.MarkEmailVerificationTokenUsed(context.Background(), 1)
+
+			if tt.wantError {
+				if err == nil {
+					t.Error("MarkEmailVerificationTokenUsed() expected error but got nil")
+					return
+				}
+				if !contains(err.Error(), tt.errorMsg) {
+					t.Errorf("MarkEmailVerificationTokenUsed() error = %v, expected to contain %v", err.Error(), tt.errorMsg)
+				}
+			} else if err != nil {
+				t.Errorf("MarkEmailVerificationTokenUsed() unexpected error = %v", err)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("Unfulfilled expectations: %v", err)
+			}
+		})
+	}
+}
+
+func TestEmailVerificationTokenRepository_InvalidateUserEmailVerificationTokens(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewEmailVerificationTokenRepository(db)
+
+	mock.ExpectExec(`UPDATE email_verification_tokens SET used_at`).
+		WithArgs(1, true).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	if err := repo.InvalidateUserEmailVerificationTokens(context.Background(), 1, true); err != nil {
+		t.Errorf("InvalidateUserEmailVerificationTokens() unexpected error = %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
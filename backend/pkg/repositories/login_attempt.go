@@ -0,0 +1,110 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"smart-learning-backend/pkg/apperrors"
+	"smart-learning-backend/pkg/models"
+	"time"
+)
+
+type LoginAttemptRepository struct {
+	db *sql.DB
+}
+
+func NewLoginAttemptRepository(db *sql.DB) *LoginAttemptRepository {
+	return &LoginAttemptRepository{db: db}
+}
+
+func (r *LoginAttemptRepository) GetLoginAttempt(ctx context.Context, key string) (*models.LoginAttempt, error) {
+	attempt := &models.LoginAttempt{}
+	query := `
+		SELECT key, failures, locked_until, last_failed_at
+		FROM login_attempts
+		WHERE key = $1
+	`
+
+	err := r.db.QueryRowContext(ctx, query, key).Scan(
+		&attempt.Key,
+		&attempt.Failures,
+		&attempt.LockedUntil,
+		&attempt.LastFailedAt,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, apperrors.ErrLoginAttemptNotFound
+		}
+		return nil, fmt.Errorf("failed to get login attempt: %w", err)
+	}
+
+	return attempt, nil
+}
+
+func (r *LoginAttemptRepository) RecordFailedLogin(ctx context.Context, key string, resetBefore time.Time) (*models.LoginAttempt, error) {
+	attempt := &models.LoginAttempt{}
+	query := `
+		INSERT INTO login_attempts (key, failures, last_failed_at)
+		VALUES ($1, 1, CURRENT_TIMESTAMP)
+		ON CONFLICT (key) DO UPDATE
+		SET failures = CASE
+				WHEN login_attempts.last_failed_at < $2 THEN 1
+				ELSE login_attempts.failures + 1
+			END,
+			last_failed_at = CURRENT_TIMESTAMP
+		RETURNING key, failures, locked_until, last_failed_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query, key, resetBefore).Scan(
+		&attempt.Key,
+		&attempt.Failures,
+		&attempt.LockedUntil,
+		&attempt.LastFailedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record login attempt: %w", err)
+	}
+
+	return attempt, nil
+}
+
+func (r *LoginAttemptRepository) LockLogin(ctx context.Context, key string, until time.Time) error {
+	query := `UPDATE login_attempts SET locked_until = $2 WHERE key = $1`
+
+	if _, err := r.db.ExecContext(ctx, query, key, until); err != nil {
+		return fmt.Errorf("failed to lock login: %w", err)
+	}
+
+	return nil
+}
+
+func (r *LoginAttemptRepository) ClearLoginAttempts(ctx context.Context, key string) error {
+	query := `DELETE FROM login_attempts WHERE key = $1`
+
+	if _, err := r.db.ExecContext(ctx, query, key); err != nil {
+		return fmt.Errorf("failed to clear login attempts: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteStaleLoginAttempts 刪除最後失敗時間早於 before 且已解除鎖定的紀錄
+func (r *LoginAttemptRepository) DeleteStaleLoginAttempts(ctx context.Context, before time.Time) (int64, error) {
+	query := `
+		DELETE FROM login_attempts
+		WHERE last_failed_at < $1 AND (locked_until IS NULL OR locked_until < CURRENT_TIMESTAMP)
+	`
+
+	result, err := r.db.ExecContext(ctx, query, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete stale login attempts: %w", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete stale login attempts: %w", err)
+	}
+
+	return deleted, nil
+}
@@ -0,0 +1,116 @@
+package repositories
+
+import (
+	"context"
+	"smart-learning-backend/pkg/apperrors"
+	"smart-learning-backend/pkg/models"
+	"sync"
+	"time"
+)
+
+// MemoryLoginAttemptRepository 以記憶體保存登入失敗紀錄，適用於單一實例部署與測試。
+// 背景 goroutine 會定期清除超過 retention 且未鎖定的紀錄。
+type MemoryLoginAttemptRepository struct {
+	mu       sync.Mutex
+	attempts map[string]models.LoginAttempt
+	stop     chan struct{}
+	once     sync.Once
+}
+
+func NewMemoryLoginAttemptRepository(cleanupInterval, retention time.Duration) *MemoryLoginAttemptRepository {
+	r := &MemoryLoginAttemptRepository{
+		attempts: make(map[string]models.LoginAttempt),
+		stop:     make(chan struct{}),
+	}
+
+	if cleanupInterval > 0 {
+		go r.cleanupLoop(cleanupInterval, retention)
+	}
+
+	return r
+}
+
+func (r *MemoryLoginAttemptRepository) GetLoginAttempt(ctx context.Context, key string) (*models.LoginAttempt, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	attempt, ok := r.attempts[key]
+	if !ok {
+		return nil, apperrors.ErrLoginAttemptNotFound
+	}
+	return &attempt, nil
+}
+
+func (r *MemoryLoginAttemptRepository) RecordFailedLogin(ctx context.Context, key string, resetBefore time.Time) (*models.LoginAttempt, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	attempt, ok := r.attempts[key]
+	if !ok {
+		attempt = models.LoginAttempt{Key: key}
+	}
+	if ok && attempt.LastFailedAt.Before(resetBefore) {
+		attempt.Failures = 0
+	}
+	attempt.Failures++
+	attempt.LastFailedAt = time.Now()
+	r.attempts[key] = attempt
+
+	return &attempt, nil
+}
+
+func (r *MemoryLoginAttemptRepository) LockLogin(ctx context.Context, key string, until time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if attempt, ok := r.attempts[key]; ok {
+		attempt.LockedUntil = &until
+		r.attempts[key] = attempt
+	}
+	return nil
+}
+
+func (r *MemoryLoginAttemptRepository) ClearLoginAttempts(ctx context.Context, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.attempts, key)
+	return nil
+}
+
+func (r *MemoryLoginAttemptRepository) DeleteStaleLoginAttempts(ctx context.Context, before time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	var deleted int64
+	for key, attempt := range r.attempts {
+		locked := attempt.LockedUntil != nil && attempt.LockedUntil.After(now)
+		if attempt.LastFailedAt.Before(before) && !locked {
+			delete(r.attempts, key)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// Close 停止背景清理 goroutine
+func (r *MemoryLoginAttemptRepository) Close() {
+	r.once.Do(func() {
+		close(r.stop)
+	})
+}
+
+func (r *MemoryLoginAttemptRepository) cleanupLoop(interval, retention time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			r.DeleteStaleLoginAttempts(context.Background(), time.Now().Add(-retention))
+		case <-r.stop:
+			return
+		}
+	}
+}
@@ -0,0 +1,239 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestLoginAttemptRepository_GetLoginAttempt(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewLoginAttemptRepository(db)
+
+	tests := []struct {
+		name      string
+		mockSetup func()
+		wantError bool
+		errorMsg  string
+	}{
+		{
+			name: "成功獲取登入失敗紀錄",
+			mockSetup: func() {
+				rows := sqlmock.NewRows([]string{"key", "failures", "locked_until", "last_failed_at"}).
+					AddRow("account:test@example.com", 3, nil, time.Now())
+				mock.ExpectQuery(`SELECT (.+) FROM login_attempts WHERE key`).
+					WithArgs("account:test@example.com").
+					WillReturnRows(rows)
+			},
+			wantError: false,
+		},
+		{
+			name: "紀錄不存在",
+			mockSetup: func() {
+				mock.ExpectQuery(`SELECT (.+) FROM login_attempts WHERE key`).
+					WithArgs("account:test@example.com").
+					WillReturnError(sql.ErrNoRows)
+			},
+			wantError: true,
+			errorMsg:  "login attempt not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			attempt, err := repo.GetLoginAttempt(context.Background(), "account:test@example.com")
+
+			if tt.wantError {
+				if err == nil {
+					t.Error("GetLoginAttempt() expected error but got nil")
+					return
+				}
+				if tt.errorMsg != "" && !contains(err.Error(), tt.errorMsg) {
+					t.Errorf("GetLoginAttempt() error = %v, expected to contain %v", err.Error(), tt.errorMsg)
+				}
+			} else {
+				if err != nil {
+					t.Errorf("GetLoginAttempt() unexpected error = %v", err)
+					return
+				}
+				if attempt.Failures != 3 {
+					t.Errorf("GetLoginAttempt() failures = %v, want 3", attempt.Failures)
+				}
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("Unfulfilled expectations: %v", err)
+			}
+		})
+	}
+}
+
+func TestLoginAttemptRepository_RecordFailedLogin(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewLoginAttemptRepository(db)
+	resetBefore := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name      string
+		mockSetup func()
+		wantError bool
+		errorMsg  string
+	}{
+		{
+			name: "成功累加失敗次數",
+			mockSetup: func() {
+				rows := sqlmock.NewRows([]string{"key", "failures", "locked_until", "last_failed_at"}).
+					AddRow("ip:203.0.113.1", 2, nil, time.Now())
+				mock.ExpectQuery(`INSERT INTO login_attempts (.+) ON CONFLICT \(key\) DO UPDATE`).
+					WithArgs("ip:203.0.113.1", resetBefore).
+					WillReturnRows(rows)
+			},
+			wantError: false,
+		},
+		{
+			name: "數據庫錯誤",
+			mockSetup: func() {
+				mock.ExpectQuery(`INSERT INTO login_attempts`).
+					WithArgs("ip:203.0.113.1", resetBefore).
+					WillReturnError(errors.New("database connection failed"))
+			},
+			wantError: true,
+			errorMsg:  "failed to record login attempt",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			attempt, err := repo.RecordFailedLogin(context.Background(), "ip:203.0.113.1", resetBefore)
+
+			if tt.wantError {
+				if err == nil {
+					t.Error("RecordFailedLogin() expected error but got nil")
+					return
+				}
+				if tt.errorMsg != "" && !contains(err.Error(), tt.errorMsg) {
+					t.Errorf("RecordFailedLogin() error = %v, expected to contain %v", err.Error(), tt.errorMsg)
+				}
+			} else {
+				if err != nil {
+					t.Errorf("RecordFailedLogin() unexpected error = %v", err)
+					return
+				}
+				if attempt.Failures != 2 {
+					t.Errorf("RecordFailedLogin() failures = %v, want 2", attempt.Failures)
+				}
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("Unfulfilled expectations: %v", err)
+			}
+		})
+	}
+}
+
+func TestLoginAttemptRepository_LockAndClear(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewLoginAttemptRepository(db)
+	until := time.Now().Add(time.Minute)
+
+	mock.ExpectExec(`UPDATE login_attempts SET locked_until`).
+		WithArgs("account:test@example.com", until).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`DELETE FROM login_attempts WHERE key`).
+		WithArgs("account:test@example.com").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if err := repo.LockLogin(context.Background(), "account:test@example.com", until); err != nil {
+		t.Errorf("LockLogin() unexpected error = %v", err)
+	}
+	if err := repo.ClearLoginAttempts(context.Background(), "account:test@example.com"); err != nil {
+		t.Errorf("ClearLoginAttempts() unexpected error = %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestMemoryLoginAttemptRepository(t *testing.T) {
+	repo := NewMemoryLoginAttemptRepository(0, 0)
+	defer repo.Close()
+
+	key := "account:test@example.com"
+
+	if _, err := repo.GetLoginAttempt(context.Background(), key); err == nil || !contains(err.Error(), "login attempt not found") {
+		t.Errorf("GetLoginAttempt() error = %v, want login attempt not found", err)
+	}
+
+	for i := 1; i <= 3; i++ {
+		attempt, err := repo.RecordFailedLogin(context.Background(), key, time.Now().Add(-time.Hour))
+		if err != nil {
+			t.Fatalf("RecordFailedLogin() unexpected error = %v", err)
+		}
+		if attempt.Failures != i {
+			t.Errorf("RecordFailedLogin() failures = %d, want %d", attempt.Failures, i)
+		}
+	}
+
+	// 上次失敗早於 resetBefore 時重新計算
+	attempt, _ := repo.RecordFailedLogin(context.Background(), key, time.Now().Add(time.Second))
+	if attempt.Failures != 1 {
+		t.Errorf("RecordFailedLogin() after window failures = %d, want 1", attempt.Failures)
+	}
+
+	until := time.Now().Add(time.Minute)
+	repo.LockLogin(context.Background(), key, until)
+	attempt, _ = repo.GetLoginAttempt(context.Background(), key)
+	if attempt.LockedUntil == nil || !attempt.LockedUntil.Equal(until) {
+		t.Errorf("LockLogin() locked_until = %v, want %v", attempt.LockedUntil, until)
+	}
+
+	// 鎖定中的紀錄不會被清除
+	if deleted, _ := repo.DeleteStaleLoginAttempts(context.Background(), time.Now().Add(time.Hour)); deleted != 0 {
+		t.Errorf("DeleteStaleLoginAttempts() deleted %d locked records", deleted)
+	}
+
+	repo.ClearLoginAttempts(context.Background(), key)
+	if _, err := repo.GetLoginAttempt(context.Background(), key); err == nil {
+		t.Error("ClearLoginAttempts() did not remove the record")
+	}
+}
+
+func TestMemoryLoginAttemptRepository_Cleanup(t *testing.T) {
+	repo := NewMemoryLoginAttemptRepository(10*time.Millisecond, 0)
+	defer repo.Close()
+
+	repo.RecordFailedLogin(context.Background(), "ip:203.0.113.1", time.Now())
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if _, err := repo.GetLoginAttempt(context.Background(), "ip:203.0.113.1"); err != nil {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Error("cleanup loop did not remove stale record")
+}
@@ -0,0 +1,100 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"smart-learning-backend/pkg/apperrors"
+	"smart-learning-backend/pkg/models"
+)
+
+type MagicLinkTokenRepository struct {
+	db *sql.DB
+}
+
+func NewMagicLinkTokenRepository(db *sql.DB) *MagicLinkTokenRepository {
+	return &MagicLinkTokenRepository{db: db}
+}
+
+func (r *MagicLinkTokenRepository) CreateMagicLinkToken(ctx context.Context, token *models.MagicLinkToken) error {
+	query := `
+		INSERT INTO magic_link_tokens (user_id, token_hash, expires_at)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query, token.UserID, token.TokenHash, token.ExpiresAt).
+		Scan(&token.ID, &token.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create magic link token: %w", err)
+	}
+
+	return nil
+}
+
+func (r *MagicLinkTokenRepository) GetMagicLinkTokenByHash(ctx context.Context, tokenHash string) (*models.MagicLinkToken, error) {
+	token := &models.MagicLinkToken{}
+	query := `
+		SELECT id, user_id, token_hash, expires_at, used_at, created_at
+		FROM magic_link_tokens
+		WHERE token_hash = $1
+	`
+
+	err := r.db.QueryRowContext(ctx, query, tokenHash).Scan(
+		&token.ID,
+		&token.UserID,
+		&token.TokenHash,
+		&token.ExpiresAt,
+		&token.UsedAt,
+		&token.CreatedAt,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, apperrors.ErrMagicLinkTokenNotFound
+		}
+		return nil, fmt.Errorf("failed to get magic link token: %w", err)
+	}
+
+	return token, nil
+}
+
+// MarkMagicLinkTokenUsed 將 token 標記為已使用。
+// 若 token 已被使用（例如併發請求），回傳 apperrors.ErrMagicLinkTokenAlreadyUsed。
+func (r *MagicLinkTokenRepository) MarkMagicLinkTokenUsed(ctx context.Context, id int) error {
+	query := `
+		UPDATE magic_link_tokens
+		SET used_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND used_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to mark magic link token used: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to mark magic link token used: %w", err)
+	}
+	if affected == 0 {
+		return apperrors.ErrMagicLinkTokenAlreadyUsed
+	}
+
+	return nil
+}
+
+// InvalidateUserMagicLinkTokens 使用戶所有尚未使用的登入 token 失效
+func (r *MagicLinkTokenRepository) InvalidateUserMagicLinkTokens(ctx context.Context, userID int) error {
+	query := `
+		UPDATE magic_link_tokens
+		SET used_at = CURRENT_TIMESTAMP
+		WHERE user_id = $1 AND used_at IS NULL
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, userID); err != nil {
+		return fmt.Errorf("failed to invalidate magic link tokens: %w", err)
+	}
+
+	return nil
+}
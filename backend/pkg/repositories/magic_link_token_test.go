@@ -0,0 +1,175 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"smart-learning-backend/pkg/models"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestMagicLinkTokenRepository_CreateMagicLinkToken(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewMagicLinkTokenRepository(db)
+	expiresAt := time.Now().Add(time.Hour)
+
+	mock.ExpectQuery(`INSERT INTO magic_link_tokens`).
+		WithArgs(1, "hash", expiresAt).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(1, time.Now()))
+
+	token := &models.MagicLinkToken{UserID: 1, TokenHash: "hash", ExpiresAt: expiresAt}
+	if err := repo.CreateMagicLinkToken(context.Background(), token); err != nil {
+		t.Fatalf("CreateMagicLinkToken() unexpected error = %v", err)
+	}
+	if token.ID != 1 {
+		t.Errorf("CreateMagicLinkToken() ID = %v, want 1", token.ID)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestMagicLinkTokenRepository_GetMagicLinkTokenByHash(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewMagicLinkTokenRepository(db)
+
+	tests := []struct {
+		name      string
+		mockSetup func()
+		wantError bool
+		errorMsg  string
+	}{
+		{
+			name: "成功獲取登入 token",
+			mockSetup: func() {
+				rows := sqlmock.NewRows([]string{"id", "user_id", "token_hash", "expires_at", "used_at", "created_at"}).
+					AddRow(1, 1, "hash", time.Now().Add(time.Hour), nil, time.Now())
+				mock.ExpectQuery(`SELECT (.+) FROM magic_link_tokens WHERE token_hash`).
+					WithArgs("hash").
+					WillReturnRows(rows)
+			},
+			wantError: false,
+		},
+		{
+			name: "登入 token 不存在",
+			mockSetup: func() {
+				mock.ExpectQuery(`SELECT (.+) FROM magic_link_tokens WHERE token_hash`).
+					WithArgs("hash").
+					WillReturnError(sql.ErrNoRows)
+			},
+			wantError: true,
+			errorMsg:  "magic link token not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			token, err := repo.GetMagicLinkTokenByHash(context.Background(), "hash")
+
+			if tt.wantError {
+				if err == nil {
+					t.Error("GetMagicLinkTokenByHash() expected error but got nil")
+					return
+				}
+				if tt.errorMsg != "" && !contains(err.Error(), tt.errorMsg) {
+					t.Errorf("GetMagicLinkTokenByHash() error = %v, expected to contain %v", err.Error(), tt.errorMsg)
+				}
+			} else {
+				if err != nil {
+					t.Errorf("GetMagicLinkTokenByHash() unexpected error = %v", err)
+					return
+				}
+				if token.UserID != 1 {
+					t.Errorf("GetMagicLinkTokenByHash() user_id = %v, want 1", token.UserID)
+				}
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("Unfulfilled expectations: %v", err)
+			}
+		})
+	}
+}
+
+func TestMagicLinkTokenRepository_MarkMagicLinkTokenUsed(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewMagicLinkTokenRepository(db)
+
+	tests := []struct {
+		name      string
+		affected  int64
+		wantError bool
+		errorMsg  string
+	}{
+		{name: "成功標記為已使用", affected: 1, wantError: false},
+		{name: "token 已被使用", affected: 0, wantError: true, errorMsg: "magic link token already used"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock.ExpectExec(`UPDATE magic_link_tokens SET used_at`).
+				WithArgs(1).
+				WillReturnResult(sqlmock.NewResult(0, tt.affected))
+
+			err := repo.MarkMagicLinkTokenUsed(context.Background(), 1)
+
+			if tt.wantError {
+				if err == nil {
+					t.Error("MarkMagicLinkTokenUsed() expected error but got nil")
+					return
+				}
+				if !contains(err.Error(), tt.errorMsg) {
+					t.Errorf("MarkMagicLinkTokenUsed() error = %v, expected to contain %v", err.Error(), tt.errorMsg)
+				}
+			} else if err != nil {
+				t.Errorf("MarkMagicLinkTokenUsed() unexpected error = %v", err)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("Unfulfilled expectations: %v", err)
+			}
+		})
+	}
+}
+
+func TestMagicLinkTokenRepository_InvalidateUserMagicLinkTokens(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewMagicLinkTokenRepository(db)
+
+	mock.ExpectExec(`UPDATE magic_link_tokens SET used_at`).
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	if err := repo.InvalidateUserMagicLinkTokens(context.Background(), 1); err != nil {
+		t.Errorf("InvalidateUserMagicLinkTokens() unexpected error = %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
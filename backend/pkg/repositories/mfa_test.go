@@ -0,0 +1,276 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestMFARepository_GetUserMFA(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewMFARepository(db)
+
+	tests := []struct {
+		name      string
+		mockSetup func()
+		wantError bool
+		errorMsg  string
+	}{
+		{
+			name: "成功獲取兩步驟驗證設定",
+			mockSetup: func() {
+				rows := sqlmock.NewRows([]string{"user_id", "totp_secret", "enabled_at", "last_used_step", "created_at"}).
+					AddRow(1, "SECRET", time.Now(), 100, time.Now())
+				mock.ExpectQuery(`SELECT (.+) FROM user_mfa WHERE user_id`).
+					WithArgs(1).
+					WillReturnRows(rows)
+			},
+			wantError: false,
+		},
+		{
+			name: "尚未設定",
+			mockSetup: func() {
+				mock.ExpectQuery(`SELECT (.+) FROM user_mfa WHERE user_id`).
+					WithArgs(1).
+					WillReturnError(sql.ErrNoRows)
+			},
+			wantError: true,
+			errorMsg:  "mfa not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			mfa, err := repo.GetUserMFA(context.Background(), 1)
+
+			if tt.wantError {
+				if err == nil {
+					t.Error("GetUserMFA() expected error but got nil")
+					return
+				}
+				if !contains(err.Error(), tt.errorMsg) {
+					t.Errorf("GetUserMFA() error = %v, expected to contain %v", err.Error(), tt.errorMsg)
+				}
+			} else {
+				if err != nil {
+					t.Errorf("GetUserMFA() unexpected error = %v", err)
+					return
+				}
+				if mfa.Secret != "SECRET" || mfa.EnabledAt == nil {
+					t.Errorf("GetUserMFA() = %+v", mfa)
+				}
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("Unfulfilled expectations: %v", err)
+			}
+		})
+	}
+}
+
+func TestMFARepository_SaveMFASecret(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewMFARepository(db)
+
+	tests := []struct {
+		name      string
+		affected  int64
+		wantError bool
+		errorMsg  string
+	}{
+		{name: "成功儲存密鑰", affected: 1, wantError: false},
+		{name: "已啟用兩步驟驗證", affected: 0, wantError: true, errorMsg: "mfa already enabled"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock.ExpectExec(`INSERT INTO user_mfa`).
+				WithArgs(1, "SECRET").
+				WillReturnResult(sqlmock.NewResult(0, tt.affected))
+
+			err := repo.SaveMFASecret(context.Background(), 1, "SECRET")
+
+			if tt.wantError {
+				if err == nil || !contains(err.Error(), tt.errorMsg) {
+					t.Errorf("SaveMFASecret() error = %v, expected to contain %v", err, tt.errorMsg)
+				}
+			} else if err != nil {
+				t.Errorf("SaveMFASecret() unexpected error = %v", err)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("Unfulfilled expectations: %v", err)
+			}
+		})
+	}
+}
+
+func TestMFARepository_EnableMFA(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewMFARepository(db)
+
+	tests := []struct {
+		name      string
+		mockSetup func()
+		wantError bool
+		errorMsg  string
+	}{
+		{
+			name: "成功啟用",
+			mockSetup: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(`UPDATE user_mfa SET enabled_at`).
+					WithArgs(1, int64(100)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`DELETE FROM mfa_recovery_codes`).
+					WithArgs(1).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(`INSERT INTO mfa_recovery_codes`).
+					WithArgs(1, "hash-1").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec(`INSERT INTO mfa_recovery_codes`).
+					WithArgs(1, "hash-2").
+					WillReturnResult(sqlmock.NewResult(2, 1))
+				mock.ExpectCommit()
+			},
+			wantError: false,
+		},
+		{
+			name: "已啟用兩步驟驗證",
+			mockSetup: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(`UPDATE user_mfa SET enabled_at`).
+					WithArgs(1, int64(100)).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+			},
+			wantError: true,
+			errorMsg:  "mfa already enabled",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			err := repo.EnableMFA(context.Background(), 1, 100, []string{"hash-1", "hash-2"})
+
+			if tt.wantError {
+				if err == nil || !contains(err.Error(), tt.errorMsg) {
+					t.Errorf("EnableMFA() error = %v, expected to contain %v", err, tt.errorMsg)
+				}
+			} else if err != nil {
+				t.Errorf("EnableMFA() unexpected error = %v", err)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("Unfulfilled expectations: %v", err)
+			}
+		})
+	}
+}
+
+func TestMFARepository_ConsumeTOTPStep(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewMFARepository(db)
+
+	tests := []struct {
+		name     string
+		affected int64
+		want     bool
+	}{
+		{name: "新的時間區間", affected: 1, want: true},
+		{name: "重複使用的驗證碼", affected: 0, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock.ExpectExec(`UPDATE user_mfa SET last_used_step`).
+				WithArgs(1, int64(100)).
+				WillReturnResult(sqlmock.NewResult(0, tt.affected))
+
+			got, err := repo.ConsumeTOTPStep(context.Background(), 1, 100)
+			if err != nil {
+				t.Fatalf("ConsumeTOTPStep() unexpected error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ConsumeTOTPStep() = %v, want %v", got, tt.want)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("Unfulfilled expectations: %v", err)
+			}
+		})
+	}
+}
+
+func TestMFARepository_ConsumeRecoveryCode(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewMFARepository(db)
+
+	mock.ExpectExec(`UPDATE mfa_recovery_codes SET used_at`).
+		WithArgs(1, "hash").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	got, err := repo.ConsumeRecoveryCode(context.Background(), 1, "hash")
+	if err != nil || !got {
+		t.Errorf("ConsumeRecoveryCode() = %v, %v, want true, nil", got, err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestMFARepository_DeleteUserMFA(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewMFARepository(db)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`DELETE FROM mfa_recovery_codes`).WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 10))
+	mock.ExpectExec(`DELETE FROM user_mfa`).WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	if err := repo.DeleteUserMFA(context.Background(), 1); err != nil {
+		t.Errorf("DeleteUserMFA() unexpected error = %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
@@ -0,0 +1,75 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"smart-learning-backend/pkg/apperrors"
+	"smart-learning-backend/pkg/models"
+	"time"
+)
+
+type OIDCAuthStateRepository struct {
+	db *sql.DB
+}
+
+func NewOIDCAuthStateRepository(db *sql.DB) *OIDCAuthStateRepository {
+	return &OIDCAuthStateRepository{db: db}
+}
+
+func (r *OIDCAuthStateRepository) CreateOIDCAuthState(ctx context.Context, state *models.OIDCAuthState) error {
+	query := `
+		INSERT INTO oidc_auth_states (state_hash, provider, code_verifier, nonce, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+
+	_, err := r.db.ExecContext(ctx, query, state.StateHash, state.Provider, state.CodeVerifier, state.Nonce, state.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("failed to create oidc auth state: %w", err)
+	}
+
+	return nil
+}
+
+// ConsumeOIDCAuthState 以 DELETE ... RETURNING 取出狀態，併發的重複 callback 只有一個會成功
+func (r *OIDCAuthStateRepository) ConsumeOIDCAuthState(ctx context.Context, stateHash string) (*models.OIDCAuthState, error) {
+	state := &models.OIDCAuthState{}
+	query := `
+		DELETE FROM oidc_auth_states
+		WHERE state_hash = $1 AND expires_at > CURRENT_TIMESTAMP
+		RETURNING state_hash, provider, code_verifier, nonce, expires_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query, stateHash).Scan(
+		&state.StateHash,
+		&state.Provider,
+		&state.CodeVerifier,
+		&state.Nonce,
+		&state.ExpiresAt,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, apperrors.ErrOIDCAuthStateNotFound
+		}
+		return nil, fmt.Errorf("failed to consume oidc auth state: %w", err)
+	}
+
+	return state, nil
+}
+
+func (r *OIDCAuthStateRepository) DeleteExpiredOIDCAuthStates(ctx context.Context, before time.Time) (int64, error) {
+	query := `DELETE FROM oidc_auth_states WHERE expires_at < $1`
+
+	result, err := r.db.ExecContext(ctx, query, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired oidc auth states: %w", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired oidc auth states: %w", err)
+	}
+
+	return deleted, nil
+}
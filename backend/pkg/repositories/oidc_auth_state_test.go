@@ -0,0 +1,110 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"smart-learning-backend/pkg/models"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestOIDCAuthStateRepository_CreateOIDCAuthState(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewOIDCAuthStateRepository(db)
+	expiresAt := time.Now().Add(10 * time.Minute)
+
+	mock.ExpectExec(`INSERT INTO oidc_auth_states`).
+		WithArgs("hash", "school", "verifier", "nonce", expiresAt).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = repo.CreateOIDCAuthState(context.Background(), &models.OIDCAuthState{
+		StateHash:    "hash",
+		Provider:     "school",
+		CodeVerifier: "verifier",
+		Nonce:        "nonce",
+		ExpiresAt:    expiresAt,
+	})
+	if err != nil {
+		t.Errorf("CreateOIDCAuthState() unexpected error = %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestOIDCAuthStateRepository_ConsumeOIDCAuthState(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewOIDCAuthStateRepository(db)
+
+	tests := []struct {
+		name      string
+		mockSetup func()
+		wantError bool
+		errorMsg  string
+	}{
+		{
+			name: "成功取出狀態",
+			mockSetup: func() {
+				rows := sqlmock.NewRows([]string{"state_hash", "provider", "code_verifier", "nonce", "expires_at"}).
+					AddRow("hash", "school", "verifier", "nonce", time.Now().Add(time.Minute))
+				mock.ExpectQuery(`DELETE FROM oidc_auth_states`).
+					WithArgs("hash").
+					WillReturnRows(rows)
+			},
+			wantError: false,
+		},
+		{
+			name: "狀態不存在或已過期",
+			mockSetup: func() {
+				mock.ExpectQuery(`DELETE FROM oidc_auth_states`).
+					WithArgs("hash").
+					WillReturnError(sql.ErrNoRows)
+			},
+			wantError: true,
+			errorMsg:  "oidc auth state not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			state, err := repo.ConsumeOIDCAuthState(context.Background(), "hash")
+
+			if tt.wantError {
+				if err == nil {
+					t.Error("ConsumeOIDCAuthState() expected error but got nil")
+					return
+				}
+				if tt.errorMsg != "" && !contains(err.Error(), tt.errorMsg) {
+					t.Errorf("ConsumeOIDCAuthState() error = %v, expected to contain %v", err.Error(), tt.errorMsg)
+				}
+			} else {
+				if err != nil {
+					t.Errorf("ConsumeOIDCAuthState() unexpected error = %v", err)
+					return
+				}
+				if state.CodeVerifier != "verifier" {
+					t.Errorf("ConsumeOIDCAuthState() verifier = %v, want verifier", state.CodeVerifier)
+				}
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("Unfulfilled expectations: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package repositories
+
+import (
+	"context"
+	"math"
+	"smart-learning-backend/pkg/models"
+	"sync"
+	"time"
+)
+
+// tokenBucket 記錄單一 key 的剩餘額度；額度依 limit/window 的速率持續回補
+type tokenBucket struct {
+	tokens float64
+	limit  int
+	window time.Duration
+	last   time.Time
+}
+
+// MemoryRateLimitRepository 以記憶體實作 token bucket 限流，適用於單一實例部署與測試。
+// 背景 goroutine 會定期清除已回補至滿額的 bucket。
+type MemoryRateLimitRepository struct {
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket
+	now     func() time.Time
+	stop    chan struct{}
+	once    sync.Once
+}
+
+func NewMemoryRateLimitRepository(cleanupInterval time.Duration) *MemoryRateLimitRepository {
+	r := &MemoryRateLimitRepository{
+		buckets: make(map[string]*tokenBucket),
+		now:     time.Now,
+		stop:    make(chan struct{}),
+	}
+
+	if cleanupInterval > 0 {
+		go r.cleanupLoop(cleanupInterval)
+	}
+
+	return r
+}
+
+func (r *MemoryRateLimitRepository) Allow(ctx context.Context, key string, limit int, window time.Duration) (*models.RateLimitResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	rate := float64(limit) / window.Seconds()
+
+	bucket, ok := r.buckets[key]
+	if !ok || bucket.limit != limit || bucket.window != window {
+		bucket = &tokenBucket{tokens: float64(limit), limit: limit, window: window, last: now}
+		r.buckets[key] = bucket
+	}
+
+	// 依經過時間回補額度，不超過上限
+	bucket.tokens = math.Min(float64(limit), bucket.tokens+now.Sub(bucket.last).Seconds()*rate)
+	bucket.last = now
+
+	result := &models.RateLimitResult{Limit: limit}
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = secondsToDuration((1 - bucket.tokens) / rate)
+	}
+
+	result.Remaining = int(math.Floor(bucket.tokens))
+	result.ResetAfter = secondsToDuration((float64(limit) - bucket.tokens) / rate)
+
+	return result, nil
+}
+
+// DeleteIdleBuckets 刪除已回補至滿額的 bucket，回傳刪除數量
+func (r *MemoryRateLimitRepository) DeleteIdleBuckets() int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	var deleted int64
+	for key, bucket := range r.buckets {
+		if now.Sub(bucket.last) >= bucket.window {
+			delete(r.buckets, key)
+			deleted++
+		}
+	}
+	return deleted
+}
+
+// Close 停止背景清理 goroutine
+func (r *MemoryRateLimitRepository) Close() {
+	r.once.Do(func() {
+		close(r.stop)
+	})
+}
+
+func (r *MemoryRateLimitRepository) cleanupLoop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			r.DeleteIdleBuckets()
+		case <-r.stop:
+			return
+		}
+	}
+}
+
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
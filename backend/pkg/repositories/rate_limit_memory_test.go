@@ -0,0 +1,68 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryRateLimitRepository_Allow(t *testing.T) {
+	repo := NewMemoryRateLimitRepository(0)
+	defer repo.Close()
+
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	repo.now = func() time.Time { return now }
+
+	// 額度內的請求皆可通過，剩餘次數遞減
+	for i := 0; i < 3; i++ {
+		result, err := repo.Allow(context.Background(), "auth:ip:203.0.113.1", 3, time.Minute)
+		if err != nil {
+			t.Fatalf("Allow() unexpected error = %v", err)
+		}
+		if !result.Allowed {
+			t.Fatalf("Allow() request %d rejected", i+1)
+		}
+		if result.Remaining != 2-i {
+			t.Errorf("Allow() remaining = %d, want %d", result.Remaining, 2-i)
+		}
+	}
+
+	// 額度用完後拒絕，並回傳回補一次額度所需時間
+	result, _ := repo.Allow(context.Background(), "auth:ip:203.0.113.1", 3, time.Minute)
+	if result.Allowed {
+		t.Fatal("Allow() accepted request over the limit")
+	}
+	if result.RetryAfter != 20*time.Second {
+		t.Errorf("Allow() retry after = %v, want 20s", result.RetryAfter)
+	}
+	if result.ResetAfter != time.Minute {
+		t.Errorf("Allow() reset after = %v, want 1m", result.ResetAfter)
+	}
+
+	// 不同的 key 互不影響
+	if result, _ := repo.Allow(context.Background(), "auth:ip:203.0.113.2", 3, time.Minute); !result.Allowed {
+		t.Error("Allow() rejected a different key")
+	}
+
+	// 經過回補時間後可再次通過
+	now = now.Add(20 * time.Second)
+	if result, _ := repo.Allow(context.Background(), "auth:ip:203.0.113.1", 3, time.Minute); !result.Allowed {
+		t.Error("Allow() rejected request after refill")
+	}
+}
+
+func TestMemoryRateLimitRepository_DeleteIdleBuckets(t *testing.T) {
+	repo := NewMemoryRateLimitRepository(0)
+	defer repo.Close()
+
+	now := time.Now()
+	repo.now = func() time.Time { return now }
+
+	repo.Allow(context.Background(), "a", 10, time.Minute)
+	repo.Allow(context.Background(), "b", 10, time.Hour)
+
+	now = now.Add(2 * time.Minute)
+	if deleted := repo.DeleteIdleBuckets(); deleted != 1 {
+		t.Errorf("DeleteIdleBuckets() deleted = %d, want 1", deleted)
+	}
+}
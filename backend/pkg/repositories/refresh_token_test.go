@@ -0,0 +1,279 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"smart-learning-backend/pkg/models"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestRefreshTokenRepository_CreateRefreshToken(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewRefreshTokenRepository(db)
+	expiresAt := time.Now().Add(time.Hour)
+
+	tests := []struct {
+		name      string
+		mockSetup func()
+		wantError bool
+		errorMsg  string
+	}{
+		{
+			name: "成功建立 refresh token",
+			mockSetup: func() {
+				rows := sqlmock.NewRows([]string{"id", "created_at"}).AddRow(1, time.Now())
+				mock.ExpectQuery(`INSERT INTO refresh_tokens`).
+					WithArgs(1, "family", "hash", expiresAt).
+					WillReturnRows(rows)
+			},
+			wantError: false,
+		},
+		{
+			name: "數據庫錯誤",
+			mockSetup: func() {
+				mock.ExpectQuery(`INSERT INTO refresh_tokens`).
+					WithArgs(1, "family", "hash", expiresAt).
+					WillReturnError(errors.New("database connection failed"))
+			},
+			wantError: true,
+			errorMsg:  "failed to create refresh token",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			token := &models.RefreshToken{
+				UserID:    1,
+				FamilyID:  "family",
+				TokenHash: "hash",
+				ExpiresAt: expiresAt,
+			}
+			err := repo.CreateRefreshToken(context.Background(), token)
+
+			if tt.wantError {
+				if err == nil {
+					t.Error("CreateRefreshToken() expected error but got nil")
+					return
+				}
+				if tt.errorMsg != "" && !contains(err.Error(), tt.errorMsg) {
+					t.Errorf("CreateRefreshToken() error = %v, expected to contain %v", err.Error(), tt.errorMsg)
+				}
+			} else {
+				if err != nil {
+					t.Errorf("CreateRefreshToken() unexpected error = %v", err)
+					return
+				}
+				if token.ID == 0 {
+					t.Error("CreateRefreshToken() did not set ID")
+				}
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("Unfulfilled expectations: %v", err)
+			}
+		})
+	}
+}
+
+func TestRefreshTokenRepository_GetRefreshTokenByHash(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewRefreshTokenRepository(db)
+
+	tests := []struct {
+		name      string
+		mockSetup func()
+		wantError bool
+		errorMsg  string
+	}{
+		{
+			name: "成功獲取 refresh token",
+			mockSetup: func() {
+				rows := sqlmock.NewRows([]string{"id", "user_id", "family_id", "token_hash",
+					"expires_at", "revoked_at", "replaced_by", "created_at"}).
+					AddRow(1, 1, "family", "hash", time.Now().Add(time.Hour), nil, nil, time.Now())
+				mock.ExpectQuery(`SELECT (.+) FROM refresh_tokens WHERE token_hash`).
+					WithArgs("hash").
+					WillReturnRows(rows)
+			},
+			wantError: false,
+		},
+		{
+			name: "refresh token 不存在",
+			mockSetup: func() {
+				mock.ExpectQuery(`SELECT (.+) FROM refresh_tokens WHERE token_hash`).
+					WithArgs("hash").
+					WillReturnError(sql.ErrNoRows)
+			},
+			wantError: true,
+			errorMsg:  "refresh token not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			token, err := repo.GetRefreshTokenByHash(context.Background(), "hash")
+
+			if tt.wantError {
+				if err == nil {
+					t.Error("GetRefreshTokenByHash() expected error but got nil")
+					return
+				}
+				if tt.errorMsg != "" && !contains(err.Error(), tt.errorMsg) {
+					t.Errorf("GetRefreshTokenByHash() error = %v, expected to contain %v", err.Error(), tt.errorMsg)
+				}
+			} else {
+				if err != nil {
+					t.Errorf("GetRefreshTokenByHash() unexpected error = %v", err)
+					return
+				}
+				if token.FamilyID != "family" {
+					t.Errorf("GetRefreshTokenByHash() family = %v, want family", token.FamilyID)
+				}
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("Unfulfilled expectations: %v", err)
+			}
+		})
+	}
+}
+
+func TestRefreshTokenRepository_RotateRefreshToken(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewRefreshTokenRepository(db)
+	expiresAt := time.Now().Add(time.Hour)
+
+	tests := []struct {
+		name      string
+		mockSetup func()
+		wantError bool
+		errorMsg  string
+	}{
+		{
+			name: "成功輪換",
+			mockSetup: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`INSERT INTO refresh_tokens`).
+					WithArgs(1, "family", "new-hash", expiresAt).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(2, time.Now()))
+				mock.ExpectExec(`UPDATE refresh_tokens`).
+					WithArgs(1, 2).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			wantError: false,
+		},
+		{
+			name: "舊 token 已被使用",
+			mockSetup: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`INSERT INTO refresh_tokens`).
+					WithArgs(1, "family", "new-hash", expiresAt).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(2, time.Now()))
+				mock.ExpectExec(`UPDATE refresh_tokens`).
+					WithArgs(1, 2).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+			},
+			wantError: true,
+			errorMsg:  "refresh token already used",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			next := &models.RefreshToken{
+				UserID:    1,
+				FamilyID:  "family",
+				TokenHash: "new-hash",
+				ExpiresAt: expiresAt,
+			}
+			err := repo.RotateRefreshToken(context.Background(), 1, next)
+
+			if tt.wantError {
+				if err == nil {
+					t.Error("RotateRefreshToken() expected error but got nil")
+					return
+				}
+				if tt.errorMsg != "" && !contains(err.Error(), tt.errorMsg) {
+					t.Errorf("RotateRefreshToken() error = %v, expected to contain %v", err.Error(), tt.errorMsg)
+				}
+			} else if err != nil {
+				t.Errorf("RotateRefreshToken() unexpected error = %v", err)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("Unfulfilled expectations: %v", err)
+			}
+		})
+	}
+}
+
+func TestRefreshTokenRepository_RevokeRefreshTokenFamily(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewRefreshTokenRepository(db)
+
+	mock.ExpectExec(`UPDATE refresh_tokens SET revoked_at`).
+		WithArgs("family").
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	if err := repo.RevokeRefreshTokenFamily(context.Background(), "family"); err != nil {
+		t.Errorf("RevokeRefreshTokenFamily() unexpected error = %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestRefreshTokenRepository_RevokeUserRefreshTokens(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewRefreshTokenRepository(db)
+
+	mock.ExpectExec(`UPDATE refresh_tokens SET revoked_at`).
+		WithArgs(1, "keep").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	if err := repo.RevokeUserRefreshTokens(context.Background(), 1, "keep"); err != nil {
+		t.Errorf("RevokeUserRefreshTokens() unexpected error = %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
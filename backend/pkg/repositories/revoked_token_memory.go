@@ -0,0 +1,84 @@
+package repositories
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryRevokedTokenRepository 以記憶體保存撤銷清單，適用於單一實例部署與測試。
+// 背景 goroutine 會定期清除已過期的紀錄。
+type MemoryRevokedTokenRepository struct {
+	mu     sync.RWMutex
+	tokens map[string]time.Time
+	stop   chan struct{}
+	once   sync.Once
+}
+
+func NewMemoryRevokedTokenRepository(cleanupInterval time.Duration) *MemoryRevokedTokenRepository {
+	r := &MemoryRevokedTokenRepository{
+		tokens: make(map[string]time.Time),
+		stop:   make(chan struct{}),
+	}
+
+	if cleanupInterval > 0 {
+		go r.cleanupLoop(cleanupInterval)
+	}
+
+	return r
+}
+
+func (r *MemoryRevokedTokenRepository) RevokeToken(ctx context.Context, jti string, userID int, expiresAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.tokens[jti] = expiresAt
+	return nil
+}
+
+func (r *MemoryRevokedTokenRepository) IsTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	expiresAt, ok := r.tokens[jti]
+	if !ok {
+		return false, nil
+	}
+	return time.Now().Before(expiresAt), nil
+}
+
+func (r *MemoryRevokedTokenRepository) DeleteExpiredTokens(ctx context.Context) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	var deleted int64
+	for jti, expiresAt := range r.tokens {
+		if !now.Before(expiresAt) {
+			delete(r.tokens, jti)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// Close 停止背景清理 goroutine
+func (r *MemoryRevokedTokenRepository) Close() {
+	r.once.Do(func() {
+		close(r.stop)
+	})
+}
+
+func (r *MemoryRevokedTokenRepository) cleanupLoop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			r.DeleteExpiredTokens(context.Background())
+		case <-r.stop:
+			return
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestRevokedTokenRepository_RevokeToken(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewRevokedTokenRepository(db)
+	expiresAt := time.Now().Add(time.Hour)
+
+	mock.ExpectExec(`INSERT INTO revoked_tokens`).
+		WithArgs("jti-1", 1, expiresAt).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if err := repo.RevokeToken(context.Background(), "jti-1", 1, expiresAt); err != nil {
+		t.Errorf("RevokeToken() unexpected error = %v", err)
+	}
+
+	mock.ExpectExec(`INSERT INTO revoked_tokens`).
+		WithArgs("jti-2", 1, expiresAt).
+		WillReturnError(errors.New("database connection failed"))
+
+	err = repo.RevokeToken(context.Background(), "jti-2", 1, expiresAt)
+	if err == nil || !contains(err.Error(), "failed to revoke token") {
+		t.Errorf("RevokeToken() error = %v, expected to contain 'failed to revoke token'", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestRevokedTokenRepository_IsTokenRevoked(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewRevokedTokenRepository(db)
+
+	tests := []struct {
+		name        string
+		mockSetup   func()
+		wantRevoked bool
+		wantError   bool
+	}{
+		{
+			name: "token 已撤銷",
+			mockSetup: func() {
+				mock.ExpectQuery(`SELECT EXISTS`).
+					WithArgs("jti").
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+			},
+			wantRevoked: true,
+		},
+		{
+			name: "token 未撤銷",
+			mockSetup: func() {
+				mock.ExpectQuery(`SELECT EXISTS`).
+					WithArgs("jti").
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+			},
+			wantRevoked: false,
+		},
+		{
+			name: "數據庫錯誤",
+			mockSetup: func() {
+				mock.ExpectQuery(`SELECT EXISTS`).
+					WithArgs("jti").
+					WillReturnError(errors.New("database connection failed"))
+			},
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			revoked, err := repo.IsTokenRevoked(context.Background(), "jti")
+			if (err != nil) != tt.wantError {
+				t.Errorf("IsTokenRevoked() error = %v, wantError %v", err, tt.wantError)
+			}
+			if revoked != tt.wantRevoked {
+				t.Errorf("IsTokenRevoked() = %v, want %v", revoked, tt.wantRevoked)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("Unfulfilled expectations: %v", err)
+			}
+		})
+	}
+}
+
+func TestMemoryRevokedTokenRepository(t *testing.T) {
+	repo := NewMemoryRevokedTokenRepository(0)
+	defer repo.Close()
+
+	repo.RevokeToken(context.Background(), "active", 1, time.Now().Add(time.Hour))
+	repo.RevokeToken(context.Background(), "expired", 1, time.Now().Add(-time.Minute))
+
+	if revoked, _ := repo.IsTokenRevoked(context.Background(), "active"); !revoked {
+		t.Error("IsTokenRevoked() = false for revoked token")
+	}
+	if revoked, _ := repo.IsTokenRevoked(context.Background(), "expired"); revoked {
+		t.Error("IsTokenRevoked() = true for expired entry")
+	}
+	if revoked, _ := repo.IsTokenRevoked(context.Background(), "unknown"); revoked {
+		t.Error("IsTokenRevoked() = true for unknown token")
+	}
+
+	deleted, err := repo.DeleteExpiredTokens(context.Background())
+	if err != nil {
+		t.Fatalf("DeleteExpiredTokens() unexpected error = %v", err)
+	}
+	if deleted != 1 {
+		t.Errorf("DeleteExpiredTokens() = %d, want 1", deleted)
+	}
+}
+
+func TestMemoryRevokedTokenRepository_Cleanup(t *testing.T) {
+	repo := NewMemoryRevokedTokenRepository(10 * time.Millisecond)
+	defer repo.Close()
+
+	repo.RevokeToken(context.Background(), "short-lived", 1, time.Now().Add(20*time.Millisecond))
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		repo.mu.RLock()
+		_, ok := repo.tokens["short-lived"]
+		repo.mu.RUnlock()
+		if !ok {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Error("cleanup loop did not remove expired token")
+}
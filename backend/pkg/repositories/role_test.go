@@ -0,0 +1,80 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestRoleRepository_GetRolePermissions(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewRoleRepository(db)
+
+	tests := []struct {
+		name      string
+		mockSetup func()
+		want      int
+		wantError bool
+		errorMsg  string
+	}{
+		{
+			name: "成功獲取角色權限",
+			mockSetup: func() {
+				rows := sqlmock.NewRows([]string{"permission"}).
+					AddRow("content:manage").
+					AddRow("learning:read")
+				mock.ExpectQuery(`SELECT permission FROM role_permissions WHERE role`).
+					WithArgs("teacher").
+					WillReturnRows(rows)
+			},
+			want: 2,
+		},
+		{
+			name: "數據庫錯誤",
+			mockSetup: func() {
+				mock.ExpectQuery(`SELECT permission FROM role_permissions WHERE role`).
+					WithArgs("teacher").
+					WillReturnError(errors.New("database connection failed"))
+			},
+			wantError: true,
+			errorMsg:  "failed to get role permissions",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			permissions, err := repo.GetRolePermissions(context.Background(), "teacher")
+
+			if tt.wantError {
+				if err == nil {
+					t.Error("GetRolePermissions() expected error but got nil")
+					return
+				}
+				if tt.errorMsg != "" && !contains(err.Error(), tt.errorMsg) {
+					t.Errorf("GetRolePermissions() error = %v, expected to contain %v", err.Error(), tt.errorMsg)
+				}
+			} else {
+				if err != nil {
+					t.Errorf("GetRolePermissions() unexpected error = %v", err)
+					return
+				}
+				if len(permissions) != tt.want {
+					t.Errorf("GetRolePermissions() returned %d permissions, want %d", len(permissions), tt.want)
+				}
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("Unfulfilled expectations: %v", err)
+			}
+		})
+	}
+}
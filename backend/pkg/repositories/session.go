@@ -0,0 +1,190 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"smart-learning-backend/pkg/apperrors"
+	"smart-learning-backend/pkg/models"
+	"time"
+)
+
+// sessionTouchInterval 最後活動時間的更新間隔，避免每個已認證的請求都寫入資料庫
+const sessionTouchInterval = time.Minute
+
+type SessionRepository struct {
+	db *sql.DB
+}
+
+func NewSessionRepository(db *sql.DB) *SessionRepository {
+	return &SessionRepository{db: db}
+}
+
+func (r *SessionRepository) CreateSession(ctx context.Context, session *models.Session) error {
+	query := `
+		INSERT INTO sessions (id, user_id, user_agent, ip_address)
+		VALUES ($1, $2, $3, $4)
+		RETURNING created_at, last_seen_at
+	`
+
+	err := r.db.QueryRowContext(ctx,
+		query,
+		session.ID,
+		session.UserID,
+		session.UserAgent,
+		session.IPAddress,
+	).Scan(&session.CreatedAt, &session.LastSeenAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create session: %w", err)
+	}
+
+	return nil
+}
+
+// ListActiveSessions 列出用戶的有效會話；會話 ID 即 refresh token 家族 ID，
+// 家族中已沒有未撤銷且未過期的 refresh token 時，會話無法再換發 token，視為已過期
+func (r *SessionRepository) ListActiveSessions(ctx context.Context, userID int) ([]models.Session, error) {
+	query := `
+		SELECT s.id, s.user_id, s.user_agent, s.ip_address, s.created_at, s.last_seen_at
+		FROM sessions s
+		WHERE s.user_id = $1 AND s.revoked_at IS NULL
+			AND EXISTS (
+				SELECT 1 FROM refresh_tokens rt
+				WHERE rt.family_id = s.id AND rt.revoked_at IS NULL AND rt.expires_at > CURRENT_TIMESTAMP
+			)
+		ORDER BY s.last_seen_at DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	defer rows.Close()
+
+	sessions := make([]models.Session, 0)
+	for rows.Next() {
+		var session models.Session
+		if err := rows.Scan(
+			&session.ID,
+			&session.UserID,
+			&session.UserAgent,
+			&session.IPAddress,
+			&session.CreatedAt,
+			&session.LastSeenAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan session: %w", err)
+		}
+		sessions = append(sessions, session)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+
+	return sessions, nil
+}
+
+// GetSession 取得用戶未結束的會話；不存在或已結束時回傳 apperrors.ErrSessionNotFound
+func (r *SessionRepository) GetSession(ctx context.Context, userID int, id string) (*models.Session, error) {
+	query := `
+		SELECT id, user_id, user_agent, ip_address, created_at, last_seen_at
+		FROM sessions
+		WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL
+	`
+
+	session := &models.Session{}
+	err := r.db.QueryRowContext(ctx, query, id, userID).Scan(
+		&session.ID,
+		&session.UserID,
+		&session.UserAgent,
+		&session.IPAddress,
+		&session.CreatedAt,
+		&session.LastSeenAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, apperrors.ErrSessionNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+
+	return session, nil
+}
+
+// TouchSession 回傳會話是否仍有效；最後活動時間超過 sessionTouchInterval 才更新，
+// 其餘請求只讀取，不寫入資料庫
+func (r *SessionRepository) TouchSession(ctx context.Context, id string) (bool, error) {
+	var lastSeenAt time.Time
+	err := r.db.QueryRowContext(ctx,
+		`SELECT last_seen_at FROM sessions WHERE id = $1 AND revoked_at IS NULL`,
+		id,
+	).Scan(&lastSeenAt)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to get session: %w", err)
+	}
+
+	if time.Since(lastSeenAt) < sessionTouchInterval {
+		return true, nil
+	}
+
+	query := `
+		UPDATE sessions
+		SET last_seen_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND revoked_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to touch session: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to touch session: %w", err)
+	}
+
+	return affected > 0, nil
+}
+
+func (r *SessionRepository) RevokeSession(ctx context.Context, userID int, id string) error {
+	query := `
+		UPDATE sessions
+		SET revoked_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query, id, userID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+	if affected == 0 {
+		return apperrors.ErrSessionNotFound
+	}
+
+	return nil
+}
+
+// RevokeOtherSessions 撤銷用戶除 keepID 以外的所有會話，回傳撤銷數量
+func (r *SessionRepository) RevokeOtherSessions(ctx context.Context, userID int, keepID string) (int64, error) {
+	query := `
+		UPDATE sessions
+		SET revoked_at = CURRENT_TIMESTAMP
+		WHERE user_id = $1 AND id <> $2 AND revoked_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query, userID, keepID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	return result.RowsAffected()
+}
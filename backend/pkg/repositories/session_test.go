@@ -0,0 +1,269 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"smart-learning-backend/pkg/models"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestSessionRepository_CreateSession(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewSessionRepository(db)
+	now := time.Now()
+
+	mock.ExpectQuery(`INSERT INTO sessions`).
+		WithArgs("session-1", 1, "Mozilla/5.0", "203.0.113.1").
+		WillReturnRows(sqlmock.NewRows([]string{"created_at", "last_seen_at"}).AddRow(now, now))
+
+	session := &models.Session{
+		ID:        "session-1",
+		UserID:    1,
+		UserAgent: "Mozilla/5.0",
+		IPAddress: "203.0.113.1",
+	}
+	if err := repo.CreateSession(context.Background(), session); err != nil {
+		t.Fatalf("CreateSession() unexpected error = %v", err)
+	}
+	if session.CreatedAt.IsZero() || session.LastSeenAt.IsZero() {
+		t.Error("CreateSession() did not set timestamps")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestSessionRepository_ListActiveSessions(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewSessionRepository(db)
+	now := time.Now()
+
+	rows := sqlmock.NewRows([]string{"id", "user_id", "user_agent", "ip_address", "created_at", "last_seen_at"}).
+		AddRow("session-1", 1, "Firefox", "203.0.113.1", now, now).
+		AddRow("session-2", 1, "Chrome", "203.0.113.2", now, now)
+	// refresh token 家族已全數過期或撤銷的會話不列出
+	mock.ExpectQuery(`SELECT (.+) FROM sessions s WHERE s.user_id = \$1 AND s.revoked_at IS NULL AND EXISTS (.+) FROM refresh_tokens rt WHERE rt.family_id = s.id AND rt.revoked_at IS NULL AND rt.expires_at > CURRENT_TIMESTAMP`).
+		WithArgs(1).
+		WillReturnRows(rows)
+
+	sessions, err := repo.ListActiveSessions(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("ListActiveSessions() unexpected error = %v", err)
+	}
+	if len(sessions) != 2 {
+		t.Errorf("ListActiveSessions() returned %d sessions, want 2", len(sessions))
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestSessionRepository_GetSession(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewSessionRepository(db)
+	now := time.Now()
+
+	mock.ExpectQuery(`SELECT (.+) FROM sessions WHERE id = \$1 AND user_id = \$2 AND revoked_at IS NULL`).
+		WithArgs("session-1", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "user_agent", "ip_address", "created_at", "last_seen_at"}).
+			AddRow("session-1", 1, "Firefox", "203.0.113.1", now, now))
+
+	session, err := repo.GetSession(context.Background(), 1, "session-1")
+	if err != nil {
+		t.Fatalf("GetSession() unexpected error = %v", err)
+	}
+	if session.ID != "session-1" || !session.CreatedAt.Equal(now) {
+		t.Errorf("GetSession() = %+v", session)
+	}
+
+	mock.ExpectQuery(`SELECT (.+) FROM sessions`).
+		WithArgs("session-2", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "user_agent", "ip_address", "created_at", "last_seen_at"}))
+
+	_, err = repo.GetSession(context.Background(), 1, "session-2")
+	if err == nil || !contains(err.Error(), "session not found") {
+		t.Errorf("GetSession() error = %v, expected 'session not found'", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestSessionRepository_TouchSession(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewSessionRepository(db)
+
+	lastSeen := func(at time.Time) *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"last_seen_at"}).AddRow(at)
+	}
+
+	tests := []struct {
+		name       string
+		mockSetup  func()
+		wantActive bool
+		wantError  bool
+	}{
+		{
+			name: "最近活動過不更新",
+			mockSetup: func() {
+				mock.ExpectQuery(`SELECT last_seen_at FROM sessions`).
+					WithArgs("session-1").
+					WillReturnRows(lastSeen(time.Now().Add(-10 * time.Second)))
+			},
+			wantActive: true,
+		},
+		{
+			name: "超過更新間隔",
+			mockSetup: func() {
+				mock.ExpectQuery(`SELECT last_seen_at FROM sessions`).
+					WithArgs("session-1").
+					WillReturnRows(lastSeen(time.Now().Add(-2 * time.Minute)))
+				mock.ExpectExec(`UPDATE sessions SET last_seen_at`).
+					WithArgs("session-1").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			wantActive: true,
+		},
+		{
+			name: "更新前被撤銷",
+			mockSetup: func() {
+				mock.ExpectQuery(`SELECT last_seen_at FROM sessions`).
+					WithArgs("session-1").
+					WillReturnRows(lastSeen(time.Now().Add(-2 * time.Minute)))
+				mock.ExpectExec(`UPDATE sessions SET last_seen_at`).
+					WithArgs("session-1").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			wantActive: false,
+		},
+		{
+			name: "已撤銷的會話",
+			mockSetup: func() {
+				mock.ExpectQuery(`SELECT last_seen_at FROM sessions`).
+					WithArgs("session-1").
+					WillReturnRows(sqlmock.NewRows([]string{"last_seen_at"}))
+			},
+			wantActive: false,
+		},
+		{
+			name: "數據庫錯誤",
+			mockSetup: func() {
+				mock.ExpectQuery(`SELECT last_seen_at FROM sessions`).
+					WithArgs("session-1").
+					WillReturnError(errors.New("database connection failed"))
+			},
+			wantError: true,
+		},
+		{
+			name: "更新失敗",
+			mockSetup: func() {
+				mock.ExpectQuery(`SELECT last_seen_at FROM sessions`).
+					WithArgs("session-1").
+					WillReturnRows(lastSeen(time.Now().Add(-2 * time.Minute)))
+				mock.ExpectExec(`UPDATE sessions SET last_seen_at`).
+					WithArgs("session-1").
+					WillReturnError(errors.New("database connection failed"))
+			},
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			active, err := repo.TouchSession(context.Background(), "session-1")
+			if (err != nil) != tt.wantError {
+				t.Errorf("TouchSession() error = %v, wantError %v", err, tt.wantError)
+			}
+			if active != tt.wantActive {
+				t.Errorf("TouchSession() = %v, want %v", active, tt.wantActive)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("Unfulfilled expectations: %v", err)
+			}
+		})
+	}
+}
+
+func TestSessionRepository_RevokeSession(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewSessionRepository(db)
+
+	mock.ExpectExec(`UPDATE sessions SET revoked_at`).
+		WithArgs("session-1", 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	if err := repo.RevokeSession(context.Background(), 1, "session-1"); err != nil {
+		t.Errorf("RevokeSession() unexpected error = %v", err)
+	}
+
+	mock.ExpectExec(`UPDATE sessions SET revoked_at`).
+		WithArgs("session-2", 1).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	err = repo.RevokeSession(context.Background(), 1, "session-2")
+	if err == nil || !contains(err.Error(), "session not found") {
+		t.Errorf("RevokeSession() error = %v, expected 'session not found'", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestSessionRepository_RevokeOtherSessions(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewSessionRepository(db)
+
+	mock.ExpectExec(`UPDATE sessions SET revoked_at`).
+		WithArgs(1, "current").
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	revoked, err := repo.RevokeOtherSessions(context.Background(), 1, "current")
+	if err != nil {
+		t.Fatalf("RevokeOtherSessions() unexpected error = %v", err)
+	}
+	if revoked != 3 {
+		t.Errorf("RevokeOtherSessions() = %d, want 3", revoked)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
@@ -1,13 +1,22 @@
 package repositories
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"smart-learning-backend/pkg/apperrors"
 	"smart-learning-backend/pkg/models"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/lib/pq"
 )
 
+// userColumns 為查詢用戶時選取的欄位，順序與 scanUser 一致
+const userColumns = `id, email, username, password_hash, learning_level, avatar_url, email_verified_at, role,
+	deletion_scheduled_at, suspended_at, suspension_reason, locale, created_at, updated_at`
+
 type UserRepository struct {
 	db *sql.DB
 }
@@ -16,26 +25,27 @@ func NewUserRepository(db *sql.DB) *UserRepository {
 	return &UserRepository{db: db}
 }
 
-func (r *UserRepository) CreateUser(user *models.User) error {
+func (r *UserRepository) CreateUser(ctx context.Context, user *models.User) error {
 	query := `
-		INSERT INTO users (email, username, password_hash, learning_level, avatar_url)
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO users (email, username, password_hash, learning_level, avatar_url, role)
+		VALUES ($1, $2, $3, $4, $5, $6)
 		RETURNING id, created_at, updated_at
 	`
 	
-	err := r.db.QueryRow(
+	err := r.db.QueryRowContext(ctx,
 		query,
 		user.Email,
 		user.Username,
 		user.PasswordHash,
 		user.LearningLevel,
 		user.AvatarURL,
+		user.Role,
 	).Scan(&user.ID, &user.CreatedAt, &user.UpdatedAt)
 	
 	if err != nil {
 		if pqErr, ok := err.(*pq.Error); ok {
 			if pqErr.Code == "23505" { // unique_violation
-				return fmt.Errorf("user already exists")
+				return apperrors.ErrUserAlreadyExists
 			}
 		}
 		return fmt.Errorf("failed to create user: %w", err)
@@ -44,28 +54,15 @@ func (r *UserRepository) CreateUser(user *models.User) error {
 	return nil
 }
 
-func (r *UserRepository) GetUserByEmail(email string) (*models.User, error) {
+func (r *UserRepository) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
 	user := &models.User{}
-	query := `
-		SELECT id, email, username, password_hash, learning_level, avatar_url, created_at, updated_at
-		FROM users
-		WHERE email = $1
-	`
+	query := `SELECT ` + userColumns + ` FROM users WHERE email = $1`
 	
-	err := r.db.QueryRow(query, email).Scan(
-		&user.ID,
-		&user.Email,
-		&user.Username,
-		&user.PasswordHash,
-		&user.LearningLevel,
-		&user.AvatarURL,
-		&user.CreatedAt,
-		&user.UpdatedAt,
-	)
+	err := scanUser(r.db.QueryRowContext(ctx, query, email), user)
 	
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("user not found")
+			return nil, apperrors.ErrUserNotFound
 		}
 		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
@@ -73,28 +70,15 @@ func (r *UserRepository) GetUserByEmail(email string) (*models.User, error) {
 	return user, nil
 }
 
-func (r *UserRepository) GetUserByID(id int) (*models.User, error) {
+func (r *UserRepository) GetUserByID(ctx context.Context, id int) (*models.User, error) {
 	user := &models.User{}
-	query := `
-		SELECT id, email, username, password_hash, learning_level, avatar_url, created_at, updated_at
-		FROM users
-		WHERE id = $1
-	`
+	query := `SELECT ` + userColumns + ` FROM users WHERE id = $1`
 	
-	err := r.db.QueryRow(query, id).Scan(
-		&user.ID,
-		&user.Email,
-		&user.Username,
-		&user.PasswordHash,
-		&user.LearningLevel,
-		&user.AvatarURL,
-		&user.CreatedAt,
-		&user.UpdatedAt,
-	)
+	err := scanUser(r.db.QueryRowContext(ctx, query, id), user)
 	
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("user not found")
+			return nil, apperrors.ErrUserNotFound
 		}
 		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
@@ -102,14 +86,407 @@ func (r *UserRepository) GetUserByID(id int) (*models.User, error) {
 	return user, nil
 }
 
-func (r *UserRepository) CheckUserExists(email, username string) (bool, error) {
+func (r *UserRepository) CheckUserExists(ctx context.Context, email, username string) (bool, error) {
 	var count int
 	query := `SELECT COUNT(*) FROM users WHERE email = $1 OR username = $2`
 	
-	err := r.db.QueryRow(query, email, username).Scan(&count)
+	err := r.db.QueryRowContext(ctx, query, email, username).Scan(&count)
 	if err != nil {
 		return false, fmt.Errorf("failed to check user existence: %w", err)
 	}
 	
 	return count > 0, nil
-}
\ No newline at end of file
+}
+
+func (r *UserRepository) UpdatePasswordHash(ctx context.Context, id int, passwordHash string) error {
+	query := `UPDATE users SET password_hash = $1 WHERE id = $2`
+
+	result, err := r.db.ExecContext(ctx, query, passwordHash, id)
+	if err != nil {
+		return fmt.Errorf("failed to update password: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to update password: %w", err)
+	}
+	if affected == 0 {
+		return apperrors.ErrUserNotFound
+	}
+
+	return nil
+}
+
+// MarkEmailVerified 將用戶的 email 標記為已驗證；已驗證者保留原本的驗證時間
+func (r *UserRepository) MarkEmailVerified(ctx context.Context, id int) error {
+	query := `UPDATE users SET email_verified_at = COALESCE(email_verified_at, CURRENT_TIMESTAMP) WHERE id = $1`
+
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to mark email verified: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to mark email verified: %w", err)
+	}
+	if affected == 0 {
+		return apperrors.ErrUserNotFound
+	}
+
+	return nil
+}
+// 以下更新方法使用 updated_at 做樂觀鎖：只有在資料列的 updated_at 仍等於 expectedUpdatedAt 時才會更新，
+// 否則回傳 apperrors.ErrUserUpdateConflict，表示資料已被其他請求修改。成功時由觸發器更新 updated_at。
+
+// UpdateProfile 更新用戶名、學習等級、頭像網址與偏好語系，成功後將新的 updated_at 寫回 user
+func (r *UserRepository) UpdateProfile(ctx context.Context, user *models.User, expectedUpdatedAt time.Time) error {
+	query := `
+		UPDATE users
+		SET username = $1, learning_level = $2, avatar_url = $3, locale = $4
+		WHERE id = $5 AND updated_at = $6
+		RETURNING updated_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query, user.Username, user.LearningLevel, user.AvatarURL, user.Locale, user.ID, expectedUpdatedAt).
+		Scan(&user.UpdatedAt)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			return apperrors.ErrUsernameTaken
+		}
+		if err == sql.ErrNoRows {
+			return r.updateMissError(ctx, user.ID)
+		}
+		return fmt.Errorf("failed to update profile: %w", err)
+	}
+
+	return nil
+}
+
+// UpdatePassword 更新密碼雜湊
+func (r *UserRepository) UpdatePassword(ctx context.Context, id int, passwordHash string, expectedUpdatedAt time.Time) error {
+	query := `
+		UPDATE users SET password_hash = $1
+		WHERE id = $2 AND updated_at = $3
+		RETURNING updated_at
+	`
+
+	var updatedAt time.Time
+	err := r.db.QueryRowContext(ctx, query, passwordHash, id, expectedUpdatedAt).Scan(&updatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return r.updateMissError(ctx, id)
+		}
+		return fmt.Errorf("failed to update password: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateEmail 將 email 改為已驗證的新地址
+func (r *UserRepository) UpdateEmail(ctx context.Context, id int, email string, expectedUpdatedAt time.Time) error {
+	query := `
+		UPDATE users SET email = $1, email_verified_at = CURRENT_TIMESTAMP
+		WHERE id = $2 AND updated_at = $3
+		RETURNING updated_at
+	`
+
+	var updatedAt time.Time
+	err := r.db.QueryRowContext(ctx, query, email, id, expectedUpdatedAt).Scan(&updatedAt)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			return apperrors.ErrEmailAlreadyInUse
+		}
+		if err == sql.ErrNoRows {
+			return r.updateMissError(ctx, id)
+		}
+		return fmt.Errorf("failed to update email: %w", err)
+	}
+
+	return nil
+}
+
+// updateMissError 區分樂觀鎖更新未命中的原因：用戶不存在或資料已被修改
+func (r *UserRepository) updateMissError(ctx context.Context, id int) error {
+	return r.missError(ctx, id, apperrors.ErrUserUpdateConflict)
+}
+
+// missError 區分條件式更新未命中的原因：用戶不存在時回傳 apperrors.ErrUserNotFound，否則回傳 reason
+func (r *UserRepository) missError(ctx context.Context, id int, reason error) error {
+	var exists bool
+	if err := r.db.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM users WHERE id = $1)`, id).Scan(&exists); err != nil {
+		return fmt.Errorf("failed to check user existence: %w", err)
+	}
+	if !exists {
+		return apperrors.ErrUserNotFound
+	}
+	return reason
+}
+
+// ScheduleDeletion 設定帳號的刪除時間，到期後由 DeleteScheduledUser 刪除
+func (r *UserRepository) ScheduleDeletion(ctx context.Context, id int, at time.Time) error {
+	query := `UPDATE users SET deletion_scheduled_at = $1 WHERE id = $2`
+
+	result, err := r.db.ExecContext(ctx, query, at, id)
+	if err != nil {
+		return fmt.Errorf("failed to schedule deletion: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to schedule deletion: %w", err)
+	}
+	if affected == 0 {
+		return apperrors.ErrUserNotFound
+	}
+
+	return nil
+}
+
+// CancelDeletion 取消尚未執行的帳號刪除
+func (r *UserRepository) CancelDeletion(ctx context.Context, id int) error {
+	query := `UPDATE users SET deletion_scheduled_at = NULL WHERE id = $1 AND deletion_scheduled_at IS NOT NULL`
+
+	if _, err := r.db.ExecContext(ctx, query, id); err != nil {
+		return fmt.Errorf("failed to cancel deletion: %w", err)
+	}
+
+	return nil
+}
+
+// ListUsersDueForDeletion 列出刪除時間早於 before 的用戶，依刪除時間排序
+func (r *UserRepository) ListUsersDueForDeletion(ctx context.Context, before time.Time, limit int) ([]models.User, error) {
+	query := `
+		SELECT ` + userColumns + `
+		FROM users
+		WHERE deletion_scheduled_at <= $1
+		ORDER BY deletion_scheduled_at
+		LIMIT $2
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, before, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list users due for deletion: %w", err)
+	}
+	defer rows.Close()
+
+	users := []models.User{}
+	for rows.Next() {
+		var user models.User
+		if err := scanUser(rows, &user); err != nil {
+			return nil, fmt.Errorf("failed to scan user: %w", err)
+		}
+		users = append(users, user)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list users due for deletion: %w", err)
+	}
+
+	return users, nil
+}
+
+// DeleteScheduledUser 刪除刪除時間早於 before 的用戶，其餘資料表以外鍵 ON DELETE CASCADE 一併刪除。
+// 用戶不存在或已取消刪除時回傳 apperrors.ErrUserNotFound
+func (r *UserRepository) DeleteScheduledUser(ctx context.Context, id int, before time.Time) error {
+	query := `DELETE FROM users WHERE id = $1 AND deletion_scheduled_at <= $2`
+
+	result, err := r.db.ExecContext(ctx, query, id, before)
+	if err != nil {
+		return fmt.Errorf("failed to delete user: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to delete user: %w", err)
+	}
+	if affected == 0 {
+		return apperrors.ErrUserNotFound
+	}
+
+	return nil
+}
+
+// userListSortColumns 為用戶列表可排序的欄位，鍵為 ListUsersRequest.Sort 去掉 - 後的值
+var userListSortColumns = map[string]string{
+	"created_at":     "created_at",
+	"username":       "username",
+	"email":          "email",
+	"learning_level": "learning_level",
+}
+
+// ListUsers 依條件查詢用戶並分頁，回傳該頁的用戶與符合條件的總數。
+// Page 與 PageSize 須由呼叫端先設定；未指定排序時依建立時間遞減
+func (r *UserRepository) ListUsers(ctx context.Context, filter *models.ListUsersRequest) ([]models.User, int, error) {
+	conditions := []string{}
+	args := []interface{}{}
+	addCondition := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, strings.ReplaceAll(condition, "?", "$"+strconv.Itoa(len(args))))
+	}
+
+	if filter.Query != "" {
+		addCondition("(email ILIKE ? OR username ILIKE ?)", "%"+escapeLike(filter.Query)+"%")
+	}
+	if filter.Role != "" {
+		addCondition("role = ?", filter.Role)
+	}
+	if filter.LearningLevel != 0 {
+		addCondition("learning_level = ?", filter.LearningLevel)
+	}
+	if filter.CreatedFrom != nil {
+		addCondition("created_at >= ?", *filter.CreatedFrom)
+	}
+	if filter.CreatedTo != nil {
+		// created_to 為日期，包含當天整天
+		addCondition("created_at < ?", filter.CreatedTo.AddDate(0, 0, 1))
+	}
+	switch filter.Status {
+	case models.UserStatusActive:
+		conditions = append(conditions, "suspended_at IS NULL AND deletion_scheduled_at IS NULL")
+	case models.UserStatusSuspended:
+		conditions = append(conditions, "suspended_at IS NOT NULL")
+	case models.UserStatusPendingDeletion:
+		conditions = append(conditions, "deletion_scheduled_at IS NOT NULL")
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM users`+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count users: %w", err)
+	}
+
+	column, direction := "created_at", "DESC"
+	if filter.Sort != "" {
+		direction = "ASC"
+		name, descending := strings.CutPrefix(filter.Sort, "-")
+		if descending {
+			direction = "DESC"
+		}
+		if c, ok := userListSortColumns[name]; ok {
+			column = c
+		}
+	}
+
+	// 以 id 作為次要排序，相同值的用戶在分頁間順序固定
+	query := `SELECT ` + userColumns + ` FROM users` + where +
+		` ORDER BY ` + column + ` ` + direction + `, id ` + direction +
+		` LIMIT $` + strconv.Itoa(len(args)+1) + ` OFFSET $` + strconv.Itoa(len(args)+2)
+	args = append(args, filter.PageSize, (filter.Page-1)*filter.PageSize)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list users: %w", err)
+	}
+	defer rows.Close()
+
+	users := []models.User{}
+	for rows.Next() {
+		var user models.User
+		if err := scanUser(rows, &user); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan user: %w", err)
+		}
+		users = append(users, user)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to list users: %w", err)
+	}
+
+	return users, total, nil
+}
+
+// SuspendUser 停用帳號；已停用時回傳 apperrors.ErrUserAlreadySuspended
+func (r *UserRepository) SuspendUser(ctx context.Context, id int, reason string) error {
+	query := `
+		UPDATE users SET suspended_at = CURRENT_TIMESTAMP, suspension_reason = $1
+		WHERE id = $2 AND suspended_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query, reason, id)
+	if err != nil {
+		return fmt.Errorf("failed to suspend user: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to suspend user: %w", err)
+	}
+	if affected == 0 {
+		return r.missError(ctx, id, apperrors.ErrUserAlreadySuspended)
+	}
+
+	return nil
+}
+
+// UnsuspendUser 解除停用；未停用時回傳 apperrors.ErrUserNotSuspended
+func (r *UserRepository) UnsuspendUser(ctx context.Context, id int) error {
+	query := `
+		UPDATE users SET suspended_at = NULL, suspension_reason = NULL
+		WHERE id = $1 AND suspended_at IS NOT NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to unsuspend user: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to unsuspend user: %w", err)
+	}
+	if affected == 0 {
+		return r.missError(ctx, id, apperrors.ErrUserNotSuspended)
+	}
+
+	return nil
+}
+
+// UpdateRole 變更用戶角色；角色不存在於 roles 表時回傳 apperrors.ErrRoleNotFound
+func (r *UserRepository) UpdateRole(ctx context.Context, id int, role string) error {
+	query := `UPDATE users SET role = $1 WHERE id = $2`
+
+	result, err := r.db.ExecContext(ctx, query, role, id)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23503" { // foreign_key_violation
+			return apperrors.ErrRoleNotFound
+		}
+		return fmt.Errorf("failed to update role: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to update role: %w", err)
+	}
+	if affected == 0 {
+		return apperrors.ErrUserNotFound
+	}
+
+	return nil
+}
+
+func scanUser(row rowScanner, user *models.User) error {
+	return row.Scan(
+		&user.ID,
+		&user.Email,
+		&user.Username,
+		&user.PasswordHash,
+		&user.LearningLevel,
+		&user.AvatarURL,
+		&user.EmailVerifiedAt,
+		&user.Role,
+		&user.DeletionScheduledAt,
+		&user.SuspendedAt,
+		&user.SuspensionReason,
+		&user.Locale,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+	)
+}
+
+// escapeLike 跳脫 LIKE 模式中的特殊字元，讓搜尋字串依字面比對
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
@@ -0,0 +1,203 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"smart-learning-backend/pkg/models"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+)
+
+func TestUserIdentityRepository_CreateUserIdentity(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewUserIdentityRepository(db)
+	email := "student@school.example"
+
+	tests := []struct {
+		name      string
+		mockSetup func()
+		wantError bool
+		errorMsg  string
+	}{
+		{
+			name: "成功連結外部身分",
+			mockSetup: func() {
+				rows := sqlmock.NewRows([]string{"id", "created_at", "last_login_at"}).AddRow(1, time.Now(), time.Now())
+				mock.ExpectQuery(`INSERT INTO user_identities`).
+					WithArgs(1, "school", "subject", &email).
+					WillReturnRows(rows)
+			},
+			wantError: false,
+		},
+		{
+			name: "外部身分已被連結",
+			mockSetup: func() {
+				mock.ExpectQuery(`INSERT INTO user_identities`).
+					WithArgs(1, "school", "subject", &email).
+					WillReturnError(&pq.Error{Code: "23505"})
+			},
+			wantError: true,
+			errorMsg:  "identity already linked",
+		},
+		{
+			name: "數據庫錯誤",
+			mockSetup: func() {
+				mock.ExpectQuery(`INSERT INTO user_identities`).
+					WithArgs(1, "school", "subject", &email).
+					WillReturnError(errors.New("database connection failed"))
+			},
+			wantError: true,
+			errorMsg:  "failed to create user identity",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			identity := &models.UserIdentity{
+				UserID:   1,
+				Provider: "school",
+				Subject:  "subject",
+				Email:    &email,
+			}
+			err := repo.CreateUserIdentity(context.Background(), identity)
+
+			if tt.wantError {
+				if err == nil {
+					t.Error("CreateUserIdentity() expected error but got nil")
+					return
+				}
+				if tt.errorMsg != "" && !contains(err.Error(), tt.errorMsg) {
+					t.Errorf("CreateUserIdentity() error = %v, expected to contain %v", err.Error(), tt.errorMsg)
+				}
+			} else {
+				if err != nil {
+					t.Errorf("CreateUserIdentity() unexpected error = %v", err)
+					return
+				}
+				if identity.ID == 0 {
+					t.Error("CreateUserIdentity() did not set ID")
+				}
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("Unfulfilled expectations: %v", err)
+			}
+		})
+	}
+}
+
+func TestUserIdentityRepository_GetUserIdentity(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewUserIdentityRepository(db)
+
+	tests := []struct {
+		name      string
+		mockSetup func()
+		wantError bool
+		errorMsg  string
+	}{
+		{
+			name: "成功獲取外部身分",
+			mockSetup: func() {
+				rows := sqlmock.NewRows([]string{"id", "user_id", "provider", "subject", "email", "created_at", "last_login_at"}).
+					AddRow(1, 2, "school", "subject", nil, time.Now(), time.Now())
+				mock.ExpectQuery(`SELECT (.+) FROM user_identities WHERE provider`).
+					WithArgs("school", "subject").
+					WillReturnRows(rows)
+			},
+			wantError: false,
+		},
+		{
+			name: "外部身分不存在",
+			mockSetup: func() {
+				mock.ExpectQuery(`SELECT (.+) FROM user_identities WHERE provider`).
+					WithArgs("school", "subject").
+					WillReturnError(sql.ErrNoRows)
+			},
+			wantError: true,
+			errorMsg:  "identity not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			identity, err := repo.GetUserIdentity(context.Background(), "school", "subject")
+
+			if tt.wantError {
+				if err == nil {
+					t.Error("GetUserIdentity() expected error but got nil")
+					return
+				}
+				if tt.errorMsg != "" && !contains(err.Error(), tt.errorMsg) {
+					t.Errorf("GetUserIdentity() error = %v, expected to contain %v", err.Error(), tt.errorMsg)
+				}
+			} else {
+				if err != nil {
+					t.Errorf("GetUserIdentity() unexpected error = %v", err)
+					return
+				}
+				if identity.UserID != 2 {
+					t.Errorf("GetUserIdentity() user_id = %v, want 2", identity.UserID)
+				}
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("Unfulfilled expectations: %v", err)
+			}
+		})
+	}
+}
+
+func TestUserIdentityRepository_ListUserIdentities(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewUserIdentityRepository(db)
+
+	rows := sqlmock.NewRows([]string{"id", "user_id", "provider", "subject", "email", "created_at", "last_login_at"}).
+		AddRow(1, 2, "google", "subject-1", "user@gmail.com", time.Now(), time.Now()).
+		AddRow(2, 2, "school", "subject-2", nil, time.Now(), time.Now())
+	mock.ExpectQuery(`SELECT (.+) FROM user_identities WHERE user_id = \$1`).
+		WithArgs(2).
+		WillReturnRows(rows)
+
+	identities, err := repo.ListUserIdentities(context.Background(), 2)
+	if err != nil {
+		t.Fatalf("ListUserIdentities() unexpected error = %v", err)
+	}
+	if len(identities) != 2 || identities[0].Provider != "google" || identities[1].Email != nil {
+		t.Errorf("ListUserIdentities() = %+v", identities)
+	}
+
+	mock.ExpectQuery(`SELECT (.+) FROM user_identities`).
+		WithArgs(2).
+		WillReturnError(errors.New("database connection failed"))
+	if _, err := repo.ListUserIdentities(context.Background(), 2); err == nil || !contains(err.Error(), "failed to list user identities") {
+		t.Errorf("ListUserIdentities() error = %v, want failed to list user identities", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
@@ -1,6 +1,7 @@
 package repositories
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"smart-learning-backend/pkg/models"
@@ -8,6 +9,7 @@ import (
 	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
 )
 
 func TestNewUserRepository(t *testing.T) {
@@ -41,6 +43,7 @@ func TestUserRepository_CreateUser(t *testing.T) {
 		PasswordHash:  "hashedpassword",
 		LearningLevel: 1,
 		AvatarURL:     nil,
+		Role:          models.RoleStudent,
 	}
 
 	expectedTime := time.Now()
@@ -61,7 +64,7 @@ func TestUserRepository_CreateUser(t *testing.T) {
 				
 				mock.ExpectQuery(`INSERT INTO users`).
 					WithArgs(testUser.Email, testUser.Username, testUser.PasswordHash, 
-						testUser.LearningLevel, testUser.AvatarURL).
+						testUser.LearningLevel, testUser.AvatarURL, testUser.Role).
 					WillReturnRows(rows)
 			},
 			wantError: false,
@@ -72,7 +75,7 @@ func TestUserRepository_CreateUser(t *testing.T) {
 			mockSetup: func() {
 				mock.ExpectQuery(`INSERT INTO users`).
 					WithArgs(testUser.Email, testUser.Username, testUser.PasswordHash, 
-						testUser.LearningLevel, testUser.AvatarURL).
+						testUser.LearningLevel, testUser.AvatarURL, testUser.Role).
 					WillReturnError(errors.New("constraint violation"))
 			},
 			wantError: true,
@@ -84,7 +87,7 @@ func TestUserRepository_CreateUser(t *testing.T) {
 			mockSetup: func() {
 				mock.ExpectQuery(`INSERT INTO users`).
 					WithArgs(testUser.Email, testUser.Username, testUser.PasswordHash, 
-						testUser.LearningLevel, testUser.AvatarURL).
+						testUser.LearningLevel, testUser.AvatarURL, testUser.Role).
 					WillReturnError(errors.New("database connection failed"))
 			},
 			wantError: true,
@@ -99,7 +102,7 @@ func TestUserRepository_CreateUser(t *testing.T) {
 			// 創建用戶的副本以避免修改原始測試數據
 			userCopy := *tt.user
 			
-			err := repo.CreateUser(&userCopy)
+			err := repo.CreateUser(context.Background(), &userCopy)
 			
 			if tt.wantError {
 				if err == nil {
@@ -148,6 +151,7 @@ func TestUserRepository_GetUserByEmail(t *testing.T) {
 		PasswordHash:  "hashedpassword",
 		LearningLevel: 1,
 		AvatarURL:     nil,
+		Role:          models.RoleStudent,
 		CreatedAt:     time.Now(),
 		UpdatedAt:     time.Now(),
 	}
@@ -165,10 +169,11 @@ func TestUserRepository_GetUserByEmail(t *testing.T) {
 			email: "test@example.com",
 			mockSetup: func() {
 				rows := sqlmock.NewRows([]string{"id", "email", "username", "password_hash", 
-					"learning_level", "avatar_url", "created_at", "updated_at"}).
+					"learning_level", "avatar_url", "email_verified_at", "role", "deletion_scheduled_at", "suspended_at",
+					"suspension_reason", "locale", "created_at", "updated_at"}).
 					AddRow(expectedUser.ID, expectedUser.Email, expectedUser.Username, 
 						expectedUser.PasswordHash, expectedUser.LearningLevel, expectedUser.AvatarURL,
-						expectedUser.CreatedAt, expectedUser.UpdatedAt)
+						expectedUser.EmailVerifiedAt, expectedUser.Role, nil, nil, nil, nil, expectedUser.CreatedAt, expectedUser.UpdatedAt)
 				
 				mock.ExpectQuery(`SELECT (.+) FROM users WHERE email`).
 					WithArgs("test@example.com").
@@ -207,7 +212,7 @@ func TestUserRepository_GetUserByEmail(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mockSetup()
 			
-			user, err := repo.GetUserByEmail(tt.email)
+			user, err := repo.GetUserByEmail(context.Background(), tt.email)
 			
 			if tt.wantError {
 				if err == nil {
@@ -304,7 +309,7 @@ func TestUserRepository_CheckUserExists(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mockSetup()
 			
-			exists, err := repo.CheckUserExists(tt.email, tt.username)
+			exists, err := repo.CheckUserExists(context.Background(), tt.email, tt.username)
 			
 			if tt.wantError {
 				if err == nil {
@@ -332,6 +337,113 @@ func TestUserRepository_CheckUserExists(t *testing.T) {
 }
 
 
+func TestUserRepository_UpdatePasswordHash(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewUserRepository(db)
+
+	tests := []struct {
+		name      string
+		mockSetup func()
+		wantError bool
+		errorMsg  string
+	}{
+		{
+			name: "成功更新密碼",
+			mockSetup: func() {
+				mock.ExpectExec(`UPDATE users SET password_hash`).
+					WithArgs("new-hash", 1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			wantError: false,
+		},
+		{
+			name: "用戶不存在",
+			mockSetup: func() {
+				mock.ExpectExec(`UPDATE users SET password_hash`).
+					WithArgs("new-hash", 1).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			wantError: true,
+			errorMsg:  "user not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			err := repo.UpdatePasswordHash(context.Background(), 1, "new-hash")
+
+			if tt.wantError {
+				if err == nil {
+					t.Error("UpdatePasswordHash() expected error but got nil")
+					return
+				}
+				if tt.errorMsg != "" && !contains(err.Error(), tt.errorMsg) {
+					t.Errorf("UpdatePasswordHash() error = %v, expected to contain %v", err.Error(), tt.errorMsg)
+				}
+			} else if err != nil {
+				t.Errorf("UpdatePasswordHash() unexpected error = %v", err)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("Unfulfilled expectations: %v", err)
+			}
+		})
+	}
+}
+
+func TestUserRepository_MarkEmailVerified(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewUserRepository(db)
+
+	tests := []struct {
+		name      string
+		affected  int64
+		wantError bool
+		errorMsg  string
+	}{
+		{name: "成功標記為已驗證", affected: 1, wantError: false},
+		{name: "用戶不存在", affected: 0, wantError: true, errorMsg: "user not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock.ExpectExec(`UPDATE users SET email_verified_at`).
+				WithArgs(1).
+				WillReturnResult(sqlmock.NewResult(0, tt.affected))
+
+			err := repo.MarkEmailVerified(context.Background(), 1)
+
+			if tt.wantError {
+				if err == nil {
+					t.Error("MarkEmailVerified() expected error but got nil")
+					return
+				}
+				if !contains(err.Error(), tt.errorMsg) {
+					t.Errorf("MarkEmailVerified() error = %v, expected to contain %v", err.Error(), tt.errorMsg)
+				}
+			} else if err != nil {
+				t.Errorf("MarkEmailVerified() unexpected error = %v", err)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("Unfulfilled expectations: %v", err)
+			}
+		})
+	}
+}
+
 // 幫助函數：檢查字符串是否包含子字符串
 func contains(s, substr string) bool {
 	return len(s) >= len(substr) && (s == substr || 
@@ -348,4 +460,349 @@ func containsInMiddle(s, substr string) bool {
 		}
 	}
 	return false
-}
\ No newline at end of file
+}
+func TestUserRepository_UpdateProfile(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewUserRepository(db)
+	expectedUpdatedAt := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	newUpdatedAt := expectedUpdatedAt.Add(time.Hour)
+
+	tests := []struct {
+		name      string
+		mockSetup func()
+		wantError bool
+		errorMsg  string
+	}{
+		{
+			name: "成功更新個人資料",
+			mockSetup: func() {
+				mock.ExpectQuery(`UPDATE users SET username = \$1, learning_level = \$2, avatar_url = \$3, locale = \$4 WHERE id = \$5 AND updated_at = \$6`).
+					WithArgs("newname", 3, nil, "ja", 1, expectedUpdatedAt).
+					WillReturnRows(sqlmock.NewRows([]string{"updated_at"}).AddRow(newUpdatedAt))
+			},
+			wantError: false,
+		},
+		{
+			name: "用戶名已被使用",
+			mockSetup: func() {
+				mock.ExpectQuery(`UPDATE users SET username`).
+					WithArgs("newname", 3, nil, "ja", 1, expectedUpdatedAt).
+					WillReturnError(&pq.Error{Code: "23505"})
+			},
+			wantError: true,
+			errorMsg:  "username already taken",
+		},
+		{
+			name: "資料已被修改",
+			mockSetup: func() {
+				mock.ExpectQuery(`UPDATE users SET username`).
+					WithArgs("newname", 3, nil, "ja", 1, expectedUpdatedAt).
+					WillReturnError(sql.ErrNoRows)
+				mock.ExpectQuery(`SELECT EXISTS`).
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+			},
+			wantError: true,
+			errorMsg:  "user update conflict",
+		},
+		{
+			name: "用戶不存在",
+			mockSetup: func() {
+				mock.ExpectQuery(`UPDATE users SET username`).
+					WithArgs("newname", 3, nil, "ja", 1, expectedUpdatedAt).
+					WillReturnError(sql.ErrNoRows)
+				mock.ExpectQuery(`SELECT EXISTS`).
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+			},
+			wantError: true,
+			errorMsg:  "user not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			locale := "ja"
+			user := &models.User{ID: 1, Username: "newname", LearningLevel: 3, Locale: &locale, UpdatedAt: expectedUpdatedAt}
+			err := repo.UpdateProfile(context.Background(), user, expectedUpdatedAt)
+
+			if tt.wantError {
+				if err == nil {
+					t.Error("UpdateProfile() expected error but got nil")
+					return
+				}
+				if tt.errorMsg != "" && !contains(err.Error(), tt.errorMsg) {
+					t.Errorf("UpdateProfile() error = %v, expected to contain %v", err.Error(), tt.errorMsg)
+				}
+			} else {
+				if err != nil {
+					t.Errorf("UpdateProfile() unexpected error = %v", err)
+				}
+				if !user.UpdatedAt.Equal(newUpdatedAt) {
+					t.Errorf("UpdateProfile() UpdatedAt = %v, want %v", user.UpdatedAt, newUpdatedAt)
+				}
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("Unfulfilled expectations: %v", err)
+			}
+		})
+	}
+}
+
+func TestUserRepository_UpdatePassword(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewUserRepository(db)
+	expectedUpdatedAt := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery(`UPDATE users SET password_hash = \$1 WHERE id = \$2 AND updated_at = \$3`).
+		WithArgs("new-hash", 1, expectedUpdatedAt).
+		WillReturnRows(sqlmock.NewRows([]string{"updated_at"}).AddRow(time.Now()))
+	if err := repo.UpdatePassword(context.Background(), 1, "new-hash", expectedUpdatedAt); err != nil {
+		t.Errorf("UpdatePassword() unexpected error = %v", err)
+	}
+
+	mock.ExpectQuery(`UPDATE users SET password_hash`).
+		WithArgs("new-hash", 1, expectedUpdatedAt).
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectQuery(`SELECT EXISTS`).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	if err := repo.UpdatePassword(context.Background(), 1, "new-hash", expectedUpdatedAt); err == nil || !contains(err.Error(), "user update conflict") {
+		t.Errorf("UpdatePassword() error = %v, want user update conflict", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestUserRepository_UpdateEmail(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewUserRepository(db)
+	expectedUpdatedAt := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery(`UPDATE users SET email = \$1, email_verified_at = CURRENT_TIMESTAMP WHERE id = \$2 AND updated_at = \$3`).
+		WithArgs("new@example.com", 1, expectedUpdatedAt).
+		WillReturnRows(sqlmock.NewRows([]string{"updated_at"}).AddRow(time.Now()))
+	if err := repo.UpdateEmail(context.Background(), 1, "new@example.com", expectedUpdatedAt); err != nil {
+		t.Errorf("UpdateEmail() unexpected error = %v", err)
+	}
+
+	mock.ExpectQuery(`UPDATE users SET email`).
+		WithArgs("taken@example.com", 1, expectedUpdatedAt).
+		WillReturnError(&pq.Error{Code: "23505"})
+	if err := repo.UpdateEmail(context.Background(), 1, "taken@example.com", expectedUpdatedAt); err == nil || !contains(err.Error(), "email already in use") {
+		t.Errorf("UpdateEmail() error = %v, want email already in use", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestUserRepository_AccountDeletion(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewUserRepository(db)
+	scheduledAt := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+	now := time.Date(2025, 2, 2, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectExec(`UPDATE users SET deletion_scheduled_at = \$1 WHERE id = \$2`).
+		WithArgs(scheduledAt, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	if err := repo.ScheduleDeletion(context.Background(), 1, scheduledAt); err != nil {
+		t.Errorf("ScheduleDeletion() unexpected error = %v", err)
+	}
+
+	mock.ExpectExec(`UPDATE users SET deletion_scheduled_at = NULL WHERE id = \$1`).
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	if err := repo.CancelDeletion(context.Background(), 1); err != nil {
+		t.Errorf("CancelDeletion() unexpected error = %v", err)
+	}
+
+	rows := sqlmock.NewRows([]string{"id", "email", "username", "password_hash", "learning_level", "avatar_url",
+		"email_verified_at", "role", "deletion_scheduled_at", "suspended_at", "suspension_reason", "locale", "created_at", "updated_at"}).
+		AddRow(1, "test@example.com", "testuser", "hash", 1, nil, nil, models.RoleStudent, scheduledAt, nil, nil, nil, now, now)
+	mock.ExpectQuery(`SELECT (.+) FROM users WHERE deletion_scheduled_at <= \$1 ORDER BY deletion_scheduled_at LIMIT \$2`).
+		WithArgs(now, 100).
+		WillReturnRows(rows)
+	users, err := repo.ListUsersDueForDeletion(context.Background(), now, 100)
+	if err != nil || len(users) != 1 || users[0].DeletionScheduledAt == nil || !users[0].DeletionScheduledAt.Equal(scheduledAt) {
+		t.Errorf("ListUsersDueForDeletion() = %+v, %v", users, err)
+	}
+
+	mock.ExpectExec(`DELETE FROM users WHERE id = \$1 AND deletion_scheduled_at <= \$2`).
+		WithArgs(1, now).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	if err := repo.DeleteScheduledUser(context.Background(), 1, now); err != nil {
+		t.Errorf("DeleteScheduledUser() unexpected error = %v", err)
+	}
+
+	// 已取消刪除的用戶不會被刪除
+	mock.ExpectExec(`DELETE FROM users`).
+		WithArgs(2, now).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	if err := repo.DeleteScheduledUser(context.Background(), 2, now); err == nil || !contains(err.Error(), "user not found") {
+		t.Errorf("DeleteScheduledUser() error = %v, want user not found", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestUserRepository_ListUsers(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewUserRepository(db)
+	now := time.Date(2025, 2, 2, 0, 0, 0, 0, time.UTC)
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC)
+	columns := []string{"id", "email", "username", "password_hash", "learning_level", "avatar_url", "email_verified_at",
+		"role", "deletion_scheduled_at", "suspended_at", "suspension_reason", "locale", "created_at", "updated_at"}
+
+	t.Run("組合篩選條件", func(t *testing.T) {
+		filter := &models.ListUsersRequest{
+			Query:         "50%_off",
+			Role:          models.RoleStudent,
+			LearningLevel: 3,
+			Status:        models.UserStatusSuspended,
+			CreatedFrom:   &from,
+			CreatedTo:     &to,
+			Sort:          "-username",
+			Page:          2,
+			PageSize:      10,
+		}
+		where := `WHERE \(email ILIKE \$1 OR username ILIKE \$1\) AND role = \$2 AND learning_level = \$3 ` +
+			`AND created_at >= \$4 AND created_at < \$5 AND suspended_at IS NOT NULL`
+
+		mock.ExpectQuery(`SELECT COUNT\(\*\) FROM users ` + where).
+			WithArgs(`%50\%\_off%`, models.RoleStudent, 3, from, to.AddDate(0, 0, 1)).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(11))
+		reason := "spam"
+		mock.ExpectQuery(`SELECT (.+) FROM users ` + where + ` ORDER BY username DESC, id DESC LIMIT \$6 OFFSET \$7`).
+			WithArgs(`%50\%\_off%`, models.RoleStudent, 3, from, to.AddDate(0, 0, 1), 10, 10).
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow(11, "a@example.com", "a", "hash", 3, nil, nil, models.RoleStudent, nil, now, reason, nil, now, now))
+
+		users, total, err := repo.ListUsers(context.Background(), filter)
+		if err != nil {
+			t.Fatalf("ListUsers() unexpected error = %v", err)
+		}
+		if total != 11 || len(users) != 1 || users[0].SuspensionReason == nil || *users[0].SuspensionReason != reason {
+			t.Errorf("ListUsers() = %+v, total %d", users, total)
+		}
+	})
+
+	t.Run("預設依建立時間遞減", func(t *testing.T) {
+		mock.ExpectQuery(`SELECT COUNT\(\*\) FROM users$`).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+		mock.ExpectQuery(`SELECT (.+) FROM users ORDER BY created_at DESC, id DESC LIMIT \$1 OFFSET \$2`).
+			WithArgs(20, 0).
+			WillReturnRows(sqlmock.NewRows(columns))
+
+		users, total, err := repo.ListUsers(context.Background(), &models.ListUsersRequest{Page: 1, PageSize: 20})
+		if err != nil || total != 0 || users == nil || len(users) != 0 {
+			t.Errorf("ListUsers() = %v, %d, %v", users, total, err)
+		}
+	})
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestUserRepository_SuspensionAndRole(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewUserRepository(db)
+
+	mock.ExpectExec(`UPDATE users SET suspended_at = CURRENT_TIMESTAMP, suspension_reason = \$1 WHERE id = \$2 AND suspended_at IS NULL`).
+		WithArgs("spam", 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	if err := repo.SuspendUser(context.Background(), 1, "spam"); err != nil {
+		t.Errorf("SuspendUser() unexpected error = %v", err)
+	}
+
+	// 已停用
+	mock.ExpectExec(`UPDATE users SET suspended_at`).
+		WithArgs("spam", 1).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT EXISTS`).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	if err := repo.SuspendUser(context.Background(), 1, "spam"); err == nil || !contains(err.Error(), "user already suspended") {
+		t.Errorf("SuspendUser() error = %v, want user already suspended", err)
+	}
+
+	// 未停用的用戶
+	mock.ExpectExec(`UPDATE users SET suspended_at = NULL, suspension_reason = NULL WHERE id = \$1 AND suspended_at IS NOT NULL`).
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT EXISTS`).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	if err := repo.UnsuspendUser(context.Background(), 1); err == nil || !contains(err.Error(), "user not suspended") {
+		t.Errorf("UnsuspendUser() error = %v, want user not suspended", err)
+	}
+
+	// 用戶不存在
+	mock.ExpectExec(`UPDATE users SET suspended_at = NULL`).
+		WithArgs(99).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT EXISTS`).
+		WithArgs(99).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	if err := repo.UnsuspendUser(context.Background(), 99); err == nil || !contains(err.Error(), "user not found") {
+		t.Errorf("UnsuspendUser() error = %v, want user not found", err)
+	}
+
+	mock.ExpectExec(`UPDATE users SET role = \$1 WHERE id = \$2`).
+		WithArgs(models.RoleTeacher, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	if err := repo.UpdateRole(context.Background(), 1, models.RoleTeacher); err != nil {
+		t.Errorf("UpdateRole() unexpected error = %v", err)
+	}
+
+	mock.ExpectExec(`UPDATE users SET role`).
+		WithArgs("owner", 1).
+		WillReturnError(&pq.Error{Code: "23503"})
+	if err := repo.UpdateRole(context.Background(), 1, "owner"); err == nil || !contains(err.Error(), "role not found") {
+		t.Errorf("UpdateRole() error = %v, want role not found", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
@@ -0,0 +1,246 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"smart-learning-backend/pkg/apperrors"
+	"smart-learning-backend/pkg/interfaces"
+	"smart-learning-backend/pkg/logging"
+	"smart-learning-backend/pkg/models"
+	"smart-learning-backend/pkg/utils"
+	"time"
+)
+
+const (
+	// AccountDeletionGracePeriod 申請刪除帳號到實際刪除的寬限期，期間重新登入即取消刪除
+	AccountDeletionGracePeriod = 30 * 24 * time.Hour
+	// AccountDeletionReauthWindow 未提供目前密碼時，會話須在此時間內建立（即剛重新登入）才能申請刪除帳號；
+	// 以 OIDC 或登入連結建立的帳號沒有可用的密碼，只能以此方式確認身分
+	AccountDeletionReauthWindow = 5 * time.Minute
+	// accountPurgeBatchSize 每次清除作業最多刪除的帳號數
+	accountPurgeBatchSize = 100
+)
+
+// exportSectionNamePattern 限制區段名稱，名稱會作為 ZIP 匯出中的檔名
+var exportSectionNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// AccountService 處理帳號刪除與個人資料匯出。
+//
+// 申請刪除後帳號立即登出所有裝置並停用 API 金鑰，寬限期過後由 PurgeDueAccounts 刪除用戶；
+// 資料庫內的關聯資料以外鍵 ON DELETE CASCADE 一併刪除，資料庫以外的資料則交給各個 purger 清除。
+// 個人資料匯出除了 profile 與 security，其餘區段由各功能模組註冊的 exporter 提供。
+type AccountService struct {
+	userRepo         interfaces.UserRepositoryInterface
+	sessionRepo      interfaces.SessionRepositoryInterface
+	refreshTokenRepo interfaces.RefreshTokenRepositoryInterface
+	identityRepo     interfaces.UserIdentityRepositoryInterface
+	apiKeyRepo       interfaces.APIKeyRepositoryInterface
+	mfaService       interfaces.MFAServiceInterface
+	loginAttemptRepo interfaces.LoginAttemptRepositoryInterface
+	mailer           interfaces.MailerInterface
+	purgers          []interfaces.UserDataPurgerInterface
+	exporters        []interfaces.UserDataExporterInterface
+}
+
+func NewAccountService(
+	userRepo interfaces.UserRepositoryInterface,
+	sessionRepo interfaces.SessionRepositoryInterface,
+	refreshTokenRepo interfaces.RefreshTokenRepositoryInterface,
+	identityRepo interfaces.UserIdentityRepositoryInterface,
+	apiKeyRepo interfaces.APIKeyRepositoryInterface,
+	mfaService interfaces.MFAServiceInterface,
+	loginAttemptRepo interfaces.LoginAttemptRepositoryInterface,
+	mailer interfaces.MailerInterface,
+	purgers []interfaces.UserDataPurgerInterface,
+	exporters []interfaces.UserDataExporterInterface,
+) *AccountService {
+	return &AccountService{
+		userRepo:         userRepo,
+		sessionRepo:      sessionRepo,
+		refreshTokenRepo: refreshTokenRepo,
+		identityRepo:     identityRepo,
+		apiKeyRepo:       apiKeyRepo,
+		mfaService:       mfaService,
+		loginAttemptRepo: loginAttemptRepo,
+		mailer:           mailer,
+		purgers:          purgers,
+		exporters:        exporters,
+	}
+}
+
+// RequestDeletion 確認身分後排定刪除帳號，並登出所有裝置
+func (s *AccountService) RequestDeletion(ctx context.Context, userID int, sessionID string, req *models.DeleteAccountRequest) (*models.AccountDeletionResponse, error) {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrUserNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if err := s.verifyDeletionIdentity(ctx, user, sessionID, req.CurrentPassword); err != nil {
+		return nil, err
+	}
+
+	scheduledAt := time.Now().Add(AccountDeletionGracePeriod).UTC().Truncate(time.Second)
+	if err := s.userRepo.ScheduleDeletion(ctx, user.ID, scheduledAt); err != nil {
+		return nil, fmt.Errorf("failed to schedule deletion: %w", err)
+	}
+
+	if _, err := s.sessionRepo.RevokeOtherSessions(ctx, user.ID, ""); err != nil {
+		return nil, fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+	if err := s.refreshTokenRepo.RevokeUserRefreshTokens(ctx, user.ID, ""); err != nil {
+		return nil, fmt.Errorf("failed to revoke refresh tokens: %w", err)
+	}
+
+	message := &models.EmailMessage{
+		To:      user.Email,
+		Subject: "Smart Learning 帳號刪除申請",
+		Body: fmt.Sprintf(
+			"%s 您好：\n\n您已申請刪除 Smart Learning 帳號，所有裝置皆已登出。帳號與所有資料將於 %s 永久刪除，刪除後無法復原。\n\n若要保留帳號，請在此之前重新登入，刪除申請即會取消。若這不是您本人的操作，請登入後立即變更密碼。\n",
+			user.Username,
+			scheduledAt.Format("2006-01-02 15:04 UTC"),
+		),
+	}
+	if err := s.mailer.Send(message); err != nil {
+		logging.FromContext(ctx).Error("帳號刪除通知寄送失敗", "target_user_id", user.ID, "error", err)
+	}
+
+	return &models.AccountDeletionResponse{DeletionScheduledAt: scheduledAt}, nil
+}
+
+// verifyDeletionIdentity 有提供目前密碼時驗證密碼，否則要求目前會話是剛重新登入建立的；
+// 換發 token 沿用原會話，API 金鑰與代入身分的 token 沒有會話，皆不算重新登入
+func (s *AccountService) verifyDeletionIdentity(ctx context.Context, user *models.User, sessionID, currentPassword string) error {
+	if currentPassword != "" {
+		if err := utils.VerifyPassword(user.PasswordHash, currentPassword); err != nil {
+			return apperrors.ErrInvalidCurrentPassword
+		}
+		return nil
+	}
+
+	reauthRequired := apperrors.ErrReauthenticationRequired.WithArgs(int(AccountDeletionReauthWindow / time.Minute))
+	if sessionID == "" {
+		return reauthRequired
+	}
+	session, err := s.sessionRepo.GetSession(ctx, user.ID, sessionID)
+	if errors.Is(err, apperrors.ErrSessionNotFound) {
+		return reauthRequired
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get session: %w", err)
+	}
+	if time.Since(session.CreatedAt) > AccountDeletionReauthWindow {
+		return reauthRequired
+	}
+	return nil
+}
+
+// PurgeDueAccounts 刪除寬限期已過的帳號，回傳刪除的數量；單一帳號失敗只記錄日誌，下次再重試
+func (s *AccountService) PurgeDueAccounts(ctx context.Context, now time.Time) (int, error) {
+	users, err := s.userRepo.ListUsersDueForDeletion(ctx, now, accountPurgeBatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list accounts due for deletion: %w", err)
+	}
+
+	purged := 0
+	for i := range users {
+		if err := s.purgeAccount(ctx, &users[i], now); err != nil {
+			logging.FromContext(ctx).Error("刪除帳號失敗", "target_user_id", users[i].ID, "error", err)
+			continue
+		}
+		purged++
+	}
+
+	return purged, nil
+}
+
+// purgeAccount 先刪除用戶資料列，再清除資料庫以外的資料；
+// 用戶在清除前重新登入取消刪除時，DeleteScheduledUser 會回傳 apperrors.ErrUserNotFound 而不會清除任何資料
+func (s *AccountService) purgeAccount(ctx context.Context, user *models.User, now time.Time) error {
+	if err := s.userRepo.DeleteScheduledUser(ctx, user.ID, now); err != nil {
+		return err
+	}
+
+	for _, purger := range s.purgers {
+		if err := purger.PurgeUserData(user); err != nil {
+			logging.FromContext(ctx).Error("清除已刪除帳號的資料失敗", "target_user_id", user.ID, "error", err)
+		}
+	}
+
+	// 登入失敗紀錄以 email 為鍵，不會隨用戶刪除
+	if err := s.loginAttemptRepo.ClearLoginAttempts(ctx, accountAttemptKey(user.Email)); err != nil {
+		logging.FromContext(ctx).Error("清除已刪除帳號的登入失敗紀錄失敗", "target_user_id", user.ID, "error", err)
+	}
+
+	return nil
+}
+
+// ExportUserData 匯出用戶的個人資料，包含各 exporter 提供的區段
+func (s *AccountService) ExportUserData(ctx context.Context, userID int) (*models.UserDataExport, error) {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrUserNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	mfaEnabled, err := s.mfaService.IsEnabled(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check mfa status: %w", err)
+	}
+	sessions, err := s.sessionRepo.ListActiveSessions(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	identities, err := s.identityRepo.ListUserIdentities(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list linked accounts: %w", err)
+	}
+	apiKeys, err := s.apiKeyRepo.ListAPIKeys(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list api keys: %w", err)
+	}
+
+	sections, err := s.exportSections(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.UserDataExport{
+		ExportedAt: time.Now().UTC().Truncate(time.Second),
+		Profile:    user,
+		Security: models.AccountSecurityExport{
+			MFAEnabled:     mfaEnabled,
+			Sessions:       sessions,
+			LinkedAccounts: identities,
+			APIKeys:        apiKeys,
+		},
+		Sections: sections,
+	}, nil
+}
+
+// exportSections 依註冊順序收集各 exporter 的區段；任一區段失敗即中止，避免交出不完整的匯出
+func (s *AccountService) exportSections(ctx context.Context, user *models.User) (map[string]interface{}, error) {
+	if len(s.exporters) == 0 {
+		return nil, nil
+	}
+
+	sections := make(map[string]interface{}, len(s.exporters))
+	for _, exporter := range s.exporters {
+		name := exporter.ExportSection()
+		if _, exists := sections[name]; exists || !exportSectionNamePattern.MatchString(name) || name == "profile" || name == "security" {
+			return nil, fmt.Errorf("invalid export section name %q", name)
+		}
+
+		data, err := exporter.ExportUserData(ctx, user)
+		if err != nil {
+			return nil, fmt.Errorf("failed to export %s: %w", name, err)
+		}
+		sections[name] = data
+	}
+	return sections, nil
+}
@@ -0,0 +1,274 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"smart-learning-backend/pkg/interfaces"
+	"smart-learning-backend/pkg/models"
+	"strings"
+	"testing"
+	"time"
+)
+
+// MockUserDataPurger 實現了 UserDataPurgerInterface 介面用於測試
+type MockUserDataPurger struct {
+	purged         []int
+	shouldFailNext string
+}
+
+func (m *MockUserDataPurger) PurgeUserData(user *models.User) error {
+	if m.shouldFailNext == "PurgeUserData" {
+		m.shouldFailNext = ""
+		return errors.New("storage unavailable")
+	}
+	m.purged = append(m.purged, user.ID)
+	return nil
+}
+
+func TestAccountService_RequestDeletion(t *testing.T) {
+	tests := []struct {
+		name       string
+		password   string
+		useSession bool
+		sessionAge time.Duration
+		errorMsg   string
+	}{
+		{name: "申請成功", password: "password123"},
+		{name: "目前密碼錯誤", password: "wrong-password", errorMsg: "invalid current password"},
+		{name: "剛重新登入不需密碼", useSession: true, sessionAge: time.Minute},
+		{name: "會話建立已久", useSession: true, sessionAge: AccountDeletionReauthWindow + time.Minute, errorMsg: "reauthentication required"},
+		{name: "沒有會話", errorMsg: "reauthentication required"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deps, _ := loginTestUser(t)
+			deps.mailer.sent = nil
+
+			sessionID := ""
+			if tt.useSession {
+				deps.sessionRepo.sessions[0].CreatedAt = time.Now().Add(-tt.sessionAge)
+				sessionID = deps.sessionRepo.sessions[0].ID
+			}
+
+			result, err := deps.accountService().RequestDeletion(context.Background(), 1, sessionID, &models.DeleteAccountRequest{CurrentPassword: tt.password})
+			if tt.errorMsg != "" {
+				if err == nil || !contains(err.Error(), tt.errorMsg) {
+					t.Errorf("RequestDeletion() error = %v, expected error containing %q", err, tt.errorMsg)
+				}
+				if deps.userRepo.users[0].DeletionScheduledAt != nil {
+					t.Error("RequestDeletion() scheduled deletion despite the error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("RequestDeletion() unexpected error = %v", err)
+			}
+
+			wantAt := time.Now().Add(AccountDeletionGracePeriod)
+			if diff := result.DeletionScheduledAt.Sub(wantAt); diff < -time.Minute || diff > time.Minute {
+				t.Errorf("RequestDeletion() scheduled at %v, want about %v", result.DeletionScheduledAt, wantAt)
+			}
+			if stored := deps.userRepo.users[0].DeletionScheduledAt; stored == nil || !stored.Equal(result.DeletionScheduledAt) {
+				t.Errorf("stored deletion_scheduled_at = %v, want %v", stored, result.DeletionScheduledAt)
+			}
+
+			// 所有裝置皆已登出
+			if sessions, _ := deps.sessionRepo.ListActiveSessions(context.Background(), 1); len(sessions) != 0 {
+				t.Errorf("RequestDeletion() left %d active sessions", len(sessions))
+			}
+			for _, token := range deps.refreshTokenRepo.tokens {
+				if token.RevokedAt == nil {
+					t.Error("RequestDeletion() left a refresh token active")
+				}
+			}
+
+			if len(deps.mailer.sent) != 1 || !strings.Contains(deps.mailer.sent[0].Body, result.DeletionScheduledAt.Format("2006-01-02")) {
+				t.Errorf("RequestDeletion() sent %+v, want one notice with the deletion date", deps.mailer.sent)
+			}
+		})
+	}
+}
+
+func TestAccountService_LoginCancelsDeletion(t *testing.T) {
+	deps, _ := loginTestUser(t)
+	if _, err := deps.accountService().RequestDeletion(context.Background(), 1, "", &models.DeleteAccountRequest{CurrentPassword: "password123"}); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := deps.authService().Login(context.Background(), &models.LoginRequest{Email: "test@example.com", Password: "password123"}, models.ClientInfo{})
+	if err != nil {
+		t.Fatalf("Login() unexpected error = %v", err)
+	}
+	if result.User.DeletionScheduledAt != nil || deps.userRepo.users[0].DeletionScheduledAt != nil {
+		t.Error("Login() did not cancel the scheduled deletion")
+	}
+
+	if purged, err := deps.accountService().PurgeDueAccounts(context.Background(), time.Now().Add(AccountDeletionGracePeriod+time.Hour)); err != nil || purged != 0 {
+		t.Errorf("PurgeDueAccounts() = %d, %v, want 0 after cancellation", purged, err)
+	}
+}
+
+func TestAccountService_PurgeDueAccounts(t *testing.T) {
+	deps, _ := loginTestUser(t)
+	deps.userRepo.CreateUser(context.Background(), &models.User{Email: "other@example.com", Username: "other"})
+	if _, err := deps.loginAttemptRepo.RecordFailedLogin(context.Background(), accountAttemptKey("test@example.com"), time.Now().Add(-time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	purger := &MockUserDataPurger{}
+	accountService := deps.accountService(purger)
+	if _, err := accountService.RequestDeletion(context.Background(), 1, "", &models.DeleteAccountRequest{CurrentPassword: "password123"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// 寬限期內不會刪除
+	if purged, err := accountService.PurgeDueAccounts(context.Background(), time.Now()); err != nil || purged != 0 {
+		t.Errorf("PurgeDueAccounts() during grace period = %d, %v, want 0", purged, err)
+	}
+
+	// 外部資料清除失敗不影響刪除
+	purger.shouldFailNext = "PurgeUserData"
+	purged, err := accountService.PurgeDueAccounts(context.Background(), time.Now().Add(AccountDeletionGracePeriod+time.Minute))
+	if err != nil || purged != 1 {
+		t.Fatalf("PurgeDueAccounts() = %d, %v, want 1", purged, err)
+	}
+	if _, err := deps.userRepo.GetUserByID(context.Background(), 1); err == nil {
+		t.Error("PurgeDueAccounts() did not delete the user")
+	}
+	if _, err := deps.userRepo.GetUserByID(context.Background(), 2); err != nil {
+		t.Error("PurgeDueAccounts() deleted a user without a scheduled deletion")
+	}
+	if attempt, _ := deps.loginAttemptRepo.GetLoginAttempt(context.Background(), accountAttemptKey("test@example.com")); attempt != nil {
+		t.Errorf("PurgeDueAccounts() left login attempts %+v", attempt)
+	}
+}
+
+func TestAccountService_PurgeDueAccounts_DeleteFailure(t *testing.T) {
+	deps, _ := loginTestUser(t)
+	purger := &MockUserDataPurger{}
+	accountService := deps.accountService(purger)
+	if _, err := accountService.RequestDeletion(context.Background(), 1, "", &models.DeleteAccountRequest{CurrentPassword: "password123"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// 資料列刪除失敗時不清除外部資料，下次重試
+	deps.userRepo.SetShouldFailNext("DeleteScheduledUser")
+	due := time.Now().Add(AccountDeletionGracePeriod + time.Minute)
+	if purged, err := accountService.PurgeDueAccounts(context.Background(), due); err != nil || purged != 0 || len(purger.purged) != 0 {
+		t.Errorf("PurgeDueAccounts() = %d, %v, purged data %v", purged, err, purger.purged)
+	}
+
+	if purged, err := accountService.PurgeDueAccounts(context.Background(), due); err != nil || purged != 1 || len(purger.purged) != 1 {
+		t.Errorf("PurgeDueAccounts() retry = %d, %v, purged data %v", purged, err, purger.purged)
+	}
+}
+
+// MockUserDataExporter 實現了 UserDataExporterInterface 介面用於測試
+type MockUserDataExporter struct {
+	section string
+	err     error
+}
+
+func (m *MockUserDataExporter) ExportSection() string {
+	return m.section
+}
+
+func (m *MockUserDataExporter) ExportUserData(ctx context.Context, user *models.User) (interface{}, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return []string{m.section + " of " + user.Username}, nil
+}
+
+func TestAccountService_ExportUserData(t *testing.T) {
+	deps, _ := loginTestUser(t)
+	email := "test@gmail.com"
+	deps.identityRepo.identities = []models.UserIdentity{
+		{ID: 1, UserID: 1, Provider: "google", Subject: "subject", Email: &email},
+		{ID: 2, UserID: 2, Provider: "google", Subject: "other"},
+	}
+	deps.apiKeyRepo.CreateAPIKey(context.Background(), &models.APIKey{UserID: 1, Name: "匯入腳本", Prefix: "slk_abcdefgh", KeyHash: "hash"})
+
+	export, err := deps.accountService().ExportUserData(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("ExportUserData() unexpected error = %v", err)
+	}
+
+	if export.Profile == nil || export.Profile.Email != "test@example.com" {
+		t.Errorf("ExportUserData() profile = %+v", export.Profile)
+	}
+	if export.Security.MFAEnabled {
+		t.Error("ExportUserData() mfa_enabled = true, want false")
+	}
+	if len(export.Security.Sessions) != 1 || len(export.Security.LinkedAccounts) != 1 || len(export.Security.APIKeys) != 1 {
+		t.Errorf("ExportUserData() security = %+v", export.Security)
+	}
+
+	if _, err := deps.accountService().ExportUserData(context.Background(), 99); err == nil || !contains(err.Error(), "user not found") {
+		t.Errorf("ExportUserData() error = %v, want user not found", err)
+	}
+}
+
+func TestAccountService_ExportUserData_Sections(t *testing.T) {
+	tests := []struct {
+		name      string
+		exporters []interfaces.UserDataExporterInterface
+		errorMsg  string
+	}{
+		{
+			name: "收集所有區段",
+			exporters: []interfaces.UserDataExporterInterface{
+				&MockUserDataExporter{section: "word_lists"},
+				&MockUserDataExporter{section: "review_schedules"},
+			},
+		},
+		{
+			name: "區段名稱重複",
+			exporters: []interfaces.UserDataExporterInterface{
+				&MockUserDataExporter{section: "word_lists"},
+				&MockUserDataExporter{section: "word_lists"},
+			},
+			errorMsg: "invalid export section name",
+		},
+		{
+			name:      "區段名稱保留",
+			exporters: []interfaces.UserDataExporterInterface{&MockUserDataExporter{section: "profile"}},
+			errorMsg:  "invalid export section name",
+		},
+		{
+			name:      "區段名稱不是合法檔名",
+			exporters: []interfaces.UserDataExporterInterface{&MockUserDataExporter{section: "../word_lists"}},
+			errorMsg:  "invalid export section name",
+		},
+		{
+			name:      "區段匯出失敗",
+			exporters: []interfaces.UserDataExporterInterface{&MockUserDataExporter{section: "learning_records", err: errors.New("database error")}},
+			errorMsg:  "failed to export learning_records",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deps, _ := loginTestUser(t)
+
+			export, err := deps.accountServiceWithExporters(tt.exporters...).ExportUserData(context.Background(), 1)
+			if tt.errorMsg != "" {
+				if err == nil || !contains(err.Error(), tt.errorMsg) {
+					t.Errorf("ExportUserData() error = %v, expected error containing %q", err, tt.errorMsg)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ExportUserData() unexpected error = %v", err)
+			}
+
+			if len(export.Sections) != 2 {
+				t.Fatalf("ExportUserData() sections = %+v, want 2", export.Sections)
+			}
+			if data, ok := export.Sections["word_lists"].([]string); !ok || len(data) != 1 || data[0] != "word_lists of testuser" {
+				t.Errorf("ExportUserData() word_lists = %+v", export.Sections["word_lists"])
+			}
+		})
+	}
+}
@@ -0,0 +1,186 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"smart-learning-backend/pkg/apperrors"
+	"smart-learning-backend/pkg/authtoken"
+	"smart-learning-backend/pkg/interfaces"
+	"smart-learning-backend/pkg/logging"
+	"smart-learning-backend/pkg/models"
+)
+
+// AdminService 處理管理員的用戶管理：查詢、停用、強制重設密碼、變更角色與代入用戶身分。
+//
+// 停用與變更角色都會登出該用戶所有裝置，讓新的狀態與權限立即生效；
+// 管理員不可停用自己或變更自己的角色，避免誤把最後一個管理員鎖在系統外。
+type AdminService struct {
+	userRepo             interfaces.UserRepositoryInterface
+	sessionRepo          interfaces.SessionRepositoryInterface
+	refreshTokenRepo     interfaces.RefreshTokenRepositoryInterface
+	roleRepo             interfaces.RoleRepositoryInterface
+	passwordResetService interfaces.PasswordResetForcerInterface
+	tokens               *authtoken.Issuer
+}
+
+func NewAdminService(
+	userRepo interfaces.UserRepositoryInterface,
+	sessionRepo interfaces.SessionRepositoryInterface,
+	refreshTokenRepo interfaces.RefreshTokenRepositoryInterface,
+	roleRepo interfaces.RoleRepositoryInterface,
+	passwordResetService interfaces.PasswordResetForcerInterface,
+	tokens *authtoken.Issuer,
+) *AdminService {
+	return &AdminService{
+		userRepo:             userRepo,
+		sessionRepo:          sessionRepo,
+		refreshTokenRepo:     refreshTokenRepo,
+		roleRepo:             roleRepo,
+		passwordResetService: passwordResetService,
+		tokens:               tokens,
+	}
+}
+
+// ListUsers 依條件查詢用戶，未指定分頁時使用第一頁與預設筆數
+func (s *AdminService) ListUsers(ctx context.Context, req *models.ListUsersRequest) (*models.UserListResponse, error) {
+	if req.CreatedFrom != nil && req.CreatedTo != nil && req.CreatedFrom.After(*req.CreatedTo) {
+		return nil, apperrors.ErrInvalidDateRange
+	}
+
+	filter := *req
+	if filter.Page == 0 {
+		filter.Page = 1
+	}
+	if filter.PageSize == 0 {
+		filter.PageSize = models.DefaultUserListPageSize
+	}
+	if filter.PageSize > models.MaxUserListPageSize {
+		filter.PageSize = models.MaxUserListPageSize
+	}
+
+	users, total, err := s.userRepo.ListUsers(ctx, &filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.UserListResponse{
+		Users:    users,
+		Page:     filter.Page,
+		PageSize: filter.PageSize,
+		Total:    total,
+	}, nil
+}
+
+func (s *AdminService) GetUser(ctx context.Context, userID int) (*models.User, error) {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrUserNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	return user, nil
+}
+
+// SuspendUser 停用帳號並登出所有裝置；停用期間無法登入、換發 token 或使用 API 金鑰
+func (s *AdminService) SuspendUser(ctx context.Context, adminID, userID int, req *models.SuspendUserRequest) (*models.User, error) {
+	if adminID == userID {
+		return nil, apperrors.ErrCannotModifySelf
+	}
+
+	if err := s.userRepo.SuspendUser(ctx, userID, req.Reason); err != nil {
+		return nil, err
+	}
+	if err := s.signOutEverywhere(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	logging.FromContext(ctx).Info("管理員停用用戶", "admin_id", adminID, "target_user_id", userID)
+	return s.GetUser(ctx, userID)
+}
+
+// UnsuspendUser 解除停用；用戶需重新登入
+func (s *AdminService) UnsuspendUser(ctx context.Context, userID int) (*models.User, error) {
+	if err := s.userRepo.UnsuspendUser(ctx, userID); err != nil {
+		return nil, err
+	}
+	return s.GetUser(ctx, userID)
+}
+
+// ForcePasswordReset 讓用戶原密碼失效並寄送重設連結
+func (s *AdminService) ForcePasswordReset(ctx context.Context, userID int) error {
+	return s.passwordResetService.ForceReset(ctx, userID)
+}
+
+// ChangeRole 變更用戶角色並登出所有裝置，重新登入後的 token 即帶有新角色的權限
+func (s *AdminService) ChangeRole(ctx context.Context, adminID, userID int, req *models.ChangeRoleRequest) (*models.User, error) {
+	if adminID == userID {
+		return nil, apperrors.ErrCannotModifySelf
+	}
+
+	user, err := s.GetUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.Role == req.Role {
+		return user, nil
+	}
+
+	if err := s.userRepo.UpdateRole(ctx, userID, req.Role); err != nil {
+		return nil, err
+	}
+	if err := s.signOutEverywhere(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	logging.FromContext(ctx).Info("管理員變更用戶角色", "admin_id", adminID, "target_user_id", userID, "from_role", user.Role, "to_role", req.Role)
+	return s.GetUser(ctx, userID)
+}
+
+// Impersonate 簽發以用戶身分存取 API 的短效 token，供客服重現用戶遇到的問題。
+// 不可代入自己、管理員或已停用的帳號；token 不建立會話，也無法換發
+func (s *AdminService) Impersonate(ctx context.Context, adminID, userID int) (*models.ImpersonationResponse, error) {
+	if adminID == userID {
+		return nil, apperrors.ErrCannotImpersonateSelf
+	}
+
+	user, err := s.GetUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.Role == models.RoleAdmin {
+		return nil, apperrors.ErrCannotImpersonateAdmin
+	}
+	if user.SuspendedAt != nil {
+		return nil, apperrors.ErrAccountSuspended
+	}
+
+	permissions, err := s.roleRepo.GetRolePermissions(ctx, user.Role)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load permissions: %w", err)
+	}
+
+	token, err := s.tokens.GenerateImpersonationJWT(user.ID, user.Email, user.Username, user.Role, permissions, adminID)
+	if err != nil {
+		return nil, err
+	}
+
+	logging.FromContext(ctx).Info("管理員代入用戶身分", "admin_id", adminID, "target_user_id", userID)
+	return &models.ImpersonationResponse{
+		User:      user,
+		Token:     token,
+		ExpiresIn: int64(authtoken.ImpersonationTokenTTL.Seconds()),
+	}, nil
+}
+
+// signOutEverywhere 撤銷用戶所有裝置會話與 refresh token，既有的 access token 也會因會話失效而被拒絕
+func (s *AdminService) signOutEverywhere(ctx context.Context, userID int) error {
+	if _, err := s.sessionRepo.RevokeOtherSessions(ctx, userID, ""); err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+	if err := s.refreshTokenRepo.RevokeUserRefreshTokens(ctx, userID, ""); err != nil {
+		return fmt.Errorf("failed to revoke refresh tokens: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,205 @@
+package services
+
+import (
+	"context"
+	"smart-learning-backend/pkg/authtoken"
+	"smart-learning-backend/pkg/models"
+	"smart-learning-backend/pkg/utils"
+	"testing"
+	"time"
+)
+
+// newAdminTestDeps 建立已登入的學生（ID 1）與管理員（ID 2）
+func newAdminTestDeps(t *testing.T) (*mockDeps, *models.AuthResponse) {
+	t.Helper()
+
+	deps, login := loginTestUser(t)
+	deps.userRepo.users[0].Role = models.RoleStudent
+	deps.userRepo.CreateUser(context.Background(), &models.User{Email: "admin@example.com", Username: "admin", Role: models.RoleAdmin})
+	return deps, login
+}
+
+func TestAdminService_ListUsers(t *testing.T) {
+	deps, _ := newAdminTestDeps(t)
+	service := deps.adminService()
+
+	t.Run("預設分頁", func(t *testing.T) {
+		result, err := service.ListUsers(context.Background(), &models.ListUsersRequest{})
+		if err != nil {
+			t.Fatalf("ListUsers() unexpected error = %v", err)
+		}
+		if result.Page != 1 || result.PageSize != models.DefaultUserListPageSize || result.Total != 2 || len(result.Users) != 2 {
+			t.Errorf("ListUsers() = %+v", result)
+		}
+	})
+
+	t.Run("篩選與分頁", func(t *testing.T) {
+		result, err := service.ListUsers(context.Background(), &models.ListUsersRequest{Role: models.RoleAdmin, Page: 1, PageSize: 1})
+		if err != nil || result.Total != 1 || len(result.Users) != 1 || result.Users[0].ID != 2 {
+			t.Errorf("ListUsers() = %+v, %v", result, err)
+		}
+	})
+
+	t.Run("起始日期晚於結束日期", func(t *testing.T) {
+		from := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+		to := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+		if _, err := service.ListUsers(context.Background(), &models.ListUsersRequest{CreatedFrom: &from, CreatedTo: &to}); err == nil || !contains(err.Error(), "invalid date range") {
+			t.Errorf("ListUsers() error = %v, want invalid date range", err)
+		}
+	})
+}
+
+func TestAdminService_SuspendUser(t *testing.T) {
+	deps, login := newAdminTestDeps(t)
+	service := deps.adminService()
+
+	if _, err := service.SuspendUser(context.Background(), 2, 2, &models.SuspendUserRequest{Reason: "test"}); err == nil || !contains(err.Error(), "cannot modify own account") {
+		t.Errorf("SuspendUser() self error = %v, want cannot modify own account", err)
+	}
+
+	user, err := service.SuspendUser(context.Background(), 2, 1, &models.SuspendUserRequest{Reason: "spam"})
+	if err != nil {
+		t.Fatalf("SuspendUser() unexpected error = %v", err)
+	}
+	if user.SuspendedAt == nil || user.SuspensionReason == nil || *user.SuspensionReason != "spam" {
+		t.Errorf("SuspendUser() user = %+v", user)
+	}
+
+	// 所有裝置登出，且無法再登入或換發
+	if sessions, _ := deps.sessionRepo.ListActiveSessions(context.Background(), 1); len(sessions) != 0 {
+		t.Errorf("SuspendUser() left %d active sessions", len(sessions))
+	}
+	authService := deps.authService()
+	if _, err := authService.Refresh(context.Background(), &models.RefreshRequest{RefreshToken: login.RefreshToken}, models.ClientInfo{}); err == nil {
+		t.Error("Refresh() succeeded after suspension")
+	}
+	_, err = authService.Login(context.Background(), &models.LoginRequest{Email: "test@example.com", Password: "password123"}, models.ClientInfo{})
+	if err == nil || !contains(err.Error(), "account suspended") {
+		t.Errorf("Login() error = %v, want account suspended", err)
+	}
+
+	if _, err := service.SuspendUser(context.Background(), 2, 1, &models.SuspendUserRequest{Reason: "again"}); err == nil || !contains(err.Error(), "user already suspended") {
+		t.Errorf("SuspendUser() twice error = %v, want user already suspended", err)
+	}
+
+	// 解除停用後可重新登入
+	user, err = service.UnsuspendUser(context.Background(), 1)
+	if err != nil || user.SuspendedAt != nil {
+		t.Fatalf("UnsuspendUser() = %+v, %v", user, err)
+	}
+	if _, err := authService.Login(context.Background(), &models.LoginRequest{Email: "test@example.com", Password: "password123"}, models.ClientInfo{}); err != nil {
+		t.Errorf("Login() after unsuspend error = %v", err)
+	}
+	if _, err := service.UnsuspendUser(context.Background(), 1); err == nil || !contains(err.Error(), "user not suspended") {
+		t.Errorf("UnsuspendUser() twice error = %v, want user not suspended", err)
+	}
+}
+
+func TestAdminService_ChangeRole(t *testing.T) {
+	deps, login := newAdminTestDeps(t)
+	service := deps.adminService()
+
+	tests := []struct {
+		name     string
+		adminID  int
+		userID   int
+		role     string
+		errorMsg string
+	}{
+		{name: "不可變更自己的角色", adminID: 2, userID: 2, role: models.RoleStudent, errorMsg: "cannot modify own account"},
+		{name: "角色不存在", adminID: 2, userID: 1, role: "owner", errorMsg: "role not found"},
+		{name: "用戶不存在", adminID: 2, userID: 99, role: models.RoleTeacher, errorMsg: "user not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.ChangeRole(context.Background(), tt.adminID, tt.userID, &models.ChangeRoleRequest{Role: tt.role})
+			if err == nil || !contains(err.Error(), tt.errorMsg) {
+				t.Errorf("ChangeRole() error = %v, want %q", err, tt.errorMsg)
+			}
+		})
+	}
+
+	user, err := service.ChangeRole(context.Background(), 2, 1, &models.ChangeRoleRequest{Role: models.RoleTeacher})
+	if err != nil || user.Role != models.RoleTeacher {
+		t.Fatalf("ChangeRole() = %+v, %v", user, err)
+	}
+
+	// 登出所有裝置，舊 token 的權限不再有效
+	if sessions, _ := deps.sessionRepo.ListActiveSessions(context.Background(), 1); len(sessions) != 0 {
+		t.Errorf("ChangeRole() left %d active sessions", len(sessions))
+	}
+	if _, err := deps.authService().Refresh(context.Background(), &models.RefreshRequest{RefreshToken: login.RefreshToken}, models.ClientInfo{}); err == nil {
+		t.Error("Refresh() succeeded after role change")
+	}
+}
+
+func TestAdminService_ForcePasswordReset(t *testing.T) {
+	deps, _ := newAdminTestDeps(t)
+
+	if err := deps.adminService().ForcePasswordReset(context.Background(), 1); err != nil {
+		t.Fatalf("ForcePasswordReset() unexpected error = %v", err)
+	}
+	if utils.VerifyPassword(deps.userRepo.users[0].PasswordHash, "password123") == nil {
+		t.Error("ForcePasswordReset() kept the previous password")
+	}
+	if len(deps.mailer.sent) != 1 || deps.mailer.sent[0].To != "test@example.com" {
+		t.Errorf("ForcePasswordReset() sent %+v", deps.mailer.sent)
+	}
+}
+
+func TestAdminService_Impersonate(t *testing.T) {
+	deps, _ := newAdminTestDeps(t)
+	service := deps.adminService()
+
+	result, err := service.Impersonate(context.Background(), 2, 1)
+	if err != nil {
+		t.Fatalf("Impersonate() unexpected error = %v", err)
+	}
+	if result.ExpiresIn != int64(authtoken.ImpersonationTokenTTL.Seconds()) || result.User.ID != 1 {
+		t.Errorf("Impersonate() = %+v", result)
+	}
+
+	claims, err := deps.tokens.ValidateJWT(result.Token)
+	if err != nil {
+		t.Fatalf("ValidateJWT() error = %v", err)
+	}
+	if claims.UserID != 1 || claims.ImpersonatorID != 2 || claims.SessionID != "" || len(claims.Permissions) == 0 {
+		t.Errorf("impersonation claims = %+v", claims)
+	}
+
+	// 不建立會話或 refresh token
+	if sessions, _ := deps.sessionRepo.ListActiveSessions(context.Background(), 1); len(sessions) != 1 {
+		t.Errorf("Impersonate() sessions = %d, want the original login only", len(sessions))
+	}
+
+	tests := []struct {
+		name     string
+		adminID  int
+		userID   int
+		setup    func()
+		errorMsg string
+	}{
+		{name: "不可代入自己", adminID: 2, userID: 2, errorMsg: "cannot impersonate self"},
+		{name: "不可代入管理員", adminID: 1, userID: 2, errorMsg: "cannot impersonate admin"},
+		{name: "用戶不存在", adminID: 2, userID: 99, errorMsg: "user not found"},
+		{
+			name:     "已停用的帳號",
+			adminID:  2,
+			userID:   1,
+			setup:    func() { deps.userRepo.SuspendUser(context.Background(), 1, "spam") },
+			errorMsg: "account suspended",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.setup != nil {
+				tt.setup()
+			}
+			if _, err := service.Impersonate(context.Background(), tt.adminID, tt.userID); err == nil || !contains(err.Error(), tt.errorMsg) {
+				t.Errorf("Impersonate() error = %v, want %q", err, tt.errorMsg)
+			}
+		})
+	}
+}
@@ -0,0 +1,161 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"smart-learning-backend/pkg/apperrors"
+	"smart-learning-backend/pkg/interfaces"
+	"smart-learning-backend/pkg/logging"
+	"smart-learning-backend/pkg/models"
+	"smart-learning-backend/pkg/utils"
+	"strings"
+	"time"
+)
+
+// MaxAPIKeysPerUser 為每個用戶可同時持有的 API 金鑰數量上限
+const MaxAPIKeysPerUser = 10
+
+type APIKeyService struct {
+	apiKeyRepo interfaces.APIKeyRepositoryInterface
+	userRepo   interfaces.UserRepositoryInterface
+	roleRepo   interfaces.RoleRepositoryInterface
+}
+
+func NewAPIKeyService(
+	apiKeyRepo interfaces.APIKeyRepositoryInterface,
+	userRepo interfaces.UserRepositoryInterface,
+	roleRepo interfaces.RoleRepositoryInterface,
+) *APIKeyService {
+	return &APIKeyService{
+		apiKeyRepo: apiKeyRepo,
+		userRepo:   userRepo,
+		roleRepo:   roleRepo,
+	}
+}
+
+// CreateAPIKey 建立新的 API 金鑰，scope 必須是用戶目前角色擁有的權限。
+// 完整金鑰只在此時回傳一次。
+func (s *APIKeyService) CreateAPIKey(ctx context.Context, userID int, req *models.CreateAPIKeyRequest) (*models.CreateAPIKeyResponse, error) {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	permissions, err := s.roleRepo.GetRolePermissions(ctx, user.Role)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load permissions: %w", err)
+	}
+
+	granted := make(map[string]bool)
+	for _, permission := range permissions {
+		granted[permission] = true
+	}
+
+	scopes := make([]string, 0, len(req.Scopes))
+	seen := make(map[string]bool)
+	for _, scope := range req.Scopes {
+		if !granted[scope] {
+			return nil, apperrors.ErrInvalidScope.WithArgs(scope)
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			scopes = append(scopes, scope)
+		}
+	}
+
+	existing, err := s.apiKeyRepo.ListAPIKeys(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) >= MaxAPIKeysPerUser {
+		return nil, apperrors.ErrAPIKeyLimitReached
+	}
+
+	plainKey, prefix, err := utils.GenerateAPIKey()
+	if err != nil {
+		return nil, err
+	}
+
+	key := &models.APIKey{
+		UserID:  userID,
+		Name:    strings.TrimSpace(req.Name),
+		Prefix:  prefix,
+		KeyHash: utils.HashToken(plainKey),
+		Scopes:  scopes,
+	}
+	if req.ExpiresInDays > 0 {
+		expiresAt := time.Now().AddDate(0, 0, req.ExpiresInDays)
+		key.ExpiresAt = &expiresAt
+	}
+
+	if err := s.apiKeyRepo.CreateAPIKey(ctx, key); err != nil {
+		return nil, err
+	}
+
+	return &models.CreateAPIKeyResponse{APIKey: key, Key: plainKey}, nil
+}
+
+func (s *APIKeyService) ListAPIKeys(ctx context.Context, userID int) ([]models.APIKey, error) {
+	return s.apiKeyRepo.ListAPIKeys(ctx, userID)
+}
+
+func (s *APIKeyService) RevokeAPIKey(ctx context.Context, userID, id int) error {
+	return s.apiKeyRepo.RevokeAPIKey(ctx, userID, id)
+}
+
+// AuthenticateAPIKey 驗證 API 金鑰並回傳對應的用戶。
+// 權限為金鑰 scope 與用戶目前角色權限的交集，角色被降級後金鑰的權限也隨之縮減。
+func (s *APIKeyService) AuthenticateAPIKey(ctx context.Context, plainKey string) (*models.APIKeyPrincipal, error) {
+	if !utils.IsAPIKey(plainKey) {
+		return nil, apperrors.ErrInvalidAPIKey
+	}
+
+	key, err := s.apiKeyRepo.GetAPIKeyByHash(ctx, utils.HashToken(plainKey))
+	if err != nil {
+		if errors.Is(err, apperrors.ErrAPIKeyNotFound) {
+			return nil, apperrors.ErrInvalidAPIKey
+		}
+		return nil, err
+	}
+
+	if key.RevokedAt != nil || (key.ExpiresAt != nil && time.Now().After(*key.ExpiresAt)) {
+		return nil, apperrors.ErrInvalidAPIKey
+	}
+
+	user, err := s.userRepo.GetUserByID(ctx, key.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	// 已申請刪除的帳號在寬限期內停用 API 金鑰，重新登入取消刪除後恢復；停用的帳號在解除停用前同樣無法使用
+	if user.DeletionScheduledAt != nil || user.SuspendedAt != nil {
+		return nil, apperrors.ErrInvalidAPIKey
+	}
+
+	rolePermissions, err := s.roleRepo.GetRolePermissions(ctx, user.Role)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load permissions: %w", err)
+	}
+
+	granted := make(map[string]bool)
+	for _, permission := range rolePermissions {
+		granted[permission] = true
+	}
+	permissions := make([]string, 0, len(key.Scopes))
+	for _, scope := range key.Scopes {
+		if granted[scope] {
+			permissions = append(permissions, scope)
+		}
+	}
+
+	// 最後使用時間僅供參考，更新失敗不影響請求
+	if err := s.apiKeyRepo.TouchAPIKey(ctx, key.ID); err != nil {
+		logging.FromContext(ctx).Warn("更新 API 金鑰使用時間失敗", "api_key_id", key.ID, "error", err)
+	}
+
+	return &models.APIKeyPrincipal{
+		KeyID:       key.ID,
+		User:        user,
+		Permissions: permissions,
+	}, nil
+}
@@ -0,0 +1,293 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"smart-learning-backend/pkg/apperrors"
+	"smart-learning-backend/pkg/models"
+	"smart-learning-backend/pkg/utils"
+	"testing"
+	"time"
+)
+
+// MockAPIKeyRepository 實現了 APIKeyRepositoryInterface 介面用於測試
+type MockAPIKeyRepository struct {
+	keys           []models.APIKey
+	touched        []int
+	shouldFailNext string
+}
+
+func NewMockAPIKeyRepository() *MockAPIKeyRepository {
+	return &MockAPIKeyRepository{
+		keys: make([]models.APIKey, 0),
+	}
+}
+
+func (m *MockAPIKeyRepository) CreateAPIKey(ctx context.Context, key *models.APIKey) error {
+	if m.shouldFailNext == "CreateAPIKey" {
+		m.shouldFailNext = ""
+		return errors.New("database error")
+	}
+
+	key.ID = len(m.keys) + 1
+	key.CreatedAt = time.Now()
+	m.keys = append(m.keys, *key)
+	return nil
+}
+
+func (m *MockAPIKeyRepository) ListAPIKeys(ctx context.Context, userID int) ([]models.APIKey, error) {
+	keys := make([]models.APIKey, 0)
+	for _, key := range m.keys {
+		if key.UserID == userID && key.RevokedAt == nil {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+func (m *MockAPIKeyRepository) GetAPIKeyByHash(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	for _, key := range m.keys {
+		if key.KeyHash == keyHash {
+			return &key, nil
+		}
+	}
+	return nil, apperrors.ErrAPIKeyNotFound
+}
+
+func (m *MockAPIKeyRepository) RevokeAPIKey(ctx context.Context, userID, id int) error {
+	for i := range m.keys {
+		if m.keys[i].ID == id && m.keys[i].UserID == userID && m.keys[i].RevokedAt == nil {
+			now := time.Now()
+			m.keys[i].RevokedAt = &now
+			return nil
+		}
+	}
+	return apperrors.ErrAPIKeyNotFound
+}
+
+func (m *MockAPIKeyRepository) TouchAPIKey(ctx context.Context, id int) error {
+	if m.shouldFailNext == "TouchAPIKey" {
+		m.shouldFailNext = ""
+		return errors.New("database error")
+	}
+	m.touched = append(m.touched, id)
+	return nil
+}
+
+func (m *MockAPIKeyRepository) SetShouldFailNext(method string) {
+	m.shouldFailNext = method
+}
+
+// newAPIKeyTestService 建立學生角色的測試用戶與 API 金鑰服務
+func newAPIKeyTestService() (*APIKeyService, *MockAPIKeyRepository, *MockUserRepository) {
+	userRepo := NewMockUserRepository()
+	userRepo.CreateUser(context.Background(), &models.User{
+		Email:    "test@example.com",
+		Username: "testuser",
+		Role:     models.RoleStudent,
+	})
+	apiKeyRepo := NewMockAPIKeyRepository()
+	return NewAPIKeyService(apiKeyRepo, userRepo, NewMockRoleRepository()), apiKeyRepo, userRepo
+}
+
+func TestAPIKeyService_CreateAPIKey(t *testing.T) {
+	tests := []struct {
+		name       string
+		req        models.CreateAPIKeyRequest
+		setup      func(service *APIKeyService)
+		wantError  string
+		wantScopes []string
+	}{
+		{
+			name:       "成功建立金鑰",
+			req:        models.CreateAPIKeyRequest{Name: " 匯入腳本 ", Scopes: []string{models.PermissionLearningWrite, models.PermissionLearningWrite}},
+			wantScopes: []string{models.PermissionLearningWrite},
+		},
+		{
+			name:      "scope 超出角色權限",
+			req:       models.CreateAPIKeyRequest{Name: "腳本", Scopes: []string{models.PermissionUsersManage}},
+			wantError: "invalid scope: users:manage",
+		},
+		{
+			name: "超過金鑰數量上限",
+			req:  models.CreateAPIKeyRequest{Name: "腳本", Scopes: []string{models.PermissionLearningRead}},
+			setup: func(service *APIKeyService) {
+				for i := 0; i < MaxAPIKeysPerUser; i++ {
+					service.CreateAPIKey(context.Background(), 1, &models.CreateAPIKeyRequest{Name: "舊金鑰", Scopes: []string{models.PermissionLearningRead}})
+				}
+			},
+			wantError: "api key limit reached",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, apiKeyRepo, _ := newAPIKeyTestService()
+			if tt.setup != nil {
+				tt.setup(service)
+			}
+
+			result, err := service.CreateAPIKey(context.Background(), 1, &tt.req)
+
+			if tt.wantError != "" {
+				if err == nil || !contains(err.Error(), tt.wantError) {
+					t.Errorf("CreateAPIKey() error = %v, want %s", err, tt.wantError)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("CreateAPIKey() unexpected error = %v", err)
+			}
+			if !utils.IsAPIKey(result.Key) || !contains(result.Key, result.Prefix) {
+				t.Errorf("CreateAPIKey() key = %s, prefix = %s", result.Key, result.Prefix)
+			}
+			if result.Name != "匯入腳本" {
+				t.Errorf("CreateAPIKey() name = %q, want trimmed", result.Name)
+			}
+			if len(result.Scopes) != len(tt.wantScopes) || result.Scopes[0] != tt.wantScopes[0] {
+				t.Errorf("CreateAPIKey() scopes = %v, want %v", result.Scopes, tt.wantScopes)
+			}
+
+			// 資料庫只保存雜湊值
+			stored := apiKeyRepo.keys[len(apiKeyRepo.keys)-1]
+			if stored.KeyHash != utils.HashToken(result.Key) {
+				t.Error("CreateAPIKey() did not store the hashed key")
+			}
+		})
+	}
+}
+
+func TestAPIKeyService_AuthenticateAPIKey(t *testing.T) {
+	tests := []struct {
+		name            string
+		setup           func(apiKeyRepo *MockAPIKeyRepository, userRepo *MockUserRepository, key string) string
+		wantError       bool
+		wantPermissions []string
+	}{
+		{
+			name:            "有效的金鑰",
+			wantPermissions: []string{models.PermissionLearningRead, models.PermissionLearningWrite},
+		},
+		{
+			name: "不是 API 金鑰格式",
+			setup: func(apiKeyRepo *MockAPIKeyRepository, userRepo *MockUserRepository, key string) string {
+				return "eyJhbGciOiJIUzI1NiJ9"
+			},
+			wantError: true,
+		},
+		{
+			name: "金鑰不存在",
+			setup: func(apiKeyRepo *MockAPIKeyRepository, userRepo *MockUserRepository, key string) string {
+				return utils.APIKeyPrefix + "unknown"
+			},
+			wantError: true,
+		},
+		{
+			name: "金鑰已撤銷",
+			setup: func(apiKeyRepo *MockAPIKeyRepository, userRepo *MockUserRepository, key string) string {
+				apiKeyRepo.RevokeAPIKey(context.Background(), 1, 1)
+				return key
+			},
+			wantError: true,
+		},
+		{
+			name: "金鑰已過期",
+			setup: func(apiKeyRepo *MockAPIKeyRepository, userRepo *MockUserRepository, key string) string {
+				expired := time.Now().Add(-time.Minute)
+				apiKeyRepo.keys[0].ExpiresAt = &expired
+				return key
+			},
+			wantError: true,
+		},
+		{
+			name: "角色變更後權限取交集",
+			setup: func(apiKeyRepo *MockAPIKeyRepository, userRepo *MockUserRepository, key string) string {
+				userRepo.users[0].Role = models.RoleTeacher
+				return key
+			},
+			wantPermissions: []string{models.PermissionLearningRead},
+		},
+		{
+			name: "帳號已申請刪除",
+			setup: func(apiKeyRepo *MockAPIKeyRepository, userRepo *MockUserRepository, key string) string {
+				userRepo.ScheduleDeletion(context.Background(), 1, time.Now().Add(time.Hour))
+				return key
+			},
+			wantError: true,
+		},
+		{
+			name: "帳號已停用",
+			setup: func(apiKeyRepo *MockAPIKeyRepository, userRepo *MockUserRepository, key string) string {
+				userRepo.SuspendUser(context.Background(), 1, "spam")
+				return key
+			},
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, apiKeyRepo, userRepo := newAPIKeyTestService()
+			created, err := service.CreateAPIKey(context.Background(), 1, &models.CreateAPIKeyRequest{
+				Name:   "腳本",
+				Scopes: []string{models.PermissionLearningRead, models.PermissionLearningWrite},
+			})
+			if err != nil {
+				t.Fatalf("CreateAPIKey() unexpected error = %v", err)
+			}
+
+			key := created.Key
+			if tt.setup != nil {
+				key = tt.setup(apiKeyRepo, userRepo, key)
+			}
+
+			principal, err := service.AuthenticateAPIKey(context.Background(), key)
+
+			if tt.wantError {
+				if err == nil || !contains(err.Error(), "invalid api key") {
+					t.Errorf("AuthenticateAPIKey() error = %v, want invalid api key", err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("AuthenticateAPIKey() unexpected error = %v", err)
+			}
+			if principal.User.ID != 1 || principal.KeyID != created.ID {
+				t.Errorf("AuthenticateAPIKey() = %+v", principal)
+			}
+			if len(principal.Permissions) != len(tt.wantPermissions) {
+				t.Fatalf("AuthenticateAPIKey() permissions = %v, want %v", principal.Permissions, tt.wantPermissions)
+			}
+			for i := range tt.wantPermissions {
+				if principal.Permissions[i] != tt.wantPermissions[i] {
+					t.Errorf("AuthenticateAPIKey() permissions = %v, want %v", principal.Permissions, tt.wantPermissions)
+				}
+			}
+			if len(apiKeyRepo.touched) != 1 {
+				t.Error("AuthenticateAPIKey() did not update last used time")
+			}
+		})
+	}
+}
+
+func TestAPIKeyService_RevokeAPIKey(t *testing.T) {
+	service, _, _ := newAPIKeyTestService()
+	created, _ := service.CreateAPIKey(context.Background(), 1, &models.CreateAPIKeyRequest{Name: "腳本", Scopes: []string{models.PermissionLearningRead}})
+
+	// 其他用戶無法撤銷
+	if err := service.RevokeAPIKey(context.Background(), 2, created.ID); err == nil || !contains(err.Error(), "api key not found") {
+		t.Errorf("RevokeAPIKey() by other user error = %v, want api key not found", err)
+	}
+
+	if err := service.RevokeAPIKey(context.Background(), 1, created.ID); err != nil {
+		t.Fatalf("RevokeAPIKey() unexpected error = %v", err)
+	}
+	if keys, _ := service.ListAPIKeys(context.Background(), 1); len(keys) != 0 {
+		t.Errorf("ListAPIKeys() returned %d keys after revoke", len(keys))
+	}
+	if _, err := service.AuthenticateAPIKey(context.Background(), created.Key); err == nil {
+		t.Error("AuthenticateAPIKey() accepted a revoked key")
+	}
+}
@@ -0,0 +1,80 @@
+package services
+
+import (
+	"context"
+	"smart-learning-backend/pkg/apperrors"
+	"smart-learning-backend/pkg/interfaces"
+	"smart-learning-backend/pkg/logging"
+	"smart-learning-backend/pkg/models"
+	"time"
+)
+
+// auditWriteTimeout 為寫入一筆稽核紀錄的時限
+const auditWriteTimeout = 5 * time.Second
+
+// AuditLogService 寫入與查詢安全稽核日誌。
+//
+// 寫入失敗只記錄在日誌而不回傳錯誤，稽核日誌無法寫入時不應讓登入等操作跟著失敗。
+type AuditLogService struct {
+	auditLogRepo interfaces.AuditLogRepositoryInterface
+}
+
+func NewAuditLogService(auditLogRepo interfaces.AuditLogRepositoryInterface) *AuditLogService {
+	return &AuditLogService{auditLogRepo: auditLogRepo}
+}
+
+// Record 寫入一筆稽核紀錄。操作已經完成，即使請求逾時或用戶端中斷連線仍須留下紀錄，
+// 因此不隨請求的 context 取消，改以 auditWriteTimeout 為時限
+func (s *AuditLogService) Record(ctx context.Context, entry *models.AuditLogEntry) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), auditWriteTimeout)
+	defer cancel()
+
+	if err := s.auditLogRepo.CreateAuditLogEntry(ctx, entry); err != nil {
+		logging.FromContext(ctx).Error("稽核日誌寫入失敗", "event_type", entry.EventType, "error", err)
+	}
+}
+
+// ListEntries 依條件查詢稽核紀錄，未指定分頁時使用第一頁與預設筆數
+func (s *AuditLogService) ListEntries(ctx context.Context, query *models.AuditLogQuery) (*models.AuditLogListResponse, error) {
+	if err := validateAuditLogRange(query); err != nil {
+		return nil, err
+	}
+
+	filter := *query
+	if filter.Page == 0 {
+		filter.Page = 1
+	}
+	if filter.PageSize == 0 {
+		filter.PageSize = models.DefaultAuditLogPageSize
+	}
+	if filter.PageSize > models.MaxAuditLogPageSize {
+		filter.PageSize = models.MaxAuditLogPageSize
+	}
+
+	entries, total, err := s.auditLogRepo.ListAuditLogEntries(ctx, &filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.AuditLogListResponse{
+		Entries:  entries,
+		Page:     filter.Page,
+		PageSize: filter.PageSize,
+		Total:    total,
+	}, nil
+}
+
+// ExportEntries 依時間先後逐筆讀出符合條件的所有紀錄，供匯出 CSV
+func (s *AuditLogService) ExportEntries(ctx context.Context, query *models.AuditLogQuery, fn func(entry *models.AuditLogEntry) error) error {
+	if err := validateAuditLogRange(query); err != nil {
+		return err
+	}
+	return s.auditLogRepo.ExportAuditLogEntries(ctx, query, fn)
+}
+
+func validateAuditLogRange(query *models.AuditLogQuery) error {
+	if query.From != nil && query.To != nil && !query.From.Before(*query.To) {
+		return apperrors.ErrInvalidTimeRange
+	}
+	return nil
+}
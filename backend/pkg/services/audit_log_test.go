@@ -0,0 +1,183 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"smart-learning-backend/pkg/models"
+	"testing"
+	"time"
+)
+
+// MockAuditLogRepository 實現了 AuditLogRepositoryInterface 介面用於測試
+type MockAuditLogRepository struct {
+	entries        []models.AuditLogEntry
+	lastQuery      *models.AuditLogQuery
+	shouldFailNext string
+}
+
+func NewMockAuditLogRepository() *MockAuditLogRepository {
+	return &MockAuditLogRepository{
+		entries: make([]models.AuditLogEntry, 0),
+	}
+}
+
+func (m *MockAuditLogRepository) CreateAuditLogEntry(ctx context.Context, entry *models.AuditLogEntry) error {
+	if m.shouldFailNext == "CreateAuditLogEntry" {
+		m.shouldFailNext = ""
+		return errors.New("database error")
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	entry.ID = int64(len(m.entries) + 1)
+	entry.CreatedAt = time.Now()
+	m.entries = append(m.entries, *entry)
+	return nil
+}
+
+func (m *MockAuditLogRepository) ListAuditLogEntries(ctx context.Context, query *models.AuditLogQuery) ([]models.AuditLogEntry, int, error) {
+	m.lastQuery = query
+	entries := make([]models.AuditLogEntry, 0)
+	for _, entry := range m.entries {
+		if query.EventType == "" || entry.EventType == query.EventType {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, len(entries), nil
+}
+
+func (m *MockAuditLogRepository) ExportAuditLogEntries(ctx context.Context, query *models.AuditLogQuery, fn func(entry *models.AuditLogEntry) error) error {
+	m.lastQuery = query
+	for i := range m.entries {
+		if err := fn(&m.entries[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// eventTypes 回傳已寫入的事件類型，依寫入順序
+func (m *MockAuditLogRepository) eventTypes() []string {
+	types := make([]string, 0, len(m.entries))
+	for _, entry := range m.entries {
+		types = append(types, entry.EventType)
+	}
+	return types
+}
+
+func TestAuditLogService_Record(t *testing.T) {
+	repo := NewMockAuditLogRepository()
+	service := NewAuditLogService(repo)
+
+	service.Record(context.Background(), models.NewAuditLogEntry(models.AuditEventLogout, models.ClientInfo{RequestID: "req-1"}, 1, 1, nil))
+	if len(repo.entries) != 1 || repo.entries[0].RequestID != "req-1" {
+		t.Fatalf("Record() entries = %+v", repo.entries)
+	}
+
+	// 寫入失敗不影響呼叫端
+	repo.shouldFailNext = "CreateAuditLogEntry"
+	service.Record(context.Background(), models.NewAuditLogEntry(models.AuditEventLogout, models.ClientInfo{}, 1, 1, nil))
+	if len(repo.entries) != 1 {
+		t.Errorf("Record() entries = %d, want 1", len(repo.entries))
+	}
+
+	// 請求已取消（用戶端中斷連線）時仍寫入紀錄
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	service.Record(ctx, models.NewAuditLogEntry(models.AuditEventLogout, models.ClientInfo{}, 1, 1, nil))
+	if len(repo.entries) != 2 {
+		t.Errorf("Record() with canceled context entries = %d, want 2", len(repo.entries))
+	}
+}
+
+func TestAuditLogService_ListEntries(t *testing.T) {
+	from := time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		query        models.AuditLogQuery
+		wantPage     int
+		wantPageSize int
+		wantErr      string
+	}{
+		{
+			name:         "預設分頁",
+			query:        models.AuditLogQuery{},
+			wantPage:     1,
+			wantPageSize: models.DefaultAuditLogPageSize,
+		},
+		{
+			name:         "每頁筆數上限",
+			query:        models.AuditLogQuery{Page: 3, PageSize: 1000},
+			wantPage:     3,
+			wantPageSize: models.MaxAuditLogPageSize,
+		},
+		{
+			name:    "起始時間晚於結束時間",
+			query:   models.AuditLogQuery{From: &from, To: &to},
+			wantErr: "invalid time range",
+		},
+		{
+			name:    "起始與結束時間相同",
+			query:   models.AuditLogQuery{From: &from, To: &from},
+			wantErr: "invalid time range",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := NewMockAuditLogRepository()
+			repo.CreateAuditLogEntry(context.Background(), models.NewAuditLogEntry(models.AuditEventRegister, models.ClientInfo{}, 1, 1, nil))
+
+			result, err := NewAuditLogService(repo).ListEntries(context.Background(), &tt.query)
+			if tt.wantErr != "" {
+				if err == nil || !contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ListEntries() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ListEntries() unexpected error = %v", err)
+			}
+			if result.Page != tt.wantPage || result.PageSize != tt.wantPageSize {
+				t.Errorf("ListEntries() page = %d/%d, want %d/%d", result.Page, result.PageSize, tt.wantPage, tt.wantPageSize)
+			}
+			if repo.lastQuery.PageSize != tt.wantPageSize {
+				t.Errorf("ListEntries() repo page size = %d, want %d", repo.lastQuery.PageSize, tt.wantPageSize)
+			}
+			if result.Total != 1 || len(result.Entries) != 1 {
+				t.Errorf("ListEntries() = %+v", result)
+			}
+		})
+	}
+}
+
+func TestAuditLogService_ExportEntries(t *testing.T) {
+	repo := NewMockAuditLogRepository()
+	service := NewAuditLogService(repo)
+	service.Record(context.Background(), models.NewAuditLogEntry(models.AuditEventRegister, models.ClientInfo{}, 1, 1, nil))
+	service.Record(context.Background(), models.NewAuditLogEntry(models.AuditEventLogout, models.ClientInfo{}, 1, 1, nil))
+
+	exported := 0
+	if err := service.ExportEntries(context.Background(), &models.AuditLogQuery{}, func(entry *models.AuditLogEntry) error {
+		exported++
+		return nil
+	}); err != nil {
+		t.Fatalf("ExportEntries() unexpected error = %v", err)
+	}
+	if exported != 2 {
+		t.Errorf("ExportEntries() exported %d entries, want 2", exported)
+	}
+
+	from := time.Now()
+	to := from.Add(-time.Hour)
+	err := service.ExportEntries(context.Background(), &models.AuditLogQuery{From: &from, To: &to}, func(entry *models.AuditLogEntry) error {
+		t.Error("ExportEntries() exported entries for an invalid range")
+		return nil
+	})
+	if err == nil || !contains(err.Error(), "invalid time range") {
+		t.Errorf("ExportEntries() error = %v, want invalid time range", err)
+	}
+}
@@ -1,42 +1,83 @@
 package services
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"regexp"
+	"smart-learning-backend/pkg/apperrors"
+	"smart-learning-backend/pkg/authtoken"
 	"smart-learning-backend/pkg/interfaces"
+	"smart-learning-backend/pkg/logging"
 	"smart-learning-backend/pkg/models"
 	"smart-learning-backend/pkg/utils"
+	"time"
 )
 
 type AuthService struct {
-	userRepo interfaces.UserRepositoryInterface
+	userRepo         interfaces.UserRepositoryInterface
+	refreshTokenRepo interfaces.RefreshTokenRepositoryInterface
+	revokedTokenRepo interfaces.RevokedTokenRepositoryInterface
+	sessionRepo      interfaces.SessionRepositoryInterface
+	emailVerifier    interfaces.EmailVerificationServiceInterface
+	mfaService       interfaces.MFAServiceInterface
+	loginAttemptRepo interfaces.LoginAttemptRepositoryInterface
+	roleRepo         interfaces.RoleRepositoryInterface
+	passwordPolicy   interfaces.PasswordPolicyInterface
+	auditLog         interfaces.AuditRecorderInterface
+	tokens           *authtoken.Issuer
 }
 
-func NewAuthService(userRepo interfaces.UserRepositoryInterface) *AuthService {
+func NewAuthService(
+	userRepo interfaces.UserRepositoryInterface,
+	refreshTokenRepo interfaces.RefreshTokenRepositoryInterface,
+	revokedTokenRepo interfaces.RevokedTokenRepositoryInterface,
+	sessionRepo interfaces.SessionRepositoryInterface,
+	emailVerifier interfaces.EmailVerificationServiceInterface,
+	mfaService interfaces.MFAServiceInterface,
+	loginAttemptRepo interfaces.LoginAttemptRepositoryInterface,
+	roleRepo interfaces.RoleRepositoryInterface,
+	passwordPolicy interfaces.PasswordPolicyInterface,
+	auditLog interfaces.AuditRecorderInterface,
+	tokens *authtoken.Issuer,
+) *AuthService {
 	return &AuthService{
-		userRepo: userRepo,
+		userRepo:         userRepo,
+		refreshTokenRepo: refreshTokenRepo,
+		revokedTokenRepo: revokedTokenRepo,
+		sessionRepo:      sessionRepo,
+		emailVerifier:    emailVerifier,
+		mfaService:       mfaService,
+		loginAttemptRepo: loginAttemptRepo,
+		roleRepo:         roleRepo,
+		passwordPolicy:   passwordPolicy,
+		auditLog:         auditLog,
+		tokens:           tokens,
 	}
 }
 
-func (s *AuthService) Register(req *models.RegisterRequest) (*models.AuthResponse, error) {
+func (s *AuthService) Register(ctx context.Context, req *models.RegisterRequest, client models.ClientInfo) (*models.AuthResponse, error) {
 	// 驗證密碼確認
 	if req.Password != req.ConfirmPassword {
-		return nil, fmt.Errorf("passwords do not match")
+		return nil, apperrors.ErrPasswordsDoNotMatch
 	}
 	
 	// 驗證用戶名格式
-	usernameRegex := regexp.MustCompile(`^[a-zA-Z0-9_]+$`)
-	if !usernameRegex.MatchString(req.Username) {
-		return nil, fmt.Errorf("username can only contain letters, numbers and underscores")
+	if !usernamePattern.MatchString(req.Username) {
+		return nil, apperrors.ErrInvalidUsername
+	}
+	
+	// 密碼政策（長度、字元種類、強度、個人資料、外洩清單）
+	if err := s.passwordPolicy.Validate(req.Password, req.Username, req.Email); err != nil {
+		return nil, err
 	}
 	
 	// 檢查用戶是否已存在
-	exists, err := s.userRepo.CheckUserExists(req.Email, req.Username)
+	exists, err := s.userRepo.CheckUserExists(ctx, req.Email, req.Username)
 	if err != nil {
 		return nil, fmt.Errorf("failed to check user existence: %w", err)
 	}
 	if exists {
-		return nil, fmt.Errorf("user already exists")
+		return nil, apperrors.ErrUserAlreadyExists
 	}
 	
 	// 雜湊密碼
@@ -51,54 +92,384 @@ func (s *AuthService) Register(req *models.RegisterRequest) (*models.AuthRespons
 		Username:      req.Username,
 		PasswordHash:  hashedPassword,
 		LearningLevel: 1, // 預設等級
+		Role:          models.RoleStudent,
 	}
 	
-	err = s.userRepo.CreateUser(user)
+	err = s.userRepo.CreateUser(ctx, user)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create user: %w", err)
 	}
-	
-	// 生成 JWT
-	token, err := utils.GenerateJWT(user.ID, user.Email, user.Username)
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate token: %w", err)
+	s.auditLog.Record(ctx, models.NewAuditLogEntry(models.AuditEventRegister, client, user.ID, user.ID, nil))
+
+	// 寄送 email 驗證連結；寄送失敗不影響註冊，用戶可稍後重新寄送
+	if err := s.emailVerifier.SendVerification(ctx, user); err != nil {
+		logging.FromContext(ctx).Error("驗證郵件寄送失敗", "target_user_id", user.ID, "error", err)
 	}
 	
-	return &models.AuthResponse{
-		User:  *user,
-		Token: token,
-	}, nil
+	// 建立裝置會話並生成 access token 與 refresh token
+	return s.issueTokens(ctx, user, client)
 }
 
-func (s *AuthService) Login(req *models.LoginRequest) (*models.AuthResponse, error) {
-	// 根據 email 查找用戶
-	user, err := s.userRepo.GetUserByEmail(req.Email)
+func (s *AuthService) Login(ctx context.Context, req *models.LoginRequest, client models.ClientInfo) (*models.AuthResponse, error) {
+	// 帳號或來源 IP 鎖定期間直接拒絕，不進行密碼比對
+	keys := attemptKeys(req.Email, client)
+	if err := s.checkLoginLockout(ctx, keys); err != nil {
+		s.auditLockedLogin(ctx, err, 0, req.Email, client)
+		return nil, err
+	}
+
+	// 根據 email 查找用戶（不存在的帳號同樣計入失敗次數，避免洩漏帳號是否存在）；
+	// 資料庫錯誤不計入失敗次數，避免短暫中斷時鎖定正常用戶
+	user, err := s.userRepo.GetUserByEmail(ctx, req.Email)
 	if err != nil {
-		return nil, fmt.Errorf("invalid credentials")
+		if !errors.Is(err, apperrors.ErrUserNotFound) {
+			return nil, fmt.Errorf("failed to get user: %w", err)
+		}
+		s.recordLoginFailure(ctx, keys)
+		s.auditLoginFailure(ctx, 0, req.Email, client, "unknown_account")
+		return nil, apperrors.ErrInvalidCredentials
 	}
 	
 	// 驗證密碼
 	err = utils.VerifyPassword(user.PasswordHash, req.Password)
 	if err != nil {
-		return nil, fmt.Errorf("invalid credentials")
+		s.recordLoginFailure(ctx, keys)
+		s.auditLoginFailure(ctx, user.ID, req.Email, client, "invalid_password")
+		return nil, apperrors.ErrInvalidCredentials
+	}
+	s.clearLoginFailures(ctx, req.Email)
+
+	// 密碼雜湊使用舊演算法或舊參數時，趁有明文密碼時升級
+	if utils.PasswordNeedsRehash(user.PasswordHash) {
+		s.rehashPassword(ctx, user, req.Password)
+	}
+
+	return s.CompleteLogin(ctx, user, client)
+}
+
+// rehashPassword 以目前的雜湊設定重新雜湊密碼並保存；失敗時僅記錄，不影響登入
+func (s *AuthService) rehashPassword(ctx context.Context, user *models.User, password string) {
+	hashedPassword, err := utils.HashPassword(password)
+	if err == nil {
+		err = s.userRepo.UpdatePasswordHash(ctx, user.ID, hashedPassword)
+	}
+	if err != nil {
+		logging.FromContext(ctx).Error("密碼雜湊升級失敗", "target_user_id", user.ID, "error", err)
+		return
+	}
+
+	user.PasswordHash = hashedPassword
+}
+
+// CompleteLogin 在密碼或外部身分驗證通過後完成登入。
+// 啟用兩步驟驗證的用戶先取得短效的待驗證 token，驗證碼通過後才建立會話。
+func (s *AuthService) CompleteLogin(ctx context.Context, user *models.User, client models.ClientInfo) (*models.AuthResponse, error) {
+	if user.SuspendedAt != nil {
+		s.auditLoginFailure(ctx, user.ID, user.Email, client, "account_suspended")
+		return nil, apperrors.ErrAccountSuspended
+	}
+
+	mfaEnabled, err := s.mfaService.IsEnabled(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check mfa status: %w", err)
+	}
+	if mfaEnabled {
+		mfaToken, err := s.tokens.GenerateMFAPendingToken(user.ID)
+		if err != nil {
+			return nil, err
+		}
+		return &models.AuthResponse{
+			MFARequired: true,
+			MFAToken:    mfaToken,
+			ExpiresIn:   int64(authtoken.MFAPendingTokenTTL.Seconds()),
+		}, nil
 	}
 	
-	// 生成 JWT
-	token, err := utils.GenerateJWT(user.ID, user.Email, user.Username)
+	// 建立裝置會話並生成 access token 與 refresh token
+	return s.issueLoginTokens(ctx, user, client, nil)
+}
+
+// VerifyMFA 以待驗證 token 與 TOTP 驗證碼（或復原碼）換取正式的 token
+func (s *AuthService) VerifyMFA(ctx context.Context, req *models.MFAVerifyRequest, client models.ClientInfo) (*models.AuthResponse, error) {
+	claims, err := s.tokens.ValidateMFAPendingToken(req.MFAToken)
+	if err != nil {
+		return nil, apperrors.ErrInvalidMFAToken
+	}
+
+	user, err := s.userRepo.GetUserByID(ctx, claims.UserID)
+	if err != nil {
+		return nil, apperrors.ErrInvalidMFAToken
+	}
+
+	// 驗證碼錯誤同樣計入帳號的失敗次數，避免在待驗證 token 有效期間暴力猜測
+	keys := attemptKeys(user.Email, client)
+	if err := s.checkLoginLockout(ctx, keys); err != nil {
+		s.auditLockedLogin(ctx, err, user.ID, user.Email, client)
+		return nil, err
+	}
+
+	if err := s.mfaService.VerifyCode(ctx, user.ID, req.Code); err != nil {
+		if errors.Is(err, apperrors.ErrInvalidMFACode) {
+			s.recordLoginFailure(ctx, keys)
+			s.auditLoginFailure(ctx, user.ID, user.Email, client, "invalid_mfa_code")
+			// 登入時驗證碼錯誤屬於驗證失敗，與已登入時設定兩步驟驗證的錯誤區分
+			return nil, apperrors.ErrInvalidMFALoginCode
+		}
+		return nil, err
+	}
+	s.clearLoginFailures(ctx, user.Email)
+
+	return s.issueLoginTokens(ctx, user, client, map[string]string{"mfa": "verified"})
+}
+
+// issueLoginTokens 簽發登入的 token 並記錄稽核日誌；停用的帳號記錄為登入失敗
+func (s *AuthService) issueLoginTokens(ctx context.Context, user *models.User, client models.ClientInfo, metadata map[string]string) (*models.AuthResponse, error) {
+	authResponse, err := s.issueTokens(ctx, user, client)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrAccountSuspended) {
+			s.auditLoginFailure(ctx, user.ID, user.Email, client, "account_suspended")
+		}
+		return nil, err
+	}
+
+	s.auditLog.Record(ctx, models.NewAuditLogEntry(models.AuditEventLoginSuccess, client, user.ID, user.ID, metadata))
+	return authResponse, nil
+}
+
+// auditLoginFailure 將登入失敗記錄到稽核日誌與請求日誌；userID 為 0 表示帳號不存在或尚未查得，email 為嘗試登入的帳號
+func (s *AuthService) auditLoginFailure(ctx context.Context, userID int, email string, client models.ClientInfo, reason string) {
+	logging.FromContext(ctx).Warn("登入失敗", "target_user_id", userID, "email", email, "reason", reason)
+	s.auditLog.Record(ctx, models.NewAuditLogEntry(models.AuditEventLoginFailure, client, 0, userID, map[string]string{
+		"email":  email,
+		"reason": reason,
+	}))
+}
+
+// auditLockedLogin 若錯誤為登入鎖定，記錄鎖定期間的登入嘗試
+func (s *AuthService) auditLockedLogin(ctx context.Context, err error, userID int, email string, client models.ClientInfo) {
+	var lockedErr *models.AccountLockedError
+	if errors.As(err, &lockedErr) {
+		s.auditLoginFailure(ctx, userID, email, client, "account_locked")
+	}
+}
+
+func (s *AuthService) GetUserByID(ctx context.Context, id int) (*models.User, error) {
+	user, err := s.userRepo.GetUserByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrUserNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	return user, nil
+}
+
+func (s *AuthService) Refresh(ctx context.Context, req *models.RefreshRequest, client models.ClientInfo) (*models.AuthResponse, error) {
+	// 只有 token 或用戶不存在時才回應 token 無效；資料庫錯誤時用戶端應保留 token 稍後重試
+	stored, err := s.refreshTokenRepo.GetRefreshTokenByHash(ctx, utils.HashToken(req.RefreshToken))
+	if err != nil {
+		if errors.Is(err, apperrors.ErrRefreshTokenNotFound) {
+			return nil, apperrors.ErrInvalidRefreshToken
+		}
+		return nil, fmt.Errorf("failed to get refresh token: %w", err)
+	}
+
+	// 已輪換或撤銷的 token 再次被使用，視為遭竊並撤銷整個 token 家族
+	if stored.RevokedAt != nil {
+		return nil, s.revokeFamilyOnReuse(ctx, stored.UserID, stored.FamilyID, client)
+	}
+
+	if time.Now().After(stored.ExpiresAt) {
+		return nil, apperrors.ErrRefreshTokenExpired
+	}
+
+	user, err := s.userRepo.GetUserByID(ctx, stored.UserID)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrUserNotFound) {
+			return nil, apperrors.ErrInvalidRefreshToken
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user.SuspendedAt != nil {
+		return nil, apperrors.ErrAccountSuspended
+	}
+
+	next, plainToken, err := s.newRefreshToken(user.ID, stored.FamilyID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.refreshTokenRepo.RotateRefreshToken(ctx, stored.ID, next); err != nil {
+		if errors.Is(err, apperrors.ErrRefreshTokenAlreadyUsed) {
+			return nil, s.revokeFamilyOnReuse(ctx, stored.UserID, stored.FamilyID, client)
+		}
+		return nil, fmt.Errorf("failed to rotate refresh token: %w", err)
+	}
+
+	return s.newAuthResponse(ctx, user, stored.FamilyID, plainToken)
+}
+
+// Logout 撤銷目前的 access token 並結束所屬的裝置會話
+func (s *AuthService) Logout(ctx context.Context, userID int, sessionID, jti string, expiresAt time.Time) error {
+	if jti != "" {
+		if err := s.revokedTokenRepo.RevokeToken(ctx, jti, userID, expiresAt); err != nil {
+			return fmt.Errorf("failed to revoke access token: %w", err)
+		}
+	}
+
+	if sessionID == "" {
+		return nil
+	}
+
+	if err := s.revokeSession(ctx, userID, sessionID); err != nil && !errors.Is(err, apperrors.ErrSessionNotFound) {
+		return err
+	}
+
+	return nil
+}
+
+// ListSessions 列出用戶仍有效的裝置會話，並標記目前使用中的會話
+func (s *AuthService) ListSessions(ctx context.Context, userID int, currentSessionID string) ([]models.Session, error) {
+	sessions, err := s.sessionRepo.ListActiveSessions(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID == currentSessionID
+	}
+
+	return sessions, nil
+}
+
+// RevokeSession 撤銷用戶指定的裝置會話及其 refresh token
+func (s *AuthService) RevokeSession(ctx context.Context, userID int, sessionID string) error {
+	return s.revokeSession(ctx, userID, sessionID)
+}
+
+// RevokeOtherSessions 撤銷目前會話以外的所有裝置會話（登出其他裝置）
+func (s *AuthService) RevokeOtherSessions(ctx context.Context, userID int, currentSessionID string) (int64, error) {
+	revoked, err := s.sessionRepo.RevokeOtherSessions(ctx, userID, currentSessionID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	if err := s.refreshTokenRepo.RevokeUserRefreshTokens(ctx, userID, currentSessionID); err != nil {
+		return 0, fmt.Errorf("failed to revoke refresh tokens: %w", err)
+	}
+
+	return revoked, nil
+}
+
+// issueTokens 為用戶建立新的裝置會話，並簽發 access token 與該會話的第一個 refresh token。
+// 會話 ID 同時作為 refresh token 家族 ID。
+func (s *AuthService) issueTokens(ctx context.Context, user *models.User, client models.ClientInfo) (*models.AuthResponse, error) {
+	// 停用的帳號不可登入；須在取消刪除之前檢查，避免停用期間的登入嘗試取消刪除
+	if user.SuspendedAt != nil {
+		return nil, apperrors.ErrAccountSuspended
+	}
+
+	// 刪除帳號的寬限期內重新登入即取消刪除
+	if user.DeletionScheduledAt != nil {
+		if err := s.userRepo.CancelDeletion(ctx, user.ID); err != nil {
+			return nil, fmt.Errorf("failed to cancel account deletion: %w", err)
+		}
+		user.DeletionScheduledAt = nil
+	}
+
+	sessionID, err := utils.GenerateRandomID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate session id: %w", err)
+	}
+
+	session := &models.Session{
+		ID:        sessionID,
+		UserID:    user.ID,
+		UserAgent: client.UserAgent,
+		IPAddress: client.IPAddress,
+	}
+	if err := s.sessionRepo.CreateSession(ctx, session); err != nil {
+		return nil, fmt.Errorf("failed to create session: %w", err)
+	}
+
+	refreshToken, plainToken, err := s.newRefreshToken(user.ID, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.refreshTokenRepo.CreateRefreshToken(ctx, refreshToken); err != nil {
+		return nil, fmt.Errorf("failed to store refresh token: %w", err)
+	}
+
+	return s.newAuthResponse(ctx, user, sessionID, plainToken)
+}
+
+// newAuthResponse 載入用戶角色的權限，簽發帶有偏好語系的 access token 並組合認證響應
+func (s *AuthService) newAuthResponse(ctx context.Context, user *models.User, sessionID, refreshToken string) (*models.AuthResponse, error) {
+	permissions, err := s.roleRepo.GetRolePermissions(ctx, user.Role)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load permissions: %w", err)
+	}
+
+	locale := ""
+	if user.Locale != nil {
+		locale = *user.Locale
+	}
+	token, err := s.tokens.GenerateJWT(user.ID, user.Email, user.Username, sessionID, user.Role, permissions, locale)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate token: %w", err)
 	}
-	
+
 	return &models.AuthResponse{
-		User:  *user,
-		Token: token,
+		User:         user,
+		Token:        token,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(s.tokens.AccessTokenTTL().Seconds()),
 	}, nil
 }
 
-func (s *AuthService) GetUserByID(id int) (*models.User, error) {
-	user, err := s.userRepo.GetUserByID(id)
+// revokeSession 撤銷裝置會話以及同一家族的所有 refresh token
+func (s *AuthService) revokeSession(ctx context.Context, userID int, sessionID string) error {
+	if err := s.sessionRepo.RevokeSession(ctx, userID, sessionID); err != nil {
+		if errors.Is(err, apperrors.ErrSessionNotFound) {
+			return err
+		}
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+
+	if err := s.refreshTokenRepo.RevokeRefreshTokenFamily(ctx, sessionID); err != nil {
+		return fmt.Errorf("failed to revoke refresh token family: %w", err)
+	}
+
+	return nil
+}
+
+// revokeFamilyOnReuse 撤銷遭重複使用的 refresh token 所屬的家族與裝置會話，並記錄稽核日誌
+func (s *AuthService) revokeFamilyOnReuse(ctx context.Context, userID int, familyID string, client models.ClientInfo) error {
+	if err := s.refreshTokenRepo.RevokeRefreshTokenFamily(ctx, familyID); err != nil {
+		return fmt.Errorf("failed to revoke refresh token family: %w", err)
+	}
+	if err := s.sessionRepo.RevokeSession(ctx, userID, familyID); err != nil && !errors.Is(err, apperrors.ErrSessionNotFound) {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+	s.auditLog.Record(ctx, models.NewAuditLogEntry(models.AuditEventTokenRevoked, client, 0, userID, map[string]string{
+		"reason":     "refresh_token_reuse",
+		"session_id": familyID,
+	}))
+	return apperrors.ErrRefreshTokenReused
+}
+
+// newRefreshToken 產生新的 refresh token，回傳待儲存的紀錄與明文 token
+func (s *AuthService) newRefreshToken(userID int, familyID string) (*models.RefreshToken, string, error) {
+	plainToken, err := utils.GenerateOpaqueToken()
 	if err != nil {
-		return nil, fmt.Errorf("user not found")
+		return nil, "", fmt.Errorf("failed to generate refresh token: %w", err)
 	}
-	return user, nil
+
+	return &models.RefreshToken{
+		UserID:    userID,
+		FamilyID:  familyID,
+		TokenHash: utils.HashToken(plainToken),
+		ExpiresAt: time.Now().Add(s.tokens.RefreshTokenTTL()),
+	}, plainToken, nil
 }
\ No newline at end of file
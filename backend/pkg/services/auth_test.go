@@ -1,11 +1,25 @@
 package services
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"errors"
+	"smart-learning-backend/pkg/apperrors"
+	"smart-learning-backend/pkg/authtoken"
+	"smart-learning-backend/pkg/authtoken/authtokentest"
+	"smart-learning-backend/pkg/config"
+	"smart-learning-backend/pkg/interfaces"
+	"smart-learning-backend/pkg/logging"
 	"smart-learning-backend/pkg/models"
+	"smart-learning-backend/pkg/passwordpolicy"
+	"smart-learning-backend/pkg/repositories"
 	"smart-learning-backend/pkg/utils"
+	"strings"
 	"testing"
 	"time"
+
+	"golang.org/x/crypto/bcrypt"
 )
 
 // MockUserRepository 實現了 UserRepositoryInterface 介面用於測試  
@@ -20,7 +34,7 @@ func NewMockUserRepository() *MockUserRepository {
 	}
 }
 
-func (m *MockUserRepository) CreateUser(user *models.User) error {
+func (m *MockUserRepository) CreateUser(ctx context.Context, user *models.User) error {
 	if m.shouldFailNext == "CreateUser" {
 		m.shouldFailNext = ""
 		return errors.New("database error")
@@ -29,7 +43,7 @@ func (m *MockUserRepository) CreateUser(user *models.User) error {
 	// 檢查用戶是否已存在
 	for _, existingUser := range m.users {
 		if existingUser.Email == user.Email || existingUser.Username == user.Username {
-			return errors.New("user already exists")
+			return apperrors.ErrUserAlreadyExists
 		}
 	}
 
@@ -42,7 +56,7 @@ func (m *MockUserRepository) CreateUser(user *models.User) error {
 	return nil
 }
 
-func (m *MockUserRepository) GetUserByEmail(email string) (*models.User, error) {
+func (m *MockUserRepository) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
 	if m.shouldFailNext == "GetUserByEmail" {
 		m.shouldFailNext = ""
 		return nil, errors.New("database error")
@@ -53,10 +67,10 @@ func (m *MockUserRepository) GetUserByEmail(email string) (*models.User, error)
 			return &user, nil
 		}
 	}
-	return nil, errors.New("user not found")
+	return nil, apperrors.ErrUserNotFound
 }
 
-func (m *MockUserRepository) GetUserByID(id int) (*models.User, error) {
+func (m *MockUserRepository) GetUserByID(ctx context.Context, id int) (*models.User, error) {
 	if m.shouldFailNext == "GetUserByID" {
 		m.shouldFailNext = ""
 		return nil, errors.New("database error")
@@ -67,10 +81,10 @@ func (m *MockUserRepository) GetUserByID(id int) (*models.User, error) {
 			return &user, nil
 		}
 	}
-	return nil, errors.New("user not found")
+	return nil, apperrors.ErrUserNotFound
 }
 
-func (m *MockUserRepository) CheckUserExists(email, username string) (bool, error) {
+func (m *MockUserRepository) CheckUserExists(ctx context.Context, email, username string) (bool, error) {
 	if m.shouldFailNext == "CheckUserExists" {
 		m.shouldFailNext = ""
 		return false, errors.New("database error")
@@ -84,13 +98,587 @@ func (m *MockUserRepository) CheckUserExists(email, username string) (bool, erro
 	return false, nil
 }
 
+func (m *MockUserRepository) UpdatePasswordHash(ctx context.Context, id int, passwordHash string) error {
+	if m.shouldFailNext == "UpdatePasswordHash" {
+		m.shouldFailNext = ""
+		return errors.New("database error")
+	}
+
+	for i := range m.users {
+		if m.users[i].ID == id {
+			m.users[i].PasswordHash = passwordHash
+			return nil
+		}
+	}
+	return apperrors.ErrUserNotFound
+}
+
+func (m *MockUserRepository) MarkEmailVerified(ctx context.Context, id int) error {
+	for i := range m.users {
+		if m.users[i].ID == id {
+			if m.users[i].EmailVerifiedAt == nil {
+				now := time.Now()
+				m.users[i].EmailVerifiedAt = &now
+			}
+			return nil
+		}
+	}
+	return apperrors.ErrUserNotFound
+}
+
+// updateIfUnchanged 模擬 updated_at 樂觀鎖：資料未被修改時套用 apply 並更新 updated_at
+func (m *MockUserRepository) updateIfUnchanged(method string, id int, expectedUpdatedAt time.Time, apply func(user *models.User) error) error {
+	if m.shouldFailNext == method {
+		m.shouldFailNext = ""
+		return errors.New("database error")
+	}
+
+	for i := range m.users {
+		if m.users[i].ID != id {
+			continue
+		}
+		if !m.users[i].UpdatedAt.Equal(expectedUpdatedAt) {
+			return apperrors.ErrUserUpdateConflict
+		}
+		if err := apply(&m.users[i]); err != nil {
+			return err
+		}
+		m.users[i].UpdatedAt = m.users[i].UpdatedAt.Add(time.Millisecond)
+		return nil
+	}
+	return apperrors.ErrUserNotFound
+}
+
+func (m *MockUserRepository) UpdateProfile(ctx context.Context, user *models.User, expectedUpdatedAt time.Time) error {
+	for _, existing := range m.users {
+		if existing.ID != user.ID && existing.Username == user.Username {
+			return apperrors.ErrUsernameTaken
+		}
+	}
+
+	return m.updateIfUnchanged("UpdateProfile", user.ID, expectedUpdatedAt, func(stored *models.User) error {
+		stored.Username = user.Username
+		stored.LearningLevel = user.LearningLevel
+		stored.AvatarURL = user.AvatarURL
+		user.UpdatedAt = stored.UpdatedAt.Add(time.Millisecond)
+		return nil
+	})
+}
+
+func (m *MockUserRepository) UpdatePassword(ctx context.Context, id int, passwordHash string, expectedUpdatedAt time.Time) error {
+	return m.updateIfUnchanged("UpdatePassword", id, expectedUpdatedAt, func(stored *models.User) error {
+		stored.PasswordHash = passwordHash
+		return nil
+	})
+}
+
+func (m *MockUserRepository) UpdateEmail(ctx context.Context, id int, email string, expectedUpdatedAt time.Time) error {
+	for _, existing := range m.users {
+		if existing.ID != id && existing.Email == email {
+			return apperrors.ErrEmailAlreadyInUse
+		}
+	}
+
+	return m.updateIfUnchanged("UpdateEmail", id, expectedUpdatedAt, func(stored *models.User) error {
+		now := time.Now()
+		stored.Email = email
+		stored.EmailVerifiedAt = &now
+		return nil
+	})
+}
+
+func (m *MockUserRepository) ScheduleDeletion(ctx context.Context, id int, at time.Time) error {
+	if m.shouldFailNext == "ScheduleDeletion" {
+		m.shouldFailNext = ""
+		return errors.New("database error")
+	}
+
+	for i := range m.users {
+		if m.users[i].ID == id {
+			m.users[i].DeletionScheduledAt = &at
+			return nil
+		}
+	}
+	return apperrors.ErrUserNotFound
+}
+
+func (m *MockUserRepository) CancelDeletion(ctx context.Context, id int) error {
+	for i := range m.users {
+		if m.users[i].ID == id {
+			m.users[i].DeletionScheduledAt = nil
+		}
+	}
+	return nil
+}
+
+func (m *MockUserRepository) ListUsersDueForDeletion(ctx context.Context, before time.Time, limit int) ([]models.User, error) {
+	users := []models.User{}
+	for _, user := range m.users {
+		if user.DeletionScheduledAt != nil && !user.DeletionScheduledAt.After(before) && len(users) < limit {
+			users = append(users, user)
+		}
+	}
+	return users, nil
+}
+
+func (m *MockUserRepository) DeleteScheduledUser(ctx context.Context, id int, before time.Time) error {
+	if m.shouldFailNext == "DeleteScheduledUser" {
+		m.shouldFailNext = ""
+		return errors.New("database error")
+	}
+
+	for i, user := range m.users {
+		if user.ID == id && user.DeletionScheduledAt != nil && !user.DeletionScheduledAt.After(before) {
+			m.users = append(m.users[:i], m.users[i+1:]...)
+			return nil
+		}
+	}
+	return apperrors.ErrUserNotFound
+}
+
+func (m *MockUserRepository) ListUsers(ctx context.Context, filter *models.ListUsersRequest) ([]models.User, int, error) {
+	if m.shouldFailNext == "ListUsers" {
+		m.shouldFailNext = ""
+		return nil, 0, errors.New("database error")
+	}
+
+	matched := []models.User{}
+	for _, user := range m.users {
+		if filter.Role != "" && user.Role != filter.Role {
+			continue
+		}
+		if filter.Query != "" && !strings.Contains(user.Email, filter.Query) && !strings.Contains(user.Username, filter.Query) {
+			continue
+		}
+		matched = append(matched, user)
+	}
+
+	start := (filter.Page - 1) * filter.PageSize
+	if start > len(matched) {
+		start = len(matched)
+	}
+	end := start + filter.PageSize
+	if end > len(matched) {
+		end = len(matched)
+	}
+	return matched[start:end], len(matched), nil
+}
+
+func (m *MockUserRepository) SuspendUser(ctx context.Context, id int, reason string) error {
+	for i := range m.users {
+		if m.users[i].ID == id {
+			if m.users[i].SuspendedAt != nil {
+				return apperrors.ErrUserAlreadySuspended
+			}
+			now := time.Now()
+			m.users[i].SuspendedAt = &now
+			m.users[i].SuspensionReason = &reason
+			return nil
+		}
+	}
+	return apperrors.ErrUserNotFound
+}
+
+func (m *MockUserRepository) UnsuspendUser(ctx context.Context, id int) error {
+	for i := range m.users {
+		if m.users[i].ID == id {
+			if m.users[i].SuspendedAt == nil {
+				return apperrors.ErrUserNotSuspended
+			}
+			m.users[i].SuspendedAt = nil
+			m.users[i].SuspensionReason = nil
+			return nil
+		}
+	}
+	return apperrors.ErrUserNotFound
+}
+
+func (m *MockUserRepository) UpdateRole(ctx context.Context, id int, role string) error {
+	if role != models.RoleStudent && role != models.RoleTeacher && role != models.RoleAdmin {
+		return apperrors.ErrRoleNotFound
+	}
+	for i := range m.users {
+		if m.users[i].ID == id {
+			m.users[i].Role = role
+			return nil
+		}
+	}
+	return apperrors.ErrUserNotFound
+}
+
 func (m *MockUserRepository) SetShouldFailNext(method string) {
 	m.shouldFailNext = method
 }
 
+// MockRefreshTokenRepository 實現了 RefreshTokenRepositoryInterface 介面用於測試
+type MockRefreshTokenRepository struct {
+	tokens         []models.RefreshToken
+	shouldFailNext string
+}
+
+func NewMockRefreshTokenRepository() *MockRefreshTokenRepository {
+	return &MockRefreshTokenRepository{
+		tokens: make([]models.RefreshToken, 0),
+	}
+}
+
+func (m *MockRefreshTokenRepository) CreateRefreshToken(ctx context.Context, token *models.RefreshToken) error {
+	if m.shouldFailNext == "CreateRefreshToken" {
+		m.shouldFailNext = ""
+		return errors.New("database error")
+	}
+
+	token.ID = len(m.tokens) + 1
+	token.CreatedAt = time.Now()
+	m.tokens = append(m.tokens, *token)
+	return nil
+}
+
+func (m *MockRefreshTokenRepository) GetRefreshTokenByHash(ctx context.Context, tokenHash string) (*models.RefreshToken, error) {
+	if m.shouldFailNext == "GetRefreshTokenByHash" {
+		m.shouldFailNext = ""
+		return nil, errors.New("database error")
+	}
+
+	for _, token := range m.tokens {
+		if token.TokenHash == tokenHash {
+			return &token, nil
+		}
+	}
+	return nil, apperrors.ErrRefreshTokenNotFound
+}
+
+func (m *MockRefreshTokenRepository) RotateRefreshToken(ctx context.Context, oldID int, next *models.RefreshToken) error {
+	if m.shouldFailNext == "RotateRefreshToken" {
+		m.shouldFailNext = ""
+		return apperrors.ErrRefreshTokenAlreadyUsed
+	}
+
+	for i := range m.tokens {
+		if m.tokens[i].ID == oldID {
+			if m.tokens[i].RevokedAt != nil {
+				return apperrors.ErrRefreshTokenAlreadyUsed
+			}
+			if err := m.CreateRefreshToken(context.Background(), next); err != nil {
+				return err
+			}
+			now := time.Now()
+			m.tokens[i].RevokedAt = &now
+			m.tokens[i].ReplacedBy = &next.ID
+			return nil
+		}
+	}
+	return apperrors.ErrRefreshTokenNotFound
+}
+
+func (m *MockRefreshTokenRepository) RevokeRefreshTokenFamily(ctx context.Context, familyID string) error {
+	now := time.Now()
+	for i := range m.tokens {
+		if m.tokens[i].FamilyID == familyID && m.tokens[i].RevokedAt == nil {
+			m.tokens[i].RevokedAt = &now
+		}
+	}
+	return nil
+}
+
+func (m *MockRefreshTokenRepository) RevokeUserRefreshTokens(ctx context.Context, userID int, exceptFamilyID string) error {
+	now := time.Now()
+	for i := range m.tokens {
+		if m.tokens[i].UserID == userID && m.tokens[i].FamilyID != exceptFamilyID && m.tokens[i].RevokedAt == nil {
+			m.tokens[i].RevokedAt = &now
+		}
+	}
+	return nil
+}
+
+func (m *MockRefreshTokenRepository) SetShouldFailNext(method string) {
+	m.shouldFailNext = method
+}
+
+// MockRevokedTokenRepository 實現了 RevokedTokenRepositoryInterface 介面用於測試
+type MockRevokedTokenRepository struct {
+	tokens         map[string]time.Time
+	shouldFailNext string
+}
+
+func NewMockRevokedTokenRepository() *MockRevokedTokenRepository {
+	return &MockRevokedTokenRepository{
+		tokens: make(map[string]time.Time),
+	}
+}
+
+func (m *MockRevokedTokenRepository) RevokeToken(ctx context.Context, jti string, userID int, expiresAt time.Time) error {
+	if m.shouldFailNext == "RevokeToken" {
+		m.shouldFailNext = ""
+		return errors.New("database error")
+	}
+	m.tokens[jti] = expiresAt
+	return nil
+}
+
+func (m *MockRevokedTokenRepository) IsTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	_, ok := m.tokens[jti]
+	return ok, nil
+}
+
+func (m *MockRevokedTokenRepository) DeleteExpiredTokens(ctx context.Context) (int64, error) {
+	return 0, nil
+}
+
+func (m *MockRevokedTokenRepository) SetShouldFailNext(method string) {
+	m.shouldFailNext = method
+}
+
+// MockSessionRepository 實現了 SessionRepositoryInterface 介面用於測試
+type MockSessionRepository struct {
+	sessions       []models.Session
+	shouldFailNext string
+}
+
+func NewMockSessionRepository() *MockSessionRepository {
+	return &MockSessionRepository{
+		sessions: make([]models.Session, 0),
+	}
+}
+
+func (m *MockSessionRepository) CreateSession(ctx context.Context, session *models.Session) error {
+	if m.shouldFailNext == "CreateSession" {
+		m.shouldFailNext = ""
+		return errors.New("database error")
+	}
+
+	session.CreatedAt = time.Now()
+	session.LastSeenAt = time.Now()
+	m.sessions = append(m.sessions, *session)
+	return nil
+}
+
+func (m *MockSessionRepository) ListActiveSessions(ctx context.Context, userID int) ([]models.Session, error) {
+	sessions := make([]models.Session, 0)
+	for _, session := range m.sessions {
+		if session.UserID == userID && session.RevokedAt == nil {
+			sessions = append(sessions, session)
+		}
+	}
+	return sessions, nil
+}
+
+func (m *MockSessionRepository) GetSession(ctx context.Context, userID int, id string) (*models.Session, error) {
+	for _, session := range m.sessions {
+		if session.ID == id && session.UserID == userID && session.RevokedAt == nil {
+			return &session, nil
+		}
+	}
+	return nil, apperrors.ErrSessionNotFound
+}
+
+func (m *MockSessionRepository) TouchSession(ctx context.Context, id string) (bool, error) {
+	for i := range m.sessions {
+		if m.sessions[i].ID == id && m.sessions[i].RevokedAt == nil {
+			m.sessions[i].LastSeenAt = time.Now()
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m *MockSessionRepository) RevokeSession(ctx context.Context, userID int, id string) error {
+	for i := range m.sessions {
+		if m.sessions[i].ID == id && m.sessions[i].UserID == userID && m.sessions[i].RevokedAt == nil {
+			now := time.Now()
+			m.sessions[i].RevokedAt = &now
+			return nil
+		}
+	}
+	return apperrors.ErrSessionNotFound
+}
+
+func (m *MockSessionRepository) RevokeOtherSessions(ctx context.Context, userID int, keepID string) (int64, error) {
+	var revoked int64
+	now := time.Now()
+	for i := range m.sessions {
+		if m.sessions[i].UserID == userID && m.sessions[i].ID != keepID && m.sessions[i].RevokedAt == nil {
+			m.sessions[i].RevokedAt = &now
+			revoked++
+		}
+	}
+	return revoked, nil
+}
+
+func (m *MockSessionRepository) SetShouldFailNext(method string) {
+	m.shouldFailNext = method
+}
+
+// MockRoleRepository 實現了 RoleRepositoryInterface 介面用於測試
+type MockRoleRepository struct {
+	permissions    map[string][]string
+	shouldFailNext string
+}
+
+func NewMockRoleRepository() *MockRoleRepository {
+	return &MockRoleRepository{
+		permissions: map[string][]string{
+			models.RoleStudent: {models.PermissionLearningRead, models.PermissionLearningWrite},
+			models.RoleTeacher: {models.PermissionContentManage, models.PermissionLearningRead, models.PermissionStudentsRead},
+			models.RoleAdmin:   {models.PermissionUsersManage, models.PermissionUsersRead},
+		},
+	}
+}
+
+func (m *MockRoleRepository) GetRolePermissions(ctx context.Context, role string) ([]string, error) {
+	if m.shouldFailNext == "GetRolePermissions" {
+		m.shouldFailNext = ""
+		return nil, errors.New("database error")
+	}
+	return m.permissions[role], nil
+}
+
+func (m *MockRoleRepository) SetShouldFailNext(method string) {
+	m.shouldFailNext = method
+}
+
+// mockDeps 集中管理 AuthService 測試所需的 mock 依賴
+type mockDeps struct {
+	userRepo         *MockUserRepository
+	refreshTokenRepo *MockRefreshTokenRepository
+	revokedTokenRepo *MockRevokedTokenRepository
+	sessionRepo      *MockSessionRepository
+	resetTokenRepo   *MockPasswordResetTokenRepository
+	verifyTokenRepo  *MockEmailVerificationTokenRepository
+	mfaRepo          *MockMFARepository
+	mailer           *MockMailer
+	loginAttemptRepo *repositories.MemoryLoginAttemptRepository
+	roleRepo         *MockRoleRepository
+	magicLinkRepo    *MockMagicLinkTokenRepository
+	rateLimitRepo    *repositories.MemoryRateLimitRepository
+	identityRepo     *MockUserIdentityRepository
+	apiKeyRepo       *MockAPIKeyRepository
+	auditLogRepo     *MockAuditLogRepository
+	passwordPolicy   *passwordpolicy.Policy
+	tokens           *authtoken.Issuer
+}
+
+func newMockDeps(userRepo *MockUserRepository) *mockDeps {
+	return &mockDeps{
+		userRepo:         userRepo,
+		refreshTokenRepo: NewMockRefreshTokenRepository(),
+		revokedTokenRepo: NewMockRevokedTokenRepository(),
+		sessionRepo:      NewMockSessionRepository(),
+		resetTokenRepo:   NewMockPasswordResetTokenRepository(),
+		verifyTokenRepo:  NewMockEmailVerificationTokenRepository(),
+		mfaRepo:          NewMockMFARepository(),
+		mailer:           &MockMailer{},
+		loginAttemptRepo: repositories.NewMemoryLoginAttemptRepository(0, 0),
+		roleRepo:         NewMockRoleRepository(),
+		magicLinkRepo:    NewMockMagicLinkTokenRepository(),
+		rateLimitRepo:    repositories.NewMemoryRateLimitRepository(0),
+		identityRepo:     &MockUserIdentityRepository{},
+		apiKeyRepo:       NewMockAPIKeyRepository(),
+		auditLogRepo:     NewMockAuditLogRepository(),
+		// 僅檢查長度，其餘規則由各自的測試以預設政策驗證
+		passwordPolicy: passwordpolicy.New(passwordpolicy.Config{MinLength: 8}),
+		tokens:         authtokentest.NewIssuer(),
+	}
+}
+
+func (d *mockDeps) authService() *AuthService {
+	return NewAuthService(
+		d.userRepo,
+		d.refreshTokenRepo,
+		d.revokedTokenRepo,
+		d.sessionRepo,
+		d.emailVerificationService(),
+		d.mfaService(),
+		d.loginAttemptRepo,
+		d.roleRepo,
+		d.passwordPolicy,
+		d.auditLogService(),
+		d.tokens,
+	)
+}
+
+func (d *mockDeps) auditLogService() *AuditLogService {
+	return NewAuditLogService(d.auditLogRepo)
+}
+
+func (d *mockDeps) mfaService() *MFAService {
+	return NewMFAService(d.mfaRepo, d.userRepo, "Smart Learning")
+}
+
+func (d *mockDeps) emailVerificationService() *EmailVerificationService {
+	return NewEmailVerificationService(d.userRepo, d.verifyTokenRepo, d.mailer, "http://localhost:5173")
+}
+
+func (d *mockDeps) passwordResetService() *PasswordResetService {
+	return NewPasswordResetService(d.userRepo, d.resetTokenRepo, d.refreshTokenRepo, d.sessionRepo, d.mailer, d.passwordPolicy, d.auditLogService(), "http://localhost:5173/")
+}
+
+func (d *mockDeps) profileService() *ProfileService {
+	return NewProfileService(d.userRepo, d.sessionRepo, d.refreshTokenRepo, d.emailVerificationService(), d.passwordPolicy)
+}
+
+func (d *mockDeps) magicLinkService() *MagicLinkService {
+	return NewMagicLinkService(d.userRepo, d.magicLinkRepo, d.rateLimitRepo, d.mailer, d.authService(), "http://localhost:5173")
+}
+
+func (d *mockDeps) accountService(purgers ...interfaces.UserDataPurgerInterface) *AccountService {
+	return NewAccountService(
+		d.userRepo,
+		d.sessionRepo,
+		d.refreshTokenRepo,
+		d.identityRepo,
+		d.apiKeyRepo,
+		d.mfaService(),
+		d.loginAttemptRepo,
+		d.mailer,
+		purgers,
+		nil,
+	)
+}
+
+func (d *mockDeps) accountServiceWithExporters(exporters ...interfaces.UserDataExporterInterface) *AccountService {
+	return NewAccountService(
+		d.userRepo,
+		d.sessionRepo,
+		d.refreshTokenRepo,
+		d.identityRepo,
+		d.apiKeyRepo,
+		d.mfaService(),
+		d.loginAttemptRepo,
+		d.mailer,
+		nil,
+		exporters,
+	)
+}
+
+func (d *mockDeps) adminService() *AdminService {
+	return NewAdminService(d.userRepo, d.sessionRepo, d.refreshTokenRepo, d.roleRepo, d.passwordResetService(), d.tokens)
+}
+
+// loginTestUser 建立測試用戶並登入一次
+func loginTestUser(t *testing.T) (*mockDeps, *models.AuthResponse) {
+	t.Helper()
+
+	deps := newMockDeps(NewMockUserRepository())
+	deps.userRepo.CreateUser(context.Background(), &models.User{
+		Email:        "test@example.com",
+		Username:     "testuser",
+		PasswordHash: func() string { hash, _ := utils.HashPassword("password123"); return hash }(),
+	})
+
+	result, err := deps.authService().Login(context.Background(), &models.LoginRequest{
+		Email:    "test@example.com",
+		Password: "password123",
+	}, models.ClientInfo{UserAgent: "Mozilla/5.0", IPAddress: "203.0.113.1"})
+	if err != nil {
+		t.Fatalf("Login() unexpected error = %v", err)
+	}
+	return deps, result
+}
+
 func TestNewAuthService(t *testing.T) {
 	mockRepo := NewMockUserRepository()
-	authService := NewAuthService(mockRepo)
+	authService := newMockDeps(mockRepo).authService()
 
 	if authService == nil {
 		t.Fatal("NewAuthService() returned nil")
@@ -99,6 +687,18 @@ func TestNewAuthService(t *testing.T) {
 	if authService.userRepo == nil {
 		t.Fatal("NewAuthService() userRepo is nil")
 	}
+
+	if authService.refreshTokenRepo == nil {
+		t.Fatal("NewAuthService() refreshTokenRepo is nil")
+	}
+
+	if authService.revokedTokenRepo == nil {
+		t.Fatal("NewAuthService() revokedTokenRepo is nil")
+	}
+
+	if authService.sessionRepo == nil {
+		t.Fatal("NewAuthService() sessionRepo is nil")
+	}
 }
 
 func TestAuthService_Register(t *testing.T) {
@@ -146,6 +746,18 @@ func TestAuthService_Register(t *testing.T) {
 			wantError: true,
 			errorContains: "username can only contain",
 		},
+		{
+			name: "密碼過短",
+			request: &models.RegisterRequest{
+				Email:           "test@example.com",
+				Username:        "testuser",
+				Password:        "short12",
+				ConfirmPassword: "short12",
+			},
+			setupMock: func(m *MockUserRepository) {},
+			wantError: true,
+			errorContains: "password does not meet policy: min_length",
+		},
 		{
 			name: "用戶已存在",
 			request: &models.RegisterRequest{
@@ -160,7 +772,7 @@ func TestAuthService_Register(t *testing.T) {
 					Email:    "existing@example.com",
 					Username: "existinguser",
 				}
-				m.CreateUser(user)
+				m.CreateUser(context.Background(), user)
 			},
 			wantError: true,
 			errorContains: "user already exists",
@@ -185,9 +797,9 @@ func TestAuthService_Register(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			mockRepo := NewMockUserRepository()
 			tt.setupMock(mockRepo)
-			authService := NewAuthService(mockRepo)
+			authService := newMockDeps(mockRepo).authService()
 
-			result, err := authService.Register(tt.request)
+			result, err := authService.Register(context.Background(), tt.request, models.ClientInfo{})
 
 			if tt.wantError {
 				if err == nil {
@@ -226,6 +838,54 @@ func TestAuthService_Register(t *testing.T) {
 	}
 }
 
+func TestAuthService_Register_PasswordPolicy(t *testing.T) {
+	tests := []struct {
+		name      string
+		password  string
+		wantRules []string
+	}{
+		{name: "常見密碼", password: "password123", wantRules: []string{models.PasswordRuleStrength}},
+		{name: "包含用戶名", password: "Testuser#4821", wantRules: []string{models.PasswordRuleUserInfo}},
+		{name: "符合政策", password: "gH7#kLq9!vZ"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deps := newMockDeps(NewMockUserRepository())
+			deps.passwordPolicy = passwordpolicy.New(passwordpolicy.DefaultConfig)
+
+			_, err := deps.authService().Register(context.Background(), &models.RegisterRequest{
+				Email:           "test@example.com",
+				Username:        "testuser",
+				Password:        tt.password,
+				ConfirmPassword: tt.password,
+			}, models.ClientInfo{})
+
+			if len(tt.wantRules) == 0 {
+				if err != nil {
+					t.Fatalf("Register() unexpected error = %v", err)
+				}
+				return
+			}
+
+			var policyErr *models.PasswordPolicyError
+			if !errors.As(err, &policyErr) {
+				t.Fatalf("Register() error = %v, want *models.PasswordPolicyError", err)
+			}
+			var rules []string
+			for _, violation := range policyErr.Violations {
+				rules = append(rules, violation.Rule)
+			}
+			if strings.Join(rules, ",") != strings.Join(tt.wantRules, ",") {
+				t.Errorf("Register() violations = %v, want %v", rules, tt.wantRules)
+			}
+			if len(deps.userRepo.users) != 0 {
+				t.Error("Register() created a user with a rejected password")
+			}
+		})
+	}
+}
+
 func TestAuthService_Login(t *testing.T) {
 	// 先創建一個測試用戶
 	testUser := &models.User{
@@ -249,7 +909,7 @@ func TestAuthService_Login(t *testing.T) {
 				Password: "password123",
 			},
 			setupMock: func(m *MockUserRepository) {
-				m.CreateUser(testUser)
+				m.CreateUser(context.Background(), testUser)
 			},
 			wantError: false,
 		},
@@ -270,7 +930,7 @@ func TestAuthService_Login(t *testing.T) {
 				Password: "wrongpassword",
 			},
 			setupMock: func(m *MockUserRepository) {
-				m.CreateUser(testUser)
+				m.CreateUser(context.Background(), testUser)
 			},
 			wantError: true,
 			errorContains: "invalid credentials",
@@ -285,7 +945,7 @@ func TestAuthService_Login(t *testing.T) {
 				m.SetShouldFailNext("GetUserByEmail")
 			},
 			wantError: true,
-			errorContains: "invalid credentials",
+			errorContains: "failed to get user: database error",
 		},
 	}
 
@@ -293,9 +953,9 @@ func TestAuthService_Login(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			mockRepo := NewMockUserRepository()
 			tt.setupMock(mockRepo)
-			authService := NewAuthService(mockRepo)
+			authService := newMockDeps(mockRepo).authService()
 
-			result, err := authService.Login(tt.request)
+			result, err := authService.Login(context.Background(), tt.request, models.ClientInfo{})
 
 			if tt.wantError {
 				if err == nil {
@@ -325,6 +985,94 @@ func TestAuthService_Login(t *testing.T) {
 	}
 }
 
+func TestAuthService_Login_RehashesLegacyPassword(t *testing.T) {
+	legacyHash, _ := utils.NewBcryptHasher(bcrypt.MinCost).Hash("password123")
+
+	tests := []struct {
+		name         string
+		failUpdate   bool
+		wantUpgraded bool
+	}{
+		{name: "bcrypt 雜湊升級為 argon2id", wantUpgraded: true},
+		{name: "升級失敗不影響登入", failUpdate: true, wantUpgraded: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := NewMockUserRepository()
+			mockRepo.CreateUser(context.Background(), &models.User{
+				Email:        "test@example.com",
+				Username:     "testuser",
+				PasswordHash: legacyHash,
+			})
+			if tt.failUpdate {
+				mockRepo.SetShouldFailNext("UpdatePasswordHash")
+			}
+
+			_, err := newMockDeps(mockRepo).authService().Login(context.Background(), &models.LoginRequest{
+				Email:    "test@example.com",
+				Password: "password123",
+			}, models.ClientInfo{})
+			if err != nil {
+				t.Fatalf("Login() unexpected error = %v", err)
+			}
+
+			stored := mockRepo.users[0].PasswordHash
+			if upgraded := stored != legacyHash; upgraded != tt.wantUpgraded {
+				t.Fatalf("password hash upgraded = %v, want %v", upgraded, tt.wantUpgraded)
+			}
+			if tt.wantUpgraded {
+				if utils.PasswordNeedsRehash(stored) {
+					t.Errorf("stored hash %s still needs rehash", stored)
+				}
+				if err := utils.VerifyPassword(stored, "password123"); err != nil {
+					t.Errorf("upgraded hash cannot be verified: %v", err)
+				}
+			}
+		})
+	}
+
+	// 已是目前設定的雜湊不會重寫
+	deps, _ := loginTestUser(t)
+	before := deps.userRepo.users[0].PasswordHash
+	deps.authService().Login(context.Background(), &models.LoginRequest{Email: "test@example.com", Password: "password123"}, models.ClientInfo{})
+	if deps.userRepo.users[0].PasswordHash != before {
+		t.Error("Login() rewrote an up-to-date password hash")
+	}
+}
+
+func TestAuthService_Login_LogsFailureWithRequestLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := logging.New(config.LogConfig{Level: "info", Format: "json"}, &buf)
+	if err != nil {
+		t.Fatalf("logging.New() error = %v", err)
+	}
+	ctx := logging.WithLogger(context.Background(), logger.With("request_id", "req-123"))
+
+	deps, _ := loginTestUser(t)
+	_, err = deps.authService().Login(ctx, &models.LoginRequest{Email: "test@example.com", Password: "wrong-password"}, models.ClientInfo{})
+	if !errors.Is(err, apperrors.ErrInvalidCredentials) {
+		t.Fatalf("Login() error = %v, want ErrInvalidCredentials", err)
+	}
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("log output is not a single JSON entry: %v\n%s", err, buf.String())
+	}
+	if entry["msg"] != "登入失敗" || entry["request_id"] != "req-123" || entry["reason"] != "invalid_password" {
+		t.Errorf("log entry = %v, want login failure with request_id", entry)
+	}
+	if entry["target_user_id"] != float64(deps.userRepo.users[0].ID) {
+		t.Errorf("target_user_id = %v, want %d", entry["target_user_id"], deps.userRepo.users[0].ID)
+	}
+	if entry["email"] != "t***@example.com" {
+		t.Errorf("email = %v, want redacted address", entry["email"])
+	}
+	if strings.Contains(buf.String(), "wrong-password") {
+		t.Errorf("log output leaked the password: %s", buf.String())
+	}
+}
+
 func TestAuthService_GetUserByID(t *testing.T) {
 	// 創建測試用戶
 	testUser := &models.User{
@@ -344,7 +1092,7 @@ func TestAuthService_GetUserByID(t *testing.T) {
 			name:   "成功獲取用戶",
 			userID: 1,
 			setupMock: func(m *MockUserRepository) {
-				m.CreateUser(testUser)
+				m.CreateUser(context.Background(), testUser)
 			},
 			wantError: false,
 		},
@@ -362,7 +1110,7 @@ func TestAuthService_GetUserByID(t *testing.T) {
 				m.SetShouldFailNext("GetUserByID")
 			},
 			wantError:     true,
-			errorContains: "user not found",
+			errorContains: "failed to get user: database error",
 		},
 	}
 
@@ -370,9 +1118,9 @@ func TestAuthService_GetUserByID(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			mockRepo := NewMockUserRepository()
 			tt.setupMock(mockRepo)
-			authService := NewAuthService(mockRepo)
+			authService := newMockDeps(mockRepo).authService()
 
-			result, err := authService.GetUserByID(tt.userID)
+			result, err := authService.GetUserByID(context.Background(), tt.userID)
 
 			if tt.wantError {
 				if err == nil {
@@ -399,6 +1147,292 @@ func TestAuthService_GetUserByID(t *testing.T) {
 	}
 }
 
+// 資料庫錯誤不可被當成用戶不存在，否則中斷或逾時會以 404 回應
+func TestUserLookup_RepositoryError(t *testing.T) {
+	level := 2
+	calls := []struct {
+		name string
+		call func(d *mockDeps) error
+	}{
+		{"AuthService.GetUserByID", func(d *mockDeps) error { _, err := d.authService().GetUserByID(context.Background(), 1); return err }},
+		{"AuthService.UnlockAccount", func(d *mockDeps) error { return d.authService().UnlockAccount(context.Background(), 1) }},
+		{"AdminService.GetUser", func(d *mockDeps) error { _, err := d.adminService().GetUser(context.Background(), 1); return err }},
+		{"AccountService.ExportUserData", func(d *mockDeps) error { _, err := d.accountService().ExportUserData(context.Background(), 1); return err }},
+		{"AccountService.RequestDeletion", func(d *mockDeps) error {
+			_, err := d.accountService().RequestDeletion(context.Background(), 1, "", &models.DeleteAccountRequest{CurrentPassword: "password123"})
+			return err
+		}},
+		{"MFAService.Setup", func(d *mockDeps) error { _, err := d.mfaService().Setup(context.Background(), 1); return err }},
+		{"EmailVerificationService.ResendVerification", func(d *mockDeps) error {
+			return d.emailVerificationService().ResendVerification(context.Background(), 1)
+		}},
+		{"PasswordResetService.ForceReset", func(d *mockDeps) error { return d.passwordResetService().ForceReset(context.Background(), 1) }},
+		{"ProfileService.UpdateProfile", func(d *mockDeps) error {
+			_, err := d.profileService().UpdateProfile(context.Background(), 1, &models.UpdateProfileRequest{LearningLevel: &level})
+			return err
+		}},
+		{"ProfileService.ChangePassword", func(d *mockDeps) error {
+			return d.profileService().ChangePassword(context.Background(), 1, "", &models.ChangePasswordRequest{
+				CurrentPassword: "password123", NewPassword: "Blue-kite-42", ConfirmPassword: "Blue-kite-42",
+			})
+		}},
+		{"ProfileService.RequestEmailChange", func(d *mockDeps) error {
+			return d.profileService().RequestEmailChange(context.Background(), 1, &models.ChangeEmailRequest{
+				NewEmail: "new@example.com", CurrentPassword: "password123",
+			})
+		}},
+	}
+
+	for _, tt := range calls {
+		t.Run(tt.name, func(t *testing.T) {
+			deps, _ := loginTestUser(t)
+			deps.userRepo.SetShouldFailNext("GetUserByID")
+
+			err := tt.call(deps)
+			if err == nil || !contains(err.Error(), "database error") {
+				t.Fatalf("error = %v, want the repository error", err)
+			}
+			if appErr, ok := apperrors.As(err); ok {
+				t.Errorf("error = %v mapped to %s (%d), want an internal error", err, appErr.Code, appErr.Status)
+			}
+		})
+	}
+}
+
+func TestAuthService_Refresh(t *testing.T) {
+	// 建立測試用戶並登入取得 refresh token
+	setup := func(t *testing.T) (*AuthService, *MockRefreshTokenRepository, string) {
+		deps, result := loginTestUser(t)
+		if result.RefreshToken == "" {
+			t.Fatal("Login() refresh token is empty")
+		}
+		return deps.authService(), deps.refreshTokenRepo, result.RefreshToken
+	}
+
+	t.Run("成功換發", func(t *testing.T) {
+		authService, refreshRepo, refreshToken := setup(t)
+
+		result, err := authService.Refresh(context.Background(), &models.RefreshRequest{RefreshToken: refreshToken}, models.ClientInfo{})
+		if err != nil {
+			t.Fatalf("Refresh() unexpected error = %v", err)
+		}
+		if result.Token == "" {
+			t.Error("Refresh() token is empty")
+		}
+		if result.RefreshToken == "" || result.RefreshToken == refreshToken {
+			t.Error("Refresh() did not rotate refresh token")
+		}
+		if len(refreshRepo.tokens) != 2 {
+			t.Fatalf("Refresh() stored tokens = %d, want 2", len(refreshRepo.tokens))
+		}
+		if refreshRepo.tokens[0].RevokedAt == nil {
+			t.Error("Refresh() did not revoke the old refresh token")
+		}
+		if refreshRepo.tokens[0].FamilyID != refreshRepo.tokens[1].FamilyID {
+			t.Error("Refresh() rotated token is not in the same family")
+		}
+	})
+
+	t.Run("重複使用舊 token 撤銷整個家族", func(t *testing.T) {
+		authService, refreshRepo, refreshToken := setup(t)
+
+		if _, err := authService.Refresh(context.Background(), &models.RefreshRequest{RefreshToken: refreshToken}, models.ClientInfo{}); err != nil {
+			t.Fatalf("Refresh() unexpected error = %v", err)
+		}
+
+		_, err := authService.Refresh(context.Background(), &models.RefreshRequest{RefreshToken: refreshToken}, models.ClientInfo{})
+		if err == nil || !contains(err.Error(), "refresh token reuse detected") {
+			t.Fatalf("Refresh() error = %v, expected reuse detection", err)
+		}
+		for _, token := range refreshRepo.tokens {
+			if token.RevokedAt == nil {
+				t.Errorf("Refresh() token %d in family was not revoked", token.ID)
+			}
+		}
+	})
+
+	t.Run("併發輪換視為重複使用", func(t *testing.T) {
+		authService, refreshRepo, refreshToken := setup(t)
+		refreshRepo.SetShouldFailNext("RotateRefreshToken")
+
+		_, err := authService.Refresh(context.Background(), &models.RefreshRequest{RefreshToken: refreshToken}, models.ClientInfo{})
+		if err == nil || !contains(err.Error(), "refresh token reuse detected") {
+			t.Fatalf("Refresh() error = %v, expected reuse detection", err)
+		}
+	})
+
+	t.Run("過期的 token", func(t *testing.T) {
+		authService, refreshRepo, refreshToken := setup(t)
+		refreshRepo.tokens[0].ExpiresAt = time.Now().Add(-time.Minute)
+
+		_, err := authService.Refresh(context.Background(), &models.RefreshRequest{RefreshToken: refreshToken}, models.ClientInfo{})
+		if err == nil || !contains(err.Error(), "refresh token expired") {
+			t.Fatalf("Refresh() error = %v, expected expiry error", err)
+		}
+	})
+
+	t.Run("未知的 token", func(t *testing.T) {
+		authService, _, _ := setup(t)
+
+		_, err := authService.Refresh(context.Background(), &models.RefreshRequest{RefreshToken: "unknown"}, models.ClientInfo{})
+		if err == nil || !contains(err.Error(), "invalid refresh token") {
+			t.Fatalf("Refresh() error = %v, expected invalid token error", err)
+		}
+	})
+
+	// 資料庫錯誤不可回應 token 無效，否則用戶端會丟棄仍有效的 token
+	t.Run("讀取 token 時資料庫錯誤", func(t *testing.T) {
+		authService, refreshRepo, refreshToken := setup(t)
+		refreshRepo.SetShouldFailNext("GetRefreshTokenByHash")
+
+		_, err := authService.Refresh(context.Background(), &models.RefreshRequest{RefreshToken: refreshToken}, models.ClientInfo{})
+		if _, ok := apperrors.As(err); err == nil || ok {
+			t.Fatalf("Refresh() error = %v, want an internal error", err)
+		}
+		if refreshRepo.tokens[0].RevokedAt != nil {
+			t.Error("Refresh() revoked the token on a database error")
+		}
+	})
+
+	t.Run("讀取用戶時資料庫錯誤", func(t *testing.T) {
+		deps, result := loginTestUser(t)
+		deps.userRepo.SetShouldFailNext("GetUserByID")
+
+		_, err := deps.authService().Refresh(context.Background(), &models.RefreshRequest{RefreshToken: result.RefreshToken}, models.ClientInfo{})
+		if _, ok := apperrors.As(err); err == nil || ok {
+			t.Fatalf("Refresh() error = %v, want an internal error", err)
+		}
+	})
+
+	t.Run("用戶已刪除", func(t *testing.T) {
+		deps, result := loginTestUser(t)
+		deps.userRepo.users = nil
+
+		_, err := deps.authService().Refresh(context.Background(), &models.RefreshRequest{RefreshToken: result.RefreshToken}, models.ClientInfo{})
+		if err == nil || !contains(err.Error(), "invalid refresh token") {
+			t.Fatalf("Refresh() error = %v, expected invalid token error", err)
+		}
+	})
+}
+
+func TestAuthService_Logout(t *testing.T) {
+	t.Run("撤銷 access token 與目前會話", func(t *testing.T) {
+		deps, result := loginTestUser(t)
+		authService := deps.authService()
+		sessionID := deps.sessionRepo.sessions[0].ID
+		expiresAt := time.Now().Add(time.Minute)
+
+		if err := authService.Logout(context.Background(), 1, sessionID, "jti-1", expiresAt); err != nil {
+			t.Fatalf("Logout() unexpected error = %v", err)
+		}
+		if revoked, _ := deps.revokedTokenRepo.IsTokenRevoked(context.Background(), "jti-1"); !revoked {
+			t.Error("Logout() did not revoke access token")
+		}
+		if deps.sessionRepo.sessions[0].RevokedAt == nil {
+			t.Error("Logout() did not revoke session")
+		}
+		if deps.refreshTokenRepo.tokens[0].RevokedAt == nil {
+			t.Error("Logout() did not revoke refresh token family")
+		}
+		if _, err := authService.Refresh(context.Background(), &models.RefreshRequest{RefreshToken: result.RefreshToken}, models.ClientInfo{}); err == nil {
+			t.Error("Refresh() succeeded after logout")
+		}
+	})
+
+	t.Run("不可結束其他用戶的會話", func(t *testing.T) {
+		deps, _ := loginTestUser(t)
+		sessionID := deps.sessionRepo.sessions[0].ID
+
+		if err := deps.authService().Logout(context.Background(), 2, sessionID, "jti-2", time.Now().Add(time.Minute)); err != nil {
+			t.Fatalf("Logout() unexpected error = %v", err)
+		}
+		if deps.sessionRepo.sessions[0].RevokedAt != nil {
+			t.Error("Logout() revoked a session owned by another user")
+		}
+		if deps.refreshTokenRepo.tokens[0].RevokedAt != nil {
+			t.Error("Logout() revoked a refresh token owned by another user")
+		}
+	})
+
+	t.Run("撤銷清單錯誤", func(t *testing.T) {
+		deps, _ := loginTestUser(t)
+		deps.revokedTokenRepo.SetShouldFailNext("RevokeToken")
+
+		err := deps.authService().Logout(context.Background(), 1, "", "jti-3", time.Now().Add(time.Minute))
+		if err == nil || !contains(err.Error(), "failed to revoke access token") {
+			t.Fatalf("Logout() error = %v, expected revoke failure", err)
+		}
+	})
+}
+
+func TestAuthService_Sessions(t *testing.T) {
+	// 同一用戶在三個裝置登入
+	deps := newMockDeps(NewMockUserRepository())
+	deps.userRepo.CreateUser(context.Background(), &models.User{
+		Email:        "test@example.com",
+		Username:     "testuser",
+		PasswordHash: func() string { hash, _ := utils.HashPassword("password123"); return hash }(),
+	})
+	authService := deps.authService()
+	for _, agent := range []string{"Lab PC", "Phone", "Laptop"} {
+		_, err := authService.Login(context.Background(), &models.LoginRequest{
+			Email:    "test@example.com",
+			Password: "password123",
+		}, models.ClientInfo{UserAgent: agent, IPAddress: "203.0.113.10"})
+		if err != nil {
+			t.Fatalf("Login() unexpected error = %v", err)
+		}
+	}
+	current := deps.sessionRepo.sessions[2].ID
+
+	sessions, err := authService.ListSessions(context.Background(), 1, current)
+	if err != nil {
+		t.Fatalf("ListSessions() unexpected error = %v", err)
+	}
+	if len(sessions) != 3 {
+		t.Fatalf("ListSessions() returned %d sessions, want 3", len(sessions))
+	}
+	for _, session := range sessions {
+		if session.Current != (session.ID == current) {
+			t.Errorf("ListSessions() session %s current = %v", session.ID, session.Current)
+		}
+		if session.IPAddress != "203.0.113.10" {
+			t.Errorf("ListSessions() session IP = %v, want 203.0.113.10", session.IPAddress)
+		}
+	}
+
+	// 結束實驗室電腦的會話
+	labSession := deps.sessionRepo.sessions[0].ID
+	if err := authService.RevokeSession(context.Background(), 1, labSession); err != nil {
+		t.Fatalf("RevokeSession() unexpected error = %v", err)
+	}
+	if err := authService.RevokeSession(context.Background(), 1, labSession); err == nil || !contains(err.Error(), "session not found") {
+		t.Errorf("RevokeSession() error = %v, expected 'session not found'", err)
+	}
+	if err := authService.RevokeSession(context.Background(), 2, deps.sessionRepo.sessions[1].ID); err == nil {
+		t.Error("RevokeSession() allowed revoking another user's session")
+	}
+
+	// 登出其他裝置
+	revoked, err := authService.RevokeOtherSessions(context.Background(), 1, current)
+	if err != nil {
+		t.Fatalf("RevokeOtherSessions() unexpected error = %v", err)
+	}
+	if revoked != 1 {
+		t.Errorf("RevokeOtherSessions() = %d, want 1", revoked)
+	}
+
+	sessions, _ = authService.ListSessions(context.Background(), 1, current)
+	if len(sessions) != 1 || sessions[0].ID != current {
+		t.Errorf("ListSessions() after revoke = %+v, want only current session", sessions)
+	}
+	for _, token := range deps.refreshTokenRepo.tokens {
+		if (token.RevokedAt == nil) != (token.FamilyID == current) {
+			t.Errorf("refresh token family %s revoked state is wrong", token.FamilyID)
+		}
+	}
+}
+
 // 幫助函數：檢查字符串是否包含子字符串
 func contains(s, substr string) bool {
 	return len(s) >= len(substr) && (s == substr || 
@@ -415,4 +1449,200 @@ func containsInMiddle(s, substr string) bool {
 		}
 	}
 	return false
-}
\ No newline at end of file
+}
+
+func TestAuthService_TokenLocale(t *testing.T) {
+	deps := newMockDeps(NewMockUserRepository())
+	locale := "ja"
+	deps.userRepo.CreateUser(context.Background(), &models.User{
+		Email:        "student@example.com",
+		Username:     "student",
+		Role:         models.RoleStudent,
+		Locale:       &locale,
+		PasswordHash: func() string { hash, _ := utils.HashPassword("password123"); return hash }(),
+	})
+
+	result, err := deps.authService().Login(context.Background(), &models.LoginRequest{Email: "student@example.com", Password: "password123"}, models.ClientInfo{})
+	if err != nil {
+		t.Fatalf("Login() unexpected error = %v", err)
+	}
+
+	claims, err := deps.tokens.ValidateJWT(result.Token)
+	if err != nil {
+		t.Fatalf("ValidateJWT() error = %v", err)
+	}
+	if claims.Locale != "ja" {
+		t.Errorf("Locale = %v, want ja", claims.Locale)
+	}
+}
+
+// access token 與 refresh token 的有效期限取自 JWT 設定
+func TestAuthService_TokenTTLFromConfig(t *testing.T) {
+	deps := newMockDeps(NewMockUserRepository())
+	cfg := config.Default().JWT
+	cfg.AccessTokenTTL = 5 * time.Minute
+	cfg.RefreshTokenTTL = 7 * 24 * time.Hour
+	deps.tokens = authtokentest.NewIssuerWithConfig(cfg)
+	deps.userRepo.CreateUser(context.Background(), &models.User{
+		Email:        "test@example.com",
+		Username:     "testuser",
+		PasswordHash: func() string { hash, _ := utils.HashPassword("password123"); return hash }(),
+	})
+
+	result, err := deps.authService().Login(context.Background(), &models.LoginRequest{Email: "test@example.com", Password: "password123"}, models.ClientInfo{})
+	if err != nil {
+		t.Fatalf("Login() unexpected error = %v", err)
+	}
+
+	if result.ExpiresIn != int64((5 * time.Minute).Seconds()) {
+		t.Errorf("ExpiresIn = %d, want 300", result.ExpiresIn)
+	}
+	if len(deps.refreshTokenRepo.tokens) != 1 {
+		t.Fatalf("stored %d refresh tokens, want 1", len(deps.refreshTokenRepo.tokens))
+	}
+	if ttl := time.Until(deps.refreshTokenRepo.tokens[0].ExpiresAt); ttl < 7*24*time.Hour-time.Minute || ttl > 7*24*time.Hour {
+		t.Errorf("refresh token ttl = %v, want 168h", ttl)
+	}
+}
+
+func TestAuthService_TokenPermissions(t *testing.T) {
+	deps := newMockDeps(NewMockUserRepository())
+	deps.userRepo.CreateUser(context.Background(), &models.User{
+		Email:        "teacher@example.com",
+		Username:     "teacher",
+		Role:         models.RoleTeacher,
+		PasswordHash: func() string { hash, _ := utils.HashPassword("password123"); return hash }(),
+	})
+	service := deps.authService()
+	login := &models.LoginRequest{Email: "teacher@example.com", Password: "password123"}
+
+	result, err := service.Login(context.Background(), login, models.ClientInfo{})
+	if err != nil {
+		t.Fatalf("Login() unexpected error = %v", err)
+	}
+
+	claims, err := deps.tokens.ValidateJWT(result.Token)
+	if err != nil {
+		t.Fatalf("ValidateJWT() error = %v", err)
+	}
+	if claims.Role != models.RoleTeacher {
+		t.Errorf("Role = %v, want %v", claims.Role, models.RoleTeacher)
+	}
+	if len(claims.Permissions) != 3 || claims.Permissions[0] != models.PermissionContentManage {
+		t.Errorf("Permissions = %v, want teacher permissions", claims.Permissions)
+	}
+
+	// 無法載入權限時不簽發 token
+	deps.roleRepo.SetShouldFailNext("GetRolePermissions")
+	if _, err := service.Login(context.Background(), login, models.ClientInfo{}); err == nil || !contains(err.Error(), "failed to load permissions") {
+		t.Errorf("Login() error = %v, want failed to load permissions", err)
+	}
+
+	// 註冊的新用戶預設為學生
+	registered, err := service.Register(context.Background(), &models.RegisterRequest{
+		Email:           "student@example.com",
+		Username:        "student",
+		Password:        "password123",
+		ConfirmPassword: "password123",
+	}, models.ClientInfo{})
+	if err != nil {
+		t.Fatalf("Register() unexpected error = %v", err)
+	}
+	if registered.User.Role != models.RoleStudent {
+		t.Errorf("Register() role = %v, want %v", registered.User.Role, models.RoleStudent)
+	}
+}
+
+func TestAuthService_AuditLog(t *testing.T) {
+	client := models.ClientInfo{UserAgent: "Mozilla/5.0", IPAddress: "203.0.113.9", RequestID: "req-1"}
+
+	t.Run("註冊與登入", func(t *testing.T) {
+		deps := newMockDeps(NewMockUserRepository())
+		service := deps.authService()
+
+		registered, err := service.Register(context.Background(), &models.RegisterRequest{
+			Email:           "test@example.com",
+			Username:        "testuser",
+			Password:        "password123",
+			ConfirmPassword: "password123",
+		}, client)
+		if err != nil {
+			t.Fatalf("Register() unexpected error = %v", err)
+		}
+		if _, err := service.Login(context.Background(), &models.LoginRequest{Email: "test@example.com", Password: "password123"}, client); err != nil {
+			t.Fatalf("Login() unexpected error = %v", err)
+		}
+
+		types := deps.auditLogRepo.eventTypes()
+		if len(types) != 2 || types[0] != models.AuditEventRegister || types[1] != models.AuditEventLoginSuccess {
+			t.Fatalf("audit events = %v, want register and login_success", types)
+		}
+		entry := deps.auditLogRepo.entries[1]
+		if entry.ActorID == nil || *entry.ActorID != registered.User.ID || entry.TargetUserID == nil || *entry.TargetUserID != registered.User.ID {
+			t.Errorf("login_success actor/target = %v/%v, want %d", entry.ActorID, entry.TargetUserID, registered.User.ID)
+		}
+		if entry.IPAddress != client.IPAddress || entry.UserAgent != client.UserAgent || entry.RequestID != client.RequestID {
+			t.Errorf("login_success client = %+v, want %+v", entry, client)
+		}
+	})
+
+	t.Run("登入失敗記錄原因", func(t *testing.T) {
+		deps, _ := loginTestUser(t)
+		service := deps.authService()
+		deps.auditLogRepo.entries = nil
+
+		service.Login(context.Background(), &models.LoginRequest{Email: "nobody@example.com", Password: "password123"}, client)
+		for i := 0; i < AccountLockoutThreshold; i++ {
+			service.Login(context.Background(), &models.LoginRequest{Email: "test@example.com", Password: "wrong-password"}, client)
+		}
+		service.Login(context.Background(), &models.LoginRequest{Email: "test@example.com", Password: "password123"}, client)
+
+		entries := deps.auditLogRepo.entries
+		if len(entries) != AccountLockoutThreshold+2 {
+			t.Fatalf("audit events = %v, want %d login failures", deps.auditLogRepo.eventTypes(), AccountLockoutThreshold+2)
+		}
+		for _, entry := range entries {
+			if entry.EventType != models.AuditEventLoginFailure || entry.ActorID != nil {
+				t.Errorf("entry = %+v, want login_failure without actor", entry)
+			}
+		}
+		if entries[0].TargetUserID != nil || entries[0].Metadata["reason"] != "unknown_account" || entries[0].Metadata["email"] != "nobody@example.com" {
+			t.Errorf("unknown account entry = %+v", entries[0])
+		}
+		if entries[1].TargetUserID == nil || *entries[1].TargetUserID != 1 || entries[1].Metadata["reason"] != "invalid_password" {
+			t.Errorf("invalid password entry = %+v", entries[1])
+		}
+		if last := entries[len(entries)-1]; last.Metadata["reason"] != "account_locked" {
+			t.Errorf("locked entry reason = %v, want account_locked", last.Metadata["reason"])
+		}
+	})
+
+	t.Run("停用的帳號", func(t *testing.T) {
+		deps, _ := loginTestUser(t)
+		deps.userRepo.SuspendUser(context.Background(), 1, "spam")
+		deps.auditLogRepo.entries = nil
+
+		deps.authService().Login(context.Background(), &models.LoginRequest{Email: "test@example.com", Password: "password123"}, client)
+		if len(deps.auditLogRepo.entries) != 1 || deps.auditLogRepo.entries[0].Metadata["reason"] != "account_suspended" {
+			t.Errorf("audit entries = %+v, want account_suspended login failure", deps.auditLogRepo.entries)
+		}
+	})
+
+	t.Run("refresh token 重複使用", func(t *testing.T) {
+		deps, result := loginTestUser(t)
+		service := deps.authService()
+		request := &models.RefreshRequest{RefreshToken: result.RefreshToken}
+
+		service.Refresh(context.Background(), request, client)
+		deps.auditLogRepo.entries = nil
+		service.Refresh(context.Background(), request, client)
+
+		entries := deps.auditLogRepo.entries
+		if len(entries) != 1 || entries[0].EventType != models.AuditEventTokenRevoked || entries[0].Metadata["reason"] != "refresh_token_reuse" {
+			t.Fatalf("audit entries = %+v, want token_revoked", entries)
+		}
+		if entries[0].TargetUserID == nil || *entries[0].TargetUserID != 1 || entries[0].Metadata["session_id"] != deps.sessionRepo.sessions[0].ID {
+			t.Errorf("token_revoked entry = %+v", entries[0])
+		}
+	})
+}
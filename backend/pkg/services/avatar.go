@@ -0,0 +1,184 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"smart-learning-backend/pkg/apperrors"
+	"smart-learning-backend/pkg/imaging"
+	"smart-learning-backend/pkg/interfaces"
+	"smart-learning-backend/pkg/logging"
+	"smart-learning-backend/pkg/models"
+	"smart-learning-backend/pkg/utils"
+	"strconv"
+	"strings"
+)
+
+// avatarVersionPattern 為頭像版本（utils.GenerateRandomID 產生的十六進位字串）
+var avatarVersionPattern = regexp.MustCompile(`^[0-9a-f]{32}$`)
+
+// AvatarService 處理頭像上傳：驗證並轉正圖片、產生各尺寸縮圖後存入 BlobStore。
+//
+// 每次上傳產生新的版本，檔案存放於 avatars/{userID}/{version}/{size}.jpg，內容不會再被覆寫。
+// 用戶的 avatar_url 設為穩定的 API 網址 {apiBaseURL}/api/v1/users/{id}/avatar?v={version}，
+// 由該端點轉址到儲存服務的簽章或靜態網址，簽章網址因此不會被存進資料庫而過期。
+type AvatarService struct {
+	userRepo   interfaces.UserRepositoryInterface
+	blobStore  interfaces.BlobStoreInterface
+	apiBaseURL string
+}
+
+func NewAvatarService(userRepo interfaces.UserRepositoryInterface, blobStore interfaces.BlobStoreInterface, apiBaseURL string) *AvatarService {
+	return &AvatarService{
+		userRepo:   userRepo,
+		blobStore:  blobStore,
+		apiBaseURL: strings.TrimRight(apiBaseURL, "/"),
+	}
+}
+
+// UploadAvatar 處理上傳的圖片並設為用戶頭像，成功後刪除前一個版本的檔案
+func (s *AvatarService) UploadAvatar(ctx context.Context, userID int, data []byte) (*models.User, error) {
+	if len(data) > models.AvatarMaxBytes {
+		return nil, apperrors.ErrAvatarTooLarge.WithArgs(models.AvatarMaxBytes >> 20)
+	}
+
+	img, err := imaging.Decode(data)
+	if err != nil {
+		return nil, err
+	}
+
+	version, err := utils.GenerateRandomID()
+	if err != nil {
+		return nil, err
+	}
+	for _, size := range models.AvatarSizes {
+		encoded, err := imaging.EncodeJPEG(imaging.Thumbnail(img, size))
+		if err != nil {
+			return nil, err
+		}
+		if err := s.blobStore.Put(avatarKey(userID, version, size), "image/jpeg", encoded); err != nil {
+			s.deleteVersion(ctx, userID, version)
+			return nil, fmt.Errorf("failed to store avatar: %w", err)
+		}
+	}
+
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		s.deleteVersion(ctx, userID, version)
+		if errors.Is(err, apperrors.ErrUserNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	previous := s.uploadedVersion(user)
+
+	avatarURL := s.avatarURL(userID, version)
+	user.AvatarURL = &avatarURL
+	if err := s.userRepo.UpdateProfile(ctx, user, user.UpdatedAt); err != nil {
+		s.deleteVersion(ctx, userID, version)
+		return nil, err
+	}
+
+	if previous != "" {
+		s.deleteVersion(ctx, userID, previous)
+	}
+	return user, nil
+}
+
+// DeleteAvatar 移除用戶頭像；若為上傳的頭像一併刪除檔案
+func (s *AvatarService) DeleteAvatar(ctx context.Context, userID int) (*models.User, error) {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrUserNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user.AvatarURL == nil {
+		return nil, apperrors.ErrAvatarNotFound
+	}
+	previous := s.uploadedVersion(user)
+
+	user.AvatarURL = nil
+	if err := s.userRepo.UpdateProfile(ctx, user, user.UpdatedAt); err != nil {
+		return nil, err
+	}
+
+	if previous != "" {
+		s.deleteVersion(ctx, userID, previous)
+	}
+	return user, nil
+}
+
+// PurgeUserData 刪除用戶上傳的頭像檔案，供刪除帳號時使用
+func (s *AvatarService) PurgeUserData(user *models.User) error {
+	version := s.uploadedVersion(user)
+	if version == "" {
+		return nil
+	}
+
+	for _, size := range models.AvatarSizes {
+		if err := s.blobStore.Delete(avatarKey(user.ID, version, size)); err != nil {
+			return fmt.Errorf("failed to delete avatar: %w", err)
+		}
+	}
+	return nil
+}
+
+// AvatarURL 回傳指定版本與尺寸的下載網址；size 為 0 時使用預設尺寸
+func (s *AvatarService) AvatarURL(userID int, version string, size int) (string, error) {
+	if size == 0 {
+		size = models.AvatarSizes[0]
+	}
+	if !isAvatarSize(size) {
+		sizes := make([]string, 0, len(models.AvatarSizes))
+		for _, allowed := range models.AvatarSizes {
+			sizes = append(sizes, strconv.Itoa(allowed))
+		}
+		return "", apperrors.ErrInvalidAvatarSize.WithArgs(strings.Join(sizes, "/"))
+	}
+	if !avatarVersionPattern.MatchString(version) {
+		return "", apperrors.ErrAvatarNotFound
+	}
+
+	return s.blobStore.URL(avatarKey(userID, version, size))
+}
+
+func (s *AvatarService) avatarURL(userID int, version string) string {
+	return fmt.Sprintf("%s/api/v1/users/%d/avatar?v=%s", s.apiBaseURL, userID, version)
+}
+
+// uploadedVersion 回傳用戶目前上傳頭像的版本；頭像為外部網址或未設定時回傳空字串
+func (s *AvatarService) uploadedVersion(user *models.User) string {
+	if user.AvatarURL == nil {
+		return ""
+	}
+	version, ok := strings.CutPrefix(*user.AvatarURL, s.avatarURL(user.ID, ""))
+	if !ok || !avatarVersionPattern.MatchString(version) {
+		return ""
+	}
+	return version
+}
+
+// deleteVersion 刪除某個版本的所有尺寸；失敗只記錄日誌，不影響已完成的更新
+func (s *AvatarService) deleteVersion(ctx context.Context, userID int, version string) {
+	for _, size := range models.AvatarSizes {
+		if err := s.blobStore.Delete(avatarKey(userID, version, size)); err != nil {
+			logging.FromContext(ctx).Warn("刪除頭像檔案失敗", "target_user_id", userID, "error", err)
+		}
+	}
+}
+
+func avatarKey(userID int, version string, size int) string {
+	return "avatars/" + strconv.Itoa(userID) + "/" + version + "/" + strconv.Itoa(size) + ".jpg"
+}
+
+func isAvatarSize(size int) bool {
+	for _, allowed := range models.AvatarSizes {
+		if size == allowed {
+			return true
+		}
+	}
+	return false
+}
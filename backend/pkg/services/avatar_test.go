@@ -0,0 +1,291 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"smart-learning-backend/pkg/models"
+	"strings"
+	"testing"
+)
+
+// MockBlobStore 實現了 BlobStoreInterface 介面用於測試
+type MockBlobStore struct {
+	blobs          map[string][]byte
+	shouldFailNext string
+}
+
+func NewMockBlobStore() *MockBlobStore {
+	return &MockBlobStore{
+		blobs: make(map[string][]byte),
+	}
+}
+
+func (m *MockBlobStore) Put(key, contentType string, data []byte) error {
+	if m.shouldFailNext == "Put" {
+		m.shouldFailNext = ""
+		return errors.New("storage unavailable")
+	}
+	m.blobs[key] = data
+	return nil
+}
+
+func (m *MockBlobStore) Delete(key string) error {
+	delete(m.blobs, key)
+	return nil
+}
+
+func (m *MockBlobStore) URL(key string) (string, error) {
+	return "https://blobs.example.com/" + key + "?signature=test", nil
+}
+
+func (m *MockBlobStore) SetShouldFailNext(method string) {
+	m.shouldFailNext = method
+}
+
+func (m *MockBlobStore) keys(prefix string) []string {
+	keys := []string{}
+	for key := range m.blobs {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+func testImage(t *testing.T, w, h int) []byte {
+	t.Helper()
+
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 128, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func newAvatarTestService(t *testing.T) (*mockDeps, *MockBlobStore, *AvatarService) {
+	t.Helper()
+
+	deps, _ := loginTestUser(t)
+	store := NewMockBlobStore()
+	return deps, store, NewAvatarService(deps.userRepo, store, "http://localhost:8080/")
+}
+
+func TestAvatarService_UploadAvatar(t *testing.T) {
+	deps, store, avatarService := newAvatarTestService(t)
+
+	user, err := avatarService.UploadAvatar(context.Background(), 1, testImage(t, 600, 400))
+	if err != nil {
+		t.Fatalf("UploadAvatar() unexpected error = %v", err)
+	}
+
+	if user.AvatarURL == nil || !strings.HasPrefix(*user.AvatarURL, "http://localhost:8080/api/v1/users/1/avatar?v=") {
+		t.Fatalf("UploadAvatar() avatar_url = %v", user.AvatarURL)
+	}
+	if stored := deps.userRepo.users[0].AvatarURL; stored == nil || *stored != *user.AvatarURL {
+		t.Errorf("stored avatar_url = %v, want %s", stored, *user.AvatarURL)
+	}
+
+	version := strings.TrimPrefix(*user.AvatarURL, "http://localhost:8080/api/v1/users/1/avatar?v=")
+	wantSizes := map[int]int{512: 400, 256: 256, 64: 64}
+	for size, wantSide := range wantSizes {
+		data, ok := store.blobs[avatarKey(1, version, size)]
+		if !ok {
+			t.Fatalf("size %d was not stored", size)
+		}
+		img, format, err := image.Decode(bytes.NewReader(data))
+		if err != nil || format != "jpeg" {
+			t.Fatalf("size %d: stored %s image, err = %v", size, format, err)
+		}
+		if img.Bounds().Dx() != wantSide || img.Bounds().Dy() != wantSide {
+			t.Errorf("size %d: dimensions = %v, want %dx%d", size, img.Bounds().Size(), wantSide, wantSide)
+		}
+	}
+
+	blobURL, err := avatarService.AvatarURL(1, version, 0)
+	if err != nil {
+		t.Fatalf("AvatarURL() unexpected error = %v", err)
+	}
+	if blobURL != "https://blobs.example.com/avatars/1/"+version+"/512.jpg?signature=test" {
+		t.Errorf("AvatarURL() = %s", blobURL)
+	}
+
+	// 重新上傳後刪除舊版本
+	if _, err := avatarService.UploadAvatar(context.Background(), 1, testImage(t, 100, 100)); err != nil {
+		t.Fatalf("UploadAvatar() second upload unexpected error = %v", err)
+	}
+	if keys := store.keys("avatars/1/" + version); len(keys) != 0 {
+		t.Errorf("previous version was not deleted: %v", keys)
+	}
+	if keys := store.keys("avatars/1/"); len(keys) != len(models.AvatarSizes) {
+		t.Errorf("stored blobs = %v, want one version", keys)
+	}
+}
+
+func TestAvatarService_UploadAvatar_Errors(t *testing.T) {
+	var jpegData bytes.Buffer
+	jpeg.Encode(&jpegData, image.NewRGBA(image.Rect(0, 0, 8, 8)), nil)
+
+	tests := []struct {
+		name      string
+		data      []byte
+		setup     func(deps *mockDeps, store *MockBlobStore)
+		errorMsg  string
+		wantBlobs int
+	}{
+		{
+			name:     "檔案過大",
+			data:     make([]byte, models.AvatarMaxBytes+1),
+			errorMsg: "avatar too large",
+		},
+		{
+			name:     "不支援的格式",
+			data:     []byte("<svg xmlns='http://www.w3.org/2000/svg'></svg>"),
+			errorMsg: "unsupported image type",
+		},
+		{
+			name:     "損壞的圖片",
+			data:     jpegData.Bytes()[:40],
+			errorMsg: "invalid image",
+		},
+		{
+			name: "儲存失敗",
+			data: jpegData.Bytes(),
+			setup: func(deps *mockDeps, store *MockBlobStore) {
+				store.SetShouldFailNext("Put")
+			},
+			errorMsg: "failed to store avatar",
+		},
+		{
+			name: "更新用戶資料失敗時刪除已上傳的檔案",
+			data: jpegData.Bytes(),
+			setup: func(deps *mockDeps, store *MockBlobStore) {
+				deps.userRepo.SetShouldFailNext("UpdateProfile")
+			},
+			errorMsg: "database error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deps, store, avatarService := newAvatarTestService(t)
+			if tt.setup != nil {
+				tt.setup(deps, store)
+			}
+
+			_, err := avatarService.UploadAvatar(context.Background(), 1, tt.data)
+			if err == nil || !contains(err.Error(), tt.errorMsg) {
+				t.Errorf("UploadAvatar() error = %v, expected error containing %q", err, tt.errorMsg)
+			}
+			if len(store.blobs) != 0 {
+				t.Errorf("UploadAvatar() left %d blobs after error", len(store.blobs))
+			}
+			if deps.userRepo.users[0].AvatarURL != nil {
+				t.Error("UploadAvatar() set avatar_url despite the error")
+			}
+		})
+	}
+}
+
+func TestAvatarService_DeleteAvatar(t *testing.T) {
+	t.Run("刪除上傳的頭像", func(t *testing.T) {
+		deps, store, avatarService := newAvatarTestService(t)
+		if _, err := avatarService.UploadAvatar(context.Background(), 1, testImage(t, 64, 64)); err != nil {
+			t.Fatal(err)
+		}
+
+		user, err := avatarService.DeleteAvatar(context.Background(), 1)
+		if err != nil {
+			t.Fatalf("DeleteAvatar() unexpected error = %v", err)
+		}
+		if user.AvatarURL != nil || deps.userRepo.users[0].AvatarURL != nil {
+			t.Error("DeleteAvatar() did not clear avatar_url")
+		}
+		if len(store.blobs) != 0 {
+			t.Errorf("DeleteAvatar() left %d blobs", len(store.blobs))
+		}
+	})
+
+	t.Run("外部頭像網址只清除欄位", func(t *testing.T) {
+		deps, store, avatarService := newAvatarTestService(t)
+		external := "https://cdn.example.com/a.png"
+		deps.userRepo.users[0].AvatarURL = &external
+		store.blobs["avatars/other"] = []byte("x")
+
+		if _, err := avatarService.DeleteAvatar(context.Background(), 1); err != nil {
+			t.Fatalf("DeleteAvatar() unexpected error = %v", err)
+		}
+		if deps.userRepo.users[0].AvatarURL != nil || len(store.blobs) != 1 {
+			t.Errorf("DeleteAvatar() avatar_url = %v, blobs = %d", deps.userRepo.users[0].AvatarURL, len(store.blobs))
+		}
+	})
+
+	t.Run("沒有頭像", func(t *testing.T) {
+		_, _, avatarService := newAvatarTestService(t)
+		if _, err := avatarService.DeleteAvatar(context.Background(), 1); err == nil || !contains(err.Error(), "avatar not found") {
+			t.Errorf("DeleteAvatar() error = %v, expected 'avatar not found'", err)
+		}
+	})
+}
+
+func TestAvatarService_AvatarURL(t *testing.T) {
+	_, _, avatarService := newAvatarTestService(t)
+	version := strings.Repeat("ab", 16)
+
+	tests := []struct {
+		name     string
+		version  string
+		size     int
+		want     string
+		errorMsg string
+	}{
+		{name: "指定尺寸", version: version, size: 64, want: "https://blobs.example.com/avatars/1/" + version + "/64.jpg?signature=test"},
+		{name: "不支援的尺寸", version: version, size: 100, errorMsg: "invalid avatar size"},
+		{name: "版本格式錯誤", version: "../../secret", size: 64, errorMsg: "avatar not found"},
+		{name: "缺少版本", version: "", errorMsg: "avatar not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := avatarService.AvatarURL(1, tt.version, tt.size)
+			if tt.errorMsg != "" {
+				if err == nil || !contains(err.Error(), tt.errorMsg) {
+					t.Errorf("AvatarURL() error = %v, expected error containing %q", err, tt.errorMsg)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("AvatarURL() = %s, %v, want %s", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestAvatarService_PurgeUserData(t *testing.T) {
+	deps, store, avatarService := newAvatarTestService(t)
+	if _, err := avatarService.UploadAvatar(context.Background(), 1, testImage(t, 64, 64)); err != nil {
+		t.Fatal(err)
+	}
+	store.blobs["avatars/2/other"] = []byte("x")
+
+	user, _ := deps.userRepo.GetUserByID(context.Background(), 1)
+	if err := avatarService.PurgeUserData(user); err != nil {
+		t.Fatalf("PurgeUserData() unexpected error = %v", err)
+	}
+	if keys := store.keys("avatars/1/"); len(keys) != 0 {
+		t.Errorf("PurgeUserData() left %v", keys)
+	}
+	if len(store.blobs) != 1 {
+		t.Errorf("PurgeUserData() deleted other users' files")
+	}
+}
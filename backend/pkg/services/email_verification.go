@@ -0,0 +1,200 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"smart-learning-backend/pkg/apperrors"
+	"smart-learning-backend/pkg/interfaces"
+	"smart-learning-backend/pkg/logging"
+	"smart-learning-backend/pkg/models"
+	"smart-learning-backend/pkg/utils"
+	"strings"
+	"time"
+)
+
+// EmailVerificationTokenTTL 為 email 驗證連結的有效期限
+const EmailVerificationTokenTTL = 24 * time.Hour
+
+type EmailVerificationService struct {
+	userRepo              interfaces.UserRepositoryInterface
+	verificationTokenRepo interfaces.EmailVerificationTokenRepositoryInterface
+	mailer                interfaces.MailerInterface
+	appBaseURL            string
+}
+
+func NewEmailVerificationService(
+	userRepo interfaces.UserRepositoryInterface,
+	verificationTokenRepo interfaces.EmailVerificationTokenRepositoryInterface,
+	mailer interfaces.MailerInterface,
+	appBaseURL string,
+) *EmailVerificationService {
+	return &EmailVerificationService{
+		userRepo:              userRepo,
+		verificationTokenRepo: verificationTokenRepo,
+		mailer:                mailer,
+		appBaseURL:            strings.TrimRight(appBaseURL, "/"),
+	}
+}
+
+// SendVerification 產生新的驗證 token 並寄送驗證連結，先前寄出的連結隨即失效
+func (s *EmailVerificationService) SendVerification(ctx context.Context, user *models.User) error {
+	if user.EmailVerifiedAt != nil {
+		return apperrors.ErrEmailAlreadyVerified
+	}
+
+	plainToken, err := s.createToken(ctx, user.ID, nil)
+	if err != nil {
+		return err
+	}
+
+	message := &models.EmailMessage{
+		To:      user.Email,
+		Subject: "Smart Learning 電子郵件驗證",
+		Body: fmt.Sprintf(
+			"%s 您好：\n\n感謝您註冊 Smart Learning！請在 %d 小時內點擊以下連結驗證您的電子郵件：\n\n%s\n\n若您沒有註冊帳號，請忽略這封郵件。\n",
+			user.Username,
+			int(EmailVerificationTokenTTL.Hours()),
+			s.verifyURL(plainToken),
+		),
+	}
+
+	if err := s.mailer.Send(message); err != nil {
+		return fmt.Errorf("failed to send verification email: %w", err)
+	}
+
+	return nil
+}
+
+// SendEmailChangeVerification 寄送驗證連結到新的 email，驗證成功後才會變更用戶的 email；
+// 同時通知原本的 email，通知寄送失敗僅記錄
+func (s *EmailVerificationService) SendEmailChangeVerification(ctx context.Context, user *models.User, newEmail string) error {
+	plainToken, err := s.createToken(ctx, user.ID, &newEmail)
+	if err != nil {
+		return err
+	}
+
+	message := &models.EmailMessage{
+		To:      newEmail,
+		Subject: "Smart Learning 電子郵件變更驗證",
+		Body: fmt.Sprintf(
+			"%s 您好：\n\n您申請將 Smart Learning 帳號的電子郵件變更為此地址。請在 %d 小時內點擊以下連結完成變更：\n\n%s\n\n若您沒有申請變更，請忽略這封郵件。\n",
+			user.Username,
+			int(EmailVerificationTokenTTL.Hours()),
+			s.verifyURL(plainToken),
+		),
+	}
+	if err := s.mailer.Send(message); err != nil {
+		return fmt.Errorf("failed to send verification email: %w", err)
+	}
+
+	notice := &models.EmailMessage{
+		To:      user.Email,
+		Subject: "Smart Learning 電子郵件變更通知",
+		Body: fmt.Sprintf(
+			"%s 您好：\n\n您的帳號已申請將電子郵件變更為 %s，新地址完成驗證後才會生效。\n\n若這不是您本人的操作，請立即變更密碼。\n",
+			user.Username,
+			newEmail,
+		),
+	}
+	if err := s.mailer.Send(notice); err != nil {
+		logging.FromContext(ctx).Error("電子郵件變更通知寄送失敗", "target_user_id", user.ID, "error", err)
+	}
+
+	return nil
+}
+
+// createToken 產生新的驗證 token，先前寄出的同用途連結隨即失效；
+// 註冊驗證與變更 email 的連結互不影響
+func (s *EmailVerificationService) createToken(ctx context.Context, userID int, newEmail *string) (string, error) {
+	if err := s.verificationTokenRepo.InvalidateUserEmailVerificationTokens(ctx, userID, newEmail != nil); err != nil {
+		return "", err
+	}
+
+	plainToken, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate verification token: %w", err)
+	}
+
+	token := &models.EmailVerificationToken{
+		UserID:    userID,
+		TokenHash: utils.HashToken(plainToken),
+		NewEmail:  newEmail,
+		ExpiresAt: time.Now().Add(EmailVerificationTokenTTL),
+	}
+	if err := s.verificationTokenRepo.CreateEmailVerificationToken(ctx, token); err != nil {
+		return "", err
+	}
+
+	return plainToken, nil
+}
+
+func (s *EmailVerificationService) verifyURL(plainToken string) string {
+	return s.appBaseURL + "/auth/verify-email?token=" + url.QueryEscape(plainToken)
+}
+
+// VerifyEmail 以驗證 token 將用戶的 email 標記為已驗證
+func (s *EmailVerificationService) VerifyEmail(ctx context.Context, token string) error {
+	stored, err := s.verificationTokenRepo.GetEmailVerificationTokenByHash(ctx, utils.HashToken(token))
+	if err != nil {
+		if errors.Is(err, apperrors.ErrEmailVerificationTokenNotFound) {
+			return apperrors.ErrInvalidVerificationToken
+		}
+		return err
+	}
+
+	if stored.UsedAt != nil || time.Now().After(stored.ExpiresAt) {
+		return apperrors.ErrInvalidVerificationToken
+	}
+
+	if err := s.verificationTokenRepo.MarkEmailVerificationTokenUsed(ctx, stored.ID); err != nil {
+		if errors.Is(err, apperrors.ErrEmailVerificationTokenAlreadyUsed) {
+			return apperrors.ErrInvalidVerificationToken
+		}
+		return err
+	}
+
+	if stored.NewEmail != nil {
+		return s.applyEmailChange(ctx, stored.UserID, *stored.NewEmail)
+	}
+
+	if err := s.userRepo.MarkEmailVerified(ctx, stored.UserID); err != nil {
+		return fmt.Errorf("failed to mark email verified: %w", err)
+	}
+
+	return nil
+}
+
+// applyEmailChange 將用戶的 email 改為已驗證的新地址；新地址在申請後被其他帳號註冊時回傳 apperrors.ErrEmailAlreadyInUse
+func (s *EmailVerificationService) applyEmailChange(ctx context.Context, userID int, newEmail string) error {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrUserNotFound) {
+			return err
+		}
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if err := s.userRepo.UpdateEmail(ctx, user.ID, newEmail, user.UpdatedAt); err != nil {
+		if errors.Is(err, apperrors.ErrEmailAlreadyInUse) || errors.Is(err, apperrors.ErrUserUpdateConflict) {
+			return err
+		}
+		return fmt.Errorf("failed to update email: %w", err)
+	}
+
+	return nil
+}
+
+// ResendVerification 重新寄送驗證連結給尚未驗證的用戶
+func (s *EmailVerificationService) ResendVerification(ctx context.Context, userID int) error {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrUserNotFound) {
+			return err
+		}
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	return s.SendVerification(ctx, user)
+}
@@ -0,0 +1,272 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"regexp"
+	"smart-learning-backend/pkg/apperrors"
+	"smart-learning-backend/pkg/models"
+	"testing"
+	"time"
+)
+
+// MockEmailVerificationTokenRepository 實現了 EmailVerificationTokenRepositoryInterface 介面用於測試
+type MockEmailVerificationTokenRepository struct {
+	tokens         []models.EmailVerificationToken
+	shouldFailNext string
+}
+
+func NewMockEmailVerificationTokenRepository() *MockEmailVerificationTokenRepository {
+	return &MockEmailVerificationTokenRepository{
+		tokens: make([]models.EmailVerificationToken, 0),
+	}
+}
+
+func (m *MockEmailVerificationTokenRepository) CreateEmailVerificationToken(ctx context.Context, token *models.EmailVerificationToken) error {
+	if m.shouldFailNext == "CreateEmailVerificationToken" {
+		m.shouldFailNext = ""
+		return errors.New("database error")
+	}
+
+	token.ID = len(m.tokens) + 1
+	token.CreatedAt = time.Now()
+	m.tokens = append(m.tokens, *token)
+	return nil
+}
+
+func (m *MockEmailVerificationTokenRepository) GetEmailVerificationTokenByHash(ctx context.Context, tokenHash string) (*models.EmailVerificationToken, error) {
+	for _, token := range m.tokens {
+		if token.TokenHash == tokenHash {
+			return &token, nil
+		}
+	}
+	return nil, apperrors.ErrEmailVerificationTokenNotFound
+}
+
+func (m *MockEmailVerificationTokenRepository) MarkEmailVerificationTokenUsed(ctx context.Context, id int) error {
+	for i := range m.tokens {
+		if m.tokens[i].ID == id {
+			if m.tokens[i].UsedAt != nil {
+				return apperrors.ErrEmailVerificationTokenAlreadyUsed
+			}
+			now := time.Now()
+			m.tokens[i].UsedAt = &now
+			return nil
+		}
+	}
+	return apperrors.ErrEmailVerificationTokenNotFound
+}
+
+func (m *MockEmailVerificationTokenRepository) InvalidateUserEmailVerificationTokens(ctx context.Context, userID int, emailChange bool) error {
+	now := time.Now()
+	for i := range m.tokens {
+		if m.tokens[i].UserID == userID && m.tokens[i].UsedAt == nil && (m.tokens[i].NewEmail != nil) == emailChange {
+			m.tokens[i].UsedAt = &now
+		}
+	}
+	return nil
+}
+
+func (m *MockEmailVerificationTokenRepository) SetShouldFailNext(method string) {
+	m.shouldFailNext = method
+}
+
+var verifyLinkPattern = regexp.MustCompile(`verify-email\?token=(\S+)`)
+
+// lastVerificationToken 從最後一封郵件中取出驗證 token
+func lastVerificationToken(t *testing.T, deps *mockDeps) string {
+	t.Helper()
+
+	if len(deps.mailer.sent) == 0 {
+		t.Fatal("no verification email was sent")
+	}
+
+	match := verifyLinkPattern.FindStringSubmatch(deps.mailer.sent[len(deps.mailer.sent)-1].Body)
+	if match == nil {
+		t.Fatalf("verification link not found in email body: %s", deps.mailer.sent[len(deps.mailer.sent)-1].Body)
+	}
+	token, err := url.QueryUnescape(match[1])
+	if err != nil {
+		t.Fatalf("QueryUnescape() error = %v", err)
+	}
+	return token
+}
+
+// registerTestUser 註冊一個尚未驗證 email 的測試用戶
+func registerTestUser(t *testing.T) (*mockDeps, *models.AuthResponse) {
+	t.Helper()
+
+	deps := newMockDeps(NewMockUserRepository())
+	result, err := deps.authService().Register(context.Background(), &models.RegisterRequest{
+		Email:           "test@example.com",
+		Username:        "testuser",
+		Password:        "password123",
+		ConfirmPassword: "password123",
+	}, models.ClientInfo{})
+	if err != nil {
+		t.Fatalf("Register() unexpected error = %v", err)
+	}
+	return deps, result
+}
+
+func TestEmailVerificationService_RegisterSendsVerification(t *testing.T) {
+	deps, result := registerTestUser(t)
+
+	if result.User.EmailVerifiedAt != nil {
+		t.Error("Register() returned a user with a verified email")
+	}
+	if len(deps.mailer.sent) != 1 || deps.mailer.sent[0].To != "test@example.com" {
+		t.Fatalf("Register() sent %+v, want one email to test@example.com", deps.mailer.sent)
+	}
+	if !contains(deps.mailer.sent[0].Body, "http://localhost:5173/auth/verify-email?token=") {
+		t.Errorf("email body has unexpected link: %s", deps.mailer.sent[0].Body)
+	}
+}
+
+func TestEmailVerificationService_VerifyEmail(t *testing.T) {
+	tests := []struct {
+		name       string
+		setupToken func(deps *mockDeps, token string) string
+		wantError  bool
+		errorMsg   string
+	}{
+		{
+			name:      "成功驗證",
+			wantError: false,
+		},
+		{
+			name: "無效的 token",
+			setupToken: func(deps *mockDeps, token string) string {
+				return "invalid-token"
+			},
+			wantError: true,
+			errorMsg:  "invalid or expired verification token",
+		},
+		{
+			name: "token 已過期",
+			setupToken: func(deps *mockDeps, token string) string {
+				deps.verifyTokenRepo.tokens[0].ExpiresAt = time.Now().Add(-time.Minute)
+				return token
+			},
+			wantError: true,
+			errorMsg:  "invalid or expired verification token",
+		},
+		{
+			name: "token 已使用",
+			setupToken: func(deps *mockDeps, token string) string {
+				deps.emailVerificationService().VerifyEmail(context.Background(), token)
+				return token
+			},
+			wantError: true,
+			errorMsg:  "invalid or expired verification token",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deps, _ := registerTestUser(t)
+			token := lastVerificationToken(t, deps)
+			if tt.setupToken != nil {
+				token = tt.setupToken(deps, token)
+			}
+
+			err := deps.emailVerificationService().VerifyEmail(context.Background(), token)
+
+			if tt.wantError {
+				if err == nil {
+					t.Error("VerifyEmail() expected error but got nil")
+					return
+				}
+				if !contains(err.Error(), tt.errorMsg) {
+					t.Errorf("VerifyEmail() error = %v, expected to contain %v", err.Error(), tt.errorMsg)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("VerifyEmail() unexpected error = %v", err)
+			}
+			user, _ := deps.userRepo.GetUserByID(context.Background(), 1)
+			if user.EmailVerifiedAt == nil {
+				t.Error("VerifyEmail() did not mark the email as verified")
+			}
+		})
+	}
+}
+
+func TestEmailVerificationService_ResendVerification(t *testing.T) {
+	t.Run("重新寄送後舊連結失效", func(t *testing.T) {
+		deps, _ := registerTestUser(t)
+		first := lastVerificationToken(t, deps)
+		service := deps.emailVerificationService()
+
+		if err := service.ResendVerification(context.Background(), 1); err != nil {
+			t.Fatalf("ResendVerification() unexpected error = %v", err)
+		}
+		if len(deps.mailer.sent) != 2 {
+			t.Fatalf("ResendVerification() sent %d emails in total, want 2", len(deps.mailer.sent))
+		}
+
+		if err := service.VerifyEmail(context.Background(), first); err == nil {
+			t.Error("VerifyEmail() accepted a superseded token")
+		}
+		if err := service.VerifyEmail(context.Background(), lastVerificationToken(t, deps)); err != nil {
+			t.Errorf("VerifyEmail() with new token error = %v", err)
+		}
+	})
+
+	t.Run("重新寄送不影響變更 email 的連結", func(t *testing.T) {
+		deps, _ := registerTestUser(t)
+		service := deps.emailVerificationService()
+		user, _ := deps.userRepo.GetUserByID(context.Background(), 1)
+
+		if err := service.SendEmailChangeVerification(context.Background(), user, "new@example.com"); err != nil {
+			t.Fatalf("SendEmailChangeVerification() unexpected error = %v", err)
+		}
+		// 最後一封為寄往原地址的通知，驗證連結在倒數第二封
+		deps.mailer.sent = deps.mailer.sent[:len(deps.mailer.sent)-1]
+		changeToken := lastVerificationToken(t, deps)
+
+		if err := service.ResendVerification(context.Background(), 1); err != nil {
+			t.Fatalf("ResendVerification() unexpected error = %v", err)
+		}
+		if err := service.VerifyEmail(context.Background(), changeToken); err != nil {
+			t.Errorf("VerifyEmail() with email change token error = %v", err)
+		}
+	})
+
+	t.Run("變更 email 不影響註冊驗證的連結", func(t *testing.T) {
+		deps, _ := registerTestUser(t)
+		registrationToken := lastVerificationToken(t, deps)
+		service := deps.emailVerificationService()
+		user, _ := deps.userRepo.GetUserByID(context.Background(), 1)
+
+		if err := service.SendEmailChangeVerification(context.Background(), user, "new@example.com"); err != nil {
+			t.Fatalf("SendEmailChangeVerification() unexpected error = %v", err)
+		}
+		if err := service.VerifyEmail(context.Background(), registrationToken); err != nil {
+			t.Errorf("VerifyEmail() with registration token error = %v", err)
+		}
+	})
+
+	t.Run("已驗證的用戶", func(t *testing.T) {
+		deps, _ := registerTestUser(t)
+		service := deps.emailVerificationService()
+		service.VerifyEmail(context.Background(), lastVerificationToken(t, deps))
+
+		err := service.ResendVerification(context.Background(), 1)
+		if err == nil || !contains(err.Error(), "email already verified") {
+			t.Errorf("ResendVerification() error = %v, want email already verified", err)
+		}
+	})
+
+	t.Run("用戶不存在", func(t *testing.T) {
+		deps := newMockDeps(NewMockUserRepository())
+
+		err := deps.emailVerificationService().ResendVerification(context.Background(), 99)
+		if err == nil || !contains(err.Error(), "user not found") {
+			t.Errorf("ResendVerification() error = %v, want user not found", err)
+		}
+	})
+}
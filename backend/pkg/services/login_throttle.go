@@ -0,0 +1,129 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"smart-learning-backend/pkg/apperrors"
+	"smart-learning-backend/pkg/logging"
+	"smart-learning-backend/pkg/models"
+	"strings"
+	"time"
+)
+
+const (
+	// AccountLockoutThreshold 同一帳號連續失敗達此次數後開始鎖定
+	AccountLockoutThreshold = 5
+	// IPLockoutThreshold 同一來源 IP 連續失敗達此次數後開始鎖定（允許 NAT 後的多位用戶）
+	IPLockoutThreshold = 20
+	// LockoutBaseDuration 第一次鎖定的時間，之後每多失敗一次加倍
+	LockoutBaseDuration = time.Minute
+	// LockoutMaxDuration 鎖定時間上限
+	LockoutMaxDuration = time.Hour
+	// FailureResetWindow 最後一次失敗超過此時間後重新計算失敗次數
+	FailureResetWindow = 24 * time.Hour
+)
+
+func accountAttemptKey(email string) string {
+	return "account:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+func ipAttemptKey(ip string) string {
+	return "ip:" + ip
+}
+
+// attemptKeys 回傳登入請求需要追蹤的鍵與各自的鎖定門檻
+func attemptKeys(email string, client models.ClientInfo) map[string]int {
+	keys := map[string]int{accountAttemptKey(email): AccountLockoutThreshold}
+	if client.IPAddress != "" {
+		keys[ipAttemptKey(client.IPAddress)] = IPLockoutThreshold
+	}
+	return keys
+}
+
+// lockoutDuration 依失敗次數計算指數退避的鎖定時間；未達門檻時回傳 0
+func lockoutDuration(failures, threshold int) time.Duration {
+	if failures < threshold {
+		return 0
+	}
+
+	duration := LockoutBaseDuration
+	for i := threshold; i < failures && duration < LockoutMaxDuration; i++ {
+		duration *= 2
+	}
+	if duration > LockoutMaxDuration {
+		duration = LockoutMaxDuration
+	}
+	return duration
+}
+
+// checkLoginLockout 檢查帳號或來源 IP 是否仍在鎖定期間，鎖定時回傳 *models.AccountLockedError
+func (s *AuthService) checkLoginLockout(ctx context.Context, keys map[string]int) error {
+	now := time.Now()
+	var retryAfter time.Duration
+
+	for key := range keys {
+		attempt, err := s.loginAttemptRepo.GetLoginAttempt(ctx, key)
+		if err != nil {
+			if errors.Is(err, apperrors.ErrLoginAttemptNotFound) {
+				continue
+			}
+			return fmt.Errorf("failed to check login attempts: %w", err)
+		}
+
+		if attempt.LockedUntil != nil && attempt.LockedUntil.After(now) {
+			if remaining := attempt.LockedUntil.Sub(now); remaining > retryAfter {
+				retryAfter = remaining
+			}
+		}
+	}
+
+	if retryAfter > 0 {
+		return &models.AccountLockedError{RetryAfter: retryAfter}
+	}
+	return nil
+}
+
+// recordLoginFailure 累加失敗次數，達到門檻時設定鎖定期限。
+// 紀錄失敗屬於盡力而為，儲存失敗只記錄日誌，不影響原本的錯誤回應。
+func (s *AuthService) recordLoginFailure(ctx context.Context, keys map[string]int) {
+	now := time.Now()
+
+	for key, threshold := range keys {
+		attempt, err := s.loginAttemptRepo.RecordFailedLogin(ctx, key, now.Add(-FailureResetWindow))
+		if err != nil {
+			logging.FromContext(ctx).Error("登入失敗紀錄寫入失敗", "attempt_key", key, "error", err)
+			continue
+		}
+
+		if duration := lockoutDuration(attempt.Failures, threshold); duration > 0 {
+			if err := s.loginAttemptRepo.LockLogin(ctx, key, now.Add(duration)); err != nil {
+				logging.FromContext(ctx).Error("登入鎖定寫入失敗", "attempt_key", key, "error", err)
+			}
+		}
+	}
+}
+
+// clearLoginFailures 登入成功後清除帳號的失敗紀錄；來源 IP 的紀錄保留，避免攻擊者以自己的帳號重置計數
+func (s *AuthService) clearLoginFailures(ctx context.Context, email string) {
+	if err := s.loginAttemptRepo.ClearLoginAttempts(ctx, accountAttemptKey(email)); err != nil {
+		logging.FromContext(ctx).Error("清除登入失敗紀錄失敗", "error", err)
+	}
+}
+
+// UnlockAccount 由管理員解除帳號的登入鎖定並清除失敗次數
+func (s *AuthService) UnlockAccount(ctx context.Context, userID int) error {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrUserNotFound) {
+			return err
+		}
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if err := s.loginAttemptRepo.ClearLoginAttempts(ctx, accountAttemptKey(user.Email)); err != nil {
+		return fmt.Errorf("failed to unlock account: %w", err)
+	}
+
+	return nil
+}
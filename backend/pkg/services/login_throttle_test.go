@@ -0,0 +1,136 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"smart-learning-backend/pkg/models"
+	"testing"
+	"time"
+)
+
+func TestLockoutDuration(t *testing.T) {
+	tests := []struct {
+		name     string
+		failures int
+		want     time.Duration
+	}{
+		{name: "未達門檻", failures: AccountLockoutThreshold - 1, want: 0},
+		{name: "剛達門檻", failures: AccountLockoutThreshold, want: LockoutBaseDuration},
+		{name: "指數退避", failures: AccountLockoutThreshold + 3, want: 8 * LockoutBaseDuration},
+		{name: "不超過上限", failures: AccountLockoutThreshold + 100, want: LockoutMaxDuration},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := lockoutDuration(tt.failures, AccountLockoutThreshold); got != tt.want {
+				t.Errorf("lockoutDuration(%d) = %v, want %v", tt.failures, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuthService_LoginLockout(t *testing.T) {
+	deps, _ := loginTestUser(t)
+	service := deps.authService()
+	client := models.ClientInfo{IPAddress: "203.0.113.1"}
+	wrong := &models.LoginRequest{Email: "test@example.com", Password: "wrongpassword"}
+
+	for i := 0; i < AccountLockoutThreshold; i++ {
+		if _, err := service.Login(context.Background(), wrong, client); err == nil || !contains(err.Error(), "invalid credentials") {
+			t.Fatalf("Login() attempt %d error = %v, want invalid credentials", i+1, err)
+		}
+	}
+
+	// 鎖定期間即使密碼正確也拒絕登入
+	_, err := service.Login(context.Background(), &models.LoginRequest{Email: "TEST@example.com", Password: "password123"}, client)
+	var lockedErr *models.AccountLockedError
+	if !errors.As(err, &lockedErr) {
+		t.Fatalf("Login() error = %v, want AccountLockedError", err)
+	}
+	if lockedErr.RetryAfter <= 0 || lockedErr.RetryAfter > LockoutBaseDuration {
+		t.Errorf("RetryAfter = %v, want within (0, %v]", lockedErr.RetryAfter, LockoutBaseDuration)
+	}
+
+	// 管理員解除鎖定後可再次登入，且成功登入會清除失敗次數
+	if err := service.UnlockAccount(context.Background(), 1); err != nil {
+		t.Fatalf("UnlockAccount() unexpected error = %v", err)
+	}
+	if _, err := service.Login(context.Background(), &models.LoginRequest{Email: "test@example.com", Password: "password123"}, client); err != nil {
+		t.Fatalf("Login() after unlock error = %v", err)
+	}
+	if _, err := deps.loginAttemptRepo.GetLoginAttempt(context.Background(), accountAttemptKey("test@example.com")); err == nil {
+		t.Error("Login() did not clear account failures")
+	}
+
+	if err := service.UnlockAccount(context.Background(), 99); err == nil || !contains(err.Error(), "user not found") {
+		t.Errorf("UnlockAccount() error = %v, want user not found", err)
+	}
+}
+
+// 查詢用戶時的資料庫錯誤不計入失敗次數，短暫中斷後正常用戶仍可登入
+func TestAuthService_LoginLockout_RepositoryError(t *testing.T) {
+	deps, _ := loginTestUser(t)
+	service := deps.authService()
+	client := models.ClientInfo{IPAddress: "203.0.113.1"}
+	req := &models.LoginRequest{Email: "test@example.com", Password: "password123"}
+
+	for i := 0; i < IPLockoutThreshold; i++ {
+		deps.userRepo.SetShouldFailNext("GetUserByEmail")
+		_, err := service.Login(context.Background(), req, client)
+		if err == nil || contains(err.Error(), "invalid credentials") {
+			t.Fatalf("Login() attempt %d error = %v, want the repository error", i+1, err)
+		}
+	}
+
+	if _, err := deps.loginAttemptRepo.GetLoginAttempt(context.Background(), accountAttemptKey(req.Email)); err == nil {
+		t.Error("Login() counted a repository error as a failed attempt")
+	}
+	if _, err := service.Login(context.Background(), req, client); err != nil {
+		t.Errorf("Login() after the outage error = %v", err)
+	}
+}
+
+func TestAuthService_LoginLockoutByIP(t *testing.T) {
+	deps, _ := loginTestUser(t)
+	service := deps.authService()
+	client := models.ClientInfo{IPAddress: "198.51.100.7"}
+
+	// 以不同帳號嘗試，單一帳號皆未達門檻，但來源 IP 達到門檻
+	for i := 0; i < IPLockoutThreshold; i++ {
+		req := &models.LoginRequest{Email: "user" + string(rune('a'+i)) + "@example.com", Password: "password123"}
+		service.Login(context.Background(), req, client)
+	}
+
+	_, err := service.Login(context.Background(), &models.LoginRequest{Email: "test@example.com", Password: "password123"}, client)
+	var lockedErr *models.AccountLockedError
+	if !errors.As(err, &lockedErr) {
+		t.Fatalf("Login() error = %v, want AccountLockedError", err)
+	}
+
+	// 其他來源 IP 不受影響
+	if _, err := service.Login(context.Background(), &models.LoginRequest{Email: "test@example.com", Password: "password123"}, models.ClientInfo{IPAddress: "203.0.113.9"}); err != nil {
+		t.Errorf("Login() from another IP error = %v", err)
+	}
+}
+
+func TestAuthService_VerifyMFALockout(t *testing.T) {
+	deps, _ := loginTestUser(t)
+	enableTestMFA(t, deps)
+	service := deps.authService()
+	client := models.ClientInfo{IPAddress: "203.0.113.1"}
+
+	result, err := service.Login(context.Background(), &models.LoginRequest{Email: "test@example.com", Password: "password123"}, client)
+	if err != nil || !result.MFARequired {
+		t.Fatalf("Login() = %+v, %v, want mfa required", result, err)
+	}
+
+	for i := 0; i < AccountLockoutThreshold; i++ {
+		service.VerifyMFA(context.Background(), &models.MFAVerifyRequest{MFAToken: result.MFAToken, Code: "000000"}, client)
+	}
+
+	_, err = service.VerifyMFA(context.Background(), &models.MFAVerifyRequest{MFAToken: result.MFAToken, Code: "000000"}, client)
+	var lockedErr *models.AccountLockedError
+	if !errors.As(err, &lockedErr) {
+		t.Errorf("VerifyMFA() error = %v, want AccountLockedError", err)
+	}
+}
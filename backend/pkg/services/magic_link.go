@@ -0,0 +1,146 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"smart-learning-backend/pkg/apperrors"
+	"smart-learning-backend/pkg/interfaces"
+	"smart-learning-backend/pkg/logging"
+	"smart-learning-backend/pkg/models"
+	"smart-learning-backend/pkg/utils"
+	"strings"
+	"time"
+)
+
+const (
+	// MagicLinkTokenTTL 為免密碼登入連結的有效期限
+	MagicLinkTokenTTL = 15 * time.Minute
+	// MagicLinkRequestLimit 為同一 email 在 MagicLinkRequestWindow 內可申請登入連結的次數
+	MagicLinkRequestLimit  = 3
+	MagicLinkRequestWindow = 15 * time.Minute
+)
+
+type MagicLinkService struct {
+	userRepo       interfaces.UserRepositoryInterface
+	tokenRepo      interfaces.MagicLinkTokenRepositoryInterface
+	rateLimitRepo  interfaces.RateLimitRepositoryInterface
+	mailer         interfaces.MailerInterface
+	loginCompleter interfaces.LoginCompleterInterface
+	appBaseURL     string
+}
+
+func NewMagicLinkService(
+	userRepo interfaces.UserRepositoryInterface,
+	tokenRepo interfaces.MagicLinkTokenRepositoryInterface,
+	rateLimitRepo interfaces.RateLimitRepositoryInterface,
+	mailer interfaces.MailerInterface,
+	loginCompleter interfaces.LoginCompleterInterface,
+	appBaseURL string,
+) *MagicLinkService {
+	return &MagicLinkService{
+		userRepo:       userRepo,
+		tokenRepo:      tokenRepo,
+		rateLimitRepo:  rateLimitRepo,
+		mailer:         mailer,
+		loginCompleter: loginCompleter,
+		appBaseURL:     strings.TrimRight(appBaseURL, "/"),
+	}
+}
+
+// RequestMagicLink 寄送免密碼登入連結。
+// 限流以 email 為單位且不論帳號是否存在皆計算；email 未註冊時同樣回傳成功，避免洩漏帳號是否存在。
+func (s *MagicLinkService) RequestMagicLink(ctx context.Context, req *models.MagicLinkRequest) error {
+	result, err := s.rateLimitRepo.Allow(ctx, "magic-link:"+strings.ToLower(req.Email), MagicLinkRequestLimit, MagicLinkRequestWindow)
+	if err != nil {
+		// 與限流中介軟體一致，儲存層故障時放行
+		logging.FromContext(ctx).Error("登入連結限流檢查失敗", "error", err)
+	} else if !result.Allowed {
+		return &models.RateLimitedError{RetryAfter: result.RetryAfter}
+	}
+
+	user, err := s.userRepo.GetUserByEmail(ctx, req.Email)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrUserNotFound) {
+			return nil
+		}
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	// 新連結寄出後，先前尚未使用的連結一律失效
+	if err := s.tokenRepo.InvalidateUserMagicLinkTokens(ctx, user.ID); err != nil {
+		return err
+	}
+
+	plainToken, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return fmt.Errorf("failed to generate magic link token: %w", err)
+	}
+
+	token := &models.MagicLinkToken{
+		UserID:    user.ID,
+		TokenHash: utils.HashToken(plainToken),
+		ExpiresAt: time.Now().Add(MagicLinkTokenTTL),
+	}
+	if err := s.tokenRepo.CreateMagicLinkToken(ctx, token); err != nil {
+		return err
+	}
+
+	message := &models.EmailMessage{
+		To:      user.Email,
+		Subject: "Smart Learning 登入連結",
+		Body: fmt.Sprintf(
+			"%s 您好：\n\n請在 %d 分鐘內點擊以下連結登入 Smart Learning，連結只能使用一次：\n\n%s\n\n若您沒有提出此請求，請忽略這封郵件。\n",
+			user.Username,
+			int(MagicLinkTokenTTL.Minutes()),
+			s.appBaseURL+"/auth/magic-link?token="+url.QueryEscape(plainToken),
+		),
+	}
+
+	// 寄送失敗時僅記錄，回應與帳號不存在時一致
+	if err := s.mailer.Send(message); err != nil {
+		logging.FromContext(ctx).Error("登入連結郵件寄送失敗", "target_user_id", user.ID, "error", err)
+	}
+
+	return nil
+}
+
+// LoginWithMagicLink 以登入連結中的 token 完成登入，回應與密碼登入相同（含兩步驟驗證）
+func (s *MagicLinkService) LoginWithMagicLink(ctx context.Context, req *models.MagicLinkLoginRequest, client models.ClientInfo) (*models.AuthResponse, error) {
+	stored, err := s.tokenRepo.GetMagicLinkTokenByHash(ctx, utils.HashToken(req.Token))
+	if err != nil {
+		if errors.Is(err, apperrors.ErrMagicLinkTokenNotFound) {
+			return nil, apperrors.ErrInvalidMagicLink
+		}
+		return nil, err
+	}
+
+	if stored.UsedAt != nil || time.Now().After(stored.ExpiresAt) {
+		return nil, apperrors.ErrInvalidMagicLink
+	}
+
+	// 先標記 token 已使用，確保併發請求中只有一個能成功
+	if err := s.tokenRepo.MarkMagicLinkTokenUsed(ctx, stored.ID); err != nil {
+		if errors.Is(err, apperrors.ErrMagicLinkTokenAlreadyUsed) {
+			return nil, apperrors.ErrInvalidMagicLink
+		}
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetUserByID(ctx, stored.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	// 能開啟寄到信箱的連結即證明擁有該信箱
+	if user.EmailVerifiedAt == nil {
+		if err := s.userRepo.MarkEmailVerified(ctx, user.ID); err != nil {
+			return nil, err
+		}
+		now := time.Now()
+		user.EmailVerifiedAt = &now
+	}
+
+	return s.loginCompleter.CompleteLogin(ctx, user, client)
+}
@@ -0,0 +1,254 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"regexp"
+	"smart-learning-backend/pkg/apperrors"
+	"smart-learning-backend/pkg/models"
+	"smart-learning-backend/pkg/utils"
+	"testing"
+	"time"
+)
+
+// MockMagicLinkTokenRepository 實現了 MagicLinkTokenRepositoryInterface 介面用於測試
+type MockMagicLinkTokenRepository struct {
+	tokens         []models.MagicLinkToken
+	shouldFailNext string
+}
+
+func NewMockMagicLinkTokenRepository() *MockMagicLinkTokenRepository {
+	return &MockMagicLinkTokenRepository{
+		tokens: make([]models.MagicLinkToken, 0),
+	}
+}
+
+func (m *MockMagicLinkTokenRepository) CreateMagicLinkToken(ctx context.Context, token *models.MagicLinkToken) error {
+	if m.shouldFailNext == "CreateMagicLinkToken" {
+		m.shouldFailNext = ""
+		return errors.New("database error")
+	}
+
+	token.ID = len(m.tokens) + 1
+	token.CreatedAt = time.Now()
+	m.tokens = append(m.tokens, *token)
+	return nil
+}
+
+func (m *MockMagicLinkTokenRepository) GetMagicLinkTokenByHash(ctx context.Context, tokenHash string) (*models.MagicLinkToken, error) {
+	for _, token := range m.tokens {
+		if token.TokenHash == tokenHash {
+			return &token, nil
+		}
+	}
+	return nil, apperrors.ErrMagicLinkTokenNotFound
+}
+
+func (m *MockMagicLinkTokenRepository) MarkMagicLinkTokenUsed(ctx context.Context, id int) error {
+	for i := range m.tokens {
+		if m.tokens[i].ID == id {
+			if m.tokens[i].UsedAt != nil {
+				return apperrors.ErrMagicLinkTokenAlreadyUsed
+			}
+			now := time.Now()
+			m.tokens[i].UsedAt = &now
+			return nil
+		}
+	}
+	return apperrors.ErrMagicLinkTokenNotFound
+}
+
+func (m *MockMagicLinkTokenRepository) InvalidateUserMagicLinkTokens(ctx context.Context, userID int) error {
+	now := time.Now()
+	for i := range m.tokens {
+		if m.tokens[i].UserID == userID && m.tokens[i].UsedAt == nil {
+			m.tokens[i].UsedAt = &now
+		}
+	}
+	return nil
+}
+
+func (m *MockMagicLinkTokenRepository) SetShouldFailNext(method string) {
+	m.shouldFailNext = method
+}
+
+var magicLinkPattern = regexp.MustCompile(`auth/magic-link\?token=(\S+)`)
+
+// requestMagicLinkToken 申請登入連結並從郵件中取出 token
+func requestMagicLinkToken(t *testing.T, deps *mockDeps) string {
+	t.Helper()
+
+	if err := deps.magicLinkService().RequestMagicLink(context.Background(), &models.MagicLinkRequest{Email: "test@example.com"}); err != nil {
+		t.Fatalf("RequestMagicLink() unexpected error = %v", err)
+	}
+	if len(deps.mailer.sent) == 0 {
+		t.Fatal("RequestMagicLink() did not send an email")
+	}
+
+	body := deps.mailer.sent[len(deps.mailer.sent)-1].Body
+	match := magicLinkPattern.FindStringSubmatch(body)
+	if match == nil {
+		t.Fatalf("magic link not found in email body: %s", body)
+	}
+	token, err := url.QueryUnescape(match[1])
+	if err != nil {
+		t.Fatalf("QueryUnescape() error = %v", err)
+	}
+	return token
+}
+
+func TestMagicLinkService_RequestMagicLink(t *testing.T) {
+	t.Run("已註冊的 email 寄出登入連結", func(t *testing.T) {
+		deps, _ := loginTestUser(t)
+		token := requestMagicLinkToken(t, deps)
+
+		if deps.mailer.sent[0].To != "test@example.com" {
+			t.Errorf("email sent to %v, want test@example.com", deps.mailer.sent[0].To)
+		}
+
+		// 資料庫只保存雜湊值
+		stored := deps.magicLinkRepo.tokens[0]
+		if stored.TokenHash == token || stored.TokenHash != utils.HashToken(token) {
+			t.Error("RequestMagicLink() did not store the hashed token")
+		}
+	})
+
+	t.Run("未註冊的 email 同樣回傳成功但不寄信", func(t *testing.T) {
+		deps := newMockDeps(NewMockUserRepository())
+
+		err := deps.magicLinkService().RequestMagicLink(context.Background(), &models.MagicLinkRequest{Email: "nobody@example.com"})
+		if err != nil {
+			t.Errorf("RequestMagicLink() unexpected error = %v", err)
+		}
+		if len(deps.mailer.sent) != 0 {
+			t.Errorf("RequestMagicLink() sent %d emails for unknown address", len(deps.mailer.sent))
+		}
+	})
+
+	t.Run("同一 email 超過申請次數", func(t *testing.T) {
+		deps, _ := loginTestUser(t)
+		service := deps.magicLinkService()
+
+		for i := 0; i < MagicLinkRequestLimit; i++ {
+			if err := service.RequestMagicLink(context.Background(), &models.MagicLinkRequest{Email: "test@example.com"}); err != nil {
+				t.Fatalf("RequestMagicLink() #%d unexpected error = %v", i+1, err)
+			}
+		}
+
+		// 大小寫不同仍視為同一地址
+		err := service.RequestMagicLink(context.Background(), &models.MagicLinkRequest{Email: "TEST@example.com"})
+		var limitedErr *models.RateLimitedError
+		if !errors.As(err, &limitedErr) || limitedErr.RetryAfter <= 0 {
+			t.Fatalf("RequestMagicLink() error = %v, want RateLimitedError", err)
+		}
+		if len(deps.mailer.sent) != MagicLinkRequestLimit {
+			t.Errorf("sent %d emails, want %d", len(deps.mailer.sent), MagicLinkRequestLimit)
+		}
+
+		// 其他地址不受影響
+		if err := service.RequestMagicLink(context.Background(), &models.MagicLinkRequest{Email: "other@example.com"}); err != nil {
+			t.Errorf("RequestMagicLink() for other address error = %v", err)
+		}
+	})
+}
+
+func TestMagicLinkService_LoginWithMagicLink(t *testing.T) {
+	tests := []struct {
+		name       string
+		setupToken func(deps *mockDeps, token string) string
+		wantError  bool
+	}{
+		{
+			name:      "成功登入",
+			wantError: false,
+		},
+		{
+			name: "無效的 token",
+			setupToken: func(deps *mockDeps, token string) string {
+				return "invalid-token"
+			},
+			wantError: true,
+		},
+		{
+			name: "token 已過期",
+			setupToken: func(deps *mockDeps, token string) string {
+				deps.magicLinkRepo.tokens[0].ExpiresAt = time.Now().Add(-time.Minute)
+				return token
+			},
+			wantError: true,
+		},
+		{
+			name: "重新申請後舊連結失效",
+			setupToken: func(deps *mockDeps, token string) string {
+				requestMagicLinkToken(t, deps)
+				return token
+			},
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deps, _ := loginTestUser(t)
+			token := requestMagicLinkToken(t, deps)
+			if tt.setupToken != nil {
+				token = tt.setupToken(deps, token)
+			}
+
+			result, err := deps.magicLinkService().LoginWithMagicLink(context.Background(), &models.MagicLinkLoginRequest{Token: token}, models.ClientInfo{})
+
+			if tt.wantError {
+				if err == nil || !contains(err.Error(), "invalid or expired magic link") {
+					t.Errorf("LoginWithMagicLink() error = %v, want invalid or expired magic link", err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("LoginWithMagicLink() unexpected error = %v", err)
+			}
+			if result.Token == "" || result.RefreshToken == "" || result.User.ID != 1 {
+				t.Errorf("LoginWithMagicLink() = %+v, want tokens for user 1", result)
+			}
+		})
+	}
+}
+
+func TestMagicLinkService_LoginWithMagicLink_SingleUse(t *testing.T) {
+	deps, _ := loginTestUser(t)
+	token := requestMagicLinkToken(t, deps)
+	service := deps.magicLinkService()
+	req := &models.MagicLinkLoginRequest{Token: token}
+
+	result, err := service.LoginWithMagicLink(context.Background(), req, models.ClientInfo{})
+	if err != nil {
+		t.Fatalf("LoginWithMagicLink() unexpected error = %v", err)
+	}
+
+	// 開啟信箱中的連結即完成 email 驗證
+	if result.User.EmailVerifiedAt == nil {
+		t.Error("LoginWithMagicLink() did not mark email verified")
+	}
+	if user, _ := deps.userRepo.GetUserByID(context.Background(), 1); user.EmailVerifiedAt == nil {
+		t.Error("LoginWithMagicLink() did not persist email verification")
+	}
+
+	if _, err := service.LoginWithMagicLink(context.Background(), req, models.ClientInfo{}); err == nil || !contains(err.Error(), "invalid or expired magic link") {
+		t.Errorf("second LoginWithMagicLink() error = %v, want invalid or expired magic link", err)
+	}
+}
+
+func TestMagicLinkService_LoginWithMagicLink_RequiresMFA(t *testing.T) {
+	deps, _ := loginTestUser(t)
+	enableTestMFA(t, deps)
+	token := requestMagicLinkToken(t, deps)
+
+	result, err := deps.magicLinkService().LoginWithMagicLink(context.Background(), &models.MagicLinkLoginRequest{Token: token}, models.ClientInfo{})
+	if err != nil {
+		t.Fatalf("LoginWithMagicLink() unexpected error = %v", err)
+	}
+	if !result.MFARequired || result.Token != "" {
+		t.Errorf("LoginWithMagicLink() = %+v, want mfa required without tokens", result)
+	}
+}
@@ -0,0 +1,151 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"smart-learning-backend/pkg/apperrors"
+	"smart-learning-backend/pkg/interfaces"
+	"smart-learning-backend/pkg/models"
+	"smart-learning-backend/pkg/utils"
+	"strings"
+	"time"
+)
+
+// RecoveryCodeCount 為啟用兩步驟驗證時產生的復原碼數量
+const RecoveryCodeCount = 10
+
+type MFAService struct {
+	mfaRepo  interfaces.MFARepositoryInterface
+	userRepo interfaces.UserRepositoryInterface
+	issuer   string
+}
+
+func NewMFAService(
+	mfaRepo interfaces.MFARepositoryInterface,
+	userRepo interfaces.UserRepositoryInterface,
+	issuer string,
+) *MFAService {
+	return &MFAService{
+		mfaRepo:  mfaRepo,
+		userRepo: userRepo,
+		issuer:   issuer,
+	}
+}
+
+// Setup 產生新的 TOTP 密鑰；需以 Confirm 驗證第一組驗證碼後才會啟用
+func (s *MFAService) Setup(ctx context.Context, userID int) (*models.MFASetupResponse, error) {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrUserNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.mfaRepo.SaveMFASecret(ctx, userID, secret); err != nil {
+		return nil, err
+	}
+
+	return &models.MFASetupResponse{
+		Secret:          secret,
+		ProvisioningURI: utils.TOTPProvisioningURI(s.issuer, user.Email, secret),
+	}, nil
+}
+
+// Confirm 以第一組驗證碼確認設定並啟用兩步驟驗證，回傳僅顯示一次的復原碼
+func (s *MFAService) Confirm(ctx context.Context, userID int, code string) ([]string, error) {
+	mfa, err := s.mfaRepo.GetUserMFA(ctx, userID)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrMFANotFound) {
+			return nil, apperrors.ErrMFASetupRequired
+		}
+		return nil, err
+	}
+	if mfa.EnabledAt != nil {
+		return nil, apperrors.ErrMFAAlreadyEnabled
+	}
+
+	step, ok := utils.ValidateTOTPCode(mfa.Secret, strings.TrimSpace(code), time.Now())
+	if !ok {
+		return nil, apperrors.ErrInvalidMFACode
+	}
+
+	codes, err := utils.GenerateRecoveryCodes(RecoveryCodeCount)
+	if err != nil {
+		return nil, err
+	}
+
+	hashes := make([]string, 0, len(codes))
+	for _, recoveryCode := range codes {
+		hashes = append(hashes, utils.HashToken(utils.NormalizeRecoveryCode(recoveryCode)))
+	}
+
+	if err := s.mfaRepo.EnableMFA(ctx, userID, step, hashes); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// Disable 驗證目前的驗證碼或復原碼後停用兩步驟驗證
+func (s *MFAService) Disable(ctx context.Context, userID int, code string) error {
+	if err := s.VerifyCode(ctx, userID, code); err != nil {
+		return err
+	}
+
+	return s.mfaRepo.DeleteUserMFA(ctx, userID)
+}
+
+// VerifyCode 驗證 TOTP 驗證碼或一次性復原碼；每組驗證碼與復原碼都只能使用一次
+func (s *MFAService) VerifyCode(ctx context.Context, userID int, code string) error {
+	mfa, err := s.mfaRepo.GetUserMFA(ctx, userID)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrMFANotFound) {
+			return apperrors.ErrMFANotEnabled
+		}
+		return err
+	}
+	if mfa.EnabledAt == nil {
+		return apperrors.ErrMFANotEnabled
+	}
+
+	code = strings.TrimSpace(code)
+	if step, ok := utils.ValidateTOTPCode(mfa.Secret, code, time.Now()); ok {
+		consumed, err := s.mfaRepo.ConsumeTOTPStep(ctx, userID, step)
+		if err != nil {
+			return err
+		}
+		if !consumed {
+			return apperrors.ErrInvalidMFACode
+		}
+		return nil
+	}
+
+	consumed, err := s.mfaRepo.ConsumeRecoveryCode(ctx, userID, utils.HashToken(utils.NormalizeRecoveryCode(code)))
+	if err != nil {
+		return err
+	}
+	if !consumed {
+		return apperrors.ErrInvalidMFACode
+	}
+
+	return nil
+}
+
+func (s *MFAService) IsEnabled(ctx context.Context, userID int) (bool, error) {
+	mfa, err := s.mfaRepo.GetUserMFA(ctx, userID)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrMFANotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return mfa.EnabledAt != nil, nil
+}
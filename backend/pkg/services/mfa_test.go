@@ -0,0 +1,269 @@
+package services
+
+import (
+	"context"
+	"smart-learning-backend/pkg/apperrors"
+	"smart-learning-backend/pkg/models"
+	"smart-learning-backend/pkg/utils"
+	"testing"
+	"time"
+)
+
+// MockMFARepository 實現了 MFARepositoryInterface 介面用於測試
+type MockMFARepository struct {
+	settings      map[int]*models.UserMFA
+	recoveryCodes map[int]map[string]bool // codeHash -> 是否已使用
+}
+
+func NewMockMFARepository() *MockMFARepository {
+	return &MockMFARepository{
+		settings:      make(map[int]*models.UserMFA),
+		recoveryCodes: make(map[int]map[string]bool),
+	}
+}
+
+func (m *MockMFARepository) GetUserMFA(ctx context.Context, userID int) (*models.UserMFA, error) {
+	mfa, ok := m.settings[userID]
+	if !ok {
+		return nil, apperrors.ErrMFANotFound
+	}
+	copied := *mfa
+	return &copied, nil
+}
+
+func (m *MockMFARepository) SaveMFASecret(ctx context.Context, userID int, secret string) error {
+	if mfa, ok := m.settings[userID]; ok && mfa.EnabledAt != nil {
+		return apperrors.ErrMFAAlreadyEnabled
+	}
+	m.settings[userID] = &models.UserMFA{UserID: userID, Secret: secret, CreatedAt: time.Now()}
+	return nil
+}
+
+func (m *MockMFARepository) EnableMFA(ctx context.Context, userID int, step int64, recoveryCodeHashes []string) error {
+	mfa, ok := m.settings[userID]
+	if !ok || mfa.EnabledAt != nil {
+		return apperrors.ErrMFAAlreadyEnabled
+	}
+	now := time.Now()
+	mfa.EnabledAt = &now
+	mfa.LastUsedStep = step
+
+	m.recoveryCodes[userID] = make(map[string]bool)
+	for _, codeHash := range recoveryCodeHashes {
+		m.recoveryCodes[userID][codeHash] = false
+	}
+	return nil
+}
+
+func (m *MockMFARepository) ConsumeTOTPStep(ctx context.Context, userID int, step int64) (bool, error) {
+	mfa, ok := m.settings[userID]
+	if !ok || mfa.EnabledAt == nil || mfa.LastUsedStep >= step {
+		return false, nil
+	}
+	mfa.LastUsedStep = step
+	return true, nil
+}
+
+func (m *MockMFARepository) ConsumeRecoveryCode(ctx context.Context, userID int, codeHash string) (bool, error) {
+	used, ok := m.recoveryCodes[userID][codeHash]
+	if !ok || used {
+		return false, nil
+	}
+	m.recoveryCodes[userID][codeHash] = true
+	return true, nil
+}
+
+func (m *MockMFARepository) DeleteUserMFA(ctx context.Context, userID int) error {
+	delete(m.settings, userID)
+	delete(m.recoveryCodes, userID)
+	return nil
+}
+
+// enableTestMFA 為測試用戶完成兩步驟驗證設定，回傳密鑰與復原碼
+func enableTestMFA(t *testing.T, deps *mockDeps) (string, []string) {
+	t.Helper()
+
+	service := deps.mfaService()
+	setup, err := service.Setup(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("Setup() unexpected error = %v", err)
+	}
+
+	code, _ := utils.GenerateTOTPCode(setup.Secret, time.Now())
+	recoveryCodes, err := service.Confirm(context.Background(), 1, code)
+	if err != nil {
+		t.Fatalf("Confirm() unexpected error = %v", err)
+	}
+	return setup.Secret, recoveryCodes
+}
+
+func TestMFAService_SetupAndConfirm(t *testing.T) {
+	deps, _ := loginTestUser(t)
+	service := deps.mfaService()
+
+	setup, err := service.Setup(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("Setup() unexpected error = %v", err)
+	}
+	if !contains(setup.ProvisioningURI, "otpauth://totp/") || !contains(setup.ProvisioningURI, "secret="+setup.Secret) {
+		t.Errorf("Setup() provisioning URI = %v", setup.ProvisioningURI)
+	}
+
+	// 確認前尚未啟用
+	if enabled, _ := service.IsEnabled(context.Background(), 1); enabled {
+		t.Error("IsEnabled() = true before confirmation")
+	}
+
+	if _, err := service.Confirm(context.Background(), 1, "abcdef"); err == nil || !contains(err.Error(), "invalid mfa code") {
+		t.Errorf("Confirm() with wrong code error = %v, want invalid mfa code", err)
+	}
+
+	recoveryCodes, err := service.Confirm(context.Background(), 1, mustCode(t, setup.Secret))
+	if err != nil {
+		t.Fatalf("Confirm() unexpected error = %v", err)
+	}
+	if len(recoveryCodes) != RecoveryCodeCount {
+		t.Errorf("Confirm() returned %d recovery codes, want %d", len(recoveryCodes), RecoveryCodeCount)
+	}
+	for hash := range deps.mfaRepo.recoveryCodes[1] {
+		for _, code := range recoveryCodes {
+			if hash == code {
+				t.Error("recovery codes stored in plain text")
+			}
+		}
+	}
+
+	if enabled, _ := service.IsEnabled(context.Background(), 1); !enabled {
+		t.Error("IsEnabled() = false after confirmation")
+	}
+
+	if _, err := service.Setup(context.Background(), 1); err == nil || !contains(err.Error(), "mfa already enabled") {
+		t.Errorf("Setup() after enabling error = %v, want mfa already enabled", err)
+	}
+}
+
+func mustCode(t *testing.T, secret string) string {
+	t.Helper()
+	code, err := utils.GenerateTOTPCode(secret, time.Now())
+	if err != nil {
+		t.Fatalf("GenerateTOTPCode() error = %v", err)
+	}
+	return code
+}
+
+func TestMFAService_VerifyCode(t *testing.T) {
+	deps, _ := loginTestUser(t)
+	secret, recoveryCodes := enableTestMFA(t, deps)
+	service := deps.mfaService()
+
+	// 確認時使用過的驗證碼不可再次使用
+	if err := service.VerifyCode(context.Background(), 1, mustCode(t, secret)); err == nil {
+		t.Error("VerifyCode() accepted a replayed code")
+	}
+
+	next, _ := utils.GenerateTOTPCode(secret, time.Now().Add(utils.TOTPPeriod*time.Second))
+	if err := service.VerifyCode(context.Background(), 1, next); err != nil {
+		t.Errorf("VerifyCode() with next code error = %v", err)
+	}
+
+	// 復原碼不分大小寫與連字號，且只能使用一次
+	if err := service.VerifyCode(context.Background(), 1, " "+recoveryCodes[0]+" "); err != nil {
+		t.Errorf("VerifyCode() with recovery code error = %v", err)
+	}
+	if err := service.VerifyCode(context.Background(), 1, recoveryCodes[0]); err == nil {
+		t.Error("VerifyCode() accepted a used recovery code")
+	}
+
+	if err := service.VerifyCode(context.Background(), 2, "123456"); err == nil || !contains(err.Error(), "mfa not enabled") {
+		t.Errorf("VerifyCode() for user without mfa error = %v", err)
+	}
+}
+
+func TestMFAService_Disable(t *testing.T) {
+	deps, _ := loginTestUser(t)
+	_, recoveryCodes := enableTestMFA(t, deps)
+	service := deps.mfaService()
+
+	if err := service.Disable(context.Background(), 1, "invalid"); err == nil {
+		t.Error("Disable() accepted an invalid code")
+	}
+	if err := service.Disable(context.Background(), 1, recoveryCodes[1]); err != nil {
+		t.Fatalf("Disable() unexpected error = %v", err)
+	}
+	if enabled, _ := service.IsEnabled(context.Background(), 1); enabled {
+		t.Error("IsEnabled() = true after Disable()")
+	}
+}
+
+func TestAuthService_LoginWithMFA(t *testing.T) {
+	deps, _ := loginTestUser(t)
+	secret, _ := enableTestMFA(t, deps)
+	authService := deps.authService()
+	client := models.ClientInfo{UserAgent: "Mozilla/5.0", IPAddress: "203.0.113.1"}
+
+	pending, err := authService.Login(context.Background(), &models.LoginRequest{
+		Email:    "test@example.com",
+		Password: "password123",
+	}, client)
+	if err != nil {
+		t.Fatalf("Login() unexpected error = %v", err)
+	}
+	if !pending.MFARequired || pending.MFAToken == "" || pending.Token != "" || pending.User != nil {
+		t.Fatalf("Login() = %+v, want only an mfa_pending token", pending)
+	}
+
+	// 待驗證 token 不可當作 access token
+	if _, err := deps.tokens.ValidateJWT(pending.MFAToken); err == nil {
+		t.Error("mfa_pending token accepted as an access token")
+	}
+
+	tests := []struct {
+		name      string
+		request   *models.MFAVerifyRequest
+		wantError string
+	}{
+		{
+			name:      "無效的待驗證 token",
+			request:   &models.MFAVerifyRequest{MFAToken: "invalid", Code: "123456"},
+			wantError: "invalid mfa token",
+		},
+		{
+			name:      "錯誤的驗證碼",
+			request:   &models.MFAVerifyRequest{MFAToken: pending.MFAToken, Code: "invalid"},
+			wantError: "invalid mfa code",
+		},
+		{
+			name: "驗證成功",
+			request: &models.MFAVerifyRequest{
+				MFAToken: pending.MFAToken,
+				Code: func() string {
+					code, _ := utils.GenerateTOTPCode(secret, time.Now().Add(utils.TOTPPeriod*time.Second))
+					return code
+				}(),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := authService.VerifyMFA(context.Background(), tt.request, client)
+
+			if tt.wantError != "" {
+				if err == nil || !contains(err.Error(), tt.wantError) {
+					t.Errorf("VerifyMFA() error = %v, want %v", err, tt.wantError)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("VerifyMFA() unexpected error = %v", err)
+			}
+			if result.Token == "" || result.RefreshToken == "" || result.User == nil {
+				t.Errorf("VerifyMFA() = %+v, want full auth response", result)
+			}
+			if _, err := deps.tokens.ValidateJWT(result.Token); err != nil {
+				t.Errorf("VerifyMFA() returned invalid access token: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,237 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"regexp"
+	"smart-learning-backend/pkg/apperrors"
+	"smart-learning-backend/pkg/interfaces"
+	"smart-learning-backend/pkg/logging"
+	"smart-learning-backend/pkg/models"
+	"smart-learning-backend/pkg/oidc"
+	"smart-learning-backend/pkg/utils"
+	"sort"
+	"strings"
+	"time"
+)
+
+// OIDCAuthStateTTL 為完成外部登入的期限（從取得授權網址到 callback）
+const OIDCAuthStateTTL = 10 * time.Minute
+
+var usernameInvalidChars = regexp.MustCompile(`[^a-zA-Z0-9]`)
+
+type OIDCService struct {
+	providers      map[string]interfaces.OIDCProviderInterface
+	stateRepo      interfaces.OIDCAuthStateRepositoryInterface
+	identityRepo   interfaces.UserIdentityRepositoryInterface
+	userRepo       interfaces.UserRepositoryInterface
+	loginCompleter interfaces.LoginCompleterInterface
+}
+
+func NewOIDCService(
+	providers []interfaces.OIDCProviderInterface,
+	stateRepo interfaces.OIDCAuthStateRepositoryInterface,
+	identityRepo interfaces.UserIdentityRepositoryInterface,
+	userRepo interfaces.UserRepositoryInterface,
+	loginCompleter interfaces.LoginCompleterInterface,
+) *OIDCService {
+	byName := make(map[string]interfaces.OIDCProviderInterface, len(providers))
+	for _, provider := range providers {
+		byName[provider.Name()] = provider
+	}
+
+	return &OIDCService{
+		providers:      byName,
+		stateRepo:      stateRepo,
+		identityRepo:   identityRepo,
+		userRepo:       userRepo,
+		loginCompleter: loginCompleter,
+	}
+}
+
+// Providers 回傳已設定的身分提供者名稱
+func (s *OIDCService) Providers() []string {
+	names := make([]string, 0, len(s.providers))
+	for name := range s.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// AuthorizationURL 產生 state、nonce 與 PKCE code_verifier 並保存於伺服器端，回傳身分提供者的授權網址
+func (s *OIDCService) AuthorizationURL(ctx context.Context, providerName string) (string, error) {
+	provider, ok := s.providers[providerName]
+	if !ok {
+		return "", apperrors.ErrUnknownProvider
+	}
+
+	state, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate state: %w", err)
+	}
+	nonce, err := utils.GenerateRandomID()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	verifier, err := oidc.GenerateCodeVerifier()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate code verifier: %w", err)
+	}
+
+	authState := &models.OIDCAuthState{
+		StateHash:    utils.HashToken(state),
+		Provider:     providerName,
+		CodeVerifier: verifier,
+		Nonce:        nonce,
+		ExpiresAt:    time.Now().Add(OIDCAuthStateTTL),
+	}
+	if err := s.stateRepo.CreateOIDCAuthState(ctx, authState); err != nil {
+		return "", err
+	}
+
+	authURL, err := provider.AuthCodeURL(ctx, state, nonce, oidc.CodeChallengeS256(verifier))
+	if err != nil {
+		return "", apperrors.ErrOIDCProviderUnavailable.Wrap(err)
+	}
+	return authURL, nil
+}
+
+// Callback 驗證 state 並以授權碼換取 ID token，找出（或建立）對應的用戶後完成登入
+func (s *OIDCService) Callback(ctx context.Context, providerName string, req *models.OIDCCallbackRequest, client models.ClientInfo) (*models.AuthResponse, error) {
+	provider, ok := s.providers[providerName]
+	if !ok {
+		return nil, apperrors.ErrUnknownProvider
+	}
+
+	authState, err := s.stateRepo.ConsumeOIDCAuthState(ctx, utils.HashToken(req.State))
+	if err != nil {
+		if errors.Is(err, apperrors.ErrOIDCAuthStateNotFound) {
+			return nil, apperrors.ErrInvalidOIDCState
+		}
+		return nil, err
+	}
+	if authState.Provider != providerName {
+		return nil, apperrors.ErrInvalidOIDCState
+	}
+
+	identity, err := provider.Exchange(ctx, req.Code, authState.CodeVerifier, authState.Nonce)
+	if err != nil {
+		return nil, apperrors.ErrOIDCExchangeFailed.Wrap(err)
+	}
+
+	user, err := s.resolveUser(ctx, identity)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.loginCompleter.CompleteLogin(ctx, user, client)
+}
+
+// resolveUser 依外部身分找出已連結的用戶；尚未連結時以已驗證的 email 連結既有用戶或建立新用戶
+func (s *OIDCService) resolveUser(ctx context.Context, identity *models.ExternalIdentity) (*models.User, error) {
+	linked, err := s.identityRepo.GetUserIdentity(ctx, identity.Provider, identity.Subject)
+	if err == nil {
+		if err := s.identityRepo.TouchUserIdentity(ctx, linked.ID); err != nil {
+			logging.FromContext(ctx).Warn("更新外部身分登入時間失敗", "identity_id", linked.ID, "error", err)
+		}
+		return s.userRepo.GetUserByID(ctx, linked.UserID)
+	}
+	if !errors.Is(err, apperrors.ErrIdentityNotFound) {
+		return nil, err
+	}
+
+	// 未經身分提供者驗證的 email 不可用於連結或建立帳號，避免冒用他人信箱
+	if identity.Email == "" || !identity.EmailVerified {
+		return nil, apperrors.ErrOIDCEmailNotVerified
+	}
+
+	user, err := s.userRepo.GetUserByEmail(ctx, identity.Email)
+	if err != nil {
+		if !errors.Is(err, apperrors.ErrUserNotFound) {
+			return nil, err
+		}
+		if user, err = s.provisionUser(ctx, identity); err != nil {
+			return nil, err
+		}
+	} else if user.EmailVerifiedAt == nil {
+		// 既有帳號尚未驗證信箱，可能是他人預先以此 email 註冊，拒絕自動連結
+		return nil, apperrors.ErrEmailAlreadyRegistered
+	}
+
+	email := identity.Email
+	link := &models.UserIdentity{
+		UserID:   user.ID,
+		Provider: identity.Provider,
+		Subject:  identity.Subject,
+		Email:    &email,
+	}
+	if err := s.identityRepo.CreateUserIdentity(ctx, link); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// provisionUser 為第一次登入的外部身分建立用戶；密碼為隨機值，需透過忘記密碼流程才能設定
+func (s *OIDCService) provisionUser(ctx context.Context, identity *models.ExternalIdentity) (*models.User, error) {
+	username, err := s.availableUsername(ctx, identity.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	randomPassword, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate password: %w", err)
+	}
+	hashedPassword, err := utils.HashPassword(randomPassword)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	user := &models.User{
+		Email:         identity.Email,
+		Username:      username,
+		PasswordHash:  hashedPassword,
+		LearningLevel: 1,
+		Role:          models.RoleStudent,
+	}
+	if err := s.userRepo.CreateUser(ctx, user); err != nil {
+		return nil, fmt.Errorf("failed to create user: %w", err)
+	}
+
+	if err := s.userRepo.MarkEmailVerified(ctx, user.ID); err != nil {
+		return nil, fmt.Errorf("failed to mark email verified: %w", err)
+	}
+	now := time.Now()
+	user.EmailVerifiedAt = &now
+
+	return user, nil
+}
+
+// availableUsername 由 email 產生符合註冊規則（2-20 個字母或數字）且未被使用的用戶名
+func (s *OIDCService) availableUsername(ctx context.Context, email string) (string, error) {
+	base := usernameInvalidChars.ReplaceAllString(strings.SplitN(email, "@", 2)[0], "")
+	if len(base) > 15 {
+		base = base[:15]
+	}
+	if len(base) < 2 {
+		base = "user"
+	}
+
+	candidate := base
+	for attempt := 0; attempt < 5; attempt++ {
+		exists, err := s.userRepo.CheckUserExists(ctx, "", candidate)
+		if err != nil {
+			return "", fmt.Errorf("failed to check user existence: %w", err)
+		}
+		if !exists {
+			return candidate, nil
+		}
+		candidate = fmt.Sprintf("%s%04d", base, rand.Intn(10000))
+	}
+
+	return "", fmt.Errorf("failed to generate username")
+}
@@ -0,0 +1,302 @@
+package services
+
+import (
+	"context"
+	"regexp"
+	"smart-learning-backend/pkg/apperrors"
+	"smart-learning-backend/pkg/interfaces"
+	"smart-learning-backend/pkg/models"
+	"smart-learning-backend/pkg/oidc"
+	"smart-learning-backend/pkg/oidc/oidctest"
+	"testing"
+	"time"
+)
+
+// MockOIDCAuthStateRepository 實現了 OIDCAuthStateRepositoryInterface 介面用於測試
+type MockOIDCAuthStateRepository struct {
+	states map[string]models.OIDCAuthState
+}
+
+func NewMockOIDCAuthStateRepository() *MockOIDCAuthStateRepository {
+	return &MockOIDCAuthStateRepository{states: make(map[string]models.OIDCAuthState)}
+}
+
+func (m *MockOIDCAuthStateRepository) CreateOIDCAuthState(ctx context.Context, state *models.OIDCAuthState) error {
+	m.states[state.StateHash] = *state
+	return nil
+}
+
+func (m *MockOIDCAuthStateRepository) ConsumeOIDCAuthState(ctx context.Context, stateHash string) (*models.OIDCAuthState, error) {
+	state, ok := m.states[stateHash]
+	delete(m.states, stateHash)
+	if !ok || time.Now().After(state.ExpiresAt) {
+		return nil, apperrors.ErrOIDCAuthStateNotFound
+	}
+	return &state, nil
+}
+
+func (m *MockOIDCAuthStateRepository) DeleteExpiredOIDCAuthStates(ctx context.Context, before time.Time) (int64, error) {
+	return 0, nil
+}
+
+// MockUserIdentityRepository 實現了 UserIdentityRepositoryInterface 介面用於測試
+type MockUserIdentityRepository struct {
+	identities []models.UserIdentity
+}
+
+func (m *MockUserIdentityRepository) CreateUserIdentity(ctx context.Context, identity *models.UserIdentity) error {
+	for _, existing := range m.identities {
+		if existing.Provider == identity.Provider && existing.Subject == identity.Subject {
+			return apperrors.ErrIdentityAlreadyLinked
+		}
+	}
+	identity.ID = len(m.identities) + 1
+	m.identities = append(m.identities, *identity)
+	return nil
+}
+
+func (m *MockUserIdentityRepository) GetUserIdentity(ctx context.Context, provider, subject string) (*models.UserIdentity, error) {
+	for _, identity := range m.identities {
+		if identity.Provider == provider && identity.Subject == subject {
+			return &identity, nil
+		}
+	}
+	return nil, apperrors.ErrIdentityNotFound
+}
+
+func (m *MockUserIdentityRepository) TouchUserIdentity(ctx context.Context, id int) error {
+	return nil
+}
+
+func (m *MockUserIdentityRepository) ListUserIdentities(ctx context.Context, userID int) ([]models.UserIdentity, error) {
+	identities := []models.UserIdentity{}
+	for _, identity := range m.identities {
+		if identity.UserID == userID {
+			identities = append(identities, identity)
+		}
+	}
+	return identities, nil
+}
+
+type oidcTestEnv struct {
+	deps         *mockDeps
+	fake         *oidctest.Provider
+	identityRepo *MockUserIdentityRepository
+	service      *OIDCService
+}
+
+func newOIDCTestEnv(t *testing.T) *oidcTestEnv {
+	t.Helper()
+
+	fake := oidctest.NewProvider("client-1", "secret-1")
+	t.Cleanup(fake.Close)
+
+	deps := newMockDeps(NewMockUserRepository())
+	identityRepo := &MockUserIdentityRepository{}
+	provider := oidc.NewProvider(fake.Config("school", "http://localhost:5173/auth/oidc/school/callback"))
+
+	return &oidcTestEnv{
+		deps:         deps,
+		fake:         fake,
+		identityRepo: identityRepo,
+		service: NewOIDCService(
+			[]interfaces.OIDCProviderInterface{provider},
+			NewMockOIDCAuthStateRepository(),
+			identityRepo,
+			deps.userRepo,
+			deps.authService(),
+		),
+	}
+}
+
+// login 走完一次外部登入流程
+func (e *oidcTestEnv) login(t *testing.T) (*models.AuthResponse, error) {
+	t.Helper()
+
+	authURL, err := e.service.AuthorizationURL(context.Background(), "school")
+	if err != nil {
+		t.Fatalf("AuthorizationURL() error = %v", err)
+	}
+	code, state, err := e.fake.Authorize(authURL)
+	if err != nil {
+		t.Fatalf("Authorize() error = %v", err)
+	}
+
+	return e.service.Callback(context.Background(), "school", &models.OIDCCallbackRequest{Code: code, State: state}, models.ClientInfo{})
+}
+
+func TestOIDCService_ProvisionAndLogin(t *testing.T) {
+	env := newOIDCTestEnv(t)
+
+	// 第一次登入自動建立用戶並標記信箱已驗證
+	result, err := env.login(t)
+	if err != nil {
+		t.Fatalf("Callback() unexpected error = %v", err)
+	}
+	if result.Token == "" || result.RefreshToken == "" {
+		t.Fatal("Callback() did not issue tokens")
+	}
+	if result.User.Email != "student@school.example" || result.User.Username != "student" {
+		t.Errorf("Callback() user = %s / %s", result.User.Email, result.User.Username)
+	}
+	if result.User.EmailVerifiedAt == nil || result.User.Role != models.RoleStudent {
+		t.Errorf("Callback() provisioned user = %+v", result.User)
+	}
+	if len(env.identityRepo.identities) != 1 {
+		t.Fatalf("identities = %d, want 1", len(env.identityRepo.identities))
+	}
+
+	// 再次登入使用已連結的身分，不重複建立用戶
+	env.fake.User.Email = "renamed@school.example"
+	result, err = env.login(t)
+	if err != nil {
+		t.Fatalf("Callback() second login error = %v", err)
+	}
+	if result.User.ID != 1 || len(env.deps.userRepo.users) != 1 {
+		t.Errorf("Callback() created a duplicate user")
+	}
+}
+
+func TestOIDCService_LinkExistingUser(t *testing.T) {
+	tests := []struct {
+		name          string
+		emailVerified bool
+		wantError     string
+	}{
+		{name: "連結已驗證信箱的既有用戶", emailVerified: true},
+		{name: "既有用戶尚未驗證信箱", emailVerified: false, wantError: "email already registered"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newOIDCTestEnv(t)
+			env.deps.userRepo.CreateUser(context.Background(), &models.User{
+				Email:        "student@school.example",
+				Username:     "student",
+				PasswordHash: "hash",
+			})
+			if tt.emailVerified {
+				env.deps.userRepo.MarkEmailVerified(context.Background(), 1)
+			}
+
+			result, err := env.login(t)
+
+			if tt.wantError != "" {
+				if err == nil || !contains(err.Error(), tt.wantError) {
+					t.Errorf("Callback() error = %v, want %s", err, tt.wantError)
+				}
+				if len(env.identityRepo.identities) != 0 {
+					t.Error("Callback() linked identity despite error")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Callback() unexpected error = %v", err)
+			}
+			if result.User.ID != 1 || len(env.deps.userRepo.users) != 1 {
+				t.Error("Callback() did not link the existing user")
+			}
+		})
+	}
+}
+
+func TestOIDCService_Errors(t *testing.T) {
+	t.Run("身分提供者未驗證信箱", func(t *testing.T) {
+		env := newOIDCTestEnv(t)
+		env.fake.User.EmailVerified = false
+
+		if _, err := env.login(t); err == nil || !contains(err.Error(), "email not verified by provider") {
+			t.Errorf("Callback() error = %v, want email not verified by provider", err)
+		}
+	})
+
+	t.Run("state 只能使用一次", func(t *testing.T) {
+		env := newOIDCTestEnv(t)
+		authURL, _ := env.service.AuthorizationURL(context.Background(), "school")
+		code, state, _ := env.fake.Authorize(authURL)
+		req := &models.OIDCCallbackRequest{Code: code, State: state}
+
+		if _, err := env.service.Callback(context.Background(), "school", req, models.ClientInfo{}); err != nil {
+			t.Fatalf("Callback() unexpected error = %v", err)
+		}
+		if _, err := env.service.Callback(context.Background(), "school", req, models.ClientInfo{}); err == nil || !contains(err.Error(), "invalid oidc state") {
+			t.Errorf("Callback() replay error = %v, want invalid oidc state", err)
+		}
+	})
+
+	t.Run("授權碼無效", func(t *testing.T) {
+		env := newOIDCTestEnv(t)
+		authURL, _ := env.service.AuthorizationURL(context.Background(), "school")
+		_, state, _ := env.fake.Authorize(authURL)
+
+		_, err := env.service.Callback(context.Background(), "school", &models.OIDCCallbackRequest{Code: "forged", State: state}, models.ClientInfo{})
+		if err == nil || !contains(err.Error(), "oidc exchange failed") {
+			t.Errorf("Callback() error = %v, want oidc exchange failed", err)
+		}
+	})
+
+	t.Run("不支援的身分提供者", func(t *testing.T) {
+		env := newOIDCTestEnv(t)
+		if _, err := env.service.AuthorizationURL(context.Background(), "unknown"); err == nil || !contains(err.Error(), "unknown provider") {
+			t.Errorf("AuthorizationURL() error = %v, want unknown provider", err)
+		}
+	})
+}
+
+func TestOIDCService_RequiresMFA(t *testing.T) {
+	env := newOIDCTestEnv(t)
+	if _, err := env.login(t); err != nil {
+		t.Fatalf("Callback() unexpected error = %v", err)
+	}
+	enableTestMFA(t, env.deps)
+
+	result, err := env.login(t)
+	if err != nil {
+		t.Fatalf("Callback() unexpected error = %v", err)
+	}
+	if !result.MFARequired || result.Token != "" {
+		t.Errorf("Callback() = %+v, want mfa required without tokens", result)
+	}
+	if _, err := env.deps.tokens.ValidateMFAPendingToken(result.MFAToken); err != nil {
+		t.Errorf("Callback() mfa token invalid: %v", err)
+	}
+}
+
+// 自動建立的用戶名須符合註冊與修改個人資料時的 alphanum 規則
+func TestOIDCService_AvailableUsername(t *testing.T) {
+	validUsername := regexp.MustCompile(`^[a-zA-Z0-9]{2,20}$`)
+
+	tests := []struct {
+		name  string
+		email string
+		taken bool
+		want  *regexp.Regexp
+	}{
+		{name: "移除符號", email: "first.last_1+tag@school.example", want: regexp.MustCompile(`^firstlast1tag$`)},
+		{name: "過短時使用預設名稱", email: "a@school.example", want: regexp.MustCompile(`^user$`)},
+		{name: "過長時截斷", email: "averyveryverylongname@school.example", want: regexp.MustCompile(`^averyveryverylo$`)},
+		{name: "已被使用時加上數字", email: "student@school.example", taken: true, want: regexp.MustCompile(`^student[0-9]{4}$`)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newOIDCTestEnv(t)
+			if tt.taken {
+				env.deps.userRepo.CreateUser(context.Background(), &models.User{
+					Email:        "other@school.example",
+					Username:     "student",
+					PasswordHash: "hash",
+				})
+			}
+
+			username, err := env.service.availableUsername(context.Background(), tt.email)
+			if err != nil {
+				t.Fatalf("availableUsername() unexpected error = %v", err)
+			}
+			if !tt.want.MatchString(username) || !validUsername.MatchString(username) {
+				t.Errorf("availableUsername() = %q, want match %s", username, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,206 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"smart-learning-backend/pkg/apperrors"
+	"smart-learning-backend/pkg/interfaces"
+	"smart-learning-backend/pkg/logging"
+	"smart-learning-backend/pkg/models"
+	"smart-learning-backend/pkg/utils"
+	"strings"
+	"time"
+)
+
+// PasswordResetTokenTTL 為密碼重設連結的有效期限
+const PasswordResetTokenTTL = time.Hour
+
+type PasswordResetService struct {
+	userRepo         interfaces.UserRepositoryInterface
+	resetTokenRepo   interfaces.PasswordResetTokenRepositoryInterface
+	refreshTokenRepo interfaces.RefreshTokenRepositoryInterface
+	sessionRepo      interfaces.SessionRepositoryInterface
+	mailer           interfaces.MailerInterface
+	passwordPolicy   interfaces.PasswordPolicyInterface
+	auditLog         interfaces.AuditRecorderInterface
+	appBaseURL       string
+}
+
+func NewPasswordResetService(
+	userRepo interfaces.UserRepositoryInterface,
+	resetTokenRepo interfaces.PasswordResetTokenRepositoryInterface,
+	refreshTokenRepo interfaces.RefreshTokenRepositoryInterface,
+	sessionRepo interfaces.SessionRepositoryInterface,
+	mailer interfaces.MailerInterface,
+	passwordPolicy interfaces.PasswordPolicyInterface,
+	auditLog interfaces.AuditRecorderInterface,
+	appBaseURL string,
+) *PasswordResetService {
+	return &PasswordResetService{
+		userRepo:         userRepo,
+		resetTokenRepo:   resetTokenRepo,
+		refreshTokenRepo: refreshTokenRepo,
+		sessionRepo:      sessionRepo,
+		mailer:           mailer,
+		passwordPolicy:   passwordPolicy,
+		auditLog:         auditLog,
+		appBaseURL:       strings.TrimRight(appBaseURL, "/"),
+	}
+}
+
+// ForgotPassword 寄送密碼重設連結。
+// 為避免洩漏帳號是否存在，email 未註冊時同樣回傳成功。
+func (s *PasswordResetService) ForgotPassword(ctx context.Context, req *models.ForgotPasswordRequest) error {
+	user, err := s.userRepo.GetUserByEmail(ctx, req.Email)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrUserNotFound) {
+			return nil
+		}
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	return s.sendResetLink(ctx, user, "Smart Learning 密碼重設", func(link string) string {
+		return fmt.Sprintf(
+			"%s 您好：\n\n我們收到了重設您密碼的請求，請在 %d 分鐘內點擊以下連結設定新密碼：\n\n%s\n\n若您沒有提出此請求，請忽略這封郵件。\n",
+			user.Username,
+			int(PasswordResetTokenTTL.Minutes()),
+			link,
+		)
+	})
+}
+
+// ForceReset 由管理員強制用戶重設密碼：原密碼立即失效、所有裝置登出，並寄送重設連結
+func (s *PasswordResetService) ForceReset(ctx context.Context, userID int) error {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrUserNotFound) {
+			return err
+		}
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	// 以無人知道的隨機密碼取代原密碼，用戶只能透過重設連結設定新密碼
+	randomPassword, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return fmt.Errorf("failed to generate password: %w", err)
+	}
+	hashedPassword, err := utils.HashPassword(randomPassword)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+	if err := s.userRepo.UpdatePasswordHash(ctx, user.ID, hashedPassword); err != nil {
+		return fmt.Errorf("failed to update password: %w", err)
+	}
+
+	if _, err := s.sessionRepo.RevokeOtherSessions(ctx, user.ID, ""); err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+	if err := s.refreshTokenRepo.RevokeUserRefreshTokens(ctx, user.ID, ""); err != nil {
+		return fmt.Errorf("failed to revoke refresh tokens: %w", err)
+	}
+
+	return s.sendResetLink(ctx, user, "Smart Learning 請重新設定密碼", func(link string) string {
+		return fmt.Sprintf(
+			"%s 您好：\n\n基於帳號安全，管理員已重設您的密碼，所有裝置皆已登出。請在 %d 分鐘內點擊以下連結設定新密碼：\n\n%s\n\n連結過期後，可於登入頁面使用「忘記密碼」重新取得。\n",
+			user.Username,
+			int(PasswordResetTokenTTL.Minutes()),
+			link,
+		)
+	})
+}
+
+// sendResetLink 建立新的重設 token 並寄出連結，郵件內文由 body 以連結產生；
+// 先前尚未使用的連結一律失效。寄送失敗時僅記錄日誌
+func (s *PasswordResetService) sendResetLink(ctx context.Context, user *models.User, subject string, body func(link string) string) error {
+	if err := s.resetTokenRepo.InvalidateUserPasswordResetTokens(ctx, user.ID); err != nil {
+		return err
+	}
+
+	plainToken, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return fmt.Errorf("failed to generate reset token: %w", err)
+	}
+
+	token := &models.PasswordResetToken{
+		UserID:    user.ID,
+		TokenHash: utils.HashToken(plainToken),
+		ExpiresAt: time.Now().Add(PasswordResetTokenTTL),
+	}
+	if err := s.resetTokenRepo.CreatePasswordResetToken(ctx, token); err != nil {
+		return err
+	}
+
+	message := &models.EmailMessage{
+		To:      user.Email,
+		Subject: subject,
+		Body:    body(s.appBaseURL + "/auth/reset-password?token=" + url.QueryEscape(plainToken)),
+	}
+	if err := s.mailer.Send(message); err != nil {
+		logging.FromContext(ctx).Error("密碼重設郵件寄送失敗", "target_user_id", user.ID, "error", err)
+	}
+
+	return nil
+}
+
+// ResetPassword 以重設 token 設定新密碼，並結束該用戶所有的裝置會話
+func (s *PasswordResetService) ResetPassword(ctx context.Context, req *models.ResetPasswordRequest, client models.ClientInfo) error {
+	if req.Password != req.ConfirmPassword {
+		return apperrors.ErrPasswordsDoNotMatch
+	}
+
+	stored, err := s.resetTokenRepo.GetPasswordResetTokenByHash(ctx, utils.HashToken(req.Token))
+	if err != nil {
+		if errors.Is(err, apperrors.ErrPasswordResetTokenNotFound) {
+			return apperrors.ErrInvalidResetToken
+		}
+		return err
+	}
+
+	if stored.UsedAt != nil || time.Now().After(stored.ExpiresAt) {
+		return apperrors.ErrInvalidResetToken
+	}
+
+	user, err := s.userRepo.GetUserByID(ctx, stored.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if err := s.passwordPolicy.Validate(req.Password, user.Username, user.Email); err != nil {
+		return err
+	}
+
+	hashedPassword, err := utils.HashPassword(req.Password)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	// 先標記 token 已使用，確保併發請求中只有一個能成功
+	if err := s.resetTokenRepo.MarkPasswordResetTokenUsed(ctx, stored.ID); err != nil {
+		if errors.Is(err, apperrors.ErrPasswordResetTokenAlreadyUsed) {
+			return apperrors.ErrInvalidResetToken
+		}
+		return err
+	}
+
+	if err := s.userRepo.UpdatePasswordHash(ctx, stored.UserID, hashedPassword); err != nil {
+		return fmt.Errorf("failed to update password: %w", err)
+	}
+
+	if err := s.resetTokenRepo.InvalidateUserPasswordResetTokens(ctx, stored.UserID); err != nil {
+		return err
+	}
+
+	// 撤銷所有裝置會話與 refresh token，既有的 access token 也會因會話失效而被拒絕
+	if _, err := s.sessionRepo.RevokeOtherSessions(ctx, stored.UserID, ""); err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+	if err := s.refreshTokenRepo.RevokeUserRefreshTokens(ctx, stored.UserID, ""); err != nil {
+		return fmt.Errorf("failed to revoke refresh tokens: %w", err)
+	}
+
+	s.auditLog.Record(ctx, models.NewAuditLogEntry(models.AuditEventPasswordChange, client, stored.UserID, stored.UserID, map[string]string{
+		"method": "reset_link",
+	}))
+	return nil
+}
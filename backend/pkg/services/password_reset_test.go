@@ -0,0 +1,310 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"regexp"
+	"smart-learning-backend/pkg/apperrors"
+	"smart-learning-backend/pkg/models"
+	"smart-learning-backend/pkg/utils"
+	"testing"
+	"time"
+)
+
+// MockPasswordResetTokenRepository 實現了 PasswordResetTokenRepositoryInterface 介面用於測試
+type MockPasswordResetTokenRepository struct {
+	tokens         []models.PasswordResetToken
+	shouldFailNext string
+}
+
+func NewMockPasswordResetTokenRepository() *MockPasswordResetTokenRepository {
+	return &MockPasswordResetTokenRepository{
+		tokens: make([]models.PasswordResetToken, 0),
+	}
+}
+
+func (m *MockPasswordResetTokenRepository) CreatePasswordResetToken(ctx context.Context, token *models.PasswordResetToken) error {
+	if m.shouldFailNext == "CreatePasswordResetToken" {
+		m.shouldFailNext = ""
+		return errors.New("database error")
+	}
+
+	token.ID = len(m.tokens) + 1
+	token.CreatedAt = time.Now()
+	m.tokens = append(m.tokens, *token)
+	return nil
+}
+
+func (m *MockPasswordResetTokenRepository) GetPasswordResetTokenByHash(ctx context.Context, tokenHash string) (*models.PasswordResetToken, error) {
+	for _, token := range m.tokens {
+		if token.TokenHash == tokenHash {
+			return &token, nil
+		}
+	}
+	return nil, apperrors.ErrPasswordResetTokenNotFound
+}
+
+func (m *MockPasswordResetTokenRepository) MarkPasswordResetTokenUsed(ctx context.Context, id int) error {
+	for i := range m.tokens {
+		if m.tokens[i].ID == id {
+			if m.tokens[i].UsedAt != nil {
+				return apperrors.ErrPasswordResetTokenAlreadyUsed
+			}
+			now := time.Now()
+			m.tokens[i].UsedAt = &now
+			return nil
+		}
+	}
+	return apperrors.ErrPasswordResetTokenNotFound
+}
+
+func (m *MockPasswordResetTokenRepository) InvalidateUserPasswordResetTokens(ctx context.Context, userID int) error {
+	now := time.Now()
+	for i := range m.tokens {
+		if m.tokens[i].UserID == userID && m.tokens[i].UsedAt == nil {
+			m.tokens[i].UsedAt = &now
+		}
+	}
+	return nil
+}
+
+func (m *MockPasswordResetTokenRepository) SetShouldFailNext(method string) {
+	m.shouldFailNext = method
+}
+
+// MockMailer 記錄所有寄出的郵件
+type MockMailer struct {
+	sent []models.EmailMessage
+}
+
+func (m *MockMailer) Send(message *models.EmailMessage) error {
+	m.sent = append(m.sent, *message)
+	return nil
+}
+
+var resetLinkPattern = regexp.MustCompile(`reset-password\?token=(\S+)`)
+
+// requestResetToken 發送忘記密碼請求並從郵件中取出重設 token
+func requestResetToken(t *testing.T, deps *mockDeps) string {
+	t.Helper()
+
+	if err := deps.passwordResetService().ForgotPassword(context.Background(), &models.ForgotPasswordRequest{Email: "test@example.com"}); err != nil {
+		t.Fatalf("ForgotPassword() unexpected error = %v", err)
+	}
+	if len(deps.mailer.sent) == 0 {
+		t.Fatal("ForgotPassword() did not send an email")
+	}
+
+	match := resetLinkPattern.FindStringSubmatch(deps.mailer.sent[len(deps.mailer.sent)-1].Body)
+	if match == nil {
+		t.Fatalf("reset link not found in email body: %s", deps.mailer.sent[len(deps.mailer.sent)-1].Body)
+	}
+	token, err := url.QueryUnescape(match[1])
+	if err != nil {
+		t.Fatalf("QueryUnescape() error = %v", err)
+	}
+	return token
+}
+
+func TestPasswordResetService_ForgotPassword(t *testing.T) {
+	t.Run("已註冊的 email 寄出重設連結", func(t *testing.T) {
+		deps, _ := loginTestUser(t)
+		token := requestResetToken(t, deps)
+
+		message := deps.mailer.sent[0]
+		if message.To != "test@example.com" {
+			t.Errorf("email sent to %v, want test@example.com", message.To)
+		}
+		if !contains(message.Body, "http://localhost:5173/auth/reset-password?token=") {
+			t.Errorf("email body has unexpected link: %s", message.Body)
+		}
+
+		// 資料庫只保存雜湊值
+		stored := deps.resetTokenRepo.tokens[0]
+		if stored.TokenHash == token || stored.TokenHash != utils.HashToken(token) {
+			t.Error("ForgotPassword() did not store the hashed token")
+		}
+	})
+
+	t.Run("未註冊的 email 同樣回傳成功但不寄信", func(t *testing.T) {
+		deps := newMockDeps(NewMockUserRepository())
+
+		err := deps.passwordResetService().ForgotPassword(context.Background(), &models.ForgotPasswordRequest{Email: "nobody@example.com"})
+		if err != nil {
+			t.Errorf("ForgotPassword() unexpected error = %v", err)
+		}
+		if len(deps.mailer.sent) != 0 {
+			t.Errorf("ForgotPassword() sent %d emails for unknown address", len(deps.mailer.sent))
+		}
+	})
+
+	t.Run("重新申請後舊連結失效", func(t *testing.T) {
+		deps, _ := loginTestUser(t)
+		first := requestResetToken(t, deps)
+		requestResetToken(t, deps)
+
+		err := deps.passwordResetService().ResetPassword(context.Background(), &models.ResetPasswordRequest{
+			Token: first, Password: "newpassword123", ConfirmPassword: "newpassword123",
+		}, models.ClientInfo{})
+		if err == nil || !contains(err.Error(), "invalid or expired reset token") {
+			t.Errorf("ResetPassword() with superseded token error = %v", err)
+		}
+	})
+}
+
+func TestPasswordResetService_ResetPassword(t *testing.T) {
+	tests := []struct {
+		name       string
+		setupToken func(deps *mockDeps, token string) string
+		password   string
+		confirm    string
+		wantError  bool
+		errorMsg   string
+	}{
+		{
+			name:      "成功重設密碼",
+			password:  "newpassword123",
+			confirm:   "newpassword123",
+			wantError: false,
+		},
+		{
+			name:      "密碼確認不匹配",
+			password:  "newpassword123",
+			confirm:   "different123",
+			wantError: true,
+			errorMsg:  "passwords do not match",
+		},
+		{
+			name:      "密碼包含 email 帳號",
+			password:  "test-example-1",
+			confirm:   "test-example-1",
+			wantError: true,
+			errorMsg:  "password does not meet policy: user_info",
+		},
+		{
+			name: "無效的 token",
+			setupToken: func(deps *mockDeps, token string) string {
+				return "invalid-token"
+			},
+			password:  "newpassword123",
+			confirm:   "newpassword123",
+			wantError: true,
+			errorMsg:  "invalid or expired reset token",
+		},
+		{
+			name: "token 已過期",
+			setupToken: func(deps *mockDeps, token string) string {
+				deps.resetTokenRepo.tokens[0].ExpiresAt = time.Now().Add(-time.Minute)
+				return token
+			},
+			password:  "newpassword123",
+			confirm:   "newpassword123",
+			wantError: true,
+			errorMsg:  "invalid or expired reset token",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deps, _ := loginTestUser(t)
+			token := requestResetToken(t, deps)
+			if tt.setupToken != nil {
+				token = tt.setupToken(deps, token)
+			}
+
+			err := deps.passwordResetService().ResetPassword(context.Background(), &models.ResetPasswordRequest{
+				Token:           token,
+				Password:        tt.password,
+				ConfirmPassword: tt.confirm,
+			}, models.ClientInfo{})
+
+			if tt.wantError {
+				if err == nil {
+					t.Error("ResetPassword() expected error but got nil")
+					return
+				}
+				if !contains(err.Error(), tt.errorMsg) {
+					t.Errorf("ResetPassword() error = %v, expected to contain %v", err.Error(), tt.errorMsg)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("ResetPassword() unexpected error = %v", err)
+			}
+
+			user, _ := deps.userRepo.GetUserByID(context.Background(), 1)
+			if utils.VerifyPassword(user.PasswordHash, tt.password) != nil {
+				t.Error("ResetPassword() did not update the password")
+			}
+		})
+	}
+}
+
+func TestPasswordResetService_ResetPassword_SingleUseAndRevokesSessions(t *testing.T) {
+	deps, login := loginTestUser(t)
+	token := requestResetToken(t, deps)
+	service := deps.passwordResetService()
+	req := &models.ResetPasswordRequest{Token: token, Password: "newpassword123", ConfirmPassword: "newpassword123"}
+
+	if err := service.ResetPassword(context.Background(), req, models.ClientInfo{IPAddress: "203.0.113.1"}); err != nil {
+		t.Fatalf("ResetPassword() unexpected error = %v", err)
+	}
+
+	entries := deps.auditLogRepo.entries
+	if last := entries[len(entries)-1]; last.EventType != models.AuditEventPasswordChange || last.Metadata["method"] != "reset_link" || last.IPAddress != "203.0.113.1" {
+		t.Errorf("ResetPassword() audit entry = %+v, want password_change via reset_link", last)
+	}
+
+	// 所有裝置會話與 refresh token 皆已失效
+	if sessions, _ := deps.sessionRepo.ListActiveSessions(context.Background(), 1); len(sessions) != 0 {
+		t.Errorf("ResetPassword() left %d active sessions", len(sessions))
+	}
+	if _, err := deps.authService().Refresh(context.Background(), &models.RefreshRequest{RefreshToken: login.RefreshToken}, models.ClientInfo{}); err == nil {
+		t.Error("Refresh() succeeded with a refresh token issued before the reset")
+	}
+
+	// 同一個 token 不可重複使用
+	if err := service.ResetPassword(context.Background(), req, models.ClientInfo{}); err == nil || !contains(err.Error(), "invalid or expired reset token") {
+		t.Errorf("second ResetPassword() error = %v, want invalid or expired reset token", err)
+	}
+}
+
+func TestPasswordResetService_ForceReset(t *testing.T) {
+	deps, login := loginTestUser(t)
+	service := deps.passwordResetService()
+
+	if err := service.ForceReset(context.Background(), 1); err != nil {
+		t.Fatalf("ForceReset() unexpected error = %v", err)
+	}
+
+	// 原密碼失效，所有裝置登出
+	if utils.VerifyPassword(deps.userRepo.users[0].PasswordHash, "password123") == nil {
+		t.Error("ForceReset() kept the previous password")
+	}
+	if sessions, _ := deps.sessionRepo.ListActiveSessions(context.Background(), 1); len(sessions) != 0 {
+		t.Errorf("ForceReset() left %d active sessions", len(sessions))
+	}
+	if _, err := deps.authService().Refresh(context.Background(), &models.RefreshRequest{RefreshToken: login.RefreshToken}, models.ClientInfo{}); err == nil {
+		t.Error("Refresh() succeeded with a refresh token issued before the forced reset")
+	}
+
+	// 寄出的連結可用來設定新密碼
+	if len(deps.mailer.sent) != 1 || !contains(deps.mailer.sent[0].Body, "管理員已重設您的密碼") {
+		t.Fatalf("ForceReset() sent %+v", deps.mailer.sent)
+	}
+	match := resetLinkPattern.FindStringSubmatch(deps.mailer.sent[0].Body)
+	if match == nil {
+		t.Fatalf("reset link not found in email body: %s", deps.mailer.sent[0].Body)
+	}
+	token, _ := url.QueryUnescape(match[1])
+	err := service.ResetPassword(context.Background(), &models.ResetPasswordRequest{Token: token, Password: "newpassword123", ConfirmPassword: "newpassword123"}, models.ClientInfo{})
+	if err != nil {
+		t.Errorf("ResetPassword() with forced reset link error = %v", err)
+	}
+
+	if err := service.ForceReset(context.Background(), 99); err == nil || !contains(err.Error(), "user not found") {
+		t.Errorf("ForceReset() unknown user error = %v, want user not found", err)
+	}
+}
@@ -0,0 +1,169 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"smart-learning-backend/pkg/apperrors"
+	"smart-learning-backend/pkg/i18n"
+	"smart-learning-backend/pkg/interfaces"
+	"smart-learning-backend/pkg/models"
+	"smart-learning-backend/pkg/utils"
+	"strings"
+)
+
+// usernamePattern 為用戶名允許的字元：字母、數字與底線
+var usernamePattern = regexp.MustCompile(`^[a-zA-Z0-9_]+$`)
+
+// ProfileService 處理用戶管理自身資料：個人資料、密碼與 email 變更。
+// 所有寫入皆以讀取時的 updated_at 做樂觀鎖，資料已被其他請求修改時回傳 apperrors.ErrUserUpdateConflict。
+type ProfileService struct {
+	userRepo         interfaces.UserRepositoryInterface
+	sessionRepo      interfaces.SessionRepositoryInterface
+	refreshTokenRepo interfaces.RefreshTokenRepositoryInterface
+	emailVerifier    interfaces.EmailVerificationServiceInterface
+	passwordPolicy   interfaces.PasswordPolicyInterface
+}
+
+func NewProfileService(
+	userRepo interfaces.UserRepositoryInterface,
+	sessionRepo interfaces.SessionRepositoryInterface,
+	refreshTokenRepo interfaces.RefreshTokenRepositoryInterface,
+	emailVerifier interfaces.EmailVerificationServiceInterface,
+	passwordPolicy interfaces.PasswordPolicyInterface,
+) *ProfileService {
+	return &ProfileService{
+		userRepo:         userRepo,
+		sessionRepo:      sessionRepo,
+		refreshTokenRepo: refreshTokenRepo,
+		emailVerifier:    emailVerifier,
+		passwordPolicy:   passwordPolicy,
+	}
+}
+
+// UpdateProfile 更新用戶名、學習等級、頭像網址與偏好語系；req.UpdatedAt 須與目前資料一致。
+// 偏好語系記錄在 access token 中，於下一次換發 token 後生效
+func (s *ProfileService) UpdateProfile(ctx context.Context, userID int, req *models.UpdateProfileRequest) (*models.User, error) {
+	if req.Username == nil && req.LearningLevel == nil && req.AvatarURL == nil && req.Locale == nil {
+		return nil, apperrors.ErrNoProfileChanges
+	}
+
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrUserNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if !user.UpdatedAt.Equal(req.UpdatedAt) {
+		return nil, apperrors.ErrUserUpdateConflict
+	}
+
+	if req.Username != nil {
+		if !usernamePattern.MatchString(*req.Username) {
+			return nil, apperrors.ErrInvalidUsername
+		}
+		user.Username = *req.Username
+	}
+	if req.LearningLevel != nil {
+		user.LearningLevel = *req.LearningLevel
+	}
+	if req.AvatarURL != nil {
+		if *req.AvatarURL == "" {
+			user.AvatarURL = nil
+		} else {
+			// 僅接受 http(s)，避免 javascript: 等網址被前端當成圖片來源
+			parsed, err := url.Parse(*req.AvatarURL)
+			if err != nil || (parsed.Scheme != "https" && parsed.Scheme != "http") || parsed.Host == "" {
+				return nil, apperrors.ErrInvalidAvatarURL
+			}
+			avatarURL := *req.AvatarURL
+			user.AvatarURL = &avatarURL
+		}
+	}
+	if req.Locale != nil {
+		if *req.Locale == "" {
+			user.Locale = nil
+		} else {
+			if !i18n.IsSupported(*req.Locale) {
+				return nil, apperrors.ErrUnsupportedLocale.WithArgs(strings.Join(i18n.SupportedLocales, "/"))
+			}
+			locale := *req.Locale
+			user.Locale = &locale
+		}
+	}
+
+	if err := s.userRepo.UpdateProfile(ctx, user, req.UpdatedAt); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// ChangePassword 驗證目前密碼後設定新密碼，並結束目前會話以外的所有裝置會話
+func (s *ProfileService) ChangePassword(ctx context.Context, userID int, currentSessionID string, req *models.ChangePasswordRequest) error {
+	if req.NewPassword != req.ConfirmPassword {
+		return apperrors.ErrPasswordsDoNotMatch
+	}
+
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrUserNotFound) {
+			return err
+		}
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if err := utils.VerifyPassword(user.PasswordHash, req.CurrentPassword); err != nil {
+		return apperrors.ErrInvalidCurrentPassword
+	}
+
+	if err := s.passwordPolicy.Validate(req.NewPassword, user.Username, user.Email); err != nil {
+		return err
+	}
+
+	hashedPassword, err := utils.HashPassword(req.NewPassword)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+	if err := s.userRepo.UpdatePassword(ctx, user.ID, hashedPassword, user.UpdatedAt); err != nil {
+		return err
+	}
+
+	// 其他裝置可能是以舊密碼登入的攻擊者，一併登出
+	if _, err := s.sessionRepo.RevokeOtherSessions(ctx, user.ID, currentSessionID); err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+	if err := s.refreshTokenRepo.RevokeUserRefreshTokens(ctx, user.ID, currentSessionID); err != nil {
+		return fmt.Errorf("failed to revoke refresh tokens: %w", err)
+	}
+
+	return nil
+}
+
+// RequestEmailChange 驗證目前密碼後寄送驗證連結到新的 email，完成驗證後才會變更
+func (s *ProfileService) RequestEmailChange(ctx context.Context, userID int, req *models.ChangeEmailRequest) error {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrUserNotFound) {
+			return err
+		}
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if err := utils.VerifyPassword(user.PasswordHash, req.CurrentPassword); err != nil {
+		return apperrors.ErrInvalidCurrentPassword
+	}
+
+	newEmail := strings.TrimSpace(req.NewEmail)
+	if strings.EqualFold(newEmail, user.Email) {
+		return apperrors.ErrEmailUnchanged
+	}
+	if _, err := s.userRepo.GetUserByEmail(ctx, newEmail); err == nil {
+		return apperrors.ErrEmailAlreadyInUse
+	} else if !errors.Is(err, apperrors.ErrUserNotFound) {
+		return err
+	}
+
+	return s.emailVerifier.SendEmailChangeVerification(ctx, user, newEmail)
+}
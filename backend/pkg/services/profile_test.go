@@ -0,0 +1,352 @@
+package services
+
+import (
+	"context"
+	"smart-learning-backend/pkg/models"
+	"smart-learning-backend/pkg/utils"
+	"testing"
+	"time"
+)
+
+func stringPtr(s string) *string { return &s }
+
+func intPtr(n int) *int { return &n }
+
+func TestProfileService_UpdateProfile(t *testing.T) {
+	tests := []struct {
+		name      string
+		setup     func(deps *mockDeps)
+		buildReq  func(updatedAt time.Time) *models.UpdateProfileRequest
+		wantError bool
+		errorMsg  string
+		check     func(t *testing.T, user *models.User)
+	}{
+		{
+			name: "成功更新",
+			buildReq: func(updatedAt time.Time) *models.UpdateProfileRequest {
+				return &models.UpdateProfileRequest{
+					Username:      stringPtr("new_name"),
+					LearningLevel: intPtr(5),
+					AvatarURL:     stringPtr("https://cdn.example.com/a.png"),
+					UpdatedAt:     updatedAt,
+				}
+			},
+			check: func(t *testing.T, user *models.User) {
+				if user.Username != "new_name" || user.LearningLevel != 5 || user.AvatarURL == nil || *user.AvatarURL != "https://cdn.example.com/a.png" {
+					t.Errorf("UpdateProfile() = %+v, fields not updated", user)
+				}
+			},
+		},
+		{
+			name: "空字串清除頭像",
+			setup: func(deps *mockDeps) {
+				deps.userRepo.users[0].AvatarURL = stringPtr("https://cdn.example.com/a.png")
+			},
+			buildReq: func(updatedAt time.Time) *models.UpdateProfileRequest {
+				return &models.UpdateProfileRequest{AvatarURL: stringPtr(""), UpdatedAt: updatedAt}
+			},
+			check: func(t *testing.T, user *models.User) {
+				if user.AvatarURL != nil {
+					t.Errorf("UpdateProfile() avatar = %v, want nil", *user.AvatarURL)
+				}
+			},
+		},
+		{
+			name: "設定偏好語系",
+			buildReq: func(updatedAt time.Time) *models.UpdateProfileRequest {
+				return &models.UpdateProfileRequest{Locale: stringPtr("ja"), UpdatedAt: updatedAt}
+			},
+			check: func(t *testing.T, user *models.User) {
+				if user.Locale == nil || *user.Locale != "ja" {
+					t.Errorf("UpdateProfile() locale = %v, want ja", user.Locale)
+				}
+			},
+		},
+		{
+			name: "空字串清除偏好語系",
+			setup: func(deps *mockDeps) {
+				deps.userRepo.users[0].Locale = stringPtr("en")
+			},
+			buildReq: func(updatedAt time.Time) *models.UpdateProfileRequest {
+				return &models.UpdateProfileRequest{Locale: stringPtr(""), UpdatedAt: updatedAt}
+			},
+			check: func(t *testing.T, user *models.User) {
+				if user.Locale != nil {
+					t.Errorf("UpdateProfile() locale = %v, want nil", *user.Locale)
+				}
+			},
+		},
+		{
+			name: "不支援的語系",
+			buildReq: func(updatedAt time.Time) *models.UpdateProfileRequest {
+				return &models.UpdateProfileRequest{Locale: stringPtr("fr"), UpdatedAt: updatedAt}
+			},
+			wantError: true,
+			errorMsg:  "unsupported locale",
+		},
+		{
+			name: "沒有要更新的欄位",
+			buildReq: func(updatedAt time.Time) *models.UpdateProfileRequest {
+				return &models.UpdateProfileRequest{UpdatedAt: updatedAt}
+			},
+			wantError: true,
+			errorMsg:  "no profile changes",
+		},
+		{
+			name: "updated_at 過期",
+			buildReq: func(updatedAt time.Time) *models.UpdateProfileRequest {
+				return &models.UpdateProfileRequest{LearningLevel: intPtr(3), UpdatedAt: updatedAt.Add(-time.Second)}
+			},
+			wantError: true,
+			errorMsg:  "user update conflict",
+		},
+		{
+			name: "用戶名含非法字元",
+			buildReq: func(updatedAt time.Time) *models.UpdateProfileRequest {
+				return &models.UpdateProfileRequest{Username: stringPtr("bad-name"), UpdatedAt: updatedAt}
+			},
+			wantError: true,
+			errorMsg:  "username can only contain",
+		},
+		{
+			name: "頭像網址不是 http(s)",
+			buildReq: func(updatedAt time.Time) *models.UpdateProfileRequest {
+				return &models.UpdateProfileRequest{AvatarURL: stringPtr("javascript:alert(1)"), UpdatedAt: updatedAt}
+			},
+			wantError: true,
+			errorMsg:  "invalid avatar url",
+		},
+		{
+			name: "用戶名已被使用",
+			setup: func(deps *mockDeps) {
+				deps.userRepo.CreateUser(context.Background(), &models.User{Email: "other@example.com", Username: "taken"})
+			},
+			buildReq: func(updatedAt time.Time) *models.UpdateProfileRequest {
+				return &models.UpdateProfileRequest{Username: stringPtr("taken"), UpdatedAt: updatedAt}
+			},
+			wantError: true,
+			errorMsg:  "username already taken",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deps, _ := loginTestUser(t)
+			if tt.setup != nil {
+				tt.setup(deps)
+			}
+
+			user, err := deps.profileService().UpdateProfile(context.Background(), 1, tt.buildReq(deps.userRepo.users[0].UpdatedAt))
+			if tt.wantError {
+				if err == nil || !contains(err.Error(), tt.errorMsg) {
+					t.Errorf("UpdateProfile() error = %v, expected error containing %q", err, tt.errorMsg)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("UpdateProfile() unexpected error = %v", err)
+			}
+
+			tt.check(t, user)
+			if !user.UpdatedAt.Equal(deps.userRepo.users[0].UpdatedAt) {
+				t.Errorf("UpdateProfile() updated_at = %v, want %v", user.UpdatedAt, deps.userRepo.users[0].UpdatedAt)
+			}
+		})
+	}
+}
+
+func TestProfileService_UpdateProfile_StaleAfterUpdate(t *testing.T) {
+	deps, _ := loginTestUser(t)
+	profileService := deps.profileService()
+	updatedAt := deps.userRepo.users[0].UpdatedAt
+
+	if _, err := profileService.UpdateProfile(context.Background(), 1, &models.UpdateProfileRequest{LearningLevel: intPtr(2), UpdatedAt: updatedAt}); err != nil {
+		t.Fatalf("UpdateProfile() unexpected error = %v", err)
+	}
+
+	// 另一個分頁仍持有舊的 updated_at，不可覆蓋剛才的修改
+	_, err := profileService.UpdateProfile(context.Background(), 1, &models.UpdateProfileRequest{LearningLevel: intPtr(9), UpdatedAt: updatedAt})
+	if err == nil || !contains(err.Error(), "user update conflict") {
+		t.Errorf("UpdateProfile() error = %v, expected 'user update conflict'", err)
+	}
+	if deps.userRepo.users[0].LearningLevel != 2 {
+		t.Errorf("learning level = %d, want 2", deps.userRepo.users[0].LearningLevel)
+	}
+}
+
+func TestProfileService_ChangePassword(t *testing.T) {
+	tests := []struct {
+		name      string
+		req       *models.ChangePasswordRequest
+		wantError bool
+		errorMsg  string
+	}{
+		{
+			name: "成功變更",
+			req: &models.ChangePasswordRequest{
+				CurrentPassword: "password123",
+				NewPassword:     "newpassword456",
+				ConfirmPassword: "newpassword456",
+			},
+		},
+		{
+			name: "目前密碼錯誤",
+			req: &models.ChangePasswordRequest{
+				CurrentPassword: "wrongpassword",
+				NewPassword:     "newpassword456",
+				ConfirmPassword: "newpassword456",
+			},
+			wantError: true,
+			errorMsg:  "invalid current password",
+		},
+		{
+			name: "密碼確認不一致",
+			req: &models.ChangePasswordRequest{
+				CurrentPassword: "password123",
+				NewPassword:     "newpassword456",
+				ConfirmPassword: "newpassword789",
+			},
+			wantError: true,
+			errorMsg:  "passwords do not match",
+		},
+		{
+			name: "新密碼不符合政策",
+			req: &models.ChangePasswordRequest{
+				CurrentPassword: "password123",
+				NewPassword:     "short",
+				ConfirmPassword: "short",
+			},
+			wantError: true,
+			errorMsg:  "password does not meet policy",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deps, _ := loginTestUser(t)
+			// 第二個裝置登入
+			if _, err := deps.authService().Login(context.Background(), &models.LoginRequest{Email: "test@example.com", Password: "password123"}, models.ClientInfo{}); err != nil {
+				t.Fatalf("Login() unexpected error = %v", err)
+			}
+			current := deps.sessionRepo.sessions[0].ID
+
+			err := deps.profileService().ChangePassword(context.Background(), 1, current, tt.req)
+			if tt.wantError {
+				if err == nil || !contains(err.Error(), tt.errorMsg) {
+					t.Errorf("ChangePassword() error = %v, expected error containing %q", err, tt.errorMsg)
+				}
+				if utils.VerifyPassword(deps.userRepo.users[0].PasswordHash, "password123") != nil {
+					t.Error("ChangePassword() changed the password despite the error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ChangePassword() unexpected error = %v", err)
+			}
+
+			if utils.VerifyPassword(deps.userRepo.users[0].PasswordHash, tt.req.NewPassword) != nil {
+				t.Error("ChangePassword() did not update the password")
+			}
+			for _, session := range deps.sessionRepo.sessions {
+				if (session.RevokedAt == nil) != (session.ID == current) {
+					t.Errorf("session %s revoked state is wrong", session.ID)
+				}
+			}
+			for _, token := range deps.refreshTokenRepo.tokens {
+				if (token.RevokedAt == nil) != (token.FamilyID == current) {
+					t.Errorf("refresh token family %s revoked state is wrong", token.FamilyID)
+				}
+			}
+		})
+	}
+}
+
+func TestProfileService_RequestEmailChange(t *testing.T) {
+	tests := []struct {
+		name      string
+		setup     func(deps *mockDeps)
+		req       *models.ChangeEmailRequest
+		wantError bool
+		errorMsg  string
+	}{
+		{
+			name: "寄出驗證信",
+			req:  &models.ChangeEmailRequest{NewEmail: "new@example.com", CurrentPassword: "password123"},
+		},
+		{
+			name:      "目前密碼錯誤",
+			req:       &models.ChangeEmailRequest{NewEmail: "new@example.com", CurrentPassword: "wrongpassword"},
+			wantError: true,
+			errorMsg:  "invalid current password",
+		},
+		{
+			name:      "與目前 email 相同",
+			req:       &models.ChangeEmailRequest{NewEmail: "TEST@example.com", CurrentPassword: "password123"},
+			wantError: true,
+			errorMsg:  "email unchanged",
+		},
+		{
+			name: "email 已被使用",
+			setup: func(deps *mockDeps) {
+				deps.userRepo.CreateUser(context.Background(), &models.User{Email: "new@example.com", Username: "other"})
+			},
+			req:       &models.ChangeEmailRequest{NewEmail: "new@example.com", CurrentPassword: "password123"},
+			wantError: true,
+			errorMsg:  "email already in use",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deps, _ := loginTestUser(t)
+			if tt.setup != nil {
+				tt.setup(deps)
+			}
+
+			err := deps.profileService().RequestEmailChange(context.Background(), 1, tt.req)
+			if tt.wantError {
+				if err == nil || !contains(err.Error(), tt.errorMsg) {
+					t.Errorf("RequestEmailChange() error = %v, expected error containing %q", err, tt.errorMsg)
+				}
+				if len(deps.mailer.sent) != 0 {
+					t.Errorf("RequestEmailChange() sent %d emails despite the error", len(deps.mailer.sent))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("RequestEmailChange() unexpected error = %v", err)
+			}
+
+			// 驗證信寄到新地址，舊地址收到通知
+			if len(deps.mailer.sent) != 2 || deps.mailer.sent[0].To != "new@example.com" || deps.mailer.sent[1].To != "test@example.com" {
+				t.Fatalf("RequestEmailChange() sent %+v, want verification to new and notice to old address", deps.mailer.sent)
+			}
+			if deps.userRepo.users[0].Email != "test@example.com" {
+				t.Error("RequestEmailChange() changed the email before verification")
+			}
+		})
+	}
+}
+
+func TestProfileService_EmailChangeVerification(t *testing.T) {
+	deps, _ := loginTestUser(t)
+
+	err := deps.profileService().RequestEmailChange(context.Background(), 1, &models.ChangeEmailRequest{NewEmail: "new@example.com", CurrentPassword: "password123"})
+	if err != nil {
+		t.Fatalf("RequestEmailChange() unexpected error = %v", err)
+	}
+	deps.mailer.sent = deps.mailer.sent[:1]
+	token := lastVerificationToken(t, deps)
+
+	if err := deps.emailVerificationService().VerifyEmail(context.Background(), token); err != nil {
+		t.Fatalf("VerifyEmail() unexpected error = %v", err)
+	}
+
+	user := deps.userRepo.users[0]
+	if user.Email != "new@example.com" || user.EmailVerifiedAt == nil {
+		t.Errorf("VerifyEmail() user = %+v, want verified new@example.com", user)
+	}
+	if _, err := deps.authService().Login(context.Background(), &models.LoginRequest{Email: "new@example.com", Password: "password123"}, models.ClientInfo{}); err != nil {
+		t.Errorf("Login() with new email unexpected error = %v", err)
+	}
+}
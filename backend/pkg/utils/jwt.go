@@ -2,72 +2,8 @@ package utils
 
 import (
 	"fmt"
-	"os"
-	"time"
-
-	"github.com/golang-jwt/jwt/v5"
 )
 
-type JWTClaims struct {
-	UserID   int    `json:"user_id"`
-	Email    string `json:"email"`
-	Username string `json:"username"`
-	jwt.RegisteredClaims
-}
-
-var jwtSecret []byte
-
-func init() {
-	secret := os.Getenv("JWT_SECRET")
-	if secret == "" {
-		secret = "your-super-secret-jwt-key-change-in-production"
-	}
-	jwtSecret = []byte(secret)
-}
-
-func GenerateJWT(userID int, email, username string) (string, error) {
-	expirationTime := time.Now().Add(24 * time.Hour)
-
-	claims := &JWTClaims{
-		UserID:   userID,
-		Email:    email,
-		Username: username,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(expirationTime),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-		},
-	}
-
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString(jwtSecret)
-	if err != nil {
-		return "", fmt.Errorf("failed to generate token: %w", err)
-	}
-
-	return tokenString, nil
-}
-
-func ValidateJWT(tokenString string) (*JWTClaims, error) {
-	claims := &JWTClaims{}
-
-	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return jwtSecret, nil
-	})
-
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse token: %w", err)
-	}
-
-	if !token.Valid {
-		return nil, fmt.Errorf("invalid token")
-	}
-
-	return claims, nil
-}
-
 func ExtractTokenFromHeader(authHeader string) (string, error) {
 	if authHeader == "" {
 		return "", fmt.Errorf("authorization header is required")
@@ -0,0 +1,344 @@
+package utils
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	AlgorithmHS256 = "HS256"
+	AlgorithmRS256 = "RS256"
+	AlgorithmEdDSA = "EdDSA"
+
+	// HMACKeyID 為 HS256 密鑰的 kid；沒有 kid 的舊版 token 以此金鑰驗證
+	HMACKeyID = "hs256"
+)
+
+// ErrUnknownSigningKey 表示 token 的 kid 不在金鑰組中，通常是簽發方已輪換金鑰
+var ErrUnknownSigningKey = errors.New("unknown signing key")
+
+// SigningKey 代表一把可用於簽署或驗證 JWT 的金鑰
+type SigningKey struct {
+	ID        string
+	Algorithm string
+	method    jwt.SigningMethod
+	signKey   interface{}
+	verifyKey interface{}
+}
+
+// CanSign 回傳此金鑰是否含有私鑰（或 HMAC 密鑰）可用於簽署
+func (k *SigningKey) CanSign() bool {
+	return k.signKey != nil
+}
+
+// NewHMACKey 建立 HS256 對稱金鑰
+func NewHMACKey(kid string, secret []byte) *SigningKey {
+	return &SigningKey{
+		ID:        kid,
+		Algorithm: AlgorithmHS256,
+		method:    jwt.SigningMethodHS256,
+		signKey:   secret,
+		verifyKey: secret,
+	}
+}
+
+// NewAsymmetricKey 由 RSA 或 Ed25519 金鑰建立簽署金鑰。
+// key 可為私鑰（可簽署與驗證）或公鑰（僅能驗證）；kid 為空時使用 RFC 7638 thumbprint。
+func NewAsymmetricKey(kid string, key interface{}) (*SigningKey, error) {
+	k := &SigningKey{ID: kid}
+
+	switch typed := key.(type) {
+	case *rsa.PrivateKey:
+		k.Algorithm, k.method = AlgorithmRS256, jwt.SigningMethodRS256
+		k.signKey, k.verifyKey = typed, &typed.PublicKey
+	case *rsa.PublicKey:
+		k.Algorithm, k.method = AlgorithmRS256, jwt.SigningMethodRS256
+		k.verifyKey = typed
+	case ed25519.PrivateKey:
+		k.Algorithm, k.method = AlgorithmEdDSA, jwt.SigningMethodEdDSA
+		k.signKey, k.verifyKey = typed, typed.Public()
+	case ed25519.PublicKey:
+		k.Algorithm, k.method = AlgorithmEdDSA, jwt.SigningMethodEdDSA
+		k.verifyKey = typed
+	default:
+		return nil, fmt.Errorf("unsupported key type %T", key)
+	}
+
+	if k.ID == "" {
+		thumbprint, err := k.thumbprint()
+		if err != nil {
+			return nil, err
+		}
+		k.ID = thumbprint
+	}
+
+	return k, nil
+}
+
+// LoadSigningKeyFromPEM 從 PEM 檔案載入 RSA 或 Ed25519 私鑰或公鑰
+func LoadSigningKeyFromPEM(path, kid string) (*SigningKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key file %s: %w", path, err)
+	}
+
+	key, err := parsePEMKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse key file %s: %w", path, err)
+	}
+
+	return NewAsymmetricKey(kid, key)
+}
+
+func parsePEMKey(data []byte) (interface{}, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM block found")
+	}
+
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		return x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		return x509.ParsePKCS1PublicKey(block.Bytes)
+	case "PUBLIC KEY":
+		return x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block type %q", block.Type)
+	}
+}
+
+// JWK 代表 JSON Web Key 中的公鑰欄位
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+}
+
+// JWKSet 代表 /.well-known/jwks.json 的回應格式
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// publicJWK 回傳金鑰的公開 JWK；對稱金鑰不可公開
+func (k *SigningKey) publicJWK() (JWK, bool) {
+	switch pub := k.verifyKey.(type) {
+	case *rsa.PublicKey:
+		return JWK{
+			KeyType:   "RSA",
+			KeyID:     k.ID,
+			Use:       "sig",
+			Algorithm: k.Algorithm,
+			N:         base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+			E:         base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		}, true
+	case ed25519.PublicKey:
+		return JWK{
+			KeyType:   "OKP",
+			KeyID:     k.ID,
+			Use:       "sig",
+			Algorithm: k.Algorithm,
+			Curve:     "Ed25519",
+			X:         base64.RawURLEncoding.EncodeToString(pub),
+		}, true
+	}
+	return JWK{}, false
+}
+
+// NewAsymmetricKeyFromJWK 由 JWK 公鑰建立僅用於驗證的金鑰（支援 RSA 與 Ed25519）
+func NewAsymmetricKeyFromJWK(jwk JWK) (*SigningKey, error) {
+	decode := func(name, value string) ([]byte, error) {
+		b, err := base64.RawURLEncoding.DecodeString(value)
+		if err != nil || len(b) == 0 {
+			return nil, fmt.Errorf("invalid JWK parameter %q", name)
+		}
+		return b, nil
+	}
+
+	var key interface{}
+	switch jwk.KeyType {
+	case "RSA":
+		n, err := decode("n", jwk.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decode("e", jwk.E)
+		if err != nil {
+			return nil, err
+		}
+		key = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+	case "OKP":
+		if jwk.Curve != "Ed25519" {
+			return nil, fmt.Errorf("unsupported JWK curve %q", jwk.Curve)
+		}
+		x, err := decode("x", jwk.X)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid Ed25519 public key size")
+		}
+		key = ed25519.PublicKey(x)
+	default:
+		return nil, fmt.Errorf("unsupported JWK key type %q", jwk.KeyType)
+	}
+
+	return NewAsymmetricKey(jwk.KeyID, key)
+}
+
+// thumbprint 依 RFC 7638 計算公鑰的 JWK thumbprint
+func (k *SigningKey) thumbprint() (string, error) {
+	jwk, ok := k.publicJWK()
+	if !ok {
+		return "", fmt.Errorf("cannot compute thumbprint for %s key", k.Algorithm)
+	}
+
+	var members interface{}
+	switch jwk.KeyType {
+	case "RSA":
+		members = struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{jwk.E, jwk.KeyType, jwk.N}
+	case "OKP":
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+		}{jwk.Curve, jwk.KeyType, jwk.X}
+	}
+
+	data, err := json.Marshal(members)
+	if err != nil {
+		return "", fmt.Errorf("failed to compute thumbprint: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
+
+// KeyManager 管理目前用於簽署的金鑰，以及輪換期間仍接受驗證的舊金鑰
+type KeyManager struct {
+	mu     sync.RWMutex
+	active *SigningKey
+	keys   map[string]*SigningKey
+}
+
+func NewKeyManager() *KeyManager {
+	return &KeyManager{
+		keys: make(map[string]*SigningKey),
+	}
+}
+
+// SetSigningKey 設定目前用於簽署的金鑰，同時加入驗證金鑰
+func (m *KeyManager) SetSigningKey(key *SigningKey) error {
+	if !key.CanSign() {
+		return fmt.Errorf("key %s has no private key", key.ID)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.active = key
+	m.keys[key.ID] = key
+	return nil
+}
+
+// AddVerificationKey 加入僅用於驗證的金鑰（例如輪換前的舊金鑰）
+func (m *KeyManager) AddVerificationKey(key *SigningKey) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.keys[key.ID] = key
+}
+
+// Sign 以目前的簽署金鑰簽署 claims，並在標頭加入 kid
+func (m *KeyManager) Sign(claims jwt.Claims) (string, error) {
+	m.mu.RLock()
+	active := m.active
+	m.mu.RUnlock()
+
+	if active == nil {
+		return "", fmt.Errorf("no signing key configured")
+	}
+
+	token := jwt.NewWithClaims(active.method, claims)
+	token.Header["kid"] = active.ID
+	return token.SignedString(active.signKey)
+}
+
+// Keyfunc 依 token 標頭的 kid 選擇驗證金鑰，並確認演算法與金鑰相符
+func (m *KeyManager) Keyfunc(token *jwt.Token) (interface{}, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		// 舊版 token 沒有 kid，僅在設定了 HS256 金鑰時接受
+		kid = HMACKeyID
+	}
+
+	key, ok := m.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownSigningKey, kid)
+	}
+
+	// 拒絕演算法混淆攻擊（例如以公鑰作為 HMAC 密鑰）
+	if token.Method.Alg() != key.Algorithm {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+
+	return key.verifyKey, nil
+}
+
+// Algorithms 回傳目前接受的簽署演算法
+func (m *KeyManager) Algorithms() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	seen := make(map[string]bool)
+	algs := make([]string, 0)
+	for _, key := range m.keys {
+		if !seen[key.Algorithm] {
+			seen[key.Algorithm] = true
+			algs = append(algs, key.Algorithm)
+		}
+	}
+	sort.Strings(algs)
+	return algs
+}
+
+// JWKS 回傳所有非對稱驗證金鑰的公開部分
+func (m *KeyManager) JWKS() JWKSet {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	set := JWKSet{Keys: make([]JWK, 0)}
+	for _, key := range m.keys {
+		if jwk, ok := key.publicJWK(); ok {
+			set.Keys = append(set.Keys, jwk)
+		}
+	}
+	sort.Slice(set.Keys, func(i, j int) bool { return set.Keys[i].KeyID < set.Keys[j].KeyID })
+	return set
+}
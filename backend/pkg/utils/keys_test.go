@@ -0,0 +1,226 @@
+package utils
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// writePEM 將私鑰以 PKCS#8 PEM 格式寫入暫存檔
+func writePEM(t *testing.T, key interface{}) string {
+	t.Helper()
+
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("MarshalPKCS8PrivateKey() error = %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "key.pem")
+	data := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	return path
+}
+
+func testClaims() *jwt.RegisteredClaims {
+	return &jwt.RegisteredClaims{
+		Subject:   "1",
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+	}
+}
+
+func TestLoadSigningKeyFromPEM(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("rsa.GenerateKey() error = %v", err)
+	}
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("ed25519.GenerateKey() error = %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		key           interface{}
+		wantAlgorithm string
+	}{
+		{name: "RSA 私鑰", key: rsaKey, wantAlgorithm: AlgorithmRS256},
+		{name: "Ed25519 私鑰", key: edKey, wantAlgorithm: AlgorithmEdDSA},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := LoadSigningKeyFromPEM(writePEM(t, tt.key), "")
+			if err != nil {
+				t.Fatalf("LoadSigningKeyFromPEM() error = %v", err)
+			}
+			if key.Algorithm != tt.wantAlgorithm {
+				t.Errorf("Algorithm = %v, want %v", key.Algorithm, tt.wantAlgorithm)
+			}
+			if key.ID == "" {
+				t.Error("LoadSigningKeyFromPEM() did not derive a kid")
+			}
+			if !key.CanSign() {
+				t.Error("CanSign() = false for private key")
+			}
+		})
+	}
+
+	t.Run("無效的 PEM", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "invalid.pem")
+		os.WriteFile(path, []byte("not a key"), 0600)
+
+		if _, err := LoadSigningKeyFromPEM(path, ""); err == nil {
+			t.Error("LoadSigningKeyFromPEM() expected error but got nil")
+		}
+	})
+}
+
+func TestKeyManager_SignAndVerify(t *testing.T) {
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	key, err := NewAsymmetricKey("ed-1", edKey)
+	if err != nil {
+		t.Fatalf("NewAsymmetricKey() error = %v", err)
+	}
+
+	m := NewKeyManager()
+	if err := m.SetSigningKey(key); err != nil {
+		t.Fatalf("SetSigningKey() error = %v", err)
+	}
+
+	tokenString, err := m.Sign(testClaims())
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+
+	token, err := jwt.ParseWithClaims(tokenString, &jwt.RegisteredClaims{}, m.Keyfunc, jwt.WithValidMethods(m.Algorithms()))
+	if err != nil {
+		t.Fatalf("ParseWithClaims() error = %v", err)
+	}
+	if token.Header["kid"] != "ed-1" {
+		t.Errorf("kid header = %v, want ed-1", token.Header["kid"])
+	}
+	if token.Method.Alg() != AlgorithmEdDSA {
+		t.Errorf("alg = %v, want EdDSA", token.Method.Alg())
+	}
+}
+
+func TestKeyManager_Rotation(t *testing.T) {
+	oldRSA, _ := rsa.GenerateKey(rand.Reader, 2048)
+	newRSA, _ := rsa.GenerateKey(rand.Reader, 2048)
+	oldKey, _ := NewAsymmetricKey("", oldRSA)
+	newKey, _ := NewAsymmetricKey("", newRSA)
+
+	// 以舊金鑰簽發 token
+	before := NewKeyManager()
+	before.SetSigningKey(oldKey)
+	oldToken, err := before.Sign(testClaims())
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+
+	// 輪換後以新金鑰簽署，舊金鑰僅保留公鑰用於驗證
+	oldPublic, _ := NewAsymmetricKey("", &oldRSA.PublicKey)
+	if oldPublic.ID != oldKey.ID {
+		t.Fatalf("public and private key thumbprints differ: %s != %s", oldPublic.ID, oldKey.ID)
+	}
+	after := NewKeyManager()
+	after.SetSigningKey(newKey)
+	after.AddVerificationKey(oldPublic)
+
+	if _, err := jwt.Parse(oldToken, after.Keyfunc, jwt.WithValidMethods(after.Algorithms())); err != nil {
+		t.Errorf("old token rejected during rotation: %v", err)
+	}
+
+	newToken, _ := after.Sign(testClaims())
+	if _, err := jwt.Parse(newToken, after.Keyfunc, jwt.WithValidMethods(after.Algorithms())); err != nil {
+		t.Errorf("new token rejected: %v", err)
+	}
+
+	// 舊金鑰移除後，舊 token 不再被接受
+	retired := NewKeyManager()
+	retired.SetSigningKey(newKey)
+	if _, err := jwt.Parse(oldToken, retired.Keyfunc, jwt.WithValidMethods(retired.Algorithms())); err == nil {
+		t.Error("old token accepted after its key was retired")
+	}
+
+	jwks := after.JWKS()
+	if len(jwks.Keys) != 2 {
+		t.Fatalf("JWKS() returned %d keys, want 2", len(jwks.Keys))
+	}
+	for _, jwk := range jwks.Keys {
+		if jwk.KeyType != "RSA" || jwk.Algorithm != AlgorithmRS256 || jwk.N == "" || jwk.E == "" {
+			t.Errorf("JWKS() returned malformed key %+v", jwk)
+		}
+	}
+
+	if err := after.SetSigningKey(oldPublic); err == nil {
+		t.Error("SetSigningKey() accepted a public-only key")
+	}
+}
+
+func TestNewAsymmetricKeyFromJWK(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+
+	for name, private := range map[string]interface{}{"RSA": rsaKey, "Ed25519": edKey} {
+		t.Run(name, func(t *testing.T) {
+			signer, _ := NewAsymmetricKey("k1", private)
+			m := NewKeyManager()
+			m.SetSigningKey(signer)
+			tokenString, _ := m.Sign(testClaims())
+
+			// 由公開的 JWKS 重建驗證金鑰
+			key, err := NewAsymmetricKeyFromJWK(m.JWKS().Keys[0])
+			if err != nil {
+				t.Fatalf("NewAsymmetricKeyFromJWK() error = %v", err)
+			}
+			if key.CanSign() {
+				t.Error("NewAsymmetricKeyFromJWK() returned a signing key")
+			}
+
+			verifier := NewKeyManager()
+			verifier.AddVerificationKey(key)
+			if _, err := jwt.Parse(tokenString, verifier.Keyfunc, jwt.WithValidMethods(verifier.Algorithms())); err != nil {
+				t.Errorf("token rejected by key rebuilt from JWK: %v", err)
+			}
+		})
+	}
+
+	if _, err := NewAsymmetricKeyFromJWK(JWK{KeyType: "EC", KeyID: "ec"}); err == nil {
+		t.Error("NewAsymmetricKeyFromJWK() accepted unsupported key type")
+	}
+}
+
+func TestKeyManager_RejectsAlgorithmConfusion(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	key, _ := NewAsymmetricKey("rsa-1", rsaKey)
+
+	m := NewKeyManager()
+	m.SetSigningKey(key)
+
+	// 攻擊者以公鑰作為 HMAC 密鑰偽造 token
+	publicDER, _ := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, testClaims())
+	forged.Header["kid"] = "rsa-1"
+	forgedString, _ := forged.SignedString(publicDER)
+
+	if _, err := jwt.Parse(forgedString, m.Keyfunc, jwt.WithValidMethods(m.Algorithms())); err == nil {
+		t.Error("forged HS256 token was accepted")
+	}
+
+	// JWKS 不公開對稱金鑰
+	m.AddVerificationKey(NewHMACKey(HMACKeyID, []byte("secret")))
+	if len(m.JWKS().Keys) != 1 {
+		t.Errorf("JWKS() exposed a symmetric key")
+	}
+}
@@ -1,25 +1,284 @@
 package utils
 
 import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
 	"fmt"
+	"strings"
+	"sync"
 
+	"smart-learning-backend/pkg/config"
+
+	"golang.org/x/crypto/argon2"
 	"golang.org/x/crypto/bcrypt"
 )
 
 const bcryptCost = 12
 
-func HashPassword(password string) (string, error) {
-	hashedBytes, err := bcrypt.GenerateFromPassword([]byte(password), bcryptCost)
+const (
+	PasswordAlgorithmArgon2id = "argon2id"
+	PasswordAlgorithmBcrypt   = "bcrypt"
+)
+
+// PasswordHasher 為單一演算法的密碼雜湊實作。
+// 雜湊值自帶演算法與參數（PHC 字串或 bcrypt 的 $2b$ 格式），因此可同時辨識新舊格式。
+type PasswordHasher interface {
+	Hash(password string) (string, error)
+	Verify(encodedHash, password string) error
+	// Recognizes 判斷雜湊值是否由此演算法產生
+	Recognizes(encodedHash string) bool
+	// NeedsRehash 判斷雜湊值的參數是否與目前設定不同
+	NeedsRehash(encodedHash string) bool
+}
+
+// Argon2Params 為 argon2id 的參數，Memory 以 KiB 為單位
+type Argon2Params struct {
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+// DefaultArgon2Params 參考 RFC 9106 的建議，使用 64 MiB 記憶體
+var DefaultArgon2Params = Argon2Params{
+	Memory:      64 * 1024,
+	Iterations:  3,
+	Parallelism: 2,
+	SaltLength:  16,
+	KeyLength:   32,
+}
+
+// Argon2idHasher 以 PHC 字串格式保存 argon2id 雜湊：$argon2id$v=19$m=65536,t=3,p=2$<salt>$<hash>
+type Argon2idHasher struct {
+	params Argon2Params
+}
+
+func NewArgon2idHasher(params Argon2Params) *Argon2idHasher {
+	return &Argon2idHasher{params: params}
+}
+
+func (h *Argon2idHasher) Hash(password string) (string, error) {
+	salt := make([]byte, h.params.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	key := argon2.IDKey([]byte(password), salt, h.params.Iterations, h.params.Memory, h.params.Parallelism, h.params.KeyLength)
+
+	return fmt.Sprintf(
+		"$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version,
+		h.params.Memory,
+		h.params.Iterations,
+		h.params.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+func (h *Argon2idHasher) Verify(encodedHash, password string) error {
+	params, salt, key, err := decodeArgon2id(encodedHash)
+	if err != nil {
+		return err
+	}
+
+	computed := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, params.KeyLength)
+	if subtle.ConstantTimeCompare(computed, key) != 1 {
+		return fmt.Errorf("invalid password")
+	}
+
+	return nil
+}
+
+func (h *Argon2idHasher) Recognizes(encodedHash string) bool {
+	return strings.HasPrefix(encodedHash, "$argon2id$")
+}
+
+func (h *Argon2idHasher) NeedsRehash(encodedHash string) bool {
+	params, salt, _, err := decodeArgon2id(encodedHash)
+	if err != nil {
+		return true
+	}
+
+	return params.Memory != h.params.Memory ||
+		params.Iterations != h.params.Iterations ||
+		params.Parallelism != h.params.Parallelism ||
+		params.KeyLength != h.params.KeyLength ||
+		uint32(len(salt)) != h.params.SaltLength
+}
+
+// decodeArgon2id 解析 PHC 字串，回傳參數、salt 與雜湊值
+func decodeArgon2id(encodedHash string) (Argon2Params, []byte, []byte, error) {
+	var params Argon2Params
+
+	parts := strings.Split(encodedHash, "$")
+	if len(parts) != 6 || parts[1] != PasswordAlgorithmArgon2id {
+		return params, nil, nil, fmt.Errorf("invalid argon2id hash")
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return params, nil, nil, fmt.Errorf("unsupported argon2 version")
+	}
+
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Iterations, &params.Parallelism); err != nil {
+		return params, nil, nil, fmt.Errorf("invalid argon2id parameters")
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return params, nil, nil, fmt.Errorf("invalid argon2id salt")
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return params, nil, nil, fmt.Errorf("invalid argon2id hash")
+	}
+
+	params.SaltLength = uint32(len(salt))
+	params.KeyLength = uint32(len(key))
+	return params, salt, key, nil
+}
+
+// BcryptHasher 為既有帳號使用的 bcrypt 雜湊
+type BcryptHasher struct {
+	cost int
+}
+
+func NewBcryptHasher(cost int) *BcryptHasher {
+	return &BcryptHasher{cost: cost}
+}
+
+func (h *BcryptHasher) Hash(password string) (string, error) {
+	hashedBytes, err := bcrypt.GenerateFromPassword([]byte(password), h.cost)
 	if err != nil {
 		return "", fmt.Errorf("failed to hash password: %w", err)
 	}
 	return string(hashedBytes), nil
 }
 
-func VerifyPassword(hashedPassword, password string) error {
-	err := bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
+func (h *BcryptHasher) Verify(encodedHash, password string) error {
+	err := bcrypt.CompareHashAndPassword([]byte(encodedHash), []byte(password))
 	if err != nil {
 		return fmt.Errorf("invalid password: %w", err)
 	}
 	return nil
-}
\ No newline at end of file
+}
+
+func (h *BcryptHasher) Recognizes(encodedHash string) bool {
+	return strings.HasPrefix(encodedHash, "$2a$") ||
+		strings.HasPrefix(encodedHash, "$2b$") ||
+		strings.HasPrefix(encodedHash, "$2y$")
+}
+
+func (h *BcryptHasher) NeedsRehash(encodedHash string) bool {
+	cost, err := bcrypt.Cost([]byte(encodedHash))
+	return err != nil || cost != h.cost
+}
+
+// PasswordHashing 以目前設定的演算法產生新雜湊，並可驗證所有支援格式的舊雜湊
+type PasswordHashing struct {
+	current PasswordHasher
+	hashers []PasswordHasher
+}
+
+func NewPasswordHashing(current PasswordHasher, legacy ...PasswordHasher) *PasswordHashing {
+	return &PasswordHashing{
+		current: current,
+		hashers: append([]PasswordHasher{current}, legacy...),
+	}
+}
+
+func (p *PasswordHashing) Hash(password string) (string, error) {
+	return p.current.Hash(password)
+}
+
+func (p *PasswordHashing) Verify(encodedHash, password string) error {
+	hasher := p.hasherFor(encodedHash)
+	if hasher == nil {
+		return fmt.Errorf("invalid password: unsupported password hash")
+	}
+	return hasher.Verify(encodedHash, password)
+}
+
+// NeedsRehash 判斷雜湊值是否使用舊演算法或舊參數，應於下次驗證成功後重新雜湊
+func (p *PasswordHashing) NeedsRehash(encodedHash string) bool {
+	hasher := p.hasherFor(encodedHash)
+	return hasher != p.current || p.current.NeedsRehash(encodedHash)
+}
+
+func (p *PasswordHashing) hasherFor(encodedHash string) PasswordHasher {
+	for _, hasher := range p.hashers {
+		if hasher.Recognizes(encodedHash) {
+			return hasher
+		}
+	}
+	return nil
+}
+
+var (
+	passwordHashingMu sync.RWMutex
+	passwordHashing   = NewPasswordHashing(NewArgon2idHasher(DefaultArgon2Params), NewBcryptHasher(bcryptCost))
+)
+
+// SetPasswordHashing 設定 HashPassword 與 VerifyPassword 使用的雜湊設定
+func SetPasswordHashing(p *PasswordHashing) {
+	passwordHashingMu.Lock()
+	defer passwordHashingMu.Unlock()
+	passwordHashing = p
+}
+
+func currentPasswordHashing() *PasswordHashing {
+	passwordHashingMu.RLock()
+	defer passwordHashingMu.RUnlock()
+	return passwordHashing
+}
+
+func HashPassword(password string) (string, error) {
+	return currentPasswordHashing().Hash(password)
+}
+
+func VerifyPassword(hashedPassword, password string) error {
+	return currentPasswordHashing().Verify(hashedPassword, password)
+}
+
+// PasswordNeedsRehash 判斷已驗證通過的雜湊值是否應升級為目前的演算法與參數
+func PasswordNeedsRehash(hashedPassword string) bool {
+	return currentPasswordHashing().NeedsRehash(hashedPassword)
+}
+
+// LoadPasswordHashing 依設定的演算法（argon2id 或 bcrypt）建立密碼雜湊設定。
+// argon2id 參數為 Argon2Memory（KiB）、Argon2Iterations、Argon2Parallelism，bcrypt 為 BcryptCost；
+// 兩種格式的既有雜湊皆可驗證，並會在登入時升級為目前的設定。
+func LoadPasswordHashing(cfg config.PasswordHashingConfig) (*PasswordHashing, error) {
+	if cfg.Argon2Memory < 0 || cfg.Argon2Iterations < 0 || cfg.Argon2Parallelism < 0 || cfg.Argon2Parallelism > 255 {
+		return nil, fmt.Errorf("invalid argon2 parameters: m=%d, t=%d, p=%d", cfg.Argon2Memory, cfg.Argon2Iterations, cfg.Argon2Parallelism)
+	}
+	params := Argon2Params{
+		Memory:      uint32(cfg.Argon2Memory),
+		Iterations:  uint32(cfg.Argon2Iterations),
+		Parallelism: uint8(cfg.Argon2Parallelism),
+		SaltLength:  DefaultArgon2Params.SaltLength,
+		KeyLength:   DefaultArgon2Params.KeyLength,
+	}
+
+	if params.Iterations < 1 || params.Parallelism < 1 || params.Memory < 8*uint32(params.Parallelism) {
+		return nil, fmt.Errorf("invalid argon2 parameters: m=%d, t=%d, p=%d", params.Memory, params.Iterations, params.Parallelism)
+	}
+	if cfg.BcryptCost < bcrypt.MinCost || cfg.BcryptCost > bcrypt.MaxCost {
+		return nil, fmt.Errorf("invalid BCRYPT_COST: %d", cfg.BcryptCost)
+	}
+
+	argon2id := NewArgon2idHasher(params)
+	bcryptHasher := NewBcryptHasher(cfg.BcryptCost)
+
+	switch cfg.Algorithm {
+	case PasswordAlgorithmArgon2id:
+		return NewPasswordHashing(argon2id, bcryptHasher), nil
+	case PasswordAlgorithmBcrypt:
+		return NewPasswordHashing(bcryptHasher, argon2id), nil
+	default:
+		return nil, fmt.Errorf("unsupported PASSWORD_HASH_ALGORITHM: %s", cfg.Algorithm)
+	}
+}
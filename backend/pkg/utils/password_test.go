@@ -4,6 +4,8 @@ import (
 	"strings"
 	"testing"
 
+	"smart-learning-backend/pkg/config"
+
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -21,7 +23,7 @@ func TestHashPassword(t *testing.T) {
 		{
 			name:     "空密碼",
 			password: "",
-			wantErr:  false, // bcrypt 可以處理空字符串
+			wantErr:  false, // argon2id 可以處理空字符串
 		},
 		{
 			name:     "長密碼（在72字節限制內）",
@@ -29,9 +31,9 @@ func TestHashPassword(t *testing.T) {
 			wantErr:  false,
 		},
 		{
-			name:     "超長密碼（超過72字節限制）",
+			name:     "超長密碼（argon2id 沒有 bcrypt 的 72 字節限制）",
 			password: strings.Repeat("a", 100),
-			wantErr:  true,
+			wantErr:  false,
 		},
 		{
 			name:     "特殊字符密碼",
@@ -59,9 +61,11 @@ func TestHashPassword(t *testing.T) {
 					t.Error("HashPassword() returned unhashed password")
 				}
 				
-				// 檢查雜湊值可以通過 bcrypt 驗證
-				err := bcrypt.CompareHashAndPassword([]byte(hashed), []byte(tt.password))
-				if err != nil {
+				// 檢查使用 argon2id 並可通過驗證
+				if !strings.HasPrefix(hashed, "$argon2id$v=19$m=65536,t=3,p=2$") {
+					t.Errorf("HashPassword() = %s, want argon2id PHC string", hashed)
+				}
+				if err := VerifyPassword(hashed, tt.password); err != nil {
 					t.Errorf("Generated hash cannot be verified: %v", err)
 				}
 			}
@@ -136,7 +140,7 @@ func TestHashPasswordConsistency(t *testing.T) {
 		t.Fatalf("HashPassword() failed: err1=%v, err2=%v", err1, err2)
 	}
 	
-	// 雜湊值應該不同（因為 bcrypt 使用隨機 salt）
+	// 雜湊值應該不同（因為使用隨機 salt）
 	if hash1 == hash2 {
 		t.Error("HashPassword() should generate different hashes for same password")
 	}
@@ -153,7 +157,7 @@ func TestHashPasswordConsistency(t *testing.T) {
 
 func TestBcryptCost(t *testing.T) {
 	password := "cost_test_password"
-	hashedPassword, err := HashPassword(password)
+	hashedPassword, err := NewBcryptHasher(bcryptCost).Hash(password)
 	if err != nil {
 		t.Fatalf("HashPassword() failed: %v", err)
 	}
@@ -168,4 +172,122 @@ func TestBcryptCost(t *testing.T) {
 	if cost != expectedCost {
 		t.Errorf("Bcrypt cost = %v, want %v", cost, expectedCost)
 	}
-}
\ No newline at end of file
+}
+
+func TestPasswordHashing_LegacyAndRehash(t *testing.T) {
+	bcryptHash, _ := NewBcryptHasher(bcrypt.MinCost).Hash("password123")
+	currentHash, _ := HashPassword("password123")
+	weakArgon2Hash, _ := NewArgon2idHasher(Argon2Params{Memory: 1024, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}).Hash("password123")
+
+	tests := []struct {
+		name            string
+		hash            string
+		wantNeedsRehash bool
+	}{
+		{name: "既有的 bcrypt 雜湊", hash: bcryptHash, wantNeedsRehash: true},
+		{name: "目前設定的 argon2id 雜湊", hash: currentHash, wantNeedsRehash: false},
+		{name: "舊參數的 argon2id 雜湊", hash: weakArgon2Hash, wantNeedsRehash: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := VerifyPassword(tt.hash, "password123"); err != nil {
+				t.Errorf("VerifyPassword() unexpected error = %v", err)
+			}
+			if err := VerifyPassword(tt.hash, "wrongpassword"); err == nil {
+				t.Error("VerifyPassword() accepted a wrong password")
+			}
+			if got := PasswordNeedsRehash(tt.hash); got != tt.wantNeedsRehash {
+				t.Errorf("PasswordNeedsRehash() = %v, want %v", got, tt.wantNeedsRehash)
+			}
+		})
+	}
+
+	// 無法辨識的格式一律視為需要重新雜湊，且無法通過驗證
+	if !PasswordNeedsRehash("plaintext") {
+		t.Error("PasswordNeedsRehash() = false for unknown format")
+	}
+	for _, hash := range []string{"$argon2id$v=19$m=x$salt$hash", "$argon2id$v=18$m=1024,t=1,p=1$c2FsdA$aGFzaA", "$argon2i$v=19$m=1024,t=1,p=1$c2FsdA$aGFzaA"} {
+		if err := VerifyPassword(hash, "password123"); err == nil {
+			t.Errorf("VerifyPassword(%q) expected error but got nil", hash)
+		}
+	}
+}
+
+func TestLoadPasswordHashing(t *testing.T) {
+	tests := []struct {
+		name       string
+		modify     func(*config.PasswordHashingConfig)
+		wantError  bool
+		wantPrefix string
+	}{
+		{
+			name:       "預設使用 argon2id",
+			modify:     func(*config.PasswordHashingConfig) {},
+			wantPrefix: "$argon2id$v=19$m=65536,t=3,p=2$",
+		},
+		{
+			name:       "自訂 argon2id 參數",
+			modify:     func(c *config.PasswordHashingConfig) { c.Argon2Memory, c.Argon2Iterations, c.Argon2Parallelism = 19456, 2, 1 },
+			wantPrefix: "$argon2id$v=19$m=19456,t=2,p=1$",
+		},
+		{
+			name:       "使用 bcrypt",
+			modify:     func(c *config.PasswordHashingConfig) { c.Algorithm, c.BcryptCost = PasswordAlgorithmBcrypt, 4 },
+			wantPrefix: "$2a$04$",
+		},
+		{
+			name:      "不支援的演算法",
+			modify:    func(c *config.PasswordHashingConfig) { c.Algorithm = "md5" },
+			wantError: true,
+		},
+		{
+			name:      "無效的 bcrypt 成本",
+			modify:    func(c *config.PasswordHashingConfig) { c.BcryptCost = 99 },
+			wantError: true,
+		},
+		{
+			name:      "無效的 argon2 參數",
+			modify:    func(c *config.PasswordHashingConfig) { c.Argon2Iterations = 0 },
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.Default().PasswordHashing
+			tt.modify(&cfg)
+
+			hashing, err := LoadPasswordHashing(cfg)
+			if (err != nil) != tt.wantError {
+				t.Fatalf("LoadPasswordHashing() error = %v, wantError %v", err, tt.wantError)
+			}
+			if tt.wantError {
+				return
+			}
+
+			hash, err := hashing.Hash("password123")
+			if err != nil {
+				t.Fatalf("Hash() error = %v", err)
+			}
+			if !strings.HasPrefix(hash, tt.wantPrefix) {
+				t.Errorf("Hash() = %s, want prefix %s", hash, tt.wantPrefix)
+			}
+			if hashing.NeedsRehash(hash) {
+				t.Error("NeedsRehash() = true for a freshly created hash")
+			}
+
+			// 切換演算法後，另一種格式的既有雜湊仍可驗證
+			legacy, _ := NewBcryptHasher(bcrypt.MinCost).Hash("password123")
+			if cfg.Algorithm == PasswordAlgorithmBcrypt {
+				legacy, _ = NewArgon2idHasher(DefaultArgon2Params).Hash("password123")
+			}
+			if err := hashing.Verify(legacy, "password123"); err != nil {
+				t.Errorf("Verify() legacy hash error = %v", err)
+			}
+			if !hashing.NeedsRehash(legacy) {
+				t.Error("NeedsRehash() = false for legacy algorithm")
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package utils
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+const opaqueTokenBytes = 32
+
+// APIKeyPrefix 為個人 API 金鑰的固定前綴，用於辨識金鑰類型（例如被誤貼到公開程式碼時）
+const APIKeyPrefix = "slk_"
+
+// apiKeyDisplayLength 為保存並顯示於金鑰列表的前綴長度（含 APIKeyPrefix）
+const apiKeyDisplayLength = 12
+
+// GenerateOpaqueToken 產生隨機且不透明的 token（URL 安全的 base64 字串）
+func GenerateOpaqueToken() (string, error) {
+	b := make([]byte, opaqueTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// GenerateRandomID 產生隨機的十六進位識別碼
+func GenerateRandomID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate id: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// HashToken 以 SHA-256 雜湊 token，資料庫只保存雜湊值
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// GenerateAPIKey 產生新的個人 API 金鑰，回傳完整金鑰與用於辨識的顯示前綴
+func GenerateAPIKey() (key string, displayPrefix string, err error) {
+	token, err := GenerateOpaqueToken()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to generate api key: %w", err)
+	}
+
+	key = APIKeyPrefix + token
+	return key, key[:apiKeyDisplayLength], nil
+}
+
+// IsAPIKey 判斷字串是否具有個人 API 金鑰的前綴
+func IsAPIKey(value string) bool {
+	return strings.HasPrefix(value, APIKeyPrefix)
+}
@@ -0,0 +1,69 @@
+package utils
+
+import (
+	"testing"
+)
+
+func TestGenerateOpaqueToken(t *testing.T) {
+	token1, err := GenerateOpaqueToken()
+	if err != nil {
+		t.Fatalf("GenerateOpaqueToken() error = %v", err)
+	}
+	token2, err := GenerateOpaqueToken()
+	if err != nil {
+		t.Fatalf("GenerateOpaqueToken() error = %v", err)
+	}
+
+	if token1 == "" {
+		t.Fatal("GenerateOpaqueToken() returned empty token")
+	}
+	if token1 == token2 {
+		t.Error("GenerateOpaqueToken() returned the same token twice")
+	}
+}
+
+func TestGenerateRandomID(t *testing.T) {
+	id, err := GenerateRandomID()
+	if err != nil {
+		t.Fatalf("GenerateRandomID() error = %v", err)
+	}
+	if len(id) != 32 {
+		t.Errorf("GenerateRandomID() length = %d, want 32", len(id))
+	}
+}
+
+func TestHashToken(t *testing.T) {
+	hash1 := HashToken("token-value")
+	hash2 := HashToken("token-value")
+	hash3 := HashToken("other-value")
+
+	if hash1 != hash2 {
+		t.Error("HashToken() is not deterministic")
+	}
+	if hash1 == hash3 {
+		t.Error("HashToken() returned the same hash for different tokens")
+	}
+	if hash1 == "token-value" {
+		t.Error("HashToken() returned unhashed token")
+	}
+	if len(hash1) != 64 {
+		t.Errorf("HashToken() length = %d, want 64", len(hash1))
+	}
+}
+
+func TestGenerateAPIKey(t *testing.T) {
+	key, prefix, err := GenerateAPIKey()
+	if err != nil {
+		t.Fatalf("GenerateAPIKey() error = %v", err)
+	}
+
+	if !IsAPIKey(key) {
+		t.Errorf("GenerateAPIKey() = %s, want prefix %s", key, APIKeyPrefix)
+	}
+	if len(prefix) != apiKeyDisplayLength || key[:len(prefix)] != prefix {
+		t.Errorf("display prefix = %s, want first %d characters of key", prefix, apiKeyDisplayLength)
+	}
+	if IsAPIKey("eyJhbGciOiJIUzI1NiJ9") {
+		t.Error("IsAPIKey() accepted a JWT")
+	}
+}
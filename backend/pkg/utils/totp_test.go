@@ -0,0 +1,113 @@
+package utils
+
+import (
+	"encoding/base32"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// RFC 6238 附錄 B 的 SHA1 測試向量（取 8 位數結果的末 6 位）
+func TestGenerateTOTPCode_RFC6238(t *testing.T) {
+	secret := base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{unix: 59, want: "287082"},
+		{unix: 1111111109, want: "081804"},
+		{unix: 1111111111, want: "050471"},
+		{unix: 1234567890, want: "005924"},
+		{unix: 2000000000, want: "279037"},
+	}
+
+	for _, tt := range tests {
+		got, err := GenerateTOTPCode(secret, time.Unix(tt.unix, 0))
+		if err != nil {
+			t.Fatalf("GenerateTOTPCode() error = %v", err)
+		}
+		if got != tt.want {
+			t.Errorf("GenerateTOTPCode(T=%d) = %v, want %v", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestValidateTOTPCode(t *testing.T) {
+	secret, err := GenerateTOTPSecret()
+	if err != nil {
+		t.Fatalf("GenerateTOTPSecret() error = %v", err)
+	}
+	now := time.Unix(1700000000, 0)
+	code, _ := GenerateTOTPCode(secret, now)
+
+	tests := []struct {
+		name   string
+		code   string
+		at     time.Time
+		wantOK bool
+	}{
+		{name: "目前時間區間", code: code, at: now, wantOK: true},
+		{name: "前一個時間區間（時鐘誤差）", code: code, at: now.Add(TOTPPeriod * time.Second), wantOK: true},
+		{name: "超出允許誤差", code: code, at: now.Add(3 * TOTPPeriod * time.Second), wantOK: false},
+		{name: "錯誤的驗證碼", code: "000000", at: now, wantOK: code == "000000"},
+		{name: "長度錯誤", code: "12345", at: now, wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, ok := ValidateTOTPCode(secret, tt.code, tt.at)
+			if ok != tt.wantOK {
+				t.Errorf("ValidateTOTPCode() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if ok && step != now.Unix()/TOTPPeriod {
+				t.Errorf("ValidateTOTPCode() step = %v, want %v", step, now.Unix()/TOTPPeriod)
+			}
+		})
+	}
+}
+
+func TestTOTPProvisioningURI(t *testing.T) {
+	uri := TOTPProvisioningURI("Smart Learning", "test@example.com", "JBSWY3DPEHPK3PXP")
+
+	parsed, err := url.Parse(uri)
+	if err != nil {
+		t.Fatalf("url.Parse() error = %v", err)
+	}
+	if parsed.Scheme != "otpauth" || parsed.Host != "totp" {
+		t.Errorf("unexpected URI %s", uri)
+	}
+	if parsed.Path != "/Smart Learning:test@example.com" {
+		t.Errorf("label = %v", parsed.Path)
+	}
+	query := parsed.Query()
+	if query.Get("secret") != "JBSWY3DPEHPK3PXP" || query.Get("issuer") != "Smart Learning" {
+		t.Errorf("unexpected query %v", query)
+	}
+}
+
+func TestGenerateRecoveryCodes(t *testing.T) {
+	codes, err := GenerateRecoveryCodes(10)
+	if err != nil {
+		t.Fatalf("GenerateRecoveryCodes() error = %v", err)
+	}
+	if len(codes) != 10 {
+		t.Fatalf("GenerateRecoveryCodes() returned %d codes, want 10", len(codes))
+	}
+
+	seen := make(map[string]bool)
+	for _, code := range codes {
+		if len(code) != 11 || code[5] != '-' {
+			t.Errorf("recovery code %q has unexpected format", code)
+		}
+		if seen[code] {
+			t.Errorf("duplicate recovery code %q", code)
+		}
+		seen[code] = true
+
+		if NormalizeRecoveryCode(" "+strings.ToUpper(code)+" ") != strings.Replace(code, "-", "", 1) {
+			t.Errorf("NormalizeRecoveryCode() did not normalize %q", code)
+		}
+	}
+}